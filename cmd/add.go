@@ -30,10 +30,19 @@ var addCmd = &cobra.Command{
 	Short: "Add a new note",
 	Long: `Add a new note with optional tags, TTL, and source tracking.
 
+Inside a project with a .noted.yaml, the project's tags and folder are
+applied too (see "noted project").
+
 Examples:
   noted add -t "Meeting notes" -c "Discussed project timeline"
   noted add -t "Todo" --ttl 7d -c "Review PR by Friday"
-  noted add -t "Bug" --source code-review --source-ref main.go:50`,
+  noted add -t "Bug" --source code-review --source-ref main.go:50
+  noted add -t "Runbook" --folder 3 --pinned
+  noted add -t "Acme kickoff" --template meeting --var client=Acme
+
+With --template and no --content, noted asks for each custom placeholder in the
+template ({{client}}, {{attendees}}, ...) that --var did not set, then opens
+the editor with the cursor at {{cursor}}.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		title, _ := cmd.Flags().GetString("title")
 		tags, _ := cmd.Flags().GetString("tags")
@@ -42,8 +51,10 @@ Examples:
 		source, _ := cmd.Flags().GetString("source")
 		sourceRef, _ := cmd.Flags().GetString("source-ref")
 		folderID, _ := cmd.Flags().GetInt64("folder")
+		pinned, _ := cmd.Flags().GetBool("pinned")
 		asJSON, _ := cmd.Flags().GetBool("json")
 		templateName, _ := cmd.Flags().GetString("template")
+		varPairs, _ := cmd.Flags().GetStringArray("var")
 
 		if templateName != "" {
 			ctx := context.Background()
@@ -51,10 +62,32 @@ Examples:
 			if err != nil {
 				return fmt.Errorf("template %q not found: %w", templateName, err)
 			}
+			vars, err := parseTemplateVars(varPairs)
+			if err != nil {
+				return err
+			}
 			tmplContent := interpolateTemplate(tmpl.Content, title)
-			if content != "" {
+
+			// With nothing else to fill the note, ask for the template's placeholders and open the
+			// editor at the cursor marker
+			stat, _ := os.Stdin.Stat()
+			interactive := content == "" && (stat.Mode()&os.ModeCharDevice) != 0
+			if interactive {
+				if err := promptTemplateVars(templateVars(tmplContent), vars, os.Stdin, os.Stdout); err != nil {
+					return err
+				}
+			}
+			tmplContent, line, col := cutCursor(fillTemplateVars(tmplContent, vars))
+
+			switch {
+			case content != "":
 				content = tmplContent + "\n" + content
-			} else {
+			case interactive:
+				content, err = openEditorAt(tmplContent, line, col)
+				if err != nil {
+					return err
+				}
+			default:
 				content = tmplContent
 			}
 		} else if content == "" {
@@ -98,6 +131,9 @@ Examples:
 		}
 
 		ctx := context.Background()
+		if err := checkNoteQuota(ctx, cmd, content, true); err != nil {
+			return err
+		}
 		note, err := database.CreateNoteWithTTL(ctx, db.CreateNoteWithTTLParams{
 			Title:     title,
 			Content:   content,
@@ -120,6 +156,11 @@ Examples:
 				return fmt.Errorf("failed to assign folder: %w", err)
 			}
 		}
+		if pinned {
+			if err := database.PinNote(ctx, note.ID); err != nil {
+				return fmt.Errorf("failed to pin note: %w", err)
+			}
+		}
 
 		if tags != "" {
 			tagList := strings.Split(tags, ",")
@@ -145,6 +186,11 @@ Examples:
 			}
 		}
 
+		note, err = applyProject(ctx, captureProject("."), note, cmd.Flags().Changed("folder"))
+		if err != nil {
+			return err
+		}
+
 		notesync.WriteThrough(ctx, database, openVault(cmd), note)
 
 		if asJSON {
@@ -188,7 +234,9 @@ func init() {
 	addCmd.Flags().String("source", "", "Source identifier (e.g., 'code-review', 'manual')")
 	addCmd.Flags().String("source-ref", "", "Source reference (e.g., 'main.go:50')")
 	addCmd.Flags().Int64("folder", 0, "Folder ID to add the note to")
+	addCmd.Flags().Bool("pinned", false, "Pin the note")
 	addCmd.Flags().String("template", "", "Apply a template by name")
+	addCmd.Flags().StringArray("var", nil, "Template placeholder value as key=value (repeatable)")
 	addCmd.Flags().BoolP("json", "j", false, "Output as JSON")
 
 	_ = addCmd.MarkFlagRequired("title")
@@ -0,0 +1,223 @@
+/*
+Copyright © 2026 abdul hamid <abdulachik@icloud.com>
+*/
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"sort"
+	"time"
+
+	"github.com/abdul-hamid-achik/noted/internal/db"
+	"github.com/abdul-hamid-achik/noted/internal/markdown"
+	"github.com/spf13/cobra"
+)
+
+type agendaItem struct {
+	Due       string `json:"due"`
+	Overdue   bool   `json:"overdue"`
+	Text      string `json:"text"`
+	NoteID    int64  `json:"note_id"`
+	NoteTitle string `json:"note_title"`
+	Index     int    `json:"index,omitempty"` // task position in the note; 0 when the note itself is due
+}
+
+// key identifies the item across agenda runs, so a watcher notifies about it once.
+func (a agendaItem) key() string {
+	return fmt.Sprintf("%d:%d:%s", a.NoteID, a.Index, a.Due)
+}
+
+var agendaCmd = &cobra.Command{
+	Use:   "agenda",
+	Short: "Show overdue and upcoming due items",
+	Long: `List open tasks and notes with a due date, overdue ones first.
+
+A due date is written as @due(YYYY-MM-DD). On a task line it belongs to the
+task; anywhere else in a note it makes the note itself due:
+
+  - [ ] send the draft @due(2025-07-01)
+
+Checked-off tasks never show up. With --notify, items that are due today or
+overdue are also sent as desktop notifications (notify-send on Linux,
+osascript on macOS). Add --watch to keep running in the background and notify
+about each item once, as it falls due.
+
+Examples:
+  noted agenda
+  noted agenda --days 30
+  noted agenda --notify
+  noted agenda --watch 15m &
+  noted agenda --json`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		days, _ := cmd.Flags().GetInt("days")
+		asJSON, _ := cmd.Flags().GetBool("json")
+		notify, _ := cmd.Flags().GetBool("notify")
+		watch, _ := cmd.Flags().GetDuration("watch")
+
+		if days < 0 {
+			return fmt.Errorf("--days must not be negative")
+		}
+		ctx := context.Background()
+
+		if watch > 0 {
+			return watchAgenda(ctx, days, watch)
+		}
+
+		items, err := loadAgenda(ctx, time.Now(), days)
+		if err != nil {
+			return err
+		}
+		if notify {
+			if _, err := notifyDue(items, map[string]bool{}); err != nil {
+				return err
+			}
+		}
+
+		if asJSON {
+			if items == nil {
+				items = []agendaItem{}
+			}
+			return outputJSON(items)
+		}
+		if len(items) == 0 {
+			fmt.Printf("Nothing due in the next %d days.\n", days)
+			return nil
+		}
+		today := time.Now().Format(time.DateOnly)
+		section := ""
+		for _, it := range items {
+			heading := "Upcoming"
+			if it.Overdue {
+				heading = "Overdue"
+			} else if it.Due == today {
+				heading = "Today"
+			}
+			if heading != section {
+				if section != "" {
+					fmt.Println()
+				}
+				fmt.Println(heading)
+				section = heading
+			}
+			ref := fmt.Sprintf("#%d", it.NoteID)
+			if it.Index > 0 {
+				ref = fmt.Sprintf("#%d:%d", it.NoteID, it.Index)
+			}
+			fmt.Printf("  %s  %-45s (%s %s)\n", it.Due, it.Text, it.NoteTitle, ref)
+		}
+		return nil
+	},
+}
+
+// loadAgenda returns the agenda for the live notes.
+func loadAgenda(ctx context.Context, now time.Time, days int) ([]agendaItem, error) {
+	notes, err := database.GetAllNotes(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get notes: %w", err)
+	}
+	return collectAgenda(notes, now, days), nil
+}
+
+// collectAgenda returns the open tasks and notes due on or before the day days after now, including
+// everything overdue, sorted by due date.
+func collectAgenda(notes []db.Note, now time.Time, days int) []agendaItem {
+	today := now.Format(time.DateOnly)
+	last := now.AddDate(0, 0, days).Format(time.DateOnly)
+
+	var items []agendaItem
+	add := func(due, text string, note db.Note, index int) {
+		// YYYY-MM-DD dates order the same as strings
+		if due == "" || due > last {
+			return
+		}
+		items = append(items, agendaItem{Due: due, Overdue: due < today, Text: text, NoteID: note.ID, NoteTitle: note.Title, Index: index})
+	}
+	for _, note := range notes {
+		add(markdown.NoteDue(note.Content), note.Title, note, 0)
+		for _, t := range markdown.Tasks(note.Content) {
+			if !t.Done {
+				add(t.Due, t.Text, note, t.Index)
+			}
+		}
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		if items[i].Due != items[j].Due {
+			return items[i].Due < items[j].Due
+		}
+		if items[i].NoteID != items[j].NoteID {
+			return items[i].NoteID < items[j].NoteID
+		}
+		return items[i].Index < items[j].Index
+	})
+	return items
+}
+
+// desktopNotify shows a desktop notification. Tests replace it.
+var desktopNotify = func(title, body string) error {
+	switch runtime.GOOS {
+	case "darwin":
+		script := fmt.Sprintf("display notification %q with title %q", body, title)
+		return exec.Command("osascript", "-e", script).Run()
+	case "windows":
+		return errors.New("desktop notifications are not supported on Windows")
+	default:
+		return exec.Command("notify-send", title, body).Run()
+	}
+}
+
+// notifyDue sends a notification for every item that is due today or overdue and not yet in sent,
+// adding it to sent. It returns how many it sent.
+func notifyDue(items []agendaItem, sent map[string]bool) (int, error) {
+	today := time.Now().Format(time.DateOnly)
+	n := 0
+	for _, it := range items {
+		if it.Due > today || sent[it.key()] {
+			continue
+		}
+		title := "Due today"
+		if it.Overdue {
+			title = "Overdue since " + it.Due
+		}
+		if err := desktopNotify(title, fmt.Sprintf("%s (%s)", it.Text, it.NoteTitle)); err != nil {
+			return n, fmt.Errorf("failed to send notification: %w", err)
+		}
+		sent[it.key()] = true
+		n++
+	}
+	return n, nil
+}
+
+// watchAgenda rechecks the agenda every interval until interrupted, notifying about each item once.
+func watchAgenda(ctx context.Context, days int, interval time.Duration) error {
+	sent := map[string]bool{}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		items, err := loadAgenda(ctx, time.Now(), days)
+		if err != nil {
+			return err
+		}
+		if _, err := notifyDue(items, sent); err != nil {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(agendaCmd)
+
+	agendaCmd.Flags().IntP("days", "d", 7, "Show items due within this many days")
+	agendaCmd.Flags().Bool("notify", false, "Send desktop notifications for items due today or overdue")
+	agendaCmd.Flags().Duration("watch", 0, "Keep running, checking at this interval and notifying about each item once")
+	agendaCmd.Flags().BoolP("json", "j", false, "Output as JSON")
+}
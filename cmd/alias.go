@@ -0,0 +1,122 @@
+/*
+Copyright © 2026 abdul hamid <abdulachik@icloud.com>
+*/
+package cmd
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/abdul-hamid-achik/noted/internal/notesync"
+	"github.com/spf13/cobra"
+)
+
+type aliasResult struct {
+	ID      int64    `json:"id"`
+	Title   string   `json:"title"`
+	Aliases []string `json:"aliases"`
+}
+
+var aliasCmd = &cobra.Command{
+	Use:   "alias",
+	Short: "Manage the other names a note answers to",
+	Long: `Aliases are other names for a note. A [[wikilink]] to an alias resolves to
+the note, so links keep working after a concept is renamed. A title always
+wins over another note's alias. Aliases are stored in the vault frontmatter
+("aliases:") and read back from it by noted import and noted vault import.
+
+Examples:
+  noted alias add 42 "Golang" "Go language"
+  noted alias list 42
+  noted alias remove 42 Golang`,
+}
+
+var aliasListCmd = &cobra.Command{
+	Use:   "list <id>",
+	Short: "List a note's aliases",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runAlias(cmd, args[0], func(current []string) []string { return current })
+	},
+}
+
+var aliasAddCmd = &cobra.Command{
+	Use:   "add <id> <alias>...",
+	Short: "Add aliases to a note",
+	Args:  cobra.MinimumNArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runAlias(cmd, args[0], func(current []string) []string {
+			return append(current, args[1:]...)
+		})
+	},
+}
+
+var aliasRemoveCmd = &cobra.Command{
+	Use:   "remove <id> <alias>...",
+	Short: "Remove aliases from a note",
+	Args:  cobra.MinimumNArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runAlias(cmd, args[0], func(current []string) []string {
+			return slices.DeleteFunc(current, func(a string) bool { return slices.Contains(args[1:], a) })
+		})
+	},
+}
+
+// runAlias applies change to a note's aliases, saving and mirroring them to the vault when they
+// differ, then prints the result.
+func runAlias(cmd *cobra.Command, arg string, change func([]string) []string) error {
+	asJSON, _ := cmd.Flags().GetBool("json")
+
+	id, err := strconv.ParseInt(arg, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid note ID: %s", arg)
+	}
+
+	ctx := context.Background()
+	note, err := database.GetNote(ctx, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("note #%d not found", id)
+		}
+		return fmt.Errorf("failed to get note: %w", err)
+	}
+
+	current, err := database.GetNoteAliases(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to get aliases: %w", err)
+	}
+	if next := change(slices.Clone(current)); !slices.Equal(next, current) {
+		if err := notesync.SetAliases(ctx, database, note, next); err != nil {
+			return err
+		}
+		notesync.WriteThrough(ctx, database, openVault(cmd), note)
+		if current, err = database.GetNoteAliases(ctx, id); err != nil {
+			return fmt.Errorf("failed to get aliases: %w", err)
+		}
+	}
+
+	if asJSON {
+		return outputJSON(aliasResult{ID: note.ID, Title: note.Title, Aliases: append([]string{}, current...)})
+	}
+	if len(current) == 0 {
+		fmt.Printf("Note #%d (%s) has no aliases.\n", note.ID, note.Title)
+		return nil
+	}
+	fmt.Printf("Aliases of #%d (%s): %s\n", note.ID, note.Title, strings.Join(current, ", "))
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(aliasCmd)
+	aliasCmd.AddCommand(aliasListCmd)
+	aliasCmd.AddCommand(aliasAddCmd)
+	aliasCmd.AddCommand(aliasRemoveCmd)
+
+	for _, c := range []*cobra.Command{aliasListCmd, aliasAddCmd, aliasRemoveCmd} {
+		c.Flags().BoolP("json", "j", false, "Output as JSON")
+	}
+}
@@ -0,0 +1,97 @@
+/*
+Copyright © 2026 abdul hamid <abdulachik@icloud.com>
+*/
+package cmd
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+
+	"github.com/abdul-hamid-achik/noted/internal/notesync"
+	"github.com/spf13/cobra"
+)
+
+type archiveResult struct {
+	Archived bool    `json:"archived"`
+	Count    int     `json:"count"`
+	IDs      []int64 `json:"ids"`
+}
+
+var archiveCmd = &cobra.Command{
+	Use:   "archive <id> [id...]",
+	Short: "Archive notes",
+	Long: `Archive notes to declutter without deleting them. Archived notes are hidden
+from noted list and noted grep; pass --archived to include them. They keep their
+tags, links, and history, still resolve as [[wikilink]] targets, and are still
+exported.
+
+Examples:
+  noted archive 42
+  noted archive 42 43
+  noted list --archived
+  noted grep roadmap --archived
+  noted unarchive 42`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runArchive(cmd, args, true)
+	},
+}
+
+var unarchiveCmd = &cobra.Command{
+	Use:   "unarchive <id> [id...]",
+	Short: "Bring archived notes back into lists and search",
+	Args:  cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runArchive(cmd, args, false)
+	},
+}
+
+// runArchive archives or unarchives each note in args, reporting notes that don't exist.
+func runArchive(cmd *cobra.Command, args []string, archived bool) error {
+	asJSON, _ := cmd.Flags().GetBool("json")
+
+	ids, err := parseNoteIDs(args)
+	if err != nil {
+		return err
+	}
+
+	verb := "Archived"
+	if !archived {
+		verb = "Unarchived"
+	}
+
+	ctx := context.Background()
+	vlt := openVault(cmd)
+	done := make([]int64, 0, len(ids))
+	for _, id := range ids {
+		note, err := notesync.SetArchived(ctx, database, vlt, id, archived)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				if !asJSON {
+					fmt.Fprintf(os.Stderr, "note #%d not found\n", id)
+				}
+				continue
+			}
+			return err
+		}
+		if !asJSON {
+			fmt.Printf("%s note #%d: %s\n", verb, note.ID, note.Title)
+		}
+		done = append(done, id)
+	}
+
+	if asJSON {
+		return outputJSON(archiveResult{Archived: archived, Count: len(done), IDs: done})
+	}
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(archiveCmd)
+	rootCmd.AddCommand(unarchiveCmd)
+
+	archiveCmd.Flags().BoolP("json", "j", false, "Output as JSON")
+	unarchiveCmd.Flags().BoolP("json", "j", false, "Output as JSON")
+}
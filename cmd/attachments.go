@@ -0,0 +1,270 @@
+/*
+Copyright © 2026 abdul hamid <abdulachik@icloud.com>
+*/
+package cmd
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/abdul-hamid-achik/noted/internal/markdown"
+)
+
+// assetsDir holds attachments: <vault>/assets after an import, assets/ inside an export bundle.
+const assetsDir = "assets"
+
+// assetSet stores attachment files under unique names in an assets directory, each source once.
+type assetSet struct {
+	names map[string]string // resolved source path -> file name under assets/
+	// put stores src as name, reporting false when name already holds a different file.
+	put func(name, src string) (bool, error)
+	// byContent names files after a hash of their content instead of their base name, so the same
+	// file attached from several places is stored once.
+	byContent bool
+}
+
+// add stores the file at src and returns its new path, relative to the bundle or vault root.
+func (a *assetSet) add(src string) (string, error) {
+	if name, ok := a.names[src]; ok {
+		return assetsDir + "/" + name, nil
+	}
+	base := filepath.Base(src)
+	if a.byContent {
+		sum, err := fileHash(src)
+		if err != nil {
+			return "", fmt.Errorf("failed to read attachment %s: %w", src, err)
+		}
+		base = sum[:16] + strings.ToLower(filepath.Ext(base))
+	}
+	ext := filepath.Ext(base)
+	for i := 1; ; i++ {
+		name := base
+		if i > 1 {
+			name = fmt.Sprintf("%s-%d%s", strings.TrimSuffix(base, ext), i, ext)
+		}
+		ok, err := a.put(name, src)
+		if err != nil {
+			return "", fmt.Errorf("failed to copy attachment %s: %w", src, err)
+		}
+		if ok {
+			a.names[src] = name
+			return assetsDir + "/" + name, nil
+		}
+	}
+}
+
+// zipAssets stores attachments in a zip bundle's assets/ directory.
+func zipAssets(zw *zip.Writer) *assetSet {
+	taken := map[string]bool{}
+	return &assetSet{names: map[string]string{}, put: func(name, src string) (bool, error) {
+		if taken[name] {
+			return false, nil
+		}
+		taken[name] = true
+		f, err := os.Open(src)
+		if err != nil {
+			return false, err
+		}
+		defer func() { _ = f.Close() }()
+		w, err := zw.Create(assetsDir + "/" + name)
+		if err != nil {
+			return false, err
+		}
+		_, err = io.Copy(w, f)
+		return err == nil, err
+	}}
+}
+
+// dirAssets stores attachments in root/assets, named by content hash. A file already there with
+// the same content is reused, so the same screenshot attached to several notes is stored once.
+func dirAssets(root string) *assetSet {
+	return &assetSet{names: map[string]string{}, byContent: true, put: func(name, src string) (bool, error) {
+		data, err := os.ReadFile(src)
+		if err != nil {
+			return false, err
+		}
+		dest := filepath.Join(root, assetsDir, name)
+		if existing, err := os.ReadFile(dest); err == nil {
+			return bytes.Equal(existing, data), nil
+		}
+		if err := os.MkdirAll(filepath.Dir(dest), 0o755); err != nil {
+			return false, err
+		}
+		return true, os.WriteFile(dest, data, 0o644)
+	}}
+}
+
+// fileHash returns the hex SHA-256 of the file at path.
+func fileHash(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = f.Close() }()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// assetFile is a file in <vault>/assets and the number of notes that link to it.
+type assetFile struct {
+	Name string
+	Size int64
+	Hash string
+	Refs int
+}
+
+// assetUsage lists the files in root/assets with their reference counts, counted over the links in
+// every note, trashed ones included so that restoring a note never finds its attachments gone.
+func assetUsage(ctx context.Context, root string) ([]assetFile, error) {
+	entries, err := os.ReadDir(filepath.Join(root, assetsDir))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	notes, err := database.GetAllNotes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	trashed, err := database.ListTrashedNotes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	refs := map[string]int{}
+	for _, n := range append(notes, trashed...) {
+		for _, p := range markdown.Attachments(n.Content) {
+			dir, name := path.Split(path.Clean(filepath.ToSlash(p)))
+			if dir == assetsDir+"/" || strings.HasSuffix(dir, "/"+assetsDir+"/") {
+				refs[name]++
+			}
+		}
+	}
+
+	var files []assetFile
+	for _, e := range entries {
+		if !e.Type().IsRegular() {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			return nil, err
+		}
+		sum, err := fileHash(filepath.Join(root, assetsDir, e.Name()))
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, assetFile{Name: e.Name(), Size: info.Size(), Hash: sum, Refs: refs[e.Name()]})
+	}
+	return files, nil
+}
+
+// reclaimableAssets returns the files that take space for nothing: those no note links to, and
+// extra copies of content already stored under another name. Of a set of copies, the most linked
+// one is kept.
+func reclaimableAssets(files []assetFile) []assetFile {
+	keep := map[string]assetFile{}
+	for _, f := range files {
+		if k, ok := keep[f.Hash]; f.Refs > 0 && (!ok || f.Refs > k.Refs) {
+			keep[f.Hash] = f
+		}
+	}
+	var out []assetFile
+	for _, f := range files {
+		if f.Refs == 0 || keep[f.Hash].Name != f.Name {
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
+// withAttachments stores every local file content links to in assets and points the links at the
+// stored copies. Relative paths resolve against baseDir. Links to files that don't exist are left
+// as written and returned in missing.
+func withAttachments(content, baseDir string, assets *assetSet) (out string, missing []string, err error) {
+	return withAttachmentsLinked(content, baseDir, assets, func(stored string) string { return stored })
+}
+
+// withAttachmentsLinked is withAttachments with the new links made by link from the stored copy's
+// path relative to the bundle or vault root, for content that isn't stored at that root.
+func withAttachmentsLinked(content, baseDir string, assets *assetSet, link func(stored string) string) (out string, missing []string, err error) {
+	out = markdown.RewriteAttachments(content, func(p string) (string, bool) {
+		if err != nil {
+			return "", false
+		}
+		src := filepath.FromSlash(p)
+		if !filepath.IsAbs(src) {
+			src = filepath.Join(baseDir, src)
+		}
+		if fi, statErr := os.Stat(src); statErr != nil || !fi.Mode().IsRegular() {
+			missing = append(missing, p)
+			return "", false
+		}
+		next, addErr := assets.add(filepath.Clean(src))
+		if addErr != nil {
+			err = addErr
+			return "", false
+		}
+		return link(next), true
+	})
+	return out, missing, err
+}
+
+// extractZip unpacks a zip archive into dir, refusing entries that would land outside it.
+func extractZip(path, dir string) error {
+	zr, err := zip.OpenReader(path)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = zr.Close() }()
+
+	for _, f := range zr.File {
+		if !filepath.IsLocal(f.Name) {
+			return fmt.Errorf("unsafe path in archive: %s", f.Name)
+		}
+		dest := filepath.Join(dir, f.Name)
+		if f.FileInfo().IsDir() {
+			if err := os.MkdirAll(dest, 0o755); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(dest), 0o755); err != nil {
+			return err
+		}
+		if err := extractZipFile(f, dest); err != nil {
+			return fmt.Errorf("failed to extract %s: %w", f.Name, err)
+		}
+	}
+	return nil
+}
+
+func extractZipFile(f *zip.File, dest string) error {
+	r, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = r.Close() }()
+	w, err := os.Create(dest)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(w, r); err != nil {
+		_ = w.Close()
+		return err
+	}
+	return w.Close()
+}
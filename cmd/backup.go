@@ -0,0 +1,67 @@
+/*
+Copyright © 2026 abdul hamid <abdulachik@icloud.com>
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/abdul-hamid-achik/noted/internal/db"
+	"github.com/abdul-hamid-achik/noted/internal/quota"
+	"github.com/spf13/cobra"
+)
+
+type backupResult struct {
+	Path string `json:"path"`
+	Size int64  `json:"size_bytes"`
+}
+
+var backupCmd = &cobra.Command{
+	Use:   "backup [file]",
+	Short: "Write a snapshot of the database to a file",
+	Long: `Write a consistent copy of the database to a file with SQLite's VACUUM INTO.
+The copy is taken in one read transaction, so it is safe while the TUI or an
+MCP server is writing, unlike copying the database file. It is also compacted.
+
+The file defaults to noted-<date>-<time>.db in the current directory, and must
+not exist yet. Open a backup with --db, or restore it by copying it over the
+database while noted isn't running.
+
+Examples:
+  noted backup
+  noted backup ~/Backups/notes.db`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		asJSON, _ := cmd.Flags().GetBool("json")
+
+		path := "noted-" + time.Now().Format("20060102-150405") + ".db"
+		if len(args) > 0 {
+			path = args[0]
+		}
+		if _, err := os.Stat(path); err == nil {
+			return fmt.Errorf("%s already exists", path)
+		}
+
+		if err := db.Backup(context.Background(), conn, path); err != nil {
+			return err
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			return err
+		}
+
+		if asJSON {
+			return outputJSON(backupResult{Path: path, Size: info.Size()})
+		}
+		fmt.Printf("Backed up the database to %s (%s)\n", path, quota.FormatSize(info.Size()))
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(backupCmd)
+	backupCmd.Flags().BoolP("json", "j", false, "Output as JSON")
+}
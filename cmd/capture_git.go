@@ -0,0 +1,253 @@
+/*
+Copyright © 2026 abdul hamid <abdulachik@icloud.com>
+*/
+package cmd
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os/exec"
+	"path"
+	"strings"
+
+	"github.com/abdul-hamid-achik/noted/internal/config"
+	"github.com/abdul-hamid-achik/noted/internal/db"
+	"github.com/abdul-hamid-achik/noted/internal/memory"
+	"github.com/abdul-hamid-achik/noted/internal/veclite"
+	"github.com/spf13/cobra"
+)
+
+// gitSource is the memory source for captured commits; source_ref holds the commit SHA.
+const gitSource = "git"
+
+// maxCapturedFiles caps the changed-file list stored with a captured commit.
+const maxCapturedFiles = 20
+
+type gitCommit struct {
+	SHA     string
+	Message string
+	Files   []string
+}
+
+type captureGitResult struct {
+	SHA      string   `json:"sha"`
+	Captured bool     `json:"captured"`
+	ID       int64    `json:"id,omitempty"`
+	Title    string   `json:"title,omitempty"`
+	Matched  []string `json:"matched,omitempty"`
+	Reason   string   `json:"reason,omitempty"` // why the commit was skipped
+}
+
+var captureGitCmd = &cobra.Command{
+	Use:   "capture-git [rev]",
+	Short: "Store a git commit message as a memory",
+	Long: `Store a commit's message as a memory (source "git", source ref = the commit
+SHA) so decisions recorded in commits can be recalled later. Defaults to HEAD.
+
+Only commits touching one of the configured paths are captured. A path is a
+file, a directory prefix, or a glob such as "*.sql". Set them with --path or
+NOTED_CAPTURE_GIT_PATHS (comma-separated); with neither, every commit is
+captured. A commit that was already captured is skipped.
+
+Inside a project with a .noted.yaml, the project's tags, folder, and default
+category are applied to the memory (see "noted project").
+
+Run it from a post-commit hook to capture commits as they are made:
+
+  printf '#!/bin/sh\nnoted capture-git >/dev/null 2>&1 || true\n' > .git/hooks/post-commit
+  chmod +x .git/hooks/post-commit
+
+Examples:
+  noted capture-git
+  noted capture-git HEAD~1 --path internal/db --path docs
+  noted capture-git --category project --importance 4`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		asJSON, _ := cmd.Flags().GetBool("json")
+		repo, _ := cmd.Flags().GetString("repo")
+		paths, _ := cmd.Flags().GetStringSlice("path")
+		category, _ := cmd.Flags().GetString("category")
+		importance, _ := cmd.Flags().GetInt("importance")
+
+		project := captureProject(repo)
+		if project != nil && project.Category != "" && !cmd.Flags().Changed("category") {
+			category = project.Category
+		}
+
+		rev := "HEAD"
+		if len(args) == 1 {
+			rev = args[0]
+		}
+
+		cfg, err := config.Load()
+		if err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+		if len(paths) == 0 {
+			paths = cfg.CaptureGitPaths
+		}
+
+		commit, err := readCommit(repo, rev)
+		if err != nil {
+			return err
+		}
+
+		var syncer *veclite.Syncer
+		if cfg.VeclitePath != "" {
+			syncer, _ = openSyncer(cfg, false)
+			if syncer != nil {
+				defer func() { _ = syncer.Close() }()
+			}
+		}
+
+		ctx := context.Background()
+		res, err := captureCommit(ctx, syncer, commit, paths, category, importance)
+		if err != nil {
+			return err
+		}
+		if res.Captured && project != nil {
+			if note, err := database.GetNote(ctx, res.ID); err == nil {
+				if _, err := applyProject(ctx, project, note, false); err != nil {
+					return err
+				}
+			}
+		}
+
+		if asJSON {
+			return outputJSON(res)
+		}
+		if !res.Captured {
+			fmt.Printf("Skipped commit %s: %s\n", shortSHA(res.SHA), res.Reason)
+			return nil
+		}
+		fmt.Printf("Captured commit %s as memory #%d: %s\n", shortSHA(res.SHA), res.ID, res.Title)
+		return nil
+	},
+}
+
+// readCommit reads a commit's SHA, message, and changed files with the git CLI.
+func readCommit(repo, rev string) (gitCommit, error) {
+	out, err := gitOutput(repo, "log", "-1", "--format=%H%x00%B", rev, "--")
+	if err != nil {
+		return gitCommit{}, err
+	}
+	sha, message, ok := strings.Cut(out, "\x00")
+	if !ok {
+		return gitCommit{}, fmt.Errorf("unexpected git log output for %s", rev)
+	}
+	c := gitCommit{SHA: strings.TrimSpace(sha), Message: strings.TrimSpace(message)}
+
+	out, err = gitOutput(repo, "diff-tree", "--no-commit-id", "--name-only", "-r", "--root", c.SHA)
+	if err != nil {
+		return gitCommit{}, err
+	}
+	for _, f := range strings.Split(out, "\n") {
+		if f = strings.TrimSpace(f); f != "" {
+			c.Files = append(c.Files, f)
+		}
+	}
+	return c, nil
+}
+
+func gitOutput(repo string, args ...string) (string, error) {
+	cmd := exec.Command("git", append([]string{"-C", repo}, args...)...)
+	out, err := cmd.Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && len(exitErr.Stderr) > 0 {
+			return "", fmt.Errorf("git %s failed: %s", args[0], strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return "", fmt.Errorf("git %s failed: %w", args[0], err)
+	}
+	return string(out), nil
+}
+
+// matchPaths returns the files matching any pattern: the path itself, a directory prefix, or a
+// glob (matched against the full path and the file name). No patterns match every file.
+func matchPaths(files, patterns []string) []string {
+	if len(patterns) == 0 {
+		return files
+	}
+	var matched []string
+	for _, f := range files {
+		for _, p := range patterns {
+			p = strings.TrimSuffix(strings.TrimPrefix(p, "./"), "/")
+			full, _ := path.Match(p, f)
+			base, _ := path.Match(p, path.Base(f))
+			if f == p || strings.HasPrefix(f, p+"/") || full || base {
+				matched = append(matched, f)
+				break
+			}
+		}
+	}
+	return matched
+}
+
+// captureCommit stores a commit as a memory unless it touches none of the paths or was captured
+// before.
+func captureCommit(ctx context.Context, syncer *veclite.Syncer, c gitCommit, paths []string, category string, importance int) (captureGitResult, error) {
+	res := captureGitResult{SHA: c.SHA}
+
+	res.Matched = matchPaths(c.Files, paths)
+	if len(res.Matched) == 0 {
+		res.Reason = "no changes under " + strings.Join(paths, ", ")
+		return res, nil
+	}
+	if c.Message == "" {
+		res.Reason = "empty commit message"
+		return res, nil
+	}
+
+	existing, err := database.GetNoteBySource(ctx, db.GetNoteBySourceParams{
+		Source:    sql.NullString{String: gitSource, Valid: true},
+		SourceRef: sql.NullString{String: c.SHA, Valid: true},
+	})
+	switch {
+	case err == nil:
+		res.ID, res.Title, res.Reason = existing.ID, existing.Title, fmt.Sprintf("already captured as memory #%d", existing.ID)
+		return res, nil
+	case !errors.Is(err, sql.ErrNoRows):
+		return res, fmt.Errorf("failed to look up commit: %w", err)
+	}
+
+	title, _, _ := strings.Cut(c.Message, "\n")
+	content := c.Message + "\n\nCommit: " + c.SHA
+	files := c.Files
+	if len(files) > maxCapturedFiles {
+		files = append(files[:maxCapturedFiles:maxCapturedFiles], fmt.Sprintf("... and %d more", len(c.Files)-maxCapturedFiles))
+	}
+	content += "\nFiles: " + strings.Join(files, ", ")
+
+	mem, err := memory.Remember(ctx, database, syncer, memory.RememberInput{
+		Content:    content,
+		Title:      strings.TrimSpace(title),
+		Category:   category,
+		Importance: importance,
+		Source:     gitSource,
+		SourceRef:  c.SHA,
+	})
+	if err != nil {
+		return res, err
+	}
+	res.Captured, res.ID, res.Title = true, mem.ID, mem.Title
+	return res, nil
+}
+
+func shortSHA(sha string) string {
+	if len(sha) > 7 {
+		return sha[:7]
+	}
+	return sha
+}
+
+func init() {
+	rootCmd.AddCommand(captureGitCmd)
+
+	captureGitCmd.Flags().String("repo", ".", "Repository directory")
+	captureGitCmd.Flags().StringSlice("path", nil, "Only capture commits touching this path or glob (repeatable; default NOTED_CAPTURE_GIT_PATHS)")
+	captureGitCmd.Flags().StringP("category", "c", "decision", "Memory category")
+	captureGitCmd.Flags().IntP("importance", "i", 3, "Importance level 1-5")
+	captureGitCmd.Flags().BoolP("json", "j", false, "Output as JSON")
+}
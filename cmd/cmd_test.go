@@ -6,18 +6,37 @@ Test suite for noted CLI commands.
 package cmd
 
 import (
+	"archive/zip"
+	"bytes"
 	"context"
+	"crypto/sha256"
 	"database/sql"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"maps"
+	"mime"
+	"mime/multipart"
+	"net/mail"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"runtime"
+	"slices"
+	"strconv"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/abdul-hamid-achik/noted/internal/config"
 	"github.com/abdul-hamid-achik/noted/internal/db"
+	"github.com/abdul-hamid-achik/noted/internal/embeddings"
 	"github.com/abdul-hamid-achik/noted/internal/notesync"
+	"github.com/abdul-hamid-achik/noted/internal/quota"
 	"github.com/abdul-hamid-achik/noted/internal/vault"
+	"github.com/abdul-hamid-achik/noted/internal/veclite"
 )
 
 // setupTestDB sets up a fresh database for testing
@@ -289,6 +308,92 @@ func TestDatabaseDeleteUnusedTags(t *testing.T) {
 	}
 }
 
+func TestNormalizeTagColor(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{"", "", false},
+		{"#FF8800", "#ff8800", false},
+		{"f80", "#f80", false},
+		{"  #3b82f6 ", "#3b82f6", false},
+		{"red", "", true},
+		{"#ff88", "", true},
+	}
+	for _, tt := range tests {
+		got, err := normalizeTagColor(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("normalizeTagColor(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("normalizeTagColor(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestTagsEditCmd(t *testing.T) {
+	cleanup := setupTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	createTestNote(t, "Note", "Content", []string{"work"})
+
+	_ = tagsEditCmd.Flags().Set("color", "3B82F6")
+	_ = tagsEditCmd.Flags().Set("description", "Day job notes")
+	defer func() {
+		_ = tagsEditCmd.Flags().Set("color", "")
+		_ = tagsEditCmd.Flags().Set("description", "")
+		tagsEditCmd.Flags().Lookup("color").Changed = false
+		tagsEditCmd.Flags().Lookup("description").Changed = false
+	}()
+	if err := tagsEditCmd.RunE(tagsEditCmd, []string{"work"}); err != nil {
+		t.Fatalf("tags edit failed: %v", err)
+	}
+
+	// Re-tagging a note must not reset the metadata
+	createTestNote(t, "Another", "Content", []string{"work"})
+
+	tags, err := database.GetTagsWithCount(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tags) != 1 || tags[0].Color != "#3b82f6" || tags[0].Description != "Day job notes" || tags[0].NoteCount != 2 {
+		t.Errorf("unexpected tag row: %+v", tags)
+	}
+
+	if err := tagsEditCmd.RunE(tagsEditCmd, []string{"missing"}); err == nil {
+		t.Error("expected error for unknown tag")
+	}
+}
+
+func TestTagsRenameCmd(t *testing.T) {
+	defer setupTestDB(t)()
+	vaultRoot := t.TempDir()
+	t.Setenv("NOTED_VAULT", vaultRoot)
+	ctx := context.Background()
+
+	createTestNote(t, "Tips", "x", []string{"golang"})
+	createTestNote(t, "More", "x", []string{"golang", "go"})
+	if err := tagsRenameCmd.RunE(tagsRenameCmd, []string{"golang", "go"}); err != nil {
+		t.Fatalf("tags rename: %v", err)
+	}
+	tags, _ := database.GetTagsWithCount(ctx)
+	if len(tags) != 1 || tags[0].Name != "go" || tags[0].NoteCount != 2 {
+		t.Errorf("tags = %+v, want only go on 2 notes", tags)
+	}
+	// The renamed tag reaches the vault file
+	data, err := os.ReadFile(filepath.Join(vaultRoot, "tips.md"))
+	if err != nil || !strings.Contains(string(data), "- go") || strings.Contains(string(data), "golang") {
+		t.Errorf("vault file = %q, %v", data, err)
+	}
+
+	if err := tagsRenameCmd.RunE(tagsRenameCmd, []string{"missing", "x"}); !errors.Is(err, db.ErrTagNotFound) {
+		t.Errorf("rename of unknown tag = %v, want ErrTagNotFound", err)
+	}
+}
+
 func TestDatabaseGetAllNotes(t *testing.T) {
 	cleanup := setupTestDB(t)
 	defer cleanup()
@@ -312,95 +417,66 @@ func TestDatabaseGetAllNotes(t *testing.T) {
 // Helper Function Tests
 // ============================================================================
 
-func TestParseMarkdownFile(t *testing.T) {
-	tmpDir := t.TempDir()
+func TestGetEditor(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake editors are shell scripts")
+	}
+	// A PATH holding only fake editors makes the fallback chain deterministic
+	bin := t.TempDir()
+	for _, name := range []string{"vim", "nano", "code"} {
+		if err := os.WriteFile(filepath.Join(bin, name), []byte("#!/bin/sh\n"), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Setenv("PATH", bin)
+	t.Setenv("NOTED_EDITOR", "")
+	t.Setenv("VISUAL", "")
+	t.Setenv("EDITOR", "")
 
-	tests := []struct {
-		name        string
-		content     string
-		wantTitle   string
-		wantTags    []string
-		wantContent string
-	}{
-		{
-			name: "with frontmatter",
-			content: `---
-title: "My Title"
-tags: [go, testing]
----
-
-Content here`,
-			wantTitle:   "My Title",
-			wantTags:    []string{"go", "testing"},
-			wantContent: "Content here",
-		},
-		{
-			name:        "with H1 heading",
-			content:     "# Heading Title\n\nSome content",
-			wantTitle:   "Heading Title",
-			wantTags:    nil,
-			wantContent: "# Heading Title\n\nSome content",
-		},
-		{
-			name:        "plain content",
-			content:     "Just plain content\nNo heading",
-			wantTitle:   "", // Will use filename
-			wantTags:    nil,
-			wantContent: "Just plain content\nNo heading",
-		},
+	check := func(want ...string) {
+		t.Helper()
+		got, err := getEditor()
+		if err != nil || !slices.Equal(got, want) {
+			t.Errorf("expected %v, got %v (err %v)", want, got, err)
+		}
 	}
 
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			filename := "test.md"
-			if tt.wantTitle == "" {
-				filename = "expected-title.md"
-			}
-			mdFile := filepath.Join(tmpDir, filename)
-			if err := os.WriteFile(mdFile, []byte(tt.content), 0644); err != nil {
-				t.Fatalf("failed to write test file: %v", err)
-			}
+	// Nothing configured: first fallback on PATH (nvim is missing)
+	check("vim")
 
-			title, content, tags, err := parseMarkdownFile(mdFile)
-			if err != nil {
-				t.Fatalf("unexpected error: %v", err)
-			}
+	t.Setenv("EDITOR", "nano")
+	check("nano")
 
-			expectedTitle := tt.wantTitle
-			if expectedTitle == "" {
-				expectedTitle = "expected-title"
-			}
+	t.Setenv("VISUAL", "vim")
+	check("vim")
 
-			if title != expectedTitle {
-				t.Errorf("title: expected %q, got %q", expectedTitle, title)
-			}
+	t.Setenv("NOTED_EDITOR", "code --wait")
+	check("code", "--wait")
 
-			if len(tags) != len(tt.wantTags) {
-				t.Errorf("tags: expected %v, got %v", tt.wantTags, tags)
-			}
+	t.Setenv("NOTED_EDITOR", "missing-editor")
+	if _, err := getEditor(); err == nil || !strings.Contains(err.Error(), "NOTED_EDITOR") {
+		t.Errorf("expected an error naming NOTED_EDITOR, got %v", err)
+	}
 
-			if !strings.Contains(content, strings.Split(tt.wantContent, "\n")[0]) {
-				t.Errorf("content should contain %q", tt.wantContent)
-			}
-		})
+	t.Setenv("NOTED_EDITOR", "")
+	t.Setenv("VISUAL", "")
+	t.Setenv("EDITOR", "")
+	t.Setenv("PATH", t.TempDir())
+	if _, err := getEditor(); err == nil || !strings.Contains(err.Error(), "no editor found") {
+		t.Errorf("expected a no-editor error, got %v", err)
 	}
 }
 
-func TestGetEditor(t *testing.T) {
-	// Save original
-	original := os.Getenv("EDITOR")
-	t.Cleanup(func() { _ = os.Setenv("EDITOR", original) })
-
-	// Test with EDITOR set
-	_ = os.Setenv("EDITOR", "vim")
-	if editor := getEditor(); editor != "vim" {
-		t.Errorf("expected 'vim', got %q", editor)
+func TestSplitCommandLine(t *testing.T) {
+	got, err := splitCommandLine(`"C:\Program Files\Code\code.exe" --wait  -n`)
+	if err != nil || !slices.Equal(got, []string{`C:\Program Files\Code\code.exe`, "--wait", "-n"}) {
+		t.Errorf("got %q, %v", got, err)
 	}
-
-	// Test with EDITOR unset
-	_ = os.Unsetenv("EDITOR")
-	if editor := getEditor(); editor != "nvim" {
-		t.Errorf("expected 'nvim' as default, got %q", editor)
+	if _, err := splitCommandLine(`code "--wait`); err == nil {
+		t.Error("expected an error for an unterminated quote")
+	}
+	if _, err := splitCommandLine("   "); err == nil {
+		t.Error("expected an error for an empty command")
 	}
 }
 
@@ -451,7 +527,7 @@ func TestExportMarkdown(t *testing.T) {
 	notes, _ := database.GetAllNotes(ctx)
 
 	var buf strings.Builder
-	err := exportMarkdown(ctx, &buf, notes)
+	err := exportMarkdown(ctx, &buf, notes, false, exportTemplates{})
 	if err != nil {
 		t.Fatalf("exportMarkdown failed: %v", err)
 	}
@@ -472,6 +548,446 @@ func TestExportMarkdown(t *testing.T) {
 	}
 }
 
+func TestExportBacklinks(t *testing.T) {
+	defer setupTestDB(t)()
+	ctx := context.Background()
+
+	target := createTestNote(t, "Target", "Linked to", nil)
+	source := createTestNote(t, "Source Note", "See [[Target]]", nil)
+	outside := createTestNote(t, "Outside", "Also [[Target]]", nil)
+	for _, from := range []int64{source, outside} {
+		_ = database.CreateNoteLink(ctx, db.CreateNoteLinkParams{SourceNoteID: from, TargetNoteID: target, LinkText: "Target"})
+	}
+	t1, _ := database.GetNote(ctx, target)
+	s1, _ := database.GetNote(ctx, source)
+	notes := []db.Note{t1, s1}
+
+	var buf bytes.Buffer
+	if err := exportZip(ctx, &buf, notes, t.TempDir(), true, exportTemplates{}); err != nil {
+		t.Fatalf("exportZip: %v", err)
+	}
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := zr.Open("target.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := io.ReadAll(r)
+	md := string(data)
+	if !strings.Contains(md, "## Backlinks\n\n") || !strings.Contains(md, "- [Source Note](source-note.md)") || !strings.Contains(md, "- [[Outside]]") {
+		t.Errorf("target.md backlinks:\n%s", md)
+	}
+
+	var single strings.Builder
+	if err := exportMarkdown(ctx, &single, notes, true, exportTemplates{}); err != nil {
+		t.Fatalf("exportMarkdown: %v", err)
+	}
+	if !strings.Contains(single.String(), "Linked to\n\n## Backlinks\n\n- [[Source Note]]\n- [[Outside]]") && !strings.Contains(single.String(), "Linked to\n\n## Backlinks\n\n- [[Outside]]\n- [[Source Note]]") {
+		t.Errorf("markdown backlinks:\n%s", single.String())
+	}
+	if strings.Count(single.String(), "## Backlinks") != 1 {
+		t.Error("only the linked note should get a Backlinks section")
+	}
+}
+
+func TestExportDirChangedOnly(t *testing.T) {
+	defer setupTestDB(t)()
+	ctx := context.Background()
+	t.Setenv("NOTED_VAULT", t.TempDir())
+
+	keep := createTestNote(t, "Keep", "unchanged", nil)
+	edit := createTestNote(t, "Edit", "before", nil)
+	gone := createTestNote(t, "Gone", "to be deleted", nil)
+
+	out := t.TempDir()
+	_ = exportCmd.Flags().Set("output", out)
+	defer func() {
+		_ = exportCmd.Flags().Set("output", "")
+		_ = exportCmd.Flags().Set("changed-only", "false")
+	}()
+	// An existing directory as -o picks the dir format
+	if err := exportCmd.RunE(exportCmd, nil); err != nil {
+		t.Fatalf("export: %v", err)
+	}
+	for _, name := range []string{"keep.md", "edit.md", "gone.md"} {
+		if _, err := os.Stat(filepath.Join(out, name)); err != nil {
+			t.Errorf("first export should write %s: %v", name, err)
+		}
+	}
+
+	// A file the next export shouldn't touch, since its note hasn't changed
+	_ = os.WriteFile(filepath.Join(out, "keep.md"), []byte("---\nid: "+strconv.FormatInt(keep, 10)+"\ntitle: Keep\n---\n\nlocal copy\n"), 0o644)
+	_, _ = database.UpdateNote(ctx, db.UpdateNoteParams{ID: edit, Title: "Edited", Content: "after"})
+	_, _ = database.TrashNote(ctx, gone)
+
+	_ = exportCmd.Flags().Set("changed-only", "true")
+	if err := exportCmd.RunE(exportCmd, nil); err != nil {
+		t.Fatalf("export --changed-only: %v", err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(out, "keep.md")); !strings.Contains(string(data), "local copy") {
+		t.Errorf("unchanged note was rewritten:\n%s", data)
+	}
+	// The renamed note is rewritten in its old file rather than a new one
+	if data, _ := os.ReadFile(filepath.Join(out, "edit.md")); !strings.Contains(string(data), "title: Edited") || !strings.Contains(string(data), "after") {
+		t.Errorf("edited note not rewritten:\n%s", data)
+	}
+	if _, err := os.Stat(filepath.Join(out, "edited.md")); err == nil {
+		t.Error("renamed note got a second file")
+	}
+	if _, err := os.Stat(filepath.Join(out, "gone.md")); !os.IsNotExist(err) {
+		t.Errorf("deleted note's file should be removed, stat err = %v", err)
+	}
+
+	// A new tag changes the file but not updated_at, and is still exported
+	tag, _ := database.CreateTag(ctx, "mirrored")
+	_ = database.AddTagToNote(ctx, db.AddTagToNoteParams{NoteID: keep, TagID: tag.ID})
+	if err := exportCmd.RunE(exportCmd, nil); err != nil {
+		t.Fatalf("export --changed-only: %v", err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(out, "keep.md")); !strings.Contains(string(data), "mirrored") {
+		t.Errorf("retagged note not rewritten:\n%s", data)
+	}
+
+	// So does a different layout
+	layout := filepath.Join(t.TempDir(), "layout.tmpl")
+	_ = os.WriteFile(layout, []byte("# {{.Title}}\n"), 0o644)
+	_ = exportCmd.Flags().Set("layout", layout)
+	defer func() { _ = exportCmd.Flags().Set("layout", "") }()
+	if err := exportCmd.RunE(exportCmd, nil); err != nil {
+		t.Fatalf("export --changed-only --layout: %v", err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(out, "edit.md")); string(data) != "# Edited\n" {
+		t.Errorf("new layout not applied:\n%s", data)
+	}
+
+	_ = exportCmd.Flags().Set("output", "")
+	if err := exportCmd.RunE(exportCmd, nil); err == nil {
+		t.Error("--changed-only without -o should fail")
+	}
+}
+
+func TestExportLayoutAndFilename(t *testing.T) {
+	defer setupTestDB(t)()
+	ctx := context.Background()
+	vaultRoot := t.TempDir()
+	t.Setenv("NOTED_VAULT", vaultRoot)
+	_ = os.WriteFile(filepath.Join(vaultRoot, "chart.png"), []byte("png"), 0o644)
+
+	id := createTestNote(t, "Hello World", "See ![chart](chart.png)", []string{"go", "web"})
+	created := time.Date(2025, 3, 9, 10, 0, 0, 0, time.UTC)
+	_, _ = conn.ExecContext(ctx, "UPDATE notes SET created_at = ? WHERE id = ?", created, id)
+
+	layout := filepath.Join(t.TempDir(), "hugo.tmpl")
+	_ = os.WriteFile(layout, []byte("---\ntitle: {{quote .Title}}\ndate: {{.CreatedAt.Format \"2006-01-02\"}}\ntags: [{{join .Tags \", \"}}]\n---\n{{.Content}}\n"), 0o644)
+	out := t.TempDir()
+	for name, value := range map[string]string{"output": out, "format": "dir", "layout": layout, "filename": `{{.CreatedAt.Format "2006/01"}}/{{.Slug}}.md`} {
+		_ = exportCmd.Flags().Set(name, value)
+	}
+	defer func() {
+		for name, value := range map[string]string{"output": "", "format": "markdown", "layout": "", "filename": ""} {
+			_ = exportCmd.Flags().Set(name, value)
+		}
+	}()
+	if err := exportCmd.RunE(exportCmd, nil); err != nil {
+		t.Fatalf("export: %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(out, "2025", "03", "hello-world.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sum := sha256.Sum256([]byte("png"))
+	want := "---\ntitle: \"Hello World\"\ndate: 2025-03-09\ntags: [go, web]\n---\nSee ![chart](../../assets/" + hex.EncodeToString(sum[:])[:16] + ".png)\n"
+	if string(data) != want {
+		t.Errorf("file = %q, want %q", data, want)
+	}
+
+	// A rename moves the file the pattern names
+	_, _ = database.UpdateNote(ctx, db.UpdateNoteParams{ID: id, Title: "Hi", Content: "short"})
+	if err := exportCmd.RunE(exportCmd, nil); err != nil {
+		t.Fatalf("export: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(out, "2025", "03", "hi.md")); err != nil {
+		t.Errorf("renamed note not written: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(out, "2025", "03", "hello-world.md")); !os.IsNotExist(err) {
+		t.Errorf("old file should be removed, stat err = %v", err)
+	}
+
+	_ = exportCmd.Flags().Set("format", "json")
+	if err := exportCmd.RunE(exportCmd, nil); err == nil {
+		t.Error("--filename with json should fail")
+	}
+}
+
+func TestPublish(t *testing.T) {
+	defer setupTestDB(t)()
+	ctx := context.Background()
+	vaultRoot := t.TempDir()
+	t.Setenv("NOTED_VAULT", vaultRoot)
+	_ = os.WriteFile(filepath.Join(vaultRoot, "chart.png"), []byte("png"), 0o644)
+
+	createTestNote(t, "Go Tips", "Use ![[chart.png]], see [[Web Notes#Setup Steps|setup]] and [[Private]].", []string{"blog", "go"})
+	web := createTestNote(t, "Web Notes", "## Setup Steps", []string{"blog", "draft"})
+	createTestNote(t, "Private", "not published", nil)
+
+	site := t.TempDir()
+	target := filepath.Join(site, "content", "posts")
+	_ = publishCmd.Flags().Set("tag", "blog")
+	_ = publishCmd.Flags().Set("target", target)
+	defer func() {
+		_ = publishCmd.Flags().Set("tag", "")
+		_ = publishCmd.Flags().Set("target", "")
+	}()
+	if err := publishCmd.RunE(publishCmd, nil); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(target, "go-tips.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := string(data)
+	sum := sha256.Sum256([]byte("png"))
+	asset := "assets/" + hex.EncodeToString(sum[:])[:16] + ".png"
+	for _, want := range []string{
+		"title: \"Go Tips\"\n",
+		"slug: \"go-tips\"\n",
+		"draft: false\n",
+		"tags: [\"go\"]\n",
+		"Use ![](/" + asset + ")",
+		"see [setup](/posts/web-notes/#setup-steps) and Private.",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("go-tips.md missing %q:\n%s", want, got)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(site, "static", filepath.FromSlash(asset))); err != nil {
+		t.Errorf("attachment not copied to static/: %v", err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(target, "web-notes.md")); !strings.Contains(string(data), "draft: true") || strings.Contains(string(data), "tags:") {
+		t.Errorf("web-notes.md:\n%s", data)
+	}
+	if _, err := os.Stat(filepath.Join(target, "private.md")); !os.IsNotExist(err) {
+		t.Error("untagged note was published")
+	}
+
+	// Unpublishing a note removes its page on the next publish
+	_, _ = database.TrashNote(ctx, web)
+	if err := publishCmd.RunE(publishCmd, nil); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(target, "web-notes.md")); !os.IsNotExist(err) {
+		t.Errorf("trashed note's page should be removed, stat err = %v", err)
+	}
+
+	_ = publishCmd.Flags().Set("target", t.TempDir())
+	if err := publishCmd.RunE(publishCmd, nil); err == nil {
+		t.Error("a target outside content/ without --static should fail")
+	}
+}
+
+func TestShowHTML(t *testing.T) {
+	defer setupTestDB(t)()
+	ctx := context.Background()
+	vaultRoot := t.TempDir()
+	_ = os.WriteFile(filepath.Join(vaultRoot, "chart.png"), []byte("png"), 0o644)
+
+	createTestNote(t, "Design Doc", "## Open Questions\n", nil)
+	createTestNote(t, "Budget", "Spend **less**.", nil)
+	id := createTestNote(t, "Plan", "See [[Design Doc#Open Questions|questions]], [[Missing]] and ![[chart.png]].\n\n![[Budget]]\n\n<iframe src=x></iframe>", []string{"work"})
+	note, _ := database.GetNote(ctx, id)
+
+	page, err := noteHTML(ctx, note, vaultRoot)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"<title>Plan</title>",
+		"#work</p>",
+		`<a href="design-doc.html#open-questions">questions</a>, Missing and <img src="` + filepath.ToSlash(vaultRoot) + `/chart.png"`,
+		"<p>Spend <strong>less</strong>.</p>", // embeds are expanded
+		"@media print",
+	} {
+		if !strings.Contains(page, want) {
+			t.Errorf("page missing %q:\n%s", want, page)
+		}
+	}
+	if strings.Contains(page, "<iframe") {
+		t.Errorf("raw HTML should be left out:\n%s", page)
+	}
+}
+
+func TestShareMessageAndMailto(t *testing.T) {
+	html, err := renderNotePage("Q3 <plan>", "Ask [[Ana]] about **scope**", "", noLinks)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(html, "<title>Q3 &lt;plan&gt;</title>") || !strings.Contains(html, "<p>Ask Ana about <strong>scope</strong></p>") {
+		t.Errorf("page:\n%s", html)
+	}
+
+	raw, err := shareMessage("me@example.com", []string{"Ana <ana@example.com>"}, "Café plan", "Café plan\n\nAsk Ana", html, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg, err := mail.ReadMessage(bytes.NewReader(raw))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if subject, _ := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject")); subject != "Café plan" {
+		t.Errorf("Subject = %q", subject)
+	}
+	_, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var types []string
+	mr := multipart.NewReader(msg.Body, params["boundary"])
+	for {
+		part, err := mr.NextPart()
+		if err != nil {
+			break
+		}
+		body, _ := io.ReadAll(part) // multipart decodes quoted-printable parts
+		types = append(types, part.Header.Get("Content-Type"))
+		if strings.HasPrefix(part.Header.Get("Content-Type"), "text/plain") && string(body) != "Café plan\r\n\r\nAsk Ana" {
+			t.Errorf("text part = %q", body)
+		}
+	}
+	if len(types) != 2 || !strings.HasPrefix(types[1], "text/html") {
+		t.Errorf("parts = %v", types)
+	}
+
+	got := mailtoURL([]string{"ana@example.com"}, "Q3 plan", "a & b\nc")
+	if want := "mailto:ana%40example.com?subject=Q3%20plan&body=a%20%26%20b%0D%0Ac"; got != want {
+		t.Errorf("mailtoURL = %q, want %q", got, want)
+	}
+}
+
+func TestBackupCmd(t *testing.T) {
+	defer setupTestDB(t)()
+	createTestNote(t, "Backed up", "content", nil)
+
+	path := filepath.Join(t.TempDir(), "snapshot.db")
+	if err := backupCmd.RunE(backupCmd, []string{path}); err != nil {
+		t.Fatalf("backup: %v", err)
+	}
+	if info, err := os.Stat(path); err != nil || info.Size() == 0 {
+		t.Fatalf("backup file: %v", err)
+	}
+	if err := backupCmd.RunE(backupCmd, []string{path}); err == nil {
+		t.Error("backing up over an existing file should fail")
+	}
+}
+
+func TestFolderTree(t *testing.T) {
+	defer setupTestDB(t)()
+	ctx := context.Background()
+
+	folder := func(name string, parent int64) int64 {
+		params := db.CreateFolderParams{Name: name}
+		if parent != 0 {
+			params.ParentID = sql.NullInt64{Int64: parent, Valid: true}
+		}
+		f, err := database.CreateFolder(ctx, params)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return f.ID
+	}
+	noteIn := func(folderID int64) int64 {
+		id := createTestNote(t, "Note", "content", nil)
+		_ = database.MoveNoteToFolder(ctx, db.MoveNoteToFolderParams{FolderID: sql.NullInt64{Int64: folderID, Valid: true}, ID: id})
+		return id
+	}
+	work := folder("Work", 0)
+	projects := folder("Projects", work)
+	alpha := folder("Alpha", projects)
+	folder("Personal", 0)
+	noteIn(work)
+	noteIn(projects)
+	_, _ = database.ArchiveNote(ctx, noteIn(projects))
+	noteIn(alpha)
+	_, _ = database.TrashNote(ctx, noteIn(alpha))
+
+	rows, err := database.FolderTree(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tree := buildFolderTree(rows)
+	if len(tree) != 2 || tree[0].Name != "Personal" || tree[1].Name != "Work" {
+		t.Fatalf("roots = %+v", tree)
+	}
+	w := tree[1]
+	if w.NoteCount != 1 || w.TotalCount != 3 || len(w.Children) != 1 {
+		t.Errorf("Work = %+v", w)
+	}
+	p := w.Children[0]
+	if p.Name != "Projects" || p.NoteCount != 1 || p.TotalCount != 2 || len(p.Children) != 1 || p.Children[0].TotalCount != 1 {
+		t.Errorf("Projects = %+v", p)
+	}
+	if tree[0].TotalCount != 0 || tree[0].Children == nil {
+		t.Errorf("Personal = %+v", tree[0])
+	}
+}
+
+func TestFilterExportNotes(t *testing.T) {
+	cleanup := setupTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	folder, err := database.CreateFolder(ctx, db.CreateFolderParams{Name: "Work"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	roadmap := createTestNote(t, "Roadmap", "launch plan", nil)
+	pinnedID := createTestNote(t, "Pinned idea", "something", nil)
+	createTestNote(t, "Groceries", "milk", []string{"launch"})
+
+	_ = database.MoveNoteToFolder(ctx, db.MoveNoteToFolderParams{FolderID: sql.NullInt64{Int64: folder.ID, Valid: true}, ID: roadmap})
+	_ = database.MoveNoteToFolder(ctx, db.MoveNoteToFolderParams{FolderID: sql.NullInt64{Int64: folder.ID, Valid: true}, ID: pinnedID})
+	_ = database.PinNote(ctx, pinnedID)
+
+	notes, _ := database.GetAllNotes(ctx)
+
+	tests := []struct {
+		name   string
+		filter exportFilter
+		want   int
+	}{
+		{"none", exportFilter{}, 3},
+		{"folder", exportFilter{folderID: folder.ID, hasFolder: true}, 2},
+		{"pinned", exportFilter{pinned: true}, 1},
+		{"query matches content and tags", exportFilter{query: "launch"}, 2},
+		{"folder and query", exportFilter{folderID: folder.ID, hasFolder: true, query: "launch"}, 1},
+		{"folder and pinned", exportFilter{folderID: folder.ID, hasFolder: true, pinned: true}, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := filterExportNotes(ctx, notes, tt.filter)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(got) != tt.want {
+				t.Errorf("got %d notes, want %d", len(got), tt.want)
+			}
+		})
+	}
+
+	// A tagged candidate that only matches by tag ranks below the title hit, yet is still kept
+	createTestNote(t, "Launch day", "launch party", nil)
+	candidates, _ := database.GetNotesByTagName(ctx, "launch")
+	got, err := filterExportNotes(ctx, candidates, exportFilter{query: "launch"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0].Title != "Groceries" {
+		t.Errorf("expected the tagged note to match, got %v", got)
+	}
+}
+
 // ============================================================================
 // Edge Cases
 // ============================================================================
@@ -868,29 +1384,208 @@ func TestExtractTasks(t *testing.T) {
 	}
 }
 
-// ============================================================================
-// Version History Tests
-// ============================================================================
+func TestTodoDone(t *testing.T) {
+	defer setupTestDB(t)()
+	t.Setenv("NOTED_VAULT", t.TempDir())
+	ctx := context.Background()
 
-func TestNoteVersioning(t *testing.T) {
-	cleanup := setupTestDB(t)
-	defer cleanup()
+	id := createTestNote(t, "Todo", "- [ ] one\n- [ ] two", nil)
+	ref := strconv.FormatInt(id, 10) + ":2"
 
-	ctx := context.Background()
-	noteID := createTestNote(t, "Original", "Original content", nil)
+	if err := todoDoneCmd.RunE(todoDoneCmd, []string{ref}); err != nil {
+		t.Fatalf("todo done: %v", err)
+	}
+	if note, _ := database.GetNote(ctx, id); note.Content != "- [ ] one\n- [x] two" {
+		t.Errorf("content = %q", note.Content)
+	}
 
-	// Create version 1
-	_, err := database.CreateNoteVersion(ctx, db.CreateNoteVersionParams{
-		NoteID:        noteID,
-		Title:         "Original",
-		Content:       "Original content",
-		VersionNumber: 1,
-	})
-	if err != nil {
-		t.Fatalf("failed to create version: %v", err)
+	_ = todoDoneCmd.Flags().Set("undo", "true")
+	defer func() { _ = todoDoneCmd.Flags().Set("undo", "false") }()
+	if err := todoDoneCmd.RunE(todoDoneCmd, []string{ref}); err != nil {
+		t.Fatalf("todo done --undo: %v", err)
+	}
+	if note, _ := database.GetNote(ctx, id); note.Content != "- [ ] one\n- [ ] two" {
+		t.Errorf("content after undo = %q", note.Content)
 	}
 
-	// Create version 2
+	for _, bad := range []string{"42", "x:1", "42:0", strconv.FormatInt(id, 10) + ":9"} {
+		if err := todoDoneCmd.RunE(todoDoneCmd, []string{bad}); err == nil {
+			t.Errorf("expected an error for %q", bad)
+		}
+	}
+}
+
+func TestAgenda(t *testing.T) {
+	defer setupTestDB(t)()
+	ctx := context.Background()
+
+	now := time.Now()
+	day := func(n int) string { return now.AddDate(0, 0, n).Format(time.DateOnly) }
+	plan := createTestNote(t, "Plan", "- [ ] late @due("+day(-2)+")\n- [x] done @due("+day(-1)+")\n- [ ] today @due("+day(0)+")\n- [ ] later @due("+day(30)+")", nil)
+	review := createTestNote(t, "Review", "Finish by @due("+day(3)+")", nil)
+
+	items, err := loadAgenda(ctx, now, 7)
+	if err != nil {
+		t.Fatalf("loadAgenda failed: %v", err)
+	}
+	if len(items) != 3 {
+		t.Fatalf("agenda = %+v", items)
+	}
+	if items[0].Text != "late @due("+day(-2)+")" || !items[0].Overdue || items[0].NoteID != plan || items[0].Index != 1 {
+		t.Errorf("first item = %+v", items[0])
+	}
+	if items[1].Due != day(0) || items[1].Overdue {
+		t.Errorf("second item = %+v", items[1])
+	}
+	if items[2].NoteID != review || items[2].Index != 0 || items[2].Text != "Review" {
+		t.Errorf("note-level item = %+v", items[2])
+	}
+
+	var sent []string
+	orig := desktopNotify
+	desktopNotify = func(title, body string) error {
+		sent = append(sent, title+": "+body)
+		return nil
+	}
+	defer func() { desktopNotify = orig }()
+	seen := map[string]bool{}
+	if n, err := notifyDue(items, seen); err != nil || n != 2 {
+		t.Errorf("notifyDue = %d, %v; sent %q", n, err, sent)
+	}
+	if n, _ := notifyDue(items, seen); n != 0 {
+		t.Errorf("items should be notified once, sent %d again", n)
+	}
+}
+
+func TestTodoICS(t *testing.T) {
+	defer setupTestDB(t)()
+	t.Setenv("NOTED_VAULT", t.TempDir())
+	ctx := context.Background()
+
+	id := createTestNote(t, "Plan", "- [ ] send draft, v2 @due(2025-07-01)\n- [x] book room @due(2025-06-01)\n- [ ] no date", nil)
+	notes, _ := database.GetAllNotes(ctx)
+	todos := icsTodos(notes)
+	if len(todos) != 2 || todos[0].Summary != "send draft, v2" || todos[1].Done != true {
+		t.Fatalf("icsTodos = %+v", todos)
+	}
+
+	var buf bytes.Buffer
+	if err := writeICS(&buf, todos, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	ics := buf.String()
+	for _, want := range []string{"BEGIN:VTODO\r\n", "SUMMARY:send draft\\, v2\r\n", "DUE;VALUE=DATE:20250701\r\n", "STATUS:COMPLETED\r\n"} {
+		if !strings.Contains(ics, want) {
+			t.Errorf("ics is missing %q:\n%s", want, ics)
+		}
+	}
+
+	// Complete the first task in the "app", and move it down in noted meanwhile
+	edited := strings.Replace(ics, "STATUS:NEEDS-ACTION", "STATUS:COMPLETED", 1)
+	_, _ = database.UpdateNote(ctx, db.UpdateNoteParams{ID: id, Title: "Plan", Content: "- [ ] new first\n" + notes[0].Content})
+	back, err := readICS(strings.NewReader(edited))
+	if err != nil || len(back) != 2 || !back[0].Done {
+		t.Fatalf("readICS = %+v, %v", back, err)
+	}
+	res, err := importICSTodos(ctx, todoICSImportCmd, back)
+	if err != nil {
+		t.Fatalf("importICSTodos failed: %v", err)
+	}
+	if len(res.Updated) != 1 || res.Updated[0].Index != 2 {
+		t.Errorf("import = %+v", res)
+	}
+	if note, _ := database.GetNote(ctx, id); !strings.Contains(note.Content, "- [x] send draft, v2") || !strings.Contains(note.Content, "- [ ] new first") {
+		t.Errorf("content = %q", note.Content)
+	}
+}
+
+func TestIngestEmail(t *testing.T) {
+	defer setupTestDB(t)()
+	vaultPath := t.TempDir()
+	t.Setenv("NOTED_VAULT", vaultPath)
+	ctx := context.Background()
+
+	raw := "From: Alice <alice@example.com>\r\n" +
+		"Subject: =?UTF-8?Q?Caf=C3=A9_ideas?=\r\n" +
+		"Date: Mon, 5 Oct 2026 09:00:00 +0000\r\n" +
+		"Message-ID: <abc123@example.com>\r\n" +
+		"MIME-Version: 1.0\r\n" +
+		"Content-Type: multipart/mixed; boundary=outer\r\n\r\n" +
+		"--outer\r\n" +
+		"Content-Type: multipart/alternative; boundary=inner\r\n\r\n" +
+		"--inner\r\n" +
+		"Content-Type: text/plain; charset=utf-8\r\n" +
+		"Content-Transfer-Encoding: quoted-printable\r\n\r\n" +
+		"Open a caf=C3=A9 with a long=\r\n counter.\r\n" +
+		"--inner\r\n" +
+		"Content-Type: text/html\r\n\r\n" +
+		"<p>ignored</p>\r\n" +
+		"--inner--\r\n" +
+		"--outer\r\n" +
+		"Content-Type: image/png; name=\"sketch.png\"\r\n" +
+		"Content-Disposition: attachment; filename=\"sketch.png\"\r\n" +
+		"Content-Transfer-Encoding: base64\r\n\r\n" +
+		"iVBORw0K\r\nGgo=\r\n" +
+		"--outer--\r\n"
+
+	em, err := parseEmail(strings.NewReader(raw))
+	if err != nil {
+		t.Fatalf("parseEmail failed: %v", err)
+	}
+	if em.Subject != "Café ideas" || em.MessageID != "abc123@example.com" || em.Body != "Open a café with a long counter." {
+		t.Errorf("parsed = %+v", em)
+	}
+	if len(em.Attachments) != 1 || em.Attachments[0].Name != "sketch.png" || string(em.Attachments[0].Data) != "\x89PNG\r\n\x1a\n" {
+		t.Fatalf("attachments = %+v", em.Attachments)
+	}
+
+	res, err := ingestEmail(ctx, ingestEmailCmd, em, []string{"inbox"})
+	if err != nil {
+		t.Fatalf("ingestEmail failed: %v", err)
+	}
+	note, _ := database.GetNote(ctx, res.ID)
+	if note.Title != "Café ideas" || note.Source.String != emailSource || note.SourceRef.String != "abc123@example.com" {
+		t.Errorf("note = %+v", note)
+	}
+	if !strings.Contains(note.Content, "**From:** Alice <alice@example.com>") || !strings.Contains(note.Content, "- ![sketch.png]("+res.Attachments[0]+")") {
+		t.Errorf("content = %q", note.Content)
+	}
+	if _, err := os.Stat(filepath.Join(vaultPath, filepath.FromSlash(res.Attachments[0]))); err != nil {
+		t.Errorf("attachment not stored: %v", err)
+	}
+	if tags, _ := database.GetTagsForNote(ctx, res.ID); len(tags) != 1 || tags[0].Name != "inbox" {
+		t.Errorf("tags = %v", tags)
+	}
+
+	again, err := ingestEmail(ctx, ingestEmailCmd, em, nil)
+	if err != nil || !again.Skipped || again.ID != res.ID {
+		t.Errorf("second ingest = %+v, %v", again, err)
+	}
+}
+
+// ============================================================================
+// Version History Tests
+// ============================================================================
+
+func TestNoteVersioning(t *testing.T) {
+	cleanup := setupTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	noteID := createTestNote(t, "Original", "Original content", nil)
+
+	// Create version 1
+	_, err := database.CreateNoteVersion(ctx, db.CreateNoteVersionParams{
+		NoteID:        noteID,
+		Title:         "Original",
+		Content:       "Original content",
+		VersionNumber: 1,
+	})
+	if err != nil {
+		t.Fatalf("failed to create version: %v", err)
+	}
+
+	// Create version 2
 	_, err = database.CreateNoteVersion(ctx, db.CreateNoteVersionParams{
 		NoteID:        noteID,
 		Title:         "Updated",
@@ -1034,6 +1729,131 @@ func TestEditCmdCreatesVersion(t *testing.T) {
 	}
 }
 
+func TestNoteQuota(t *testing.T) {
+	defer setupTestDB(t)()
+	t.Setenv("NOTED_VAULT", t.TempDir())
+	t.Setenv("NOTED_NOTE_SIZE_MAX", "1KB")
+	t.Setenv("NOTED_DB_SIZE_MAX", "1")
+	ctx := context.Background()
+
+	if err := checkNoteQuota(ctx, editCmd, strings.Repeat("x", 2048), false); !errors.Is(err, quota.ErrExceeded) {
+		t.Errorf("oversized note: err = %v", err)
+	}
+	if err := checkNoteQuota(ctx, addCmd, "small", true); !errors.Is(err, quota.ErrExceeded) {
+		t.Errorf("new note over the database limit: err = %v", err)
+	}
+	if err := checkNoteQuota(ctx, editCmd, "small", false); err != nil {
+		t.Errorf("edits are not held to the database limit: %v", err)
+	}
+}
+
+func TestSessionLogCmd(t *testing.T) {
+	defer setupTestDB(t)()
+	t.Setenv("NOTED_VECLITE_PATH", filepath.Join(t.TempDir(), "vectors.veclite"))
+	ctx := context.Background()
+
+	createTestNote(t, "Importer", "rewritten", nil)
+	defer func() {
+		_ = sessionLogCmd.Flags().Set("since", "")
+		sessionLogCmd.Flags().Lookup("since").Changed = false
+	}()
+	_ = sessionLogCmd.Flags().Set("since", "1h")
+	if err := sessionLogCmd.RunE(sessionLogCmd, []string{"Rewrote the importer"}); err != nil {
+		t.Fatalf("session log: %v", err)
+	}
+
+	logs, _ := database.GetNotesByTagName(ctx, "memory:project")
+	if len(logs) != 1 || logs[0].Source.String != "session" || !strings.Contains(logs[0].Content, "- [[Importer]]") {
+		t.Errorf("session logs = %+v", logs)
+	}
+}
+
+func TestEditCmdFolderAndPin(t *testing.T) {
+	defer setupTestDB(t)()
+	t.Setenv("NOTED_VAULT", t.TempDir())
+	ctx := context.Background()
+
+	id := createTestNote(t, "Doc", "body", nil)
+	folder, _ := database.CreateFolder(ctx, db.CreateFolderParams{Name: "Work"})
+	for _, f := range []string{"folder", "pinned"} {
+		defer func() { editCmd.Flags().Lookup(f).Changed = false }()
+	}
+	_ = editCmd.Flags().Set("folder", strconv.FormatInt(folder.ID, 10))
+	_ = editCmd.Flags().Set("pinned", "true")
+	_ = editCmd.Flags().Set("content", "body")
+	if err := editCmd.RunE(editCmd, []string{strconv.FormatInt(id, 10)}); err != nil {
+		t.Fatalf("edit: %v", err)
+	}
+	note, _ := database.GetNote(ctx, id)
+	if note.FolderID.Int64 != folder.ID || !note.Pinned.Bool || note.Title != "Doc" {
+		t.Errorf("note = %+v", note)
+	}
+}
+
+func TestAddCmdFolderAndPinned(t *testing.T) {
+	defer setupTestDB(t)()
+	vaultRoot := t.TempDir()
+	t.Setenv("NOTED_VAULT", vaultRoot)
+	ctx := context.Background()
+
+	folder, _ := database.CreateFolder(ctx, db.CreateFolderParams{Name: "Work"})
+	for _, f := range []string{"title", "content", "folder", "pinned"} {
+		defer func() {
+			_ = addCmd.Flags().Set(f, addCmd.Flags().Lookup(f).DefValue)
+			addCmd.Flags().Lookup(f).Changed = false
+		}()
+	}
+	_ = addCmd.Flags().Set("title", "Runbook")
+	_ = addCmd.Flags().Set("content", "steps")
+	_ = addCmd.Flags().Set("folder", strconv.FormatInt(folder.ID, 10))
+	_ = addCmd.Flags().Set("pinned", "true")
+	if err := addCmd.RunE(addCmd, nil); err != nil {
+		t.Fatalf("add: %v", err)
+	}
+	notes, _ := database.ListNotes(ctx, db.ListNotesParams{Limit: 10})
+	if len(notes) != 1 || notes[0].FolderID.Int64 != folder.ID || !notes[0].Pinned.Bool {
+		t.Fatalf("notes = %+v", notes)
+	}
+	// The vault file is written with the folder and pin already set
+	data, err := os.ReadFile(filepath.Join(vaultRoot, "runbook.md"))
+	if err != nil || !strings.Contains(string(data), "folder: Work") || !strings.Contains(string(data), "pinned: true") {
+		t.Errorf("vault file = %q, %v", data, err)
+	}
+}
+
+func TestEditCmdMetadata(t *testing.T) {
+	defer setupTestDB(t)()
+	t.Setenv("NOTED_VAULT", t.TempDir())
+	ctx := context.Background()
+
+	id := createTestNote(t, "Doc", "body", nil)
+	_ = database.UpdateNoteSource(ctx, db.UpdateNoteSourceParams{ID: id, Source: sql.NullString{String: "manual", Valid: true}})
+	for _, f := range []string{"source-ref", "ttl", "source"} {
+		defer func() { _ = editCmd.Flags().Set(f, ""); editCmd.Flags().Lookup(f).Changed = false }()
+	}
+	_ = editCmd.Flags().Set("source-ref", "main.go:50")
+	_ = editCmd.Flags().Set("ttl", "7d")
+	if err := editCmd.RunE(editCmd, []string{strconv.FormatInt(id, 10)}); err != nil {
+		t.Fatalf("edit: %v", err)
+	}
+	note, _ := database.GetNote(ctx, id)
+	if note.Source.String != "manual" || note.SourceRef.String != "main.go:50" || note.Content != "body" {
+		t.Errorf("note = %+v", note)
+	}
+	if d := time.Until(note.ExpiresAt.Time); !note.ExpiresAt.Valid || d < 6*24*time.Hour || d > 8*24*time.Hour {
+		t.Errorf("expires_at = %v", note.ExpiresAt)
+	}
+
+	_ = editCmd.Flags().Set("source", "")
+	_ = editCmd.Flags().Set("ttl", "never")
+	if err := editCmd.RunE(editCmd, []string{strconv.FormatInt(id, 10)}); err != nil {
+		t.Fatalf("edit: %v", err)
+	}
+	if note, _ := database.GetNote(ctx, id); note.Source.Valid || note.ExpiresAt.Valid || note.SourceRef.String != "main.go:50" {
+		t.Errorf("expected source and expiry cleared, got %+v", note)
+	}
+}
+
 // TestRestoreWritesThroughToVault is the regression for the review's MEDIUM finding: `restore` must
 // mirror the restored content to the vault, otherwise a later vault→index rebuild (e.g. the TUI file
 // watcher firing) re-reads the stale .md and silently reverts the restore.
@@ -1102,7 +1922,7 @@ func TestGetOrCreateDailyNote(t *testing.T) {
 	ctx := context.Background()
 
 	// First call creates the note
-	note1, err := getOrCreateDailyNote(ctx, "2026-02-17")
+	note1, _, err := getOrCreateDailyNote(ctx, "2026-02-17", "")
 	if err != nil {
 		t.Fatalf("failed to create daily note: %v", err)
 	}
@@ -1124,7 +1944,7 @@ func TestGetOrCreateDailyNote(t *testing.T) {
 	}
 
 	// Second call returns the same note
-	note2, err := getOrCreateDailyNote(ctx, "2026-02-17")
+	note2, _, err := getOrCreateDailyNote(ctx, "2026-02-17", "")
 	if err != nil {
 		t.Fatalf("failed to get daily note: %v", err)
 	}
@@ -1133,6 +1953,32 @@ func TestGetOrCreateDailyNote(t *testing.T) {
 	}
 }
 
+func TestDailyTemplateContent(t *testing.T) {
+	defer setupTestDB(t)()
+	ctx := context.Background()
+
+	t.Setenv("NOTED_DAILY_TEMPLATE", "")
+	if content, err := dailyTemplateContent(ctx, "", "2026-02-17", time.Now()); err != nil || content != "" {
+		t.Errorf("without a daily template = %q, %v; want empty", content, err)
+	}
+	_, _ = database.CreateTemplate(ctx, db.CreateTemplateParams{Name: "daily", Content: "# {{title}}\nWeek of {{date}}\n{{cursor}}"})
+	date := time.Date(2026, 2, 17, 0, 0, 0, 0, time.Local)
+	if content, _ := dailyTemplateContent(ctx, "", "2026-02-17", date); content != "# 2026-02-17\nWeek of 2026-02-17\n" {
+		t.Errorf("daily template = %q", content)
+	}
+	if _, err := dailyTemplateContent(ctx, "missing", "2026-02-17", date); err == nil {
+		t.Error("expected an error for a missing --template")
+	}
+
+	note, created, err := getOrCreateDailyNote(ctx, "2026-02-17", "from template")
+	if err != nil || !created || note.Content != "from template" {
+		t.Errorf("getOrCreateDailyNote = %+v, %v, %v", note, created, err)
+	}
+	if _, created, _ := getOrCreateDailyNote(ctx, "2026-02-17", "other"); created {
+		t.Error("an existing daily note should not be created again")
+	}
+}
+
 func TestGetOrCreateDailyFolder(t *testing.T) {
 	cleanup := setupTestDB(t)
 	defer cleanup()
@@ -1289,6 +2135,13 @@ func TestVaultRoundTrip(t *testing.T) {
 		t.Fatal(err)
 	}
 
+	if err := database.AddNoteAlias(ctx, db.AddNoteAliasParams{NoteID: alpha.ID, Alias: "First"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := database.ArchiveNote(ctx, alpha.ID); err != nil {
+		t.Fatal(err)
+	}
+
 	vdir := t.TempDir()
 	_ = vaultExportCmd.Flags().Set("path", vdir)
 	if err := vaultExportCmd.RunE(vaultExportCmd, nil); err != nil {
@@ -1337,6 +2190,13 @@ func TestVaultRoundTrip(t *testing.T) {
 	} else if f, _ := database.GetFolder(ctx, ra.FolderID.Int64); f.Name != "Workspace" {
 		t.Errorf("restored folder = %q, want Workspace", f.Name)
 	}
+	// Aliases and archive state travel in the frontmatter too.
+	if aliases, _ := database.GetNoteAliases(ctx, alpha.ID); len(aliases) != 1 || aliases[0] != "First" {
+		t.Errorf("Alpha aliases = %v, want [First]", aliases)
+	}
+	if !ra.ArchivedAt.Valid {
+		t.Error("Alpha's archived state was not restored")
+	}
 }
 
 func TestVaultExportNoFilenameCollision(t *testing.T) {
@@ -1407,3 +2267,1226 @@ func TestVaultImportHardening(t *testing.T) {
 		t.Errorf("parent folder = %q, want Work", parent.Name)
 	}
 }
+
+// ============================================================================
+// Schedule Tests
+// ============================================================================
+
+func TestParseScheduleRule(t *testing.T) {
+	// Wednesday 2026-02-18 10:00 local
+	base := time.Date(2026, 2, 18, 10, 0, 0, 0, time.Local)
+
+	tests := []struct {
+		rule string
+		want time.Time
+	}{
+		{"daily", time.Date(2026, 2, 19, 9, 0, 0, 0, time.Local)},
+		{"daily@11:30", time.Date(2026, 2, 18, 11, 30, 0, 0, time.Local)},
+		{"weekdays@09:00", time.Date(2026, 2, 19, 9, 0, 0, 0, time.Local)},
+		{"weekly:mon", time.Date(2026, 2, 23, 9, 0, 0, 0, time.Local)},
+		{"weekly:friday@17:00", time.Date(2026, 2, 20, 17, 0, 0, 0, time.Local)},
+		{"monthly:1", time.Date(2026, 3, 1, 9, 0, 0, 0, time.Local)},
+		{"monthly:31", time.Date(2026, 2, 28, 9, 0, 0, 0, time.Local)},
+	}
+	for _, tt := range tests {
+		r, err := parseScheduleRule(tt.rule)
+		if err != nil {
+			t.Fatalf("parseScheduleRule(%q): %v", tt.rule, err)
+		}
+		if got := r.next(base); !got.Equal(tt.want) {
+			t.Errorf("%s: next = %v, want %v", tt.rule, got, tt.want)
+		}
+	}
+
+	for _, bad := range []string{"", "hourly", "weekly", "weekly:xyz", "monthly:0", "daily@25:00"} {
+		if _, err := parseScheduleRule(bad); err == nil {
+			t.Errorf("expected error for rule %q", bad)
+		}
+	}
+}
+
+func TestRunDueSchedules(t *testing.T) {
+	cleanup := setupTestDB(t)
+	defer cleanup()
+	vlt, _ := vault.Open(t.TempDir())
+
+	ctx := context.Background()
+	if _, err := database.CreateTemplate(ctx, db.CreateTemplateParams{Name: "retro", Content: "# {{title}}\n\n## Went well\n"}); err != nil {
+		t.Fatal(err)
+	}
+	due := time.Date(2026, 2, 16, 9, 0, 0, 0, time.Local)
+	if _, err := database.CreateSchedule(ctx, db.CreateScheduleParams{
+		Name:         "retro",
+		Rule:         "weekly:mon",
+		Title:        "Retro {{date}}",
+		TemplateName: sql.NullString{String: "retro", Valid: true},
+		Tags:         "retro,team",
+		NextRunAt:    due,
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Dry run creates nothing
+	now := time.Date(2026, 2, 18, 10, 0, 0, 0, time.Local)
+	results, err := runDueSchedules(ctx, vlt, now, true)
+	if err != nil || len(results) != 1 {
+		t.Fatalf("dry run: results=%v err=%v", results, err)
+	}
+	if count, _ := database.CountNotes(ctx); count != 0 {
+		t.Fatalf("dry run created %d notes", count)
+	}
+
+	results, err = runDueSchedules(ctx, vlt, now, false)
+	if err != nil || len(results) != 1 {
+		t.Fatalf("run: results=%v err=%v", results, err)
+	}
+	note, err := database.GetNote(ctx, results[0].NoteID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if note.Title != "Retro 2026-02-16" {
+		t.Errorf("title = %q, want dated for the due occurrence", note.Title)
+	}
+	if !strings.Contains(note.Content, "# Retro 2026-02-16") {
+		t.Errorf("template not applied: %q", note.Content)
+	}
+	tags, _ := database.GetTagsForNote(ctx, note.ID)
+	if len(tags) != 2 {
+		t.Errorf("expected 2 tags, got %d", len(tags))
+	}
+	if vn, _ := vlt.List(); len(vn) != 1 {
+		t.Errorf("expected scheduled note mirrored to vault, got %d files", len(vn))
+	}
+
+	// The schedule advanced past now, so a second run is a no-op
+	s, _ := database.GetScheduleByName(ctx, "retro")
+	if want := time.Date(2026, 2, 23, 9, 0, 0, 0, time.Local); !s.NextRunAt.Equal(want) {
+		t.Errorf("next_run_at = %v, want %v", s.NextRunAt, want)
+	}
+	results, _ = runDueSchedules(ctx, vlt, now, false)
+	if len(results) != 0 {
+		t.Errorf("expected no due schedules after run, got %d", len(results))
+	}
+
+	// A failure part way through leaves no note behind and the schedule still due
+	_, _ = database.CreateSchedule(ctx, db.CreateScheduleParams{Name: "standup", Rule: "daily", Title: "Standup", Tags: "team", NextRunAt: due})
+	if _, err := conn.ExecContext(ctx, "CREATE TRIGGER fail_tagging BEFORE INSERT ON note_tags BEGIN SELECT RAISE(ABORT, 'tagging failed'); END"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := runDueSchedules(ctx, vlt, now, false); err == nil {
+		t.Fatal("expected the run to fail")
+	}
+	if count, _ := database.CountNotes(ctx); count != 1 {
+		t.Errorf("failed run left %d notes, want 1", count)
+	}
+	if s, _ := database.GetScheduleByName(ctx, "standup"); !s.NextRunAt.Equal(due) || s.LastNoteID.Valid {
+		t.Errorf("failed run advanced the schedule: %+v", s)
+	}
+}
+
+// ============================================================================
+// Quick Capture / Inbox Tests
+// ============================================================================
+
+func TestQuickTitle(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"call the dentist", "call the dentist"},
+		{"\n\n# Idea: cache embeddings\nmore detail", "Idea: cache embeddings"},
+		{"- [ ] buy milk", "[ ] buy milk"},
+		{"", "Untitled"},
+		{strings.Repeat("word ", 30), strings.TrimSpace(strings.Repeat("word ", 12)) + "…"},
+	}
+	for _, tt := range tests {
+		if got := quickTitle(tt.in); got != tt.want {
+			t.Errorf("quickTitle(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestQuickCmdTagsInbox(t *testing.T) {
+	cleanup := setupTestDB(t)
+	defer cleanup()
+	t.Setenv("NOTED_VAULT", t.TempDir())
+
+	_ = quickCmd.Flags().Set("tags", "work")
+	defer func() { _ = quickCmd.Flags().Set("tags", "") }()
+	if err := quickCmd.RunE(quickCmd, []string{"follow", "up", "with", "Sam"}); err != nil {
+		t.Fatalf("quick: %v", err)
+	}
+
+	ctx := context.Background()
+	notes, _ := database.GetNotesByTagName(ctx, inboxTagName)
+	if len(notes) != 1 || notes[0].Title != "follow up with Sam" {
+		t.Fatalf("expected one inbox note titled from its text, got %+v", notes)
+	}
+	tags, _ := database.GetTagsForNote(ctx, notes[0].ID)
+	if len(tags) != 2 {
+		t.Errorf("expected inbox + work tags, got %d", len(tags))
+	}
+}
+
+func TestTriageInbox(t *testing.T) {
+	cleanup := setupTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	folder, _ := database.CreateFolder(ctx, db.CreateFolderParams{Name: "Projects"})
+	target := createTestNote(t, "Ideas", "existing", nil)
+	filed := createTestNote(t, "file me", "file me", []string{inboxTagName})
+	tagged := createTestNote(t, "tag me", "tag me", []string{inboxTagName})
+	merged := createTestNote(t, "merge me", "merged text", []string{inboxTagName})
+	skipped := createTestNote(t, "skip me", "skip me", []string{inboxTagName})
+
+	notes, _ := database.GetNotesByTagName(ctx, inboxTagName)
+	slices.Reverse(notes)
+
+	// "x" is an unknown action and "t inbox" / "t ," apply no tag; all re-prompt for the same note
+	input := fmt.Sprintf("f %d\nx\nt inbox\nt ,\nt go,reading\nm %d\ns\n", folder.ID, target)
+	handled, err := triageInbox(ctx, strings.NewReader(input), nil, notes)
+	if err != nil {
+		t.Fatalf("triage: %v", err)
+	}
+	if handled != 3 {
+		t.Errorf("handled = %d, want 3", handled)
+	}
+
+	remaining, _ := database.GetNotesByTagName(ctx, inboxTagName)
+	if len(remaining) != 1 || remaining[0].ID != skipped {
+		t.Errorf("expected only the skipped note left in the inbox, got %+v", remaining)
+	}
+	if n, _ := database.GetNote(ctx, filed); !n.FolderID.Valid || n.FolderID.Int64 != folder.ID {
+		t.Errorf("filed note not moved to folder")
+	}
+	if tags, _ := database.GetTagsForNote(ctx, tagged); len(tags) != 2 {
+		t.Errorf("expected tagged note to have 2 tags, got %d", len(tags))
+	}
+	if _, err := database.GetNote(ctx, merged); err != sql.ErrNoRows {
+		t.Errorf("merged note should be deleted, got err=%v", err)
+	}
+	if n, _ := database.GetNote(ctx, target); !strings.Contains(n.Content, "merged text") {
+		t.Errorf("merge target missing merged content: %q", n.Content)
+	}
+}
+
+// ============================================================================
+// Search History Tests
+// ============================================================================
+
+func TestGrepRecordsSearchHistory(t *testing.T) {
+	cleanup := setupTestDB(t)
+	defer cleanup()
+	createTestNote(t, "Go tips", "use gofmt", []string{"golang"})
+
+	ctx := context.Background()
+	for _, q := range []string{"gofmt", "golang", "gofmt"} {
+		if err := grepCmd.RunE(grepCmd, []string{q}); err != nil {
+			t.Fatalf("grep %q: %v", q, err)
+		}
+	}
+
+	rows, err := database.ListSearchHistory(ctx, db.ListSearchHistoryParams{InterfaceFilter: "cli", Limit: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 2 {
+		t.Fatalf("expected 2 distinct queries, got %d", len(rows))
+	}
+	if rows[0].Query != "gofmt" || rows[0].Uses != 2 {
+		t.Errorf("most recent = %q (%dx), want gofmt (2x)", rows[0].Query, rows[0].Uses)
+	}
+	if rows, _ := database.ListSearchHistory(ctx, db.ListSearchHistoryParams{InterfaceFilter: "mcp", Limit: 10}); len(rows) != 0 {
+		t.Errorf("expected no mcp searches, got %d", len(rows))
+	}
+
+	// Opting out stops recording
+	t.Setenv("NOTED_SEARCH_HISTORY", "off")
+	if err := grepCmd.RunE(grepCmd, []string{"sqlite"}); err != nil {
+		t.Fatal(err)
+	}
+	rows, _ = database.ListSearchHistory(ctx, db.ListSearchHistoryParams{Limit: 10})
+	if len(rows) != 2 {
+		t.Errorf("expected opt-out to skip recording, got %d queries", len(rows))
+	}
+}
+
+// ============================================================================
+// Notes-by-date Tests
+// ============================================================================
+
+func TestParseOnDate(t *testing.T) {
+	now := time.Date(2026, 3, 14, 10, 0, 0, 0, time.Local)
+	tests := []struct {
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{"", "2026-03-14", false},
+		{"today", "2026-03-14", false},
+		{"Yesterday", "2026-03-13", false},
+		{"2025-01-02", "2025-01-02", false},
+		{"03/14/2025", "", true},
+	}
+	for _, tt := range tests {
+		got, err := parseOnDate(tt.in, now)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseOnDate(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if err == nil && got.Format(dailyDateFormat) != tt.want {
+			t.Errorf("parseOnDate(%q) = %s, want %s", tt.in, got.Format(dailyDateFormat), tt.want)
+		}
+	}
+}
+
+func TestNotesOn(t *testing.T) {
+	cleanup := setupTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	setTimes := func(id int64, created, updated string) {
+		t.Helper()
+		if _, err := conn.Exec("UPDATE notes SET created_at = ?, updated_at = ? WHERE id = ?", created, updated, id); err != nil {
+			t.Fatal(err)
+		}
+	}
+	setTimes(createTestNote(t, "Created that day", "", nil), "2025-03-14 12:00:00", "2025-03-14 12:00:00")
+	setTimes(createTestNote(t, "Edited that day", "", nil), "2025-01-01 12:00:00", "2025-03-14 13:00:00")
+	setTimes(createTestNote(t, "Other day", "", nil), "2025-03-15 12:00:00", "2025-03-15 12:00:00")
+	setTimes(createTestNote(t, "Two years back", "", nil), "2023-03-14 12:00:00", "2023-03-14 12:00:00")
+	setTimes(createTestNote(t, "Last year", "", nil), "2024-03-14 12:00:00", "2024-03-14 12:00:00")
+
+	day := time.Date(2025, 3, 14, 0, 0, 0, 0, time.Local)
+	notes, err := notesOn(ctx, day, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(notes) != 2 {
+		t.Fatalf("expected 2 notes on 2025-03-14, got %d", len(notes))
+	}
+
+	notes, err = notesOn(ctx, day, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(notes) != 2 || notes[0].Title != "Last year" || notes[1].Title != "Two years back" {
+		t.Errorf("expected previous years newest first, got %+v", notes)
+	}
+}
+
+// ============================================================================
+// Sync Tests
+// ============================================================================
+
+// fakeNoteSyncer fails the listed notes and reports the embedder unavailable from stopAt on.
+type fakeNoteSyncer struct {
+	fail     map[int64]bool
+	stopAt   int64
+	synced   []int64
+	memories []int64
+}
+
+func (f *fakeNoteSyncer) SyncNote(id int64, title, content string) error {
+	switch {
+	case f.stopAt != 0 && id >= f.stopAt:
+		return embeddings.ErrUnavailable
+	case f.fail[id]:
+		return fmt.Errorf("bad note")
+	}
+	f.synced = append(f.synced, id)
+	return nil
+}
+
+func (f *fakeNoteSyncer) SyncMemory(id int64, title, content string) error {
+	f.memories = append(f.memories, id)
+	return nil
+}
+
+func TestSyncNotesSplitsMemories(t *testing.T) {
+	cleanup := setupTestDB(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	note := createTestNote(t, "Note", "body", nil)
+	mem := createTestNote(t, "Memory", "remembered", []string{"memory", "memory:fact"})
+	notes, _ := database.GetUnsynced(ctx)
+
+	f := &fakeNoteSyncer{}
+	if res := syncNotes(ctx, f, notes, nil); res.synced != 2 {
+		t.Fatalf("expected 2 synced, got %+v", res)
+	}
+	if len(f.synced) != 1 || f.synced[0] != note || len(f.memories) != 1 || f.memories[0] != mem {
+		t.Errorf("expected note %d in notes and %d in memories, got %v and %v", note, mem, f.synced, f.memories)
+	}
+}
+
+func TestSyncNotesResumes(t *testing.T) {
+	cleanup := setupTestDB(t)
+	defer cleanup()
+	ctx := context.Background()
+	syncForce, syncLimit, syncIDs = false, 0, nil
+	defer func() { syncForce, syncLimit, syncIDs = false, 0, nil }()
+
+	var ids []int64
+	for i := 0; i < 4; i++ {
+		ids = append(ids, createTestNote(t, fmt.Sprintf("Note %d", i), "body", nil))
+	}
+
+	// First run fails one note and is cut off by an outage at the last one
+	notes, err := notesToSync(ctx)
+	if err != nil || len(notes) != 4 {
+		t.Fatalf("expected 4 unsynced notes, got %d (err %v)", len(notes), err)
+	}
+	res := syncNotes(ctx, &fakeNoteSyncer{fail: map[int64]bool{ids[1]: true}, stopAt: ids[3]}, notes, nil)
+	if res.synced != 2 || len(res.failures) != 1 || res.stopped == nil {
+		t.Fatalf("unexpected result: %+v", res)
+	}
+
+	// The next run picks up only what is left
+	notes, _ = notesToSync(ctx)
+	if len(notes) != 2 || notes[0].ID != ids[1] || notes[1].ID != ids[3] {
+		t.Fatalf("expected notes %d and %d pending, got %+v", ids[1], ids[3], notes)
+	}
+
+	// --limit caps the run; --force starts over
+	syncLimit = 1
+	if notes, _ = notesToSync(ctx); len(notes) != 1 {
+		t.Errorf("expected --limit 1 to return 1 note, got %d", len(notes))
+	}
+	syncLimit, syncForce = 0, true
+	if notes, _ = notesToSync(ctx); len(notes) != 4 {
+		t.Errorf("expected --force to queue all 4 notes, got %d", len(notes))
+	}
+
+	// --ids syncs exactly those notes
+	syncForce, syncIDs = false, []int64{ids[2]}
+	if notes, _ = notesToSync(ctx); len(notes) != 1 || notes[0].ID != ids[2] {
+		t.Errorf("expected only note %d, got %+v", ids[2], notes)
+	}
+	syncIDs = []int64{9999}
+	if _, err := notesToSync(ctx); err == nil {
+		t.Error("expected error for unknown id")
+	}
+}
+
+func TestProgressLine(t *testing.T) {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	p := &progress{total: 100, start: start, now: func() time.Time { return start.Add(30 * time.Second) }}
+
+	line := p.line(25)
+	if !strings.Contains(line, " 25/100  25%") || !strings.Contains(line, "ETA 1m30s") {
+		t.Errorf("unexpected progress line: %q", line)
+	}
+	if !strings.HasPrefix(line, "[#######.......") {
+		t.Errorf("unexpected bar: %q", line)
+	}
+	if line := p.line(0); !strings.Contains(line, "ETA --") {
+		t.Errorf("expected unknown ETA before progress, got %q", line)
+	}
+}
+
+func TestReadSyncStatus(t *testing.T) {
+	cleanup := setupTestDB(t)
+	defer cleanup()
+	ctx := context.Background()
+	createTestNote(t, "Pending", "not embedded yet", nil)
+	synced := createTestNote(t, "Synced", "embedded", nil)
+	_ = database.MarkEmbeddingSynced(ctx, synced)
+
+	cfg := &config.Config{VeclitePath: filepath.Join(t.TempDir(), "vectors.veclite")}
+	res, err := readSyncStatus(ctx, cfg)
+	if err != nil {
+		t.Fatalf("readSyncStatus failed: %v", err)
+	}
+	if res.Exists || res.Notes != 2 || res.Pending != 1 {
+		t.Errorf("unexpected status: %+v", res)
+	}
+	if res.ConfiguredModels[veclite.CollectionNotes] != embeddings.DefaultModel ||
+		res.ConfiguredModels[veclite.CollectionMemories] != embeddings.DefaultModel {
+		t.Errorf("expected the default model for both collections, got %v", res.ConfiguredModels)
+	}
+
+	cfg.EmbeddingModel, cfg.MemoryEmbeddingModel = "notes-model", "memory-model"
+	if models := collectionModels(cfg); models[veclite.CollectionNotes] != "notes-model" ||
+		models[veclite.CollectionMemories] != "memory-model" {
+		t.Errorf("unexpected configured models: %v", models)
+	}
+}
+
+func TestPickTuned(t *testing.T) {
+	results := []veclite.BenchResult{
+		{Index: veclite.IndexConfig{M: 8}, RecallAtK: 0.90, IndexAvg: time.Millisecond},
+		{Index: veclite.IndexConfig{M: 16}, RecallAtK: 0.97, IndexAvg: 3 * time.Millisecond},
+		{Index: veclite.IndexConfig{M: 32}, RecallAtK: 0.99, IndexAvg: 2 * time.Millisecond},
+	}
+	if best, ok := pickTuned(results, 0.95); !ok || best.Index.M != 32 {
+		t.Errorf("expected the fastest setting over the target (m=32), got %+v", best)
+	}
+	if best, ok := pickTuned(results, 0.999); ok || best.Index.M != 32 {
+		t.Errorf("expected the most accurate setting when none reach the target, got %+v", best)
+	}
+}
+
+// ============================================================================
+// capture-git
+// ============================================================================
+
+func TestMatchPaths(t *testing.T) {
+	files := []string{"internal/db/query.sql", "docs/guide/sync.md", "README.md", "cmd/root.go"}
+
+	if got := matchPaths(files, nil); len(got) != len(files) {
+		t.Errorf("expected every file without patterns, got %v", got)
+	}
+	got := matchPaths(files, []string{"./internal/db/", "*.md"})
+	want := []string{"internal/db/query.sql", "docs/guide/sync.md", "README.md"}
+	if !slices.Equal(got, want) {
+		t.Errorf("matchPaths = %v, want %v", got, want)
+	}
+	if got := matchPaths(files, []string{"internal/d"}); len(got) != 0 {
+		t.Errorf("a partial directory name should not match, got %v", got)
+	}
+}
+
+func TestCaptureCommit(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	cleanup := setupTestDB(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	repo := t.TempDir()
+	git := func(args ...string) {
+		t.Helper()
+		c := exec.Command("git", append([]string{"-C", repo, "-c", "user.name=Test", "-c", "user.email=test@example.com"}, args...)...)
+		if out, err := c.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	git("init", "-q")
+	_ = os.MkdirAll(filepath.Join(repo, "db"), 0o755)
+	_ = os.WriteFile(filepath.Join(repo, "db", "schema.sql"), []byte("CREATE TABLE t (id INT);\n"), 0o644)
+	git("add", ".")
+	git("commit", "-q", "-m", "Use integer ids\n\nUUIDs made the indexes too large.")
+
+	commit, err := readCommit(repo, "HEAD")
+	if err != nil {
+		t.Fatalf("readCommit failed: %v", err)
+	}
+	if len(commit.SHA) != 40 || commit.Message != "Use integer ids\n\nUUIDs made the indexes too large." ||
+		!slices.Equal(commit.Files, []string{"db/schema.sql"}) {
+		t.Fatalf("unexpected commit: %+v", commit)
+	}
+
+	res, err := captureCommit(ctx, nil, commit, []string{"docs"}, "decision", 3)
+	if err != nil || res.Captured {
+		t.Fatalf("expected commit outside the paths to be skipped, got %+v (err %v)", res, err)
+	}
+
+	res, err = captureCommit(ctx, nil, commit, []string{"db"}, "decision", 3)
+	if err != nil || !res.Captured || res.Title != "Use integer ids" {
+		t.Fatalf("expected commit to be captured, got %+v (err %v)", res, err)
+	}
+	note, _ := database.GetNote(ctx, res.ID)
+	if note.Source.String != "git" || note.SourceRef.String != commit.SHA || !strings.Contains(note.Content, "UUIDs") {
+		t.Errorf("unexpected memory: %+v", note)
+	}
+
+	// Running the hook twice does not duplicate the memory
+	again, _ := captureCommit(ctx, nil, commit, nil, "decision", 3)
+	if again.Captured || again.ID != res.ID {
+		t.Errorf("expected the second capture to be skipped, got %+v", again)
+	}
+}
+
+// ============================================================================
+// Project settings (.noted.yaml)
+// ============================================================================
+
+func TestApplyProject(t *testing.T) {
+	cleanup := setupTestDB(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	p := &config.Project{Scope: "noted", Tags: []string{"go"}, Folder: "work/noted"}
+	first, _ := database.GetNote(ctx, createTestNote(t, "First", "body", nil))
+	got, err := applyProject(ctx, p, first, false)
+	if err != nil {
+		t.Fatalf("applyProject failed: %v", err)
+	}
+	if path := notesync.FolderPath(ctx, database, got.FolderID.Int64); path != "work/noted" {
+		t.Errorf("expected folder work/noted, got %q", path)
+	}
+	tags, _ := database.GetTagsForNote(ctx, got.ID)
+	var names []string
+	for _, tag := range tags {
+		names = append(names, tag.Name)
+	}
+	slices.Sort(names)
+	if strings.Join(names, ",") != "go,project:noted" {
+		t.Errorf("unexpected tags: %v", names)
+	}
+
+	// The folder is reused, and an explicit folder is kept
+	second, _ := database.GetNote(ctx, createTestNote(t, "Second", "body", nil))
+	if got2, _ := applyProject(ctx, p, second, false); got2.FolderID != got.FolderID {
+		t.Errorf("expected the same project folder, got %v and %v", got.FolderID, got2.FolderID)
+	}
+	third, _ := database.GetNote(ctx, createTestNote(t, "Third", "body", nil))
+	if got3, _ := applyProject(ctx, p, third, true); got3.FolderID.Valid {
+		t.Errorf("expected keepFolder to leave the folder alone, got %v", got3.FolderID)
+	}
+	if folders, _ := database.ListFolders(ctx); len(folders) != 2 {
+		t.Errorf("expected 2 folders (work, noted), got %d", len(folders))
+	}
+}
+
+// ============================================================================
+// Shell integration (shell-init, exec)
+// ============================================================================
+
+func TestShellInitScripts(t *testing.T) {
+	for shell, binding := range map[string]string{
+		"bash": `bind -x '"\C-n": __noted_capture_line'`,
+		"zsh":  `bindkey '^N' __noted_capture_line`,
+		"fish": `bind \cn __noted_capture_line`,
+	} {
+		script := shellInitScripts[shell]
+		if !strings.Contains(script, binding) || !strings.Contains(script, "noted exec --") {
+			t.Errorf("%s script missing binding or nx helper:\n%s", shell, script)
+		}
+	}
+	if err := shellInitCmd.RunE(shellInitCmd, []string{"tcsh"}); err == nil {
+		t.Error("expected an error for an unsupported shell")
+	}
+}
+
+func TestShellJoin(t *testing.T) {
+	got := shellJoin([]string{"git", "commit", "-m", "it's done", ""})
+	if want := `git commit -m 'it'\''s done' ''`; got != want {
+		t.Errorf("shellJoin = %s, want %s", got, want)
+	}
+}
+
+func TestExecCmdStoresOutput(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not installed")
+	}
+	defer setupTestDB(t)()
+	t.Setenv("NOTED_VAULT", t.TempDir())
+	ctx := context.Background()
+
+	err := execCmd.RunE(execCmd, []string{"sh", "-c", "echo hello; echo oops >&2; exit 3"})
+	var exitErr *exitCodeError
+	if !errors.As(err, &exitErr) || exitErr.code != 3 {
+		t.Fatalf("expected exit status 3, got %v", err)
+	}
+
+	notes, _ := database.ListNotes(ctx, db.ListNotesParams{Limit: 10})
+	if len(notes) != 1 {
+		t.Fatalf("expected 1 note, got %d", len(notes))
+	}
+	n := notes[0]
+	if n.Source.String != shellSource || n.SourceRef.String == "" {
+		t.Errorf("unexpected source %q ref %q", n.Source.String, n.SourceRef.String)
+	}
+	if !strings.HasPrefix(n.Title, "$ sh -c 'echo hello;") {
+		t.Errorf("unexpected title %q", n.Title)
+	}
+	for _, want := range []string{"```console\n$ sh -c", "hello\n", "oops\n", "Exit status: 3"} {
+		if !strings.Contains(n.Content, want) {
+			t.Errorf("content missing %q:\n%s", want, n.Content)
+		}
+	}
+	if tags, _ := database.GetTagsForNote(ctx, n.ID); len(tags) != 1 || tags[0].Name != "shell" {
+		t.Errorf("expected tag shell, got %v", tags)
+	}
+}
+
+func TestCappedBuffer(t *testing.T) {
+	b := &cappedBuffer{max: 5}
+	_, _ = b.Write([]byte("abc"))
+	_, _ = b.Write([]byte("defg"))
+	_, _ = b.Write([]byte("hi"))
+	if b.buf.String() != "abcde" || b.dropped != 4 {
+		t.Errorf("got %q dropped %d", b.buf.String(), b.dropped)
+	}
+}
+
+// ============================================================================
+// Edit conflicts
+// ============================================================================
+
+func TestNoteChangedSince(t *testing.T) {
+	at := sql.NullTime{Time: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC), Valid: true}
+	before := db.Note{Title: "T", Content: "a", UpdatedAt: at}
+	if noteChangedSince(before, before) {
+		t.Error("identical note reported as changed")
+	}
+	sameSecond := before
+	sameSecond.Content = "b"
+	if !noteChangedSince(before, sameSecond) {
+		t.Error("content change within the same second not detected")
+	}
+	later := before
+	later.UpdatedAt.Time = at.Time.Add(time.Second)
+	if !noteChangedSince(before, later) {
+		t.Error("updated_at change not detected")
+	}
+}
+
+func TestMergeText(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	base := "one\ntwo\nthree\n"
+	merged := mergeText(base, "ONE\ntwo\nthree\n", "one\ntwo\nTHREE\n")
+	if merged != "ONE\ntwo\nTHREE\n" {
+		t.Errorf("expected a clean merge, got %q", merged)
+	}
+	conflict := mergeText(base, "uno\ntwo\nthree\n", "eins\ntwo\nthree\n")
+	for _, want := range []string{"<<<<<<< yours\nuno\n", "eins\n>>>>>>> stored\n"} {
+		if !strings.Contains(conflict, want) {
+			t.Errorf("merge missing %q:\n%s", want, conflict)
+		}
+	}
+}
+
+func TestResolveEditConflict(t *testing.T) {
+	var out strings.Builder
+	got, err := resolveEditConflict(1, "base", "mine", "theirs", strings.NewReader("y\n"), &out)
+	if err != nil || got != "mine" {
+		t.Errorf("keep yours: got %q, %v", got, err)
+	}
+	if !strings.Contains(out.String(), "changed by someone else") {
+		t.Errorf("expected a conflict message, got %q", out.String())
+	}
+	if got, err := resolveEditConflict(1, "base", "mine", "theirs", strings.NewReader("s\n"), io.Discard); err != nil || got != "theirs" {
+		t.Errorf("keep stored: got %q, %v", got, err)
+	}
+
+	// Aborting (or no answer at all) keeps the user's edit on disk
+	_, err = resolveEditConflict(1, "base", "mine", "theirs", strings.NewReader(""), io.Discard)
+	if err == nil || !strings.Contains(err.Error(), "mine.md") {
+		t.Fatalf("expected an abort naming the saved file, got %v", err)
+	}
+	path := strings.TrimSuffix(err.Error()[strings.Index(err.Error(), "in ")+3:], ")")
+	if data, _ := os.ReadFile(path); string(data) != "mine" {
+		t.Errorf("expected the edit to be kept in %s, got %q", path, data)
+	}
+	_ = os.RemoveAll(filepath.Dir(path))
+}
+
+// ============================================================================
+// Template placeholders and cursor
+// ============================================================================
+
+func TestTemplateVars(t *testing.T) {
+	content := "# {{title}} with {{client}}\n{{date}} {{ attendees }} {{client}} {{cursor}}"
+	if got := templateVars(content); !slices.Equal(got, []string{"client", "attendees"}) {
+		t.Errorf("templateVars = %v", got)
+	}
+
+	vars, err := parseTemplateVars([]string{"client=Acme", "note=a=b"})
+	if err != nil || vars["client"] != "Acme" || vars["note"] != "a=b" {
+		t.Fatalf("parseTemplateVars = %v, %v", vars, err)
+	}
+	if _, err := parseTemplateVars([]string{"novalue"}); err == nil {
+		t.Error("expected an error for a --var without '='")
+	}
+
+	var out strings.Builder
+	if err := promptTemplateVars(templateVars(content), vars, strings.NewReader("Ana, Bo\n"), &out); err != nil {
+		t.Fatalf("promptTemplateVars failed: %v", err)
+	}
+	if out.String() != "attendees: " || vars["attendees"] != "Ana, Bo" {
+		t.Errorf("expected a prompt for attendees only, got %q and %v", out.String(), vars)
+	}
+
+	got := fillTemplateVars("{{client}}: {{ attendees }} {{other}}", vars)
+	if got != "Acme: Ana, Bo {{other}}" {
+		t.Errorf("fillTemplateVars = %q", got)
+	}
+}
+
+func TestCutCursor(t *testing.T) {
+	tests := []struct {
+		in        string
+		want      string
+		line, col int
+	}{
+		{"no marker", "no marker", 0, 0},
+		{"{{cursor}}start", "start", 1, 1},
+		{"# Title\n\n- {{cursor}}\n{{cursor}}", "# Title\n\n- \n", 3, 3},
+	}
+	for _, tt := range tests {
+		got, line, col := cutCursor(tt.in)
+		if got != tt.want || line != tt.line || col != tt.col {
+			t.Errorf("cutCursor(%q) = %q, %d, %d; want %q, %d, %d", tt.in, got, line, col, tt.want, tt.line, tt.col)
+		}
+	}
+}
+
+func TestTemplateUse(t *testing.T) {
+	defer setupTestDB(t)()
+	t.Setenv("NOTED_VAULT", t.TempDir())
+	ctx := context.Background()
+
+	_, _ = database.CreateTemplate(ctx, db.CreateTemplateParams{
+		Name:    "meeting",
+		Content: "# {{title}}\nClient: {{client}}\n{{cursor}}",
+	})
+	_ = templateUseCmd.Flags().Set("title", "Standup {{date}}")
+	_ = templateUseCmd.Flags().Set("tags", "meetings")
+	_ = templateUseCmd.Flags().Set("var", "client=Acme")
+	defer func() {
+		_ = templateUseCmd.Flags().Set("title", "")
+		_ = templateUseCmd.Flags().Set("tags", "")
+	}()
+
+	if err := templateUseCmd.RunE(templateUseCmd, []string{"meeting"}); err != nil {
+		t.Fatalf("template use: %v", err)
+	}
+	notes, _ := database.ListNotes(ctx, db.ListNotesParams{Limit: 10})
+	if len(notes) != 1 {
+		t.Fatalf("expected one note, got %d", len(notes))
+	}
+	title := "Standup " + time.Now().Format("2006-01-02")
+	if notes[0].Title != title || notes[0].Content != "# "+title+"\nClient: Acme\n" {
+		t.Errorf("note = %q / %q", notes[0].Title, notes[0].Content)
+	}
+	if tags, _ := database.GetTagsForNote(ctx, notes[0].ID); len(tags) != 1 || tags[0].Name != "meetings" {
+		t.Errorf("tags = %v", tags)
+	}
+
+	if err := templateUseCmd.RunE(templateUseCmd, []string{"missing"}); err == nil {
+		t.Error("expected an error for a missing template")
+	}
+}
+
+func TestEditorCursorArgs(t *testing.T) {
+	if got := editorCursorArgs("/usr/bin/nvim", 3, 5); !slices.Equal(got, []string{"+call cursor(3,5)"}) {
+		t.Errorf("nvim: %v", got)
+	}
+	if got := editorCursorArgs("nano", 3, 5); !slices.Equal(got, []string{"+3,5"}) {
+		t.Errorf("nano: %v", got)
+	}
+	if got := editorCursorArgs("code", 3, 5); got != nil {
+		t.Errorf("unknown editors get no position: %v", got)
+	}
+	if got := editorCursorArgs("vim", 0, 0); got != nil {
+		t.Errorf("no marker means no position: %v", got)
+	}
+}
+
+// ============================================================================
+// Aliases and embeds
+// ============================================================================
+
+func TestExpandEmbeds(t *testing.T) {
+	defer setupTestDB(t)()
+	ctx := context.Background()
+
+	_, _ = database.CreateNote(ctx, db.CreateNoteParams{Title: "Spec", Content: "# Spec\n## Goals\nship it\n## Risks\nnone\n"})
+	page, _ := database.CreateNote(ctx, db.CreateNoteParams{Title: "Page", Content: "Goals:\n![[Spec#Goals]]\n![[Nowhere]]"})
+
+	if got, want := expandEmbeds(ctx, page), "Goals:\n## Goals\nship it\n![[Nowhere]]"; got != want {
+		t.Errorf("expandEmbeds = %q, want %q", got, want)
+	}
+}
+
+func TestShowSectionAndOutline(t *testing.T) {
+	defer setupTestDB(t)()
+	ctx := context.Background()
+
+	note, _ := database.CreateNote(ctx, db.CreateNoteParams{Title: "Spec", Content: "# Spec\n## Goals\nship it\n"})
+	id := strconv.FormatInt(note.ID, 10)
+
+	_ = showCmd.Flags().Set("section", "Nope")
+	defer func() { _ = showCmd.Flags().Set("section", "") }()
+	if err := showCmd.RunE(showCmd, []string{id}); err == nil || !strings.Contains(err.Error(), "no section") {
+		t.Errorf("expected a missing-section error, got %v", err)
+	}
+	_ = showCmd.Flags().Set("section", "goals")
+	if err := showCmd.RunE(showCmd, []string{id}); err != nil {
+		t.Errorf("show --section: %v", err)
+	}
+
+	if err := outlineCmd.RunE(outlineCmd, []string{id}); err != nil {
+		t.Errorf("outline: %v", err)
+	}
+	if err := outlineCmd.RunE(outlineCmd, []string{"999"}); err == nil {
+		t.Error("expected an error for a missing note")
+	}
+}
+
+func TestAliasCommands(t *testing.T) {
+	defer setupTestDB(t)()
+	vdir := t.TempDir()
+	t.Setenv("NOTED_VAULT", vdir)
+	ctx := context.Background()
+
+	note, _ := database.CreateNote(ctx, db.CreateNoteParams{Title: "Go", Content: "body"})
+	other, _ := database.CreateNote(ctx, db.CreateNoteParams{Title: "Other", Content: "see [[Golang]]"})
+	id := strconv.FormatInt(note.ID, 10)
+
+	if err := aliasAddCmd.RunE(aliasAddCmd, []string{id, "Golang", "Go language", "Golang"}); err != nil {
+		t.Fatalf("alias add: %v", err)
+	}
+	if got, _ := database.GetNoteAliases(ctx, note.ID); !slices.Equal(got, []string{"Go language", "Golang"}) {
+		t.Errorf("aliases = %v", got)
+	}
+	if n, err := database.ResolveNoteTitle(ctx, "Golang"); err != nil || n.ID != note.ID {
+		t.Errorf("Golang resolved to %d (err %v), want %d", n.ID, err, note.ID)
+	}
+	if data, err := os.ReadFile(filepath.Join(vdir, "go.md")); err != nil || !strings.Contains(string(data), "aliases:") {
+		t.Errorf("expected aliases in the vault file (err %v):\n%s", err, data)
+	}
+
+	if err := aliasRemoveCmd.RunE(aliasRemoveCmd, []string{id, "Golang"}); err != nil {
+		t.Fatalf("alias remove: %v", err)
+	}
+	if got, _ := database.GetNoteAliases(ctx, note.ID); !slices.Equal(got, []string{"Go language"}) {
+		t.Errorf("aliases after remove = %v", got)
+	}
+	if _, err := database.ResolveNoteTitle(ctx, "Golang"); err == nil {
+		t.Error("removed alias should no longer resolve")
+	}
+
+	if err := aliasListCmd.RunE(aliasListCmd, []string{strconv.FormatInt(other.ID+100, 10)}); err == nil {
+		t.Error("expected an error for a missing note")
+	}
+}
+
+// ============================================================================
+// Trash
+// ============================================================================
+
+func TestTrashCommands(t *testing.T) {
+	defer setupTestDB(t)()
+	vdir := t.TempDir()
+	t.Setenv("NOTED_VAULT", vdir)
+	t.Setenv("NOTED_VECLITE_PATH", "")
+	ctx := context.Background()
+
+	a, _ := database.CreateNote(ctx, db.CreateNoteParams{Title: "A", Content: "a"})
+	b, _ := database.CreateNote(ctx, db.CreateNoteParams{Title: "B", Content: "b"})
+	ids := []string{strconv.FormatInt(a.ID, 10), strconv.FormatInt(b.ID, 10)}
+
+	_ = deleteCmd.Flags().Set("force", "true")
+	defer func() { _ = deleteCmd.Flags().Set("force", "false") }()
+	if err := deleteCmd.RunE(deleteCmd, ids); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if count, _ := database.CountNotes(ctx); count != 0 {
+		t.Errorf("expected no live notes after delete, got %d", count)
+	}
+	if trashed, _ := database.ListTrashedNotes(ctx); len(trashed) != 2 {
+		t.Fatalf("expected 2 trashed notes, got %d", len(trashed))
+	}
+
+	if err := trashRestoreCmd.RunE(trashRestoreCmd, ids[:1]); err != nil {
+		t.Fatalf("trash restore: %v", err)
+	}
+	if _, err := database.GetNote(ctx, a.ID); err != nil {
+		t.Errorf("restored note should be visible, got %v", err)
+	}
+
+	// trash list purges notes that outlived NOTED_TRASH_DAYS first
+	t.Setenv("NOTED_TRASH_DAYS", "30")
+	if _, err := conn.ExecContext(ctx, "UPDATE notes SET deleted_at = ? WHERE id = ?", time.Now().AddDate(0, 0, -31).UTC(), b.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := trashListCmd.RunE(trashListCmd, nil); err != nil {
+		t.Fatalf("trash list: %v", err)
+	}
+	if _, err := database.GetTrashedNote(ctx, b.ID); err != sql.ErrNoRows {
+		t.Errorf("expired note should be purged, got err=%v", err)
+	}
+
+	_ = trashEmptyCmd.Flags().Set("force", "true")
+	defer func() { _ = trashEmptyCmd.Flags().Set("force", "false") }()
+	if err := trashEmptyCmd.RunE(trashEmptyCmd, ids[:1]); err == nil {
+		t.Error("expected an error purging a note that is not in the trash")
+	}
+	if err := trashEmptyCmd.RunE(trashEmptyCmd, nil); err != nil {
+		t.Fatalf("trash empty: %v", err)
+	}
+	if trashed, _ := database.ListTrashedNotes(ctx); len(trashed) != 0 {
+		t.Errorf("expected an empty trash, got %d", len(trashed))
+	}
+	if _, err := database.GetNote(ctx, a.ID); err != nil {
+		t.Errorf("emptying the trash must not touch live notes, got %v", err)
+	}
+}
+
+// ============================================================================
+// Archive
+// ============================================================================
+
+func TestArchiveCommands(t *testing.T) {
+	defer setupTestDB(t)()
+	t.Setenv("NOTED_VAULT", t.TempDir())
+	ctx := context.Background()
+
+	a, _ := database.CreateNote(ctx, db.CreateNoteParams{Title: "A", Content: "a"})
+	b, _ := database.CreateNote(ctx, db.CreateNoteParams{Title: "B", Content: "b"})
+	id := strconv.FormatInt(a.ID, 10)
+
+	if err := archiveCmd.RunE(archiveCmd, []string{id}); err != nil {
+		t.Fatalf("archive: %v", err)
+	}
+	if notes, _ := database.ListNotes(ctx, db.ListNotesParams{Limit: 10}); len(notes) != 1 || notes[0].ID != b.ID {
+		t.Errorf("archived note should be hidden from ListNotes, got %v", notes)
+	}
+
+	all, _ := database.GetAllNotes(ctx)
+	exported, err := filterExportNotes(ctx, all, exportFilter{archived: true})
+	if err != nil || len(exported) != 1 || exported[0].ID != a.ID {
+		t.Errorf("export --archived = %v, %v; want only A", exported, err)
+	}
+
+	if err := unarchiveCmd.RunE(unarchiveCmd, []string{id}); err != nil {
+		t.Fatalf("unarchive: %v", err)
+	}
+	if note, _ := database.GetNote(ctx, a.ID); note.ArchivedAt.Valid {
+		t.Error("unarchived note should have no archived_at")
+	}
+	if err := archiveCmd.RunE(archiveCmd, []string{"x"}); err == nil {
+		t.Error("expected an error for an invalid id")
+	}
+}
+
+// ============================================================================
+// Attachments
+// ============================================================================
+
+func TestExportZipAndImportAttachments(t *testing.T) {
+	defer setupTestDB(t)()
+	ctx := context.Background()
+
+	src := t.TempDir()
+	t.Setenv("NOTED_VAULT", src)
+	if err := os.MkdirAll(filepath.Join(src, "img"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(src, "img", "diagram.png"), []byte("png-bytes"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	_, _ = database.CreateNote(ctx, db.CreateNoteParams{
+		Title:   "Design",
+		Content: "![diagram](img/diagram.png)\n![[diagram.png]]\n![gone](missing.png)",
+	})
+
+	bundle := filepath.Join(t.TempDir(), "notes.zip")
+	_ = exportCmd.Flags().Set("format", "zip")
+	_ = exportCmd.Flags().Set("output", bundle)
+	defer func() {
+		_ = exportCmd.Flags().Set("format", "markdown")
+		_ = exportCmd.Flags().Set("output", "")
+	}()
+	if err := exportCmd.RunE(exportCmd, nil); err != nil {
+		t.Fatalf("export: %v", err)
+	}
+
+	zr, err := zip.OpenReader(bundle)
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{}
+	for _, f := range zr.File {
+		r, _ := f.Open()
+		data, _ := io.ReadAll(r)
+		_ = r.Close()
+		files[f.Name] = string(data)
+	}
+	_ = zr.Close()
+	if files["assets/diagram.png"] != "png-bytes" {
+		t.Errorf("bundle should hold the attachment, got files %v", slices.Collect(maps.Keys(files)))
+	}
+	if md := files["design.md"]; !strings.Contains(md, "![diagram](assets/diagram.png)") || !strings.Contains(md, "![gone](missing.png)") {
+		t.Errorf("bundled note links not rewritten as expected:\n%s", md)
+	}
+
+	// Importing the bundle into another vault restores the attachment there.
+	dest := t.TempDir()
+	t.Setenv("NOTED_VAULT", dest)
+	if err := importCmd.RunE(importCmd, []string{bundle}); err != nil {
+		t.Fatalf("import: %v", err)
+	}
+	// Imported attachments are stored under a hash of their content
+	sum := sha256.Sum256([]byte("png-bytes"))
+	stored := hex.EncodeToString(sum[:])[:16] + ".png"
+	if data, err := os.ReadFile(filepath.Join(dest, "assets", stored)); err != nil || string(data) != "png-bytes" {
+		t.Errorf("attachment not restored: %q, %v", data, err)
+	}
+	notes, _ := database.SearchNotesByTitle(ctx, "Design")
+	if !slices.ContainsFunc(notes, func(n db.Note) bool { return strings.HasPrefix(n.Content, "![diagram](assets/"+stored+")") }) {
+		t.Errorf("imported note should link to the restored attachment, got %+v", notes)
+	}
+}
+
+func TestImportRoamLinksPages(t *testing.T) {
+	defer setupTestDB(t)()
+	ctx := context.Background()
+	t.Setenv("NOTED_VAULT", t.TempDir())
+
+	graph := filepath.Join(t.TempDir(), "graph.json")
+	_ = os.WriteFile(graph, []byte(`[
+  {"title": "Inbox", "children": [{"string": "Read up on [[Zettelkasten]] and [[Later]] with @ana", "uid": "i1"}]},
+  {"title": "Zettelkasten", "children": [{"string": "One idea per note", "uid": "z1"}]}
+]`), 0o644)
+	if err := importCmd.RunE(importCmd, []string{graph}); err != nil {
+		t.Fatalf("import: %v", err)
+	}
+
+	// Inbox links to a page imported after it
+	target, err := database.ResolveNoteTitle(ctx, "Zettelkasten")
+	if err != nil {
+		t.Fatal(err)
+	}
+	backlinks, _ := database.GetBacklinks(ctx, target.ID)
+	if len(backlinks) != 1 || backlinks[0].Title != "Inbox" || backlinks[0].Content != "- Read up on [[Zettelkasten]] and [[Later]] with @ana\n" {
+		t.Errorf("backlinks = %+v", backlinks)
+	}
+
+	// Imported notes are indexed like added ones, without waiting for noted gc
+	if notes, _ := database.GetNotesMentioning(ctx, "ana"); len(notes) != 1 || notes[0].Title != "Inbox" {
+		t.Errorf("notes mentioning @ana = %+v", notes)
+	}
+	rows, _ := database.ListUnresolvedLinks(ctx)
+	if len(rows) != 1 || rows[0].Target != "Later" {
+		t.Errorf("unresolved links = %+v, want only [[Later]]", rows)
+	}
+}
+
+func TestAttachmentDedupAndUsage(t *testing.T) {
+	defer setupTestDB(t)()
+	ctx := context.Background()
+
+	src := t.TempDir()
+	for name, data := range map[string]string{"a/shot.png": "same", "b/copy.png": "same", "b/other.png": "other"} {
+		_ = os.MkdirAll(filepath.Join(src, filepath.Dir(name)), 0o755)
+		if err := os.WriteFile(filepath.Join(src, name), []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	vaultRoot := t.TempDir()
+	assets := dirAssets(vaultRoot)
+	first, _, err := withAttachments("![](a/shot.png)", src, assets)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, _, _ := withAttachments("![](b/copy.png) ![](b/other.png)", src, assets)
+	if !strings.HasPrefix(second, first) {
+		t.Errorf("identical files should share one stored copy: %q vs %q", first, second)
+	}
+	if entries, _ := os.ReadDir(filepath.Join(vaultRoot, "assets")); len(entries) != 2 {
+		t.Errorf("expected 2 stored files, got %d", len(entries))
+	}
+
+	// A legacy copy of the same content under another name, and a file nothing links to
+	_ = os.WriteFile(filepath.Join(vaultRoot, "assets", "shot.png"), []byte("same"), 0o644)
+	_ = os.WriteFile(filepath.Join(vaultRoot, "assets", "unused.bin"), []byte("12345"), 0o644)
+	_, _ = database.CreateNote(ctx, db.CreateNoteParams{Title: "One", Content: first})
+	two, _ := database.CreateNote(ctx, db.CreateNoteParams{Title: "Two", Content: second + " ![](assets/shot.png)"})
+	_, _ = database.TrashNote(ctx, two.ID)
+
+	files, err := assetUsage(ctx, vaultRoot)
+	if err != nil || len(files) != 4 {
+		t.Fatalf("assetUsage = %+v, %v", files, err)
+	}
+	refs := map[string]int{}
+	for _, f := range files {
+		refs[f.Name] = f.Refs
+	}
+	if refs["shot.png"] != 1 || refs["unused.bin"] != 0 || refs[strings.TrimSuffix(strings.TrimPrefix(first, "![](assets/"), ")")] != 2 {
+		t.Errorf("reference counts = %v", refs)
+	}
+	var names []string
+	for _, f := range reclaimableAssets(files) {
+		names = append(names, f.Name)
+	}
+	slices.Sort(names)
+	if !slices.Equal(names, []string{"shot.png", "unused.bin"}) {
+		t.Errorf("reclaimable = %v", names)
+	}
+}
+
+// ============================================================================
+// Garbage collection
+// ============================================================================
+
+func TestGC(t *testing.T) {
+	defer setupTestDB(t)()
+	ctx := context.Background()
+	vaultRoot := t.TempDir()
+	t.Setenv("NOTED_VAULT", vaultRoot)
+
+	_ = os.MkdirAll(filepath.Join(vaultRoot, "assets"), 0o755)
+	_ = os.WriteFile(filepath.Join(vaultRoot, "assets", "used.png"), []byte("used"), 0o644)
+	_ = os.WriteFile(filepath.Join(vaultRoot, "assets", "trashed.png"), []byte("trashed"), 0o644)
+	_ = os.WriteFile(filepath.Join(vaultRoot, "assets", "orphan.png"), []byte("orphan"), 0o644)
+	keep := createTestNote(t, "Keep", "![](assets/used.png)", []string{"work"})
+	trashed := createTestNote(t, "Trashed", "![](assets/trashed.png)", nil)
+	_, _ = database.TrashNote(ctx, trashed)
+
+	// Rows left behind by a database from before foreign keys were enforced
+	c, err := conn.Conn(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = c.ExecContext(ctx, "PRAGMA foreign_keys = OFF")
+	_, err = c.ExecContext(ctx, "INSERT INTO note_tags (note_id, tag_id) VALUES (999, 1)")
+	if err == nil {
+		_, err = c.ExecContext(ctx, "INSERT INTO note_links (source_note_id, target_note_id, link_text) VALUES (?, 999, 'Gone')", keep)
+	}
+	_, _ = c.ExecContext(ctx, "PRAGMA foreign_keys = ON")
+	_ = c.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := &config.Config{TrashDays: 30}
+	dry, err := runGC(ctx, gcCmd, cfg, true, false)
+	if err != nil {
+		t.Fatalf("gc --dry-run: %v", err)
+	}
+	if dry.NoteTags != 1 || dry.NoteLinks != 1 || dry.Attachments != 1 || dry.TrashPurged != 0 {
+		t.Errorf("dry run = %+v", dry)
+	}
+	if _, err := os.Stat(filepath.Join(vaultRoot, "assets", "orphan.png")); err != nil {
+		t.Error("a dry run should not remove anything")
+	}
+
+	res, err := runGC(ctx, gcCmd, cfg, false, false)
+	if err != nil {
+		t.Fatalf("gc: %v", err)
+	}
+	if res.NoteTags != 1 || res.NoteLinks != 1 || res.Attachments != 1 || res.DBSizeAfter == 0 {
+		t.Errorf("gc = %+v", res)
+	}
+	if _, err := os.Stat(filepath.Join(vaultRoot, "assets", "orphan.png")); !os.IsNotExist(err) {
+		t.Error("orphaned attachment should be removed")
+	}
+	for _, name := range []string{"used.png", "trashed.png"} {
+		if _, err := os.Stat(filepath.Join(vaultRoot, "assets", name)); err != nil {
+			t.Errorf("%s is still linked and should be kept", name)
+		}
+	}
+	if tags, _ := database.GetTagsForNote(ctx, keep); len(tags) != 1 {
+		t.Errorf("live tag rows should survive, got %v", tags)
+	}
+	if again, _ := runGC(ctx, gcCmd, cfg, true, false); again.NoteTags != 0 || again.NoteLinks != 0 || again.Attachments != 0 {
+		t.Errorf("a second run should find nothing, got %+v", again)
+	}
+
+	inc, err := runGC(ctx, gcCmd, cfg, false, true)
+	if err != nil {
+		t.Fatalf("runGC --incremental failed: %v", err)
+	}
+	if inc.Vacuum != "incremental" || res.Vacuum != "full" {
+		t.Errorf("vacuum modes = %q, %q", res.Vacuum, inc.Vacuum)
+	}
+}
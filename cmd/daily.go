@@ -10,7 +10,9 @@ import (
 	"strings"
 	"time"
 
+	"github.com/abdul-hamid-achik/noted/internal/config"
 	"github.com/abdul-hamid-achik/noted/internal/db"
+	"github.com/abdul-hamid-achik/noted/internal/notesync"
 	"github.com/spf13/cobra"
 )
 
@@ -39,12 +41,18 @@ var dailyCmd = &cobra.Command{
 	Long: `Manage daily notes in Obsidian style. Creates a note titled with the date,
 tagged "daily", and stored in a "Daily Notes" folder.
 
+A new daily note starts from the template named by --template, or else from
+NOTED_DAILY_TEMPLATE (default "daily") when such a template exists. Its
+{{date}} variables take the note's date, not today's.
+
 Examples:
   noted daily                              # Show/create today's daily note
   noted daily --append "- [ ] Buy milk"    # Append to today's note
   noted daily --prepend "Morning thoughts" # Prepend to today's note
   noted daily --yesterday                  # Show/create yesterday's note
   noted daily --date 2026-02-14            # Show/create note for a specific date
+  noted daily --edit                       # Open today's note in $EDITOR
+  noted daily --template journal           # Start a new note from the journal template
   noted daily --list                       # List recent daily notes`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		asJSON, _ := cmd.Flags().GetBool("json")
@@ -53,6 +61,8 @@ Examples:
 		dateStr, _ := cmd.Flags().GetString("date")
 		appendText, _ := cmd.Flags().GetString("append")
 		prependText, _ := cmd.Flags().GetString("prepend")
+		edit, _ := cmd.Flags().GetBool("edit")
+		templateName, _ := cmd.Flags().GetString("template")
 
 		ctx := context.Background()
 
@@ -74,7 +84,11 @@ Examples:
 
 		title := targetDate.Format(dailyDateFormat)
 
-		note, err := getOrCreateDailyNote(ctx, title)
+		initial, err := dailyTemplateContent(ctx, templateName, title, targetDate)
+		if err != nil {
+			return err
+		}
+		note, created, err := getOrCreateDailyNote(ctx, title, initial)
 		if err != nil {
 			return err
 		}
@@ -110,55 +124,108 @@ Examples:
 			}
 		}
 
+		if edit {
+			edited, err := openEditorWithContent(note.Content)
+			if err != nil {
+				return err
+			}
+			if edited != note.Content {
+				if err := notesync.SnapshotVersion(ctx, database, openVault(cmd), note.ID, note.Title, note.Content); err != nil {
+					return fmt.Errorf("failed to save version: %w", err)
+				}
+				note, err = database.UpdateNote(ctx, db.UpdateNoteParams{
+					Title:   note.Title,
+					Content: edited,
+					ID:      note.ID,
+				})
+				if err != nil {
+					return fmt.Errorf("failed to save daily note: %w", err)
+				}
+			}
+		}
+
+		if created || appendText != "" || prependText != "" || edit {
+			if updated, err := database.GetNote(ctx, note.ID); err == nil {
+				note = updated
+				notesync.WriteThrough(ctx, database, openVault(cmd), note) // picks up the folder too
+			}
+		}
 		return displayDailyNote(ctx, note, asJSON)
 	},
 }
 
-func getOrCreateDailyNote(ctx context.Context, title string) (db.Note, error) {
+// dailyTemplateContent returns the content a new daily note for date starts with: the named
+// template, or the configured daily template if one exists, interpolated for that date.
+func dailyTemplateContent(ctx context.Context, name, title string, date time.Time) (string, error) {
+	explicit := name != ""
+	if !explicit {
+		cfg, err := config.Load()
+		if err != nil {
+			return "", err
+		}
+		name = cfg.DailyTemplate
+	}
+	tmpl, err := database.GetTemplateByName(ctx, name)
+	if err != nil {
+		if err == sql.ErrNoRows && !explicit {
+			return "", nil
+		}
+		if err == sql.ErrNoRows {
+			return "", fmt.Errorf("template %q not found", name)
+		}
+		return "", fmt.Errorf("failed to get template: %w", err)
+	}
+	content, _, _ := cutCursor(interpolateTemplateAt(tmpl.Content, title, date))
+	return content, nil
+}
+
+// getOrCreateDailyNote returns the daily note titled title, creating it with content if needed,
+// and whether it was created.
+func getOrCreateDailyNote(ctx context.Context, title, content string) (db.Note, bool, error) {
 	note, err := database.GetNoteByTitle(ctx, title)
 	if err == nil {
-		return note, nil
+		return note, false, nil
 	}
 	if err != sql.ErrNoRows {
-		return db.Note{}, fmt.Errorf("failed to look up daily note: %w", err)
+		return db.Note{}, false, fmt.Errorf("failed to look up daily note: %w", err)
 	}
 
 	// Create new daily note
 	note, err = database.CreateNoteWithTTL(ctx, db.CreateNoteWithTTLParams{
 		Title:   title,
-		Content: "",
+		Content: content,
 	})
 	if err != nil {
-		return db.Note{}, fmt.Errorf("failed to create daily note: %w", err)
+		return db.Note{}, false, fmt.Errorf("failed to create daily note: %w", err)
 	}
 
 	// Tag as "daily"
 	tag, err := database.CreateTag(ctx, dailyTagName)
 	if err != nil {
-		return db.Note{}, fmt.Errorf("failed to create daily tag: %w", err)
+		return db.Note{}, false, fmt.Errorf("failed to create daily tag: %w", err)
 	}
 	err = database.AddTagToNote(ctx, db.AddTagToNoteParams{
 		NoteID: note.ID,
 		TagID:  tag.ID,
 	})
 	if err != nil {
-		return db.Note{}, fmt.Errorf("failed to tag daily note: %w", err)
+		return db.Note{}, false, fmt.Errorf("failed to tag daily note: %w", err)
 	}
 
 	// Find or create "Daily Notes" folder and move note into it
 	folderID, err := getOrCreateDailyFolder(ctx)
 	if err != nil {
-		return db.Note{}, err
+		return db.Note{}, false, err
 	}
 	err = database.MoveNoteToFolder(ctx, db.MoveNoteToFolderParams{
 		FolderID: sql.NullInt64{Int64: folderID, Valid: true},
 		ID:       note.ID,
 	})
 	if err != nil {
-		return db.Note{}, fmt.Errorf("failed to move note to Daily Notes folder: %w", err)
+		return db.Note{}, false, fmt.Errorf("failed to move note to Daily Notes folder: %w", err)
 	}
 
-	return note, nil
+	return note, true, nil
 }
 
 func getOrCreateDailyFolder(ctx context.Context) (int64, error) {
@@ -269,4 +336,6 @@ func init() {
 	dailyCmd.Flags().StringP("date", "d", "", "Show/create daily note for a specific date (YYYY-MM-DD)")
 	dailyCmd.Flags().StringP("append", "a", "", "Append content to the daily note")
 	dailyCmd.Flags().StringP("prepend", "p", "", "Prepend content to the daily note")
+	dailyCmd.Flags().BoolP("edit", "e", false, "Open the daily note in $EDITOR")
+	dailyCmd.Flags().StringP("template", "t", "", "Template for a new daily note (default: NOTED_DAILY_TEMPLATE, or \"daily\")")
 }
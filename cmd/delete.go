@@ -9,10 +9,11 @@ import (
 	"database/sql"
 	"fmt"
 	"os"
-	"strconv"
 	"strings"
 
+	"github.com/abdul-hamid-achik/noted/internal/config"
 	"github.com/abdul-hamid-achik/noted/internal/notesync"
+	"github.com/abdul-hamid-achik/noted/internal/veclite"
 	"github.com/spf13/cobra"
 )
 
@@ -23,23 +24,25 @@ type deleteResult struct {
 
 var deleteCmd = &cobra.Command{
 	Use:   "delete <id> [id...]",
-	Short: "Delete notes",
-	Args:  cobra.MinimumNArgs(1),
+	Short: "Move notes to the trash",
+	Long: `Move notes to the trash. Trashed notes disappear from lists, search, and
+the vault, but keep their tags, links, and history until they are purged.
+
+Bring a note back with "noted trash restore <id>"; remove it for good with
+"noted trash empty". Trashed notes are purged automatically after
+NOTED_TRASH_DAYS days (default 30).`,
+	Args: cobra.MinimumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		force, _ := cmd.Flags().GetBool("force")
 		asJSON, _ := cmd.Flags().GetBool("json")
 
-		ids := make([]int64, 0, len(args))
-		for _, arg := range args {
-			id, err := strconv.ParseInt(arg, 10, 64)
-			if err != nil {
-				return fmt.Errorf("invalid note ID: %s", arg)
-			}
-			ids = append(ids, id)
+		ids, err := parseNoteIDs(args)
+		if err != nil {
+			return err
 		}
 
 		if !force {
-			fmt.Printf("Delete %d note(s)? [y/N]: ", len(ids))
+			fmt.Printf("Move %d note(s) to the trash? [y/N]: ", len(ids))
 			reader := bufio.NewReader(os.Stdin)
 			response, err := reader.ReadString('\n')
 			if err != nil {
@@ -56,8 +59,7 @@ var deleteCmd = &cobra.Command{
 		vlt := openVault(cmd)
 		deletedIDs := make([]int64, 0, len(ids))
 		for _, id := range ids {
-			_, err := database.GetNote(ctx, id)
-			if err != nil {
+			if err := notesync.Trash(ctx, database, vlt, id); err != nil {
 				if err == sql.ErrNoRows {
 					if !asJSON {
 						fmt.Fprintf(os.Stderr, "note #%d not found\n", id)
@@ -66,17 +68,14 @@ var deleteCmd = &cobra.Command{
 				}
 				return err
 			}
-
-			if err := database.DeleteNote(ctx, id); err != nil {
-				return fmt.Errorf("failed to delete note #%d: %w", id, err)
-			}
 			if !asJSON {
-				fmt.Printf("Deleted note #%d\n", id)
+				fmt.Printf("Moved note #%d to the trash\n", id)
 			}
-			notesync.Delete(vlt, id) // remove the note's vault file too
 			deletedIDs = append(deletedIDs, id)
 		}
 
+		deleteVectors(deletedIDs...)
+
 		if asJSON {
 			return outputJSON(deleteResult{
 				DeletedCount: len(deletedIDs),
@@ -85,7 +84,7 @@ var deleteCmd = &cobra.Command{
 		}
 
 		if len(deletedIDs) > 0 {
-			fmt.Printf("\n%d note(s) deleted.\n", len(deletedIDs))
+			fmt.Printf("\n%d note(s) moved to the trash. Restore with: noted trash restore <id>\n", len(deletedIDs))
 		}
 
 		return nil
@@ -98,3 +97,19 @@ func init() {
 	deleteCmd.Flags().BoolP("force", "f", false, "Skip confirmation")
 	deleteCmd.Flags().BoolP("json", "j", false, "Output as JSON")
 }
+
+// deleteVectors removes deleted or trashed notes from the semantic index when one is configured, so
+// they don't surface as ghost results. Failures only warn: the notes are already gone. A restored
+// note is re-embedded by the next sync.
+func deleteVectors(ids ...int64) {
+	if len(ids) == 0 {
+		return
+	}
+	cfg, err := config.Load()
+	if err != nil || cfg.VeclitePath == "" {
+		return
+	}
+	if err := veclite.DeleteNotes(cfg.VeclitePath, ids...); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: failed to remove vectors (run `noted sync --force` later): %v\n", err)
+	}
+}
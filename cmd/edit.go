@@ -7,8 +7,11 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"os"
+	"slices"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/abdul-hamid-achik/noted/internal/db"
 	"github.com/abdul-hamid-achik/noted/internal/notesync"
@@ -23,11 +26,28 @@ type editResult struct {
 var editCmd = &cobra.Command{
 	Use:   "edit <id>",
 	Short: "Edit a note",
-	Args:  cobra.ExactArgs(1),
+	Long: `Edit a note's title, content, tags, folder, pin, or metadata. Only the
+fields given are changed. With no flags the content opens in your editor
+(NOTED_EDITOR, then $VISUAL, then $EDITOR).
+
+--source and --source-ref change where the note is recorded as coming from;
+give "" to clear one. --ttl sets the note to expire that long from now (24h,
+7d), and --ttl never keeps it for good.
+
+If the note is saved elsewhere (the TUI, an MCP client, a vault sync) while it
+is open in the editor, both versions are written to temp files and you choose
+to merge them in the editor (a three-way merge with conflict markers), keep
+yours, keep the stored one, or abort without saving.`,
+	Args: cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		title, _ := cmd.Flags().GetString("title")
 		content, _ := cmd.Flags().GetString("content")
 		tags, _ := cmd.Flags().GetString("tags")
+		folderID, _ := cmd.Flags().GetInt64("folder")
+		pinned, _ := cmd.Flags().GetBool("pinned")
+		source, _ := cmd.Flags().GetString("source")
+		sourceRef, _ := cmd.Flags().GetString("source-ref")
+		ttl, _ := cmd.Flags().GetString("ttl")
 		asJSON, _ := cmd.Flags().GetBool("json")
 
 		id, err := strconv.ParseInt(args[0], 10, 64)
@@ -53,13 +73,46 @@ var editCmd = &cobra.Command{
 
 		if cmd.Flags().Changed("content") {
 			newContent = content
-		} else if !cmd.Flags().Changed("title") && !cmd.Flags().Changed("tags") {
+		} else if !slices.ContainsFunc([]string{"title", "tags", "folder", "pinned", "source", "source-ref", "ttl"}, cmd.Flags().Changed) {
 			// No flags provided, open editor with current content
 			edited, err := openEditorWithContent(note.Content)
 			if err != nil {
 				return err
 			}
 			newContent = edited
+
+			// Someone else (the TUI, an MCP client, a vault sync) may have saved the note meanwhile
+			current, err := database.GetNote(ctx, id)
+			if err != nil {
+				return fmt.Errorf("failed to re-read note: %w", err)
+			}
+			if noteChangedSince(note, current) {
+				if newContent != note.Content {
+					newContent, err = resolveEditConflict(id, note.Content, newContent, current.Content, os.Stdin, os.Stdout)
+					if err != nil {
+						return err
+					}
+				} else {
+					newContent = current.Content // nothing edited here; keep theirs
+				}
+				newTitle = current.Title
+				note = current
+			}
+		}
+
+		var expiresAt sql.NullTime
+		if ttl != "" && ttl != "never" {
+			dur, err := parseDuration(ttl)
+			if err != nil {
+				return fmt.Errorf("invalid TTL: %w", err)
+			}
+			expiresAt = sql.NullTime{Time: time.Now().Add(dur), Valid: true}
+		}
+
+		if newContent != note.Content {
+			if err := checkNoteQuota(ctx, cmd, newContent, false); err != nil {
+				return err
+			}
 		}
 
 		// Auto-save current state as a version before updating (only if something changed)
@@ -107,6 +160,41 @@ var editCmd = &cobra.Command{
 			}
 		}
 
+		if cmd.Flags().Changed("folder") {
+			folder := sql.NullInt64{Int64: folderID, Valid: folderID != 0}
+			if err := database.MoveNoteToFolder(ctx, db.MoveNoteToFolderParams{FolderID: folder, ID: id}); err != nil {
+				return fmt.Errorf("failed to move note: %w", err)
+			}
+		}
+		if cmd.Flags().Changed("pinned") {
+			if pinned {
+				err = database.PinNote(ctx, id)
+			} else {
+				err = database.UnpinNote(ctx, id)
+			}
+			if err != nil {
+				return fmt.Errorf("failed to pin note: %w", err)
+			}
+		}
+
+		if cmd.Flags().Changed("source") || cmd.Flags().Changed("source-ref") {
+			params := db.UpdateNoteSourceParams{ID: id, Source: note.Source, SourceRef: note.SourceRef}
+			if cmd.Flags().Changed("source") {
+				params.Source = sql.NullString{String: source, Valid: source != ""}
+			}
+			if cmd.Flags().Changed("source-ref") {
+				params.SourceRef = sql.NullString{String: sourceRef, Valid: sourceRef != ""}
+			}
+			if err := database.UpdateNoteSource(ctx, params); err != nil {
+				return fmt.Errorf("failed to set source: %w", err)
+			}
+		}
+		if cmd.Flags().Changed("ttl") {
+			if err := database.SetNoteExpiry(ctx, db.SetNoteExpiryParams{ExpiresAt: expiresAt, ID: id}); err != nil {
+				return fmt.Errorf("failed to set expiry: %w", err)
+			}
+		}
+
 		if updated, err := database.GetNote(ctx, id); err == nil {
 			notesync.WriteThrough(ctx, database, openVault(cmd), updated)
 		}
@@ -129,5 +217,10 @@ func init() {
 	editCmd.Flags().StringP("title", "t", "", "New title")
 	editCmd.Flags().StringP("content", "c", "", "New content")
 	editCmd.Flags().StringP("tags", "T", "", "Replace tags (comma-separated)")
+	editCmd.Flags().Int64("folder", 0, "Move to this folder ID (0 removes the note from its folder)")
+	editCmd.Flags().Bool("pinned", false, "Pin the note (--pinned=false unpins it)")
+	editCmd.Flags().String("source", "", "Set the source identifier (\"\" clears it)")
+	editCmd.Flags().String("source-ref", "", "Set the source reference (\"\" clears it)")
+	editCmd.Flags().String("ttl", "", "Expire the note this long from now (e.g. '24h', '7d'), or 'never'")
 	editCmd.Flags().BoolP("json", "j", false, "Output as JSON")
 }
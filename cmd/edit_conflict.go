@@ -0,0 +1,115 @@
+/*
+Copyright © 2026 abdul hamid <abdulachik@icloud.com>
+*/
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/abdul-hamid-achik/noted/internal/db"
+)
+
+// noteChangedSince reports whether a note was written after before was read. The content is
+// compared as well because updated_at only has one-second resolution.
+func noteChangedSince(before, now db.Note) bool {
+	return !before.UpdatedAt.Time.Equal(now.UpdatedAt.Time) ||
+		before.Title != now.Title || before.Content != now.Content
+}
+
+// resolveEditConflict handles a note that changed while it was open in the editor. Both versions
+// are written to temp files and the user chooses to merge them in the editor, keep theirs, or
+// keep the stored one. It returns the content to save; aborting returns an error naming the
+// file that still holds the user's edit.
+func resolveEditConflict(id int64, base, mine, theirs string, in io.Reader, out io.Writer) (string, error) {
+	dir, err := os.MkdirTemp("", fmt.Sprintf("noted-%d-conflict-*", id))
+	if err != nil {
+		return "", err
+	}
+	minePath := filepath.Join(dir, "mine.md")
+	theirsPath := filepath.Join(dir, "theirs.md")
+	if err := os.WriteFile(minePath, []byte(mine), 0o600); err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(theirsPath, []byte(theirs), 0o600); err != nil {
+		return "", err
+	}
+
+	fmt.Fprintf(out, "Note #%d was changed by someone else while you were editing it.\n", id)
+	fmt.Fprintf(out, "  Your version:   %s\n", minePath)
+	fmt.Fprintf(out, "  Stored version: %s\n", theirsPath)
+	fmt.Fprint(out, "[m]erge in editor, keep [y]ours, keep [s]tored, or [a]bort? [m/y/s/A]: ")
+
+	response, err := bufio.NewReader(in).ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", err
+	}
+
+	var resolved string
+	switch strings.TrimSpace(strings.ToLower(response)) {
+	case "m", "merge":
+		merged, err := openEditorWithContent(mergeText(base, mine, theirs))
+		if err != nil {
+			return "", fmt.Errorf("merge failed (your version is in %s): %w", minePath, err)
+		}
+		if strings.Contains(merged, "<<<<<<< ") {
+			return "", fmt.Errorf("conflict markers left in the merge; nothing saved (your version is in %s)", minePath)
+		}
+		resolved = merged
+	case "y", "yours":
+		resolved = mine
+	case "s", "stored":
+		resolved = theirs
+	default:
+		return "", fmt.Errorf("edit aborted; nothing saved (your version is in %s)", minePath)
+	}
+	_ = os.RemoveAll(dir)
+	return resolved, nil
+}
+
+// mergeText three-way merges two edits of base with git merge-file, leaving conflict markers
+// where both changed the same lines. Without git, the two versions are wrapped in markers whole.
+func mergeText(base, mine, theirs string) string {
+	if merged, ok := gitMergeFile(base, mine, theirs); ok {
+		return merged
+	}
+	return "<<<<<<< yours\n" + withNewline(mine) + "=======\n" + withNewline(theirs) + ">>>>>>> stored\n"
+}
+
+func gitMergeFile(base, mine, theirs string) (string, bool) {
+	dir, err := os.MkdirTemp("", "noted-merge-*")
+	if err != nil {
+		return "", false
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	paths := make([]string, 3)
+	for i, text := range []string{mine, base, theirs} {
+		paths[i] = filepath.Join(dir, fmt.Sprintf("%d.md", i))
+		if err := os.WriteFile(paths[i], []byte(text), 0o600); err != nil {
+			return "", false
+		}
+	}
+	out, err := exec.Command("git", "merge-file", "-p",
+		"-L", "yours", "-L", "original", "-L", "stored",
+		paths[0], paths[1], paths[2]).Output()
+	// An exit status of 1-127 is the number of conflicts, which is still a usable merge
+	var exitErr *exec.ExitError
+	if err != nil && !(errors.As(err, &exitErr) && exitErr.ExitCode() > 0 && exitErr.ExitCode() < 128) {
+		return "", false
+	}
+	return string(out), true
+}
+
+func withNewline(s string) string {
+	if s == "" || strings.HasSuffix(s, "\n") {
+		return s
+	}
+	return s + "\n"
+}
@@ -4,16 +4,95 @@ Copyright © 2026 abdul hamid <abdulachik@icloud.com>
 package cmd
 
 import (
+	"fmt"
 	"os"
 	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/abdul-hamid-achik/noted/internal/config"
 )
 
-func getEditor() string {
-	editor := os.Getenv("EDITOR")
-	if editor == "" {
-		editor = "nvim"
+// editorFallbacks are tried in order when no editor is configured.
+var editorFallbacks = func() []string {
+	if runtime.GOOS == "windows" {
+		return []string{"nvim", "vim", "nano", "notepad"}
+	}
+	return []string{"nvim", "vim", "nano", "vi"}
+}()
+
+// getEditor returns the editor command and its arguments: NOTED_EDITOR, then $VISUAL, then
+// $EDITOR, then the first of editorFallbacks found on PATH.
+func getEditor() ([]string, error) {
+	var configured string
+	if cfg, err := config.Load(); err == nil {
+		configured = cfg.Editor
+	}
+	for _, setting := range []struct{ name, value string }{
+		{"NOTED_EDITOR", configured},
+		{"VISUAL", os.Getenv("VISUAL")},
+		{"EDITOR", os.Getenv("EDITOR")},
+	} {
+		if strings.TrimSpace(setting.value) == "" {
+			continue
+		}
+		argv, err := splitCommandLine(setting.value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", setting.name, err)
+		}
+		if _, err := exec.LookPath(argv[0]); err != nil {
+			return nil, fmt.Errorf("editor %q from %s not found: %w", argv[0], setting.name, err)
+		}
+		return argv, nil
+	}
+	for _, name := range editorFallbacks {
+		if _, err := exec.LookPath(name); err == nil {
+			return []string{name}, nil
+		}
+	}
+	return nil, fmt.Errorf("no editor found (tried %s): set NOTED_EDITOR, VISUAL, or EDITOR", strings.Join(editorFallbacks, ", "))
+}
+
+// splitCommandLine splits an editor setting into words. Single or double quotes group words
+// containing spaces, such as a Windows path; there are no escape sequences.
+func splitCommandLine(s string) ([]string, error) {
+	var words []string
+	var word strings.Builder
+	inWord := false
+	var quote rune
+	for _, r := range s {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				word.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote, inWord = r, true
+		case r == ' ' || r == '\t':
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+		default:
+			word.WriteRune(r)
+			inWord = true
+		}
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated quote in %q", s)
+	}
+	if inWord {
+		words = append(words, word.String())
+	}
+	if len(words) == 0 {
+		return nil, fmt.Errorf("empty command")
 	}
-	return editor
+	return words, nil
 }
 
 func openEditor() (string, error) {
@@ -21,35 +100,72 @@ func openEditor() (string, error) {
 }
 
 func openEditorWithContent(initial string) (string, error) {
-	editor := getEditor()
+	return openEditorAt(initial, 0, 0)
+}
 
-	tmpFile, err := os.CreateTemp("", "noted-*.md")
+// openEditorAt is openEditorWithContent with the cursor placed at line and col (1-based) for
+// editors that accept a start position. A zero line leaves the cursor where the editor puts it.
+func openEditorAt(initial string, line, col int) (string, error) {
+	editor, err := getEditor()
 	if err != nil {
 		return "", err
 	}
-	defer func() { _ = os.Remove(tmpFile.Name()) }()
 
-	if initial != "" {
-		if _, err := tmpFile.WriteString(initial); err != nil {
-			_ = tmpFile.Close()
-			return "", err
-		}
+	// A file of its own in a fresh directory: editors that save by replacing the file (or lock it,
+	// as some do on Windows) never collide with another session's temp file.
+	dir, err := os.MkdirTemp("", "noted-edit-*")
+	if err != nil {
+		return "", err
+	}
+	defer removeTempDir(dir)
+	path := filepath.Join(dir, "note.md")
+	if err := os.WriteFile(path, []byte(initial), 0o600); err != nil {
+		return "", err
 	}
-	_ = tmpFile.Close()
 
-	cmd := exec.Command(editor, tmpFile.Name())
+	args := append(editor[1:len(editor):len(editor)], editorCursorArgs(editor[0], line, col)...)
+	cmd := exec.Command(editor[0], append(args, path)...)
 	cmd.Stdin = os.Stdin
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
 
 	if err := cmd.Run(); err != nil {
-		return "", err
+		return "", fmt.Errorf("editor %s failed: %w", editor[0], err)
 	}
 
-	content, err := os.ReadFile(tmpFile.Name())
+	content, err := os.ReadFile(path)
 	if err != nil {
 		return "", err
 	}
 
 	return string(content), nil
 }
+
+// removeTempDir removes an editor temp dir. On Windows a file can stay locked for a moment after
+// the editor exits, so removal is retried briefly before giving up.
+func removeTempDir(dir string) {
+	for attempt := 0; attempt < 5; attempt++ {
+		if err := os.RemoveAll(dir); err == nil || runtime.GOOS != "windows" {
+			return
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+}
+
+// editorCursorArgs returns the arguments that open a file at line and col in editors that
+// support it.
+func editorCursorArgs(editor string, line, col int) []string {
+	if line <= 0 {
+		return nil
+	}
+	switch strings.TrimSuffix(filepath.Base(editor), ".exe") {
+	case "vi", "vim", "nvim", "gvim":
+		return []string{fmt.Sprintf("+call cursor(%d,%d)", line, col)}
+	case "nano":
+		return []string{fmt.Sprintf("+%d,%d", line, col)}
+	case "emacs", "emacsclient", "micro", "kak":
+		return []string{fmt.Sprintf("+%d:%d", line, col)}
+	default:
+		return nil
+	}
+}
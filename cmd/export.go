@@ -4,16 +4,22 @@ Copyright © 2026 abdul hamid <abdulachik@icloud.com>
 package cmd
 
 import (
+	"archive/zip"
 	"context"
+	"crypto/sha256"
 	"database/sql"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
 	"strings"
-	"time"
 
 	"github.com/abdul-hamid-achik/noted/internal/db"
+	"github.com/abdul-hamid-achik/noted/internal/notesync"
+	"github.com/abdul-hamid-achik/noted/internal/vault"
 	"github.com/spf13/cobra"
 )
 
@@ -29,6 +35,55 @@ type exportedNote struct {
 	SourceRef string   `json:"source_ref,omitempty"`
 }
 
+// exportFilter narrows the notes selected by --tag. Zero values mean "no filter".
+type exportFilter struct {
+	folderID  int64
+	hasFolder bool
+	pinned    bool
+	archived  bool
+	query     string
+	since     sql.NullTime // updated at or after
+	before    sql.NullTime // updated before
+}
+
+// filterExportNotes keeps only the notes matching every filter in f. --query uses the same
+// search as "noted grep --archived" (FTS5 syntax, titles, content, and tag names), without a
+// limit, so a candidate is kept however it ranks against the rest of the database.
+func filterExportNotes(ctx context.Context, notes []db.Note, f exportFilter) ([]db.Note, error) {
+	var matched map[int64]bool
+	if f.query != "" {
+		results, err := db.SearchNotesArchived(ctx, conn, f.query, -1)
+		if err != nil {
+			return nil, fmt.Errorf("search failed: %w", err)
+		}
+		matched = make(map[int64]bool, len(results))
+		for _, r := range results {
+			matched[r.Note.ID] = true
+		}
+	}
+
+	filtered := notes[:0:0]
+	for _, n := range notes {
+		if f.hasFolder && (!n.FolderID.Valid || n.FolderID.Int64 != f.folderID) {
+			continue
+		}
+		if f.pinned && !n.Pinned.Bool {
+			continue
+		}
+		if !db.UpdatedBetween(n, f.since, f.before) {
+			continue
+		}
+		if f.archived && !n.ArchivedAt.Valid {
+			continue
+		}
+		if matched != nil && !matched[n.ID] {
+			continue
+		}
+		filtered = append(filtered, n)
+	}
+	return filtered, nil
+}
+
 var exportCmd = &cobra.Command{
 	Use:   "export",
 	Short: "Export notes",
@@ -38,32 +93,96 @@ Supported formats:
   - markdown: Single file with YAML frontmatter (default)
   - json: JSON array
   - jsonl: JSON Lines (one JSON object per line)
+  - zip: One markdown file per note plus an assets/ directory holding the
+    images and files the notes link to, with the links rewritten to point
+    there. Relative links resolve against the vault. noted import reads it back.
+  - dir: The same files as zip, written into the directory given by -o. A
+    note's file is rewritten in place when it changes, even if the note was
+    renamed, and files of notes that were since deleted are removed.
+
+With --changed-only, a dir export rewrites only the files whose content
+changed since the last export to the same directory, whether through an edit,
+new tags, a pin, or different templates. Mirroring a large database into a
+vault or a static site then leaves untouched files alone. It implies
+--format dir, as does an -o naming an existing directory.
+
+--layout gives a Go template (text/template) for each note's file, in place
+of the built-in frontmatter, and --filename a template for its path, so files
+can follow a static site's conventions. Both see the note's .ID, .Title,
+.Slug, .Content, .Tags, .Aliases, .Folder, .Pinned, .Draft (tagged "draft"),
+.Source, .CreatedAt and .UpdatedAt, and can call join, lower, upper, quote
+and slug.
+
+With --backlinks, markdown and zip exports end each note with a "## Backlinks"
+section listing the notes that link to it: relative links to their files in a
+zip, [[wikilinks]] in a single markdown file.
 
 Examples:
   noted export                              # Export all as markdown to stdout
   noted export --format json -o notes.json  # Export as JSON to file
   noted export --format jsonl               # Export as JSON Lines
+  noted export --format zip -o notes.zip    # Export notes with their attachments
   noted export --tag project                # Export only notes with 'project' tag
-  noted export --since 2025-01-01           # Export notes created since date`,
+  noted export --since 2025-01-01           # Export notes updated since date
+  noted export --since 7d -f jsonl          # Export notes updated in the last week
+  noted export --folder 3 --pinned          # Export pinned notes in folder #3
+  noted export --archived                   # Export only archived notes
+  noted export --query "roadmap OR launch"  # Export notes matching a search
+  noted export -f zip --backlinks -o out.zip # Keep backlinks in the exported files
+  noted export --changed-only -o ./site/notes # Mirror notes, rewriting only changed ones
+  noted export -o ./site/content/posts --layout hugo.tmpl \
+    --filename '{{.CreatedAt.Format "2006/01"}}/{{.Slug}}.md'
+
+--since and --until select notes by when they were last updated, each a date
+(YYYY-MM-DD), an RFC 3339 time, or a duration back from now such as 7d.
+
+Filters combine: only notes matching all of them are exported.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		format, _ := cmd.Flags().GetString("format")
 		output, _ := cmd.Flags().GetString("output")
 		tag, _ := cmd.Flags().GetString("tag")
-		since, _ := cmd.Flags().GetString("since")
+		sinceStr, _ := cmd.Flags().GetString("since")
+		untilStr, _ := cmd.Flags().GetString("until")
+		folderID, _ := cmd.Flags().GetInt64("folder")
+		pinned, _ := cmd.Flags().GetBool("pinned")
+		archived, _ := cmd.Flags().GetBool("archived")
+		query, _ := cmd.Flags().GetString("query")
+		backlinks, _ := cmd.Flags().GetBool("backlinks")
+		changedOnly, _ := cmd.Flags().GetBool("changed-only")
+		layout, _ := cmd.Flags().GetString("layout")
+		filename, _ := cmd.Flags().GetString("filename")
+
+		if !cmd.Flags().Changed("format") {
+			if fi, err := os.Stat(output); changedOnly || (output != "" && err == nil && fi.IsDir()) {
+				format = "dir"
+			}
+		}
+		if format == "dir" && output == "" {
+			return fmt.Errorf("--format dir needs a directory to write to (-o)")
+		}
+		if changedOnly && format != "dir" {
+			return fmt.Errorf("--changed-only only works with --format dir")
+		}
+		if layout != "" && (format == "json" || format == "jsonl") {
+			return fmt.Errorf("--layout only works with markdown, zip, and dir exports")
+		}
+		if filename != "" && format != "zip" && format != "dir" {
+			return fmt.Errorf("--filename only works with zip and dir exports")
+		}
+		tmpls, err := loadExportTemplates(layout, filename)
+		if err != nil {
+			return err
+		}
+		since, before, err := parseUpdatedWindow(sinceStr, untilStr)
+		if err != nil {
+			return err
+		}
 
 		ctx := context.Background()
 		var notes []db.Note
-		var err error
 
 		if tag != "" {
 			notes, err = database.GetNotesByTagName(ctx, tag)
-		} else if since != "" {
-			// Parse since date
-			sinceTime, parseErr := time.Parse("2006-01-02", since)
-			if parseErr != nil {
-				return fmt.Errorf("invalid --since date format (use YYYY-MM-DD): %w", parseErr)
-			}
-			notes, err = database.GetNotesSince(ctx, sql.NullTime{Time: sinceTime, Valid: true})
 		} else {
 			notes, err = database.GetAllNotes(ctx)
 		}
@@ -71,6 +190,23 @@ Examples:
 			return err
 		}
 
+		notes, err = filterExportNotes(ctx, notes, exportFilter{
+			folderID:  folderID,
+			hasFolder: cmd.Flags().Changed("folder"),
+			pinned:    pinned,
+			archived:  archived,
+			query:     strings.TrimSpace(query),
+			since:     since,
+			before:    before,
+		})
+		if err != nil {
+			return err
+		}
+
+		if format == "dir" {
+			return exportDirTo(ctx, notes, output, vaultDir(cmd), changedOnly, tmpls)
+		}
+
 		if len(notes) == 0 {
 			fmt.Fprintln(os.Stderr, "No notes to export.")
 			return nil
@@ -92,9 +228,11 @@ Examples:
 		case "jsonl":
 			return exportJSONL(ctx, w, notes)
 		case "markdown":
-			return exportMarkdown(ctx, w, notes)
+			return exportMarkdown(ctx, w, notes, backlinks, tmpls)
+		case "zip":
+			return exportZip(ctx, w, notes, vaultDir(cmd), backlinks, tmpls)
 		default:
-			return fmt.Errorf("unknown format: %s (use 'markdown', 'json', or 'jsonl')", format)
+			return fmt.Errorf("unknown format: %s (use 'markdown', 'json', 'jsonl', 'zip', or 'dir')", format)
 		}
 	},
 }
@@ -162,12 +300,73 @@ func exportJSONL(ctx context.Context, w io.Writer, notes []db.Note) error {
 	return nil
 }
 
-func exportMarkdown(ctx context.Context, w io.Writer, notes []db.Note) error {
+// backlinksSection returns a "## Backlinks" section listing the notes that link to note, or "" if
+// none do. A linking note is written as a relative link to the file link returns for it, or as a
+// [[wikilink]] when link reports none.
+func backlinksSection(ctx context.Context, note db.Note, link func(id int64) (string, bool)) (string, error) {
+	from, err := database.GetBacklinks(ctx, note.ID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get backlinks for #%d: %w", note.ID, err)
+	}
+	if len(from) == 0 {
+		return "", nil
+	}
+	var b strings.Builder
+	b.WriteString("\n## Backlinks\n\n")
+	for _, n := range from {
+		if file, ok := link(n.ID); ok {
+			fmt.Fprintf(&b, "- [%s](%s)\n", n.Title, file)
+		} else {
+			fmt.Fprintf(&b, "- [[%s]]\n", n.Title)
+		}
+	}
+	return b.String(), nil
+}
+
+// withBacklinks appends note's backlinks section (see backlinksSection) to its content.
+func withBacklinks(ctx context.Context, note db.Note, link func(id int64) (string, bool)) (string, error) {
+	section, err := backlinksSection(ctx, note, link)
+	if err != nil || section == "" {
+		return note.Content, err
+	}
+	content := note.Content
+	if content != "" && !strings.HasSuffix(content, "\n") {
+		content += "\n"
+	}
+	return content + section, nil
+}
+
+// exportMarkdown writes notes one after another into a single markdown file, each with YAML
+// frontmatter or laid out by the --layout template.
+func exportMarkdown(ctx context.Context, w io.Writer, notes []db.Note, backlinks bool, tmpls exportTemplates) error {
+	noFile := func(int64) (string, bool) { return "", false }
 	for i, note := range notes {
 		tags, err := database.GetTagsForNote(ctx, note.ID)
 		if err != nil {
 			return err
 		}
+		if backlinks {
+			if note.Content, err = withBacklinks(ctx, note, noFile); err != nil {
+				return err
+			}
+		}
+
+		if tmpls.layout != nil {
+			text, err := tmpls.fileContent(newLayoutNote(note, notesync.VaultNote(ctx, database, note)), nil)
+			if err != nil {
+				return err
+			}
+			if text != "" && !strings.HasSuffix(text, "\n") {
+				text += "\n"
+			}
+			if i < len(notes)-1 {
+				text += "\n"
+			}
+			if _, err := io.WriteString(w, text); err != nil {
+				return err
+			}
+			continue
+		}
 
 		tagNames := make([]string, len(tags))
 		for j, t := range tags {
@@ -222,11 +421,269 @@ func exportMarkdown(ctx context.Context, w io.Writer, notes []db.Note) error {
 	return nil
 }
 
+// exportZip writes a zip bundle: each note as <slug>.md in the vault file format, and the local
+// files the notes link to under assets/, with the links rewritten to match. Relative attachment
+// paths resolve against baseDir (the vault). Links to missing files are left as written and reported.
+// With backlinks, each note ends with links to the files of the exported notes that link to it.
+// tmpls can name and lay out the files differently.
+func exportZip(ctx context.Context, w io.Writer, notes []db.Note, baseDir string, backlinks bool, tmpls exportTemplates) error {
+	zw := zip.NewWriter(w)
+	assets := zipAssets(zw)
+	var missing []string
+
+	// Name every file first, so backlinks can point at notes written later
+	seen := map[string]bool{}
+	files := make(map[int64]string, len(notes))
+	for _, note := range notes {
+		name, err := tmpls.fileName(newLayoutNote(note, notesync.VaultNote(ctx, database, note)))
+		if err != nil {
+			return err
+		}
+		name = uniqueFileName(name, func(n string) bool { return seen[n] })
+		seen[name] = true
+		files[note.ID] = name
+	}
+
+	for _, note := range notes {
+		file := files[note.ID]
+		fileFor := func(id int64) (string, bool) {
+			f, ok := files[id]
+			return relativeLink(file, f), ok
+		}
+		vn := notesync.VaultNote(ctx, database, note)
+		if backlinks {
+			var err error
+			if vn.Content, err = withBacklinks(ctx, note, fileFor); err != nil {
+				return err
+			}
+		}
+		content, noFile, err := withAttachmentsLinked(vn.Content, baseDir, assets, func(stored string) string {
+			return relativeLink(file, stored)
+		})
+		if err != nil {
+			return err
+		}
+		vn.Content = content
+		for _, m := range noFile {
+			missing = append(missing, fmt.Sprintf("#%d: %s", note.ID, m))
+		}
+		text, err := tmpls.fileContent(newLayoutNote(note, vn), func() string { return vault.Serialize(vn) })
+		if err != nil {
+			return err
+		}
+
+		f, err := zw.Create(file)
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(f, text); err != nil {
+			return err
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return err
+	}
+
+	for _, m := range missing {
+		fmt.Fprintf(os.Stderr, "warning: attachment not found, link kept as is (%s)\n", m)
+	}
+	fmt.Fprintf(os.Stderr, "Exported %d note(s) and %d attachment(s).\n", len(notes), len(assets.names))
+	return nil
+}
+
+// exportDirTo runs a dir export to output and reports it. With changedOnly, only files whose
+// content changed since the last export to output are rewritten.
+func exportDirTo(ctx context.Context, notes []db.Note, output, baseDir string, changedOnly bool, tmpls exportTemplates) error {
+	dest, err := filepath.Abs(output)
+	if err != nil {
+		return err
+	}
+	stats, err := exportDir(ctx, dest, notes, baseDir, dirExport{templates: tmpls, changedOnly: changedOnly})
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "Exported %d note(s) to %s", stats.written, dest)
+	if stats.unchanged > 0 {
+		fmt.Fprintf(os.Stderr, ", %d unchanged", stats.unchanged)
+	}
+	if stats.removed > 0 {
+		fmt.Fprintf(os.Stderr, ", removed %d deleted", stats.removed)
+	}
+	fmt.Fprintln(os.Stderr, ".")
+	return nil
+}
+
+// dirExport says how exportDir lays out a directory. Its zero value writes the files of a zip
+// export.
+type dirExport struct {
+	templates exportTemplates
+	// prepare, when set, adjusts what each note's file is named and laid out from, before its
+	// attachments are collected. The default layout only sees changes to the content.
+	prepare func(ln *layoutNote)
+	// assets stores attachments, and link links to a stored copy, given its path relative to the
+	// assets' root, from a note's file. By default they are stored in dir/assets and linked
+	// relative to the file.
+	assets *assetSet
+	link   func(file, stored string) string
+	// exclusive removes the files of notes that aren't being exported, so dir holds just them.
+	exclusive bool
+	// changedOnly skips writing a note's file when it would come out as it did the last time.
+	changedOnly bool
+}
+
+// dirStats counts what exportDir did.
+type dirStats struct {
+	written, unchanged, removed int
+}
+
+// exportDir writes notes into dir as the files of a zip export, attachments under assets/
+// included, and records each note's file and a hash of its content as the destination's. A note
+// keeps its file across exports, even when renamed, unless the templates name files differently
+// now; the files of notes that have been trashed or deleted since are removed. Files already in
+// dir that noted didn't export there are left alone, apart from ones in the vault format that name
+// their note, which are adopted on the first export to dir.
+func exportDir(ctx context.Context, dir string, notes []db.Note, baseDir string, opts dirExport) (stats dirStats, err error) {
+	tmpls := opts.templates
+	rows, err := database.ListExportFiles(ctx, dir)
+	if err != nil {
+		return stats, fmt.Errorf("failed to get exported files: %w", err)
+	}
+	files := make(map[int64]string, len(rows)) // note id -> file, relative to dir
+	owners := make(map[string]int64, len(rows))
+	recorded := make(map[int64]db.ListExportFilesRow, len(rows))
+	for _, r := range rows {
+		files[r.NoteID], owners[r.Path], recorded[r.NoteID] = r.Path, r.NoteID, r
+	}
+	hashes := make(map[int64]string, len(notes)) // note id -> hash of the file written now
+	out, err := vault.Open(dir)
+	if err != nil {
+		return stats, err
+	}
+	if len(rows) == 0 {
+		existing, err := out.List()
+		if err != nil {
+			return stats, err
+		}
+		for _, n := range existing {
+			if n.ID > 0 {
+				name := filepath.Base(n.Path)
+				files[n.ID], owners[name] = name, n.ID
+			}
+		}
+	}
+
+	assets, link := opts.assets, opts.link
+	if assets == nil {
+		assets = dirAssets(dir)
+	}
+	if link == nil {
+		link = relativeLink
+	}
+	exported := make(map[int64]bool, len(notes))
+	for _, note := range notes {
+		exported[note.ID] = true
+		vn := notesync.VaultNote(ctx, database, note)
+		ln := newLayoutNote(note, vn)
+		if opts.prepare != nil {
+			opts.prepare(&ln)
+		}
+		name := files[note.ID]
+		if name == "" || tmpls.filename != nil {
+			if name, err = tmpls.fileName(ln); err != nil {
+				return stats, err
+			}
+			name = uniqueFileName(name, func(n string) bool {
+				if owner, ok := owners[n]; ok {
+					return owner != note.ID
+				}
+				_, err := os.Stat(filepath.Join(dir, filepath.FromSlash(n)))
+				return err == nil
+			})
+		}
+
+		content, noFile, err := withAttachmentsLinked(ln.Content, baseDir, assets, func(stored string) string {
+			return link(name, stored)
+		})
+		if err != nil {
+			return stats, err
+		}
+		for _, m := range noFile {
+			fmt.Fprintf(os.Stderr, "warning: attachment not found, link kept as is (#%d: %s)\n", note.ID, m)
+		}
+		vn.Content, ln.Content = content, content
+		text, err := tmpls.fileContent(ln, func() string { return vault.Serialize(vn) })
+		if err != nil {
+			return stats, err
+		}
+
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		sum := sha256.Sum256([]byte(text))
+		hashes[note.ID] = hex.EncodeToString(sum[:])
+		if last, ok := recorded[note.ID]; opts.changedOnly && ok && last.Path == name && last.Hash == hashes[note.ID] {
+			if _, err := os.Stat(path); err == nil {
+				stats.unchanged++
+				continue
+			}
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return stats, err
+		}
+		if err := os.WriteFile(path, []byte(text), 0o644); err != nil {
+			return stats, fmt.Errorf("failed to write #%d: %w", note.ID, err)
+		}
+		stats.written++
+		if old := files[note.ID]; old != "" && old != name {
+			if err := os.Remove(filepath.Join(dir, filepath.FromSlash(old))); err != nil && !os.IsNotExist(err) {
+				return stats, err
+			}
+			delete(owners, old)
+		}
+		files[note.ID], owners[name] = name, note.ID
+	}
+
+	for id, name := range files {
+		n, err := database.GetNote(ctx, id)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return stats, err
+		}
+		if err == nil && !n.DeletedAt.Valid && (exported[id] || !opts.exclusive) {
+			hash, ok := hashes[id]
+			if !ok {
+				hash = recorded[id].Hash // not exported this time: its file is as it was
+			}
+			if last := recorded[id]; last.Path != name || last.Hash != hash {
+				if err := database.SetExportFile(ctx, db.SetExportFileParams{Destination: dir, NoteID: id, Path: name, Hash: hash}); err != nil {
+					return stats, fmt.Errorf("failed to record exported file: %w", err)
+				}
+			}
+			continue
+		}
+		if err := out.Delete(filepath.FromSlash(name)); err != nil && !os.IsNotExist(err) {
+			return stats, err
+		}
+		if err := database.DeleteExportFile(ctx, db.DeleteExportFileParams{Destination: dir, NoteID: id}); err != nil {
+			return stats, fmt.Errorf("failed to forget exported file: %w", err)
+		}
+		stats.removed++
+	}
+	return stats, nil
+}
+
 func init() {
 	rootCmd.AddCommand(exportCmd)
 
-	exportCmd.Flags().StringP("format", "f", "markdown", "Output format (markdown, json, jsonl)")
-	exportCmd.Flags().StringP("output", "o", "", "Output path (default: stdout)")
+	exportCmd.Flags().StringP("format", "f", "markdown", "Output format (markdown, json, jsonl, zip, dir)")
+	exportCmd.Flags().StringP("output", "o", "", "Output path (default: stdout); a directory for --format dir")
 	exportCmd.Flags().StringP("tag", "T", "", "Filter by tag")
-	exportCmd.Flags().String("since", "", "Export notes created since date (YYYY-MM-DD)")
+	exportCmd.Flags().String("since", "", "Export notes updated since a date (YYYY-MM-DD) or duration ago (e.g. 7d)")
+	exportCmd.Flags().String("until", "", "Export notes updated before the end of a date or a duration ago")
+	exportCmd.Flags().Int64("folder", 0, "Export only notes in this folder ID")
+	exportCmd.Flags().Bool("pinned", false, "Export only pinned notes")
+	exportCmd.Flags().Bool("archived", false, "Export only archived notes")
+	exportCmd.Flags().StringP("query", "q", "", "Export only notes matching a search query (same syntax as grep)")
+	exportCmd.Flags().Bool("backlinks", false, "End each note with a Backlinks section (markdown and zip)")
+	exportCmd.Flags().Bool("changed-only", false, "Only rewrite files that changed since the last export to the same directory")
+	exportCmd.Flags().String("layout", "", "Go template file laying out each note (markdown, zip, dir)")
+	exportCmd.Flags().String("filename", "", "Go template naming each note's file, e.g. '{{.CreatedAt.Format \"2006/01\"}}/{{.Slug}}.md' (zip, dir)")
 }
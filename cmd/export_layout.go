@@ -0,0 +1,141 @@
+/*
+Copyright © 2026 abdul hamid <abdulachik@icloud.com>
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/abdul-hamid-achik/noted/internal/db"
+	"github.com/abdul-hamid-achik/noted/internal/vault"
+)
+
+// layoutNote is what --layout and --filename templates are executed with, one note at a time.
+type layoutNote struct {
+	ID        int64
+	Title     string
+	Slug      string
+	Content   string
+	Tags      []string
+	Aliases   []string
+	Folder    string
+	Pinned    bool
+	Draft     bool // tagged "draft"
+	Source    string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func newLayoutNote(note db.Note, vn vault.Note) layoutNote {
+	return layoutNote{
+		ID:        note.ID,
+		Title:     vn.Title,
+		Slug:      vault.Slugify(vn.Title),
+		Content:   vn.Content,
+		Tags:      vn.Tags,
+		Aliases:   vn.Aliases,
+		Folder:    vn.Folder,
+		Pinned:    vn.Pinned,
+		Draft:     slices.ContainsFunc(vn.Tags, func(t string) bool { return strings.EqualFold(t, "draft") }),
+		Source:    note.Source.String,
+		CreatedAt: vn.Created,
+		UpdatedAt: vn.Updated,
+	}
+}
+
+// layoutFuncs are the functions export templates can call besides text/template's own.
+var layoutFuncs = template.FuncMap{
+	"join":  strings.Join,
+	"lower": strings.ToLower,
+	"upper": strings.ToUpper,
+	"quote": strconv.Quote,
+	"slug":  vault.Slugify,
+}
+
+// exportTemplates are an export's --layout and --filename templates. Either may be nil, for the
+// format's own layout and <slug>.md file names.
+type exportTemplates struct {
+	layout   *template.Template
+	filename *template.Template
+}
+
+// loadExportTemplates parses the --layout template file and the --filename pattern; empty ones
+// are left nil.
+func loadExportTemplates(layoutPath, filename string) (exportTemplates, error) {
+	var t exportTemplates
+	if layoutPath != "" {
+		data, err := os.ReadFile(layoutPath)
+		if err != nil {
+			return t, fmt.Errorf("failed to read layout: %w", err)
+		}
+		if t.layout, err = template.New(filepath.Base(layoutPath)).Funcs(layoutFuncs).Parse(string(data)); err != nil {
+			return t, fmt.Errorf("invalid layout: %w", err)
+		}
+	}
+	if filename != "" {
+		var err error
+		if t.filename, err = template.New("filename").Funcs(layoutFuncs).Parse(filename); err != nil {
+			return t, fmt.Errorf("invalid --filename: %w", err)
+		}
+	}
+	return t, nil
+}
+
+// fileName returns the path a note is exported to, slash-separated and relative to the bundle or
+// directory root.
+func (t exportTemplates) fileName(n layoutNote) (string, error) {
+	if t.filename == nil {
+		return n.Slug + ".md", nil
+	}
+	var b strings.Builder
+	if err := t.filename.Execute(&b, n); err != nil {
+		return "", fmt.Errorf("failed to name the file for #%d: %w", n.ID, err)
+	}
+	name := path.Clean(strings.TrimSpace(b.String()))
+	if name == "." || !filepath.IsLocal(filepath.FromSlash(name)) {
+		return "", fmt.Errorf("file name %q for #%d is outside the export", b.String(), n.ID)
+	}
+	return name, nil
+}
+
+// fileContent returns a note's exported text: the --layout template's output, or fallback when
+// there is no layout.
+func (t exportTemplates) fileContent(n layoutNote, fallback func() string) (string, error) {
+	if t.layout == nil {
+		return fallback(), nil
+	}
+	var b strings.Builder
+	if err := t.layout.Execute(&b, n); err != nil {
+		return "", fmt.Errorf("failed to lay out #%d: %w", n.ID, err)
+	}
+	return b.String(), nil
+}
+
+// uniqueFileName returns name, or name with -2, -3, ... before its extension, whichever taken
+// first reports as free.
+func uniqueFileName(name string, taken func(string) bool) string {
+	ext := path.Ext(name)
+	unique := name
+	for i := 2; taken(unique); i++ {
+		unique = fmt.Sprintf("%s-%d%s", strings.TrimSuffix(name, ext), i, ext)
+	}
+	return unique
+}
+
+// relativeLink rewrites target, a path relative to the export root, to be relative to the
+// directory of the file at from, so links keep working from files in subdirectories.
+func relativeLink(from, target string) string {
+	dir := path.Dir(from)
+	if dir == "." {
+		return target
+	}
+	return strings.Repeat("../", strings.Count(dir, "/")+1) + target
+}
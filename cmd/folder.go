@@ -8,6 +8,7 @@ import (
 	"database/sql"
 	"fmt"
 	"strconv"
+	"strings"
 
 	"github.com/abdul-hamid-achik/noted/internal/db"
 	"github.com/spf13/cobra"
@@ -19,6 +20,60 @@ type folderItem struct {
 	ParentID *int64 `json:"parent_id,omitempty"`
 }
 
+// folderNode is a folder in "noted folder tree", with its note counts and subfolders.
+type folderNode struct {
+	ID         int64         `json:"id"`
+	Name       string        `json:"name"`
+	NoteCount  int64         `json:"note_count"`  // notes directly in the folder
+	TotalCount int64         `json:"total_count"` // notes in the folder and every folder below it
+	Children   []*folderNode `json:"children"`
+}
+
+// buildFolderTree nests folders under their parents, keeping the rows' order among siblings.
+// Folders whose parent is missing, or that would end up inside themselves, become roots.
+func buildFolderTree(rows []db.FolderTreeRow) []*folderNode {
+	nodes := make(map[int64]*folderNode, len(rows))
+	parents := make(map[int64]int64, len(rows))
+	for _, r := range rows {
+		nodes[r.ID] = &folderNode{ID: r.ID, Name: r.Name, NoteCount: r.NoteCount, TotalCount: r.TotalCount, Children: []*folderNode{}}
+		if r.ParentID.Valid {
+			parents[r.ID] = r.ParentID.Int64
+		}
+	}
+	insideItself := func(id int64) bool {
+		seen := map[int64]bool{}
+		for p, ok := parents[id]; ok && nodes[p] != nil; p, ok = parents[p] {
+			if p == id || seen[p] {
+				return true
+			}
+			seen[p] = true
+		}
+		return false
+	}
+
+	roots := []*folderNode{}
+	for _, r := range rows {
+		parent, ok := nodes[parents[r.ID]]
+		if !r.ParentID.Valid || !ok || insideItself(r.ID) {
+			roots = append(roots, nodes[r.ID])
+			continue
+		}
+		parent.Children = append(parent.Children, nodes[r.ID])
+	}
+	return roots
+}
+
+func printFolderTree(nodes []*folderNode, depth int) {
+	for _, n := range nodes {
+		counts := fmt.Sprintf("%d notes", n.TotalCount)
+		if len(n.Children) > 0 && n.NoteCount != n.TotalCount {
+			counts += fmt.Sprintf(" (%d here)", n.NoteCount)
+		}
+		fmt.Printf("%s%s (#%d)  %s\n", strings.Repeat("  ", depth), n.Name, n.ID, counts)
+		printFolderTree(n.Children, depth+1)
+	}
+}
+
 var folderCmd = &cobra.Command{
 	Use:   "folder",
 	Short: "Manage folders",
@@ -66,6 +121,36 @@ var folderListCmd = &cobra.Command{
 	},
 }
 
+var folderTreeCmd = &cobra.Command{
+	Use:   "tree",
+	Short: "Show folders as a tree with note counts",
+	Long: `Show the folder hierarchy, each folder with the number of notes in it and in
+every folder below it. Trashed and archived notes are not counted.
+
+Examples:
+  noted folder tree
+  noted folder tree --json`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		asJSON, _ := cmd.Flags().GetBool("json")
+
+		rows, err := database.FolderTree(context.Background())
+		if err != nil {
+			return err
+		}
+		tree := buildFolderTree(rows)
+
+		if asJSON {
+			return outputJSON(tree)
+		}
+		if len(tree) == 0 {
+			fmt.Println("No folders found.")
+			return nil
+		}
+		printFolderTree(tree, 0)
+		return nil
+	},
+}
+
 var folderCreateCmd = &cobra.Command{
 	Use:   "create <name>",
 	Short: "Create a new folder",
@@ -155,10 +240,12 @@ var folderDeleteCmd = &cobra.Command{
 func init() {
 	rootCmd.AddCommand(folderCmd)
 	folderCmd.AddCommand(folderListCmd)
+	folderCmd.AddCommand(folderTreeCmd)
 	folderCmd.AddCommand(folderCreateCmd)
 	folderCmd.AddCommand(folderDeleteCmd)
 
 	folderListCmd.Flags().BoolP("json", "j", false, "Output as JSON")
+	folderTreeCmd.Flags().BoolP("json", "j", false, "Output as JSON")
 	folderCreateCmd.Flags().BoolP("json", "j", false, "Output as JSON")
 	folderCreateCmd.Flags().Int64("parent", 0, "Parent folder ID")
 	folderDeleteCmd.Flags().BoolP("json", "j", false, "Output as JSON")
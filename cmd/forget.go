@@ -33,7 +33,8 @@ type forgetResultOutput struct {
 var forgetCmd = &cobra.Command{
 	Use:   "forget",
 	Short: "Delete old or low-importance memories",
-	Long: `Delete memories based on criteria like age, importance, or category.
+	Long: `Delete memories based on criteria like age, importance, category, source, or tags.
+Deleted memories go to the trash, so "noted trash restore" can bring them back.
 
 By default, runs in dry-run mode to show what would be deleted.
 Use --force to actually delete the memories.
@@ -44,6 +45,8 @@ Examples:
   noted forget --importance-below 2          # Delete low-importance memories
   noted forget --category todo --older-than 7d
   noted forget --query "temporary"           # Delete memories matching query
+  noted forget --source code-review          # Delete memories captured by a tool
+  noted forget --tag-prefix temp:            # Delete memories tagged temp:*
   noted forget --id 42 --force               # Delete specific memory by ID`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		olderThan, _ := cmd.Flags().GetString("older-than")
@@ -51,6 +54,8 @@ Examples:
 		category, _ := cmd.Flags().GetString("category")
 		query, _ := cmd.Flags().GetString("query")
 		id, _ := cmd.Flags().GetInt64("id")
+		source, _ := cmd.Flags().GetString("source")
+		tagPrefix, _ := cmd.Flags().GetString("tag-prefix")
 		force, _ := cmd.Flags().GetBool("force")
 		asJSON, _ := cmd.Flags().GetBool("json")
 
@@ -68,15 +73,16 @@ Examples:
 		}
 
 		// Check if any criteria specified
-		if olderThanDays == 0 && importanceBelow == 0 && category == "" && query == "" && id == 0 {
-			return fmt.Errorf("at least one filter criteria is required (--older-than, --importance-below, --category, --query, or --id)")
+		if olderThanDays == 0 && importanceBelow == 0 && category == "" && query == "" && id == 0 &&
+			source == "" && tagPrefix == "" {
+			return fmt.Errorf("at least one filter criteria is required (--older-than, --importance-below, --category, --query, --source, --tag-prefix, or --id)")
 		}
 
 		// Try to get veclite syncer
 		var syncer *veclite.Syncer
 		cfg, err := config.Load()
 		if err == nil && cfg.VeclitePath != "" {
-			syncer, _ = veclite.NewSyncer(cfg.VeclitePath, cfg.EmbeddingModel)
+			syncer, _ = openSyncer(cfg, false)
 			if syncer != nil {
 				defer func() { _ = syncer.Close() }()
 			}
@@ -84,15 +90,19 @@ Examples:
 
 		ctx := context.Background()
 
-		// First, do a dry run to see what would be deleted
-		result, err := memory.Forget(ctx, database, syncer, memory.ForgetInput{
+		criteria := memory.ForgetInput{
 			OlderThanDays:   olderThanDays,
 			ImportanceBelow: importanceBelow,
 			Category:        category,
 			Query:           query,
+			Source:          source,
+			TagPrefix:       tagPrefix,
 			ID:              id,
 			DryRun:          true, // Always dry run first
-		})
+		}
+
+		// First, do a dry run to see what would be deleted
+		result, err := memory.Forget(ctx, database, syncer, criteria)
 		if err != nil {
 			return err
 		}
@@ -163,14 +173,8 @@ Examples:
 		}
 
 		// Actually delete
-		deleteResult, err := memory.Forget(ctx, database, syncer, memory.ForgetInput{
-			OlderThanDays:   olderThanDays,
-			ImportanceBelow: importanceBelow,
-			Category:        category,
-			Query:           query,
-			ID:              id,
-			DryRun:          false,
-		})
+		criteria.DryRun = false
+		deleteResult, err := memory.Forget(ctx, database, syncer, criteria)
 		if err != nil {
 			return err
 		}
@@ -192,7 +196,7 @@ Examples:
 			return outputJSON(output)
 		}
 
-		fmt.Printf("Deleted %d memories.\n", deleteResult.Deleted)
+		fmt.Printf("Moved %d memories to the trash.\n", deleteResult.Deleted)
 		return nil
 	},
 }
@@ -205,6 +209,8 @@ func init() {
 	forgetCmd.Flags().StringP("category", "c", "", "Only delete memories in this category")
 	forgetCmd.Flags().StringP("query", "q", "", "Delete memories matching this query")
 	forgetCmd.Flags().Int64("id", 0, "Delete specific memory by ID")
+	forgetCmd.Flags().String("source", "", "Only delete memories from this source (e.g., 'code-review')")
+	forgetCmd.Flags().String("tag-prefix", "", "Only delete memories with a tag starting with this prefix (e.g., 'temp:')")
 	forgetCmd.Flags().BoolP("force", "f", false, "Actually delete (without this flag, only shows what would be deleted)")
 	forgetCmd.Flags().BoolP("json", "j", false, "Output as JSON")
 }
@@ -0,0 +1,247 @@
+/*
+Copyright © 2026 abdul hamid <abdulachik@icloud.com>
+*/
+package cmd
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/abdul-hamid-achik/noted/internal/config"
+	"github.com/abdul-hamid-achik/noted/internal/db"
+	"github.com/abdul-hamid-achik/noted/internal/notesync"
+	"github.com/abdul-hamid-achik/noted/internal/quota"
+	"github.com/abdul-hamid-achik/noted/internal/veclite"
+	"github.com/spf13/cobra"
+)
+
+type gcResult struct {
+	DryRun          bool   `json:"dry_run"`
+	TrashPurged     int    `json:"trash_purged"`
+	NoteTags        int64  `json:"dangling_note_tags"`
+	NoteLinks       int64  `json:"dangling_note_links"`
+	StaleVectors    int    `json:"stale_vector_notes"`
+	Attachments     int    `json:"orphaned_attachments"`
+	AttachmentBytes int64  `json:"orphaned_attachment_bytes"`
+	Vacuum          string `json:"vacuum,omitempty"` // "full" or "incremental"
+	FreedPages      int64  `json:"freed_pages,omitempty"`
+	DBSizeBefore    int64  `json:"db_size_before_bytes"`
+	DBSizeAfter     int64  `json:"db_size_after_bytes"`
+}
+
+var gcCmd = &cobra.Command{
+	Use:   "gc",
+	Short: "Clean up unused data and compact the database",
+	Long: `Run all housekeeping in one go:
+
+  - purge notes that have been in the trash longer than NOTED_TRASH_DAYS
+  - delete note_tags and note_links rows that point at missing notes or tags
+  - recount the cached note and tag counts "noted stats" and "noted tags" show
+  - re-index @mentions, broken wikilinks, and note languages, including notes
+    saved before they were tracked
+  - drop semantic-search vectors of notes that no longer exist
+  - delete files in the vault's assets/ directory that no note links to
+    (links from trashed notes count, so a restored note keeps its attachments)
+  - VACUUM the database
+
+A full VACUUM rewrites the whole file and blocks writers while it runs, which
+can take minutes on a large database. --incremental instead returns free pages
+to the filesystem in place, which is quick. New databases support it from the
+start; an older one is switched over by its next full "noted gc".
+
+It is safe to run from cron. Use --dry-run to see what would be removed.
+
+Examples:
+  noted gc
+  noted gc --incremental
+  noted gc --dry-run
+  noted gc --json`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		asJSON, _ := cmd.Flags().GetBool("json")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		incremental, _ := cmd.Flags().GetBool("incremental")
+
+		cfg, err := config.Load()
+		if err != nil {
+			return err
+		}
+		res, err := runGC(context.Background(), cmd, cfg, dryRun, incremental)
+		if err != nil {
+			return err
+		}
+
+		if asJSON {
+			return outputJSON(res)
+		}
+		verb := "Removed"
+		if dryRun {
+			verb = "Would remove"
+		}
+		fmt.Printf("%s:\n", verb)
+		fmt.Printf("  %-22s %d\n", "Expired trash notes:", res.TrashPurged)
+		fmt.Printf("  %-22s %d\n", "Dangling tag rows:", res.NoteTags)
+		fmt.Printf("  %-22s %d\n", "Dangling link rows:", res.NoteLinks)
+		fmt.Printf("  %-22s %d\n", "Stale vector notes:", res.StaleVectors)
+		fmt.Printf("  %-22s %d (%s)\n", "Orphaned attachments:", res.Attachments, quota.FormatSize(res.AttachmentBytes))
+		if !dryRun {
+			fmt.Printf("Database (%s vacuum): %s -> %s\n", res.Vacuum, quota.FormatSize(res.DBSizeBefore), quota.FormatSize(res.DBSizeAfter))
+		}
+		return nil
+	},
+}
+
+// runGC performs (or, with dryRun, only counts) every gc step. Expired trash goes first, so
+// attachments and rows only the purged notes used are collected in the same run. The database is
+// vacuumed fully, or with incremental, only its free pages are released.
+func runGC(ctx context.Context, cmd *cobra.Command, cfg *config.Config, dryRun, incremental bool) (gcResult, error) {
+	res := gcResult{DryRun: dryRun}
+	var err error
+
+	if cfg.TrashDays > 0 {
+		cutoff := time.Now().AddDate(0, 0, -cfg.TrashDays)
+		if dryRun {
+			old, err := database.GetTrashedNotesBefore(ctx, sql.NullTime{Time: cutoff.UTC(), Valid: true})
+			if err != nil {
+				return res, fmt.Errorf("failed to list trash: %w", err)
+			}
+			res.TrashPurged = len(old)
+		} else {
+			purged, err := notesync.PurgeTrash(ctx, database, openVault(cmd), cutoff)
+			if err != nil {
+				return res, err
+			}
+			res.TrashPurged = len(purged)
+		}
+	}
+
+	if dryRun {
+		if res.NoteTags, err = database.CountDanglingNoteTags(ctx); err != nil {
+			return res, fmt.Errorf("failed to count dangling tags: %w", err)
+		}
+		if res.NoteLinks, err = database.CountDanglingNoteLinks(ctx); err != nil {
+			return res, fmt.Errorf("failed to count dangling links: %w", err)
+		}
+	} else {
+		if res.NoteTags, err = database.DeleteDanglingNoteTags(ctx); err != nil {
+			return res, fmt.Errorf("failed to delete dangling tags: %w", err)
+		}
+		if res.NoteLinks, err = database.DeleteDanglingNoteLinks(ctx); err != nil {
+			return res, fmt.Errorf("failed to delete dangling links: %w", err)
+		}
+		// Triggers keep the counters current; recounting repairs drift from writes made around them
+		if err := database.RecountCounters(ctx); err != nil {
+			return res, fmt.Errorf("failed to recount notes: %w", err)
+		}
+		if err := database.RecountTagNotes(ctx); err != nil {
+			return res, fmt.Errorf("failed to recount tags: %w", err)
+		}
+		if err := notesync.Reindex(ctx, database); err != nil {
+			return res, err
+		}
+	}
+
+	if cfg.VeclitePath != "" {
+		stale, err := staleVectorNotes(ctx, cfg.VeclitePath)
+		if err != nil {
+			return res, err
+		}
+		res.StaleVectors = len(stale)
+		if !dryRun && len(stale) > 0 {
+			if err := veclite.DeleteNotes(cfg.VeclitePath, stale...); err != nil {
+				return res, err
+			}
+		}
+	}
+
+	if dir := vaultDir(cmd); dir != "" {
+		files, err := assetUsage(ctx, dir)
+		if err != nil {
+			return res, fmt.Errorf("failed to scan attachments: %w", err)
+		}
+		for _, f := range files {
+			if f.Refs > 0 {
+				continue
+			}
+			if !dryRun {
+				if err := os.Remove(filepath.Join(dir, assetsDir, f.Name)); err != nil {
+					return res, fmt.Errorf("failed to remove attachment %s: %w", f.Name, err)
+				}
+			}
+			res.Attachments++
+			res.AttachmentBytes += f.Size
+		}
+	}
+
+	if dryRun {
+		return res, nil
+	}
+	if res.DBSizeBefore, err = databaseSize(ctx); err != nil {
+		return res, err
+	}
+	if incremental {
+		res.Vacuum = "incremental"
+		if res.FreedPages, err = db.IncrementalVacuum(ctx, conn); err != nil {
+			if errors.Is(err, db.ErrNotIncremental) {
+				return res, fmt.Errorf("%w; run `noted gc` once without --incremental to convert it", err)
+			}
+			return res, err
+		}
+	} else {
+		res.Vacuum = "full"
+		if err := db.Vacuum(ctx, conn); err != nil {
+			return res, err
+		}
+	}
+	res.DBSizeAfter, err = databaseSize(ctx)
+	return res, err
+}
+
+// staleVectorNotes returns the notes the vector index at path holds vectors for that are no longer
+// live: deleted, or sitting in the trash.
+func staleVectorNotes(ctx context.Context, path string) ([]int64, error) {
+	ids, err := veclite.NoteIDs(path)
+	if err != nil || len(ids) == 0 {
+		return nil, err
+	}
+	notes, err := database.GetAllNotes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	live := make(map[int64]bool, len(notes))
+	for _, n := range notes {
+		live[n.ID] = true
+	}
+	var stale []int64
+	for _, id := range ids {
+		if !live[id] {
+			stale = append(stale, id)
+		}
+	}
+	return stale, nil
+}
+
+// databaseSize returns the size of the open database in bytes.
+func databaseSize(ctx context.Context) (int64, error) {
+	var pages, size int64
+	if err := conn.QueryRowContext(ctx, "PRAGMA page_count").Scan(&pages); err != nil {
+		return 0, err
+	}
+	if err := conn.QueryRowContext(ctx, "PRAGMA page_size").Scan(&size); err != nil {
+		return 0, err
+	}
+	return pages * size, nil
+}
+
+func init() {
+	rootCmd.AddCommand(gcCmd)
+
+	gcCmd.Flags().BoolP("json", "j", false, "Output as JSON")
+	gcCmd.Flags().BoolP("dry-run", "n", false, "Report what would be removed without changing anything")
+	gcCmd.Flags().Bool("incremental", false, "Release free pages in place instead of a full VACUUM")
+}
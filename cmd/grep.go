@@ -6,25 +6,73 @@ package cmd
 import (
 	"context"
 	"fmt"
+	"os"
+	"strings"
 
+	"github.com/abdul-hamid-achik/noted/internal/config"
 	"github.com/abdul-hamid-achik/noted/internal/db"
 	"github.com/spf13/cobra"
 )
 
 type grepResultItem struct {
-	ID        int64  `json:"id"`
-	Title     string `json:"title"`
-	UpdatedAt string `json:"updated_at"`
+	ID          int64         `json:"id"`
+	Title       string        `json:"title"`
+	UpdatedAt   string        `json:"updated_at"`
+	Score       float64       `json:"score"`
+	MatchedTags []string      `json:"matched_tags,omitempty"`
+	Archived    bool          `json:"archived,omitempty"`
+	Lang        string        `json:"lang,omitempty"`
+	Snippets    []grepSnippet `json:"snippets,omitempty"`
+}
+
+// grepSnippet is an excerpt around a search's matches, with the matches as byte offsets into
+// text and <mark>-wrapped in html.
+type grepSnippet struct {
+	db.Snippet
+	HTML string `json:"html"`
+}
+
+type searchHistoryItem struct {
+	Query          string `json:"query"`
+	Interface      string `json:"interface"`
+	Uses           int64  `json:"uses"`
+	LastSearchedAt string `json:"last_searched_at"`
 }
 
 var grepCmd = &cobra.Command{
-	Use:   "grep <pattern>",
-	Short: "Search notes by text",
-	Args:  cobra.ExactArgs(1),
+	Use:     "grep <pattern>",
+	Aliases: []string{"search"},
+	Short:   "Search notes by text",
+	Long: `Search note titles, content, and tag names. Results are ranked with title
+matches first, then tag matches, then body matches, so "noted grep golang" also
+finds notes that are only tagged golang. Archived notes are only searched with
+--archived. A lang:<code> term (lang:es, lang:en) keeps only notes detected as
+written in that language.
+
+Each result shows up to three one-line excerpts around its matches, with the
+matched terms highlighted on a terminal. With --json, each snippet gives the
+match offsets (in bytes) and an HTML version with the matches in <mark>.
+
+Searches are remembered per interface (CLI, MCP); --history lists recent ones.
+Set NOTED_SEARCH_HISTORY=off to stop recording them.
+
+Examples:
+  noted grep golang
+  noted grep "error handling" -n 5
+  noted grep sqlite --json
+  noted grep roadmap --archived
+  noted grep "presupuesto lang:es"
+  noted search --history
+  noted search --history --interface mcp
+  noted search --clear-history`,
+	Args: cobra.MaximumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		pattern := args[0]
 		limit, _ := cmd.Flags().GetInt("limit")
 		asJSON, _ := cmd.Flags().GetBool("json")
+		history, _ := cmd.Flags().GetBool("history")
+		iface, _ := cmd.Flags().GetString("interface")
+		clearHistory, _ := cmd.Flags().GetBool("clear-history")
+		archived, _ := cmd.Flags().GetBool("archived")
 
 		if limit < 1 {
 			return fmt.Errorf("limit must be at least 1")
@@ -32,52 +80,133 @@ var grepCmd = &cobra.Command{
 
 		ctx := context.Background()
 
-		// Try FTS5 first, fall back to LIKE
-		var notes []db.Note
-		var err error
-		if db.FTSAvailable(ctx, conn) {
-			notes, err = db.SearchNotesFTS(ctx, conn, pattern, int64(limit))
+		if clearHistory {
+			n, err := database.ClearSearchHistory(ctx)
+			if err != nil {
+				return fmt.Errorf("failed to clear search history: %w", err)
+			}
+			if asJSON {
+				return outputJSON(map[string]any{"cleared": n})
+			}
+			fmt.Printf("Cleared %d search(es) from history.\n", n)
+			return nil
+		}
+		if history {
+			return showSearchHistory(ctx, iface, limit, asJSON)
 		}
-		if notes == nil || err != nil {
-			searchPattern := "%" + pattern + "%"
-			notes, err = database.SearchNotesContent(ctx, db.SearchNotesContentParams{
-				Content: searchPattern,
-				Title:   searchPattern,
-				Limit:   int64(limit),
-			})
+		if len(args) == 0 {
+			return fmt.Errorf("a search pattern is required (or use --history)")
 		}
+		pattern := args[0]
+
+		search := db.SearchNotes
+		if archived {
+			search = db.SearchNotesArchived
+		}
+		results, err := search(ctx, conn, pattern, int64(limit))
 		if err != nil {
 			return err
 		}
 
+		if cfg, err := config.Load(); err == nil && cfg.SearchHistory {
+			_ = db.LogSearch(ctx, database, pattern, "cli", len(results)) // best-effort
+		}
+
 		if asJSON {
-			items := make([]grepResultItem, len(notes))
-			for i, note := range notes {
+			items := make([]grepResultItem, len(results))
+			for i, r := range results {
 				items[i] = grepResultItem{
-					ID:        note.ID,
-					Title:     note.Title,
-					UpdatedAt: note.UpdatedAt.Time.Format("2006-01-02T15:04:05Z07:00"),
+					ID:          r.Note.ID,
+					Title:       r.Note.Title,
+					UpdatedAt:   r.Note.UpdatedAt.Time.Format("2006-01-02T15:04:05Z07:00"),
+					Score:       r.Score,
+					MatchedTags: r.MatchedTags,
+					Archived:    r.Note.ArchivedAt.Valid,
+					Lang:        r.Note.Lang.String,
+				}
+				for _, sn := range r.Snippets {
+					items[i].Snippets = append(items[i].Snippets, grepSnippet{Snippet: sn, HTML: sn.HTML()})
 				}
 			}
 			return outputJSON(items)
 		}
 
-		if len(notes) == 0 {
+		if len(results) == 0 {
 			fmt.Println("No matching notes found.")
 			return nil
 		}
 
-		for _, note := range notes {
-			fmt.Printf("#%-4d %-40s %s\n", note.ID, note.Title, note.UpdatedAt.Time.Format("2006-01-02"))
+		on, off := matchHighlight()
+		for _, r := range results {
+			fmt.Printf("#%-4d %-40s %s", r.Note.ID, r.Note.Title, r.Note.UpdatedAt.Time.Format("2006-01-02"))
+			if len(r.MatchedTags) > 0 {
+				fmt.Printf("  [tags: %s]", strings.Join(r.MatchedTags, ", "))
+			}
+			if r.Note.ArchivedAt.Valid {
+				fmt.Print("  [archived]")
+			}
+			fmt.Println()
+			for _, sn := range r.Snippets {
+				fmt.Printf("      %s\n", sn.Highlight(on, off))
+			}
 		}
 
 		return nil
 	},
 }
 
+// matchHighlight returns the escape codes that bold search matches on a terminal, or nothing
+// when output is piped or NO_COLOR is set.
+func matchHighlight() (on, off string) {
+	if os.Getenv("NO_COLOR") != "" {
+		return "", ""
+	}
+	if fi, err := os.Stdout.Stat(); err != nil || fi.Mode()&os.ModeCharDevice == 0 {
+		return "", ""
+	}
+	return "\033[1;33m", "\033[0m"
+}
+
+func showSearchHistory(ctx context.Context, iface string, limit int, asJSON bool) error {
+	rows, err := database.ListSearchHistory(ctx, db.ListSearchHistoryParams{
+		InterfaceFilter: iface,
+		Limit:           int64(limit),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to list search history: %w", err)
+	}
+
+	if asJSON {
+		items := make([]searchHistoryItem, len(rows))
+		for i, r := range rows {
+			items[i] = searchHistoryItem{
+				Query:          r.Query,
+				Interface:      r.Interface,
+				Uses:           r.Uses,
+				LastSearchedAt: r.LastSearchedAt,
+			}
+		}
+		return outputJSON(items)
+	}
+
+	if len(rows) == 0 {
+		fmt.Println("No search history.")
+		return nil
+	}
+
+	for _, r := range rows {
+		fmt.Printf("%-40s %-4s %3dx  %s\n", r.Query, r.Interface, r.Uses, r.LastSearchedAt)
+	}
+	return nil
+}
+
 func init() {
 	rootCmd.AddCommand(grepCmd)
 
 	grepCmd.Flags().IntP("limit", "n", 20, "Max results")
 	grepCmd.Flags().BoolP("json", "j", false, "Output as JSON")
+	grepCmd.Flags().Bool("archived", false, "Include archived notes")
+	grepCmd.Flags().Bool("history", false, "List recent searches instead of searching")
+	grepCmd.Flags().String("interface", "", "With --history, only show searches from this interface (cli, mcp)")
+	grepCmd.Flags().Bool("clear-history", false, "Delete all recorded searches")
 }
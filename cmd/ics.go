@@ -0,0 +1,310 @@
+/*
+Copyright © 2026 abdul hamid <abdulachik@icloud.com>
+*/
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/abdul-hamid-achik/noted/internal/db"
+	"github.com/abdul-hamid-achik/noted/internal/markdown"
+	"github.com/spf13/cobra"
+)
+
+// icsUIDRe matches the UID noted gives a task's VTODO: <note_id>-<index>-<text hash>@noted.
+var icsUIDRe = regexp.MustCompile(`^(\d+)-(\d+)-([0-9a-f]{8})@noted$`)
+
+// icsMaxLine is the longest content line, in bytes, RFC 5545 allows before folding.
+const icsMaxLine = 75
+
+// icsTodo is one VTODO, as exported or read back.
+type icsTodo struct {
+	UID     string
+	Summary string
+	Due     string // YYYY-MM-DD
+	Done    bool
+	Ref     string // "Title (#id:index)", written as the description
+}
+
+// taskHash identifies a task's text apart from its due date, so a VTODO still finds its task after
+// the date changes or the task moves within the note.
+func taskHash(text string) string {
+	sum := sha256.Sum256([]byte(markdown.StripDue(text)))
+	return hex.EncodeToString(sum[:4])
+}
+
+// icsTodos returns a VTODO for every task with a due date, done or not, so that a calendar app that
+// imported them sees tasks completed in noted as completed.
+func icsTodos(notes []db.Note) []icsTodo {
+	var out []icsTodo
+	for _, note := range notes {
+		for _, t := range markdown.Tasks(note.Content) {
+			if t.Due == "" {
+				continue
+			}
+			out = append(out, icsTodo{
+				UID:     fmt.Sprintf("%d-%d-%s@noted", note.ID, t.Index, taskHash(t.Text)),
+				Summary: markdown.StripDue(t.Text),
+				Due:     t.Due,
+				Done:    t.Done,
+				Ref:     fmt.Sprintf("%s (#%d:%d)", note.Title, note.ID, t.Index),
+			})
+		}
+	}
+	return out
+}
+
+// writeICS writes todos as an iCalendar file.
+func writeICS(w io.Writer, todos []icsTodo, now time.Time) error {
+	bw := bufio.NewWriter(w)
+	line := func(s string) {
+		// Fold long lines: continuation lines start with a space
+		for len(s) > icsMaxLine {
+			cut := icsMaxLine
+			for cut > 1 && !isUTF8Start(s[cut]) {
+				cut--
+			}
+			_, _ = bw.WriteString(s[:cut] + "\r\n")
+			s = " " + s[cut:]
+		}
+		_, _ = bw.WriteString(s + "\r\n")
+	}
+
+	stamp := now.UTC().Format("20060102T150405Z")
+	line("BEGIN:VCALENDAR")
+	line("VERSION:2.0")
+	line("PRODID:-//noted//tasks//EN")
+	for _, t := range todos {
+		status := "NEEDS-ACTION"
+		if t.Done {
+			status = "COMPLETED"
+		}
+		line("BEGIN:VTODO")
+		line("UID:" + t.UID)
+		line("DTSTAMP:" + stamp)
+		line("SUMMARY:" + icsEscape(t.Summary))
+		line("DUE;VALUE=DATE:" + strings.ReplaceAll(t.Due, "-", ""))
+		line("STATUS:" + status)
+		line("DESCRIPTION:" + icsEscape(t.Ref))
+		line("END:VTODO")
+	}
+	line("END:VCALENDAR")
+	return bw.Flush()
+}
+
+func isUTF8Start(b byte) bool {
+	return b&0xC0 != 0x80
+}
+
+var icsEscaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\n", `\n`)
+
+func icsEscape(s string) string {
+	return icsEscaper.Replace(s)
+}
+
+// readICS returns the VTODOs in an iCalendar file. Only the properties noted uses are read; a todo
+// counts as done when its STATUS is COMPLETED or it has a COMPLETED date.
+func readICS(r io.Reader) ([]icsTodo, error) {
+	var lines []string
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for sc.Scan() {
+		l := strings.TrimRight(sc.Text(), "\r")
+		if (strings.HasPrefix(l, " ") || strings.HasPrefix(l, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += l[1:]
+			continue
+		}
+		lines = append(lines, l)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+
+	var todos []icsTodo
+	var cur *icsTodo
+	for _, l := range lines {
+		prop, value, ok := strings.Cut(l, ":")
+		if !ok {
+			continue
+		}
+		name, _, _ := strings.Cut(prop, ";")
+		switch strings.ToUpper(name) {
+		case "BEGIN":
+			if strings.EqualFold(value, "VTODO") {
+				cur = &icsTodo{}
+			}
+		case "END":
+			if strings.EqualFold(value, "VTODO") && cur != nil {
+				todos = append(todos, *cur)
+				cur = nil
+			}
+		case "UID":
+			if cur != nil {
+				cur.UID = value
+			}
+		case "SUMMARY":
+			if cur != nil {
+				cur.Summary = value
+			}
+		case "STATUS":
+			if cur != nil {
+				cur.Done = strings.EqualFold(value, "COMPLETED")
+			}
+		case "COMPLETED":
+			if cur != nil {
+				cur.Done = true
+			}
+		}
+	}
+	return todos, nil
+}
+
+type icsImportResult struct {
+	Updated []todoDoneResult `json:"updated"`
+	Skipped []string         `json:"skipped"` // UIDs of todos whose task is gone or was rewritten
+}
+
+// importICSTodos sets each noted task named by a VTODO's UID to the todo's done state. A task that
+// moved within its note is found by its text; todos from other apps, and ones whose task was
+// deleted or rewritten, are skipped.
+func importICSTodos(ctx context.Context, cmd *cobra.Command, todos []icsTodo) (icsImportResult, error) {
+	res := icsImportResult{Updated: []todoDoneResult{}, Skipped: []string{}}
+	vlt := openVault(cmd)
+	for _, todo := range todos {
+		m := icsUIDRe.FindStringSubmatch(todo.UID)
+		if m == nil {
+			continue
+		}
+		noteID, _ := strconv.ParseInt(m[1], 10, 64)
+		index, _ := strconv.Atoi(m[2])
+
+		note, err := database.GetNote(ctx, noteID)
+		if err != nil {
+			res.Skipped = append(res.Skipped, todo.UID)
+			continue
+		}
+		task, found := markdown.Task{}, false
+		for _, t := range markdown.Tasks(note.Content) {
+			if taskHash(t.Text) == m[3] && (!found || t.Index == index) {
+				task, found = t, true
+			}
+		}
+		if !found {
+			res.Skipped = append(res.Skipped, todo.UID)
+			continue
+		}
+		if task.Done == todo.Done {
+			continue
+		}
+		if _, err := setTaskDone(ctx, vlt, noteID, task.Index, todo.Done); err != nil {
+			return res, err
+		}
+		res.Updated = append(res.Updated, todoDoneResult{NoteID: noteID, Index: task.Index, Text: task.Text, Done: todo.Done})
+	}
+	return res, nil
+}
+
+var todoICSCmd = &cobra.Command{
+	Use:   "ics",
+	Short: "Export tasks with due dates as iCalendar to-dos",
+	Long: `Write every task with an @due(YYYY-MM-DD) date as a VTODO in an iCalendar
+file, for reminders and calendar apps. Completed tasks are included, marked
+completed.
+
+Bring back what was checked off in the app with "noted todo ics-import".
+
+Examples:
+  noted todo ics > tasks.ics
+  noted todo ics -o ~/Sync/tasks.ics`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		output, _ := cmd.Flags().GetString("output")
+
+		notes, err := database.GetAllNotes(context.Background())
+		if err != nil {
+			return fmt.Errorf("failed to get notes: %w", err)
+		}
+		todos := icsTodos(notes)
+
+		if output == "" {
+			return writeICS(os.Stdout, todos, time.Now())
+		}
+		f, err := os.Create(output)
+		if err != nil {
+			return err
+		}
+		if err := writeICS(f, todos, time.Now()); err != nil {
+			_ = f.Close()
+			return err
+		}
+		if err := f.Close(); err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "Wrote %d tasks to %s\n", len(todos), output)
+		return nil
+	},
+}
+
+var todoICSImportCmd = &cobra.Command{
+	Use:   "ics-import <file>",
+	Short: "Check off tasks completed in an iCalendar file",
+	Long: `Read an iCalendar file exported by "noted todo ics" and edited in another app,
+and check off (or uncheck) the matching tasks in noted. To-dos that did not
+come from noted are ignored.
+
+Examples:
+  noted todo ics-import ~/Sync/tasks.ics`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		asJSON, _ := cmd.Flags().GetBool("json")
+
+		f, err := os.Open(args[0])
+		if err != nil {
+			return err
+		}
+		todos, err := readICS(f)
+		_ = f.Close()
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", args[0], err)
+		}
+
+		res, err := importICSTodos(context.Background(), cmd, todos)
+		if err != nil {
+			return err
+		}
+		if asJSON {
+			return outputJSON(res)
+		}
+		for _, r := range res.Updated {
+			check := " "
+			if r.Done {
+				check = "x"
+			}
+			fmt.Printf("[%s] %s (#%d:%d)\n", check, r.Text, r.NoteID, r.Index)
+		}
+		fmt.Printf("Updated %d tasks", len(res.Updated))
+		if len(res.Skipped) > 0 {
+			fmt.Printf(", skipped %d that no longer match a task", len(res.Skipped))
+		}
+		fmt.Println(".")
+		return nil
+	},
+}
+
+func init() {
+	todoCmd.AddCommand(todoICSCmd)
+	todoCmd.AddCommand(todoICSImportCmd)
+
+	todoICSCmd.Flags().StringP("output", "o", "", "Write to this file instead of stdout")
+	todoICSImportCmd.Flags().BoolP("json", "j", false, "Output as JSON")
+}
@@ -4,7 +4,6 @@ Copyright © 2026 abdul hamid <abdulachik@icloud.com>
 package cmd
 
 import (
-	"bufio"
 	"context"
 	"fmt"
 	"os"
@@ -12,23 +11,55 @@ import (
 	"strings"
 
 	"github.com/abdul-hamid-achik/noted/internal/db"
+	"github.com/abdul-hamid-achik/noted/internal/importer"
+	"github.com/abdul-hamid-achik/noted/internal/notesync"
 	"github.com/spf13/cobra"
-	"gopkg.in/yaml.v3"
 )
 
-type frontmatter struct {
-	Title string   `yaml:"title"`
-	Tags  []string `yaml:"tags"`
-}
-
 var importCmd = &cobra.Command{
 	Use:   "import <path>",
-	Short: "Import markdown files",
-	Args:  cobra.ExactArgs(1),
+	Short: "Import notes from files",
+	Long: `Import a markdown file, a directory of them, a zip bundle written by
+noted export --format zip, a Roam Research or Logseq JSON export, or a
+TiddlyWiki (its HTML file or a JSON export of tiddlers).
+
+--format picks the input format; the default, auto, tells it from the input.
+Formats: markdown, roam (Roam and Logseq JSON exports), tiddlywiki.
+
+Each Roam or Logseq page becomes a note whose blocks are a nested list, with
+[[page]] links kept and ((block references)) replaced by the referenced text.
+Each tiddler becomes a note with its tags, and wikitext is converted to
+markdown; system tiddlers and drafts are skipped.
+
+Images and other local files the notes link to are copied into the vault's
+assets/ directory, and the links are rewritten to point there. Relative links
+resolve against the directory of the file that contains them.
+
+Examples:
+  noted import meeting.md
+  noted import ~/notes --recursive --tags imported
+  noted import notes.zip
+  noted import graph.json --tags roam
+  noted import wiki.html --format tiddlywiki
+  noted import ~/notes --format markdown`,
+	Args: cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		path := args[0]
 		recursive, _ := cmd.Flags().GetBool("recursive")
 		extraTags, _ := cmd.Flags().GetString("tags")
+		format, _ := cmd.Flags().GetString("format")
+
+		if strings.EqualFold(filepath.Ext(path), ".zip") {
+			dir, err := os.MkdirTemp("", "noted-import-")
+			if err != nil {
+				return err
+			}
+			defer func() { _ = os.RemoveAll(dir) }()
+			if err := extractZip(path, dir); err != nil {
+				return fmt.Errorf("failed to read %s: %w", path, err)
+			}
+			path, recursive = dir, true
+		}
 
 		var extraTagList []string
 		if extraTags != "" {
@@ -40,67 +71,68 @@ var importCmd = &cobra.Command{
 			}
 		}
 
-		info, err := os.Stat(path)
-		if err != nil {
+		if _, err := os.Stat(path); err != nil {
 			return err
 		}
-
-		var files []string
-		if info.IsDir() {
-			if recursive {
-				err = filepath.Walk(path, func(p string, fi os.FileInfo, err error) error {
-					if err != nil {
-						return err
-					}
-					if !fi.IsDir() && strings.HasSuffix(strings.ToLower(fi.Name()), ".md") {
-						files = append(files, p)
-					}
-					return nil
-				})
-			} else {
-				entries, err := os.ReadDir(path)
-				if err != nil {
-					return err
-				}
-				for _, e := range entries {
-					if !e.IsDir() && strings.HasSuffix(strings.ToLower(e.Name()), ".md") {
-						files = append(files, filepath.Join(path, e.Name()))
-					}
-				}
-			}
-			if err != nil {
+		var imp importer.Importer
+		if format == "auto" {
+			var err error
+			if imp, err = importer.Detect(path); err != nil {
 				return err
 			}
 		} else {
-			files = []string{path}
+			var ok bool
+			if imp, ok = importer.Lookup(format); !ok {
+				return fmt.Errorf("unknown format %q (formats: auto, %s)", format, strings.Join(importer.Formats(), ", "))
+			}
 		}
 
-		if len(files) == 0 {
-			fmt.Println("No markdown files found.")
+		entries, err := imp.Parse(path, importer.Options{Recursive: recursive})
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", args[0], err)
+		}
+		if len(entries) == 0 {
+			fmt.Printf("No %s notes found.\n", imp.Name())
 			return nil
 		}
 
 		ctx := context.Background()
-		imported := 0
+		var imported []db.Note
+		var assets *assetSet
+		if vdir := vaultDir(cmd); vdir != "" {
+			assets = dirAssets(vdir)
+		}
 
-		for _, file := range files {
-			title, content, fileTags, err := parseMarkdownFile(file)
+		for _, entry := range entries {
+			in, err := imp.Map(entry)
 			if err != nil {
-				fmt.Fprintf(os.Stderr, "error parsing %s: %v\n", file, err)
+				fmt.Fprintf(os.Stderr, "error parsing %s: %v\n", entry, err)
 				continue
 			}
+			content := in.Content
+			if assets != nil && in.Dir != "" {
+				var missing []string
+				content, missing, err = withAttachments(content, in.Dir, assets)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "error copying attachments of %s: %v\n", entry, err)
+					continue
+				}
+				for _, m := range missing {
+					fmt.Fprintf(os.Stderr, "warning: %s links to a missing file: %s\n", entry, m)
+				}
+			}
 
 			// Create a new slice to avoid modifying the original
-			allTags := make([]string, 0, len(fileTags)+len(extraTagList))
-			allTags = append(allTags, fileTags...)
+			allTags := make([]string, 0, len(in.Tags)+len(extraTagList))
+			allTags = append(allTags, in.Tags...)
 			allTags = append(allTags, extraTagList...)
 
 			note, err := database.CreateNote(ctx, db.CreateNoteParams{
-				Title:   title,
+				Title:   in.Title,
 				Content: content,
 			})
 			if err != nil {
-				fmt.Fprintf(os.Stderr, "error creating note from %s: %v\n", file, err)
+				fmt.Fprintf(os.Stderr, "error creating note from %s: %v\n", entry, err)
 				continue
 			}
 
@@ -119,52 +151,30 @@ var importCmd = &cobra.Command{
 				}
 			}
 
-			fmt.Printf("Imported #%d: %s\n", note.ID, title)
-			imported++
-		}
-
-		fmt.Printf("\n%d file(s) imported.\n", imported)
-		return nil
-	},
-}
-
-func parseMarkdownFile(path string) (title, content string, tags []string, err error) {
-	data, err := os.ReadFile(path)
-	if err != nil {
-		return "", "", nil, err
-	}
-
-	text := string(data)
-	fm := frontmatter{}
-
-	if strings.HasPrefix(text, "---\n") {
-		parts := strings.SplitN(text[4:], "\n---\n", 2)
-		if len(parts) == 2 {
-			if err := yaml.Unmarshal([]byte(parts[0]), &fm); err == nil {
-				text = strings.TrimPrefix(parts[1], "\n")
+			if err := notesync.SetAliases(ctx, database, note, in.Aliases); err != nil {
+				fmt.Fprintf(os.Stderr, "error adding aliases to %s: %v\n", entry, err)
 			}
+
+			fmt.Printf("Imported #%d: %s\n", note.ID, in.Title)
+			imported = append(imported, note)
 		}
-	}
-
-	if fm.Title != "" {
-		title = fm.Title
-	} else {
-		// Try to extract title from first H1
-		scanner := bufio.NewScanner(strings.NewReader(text))
-		for scanner.Scan() {
-			line := scanner.Text()
-			if strings.HasPrefix(line, "# ") {
-				title = strings.TrimPrefix(line, "# ")
-				break
+
+		// Links are resolved once every note exists, so pages can link to ones imported after them.
+		// WriteThrough then indexes each note as add does and mirrors it to the vault.
+		vlt := openVault(cmd)
+		for _, note := range imported {
+			if err := notesync.SetLinks(ctx, database, note.ID, note.Content); err != nil {
+				fmt.Fprintf(os.Stderr, "error linking #%d: %v\n", note.ID, err)
 			}
+			notesync.WriteThrough(ctx, database, vlt, note)
 		}
-		if title == "" {
-			// Use filename as title
-			title = strings.TrimSuffix(filepath.Base(path), ".md")
-		}
-	}
 
-	return title, text, fm.Tags, nil
+		fmt.Printf("\n%d note(s) imported.\n", len(imported))
+		if assets != nil && len(assets.names) > 0 {
+			fmt.Printf("%d attachment(s) copied to %s\n", len(assets.names), filepath.Join(vaultDir(cmd), assetsDir))
+		}
+		return nil
+	},
 }
 
 func init() {
@@ -172,4 +182,5 @@ func init() {
 
 	importCmd.Flags().BoolP("recursive", "r", false, "Scan subdirectories")
 	importCmd.Flags().StringP("tags", "T", "", "Add tags to all imported (comma-separated)")
+	importCmd.Flags().String("format", "auto", "Input format, or auto to detect it")
 }
@@ -0,0 +1,279 @@
+/*
+Copyright © 2026 abdul hamid <abdulachik@icloud.com>
+*/
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/abdul-hamid-achik/noted/internal/db"
+	"github.com/abdul-hamid-achik/noted/internal/notesync"
+	"github.com/abdul-hamid-achik/noted/internal/vault"
+	"github.com/spf13/cobra"
+)
+
+const inboxTriageHelp = `  f <folder-id>    file into a folder
+  t <tag,tag>      add tags
+  m <note-id>      merge into another note (appends, then trashes this one)
+  d                move to the trash
+  s / enter        skip
+  q                quit`
+
+// fileOutOfInbox removes the inbox tag from a note once it has been triaged.
+func fileOutOfInbox(ctx context.Context, noteID int64) error {
+	tag, err := database.GetTagByName(ctx, inboxTagName)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil
+		}
+		return err
+	}
+	return database.RemoveTagFromNote(ctx, db.RemoveTagFromNoteParams{NoteID: noteID, TagID: tag.ID})
+}
+
+// mergeNoteInto appends src's content to the target note (snapshotting the target first) and
+// moves src to the trash.
+func mergeNoteInto(ctx context.Context, vlt *vault.Vault, src db.Note, targetID int64) (db.Note, error) {
+	target, err := database.GetNote(ctx, targetID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return db.Note{}, fmt.Errorf("note #%d not found", targetID)
+		}
+		return db.Note{}, err
+	}
+	if target.ID == src.ID {
+		return db.Note{}, fmt.Errorf("cannot merge a note into itself")
+	}
+
+	content := target.Content
+	if content != "" && !strings.HasSuffix(content, "\n") {
+		content += "\n"
+	}
+	content += "\n" + src.Content
+
+	if err := notesync.SnapshotVersion(ctx, database, vlt, target.ID, target.Title, target.Content); err != nil {
+		return db.Note{}, fmt.Errorf("failed to save version: %w", err)
+	}
+	updated, err := database.UpdateNote(ctx, db.UpdateNoteParams{
+		Title:   target.Title,
+		Content: content,
+		ID:      target.ID,
+	})
+	if err != nil {
+		return db.Note{}, err
+	}
+	if err := notesync.Trash(ctx, database, vlt, src.ID); err != nil {
+		return db.Note{}, fmt.Errorf("failed to trash merged note #%d: %w", src.ID, err)
+	}
+	notesync.WriteThrough(ctx, database, vlt, updated)
+	deleteVectors(src.ID)
+	return updated, nil
+}
+
+type triageOutcome int
+
+const (
+	triageRetry triageOutcome = iota
+	triageSkipped
+	triageHandled
+	triageQuit
+)
+
+// triageNote applies one triage command line to an inbox note. Input mistakes (bad ids, unknown
+// actions) are reported and yield triageRetry so the caller re-prompts for the same note.
+func triageNote(ctx context.Context, vlt *vault.Vault, note db.Note, line string) (triageOutcome, error) {
+	action, arg, _ := strings.Cut(line, " ")
+	arg = strings.TrimSpace(arg)
+
+	switch strings.ToLower(action) {
+	case "", "s":
+		return triageSkipped, nil
+	case "q":
+		return triageQuit, nil
+	case "?", "h":
+		fmt.Println(inboxTriageHelp)
+		return triageRetry, nil
+	case "f":
+		folderID, err := strconv.ParseInt(arg, 10, 64)
+		if err != nil {
+			fmt.Println("usage: f <folder-id>")
+			return triageRetry, nil
+		}
+		if _, err := database.GetFolder(ctx, folderID); err != nil {
+			fmt.Printf("folder #%d not found\n", folderID)
+			return triageRetry, nil
+		}
+		if err := database.MoveNoteToFolder(ctx, db.MoveNoteToFolderParams{
+			FolderID: sql.NullInt64{Int64: folderID, Valid: true},
+			ID:       note.ID,
+		}); err != nil {
+			return triageRetry, fmt.Errorf("failed to assign folder: %w", err)
+		}
+		fmt.Printf("Filed #%d into folder #%d\n", note.ID, folderID)
+	case "t":
+		var applied []string
+		for _, tagName := range strings.Split(arg, ",") {
+			tagName = strings.TrimSpace(tagName)
+			if tagName == "" || tagName == inboxTagName {
+				continue
+			}
+			tag, err := database.CreateTag(ctx, tagName)
+			if err != nil {
+				return triageRetry, err
+			}
+			if err := database.AddTagToNote(ctx, db.AddTagToNoteParams{NoteID: note.ID, TagID: tag.ID}); err != nil {
+				return triageRetry, err
+			}
+			applied = append(applied, tagName)
+		}
+		if len(applied) == 0 {
+			fmt.Println("usage: t <tag,tag>")
+			return triageRetry, nil
+		}
+		fmt.Printf("Tagged #%d: %s\n", note.ID, strings.Join(applied, ", "))
+	case "m":
+		targetID, err := strconv.ParseInt(arg, 10, 64)
+		if err != nil {
+			fmt.Println("usage: m <note-id>")
+			return triageRetry, nil
+		}
+		if _, err := mergeNoteInto(ctx, vlt, note, targetID); err != nil {
+			fmt.Println(err)
+			return triageRetry, nil
+		}
+		fmt.Printf("Merged #%d into #%d\n", note.ID, targetID)
+		return triageHandled, nil
+	case "d":
+		if err := notesync.Trash(ctx, database, vlt, note.ID); err != nil {
+			return triageRetry, err
+		}
+		deleteVectors(note.ID)
+		fmt.Printf("Moved #%d to the trash\n", note.ID)
+		return triageHandled, nil
+	default:
+		fmt.Printf("unknown action %q (? for help)\n", action)
+		return triageRetry, nil
+	}
+
+	// Filed or tagged: the note leaves the inbox.
+	if err := fileOutOfInbox(ctx, note.ID); err != nil {
+		return triageRetry, err
+	}
+	if updated, err := database.GetNote(ctx, note.ID); err == nil {
+		notesync.WriteThrough(ctx, database, vlt, updated)
+	}
+	return triageHandled, nil
+}
+
+// triageInbox walks the inbox notes one at a time, reading a command line per note from in. Returns
+// the number of notes that left the inbox (filed, tagged, merged or deleted).
+func triageInbox(ctx context.Context, in io.Reader, vlt *vault.Vault, notes []db.Note) (int, error) {
+	reader := bufio.NewReader(in)
+	handled := 0
+
+	for i, note := range notes {
+		fmt.Printf("\n[%d/%d] #%d %s\n", i+1, len(notes), note.ID, note.Title)
+		if note.Content != note.Title {
+			fmt.Println(note.Content)
+		}
+
+		for {
+			fmt.Print("action [f/t/m/d/s/q, ? for help]: ")
+			line, err := reader.ReadString('\n')
+			if err != nil && line == "" {
+				if err == io.EOF {
+					return handled, nil
+				}
+				return handled, err
+			}
+
+			outcome, err := triageNote(ctx, vlt, note, strings.TrimSpace(line))
+			if err != nil {
+				return handled, err
+			}
+			if outcome == triageQuit {
+				return handled, nil
+			}
+			if outcome == triageHandled {
+				handled++
+			}
+			if outcome != triageRetry {
+				break
+			}
+		}
+	}
+	return handled, nil
+}
+
+var inboxCmd = &cobra.Command{
+	Use:   "inbox",
+	Short: "List and triage quick-captured notes",
+	Long: `List notes tagged "inbox" (see "noted quick"), oldest first. With --triage, step
+through them one at a time and file each into a folder, tag it, merge it into
+another note, or delete it. Filed and tagged notes leave the inbox.
+
+Examples:
+  noted inbox
+  noted inbox --triage
+  noted inbox --json`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		triage, _ := cmd.Flags().GetBool("triage")
+		asJSON, _ := cmd.Flags().GetBool("json")
+
+		ctx := context.Background()
+		notes, err := database.GetNotesByTagName(ctx, inboxTagName)
+		if err != nil {
+			return err
+		}
+		slices.Reverse(notes) // oldest first: triage in capture order
+
+		if triage {
+			if len(notes) == 0 {
+				fmt.Println("Inbox is empty.")
+				return nil
+			}
+			handled, err := triageInbox(ctx, cmd.InOrStdin(), openVault(cmd), notes)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("\nTriaged %d of %d note(s).\n", handled, len(notes))
+			return nil
+		}
+
+		if asJSON {
+			items := make([]noteListItem, len(notes))
+			for i, note := range notes {
+				items[i] = noteListItem{
+					ID:        note.ID,
+					Title:     note.Title,
+					CreatedAt: note.CreatedAt.Time.Format("2006-01-02T15:04:05Z07:00"),
+				}
+			}
+			return outputJSON(items)
+		}
+
+		if len(notes) == 0 {
+			fmt.Println("Inbox is empty.")
+			return nil
+		}
+
+		for _, note := range notes {
+			fmt.Printf("#%-4d %-40s %s\n", note.ID, note.Title, note.CreatedAt.Time.Format("2006-01-02 15:04"))
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(inboxCmd)
+
+	inboxCmd.Flags().BoolP("triage", "i", false, "Interactively file, tag, merge, or delete inbox notes")
+	inboxCmd.Flags().BoolP("json", "j", false, "Output as JSON")
+}
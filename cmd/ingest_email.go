@@ -0,0 +1,332 @@
+/*
+Copyright © 2026 abdul hamid <abdulachik@icloud.com>
+*/
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/abdul-hamid-achik/noted/internal/db"
+	"github.com/abdul-hamid-achik/noted/internal/notesync"
+	"github.com/abdul-hamid-achik/noted/internal/sanitize"
+	"github.com/spf13/cobra"
+)
+
+// emailSource marks notes made from emails; their source_ref is the Message-ID.
+const emailSource = "email"
+
+// emailMessage is the part of an email that becomes a note.
+type emailMessage struct {
+	MessageID   string
+	From        string
+	Subject     string
+	Date        string
+	Body        string
+	Attachments []emailAttachment
+}
+
+type emailAttachment struct {
+	Name string
+	Data []byte
+}
+
+// parseEmail reads an RFC 5322 message. The body is the first text/plain part, or the first
+// text/html part converted to plain text; parts with a file name are attachments.
+func parseEmail(r io.Reader) (emailMessage, error) {
+	msg, err := mail.ReadMessage(r)
+	if err != nil {
+		return emailMessage{}, err
+	}
+	dec := new(mime.WordDecoder)
+	header := func(name string) string {
+		v := msg.Header.Get(name)
+		if d, err := dec.DecodeHeader(v); err == nil {
+			v = d
+		}
+		return strings.TrimSpace(v)
+	}
+	em := emailMessage{
+		MessageID: strings.Trim(header("Message-Id"), "<>"),
+		From:      header("From"),
+		Subject:   header("Subject"),
+		Date:      header("Date"),
+	}
+
+	var plain, htmlBody string
+	err = walkEmailPart(msg.Header, msg.Body, func(mediaType, filename string, data []byte) {
+		switch {
+		case filename != "":
+			em.Attachments = append(em.Attachments, emailAttachment{Name: filename, Data: data})
+		case mediaType == "text/plain" && plain == "":
+			plain = string(data)
+		case mediaType == "text/html" && htmlBody == "":
+			htmlBody = string(data)
+		}
+	})
+	if err != nil {
+		return em, err
+	}
+	em.Body = plain
+	if em.Body == "" && htmlBody != "" {
+		em.Body = sanitize.HTMLToText(htmlBody)
+	}
+	em.Body = strings.TrimSpace(strings.ReplaceAll(em.Body, "\r\n", "\n"))
+	return em, nil
+}
+
+// partHeader is what walkEmailPart reads from a message's or part's headers.
+type partHeader interface {
+	Get(key string) string
+}
+
+// walkEmailPart decodes a MIME part and calls leaf for every non-multipart part in it, depth first.
+func walkEmailPart(h partHeader, body io.Reader, leaf func(mediaType, filename string, data []byte)) error {
+	mediaType, params, err := mime.ParseMediaType(h.Get("Content-Type"))
+	if err != nil {
+		mediaType, params = "text/plain", nil
+	}
+	if strings.HasPrefix(mediaType, "multipart/") {
+		mr := multipart.NewReader(body, params["boundary"])
+		for {
+			p, err := mr.NextRawPart()
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			if err := walkEmailPart(p.Header, p, leaf); err != nil {
+				return err
+			}
+		}
+	}
+
+	switch strings.ToLower(h.Get("Content-Transfer-Encoding")) {
+	case "base64":
+		body = base64.NewDecoder(base64.StdEncoding, newlineStripper{body})
+	case "quoted-printable":
+		body = quotedprintable.NewReader(body)
+	}
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return err
+	}
+
+	var filename string
+	if _, dp, err := mime.ParseMediaType(h.Get("Content-Disposition")); err == nil {
+		filename = dp["filename"]
+	}
+	if filename == "" {
+		filename = params["name"]
+	}
+	if filename != "" {
+		if d, err := new(mime.WordDecoder).DecodeHeader(filename); err == nil {
+			filename = d
+		}
+		filename = filepath.Base(filepath.FromSlash(filename))
+	}
+	leaf(mediaType, filename, data)
+	return nil
+}
+
+// newlineStripper drops the line breaks base64 bodies are wrapped with.
+type newlineStripper struct{ r io.Reader }
+
+func (n newlineStripper) Read(p []byte) (int, error) {
+	c, err := n.r.Read(p)
+	out := p[:0]
+	for _, b := range p[:c] {
+		if b != '\r' && b != '\n' {
+			out = append(out, b)
+		}
+	}
+	return len(out), err
+}
+
+type ingestEmailResult struct {
+	ID          int64    `json:"id"`
+	Title       string   `json:"title"`
+	From        string   `json:"from"`
+	MessageID   string   `json:"message_id,omitempty"`
+	Attachments []string `json:"attachments"`
+	Skipped     bool     `json:"skipped,omitempty"` // the message was ingested before
+}
+
+// ingestEmail stores an email as a note with source "email", its attachments in the vault's assets
+// directory. A message whose Message-ID was ingested before is skipped.
+func ingestEmail(ctx context.Context, cmd *cobra.Command, em emailMessage, tags []string) (ingestEmailResult, error) {
+	res := ingestEmailResult{From: em.From, MessageID: em.MessageID, Attachments: []string{}}
+
+	if em.MessageID != "" {
+		existing, err := database.GetNoteBySource(ctx, db.GetNoteBySourceParams{
+			Source:    sql.NullString{String: emailSource, Valid: true},
+			SourceRef: sql.NullString{String: em.MessageID, Valid: true},
+		})
+		switch {
+		case err == nil:
+			res.ID, res.Title, res.Skipped = existing.ID, existing.Title, true
+			return res, nil
+		case !errors.Is(err, sql.ErrNoRows):
+			return res, fmt.Errorf("failed to look up message: %w", err)
+		}
+	}
+
+	title := em.Subject
+	if title == "" {
+		title = "Email from " + em.From
+	}
+
+	var content strings.Builder
+	fmt.Fprintf(&content, "**From:** %s\n", em.From)
+	if em.Date != "" {
+		fmt.Fprintf(&content, "**Date:** %s\n", em.Date)
+	}
+	content.WriteString("\n" + em.Body + "\n")
+
+	if len(em.Attachments) > 0 {
+		links, err := storeEmailAttachments(vaultDir(cmd), em.Attachments)
+		if err != nil {
+			return res, err
+		}
+		content.WriteString("\n## Attachments\n\n")
+		for i, a := range em.Attachments {
+			embed := ""
+			if mt := mime.TypeByExtension(filepath.Ext(a.Name)); strings.HasPrefix(mt, "image/") {
+				embed = "!"
+			}
+			fmt.Fprintf(&content, "- %s[%s](%s)\n", embed, a.Name, links[i])
+			res.Attachments = append(res.Attachments, links[i])
+		}
+	}
+
+	var sourceRef sql.NullString
+	if em.MessageID != "" {
+		sourceRef = sql.NullString{String: em.MessageID, Valid: true}
+	}
+	note, err := database.CreateNoteWithTTL(ctx, db.CreateNoteWithTTLParams{
+		Title:     title,
+		Content:   content.String(),
+		Source:    sql.NullString{String: emailSource, Valid: true},
+		SourceRef: sourceRef,
+	})
+	if err != nil {
+		return res, fmt.Errorf("failed to create note: %w", err)
+	}
+	for _, name := range tags {
+		tag, err := database.CreateTag(ctx, name)
+		if err != nil {
+			return res, err
+		}
+		if err := database.AddTagToNote(ctx, db.AddTagToNoteParams{NoteID: note.ID, TagID: tag.ID}); err != nil {
+			return res, err
+		}
+	}
+	notesync.WriteThrough(ctx, database, openVault(cmd), note)
+
+	res.ID, res.Title = note.ID, note.Title
+	return res, nil
+}
+
+// storeEmailAttachments saves attachments in root/assets, deduplicated by content like imported
+// attachments, and returns their vault-relative paths.
+func storeEmailAttachments(root string, atts []emailAttachment) ([]string, error) {
+	tmp, err := os.MkdirTemp("", "noted-email-")
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = os.RemoveAll(tmp) }()
+
+	assets := dirAssets(root)
+	var links []string
+	for i, a := range atts {
+		// Each attachment gets its own directory, so two with the same name stay apart
+		dir := filepath.Join(tmp, fmt.Sprint(i))
+		if err := os.Mkdir(dir, 0o700); err != nil {
+			return nil, err
+		}
+		src := filepath.Join(dir, a.Name)
+		if err := os.WriteFile(src, a.Data, 0o600); err != nil {
+			return nil, err
+		}
+		link, err := assets.add(src)
+		if err != nil {
+			return nil, err
+		}
+		links = append(links, link)
+	}
+	return links, nil
+}
+
+var ingestEmailCmd = &cobra.Command{
+	Use:   "ingest-email [file]",
+	Short: "Turn an email into a note",
+	Long: `Read an email (an .eml file, or the raw message on stdin) and store it as a
+note with source "email". The subject becomes the title, the sender and date
+head the content, and attachments are saved in the vault's assets/ directory
+and linked from the note. An email that was already ingested, by Message-ID,
+is skipped.
+
+Pipe mail into it from a mail filter to get an "email myself a note" inbox,
+for example with procmail or a Sieve/fetchmail "pipe" action.
+
+Examples:
+  noted ingest-email message.eml
+  noted ingest-email --tags inbox < message.eml`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		tags, _ := cmd.Flags().GetStringSlice("tags")
+		asJSON, _ := cmd.Flags().GetBool("json")
+
+		var raw []byte
+		var err error
+		if len(args) == 1 {
+			raw, err = os.ReadFile(args[0])
+		} else {
+			raw, err = io.ReadAll(os.Stdin)
+		}
+		if err != nil {
+			return err
+		}
+		em, err := parseEmail(bytes.NewReader(raw))
+		if err != nil {
+			return fmt.Errorf("failed to parse email: %w", err)
+		}
+
+		res, err := ingestEmail(context.Background(), cmd, em, tags)
+		if err != nil {
+			return err
+		}
+		if asJSON {
+			return outputJSON(res)
+		}
+		if res.Skipped {
+			fmt.Printf("Already ingested as note #%d: %s\n", res.ID, res.Title)
+			return nil
+		}
+		fmt.Printf("Created note #%d: %s\n", res.ID, res.Title)
+		if len(res.Attachments) > 0 {
+			fmt.Printf("  Attachments: %d\n", len(res.Attachments))
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(ingestEmailCmd)
+
+	ingestEmailCmd.Flags().StringSliceP("tags", "T", nil, "Tags to add to the note")
+	ingestEmailCmd.Flags().BoolP("json", "j", false, "Output as JSON")
+}
@@ -8,7 +8,10 @@ import (
 	"database/sql"
 	"fmt"
 	"regexp"
+	"strconv"
 
+	"github.com/abdul-hamid-achik/noted/internal/db"
+	"github.com/abdul-hamid-achik/noted/internal/markdown"
 	"github.com/spf13/cobra"
 )
 
@@ -25,6 +28,12 @@ type unresolvedItem struct {
 	SourceNote string `json:"source_note"`
 }
 
+type linksResult struct {
+	ID     int64               `json:"id,omitempty"`
+	Links  []orphanItem        `json:"links,omitempty"`
+	Broken []db.UnresolvedLink `json:"broken"`
+}
+
 var orphansCmd = &cobra.Command{
 	Use:   "orphans",
 	Short: "Find notes with no links in or out",
@@ -42,6 +51,7 @@ Examples:
 			SELECT n.id, n.title FROM notes n
 			WHERE n.id NOT IN (SELECT source_note_id FROM note_links)
 			AND n.id NOT IN (SELECT target_note_id FROM note_links)
+			AND n.deleted_at IS NULL
 			ORDER BY n.title`)
 		if err != nil {
 			return fmt.Errorf("failed to query orphan notes: %w", err)
@@ -96,6 +106,7 @@ Examples:
 			SELECT n.id, n.title FROM notes n
 			WHERE n.id IN (SELECT target_note_id FROM note_links)
 			AND n.id NOT IN (SELECT source_note_id FROM note_links)
+			AND n.deleted_at IS NULL
 			ORDER BY n.title`)
 		if err != nil {
 			return fmt.Errorf("failed to query dead-end notes: %w", err)
@@ -137,7 +148,7 @@ var unresolvedCmd = &cobra.Command{
 	Use:   "unresolved",
 	Short: "Find broken wikilinks",
 	Long: `Find unresolved wikilinks — [[references]] in note content
-where no matching note title exists.
+where no note has a matching title or alias.
 
 Examples:
   noted unresolved
@@ -156,7 +167,11 @@ Examples:
 			matches := wikilinkRe.FindAllStringSubmatch(note.Content, -1)
 			for _, match := range matches {
 				linkText := match[1]
-				_, err := database.GetNoteByTitle(ctx, linkText)
+				target := markdown.ParseLink(linkText).Target // [[Title#Section|display]] → Title
+				if target == "" {
+					continue
+				}
+				_, err := database.ResolveNoteTitle(ctx, target)
 				if err == sql.ErrNoRows {
 					items = append(items, unresolvedItem{
 						LinkText:   linkText,
@@ -164,7 +179,7 @@ Examples:
 						SourceNote: note.Title,
 					})
 				} else if err != nil {
-					return fmt.Errorf("failed to look up note %q: %w", linkText, err)
+					return fmt.Errorf("failed to look up note %q: %w", target, err)
 				}
 			}
 		}
@@ -188,12 +203,117 @@ Examples:
 	},
 }
 
+var linksCmd = &cobra.Command{
+	Use:   "links [id]",
+	Short: "Show a note's links, or the wikilinks to notes not yet written",
+	Long: `Show the notes a note links to and the [[links]] in it that no note answers
+to yet.
+
+With --broken, show only the broken links: the titles you have referenced that
+no note has, whether never written or since renamed or deleted, each with the
+notes that reference it. Without an ID, --broken covers every note.
+
+Examples:
+  noted links 42
+  noted links --broken
+  noted links 42 --broken --json`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		broken, _ := cmd.Flags().GetBool("broken")
+		asJSON, _ := cmd.Flags().GetBool("json")
+
+		var res linksResult
+		if len(args) == 1 {
+			id, err := strconv.ParseInt(args[0], 10, 64)
+			if err != nil {
+				return fmt.Errorf("invalid note ID: %s", args[0])
+			}
+			res.ID = id
+		} else if !broken {
+			return fmt.Errorf("give a note ID, or --broken for every note")
+		}
+
+		ctx := context.Background()
+		if res.ID != 0 {
+			note, err := database.GetNote(ctx, res.ID)
+			if err != nil {
+				if err == sql.ErrNoRows {
+					return fmt.Errorf("note #%d not found", res.ID)
+				}
+				return err
+			}
+			// Resolved from the content as it is now, like [[links]] are followed
+			for _, l := range markdown.LinkTargets(note.Content) {
+				if markdown.IsAttachment(l.Target) {
+					continue
+				}
+				to, err := database.ResolveNoteTitle(ctx, l.Target)
+				switch {
+				case err == sql.ErrNoRows:
+					res.Broken = append(res.Broken, db.UnresolvedLink{
+						Target: l.Target,
+						Notes:  []db.LinkSource{{ID: note.ID, Title: note.Title}},
+					})
+				case err != nil:
+					return fmt.Errorf("failed to look up note %q: %w", l.Target, err)
+				case !broken:
+					res.Links = append(res.Links, orphanItem{ID: to.ID, Title: to.Title})
+				}
+			}
+		} else {
+			rows, err := database.ListUnresolvedLinks(ctx)
+			if err != nil {
+				return fmt.Errorf("failed to get broken links: %w", err)
+			}
+			res.Broken = db.GroupUnresolvedLinks(rows, 0)
+		}
+
+		if asJSON {
+			return outputJSON(res)
+		}
+
+		if !broken {
+			if len(res.Links) == 0 {
+				fmt.Printf("Note #%d links to no notes.\n", res.ID)
+			} else {
+				fmt.Printf("Notes linked from #%d:\n", res.ID)
+				for _, item := range res.Links {
+					fmt.Printf("  #%-4d %s\n", item.ID, item.Title)
+				}
+			}
+			if len(res.Broken) > 0 {
+				fmt.Println()
+			}
+		}
+		if len(res.Broken) == 0 {
+			if broken {
+				fmt.Println("No broken links found.")
+			}
+			return nil
+		}
+		fmt.Println("Broken links (no note with this title yet):")
+		for _, b := range res.Broken {
+			fmt.Printf("  [[%s]]\n", b.Target)
+			if res.ID != 0 {
+				continue
+			}
+			for _, n := range b.Notes {
+				fmt.Printf("      #%-4d %s\n", n.ID, n.Title)
+			}
+		}
+		return nil
+	},
+}
+
 func init() {
 	rootCmd.AddCommand(orphansCmd)
 	rootCmd.AddCommand(deadendsCmd)
 	rootCmd.AddCommand(unresolvedCmd)
+	rootCmd.AddCommand(linksCmd)
 
 	orphansCmd.Flags().BoolP("json", "j", false, "Output as JSON")
 	deadendsCmd.Flags().BoolP("json", "j", false, "Output as JSON")
 	unresolvedCmd.Flags().BoolP("json", "j", false, "Output as JSON")
+	linksCmd.Flags().Bool("broken", false, "Show only links to notes that don't exist")
+	linksCmd.Flags().BoolP("json", "j", false, "Output as JSON")
 }
@@ -7,8 +7,12 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/abdul-hamid-achik/noted/internal/db"
+	"github.com/abdul-hamid-achik/noted/internal/memory"
 	"github.com/spf13/cobra"
 )
 
@@ -16,17 +20,56 @@ type noteListItem struct {
 	ID        int64  `json:"id"`
 	Title     string `json:"title"`
 	CreatedAt string `json:"created_at"`
+	UpdatedAt string `json:"updated_at"`
+	Pinned    bool   `json:"pinned,omitempty"`
+	Archived  bool   `json:"archived,omitempty"`
+	Lang      string `json:"lang,omitempty"`
+}
+
+// parseUpdatedWindow reads --since and --until: each a date (YYYY-MM-DD), an RFC 3339 time, or a
+// duration back from now such as 2d or 12h. A bare --until date includes that whole day.
+func parseUpdatedWindow(since, until string) (from, before sql.NullTime, err error) {
+	now := time.Now()
+	t, err := memory.ParseTimeBound(since, now, false)
+	if err != nil {
+		return from, before, fmt.Errorf("invalid --since: %w", err)
+	}
+	from = sql.NullTime{Time: t.UTC(), Valid: !t.IsZero()}
+	if t, err = memory.ParseTimeBound(until, now, true); err != nil {
+		return from, before, fmt.Errorf("invalid --until: %w", err)
+	}
+	before = sql.NullTime{Time: t.UTC(), Valid: !t.IsZero()}
+	return from, before, nil
 }
 
 var listCmd = &cobra.Command{
 	Use:   "list",
 	Short: "List all notes",
-	Long: `List all notes in your knowledge base, optionally filtered by tag.
+	Long: `List all notes in your knowledge base, optionally filtered by tag, folder,
+or language. Archived notes are left out unless --archived is given.
+
+--sort orders the list by created (the default), updated, or title, or puts
+pinned notes first with pinned; --order asc or desc reverses it. Dates sort
+newest first and titles A to Z unless --order says otherwise.
+
+--since and --until keep the notes last updated in that window. Each takes a
+date (YYYY-MM-DD), an RFC 3339 time, or a duration back from now (2d, 12h).
+
+A note's language is detected from its content when it is saved; --lang takes
+an ISO 639-1 code (en, es, fr, de, pt, it).
 
 Examples:
   noted list
   noted list -n 50
+  noted list -n 50 --offset 50
   noted list --tag work
+  noted list --sort updated
+  noted list --sort title --order desc
+  noted list --since 2d
+  noted list --since 2025-03-01 --until 2025-03-31
+  noted list --folder 3 --subfolders
+  noted list --lang es
+  noted list --archived
   noted list --json`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		limit, err := cmd.Flags().GetInt("limit")
@@ -40,24 +83,68 @@ Examples:
 		}
 
 		folderID, _ := cmd.Flags().GetInt64("folder")
+		subfolders, _ := cmd.Flags().GetBool("subfolders")
 		asJSON, _ := cmd.Flags().GetBool("json")
+		archived, _ := cmd.Flags().GetBool("archived")
+		offset, _ := cmd.Flags().GetInt("offset")
+		lang, _ := cmd.Flags().GetString("lang")
+		lang = strings.ToLower(lang)
+		sortBy, _ := cmd.Flags().GetString("sort")
+		order, _ := cmd.Flags().GetString("order")
+		ascending, err := db.ParseNoteSort(sortBy, order)
+		if err != nil {
+			return err
+		}
+		sinceStr, _ := cmd.Flags().GetString("since")
+		untilStr, _ := cmd.Flags().GetString("until")
+		since, before, err := parseUpdatedWindow(sinceStr, untilStr)
+		if err != nil {
+			return err
+		}
 
 		ctx := context.Background()
 		var notes []db.Note
+		total := int64(-1) // known only for the paginated listing
 
-		if cmd.Flags().Changed("folder") {
+		if cmd.Flags().Changed("folder") && subfolders {
+			notes, err = database.GetNotesInFolderTree(ctx, folderID)
+		} else if cmd.Flags().Changed("folder") {
 			notes, err = database.GetNotesByFolder(ctx, sql.NullInt64{Int64: folderID, Valid: true})
 		} else if tag != "" {
 			notes, err = database.GetNotesByTagName(ctx, tag)
 		} else {
 			notes, err = database.ListNotes(ctx, db.ListNotesParams{
-				Limit:  int64(limit),
-				Offset: 0,
+				Sort:            sortBy,
+				Ascending:       ascending,
+				IncludeArchived: archived,
+				Lang:            lang,
+				UpdatedSince:    since,
+				UpdatedBefore:   before,
+				Limit:           int64(limit),
+				Offset:          int64(offset),
 			})
+			if err == nil {
+				total, err = database.CountListedNotes(ctx, db.CountListedNotesParams{
+					IncludeArchived: archived,
+					Lang:            lang,
+					UpdatedSince:    since,
+					UpdatedBefore:   before,
+				})
+			}
 		}
 		if err != nil {
 			return err
 		}
+		if total < 0 { // a filtered list, which comes newest first
+			db.SortNotes(notes, sortBy, ascending)
+		}
+		if !archived {
+			notes = slices.DeleteFunc(notes, func(n db.Note) bool { return n.ArchivedAt.Valid })
+		}
+		if lang != "" {
+			notes = slices.DeleteFunc(notes, func(n db.Note) bool { return n.Lang.String != lang })
+		}
+		notes = slices.DeleteFunc(notes, func(n db.Note) bool { return !db.UpdatedBetween(n, since, before) })
 
 		if asJSON {
 			items := make([]noteListItem, len(notes))
@@ -66,6 +153,10 @@ Examples:
 					ID:        note.ID,
 					Title:     note.Title,
 					CreatedAt: note.CreatedAt.Time.Format("2006-01-02T15:04:05Z07:00"),
+					UpdatedAt: note.UpdatedAt.Time.Format("2006-01-02T15:04:05Z07:00"),
+					Pinned:    note.Pinned.Bool,
+					Archived:  note.ArchivedAt.Valid,
+					Lang:      note.Lang.String,
 				}
 			}
 			return outputJSON(items)
@@ -81,7 +172,17 @@ Examples:
 			if note.Pinned.Valid && note.Pinned.Bool {
 				pin = "📌 "
 			}
-			fmt.Printf("#%-4d %s%-37s %s\n", note.ID, pin, note.Title, note.CreatedAt.Time.Format("2006-01-02"))
+			if note.ArchivedAt.Valid {
+				pin += "[archived] "
+			}
+			date := note.CreatedAt.Time
+			if sortBy == "updated" || sortBy == "pinned" {
+				date = note.UpdatedAt.Time
+			}
+			fmt.Printf("#%-4d %s%-37s %s\n", note.ID, pin, note.Title, date.Format("2006-01-02"))
+		}
+		if end := int64(offset + len(notes)); end < total {
+			fmt.Printf("\nShowing %d-%d of %d notes; next page: noted list --offset %d\n", offset+1, end, total, end)
 		}
 
 		return nil
@@ -92,7 +193,15 @@ func init() {
 	rootCmd.AddCommand(listCmd)
 
 	listCmd.Flags().IntP("limit", "n", 20, "Max number of notes to show")
+	listCmd.Flags().Int("offset", 0, "Skip this many notes (for paging through the list)")
 	listCmd.Flags().StringP("tag", "T", "", "Filter by tag name")
 	listCmd.Flags().Int64("folder", 0, "Filter by folder ID")
+	listCmd.Flags().Bool("subfolders", false, "With --folder, include notes in the folders below it")
+	listCmd.Flags().String("lang", "", "Filter by detected language (e.g. en, es)")
+	listCmd.Flags().String("since", "", "Only notes updated since a date (YYYY-MM-DD) or duration ago (e.g. 2d)")
+	listCmd.Flags().String("until", "", "Only notes updated before the end of a date or a duration ago")
+	listCmd.Flags().String("sort", "created", "Sort by created, updated, title, or pinned (pinned notes first)")
+	listCmd.Flags().String("order", "", "Sort order: asc or desc (default: desc, asc for title)")
+	listCmd.Flags().Bool("archived", false, "Include archived notes")
 	listCmd.Flags().BoolP("json", "j", false, "Output as JSON")
 }
@@ -12,7 +12,6 @@ import (
 
 	"github.com/abdul-hamid-achik/noted/internal/config"
 	notedmcp "github.com/abdul-hamid-achik/noted/internal/mcp"
-	"github.com/abdul-hamid-achik/noted/internal/veclite"
 	"github.com/spf13/cobra"
 )
 
@@ -32,14 +31,28 @@ Environment variables:
   NOTED_VECLITE_PATH     Path to veclite database for semantic search (optional)
   NOTED_EMBEDDING_MODEL  Embedding model name (default: nomic-embed-text)
   OLLAMA_HOST            Ollama server URL (default: http://localhost:11434)
+  NOTED_SEARCH_HISTORY   Set to "off" to stop recording searches (default: on)
+  NOTED_MCP_TOOLS        Default for --tools
+  NOTED_MCP_SAFE         Default for --safe
+  NOTED_MCP_MAX_CREATES_PER_MINUTE
+                         Notes/memories agents may create per minute (default: 60, 0 = unlimited)
+  NOTED_MCP_MAX_FORGET   Memories one noted_forget call may delete (default: 100, 0 = unlimited)
+
+Tool groups (--tools): notes, search, tags, memory, sync, daily, templates,
+tasks, history, links, stats. --safe leaves out tools that delete data (noted_delete,
+noted_forget, noted_tag_delete, noted_tag_cleanup, noted_template_delete).
 
 Example usage with Claude Code:
-  claude mcp add noted -- noted mcp`,
+  claude mcp add noted -- noted mcp
+  claude mcp add noted-memory -- noted mcp --tools memory,search --safe`,
 	RunE: runMCP,
 }
 
 func init() {
 	rootCmd.AddCommand(mcpCmd)
+
+	mcpCmd.Flags().String("tools", "", "Comma-separated tool groups to expose (default: all)")
+	mcpCmd.Flags().Bool("safe", false, "Leave out tools that delete notes, memories, tags, or templates")
 }
 
 func runMCP(cmd *cobra.Command, args []string) error {
@@ -49,6 +62,18 @@ func runMCP(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to load config: %w", err)
 	}
 
+	toolSpec, safe := cfg.MCPTools, cfg.MCPSafe
+	if cmd.Flags().Changed("tools") {
+		toolSpec, _ = cmd.Flags().GetString("tools")
+	}
+	if cmd.Flags().Changed("safe") {
+		safe, _ = cmd.Flags().GetBool("safe")
+	}
+	toolGroups, err := notedmcp.ParseToolGroups(toolSpec)
+	if err != nil {
+		return err
+	}
+
 	// Initialize database (bypass PersistentPreRunE since we need custom handling)
 	if database == nil {
 		return fmt.Errorf("database not initialized")
@@ -57,7 +82,7 @@ func runMCP(cmd *cobra.Command, args []string) error {
 	// Try to initialize veclite syncer (optional)
 	var syncer notedmcp.Syncer
 	if cfg.VeclitePath != "" {
-		s, err := veclite.NewSyncer(cfg.VeclitePath, cfg.EmbeddingModel)
+		s, err := openSyncer(cfg, false)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Warning: veclite initialization failed: %v\n", err)
 			fmt.Fprintf(os.Stderr, "Semantic search will be disabled\n")
@@ -68,7 +93,19 @@ func runMCP(cmd *cobra.Command, args []string) error {
 	}
 
 	// Create MCP server, with vault write-through so agent edits land in the markdown vault too.
-	server := notedmcp.NewServer(database, conn, syncer).WithVault(openVault(cmd))
+	server := notedmcp.NewServer(database, conn, syncer).
+		WithVault(openVault(cmd)).
+		WithSearchHistory(cfg.SearchHistory).
+		WithDailyTemplate(cfg.DailyTemplate).
+		WithQueryTimer(queryTimer).
+		WithToolGroups(toolGroups).
+		WithSafeMode(safe).
+		WithQuotas(cfg.Quotas).
+		WithSanitize(cfg.Sanitize).
+		WithLimits(notedmcp.Limits{
+			CreatesPerMinute: cfg.MCPMaxCreatesPerMinute,
+			MaxForgetPerCall: cfg.MCPMaxForget,
+		})
 
 	// Setup context with cancellation
 	ctx, cancel := context.WithCancel(context.Background())
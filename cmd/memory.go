@@ -0,0 +1,114 @@
+/*
+Copyright © 2026 abdul hamid <abdulachik@icloud.com>
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/abdul-hamid-achik/noted/internal/memory"
+	"github.com/abdul-hamid-achik/noted/internal/notesync"
+	"github.com/spf13/cobra"
+)
+
+type memoryAdjustResult struct {
+	ID         int64  `json:"id"`
+	Title      string `json:"title"`
+	Category   string `json:"category"`
+	Importance int    `json:"importance"`
+}
+
+var memoryCmd = &cobra.Command{
+	Use:   "memory",
+	Short: "Manage stored memories",
+}
+
+var memoryPromoteCmd = &cobra.Command{
+	Use:   "promote <id>",
+	Short: "Raise a memory's importance",
+	Long: `Raise a memory's importance (up to 5), optionally moving it to another category.
+
+The memory's importance:N and memory:<category> tags are rewritten together,
+so it always ends up with exactly one of each.
+
+Examples:
+  noted memory promote 42
+  noted memory promote 42 --by 2
+  noted memory promote 42 --category decision`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runMemoryAdjust(cmd, args, 1)
+	},
+}
+
+var memoryDemoteCmd = &cobra.Command{
+	Use:   "demote <id>",
+	Short: "Lower a memory's importance",
+	Long: `Lower a memory's importance (down to 1), optionally moving it to another category.
+
+Examples:
+  noted memory demote 42
+  noted memory demote 42 --category fact`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runMemoryAdjust(cmd, args, -1)
+	},
+}
+
+// runMemoryAdjust moves a memory's importance by --by steps in direction sign.
+func runMemoryAdjust(cmd *cobra.Command, args []string, sign int) error {
+	asJSON, _ := cmd.Flags().GetBool("json")
+	by, _ := cmd.Flags().GetInt("by")
+	category, _ := cmd.Flags().GetString("category")
+
+	id, err := strconv.ParseInt(args[0], 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid memory ID: %s", args[0])
+	}
+	if by < 0 {
+		return fmt.Errorf("--by must not be negative")
+	}
+
+	ctx := context.Background()
+	mem, err := memory.Adjust(ctx, database, conn, memory.AdjustInput{
+		ID:       id,
+		Delta:    sign * by,
+		Category: category,
+	})
+	if err != nil {
+		return err
+	}
+
+	// Mirror the new tags to the vault frontmatter
+	if note, err := database.GetNote(ctx, id); err == nil {
+		notesync.WriteThrough(ctx, database, openVault(cmd), note)
+	}
+
+	if asJSON {
+		return outputJSON(memoryAdjustResult{
+			ID:         mem.ID,
+			Title:      mem.Title,
+			Category:   mem.Category,
+			Importance: mem.Importance,
+		})
+	}
+
+	fmt.Printf("Memory #%d: %s\n", mem.ID, mem.Title)
+	fmt.Printf("  Category:   %s\n", mem.Category)
+	fmt.Printf("  Importance: %d\n", mem.Importance)
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(memoryCmd)
+	memoryCmd.AddCommand(memoryPromoteCmd)
+	memoryCmd.AddCommand(memoryDemoteCmd)
+
+	for _, c := range []*cobra.Command{memoryPromoteCmd, memoryDemoteCmd} {
+		c.Flags().Int("by", 1, "Importance steps to move")
+		c.Flags().StringP("category", "c", "", "Move to this category: user-pref, project, decision, fact, todo")
+		c.Flags().BoolP("json", "j", false, "Output as JSON")
+	}
+}
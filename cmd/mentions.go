@@ -0,0 +1,87 @@
+/*
+Copyright © 2026 abdul hamid <abdulachik@icloud.com>
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var mentionsCmd = &cobra.Command{
+	Use:   "mentions [name]",
+	Short: "Show notes that mention a person",
+	Long: `List the notes that mention @name, most recently updated first. Without a
+name, list everyone mentioned and in how many notes.
+
+A mention is an @ followed by a name, anywhere in a note: "1:1 with @ana".
+Names are matched without regard to case, and e-mail addresses and markers
+like @due(...) are not mentions. Notes saved before mentions were tracked are
+indexed by "noted gc".
+
+Examples:
+  noted mentions ana
+  noted mentions @ana --json
+  noted mentions`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		asJSON, _ := cmd.Flags().GetBool("json")
+		ctx := context.Background()
+
+		if len(args) == 0 {
+			names, err := database.ListMentionNames(ctx)
+			if err != nil {
+				return fmt.Errorf("failed to list mentions: %w", err)
+			}
+			if asJSON {
+				return outputJSON(names)
+			}
+			if len(names) == 0 {
+				fmt.Println("No mentions found.")
+				return nil
+			}
+			for _, n := range names {
+				fmt.Printf("@%-30s %d notes\n", n.Name, n.NoteCount)
+			}
+			return nil
+		}
+
+		name := strings.ToLower(strings.TrimPrefix(args[0], "@"))
+		notes, err := database.GetNotesMentioning(ctx, name)
+		if err != nil {
+			return fmt.Errorf("failed to get notes: %w", err)
+		}
+
+		if asJSON {
+			items := make([]noteListItem, len(notes))
+			for i, note := range notes {
+				items[i] = noteListItem{
+					ID:        note.ID,
+					Title:     note.Title,
+					CreatedAt: note.CreatedAt.Time.Format("2006-01-02T15:04:05Z07:00"),
+				}
+			}
+			return outputJSON(items)
+		}
+
+		if len(notes) == 0 {
+			fmt.Printf("No notes mention @%s.\n", name)
+			return nil
+		}
+
+		fmt.Printf("Notes mentioning @%s:\n\n", name)
+		for _, note := range notes {
+			fmt.Printf("#%-4d %-40s %s\n", note.ID, note.Title, note.UpdatedAt.Time.Format("2006-01-02"))
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(mentionsCmd)
+
+	mentionsCmd.Flags().BoolP("json", "j", false, "Output as JSON")
+}
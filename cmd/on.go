@@ -0,0 +1,137 @@
+/*
+Copyright © 2026 abdul hamid <abdulachik@icloud.com>
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/abdul-hamid-achik/noted/internal/db"
+	"github.com/spf13/cobra"
+)
+
+type onDateItem struct {
+	ID        int64  `json:"id"`
+	Title     string `json:"title"`
+	CreatedAt string `json:"created_at"`
+	UpdatedAt string `json:"updated_at"`
+	Created   bool   `json:"created"` // false: only updated on the day
+}
+
+// parseOnDate accepts YYYY-MM-DD, "today", or "yesterday" (relative to now).
+func parseOnDate(s string, now time.Time) (time.Time, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", "today":
+		return now, nil
+	case "yesterday":
+		return now.AddDate(0, 0, -1), nil
+	}
+	t, err := time.ParseInLocation(dailyDateFormat, s, now.Location())
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %q (use YYYY-MM-DD, today, or yesterday)", s)
+	}
+	return t, nil
+}
+
+// notesOn returns notes created or updated on day. With thisDay, it instead returns notes created
+// on the same month and day in earlier years, newest first.
+func notesOn(ctx context.Context, day time.Time, thisDay bool) ([]db.Note, error) {
+	if thisDay {
+		return database.GetNotesOnThisDay(ctx, db.GetNotesOnThisDayParams{
+			MonthDay:   day.Format("01-02"),
+			BeforeYear: day.Format("2006"),
+		})
+	}
+	return database.GetNotesOnDate(ctx, day.Format(dailyDateFormat))
+}
+
+var onCmd = &cobra.Command{
+	Use:   "on [date]",
+	Short: "List notes created or updated on a day",
+	Long: `List notes created or updated on a given day (default: today). With
+--this-day, resurface notes created on the same date in previous years instead,
+which is handy for revisiting old journal entries.
+
+Examples:
+  noted on 2025-03-14
+  noted on yesterday
+  noted on --this-day
+  noted on 2025-03-14 --this-day --json`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		thisDay, _ := cmd.Flags().GetBool("this-day")
+		asJSON, _ := cmd.Flags().GetBool("json")
+
+		var dateArg string
+		if len(args) > 0 {
+			dateArg = args[0]
+		}
+		day, err := parseOnDate(dateArg, time.Now())
+		if err != nil {
+			return err
+		}
+
+		ctx := context.Background()
+		notes, err := notesOn(ctx, day, thisDay)
+		if err != nil {
+			return err
+		}
+
+		dayStr := day.Format(dailyDateFormat)
+		if asJSON {
+			items := make([]onDateItem, len(notes))
+			for i, note := range notes {
+				created := note.CreatedAt.Time.Local()
+				items[i] = onDateItem{
+					ID:        note.ID,
+					Title:     note.Title,
+					CreatedAt: note.CreatedAt.Time.Format("2006-01-02T15:04:05Z07:00"),
+					UpdatedAt: note.UpdatedAt.Time.Format("2006-01-02T15:04:05Z07:00"),
+					Created:   thisDay || created.Format(dailyDateFormat) == dayStr,
+				}
+			}
+			return outputJSON(items)
+		}
+
+		if len(notes) == 0 {
+			if thisDay {
+				fmt.Printf("No notes from %s in previous years.\n", day.Format("January 2"))
+			} else {
+				fmt.Printf("No notes on %s.\n", dayStr)
+			}
+			return nil
+		}
+
+		if thisDay {
+			year := ""
+			for _, note := range notes {
+				created := note.CreatedAt.Time.Local()
+				if y := created.Format("2006"); y != year {
+					year = y
+					fmt.Printf("%s (%d year(s) ago)\n", y, day.Year()-created.Year())
+				}
+				fmt.Printf("  #%-4d %s\n", note.ID, note.Title)
+			}
+			return nil
+		}
+
+		for _, note := range notes {
+			what, at := "created", note.CreatedAt.Time.Local()
+			if at.Format(dailyDateFormat) != dayStr {
+				what, at = "updated", note.UpdatedAt.Time.Local()
+			}
+			fmt.Printf("#%-4d %-40s %s %s\n", note.ID, note.Title, what, at.Format("15:04"))
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(onCmd)
+
+	onCmd.Flags().Bool("this-day", false, "Show notes from this date in previous years")
+	onCmd.Flags().BoolP("json", "j", false, "Output as JSON")
+}
@@ -0,0 +1,80 @@
+/*
+Copyright © 2026 abdul hamid <abdulachik@icloud.com>
+*/
+package cmd
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/abdul-hamid-achik/noted/internal/markdown"
+	"github.com/spf13/cobra"
+)
+
+type outlineResult struct {
+	ID      int64                  `json:"id"`
+	Title   string                 `json:"title"`
+	Outline []markdown.OutlineNode `json:"outline"`
+}
+
+var outlineCmd = &cobra.Command{
+	Use:   "outline <id>",
+	Short: "Show a note's heading outline",
+	Long: `Show the headings of a note as a tree, with the line each one starts on.
+--json adds the byte offsets where each section starts and ends.
+
+Read a single section with noted show <id> --section <heading>.
+
+Examples:
+  noted outline 42
+  noted outline 42 --json
+  noted show 42 --section Design`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		asJSON, _ := cmd.Flags().GetBool("json")
+
+		id, err := strconv.ParseInt(args[0], 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid note ID: %s", args[0])
+		}
+
+		note, err := database.GetNote(context.Background(), id)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return fmt.Errorf("note #%d not found", id)
+			}
+			return fmt.Errorf("failed to get note: %w", err)
+		}
+
+		outline := markdown.Outline(note.Content)
+		if asJSON {
+			if outline == nil {
+				outline = []markdown.OutlineNode{}
+			}
+			return outputJSON(outlineResult{ID: note.ID, Title: note.Title, Outline: outline})
+		}
+
+		if len(outline) == 0 {
+			fmt.Printf("Note #%d (%s) has no headings.\n", note.ID, note.Title)
+			return nil
+		}
+		printOutline(outline, 0)
+		return nil
+	},
+}
+
+func printOutline(nodes []markdown.OutlineNode, depth int) {
+	for _, n := range nodes {
+		fmt.Printf("%-5d %s%s %s\n", n.Line, strings.Repeat("  ", depth), strings.Repeat("#", n.Level), n.Text)
+		printOutline(n.Children, depth+1)
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(outlineCmd)
+
+	outlineCmd.Flags().BoolP("json", "j", false, "Output as JSON")
+}
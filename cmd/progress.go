@@ -0,0 +1,61 @@
+/*
+Copyright © 2026 abdul hamid <abdulachik@icloud.com>
+*/
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+const progressWidth = 30
+
+// progress draws a single-line progress bar with an ETA, redrawn in place with \r.
+type progress struct {
+	w     io.Writer
+	total int
+	start time.Time
+	now   func() time.Time
+}
+
+// newProgress returns a bar on stderr, or nil when stderr is not a terminal (a nil *progress
+// draws nothing, so callers need not check).
+func newProgress(total int) *progress {
+	stat, err := os.Stderr.Stat()
+	if err != nil || stat.Mode()&os.ModeCharDevice == 0 {
+		return nil
+	}
+	return &progress{w: os.Stderr, total: total, start: time.Now(), now: time.Now}
+}
+
+// update redraws the bar after done items.
+func (p *progress) update(done int) {
+	if p == nil || p.total == 0 {
+		return
+	}
+	_, _ = fmt.Fprintf(p.w, "\r%s", p.line(done))
+}
+
+// finish ends the bar's line.
+func (p *progress) finish() {
+	if p != nil && p.total > 0 {
+		_, _ = fmt.Fprintln(p.w)
+	}
+}
+
+// line renders e.g. "[#########.....................]  30/100  30%  ETA 1m10s".
+func (p *progress) line(done int) string {
+	filled := done * progressWidth / p.total
+	bar := strings.Repeat("#", filled) + strings.Repeat(".", progressWidth-filled)
+	eta := "--"
+	if done > 0 {
+		elapsed := p.now().Sub(p.start)
+		remaining := time.Duration(float64(elapsed) / float64(done) * float64(p.total-done))
+		eta = remaining.Round(time.Second).String()
+	}
+	width := len(fmt.Sprint(p.total))
+	return fmt.Sprintf("[%s] %*d/%d %3d%%  ETA %-8s", bar, width, done, p.total, done*100/p.total, eta)
+}
@@ -0,0 +1,107 @@
+/*
+Copyright © 2026 abdul hamid <abdulachik@icloud.com>
+*/
+package cmd
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/abdul-hamid-achik/noted/internal/config"
+	"github.com/abdul-hamid-achik/noted/internal/db"
+	"github.com/abdul-hamid-achik/noted/internal/notesync"
+	"github.com/spf13/cobra"
+)
+
+var projectCmd = &cobra.Command{
+	Use:   "project",
+	Short: "Show the project settings that apply here",
+	Long: `Show the .noted.yaml that applies to the current directory, found by
+walking up from it. Notes and memories captured with add, quick, remember, and
+capture-git inside the project get its tags and folder automatically:
+
+  scope: noted          # tagged "project:noted"
+  tags: [go, cli]       # added to every capture
+  folder: work/noted    # folder path, created if missing
+  category: project     # default memory category for remember`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		asJSON, _ := cmd.Flags().GetBool("json")
+
+		p, err := config.FindProject(".")
+		if err != nil {
+			return err
+		}
+		if asJSON {
+			return outputJSON(p)
+		}
+		if p == nil {
+			fmt.Printf("No %s in this directory or its parents.\n", config.ProjectFileName)
+			return nil
+		}
+		fmt.Printf("%-10s %s\n", "File:", p.Path)
+		if p.Scope != "" {
+			fmt.Printf("%-10s %s (tag %s)\n", "Scope:", p.Scope, p.ScopeTag())
+		}
+		if len(p.Tags) > 0 {
+			fmt.Printf("%-10s %s\n", "Tags:", strings.Join(p.Tags, ", "))
+		}
+		if p.Folder != "" {
+			fmt.Printf("%-10s %s\n", "Folder:", p.Folder)
+		}
+		if p.Category != "" {
+			fmt.Printf("%-10s %s\n", "Category:", p.Category)
+		}
+		return nil
+	},
+}
+
+// captureProject returns the .noted.yaml settings for dir, or nil outside a project. A broken
+// file is reported on stderr and ignored rather than failing the capture.
+func captureProject(dir string) *config.Project {
+	p, err := config.FindProject(dir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: ignoring project settings: %v\n", err)
+		return nil
+	}
+	return p
+}
+
+// applyProject adds the project's tags to a newly captured note and, unless keepFolder is set
+// because a folder was chosen explicitly, moves it to the project folder. Returns the note as
+// stored afterwards.
+func applyProject(ctx context.Context, p *config.Project, note db.Note, keepFolder bool) (db.Note, error) {
+	if p == nil {
+		return database.GetNote(ctx, note.ID)
+	}
+	for _, name := range p.CaptureTags() {
+		tag, err := database.CreateTag(ctx, name)
+		if err != nil {
+			return note, err
+		}
+		if err := database.AddTagToNote(ctx, db.AddTagToNoteParams{NoteID: note.ID, TagID: tag.ID}); err != nil {
+			return note, err
+		}
+	}
+	if p.Folder != "" && !keepFolder {
+		folderID, err := notesync.EnsureFolderPath(ctx, database, p.Folder)
+		if err != nil {
+			return note, fmt.Errorf("failed to create project folder %s: %w", p.Folder, err)
+		}
+		if err := database.MoveNoteToFolder(ctx, db.MoveNoteToFolderParams{
+			FolderID: sql.NullInt64{Int64: folderID, Valid: true},
+			ID:       note.ID,
+		}); err != nil {
+			return note, fmt.Errorf("failed to assign folder: %w", err)
+		}
+	}
+	return database.GetNote(ctx, note.ID)
+}
+
+func init() {
+	rootCmd.AddCommand(projectCmd)
+
+	projectCmd.Flags().BoolP("json", "j", false, "Output as JSON")
+}
@@ -0,0 +1,237 @@
+/*
+Copyright © 2026 abdul hamid <abdulachik@icloud.com>
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+	"text/template"
+	"unicode"
+
+	"github.com/abdul-hamid-achik/noted/internal/markdown"
+	"github.com/abdul-hamid-achik/noted/internal/vault"
+	"github.com/spf13/cobra"
+)
+
+type publishResult struct {
+	Target      string `json:"target"`
+	Static      string `json:"static"`
+	Published   int    `json:"published"`
+	Drafts      int    `json:"drafts"`
+	Attachments int    `json:"attachments"`
+	Removed     int    `json:"removed"`
+}
+
+// hugoLayout is the file "noted publish" writes for a note unless --layout gives another.
+var hugoLayout = template.Must(template.New("hugo").Funcs(layoutFuncs).Parse(`---
+title: {{quote .Title}}
+slug: {{quote .Slug}}
+date: {{.CreatedAt.Format "2006-01-02T15:04:05Z07:00"}}
+lastmod: {{.UpdatedAt.Format "2006-01-02T15:04:05Z07:00"}}
+draft: {{.Draft}}
+{{- if .Tags}}
+tags: [{{range $i, $t := .Tags}}{{if $i}}, {{end}}{{quote $t}}{{end}}]
+{{- end}}
+---
+
+{{.Content}}
+`))
+
+// siteLayout finds the Hugo site a content directory belongs to: the static directory beside
+// its content/ directory, and the URL path its pages are served under. ok is false when target
+// isn't inside a content/ directory.
+func siteLayout(target string) (static, urlPrefix string, ok bool) {
+	parts := strings.Split(filepath.ToSlash(target), "/")
+	i := -1
+	for j := len(parts) - 1; j >= 0; j-- {
+		if parts[j] == "content" {
+			i = j
+			break
+		}
+	}
+	if i < 0 {
+		return "", "", false
+	}
+	root := filepath.FromSlash(strings.Join(parts[:i], "/"))
+	if root == "" {
+		root = string(filepath.Separator)
+	}
+	urlPrefix = "/"
+	if section := strings.Join(parts[i+1:], "/"); section != "" {
+		urlPrefix += section + "/"
+	}
+	return filepath.Join(root, "static"), urlPrefix, true
+}
+
+// hugoAnchor returns the id Hugo gives a heading: lowercased, punctuation dropped, and spaces
+// turned into hyphens.
+func hugoAnchor(heading string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(strings.TrimSpace(heading)) {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-' || r == '_':
+			b.WriteRune(r)
+		case unicode.IsSpace(r):
+			b.WriteRune('-')
+		}
+	}
+	return b.String()
+}
+
+// isImageFile reports whether an attachment is one Markdown can show inline.
+func isImageFile(name string) bool {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".png", ".jpg", ".jpeg", ".gif", ".webp", ".svg", ".avif":
+		return true
+	}
+	return false
+}
+
+var publishCmd = &cobra.Command{
+	Use:   "publish",
+	Short: "Publish tagged notes to a Hugo site",
+	Long: `Write the notes with a tag into a Hugo site's content directory, one file per
+note with Hugo frontmatter: title, slug, date, lastmod, draft, and the note's
+other tags. Notes also tagged "draft" are published as drafts.
+
+[[Wikilinks]] to other published notes become links to their pages, with
+[[Note#Heading]] linking to the heading; links to unpublished notes become
+plain text. Attachments are copied into the site's static/assets/ directory.
+
+The target's site is found from its path: with --target ./site/content/posts,
+attachments go to ./site/static and pages are linked as /posts/<slug>/. For a
+target outside a content/ directory, give --static and --url-prefix.
+
+Publishing again updates the site: changed notes are rewritten, and the files
+of notes that lost the tag or were deleted are removed. --layout and
+--filename change the files as they do for noted export.
+
+Examples:
+  noted publish --tag blog --target ./site/content/posts
+  noted publish -T til --target ./site/content/til --filename '{{.CreatedAt.Format "2006"}}/{{.Slug}}.md'`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		tag, _ := cmd.Flags().GetString("tag")
+		target, _ := cmd.Flags().GetString("target")
+		static, _ := cmd.Flags().GetString("static")
+		urlPrefix, _ := cmd.Flags().GetString("url-prefix")
+		layout, _ := cmd.Flags().GetString("layout")
+		filename, _ := cmd.Flags().GetString("filename")
+		asJSON, _ := cmd.Flags().GetBool("json")
+
+		if tag == "" || target == "" {
+			return fmt.Errorf("--tag and --target are required")
+		}
+		target, err := filepath.Abs(target)
+		if err != nil {
+			return err
+		}
+		if siteStatic, sitePrefix, ok := siteLayout(target); ok {
+			if static == "" {
+				static = siteStatic
+			}
+			if urlPrefix == "" {
+				urlPrefix = sitePrefix
+			}
+		} else if static == "" || urlPrefix == "" {
+			return fmt.Errorf("%s is not in a Hugo content/ directory; give --static and --url-prefix", target)
+		}
+		if !strings.HasSuffix(urlPrefix, "/") {
+			urlPrefix += "/"
+		}
+		tmpls, err := loadExportTemplates(layout, filename)
+		if err != nil {
+			return err
+		}
+		if tmpls.layout == nil {
+			tmpls.layout = hugoLayout
+		}
+
+		ctx := context.Background()
+		notes, err := database.GetNotesByTagName(ctx, tag)
+		if err != nil {
+			return err
+		}
+		published := make(map[int64]bool, len(notes))
+		for _, n := range notes {
+			published[n.ID] = true
+		}
+
+		res := publishResult{Target: target, Static: static, Published: len(notes)}
+		prepare := func(ln *layoutNote) {
+			ln.Content = markdown.RewriteLinks(ln.Content, func(l markdown.Link) (string, bool) {
+				text := l.Display
+				if text == "" {
+					text = l.Target
+				}
+				if markdown.IsAttachment(l.Target) {
+					// A Markdown link, which exportDir then points at the copy in static/
+					if l.Embed && isImageFile(l.Target) {
+						return "![" + l.Display + "](<" + l.Target + ">)", true
+					}
+					return "[" + text + "](<" + l.Target + ">)", true
+				}
+				to, err := database.ResolveNoteTitle(ctx, l.Target)
+				if err != nil || !published[to.ID] {
+					return text, true
+				}
+				url := urlPrefix + vault.Slugify(to.Title) + "/"
+				if l.Section != "" {
+					url += "#" + hugoAnchor(l.Section)
+				}
+				return "[" + text + "](" + url + ")", true
+			})
+			ln.Tags = slices.DeleteFunc(slices.Clone(ln.Tags), func(t string) bool {
+				return strings.EqualFold(t, tag) || strings.EqualFold(t, "draft")
+			})
+			if ln.Draft {
+				res.Drafts++
+			}
+		}
+
+		assets := dirAssets(static)
+		stats, err := exportDir(ctx, target, notes, vaultDir(cmd), dirExport{
+			templates: tmpls,
+			prepare:   prepare,
+			assets:    assets,
+			link:      func(_, stored string) string { return "/" + stored },
+			exclusive: true,
+		})
+		if err != nil {
+			return err
+		}
+		res.Removed = stats.removed
+		res.Attachments = len(assets.names)
+
+		if asJSON {
+			return outputJSON(res)
+		}
+		fmt.Printf("Published %d note(s) to %s", res.Published, target)
+		if res.Drafts > 0 {
+			fmt.Printf(" (%d drafts)", res.Drafts)
+		}
+		fmt.Println()
+		if res.Attachments > 0 {
+			fmt.Printf("Copied %d attachment(s) to %s\n", res.Attachments, filepath.Join(static, assetsDir))
+		}
+		if res.Removed > 0 {
+			fmt.Printf("Removed %d unpublished note(s)\n", res.Removed)
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(publishCmd)
+
+	publishCmd.Flags().StringP("tag", "T", "", "Publish the notes with this tag (required)")
+	publishCmd.Flags().String("target", "", "Directory to write the notes to, such as site/content/posts (required)")
+	publishCmd.Flags().String("static", "", "Site static directory attachments go under (default: beside content/)")
+	publishCmd.Flags().String("url-prefix", "", "URL path pages are served under (default: the target's path below content/)")
+	publishCmd.Flags().String("layout", "", "Go template file laying out each note, in place of the Hugo frontmatter")
+	publishCmd.Flags().String("filename", "", "Go template naming each note's file (default {{.Slug}}.md)")
+	publishCmd.Flags().BoolP("json", "j", false, "Output as JSON")
+}
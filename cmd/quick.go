@@ -0,0 +1,117 @@
+/*
+Copyright © 2026 abdul hamid <abdulachik@icloud.com>
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/abdul-hamid-achik/noted/internal/db"
+	"github.com/abdul-hamid-achik/noted/internal/notesync"
+	"github.com/spf13/cobra"
+)
+
+const inboxTagName = "inbox"
+
+// quickTitleMaxLen caps titles derived from the first line of a quick capture.
+const quickTitleMaxLen = 60
+
+// quickTitle derives a title from the first non-empty line of captured text, stripped of markdown
+// heading/list markers and truncated on a word boundary. Empty text yields "Untitled".
+func quickTitle(text string) string {
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(strings.TrimLeft(strings.TrimSpace(line), "#-*> "))
+		if line == "" {
+			continue
+		}
+		if r := []rune(line); len(r) > quickTitleMaxLen {
+			line = string(r[:quickTitleMaxLen])
+			if i := strings.LastIndex(line, " "); i > quickTitleMaxLen/2 {
+				line = line[:i]
+			}
+			line = strings.TrimSpace(line) + "…"
+		}
+		return line
+	}
+	return "Untitled"
+}
+
+var quickCmd = &cobra.Command{
+	Use:   "quick [text...]",
+	Short: "Quickly capture a thought into the inbox",
+	Long: `Capture a note with no ceremony. The title is taken from the first line of the
+text and the note is tagged "inbox" for later triage with "noted inbox".
+
+Examples:
+  noted quick "call the dentist about the crown"
+  echo "idea: cache embeddings per query" | noted quick
+  noted quick -T work "follow up with Sam on the migration"`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		tags, _ := cmd.Flags().GetString("tags")
+		asJSON, _ := cmd.Flags().GetBool("json")
+
+		content := strings.Join(args, " ")
+		if content == "" {
+			stat, _ := os.Stdin.Stat()
+			if (stat.Mode() & os.ModeCharDevice) != 0 {
+				return fmt.Errorf("nothing to capture: pass text as arguments or pipe it on stdin")
+			}
+			data, err := io.ReadAll(os.Stdin)
+			if err != nil {
+				return fmt.Errorf("reading stdin: %w", err)
+			}
+			content = strings.TrimSpace(string(data))
+		}
+		if content == "" {
+			return fmt.Errorf("nothing to capture")
+		}
+
+		ctx := context.Background()
+		note, err := database.CreateNote(ctx, db.CreateNoteParams{
+			Title:   quickTitle(content),
+			Content: content,
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, tagName := range append([]string{inboxTagName}, strings.Split(tags, ",")...) {
+			tagName = strings.TrimSpace(tagName)
+			if tagName == "" {
+				continue
+			}
+			tag, err := database.CreateTag(ctx, tagName)
+			if err != nil {
+				return err
+			}
+			if err := database.AddTagToNote(ctx, db.AddTagToNoteParams{NoteID: note.ID, TagID: tag.ID}); err != nil {
+				return err
+			}
+		}
+
+		note, err = applyProject(ctx, captureProject("."), note, false)
+		if err != nil {
+			return err
+		}
+
+		notesync.WriteThrough(ctx, database, openVault(cmd), note)
+
+		if asJSON {
+			return outputJSON(addResult{ID: note.ID, Title: note.Title})
+		}
+
+		fmt.Printf("Captured #%d: %s\n", note.ID, note.Title)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(quickCmd)
+
+	quickCmd.Flags().StringP("tags", "T", "", "Extra comma-separated tags (inbox is always added)")
+	quickCmd.Flags().BoolP("json", "j", false, "Output as JSON")
+}
@@ -0,0 +1,60 @@
+/*
+Copyright © 2026 abdul hamid <abdulachik@icloud.com>
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/abdul-hamid-achik/noted/internal/config"
+	"github.com/abdul-hamid-achik/noted/internal/quota"
+	"github.com/spf13/cobra"
+)
+
+// checkNoteQuota checks content about to be saved against the configured quotas, printing a warning
+// to stderr for each warning level passed. A new note is also checked against the database and
+// attachment limits; edits are not, so a vault over its limits can still be trimmed.
+func checkNoteQuota(ctx context.Context, cmd *cobra.Command, content string, isNew bool) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	var dbSize, attachments func() int64
+	if isNew {
+		dbSize = func() int64 {
+			size, _ := databaseSize(ctx)
+			return size
+		}
+		if dir := vaultDir(cmd); dir != "" {
+			attachments = func() int64 { return quota.DirSize(filepath.Join(dir, assetsDir)) }
+		}
+	}
+	warnings, err := cfg.Quotas.CheckNote(content, dbSize, attachments)
+	for _, w := range warnings {
+		fmt.Fprintf(os.Stderr, "Warning: %s\n", w)
+	}
+	return err
+}
+
+// quotaUsage measures the largest note, the database, and the attachments against the quotas that
+// are set.
+func quotaUsage(q quota.Quotas, largestNote, dbSize, attachments int64) []quota.Usage {
+	usages := []quota.Usage{}
+	for _, m := range []struct {
+		name  string
+		limit quota.Limit
+		used  int64
+	}{
+		{"largest note", q.NoteSize, largestNote},
+		{"database", q.DBSize, dbSize},
+		{"attachments", q.Attachments, attachments},
+	} {
+		if m.limit.Warn > 0 || m.limit.Max > 0 {
+			usages = append(usages, m.limit.Measure(m.name, m.used))
+		}
+	}
+	return usages
+}
@@ -40,11 +40,18 @@ var recallCmd = &cobra.Command{
 Uses semantic search if available (requires Ollama), otherwise falls back
 to keyword search.
 
+With --format context, prints a markdown "## Relevant memories" block with
+citations, ready to paste into a prompt (nothing is printed when no memories
+match).
+
 Examples:
   noted recall "database conventions"
   noted recall "authentication" --limit 10
   noted recall "project setup" --category project
-  noted recall "JWT" --semantic`,
+  noted recall "JWT" --semantic
+  noted recall "naming" --source code-review --since 30d
+  noted recall "deploys" --since 2025-01-01 --until 2025-03-31
+  noted recall "code style" --format context >> prompt.md`,
 	Args: cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		query := args[0]
@@ -52,12 +59,34 @@ Examples:
 		category, _ := cmd.Flags().GetString("category")
 		semantic, _ := cmd.Flags().GetBool("semantic")
 		asJSON, _ := cmd.Flags().GetBool("json")
+		format, _ := cmd.Flags().GetString("format")
+		source, _ := cmd.Flags().GetString("source")
+		sinceStr, _ := cmd.Flags().GetString("since")
+		untilStr, _ := cmd.Flags().GetString("until")
+
+		switch format {
+		case "text", "context":
+		case "json":
+			asJSON = true
+		default:
+			return fmt.Errorf("invalid --format %q (valid: text, json, context)", format)
+		}
+
+		now := time.Now()
+		since, err := memory.ParseTimeBound(sinceStr, now, false)
+		if err != nil {
+			return fmt.Errorf("invalid --since: %w", err)
+		}
+		until, err := memory.ParseTimeBound(untilStr, now, true)
+		if err != nil {
+			return fmt.Errorf("invalid --until: %w", err)
+		}
 
 		// Try to get veclite syncer
 		var syncer *veclite.Syncer
-		cfg, err := config.Load()
-		if err == nil && cfg.VeclitePath != "" {
-			syncer, _ = veclite.NewSearcher(cfg.VeclitePath, cfg.EmbeddingModel)
+		cfg, cfgErr := config.Load()
+		if cfgErr == nil && cfg.VeclitePath != "" {
+			syncer, _ = openSyncer(cfg, true)
 			if syncer != nil {
 				defer func() { _ = syncer.Close() }()
 			}
@@ -68,12 +97,20 @@ Examples:
 			Query:       query,
 			Limit:       limit,
 			Category:    category,
+			Source:      source,
+			Since:       since,
+			Until:       until,
 			UseSemantic: semantic && syncer != nil,
 		})
 		if err != nil {
 			return err
 		}
 
+		if format == "context" {
+			fmt.Print(memory.FormatContext(result.Memories))
+			return nil
+		}
+
 		if asJSON {
 			output := recallResultOutput{
 				Query:    result.Query,
@@ -154,6 +191,10 @@ func init() {
 
 	recallCmd.Flags().IntP("limit", "n", 5, "Max results to return")
 	recallCmd.Flags().StringP("category", "c", "", "Filter by category")
+	recallCmd.Flags().String("source", "", "Only memories from this source")
+	recallCmd.Flags().String("since", "", "Only memories created since a date (YYYY-MM-DD) or duration ago (e.g., 30d)")
+	recallCmd.Flags().String("until", "", "Only memories created up to a date (YYYY-MM-DD, inclusive) or duration ago")
 	recallCmd.Flags().BoolP("semantic", "s", true, "Use semantic search if available")
+	recallCmd.Flags().String("format", "text", "Output format: text, json, or context (markdown block for prompts)")
 	recallCmd.Flags().BoolP("json", "j", false, "Output as JSON")
 }
@@ -0,0 +1,237 @@
+/*
+Copyright © 2026 abdul hamid <abdulachik@icloud.com>
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"text/tabwriter"
+	"time"
+
+	"github.com/abdul-hamid-achik/noted/internal/config"
+	"github.com/abdul-hamid-achik/noted/internal/veclite"
+	"github.com/spf13/cobra"
+)
+
+var reindexCmd = &cobra.Command{
+	Use:   "reindex",
+	Short: "Rebuild the semantic search index",
+	Long: `Rebuild the semantic search index with the configured index settings.
+
+Existing vectors are kept, so nothing is re-embedded and Ollama is not needed.
+The flat index searches exactly but slows down as the index grows; the HNSW
+index answers approximately and stays fast on large indexes.
+
+With --tune, candidate HNSW settings are benchmarked against exact search and
+the fastest one reaching --target-recall is used.
+
+Environment variables:
+  NOTED_VECTOR_INDEX          flat (default) or hnsw
+  NOTED_HNSW_M                Connections per node (default: 16)
+  NOTED_HNSW_EF_CONSTRUCTION  Candidate list size while building (default: 200)
+  NOTED_HNSW_EF_SEARCH        Candidate list size while searching (default: 100)
+
+Examples:
+  noted reindex --index hnsw        # Switch to an HNSW index
+  noted reindex --tune              # Benchmark HNSW settings and use the best
+  noted reindex bench               # Compare the configured index with exact search`,
+	RunE: runReindex,
+}
+
+var reindexBenchCmd = &cobra.Command{
+	Use:   "bench",
+	Short: "Compare index recall and latency with exact search",
+	Long: `Compare the configured index (or --index/--m/--ef-* overrides) with exact
+brute-force search on in-memory copies of the index. Stored vectors are used
+as queries, so Ollama is not needed and the index on disk is not modified.`,
+	RunE: runReindexBench,
+}
+
+// tuneCandidates are the HNSW settings --tune tries.
+var tuneCandidates = func() []veclite.IndexConfig {
+	var out []veclite.IndexConfig
+	for _, m := range []int{8, 16, 32} {
+		for _, ef := range []int{50, 100, 200} {
+			out = append(out, veclite.IndexConfig{Type: veclite.IndexHNSW, M: m, EfSearch: ef})
+		}
+	}
+	return out
+}()
+
+func init() {
+	rootCmd.AddCommand(reindexCmd)
+	reindexCmd.AddCommand(reindexBenchCmd)
+
+	reindexCmd.PersistentFlags().String("index", "", "Index type: flat or hnsw (default from NOTED_VECTOR_INDEX)")
+	reindexCmd.PersistentFlags().Int("m", 0, "HNSW connections per node")
+	reindexCmd.PersistentFlags().Int("ef-construction", 0, "HNSW candidate list size while building")
+	reindexCmd.PersistentFlags().Int("ef-search", 0, "HNSW candidate list size while searching")
+	reindexCmd.PersistentFlags().Int("queries", 100, "Stored vectors to use as benchmark queries")
+	reindexCmd.PersistentFlags().Int("k", 10, "Results per benchmark query")
+	reindexCmd.PersistentFlags().BoolP("json", "j", false, "Output as JSON")
+	reindexCmd.Flags().Bool("tune", false, "Benchmark candidate HNSW settings and use the best")
+	reindexCmd.Flags().Float64("target-recall", 0.95, "Minimum recall@k for --tune")
+}
+
+// indexConfig returns the vector index settings from the environment.
+func indexConfig(cfg *config.Config) veclite.IndexConfig {
+	return veclite.IndexConfig{
+		Type:           cfg.VectorIndex,
+		M:              cfg.HNSWM,
+		EfConstruction: cfg.HNSWEfConstruction,
+		EfSearch:       cfg.HNSWEfSearch,
+	}
+}
+
+// reindexSettings applies --index/--m/--ef-* over the configured settings.
+func reindexSettings(cmd *cobra.Command, cfg *config.Config) (veclite.IndexConfig, error) {
+	ic := indexConfig(cfg)
+	if v, _ := cmd.Flags().GetString("index"); v != "" {
+		ic.Type = v
+	}
+	if v, _ := cmd.Flags().GetInt("m"); v > 0 {
+		ic.M = v
+	}
+	if v, _ := cmd.Flags().GetInt("ef-construction"); v > 0 {
+		ic.EfConstruction = v
+	}
+	if v, _ := cmd.Flags().GetInt("ef-search"); v > 0 {
+		ic.EfSearch = v
+	}
+	return ic.Validate()
+}
+
+// pickTuned returns the fastest result reaching targetRecall, or the most accurate one if none do.
+func pickTuned(results []veclite.BenchResult, targetRecall float64) (veclite.BenchResult, bool) {
+	var best veclite.BenchResult
+	found := false
+	for _, r := range results {
+		if r.RecallAtK >= targetRecall && (!found || r.IndexAvg < best.IndexAvg) {
+			best, found = r, true
+		}
+	}
+	if found {
+		return best, true
+	}
+	for i, r := range results {
+		if i == 0 || r.RecallAtK > best.RecallAtK {
+			best = r
+		}
+	}
+	return best, false
+}
+
+type reindexResult struct {
+	Index   veclite.IndexConfig   `json:"index"`
+	Vectors int                   `json:"vectors"`
+	Tuning  []veclite.BenchResult `json:"tuning,omitempty"`
+}
+
+func runReindex(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	asJSON, _ := cmd.Flags().GetBool("json")
+	tune, _ := cmd.Flags().GetBool("tune")
+
+	ic, err := reindexSettings(cmd, cfg)
+	if err != nil {
+		return err
+	}
+
+	var tuning []veclite.BenchResult
+	if tune {
+		queries, _ := cmd.Flags().GetInt("queries")
+		k, _ := cmd.Flags().GetInt("k")
+		target, _ := cmd.Flags().GetFloat64("target-recall")
+		candidates := slices.Clone(tuneCandidates)
+		for i := range candidates {
+			candidates[i].EfConstruction = ic.EfConstruction
+		}
+		if tuning, err = veclite.Benchmark(cfg.VeclitePath, candidates, queries, k); err != nil {
+			return err
+		}
+		best, ok := pickTuned(tuning, target)
+		if !asJSON {
+			printBench(tuning)
+			if !ok {
+				fmt.Printf("\nNo setting reached recall %.2f; using the most accurate.\n", target)
+			}
+		}
+		ic = best.Index
+	}
+
+	n, err := veclite.Reindex(cfg.VeclitePath, ic)
+	if err != nil {
+		return err
+	}
+	if asJSON {
+		return outputJSON(reindexResult{Index: ic, Vectors: n, Tuning: tuning})
+	}
+
+	fmt.Printf("Reindexed %d vectors with %s.\n", n, describeIndex(ic))
+	if configured, _ := indexConfig(cfg).Validate(); ic != configured {
+		fmt.Println("New vector indexes use NOTED_VECTOR_INDEX and NOTED_HNSW_*; to keep these settings, set:")
+		fmt.Printf("  NOTED_VECTOR_INDEX=%s", ic.Type)
+		if ic.Type == veclite.IndexHNSW {
+			fmt.Printf(" NOTED_HNSW_M=%d NOTED_HNSW_EF_CONSTRUCTION=%d NOTED_HNSW_EF_SEARCH=%d", ic.M, ic.EfConstruction, ic.EfSearch)
+		}
+		fmt.Println()
+	}
+	return nil
+}
+
+func runReindexBench(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	asJSON, _ := cmd.Flags().GetBool("json")
+	queries, _ := cmd.Flags().GetInt("queries")
+	k, _ := cmd.Flags().GetInt("k")
+
+	ic, err := reindexSettings(cmd, cfg)
+	if err != nil {
+		return err
+	}
+	results, err := veclite.Benchmark(cfg.VeclitePath, []veclite.IndexConfig{ic}, queries, k)
+	if err != nil {
+		return err
+	}
+	if asJSON {
+		return outputJSON(results[0])
+	}
+	printBench(results)
+	return nil
+}
+
+func describeIndex(ic veclite.IndexConfig) string {
+	if ic.Type != veclite.IndexHNSW {
+		return "a flat (exact) index"
+	}
+	return fmt.Sprintf("an HNSW index (m=%d, ef_construction=%d, ef_search=%d)", ic.M, ic.EfConstruction, ic.EfSearch)
+}
+
+func printBench(results []veclite.BenchResult) {
+	if len(results) == 0 {
+		return
+	}
+	first := results[0]
+	fmt.Printf("%d vectors, %d queries, top %d; exact search avg %s\n\n",
+		first.Vectors, first.Queries, first.K, first.ExactAvg.Round(time.Microsecond))
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "INDEX\tM\tEF_CONSTRUCTION\tEF_SEARCH\tRECALL@K\tAVG\tP95\tBUILD")
+	for _, r := range results {
+		m, efc, efs := "-", "-", "-"
+		if r.Index.Type == veclite.IndexHNSW {
+			m, efc, efs = fmt.Sprint(r.Index.M), fmt.Sprint(r.Index.EfConstruction), fmt.Sprint(r.Index.EfSearch)
+		}
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%.3f\t%s\t%s\t%s\n", r.Index.Type, m, efc, efs, r.RecallAtK,
+			r.IndexAvg.Round(time.Microsecond), r.IndexP95.Round(time.Microsecond), r.BuildTime.Round(time.Millisecond))
+	}
+	_ = w.Flush()
+}
@@ -36,6 +36,9 @@ Memories are notes with special tags for easy recall. They support:
 - TTL: Auto-expire after a duration
 - Source tracking: Where the memory came from
 
+Inside a project with a .noted.yaml, the project's tags, folder, and default
+category are applied too (see "noted project").
+
 Examples:
   noted remember "Always use snake_case for database columns"
   noted remember "Project uses PostgreSQL" --category project --importance 4
@@ -52,6 +55,11 @@ Examples:
 		sourceRef, _ := cmd.Flags().GetString("source-ref")
 		asJSON, _ := cmd.Flags().GetBool("json")
 
+		project := captureProject(".")
+		if project != nil && project.Category != "" && !cmd.Flags().Changed("category") {
+			category = project.Category
+		}
+
 		// Parse TTL if provided
 		var ttl time.Duration
 		if ttlStr != "" {
@@ -66,7 +74,7 @@ Examples:
 		var syncer *veclite.Syncer
 		cfg, err := config.Load()
 		if err == nil && cfg.VeclitePath != "" {
-			syncer, _ = veclite.NewSyncer(cfg.VeclitePath, cfg.EmbeddingModel)
+			syncer, _ = openSyncer(cfg, false)
 			if syncer != nil {
 				defer func() { _ = syncer.Close() }()
 			}
@@ -85,6 +93,13 @@ Examples:
 		if err != nil {
 			return err
 		}
+		if project != nil {
+			if note, err := database.GetNote(ctx, mem.ID); err == nil {
+				if _, err := applyProject(ctx, project, note, false); err != nil {
+					return err
+				}
+			}
+		}
 
 		if asJSON {
 			result := rememberResult{
@@ -7,8 +7,10 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
+	"time"
 
 	"github.com/abdul-hamid-achik/noted/internal/config"
 	"github.com/abdul-hamid-achik/noted/internal/db"
@@ -20,6 +22,9 @@ import (
 var (
 	database *db.Queries
 	conn     *sql.DB
+
+	// queryTimer times the queries database runs, for --query-stats and noted_query_stats.
+	queryTimer *db.QueryTimer
 )
 
 var rootCmd = &cobra.Command{
@@ -44,12 +49,17 @@ var rootCmd = &cobra.Command{
 			return err
 		}
 
-		database = db.New(conn)
+		queryTimer = db.NewQueryTimer(conn)
+		queryTimer.LogSlow(time.Duration(cfg.SlowQueryMS)*time.Millisecond, os.Stderr)
+		database = db.New(queryTimer)
 
 		return nil
 	},
 
 	PersistentPostRun: func(cmd *cobra.Command, args []string) {
+		if show, _ := cmd.Flags().GetBool("query-stats"); show && queryTimer != nil {
+			printQueryStats(queryTimer.Stats())
+		}
 		if conn != nil {
 			_ = conn.Close()
 		}
@@ -59,6 +69,10 @@ var rootCmd = &cobra.Command{
 func Execute() {
 	err := rootCmd.Execute()
 	if err != nil {
+		var exitErr *exitCodeError
+		if errors.As(err, &exitErr) {
+			os.Exit(exitErr.code) // noted exec passes on the command's exit status
+		}
 		os.Exit(1)
 	}
 }
@@ -66,6 +80,7 @@ func Execute() {
 func init() {
 	rootCmd.PersistentFlags().String("db", "", "Path to database file")
 	rootCmd.PersistentFlags().String("vault", "", "Path to the markdown vault directory (overrides $NOTED_VAULT)")
+	rootCmd.PersistentFlags().Bool("query-stats", false, "Print how long each database query took, to stderr")
 }
 
 // vaultDir resolves the vault directory: --vault flag, else config (which honors $NOTED_VAULT).
@@ -88,6 +103,14 @@ func openVault(cmd *cobra.Command) *vault.Vault {
 	return v
 }
 
+// printQueryStats writes per-query latency to stderr, so it never mixes with --json output.
+func printQueryStats(stats []db.QueryStat) {
+	fmt.Fprintf(os.Stderr, "\n%-40s %6s %10s %10s %10s\n", "QUERY", "COUNT", "TOTAL ms", "AVG ms", "MAX ms")
+	for _, s := range stats {
+		fmt.Fprintf(os.Stderr, "%-40s %6d %10.2f %10.2f %10.2f\n", s.Name, s.Count, s.TotalMS, s.AvgMS, s.MaxMS)
+	}
+}
+
 func outputJSON(v any) error {
 	data, err := json.MarshalIndent(v, "", "  ")
 	if err != nil {
@@ -0,0 +1,456 @@
+/*
+Copyright © 2026 abdul hamid <abdulachik@icloud.com>
+*/
+package cmd
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/abdul-hamid-achik/noted/internal/db"
+	"github.com/abdul-hamid-achik/noted/internal/notesync"
+	"github.com/abdul-hamid-achik/noted/internal/vault"
+	"github.com/spf13/cobra"
+)
+
+const scheduleSource = "schedule"
+
+// scheduleRule is a parsed recurrence rule: "daily", "weekdays", "weekly:<day>" or "monthly:<1-31>",
+// each with an optional "@HH:MM" time of day (default 09:00).
+type scheduleRule struct {
+	every   string
+	weekday time.Weekday
+	day     int
+	hour    int
+	minute  int
+}
+
+var scheduleWeekdays = map[string]time.Weekday{
+	"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday,
+	"thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+}
+
+func parseScheduleRule(s string) (scheduleRule, error) {
+	r := scheduleRule{hour: 9}
+	spec := strings.ToLower(strings.TrimSpace(s))
+
+	if i := strings.Index(spec, "@"); i >= 0 {
+		at, err := time.Parse("15:04", spec[i+1:])
+		if err != nil {
+			return r, fmt.Errorf("invalid time %q (use HH:MM)", spec[i+1:])
+		}
+		r.hour, r.minute = at.Hour(), at.Minute()
+		spec = spec[:i]
+	}
+
+	every, arg, _ := strings.Cut(spec, ":")
+	r.every = every
+	switch every {
+	case "daily", "weekdays":
+		if arg != "" {
+			return r, fmt.Errorf("%q takes no argument", every)
+		}
+	case "weekly":
+		if len(arg) < 3 {
+			return r, fmt.Errorf("weekly rule needs a day (e.g. weekly:mon)")
+		}
+		wd, ok := scheduleWeekdays[arg[:3]]
+		if !ok {
+			return r, fmt.Errorf("invalid weekday %q", arg)
+		}
+		r.weekday = wd
+	case "monthly":
+		day, err := strconv.Atoi(arg)
+		if err != nil || day < 1 || day > 31 {
+			return r, fmt.Errorf("monthly rule needs a day of month 1-31 (e.g. monthly:1)")
+		}
+		r.day = day
+	default:
+		return r, fmt.Errorf("invalid rule %q (use daily, weekdays, weekly:<day> or monthly:<day>)", s)
+	}
+	return r, nil
+}
+
+// matches reports whether the rule fires on the calendar day of t. Monthly rules on days a month
+// doesn't have (e.g. the 31st) fire on that month's last day instead.
+func (r scheduleRule) matches(t time.Time) bool {
+	switch r.every {
+	case "daily":
+		return true
+	case "weekdays":
+		return t.Weekday() != time.Saturday && t.Weekday() != time.Sunday
+	case "weekly":
+		return t.Weekday() == r.weekday
+	case "monthly":
+		lastDay := time.Date(t.Year(), t.Month()+1, 0, 0, 0, 0, 0, t.Location()).Day()
+		return t.Day() == min(r.day, lastDay)
+	}
+	return false
+}
+
+// next returns the first occurrence of the rule strictly after the given time.
+func (r scheduleRule) next(after time.Time) time.Time {
+	for i := 0; i <= 366; i++ {
+		t := time.Date(after.Year(), after.Month(), after.Day()+i, r.hour, r.minute, 0, 0, after.Location())
+		if t.After(after) && r.matches(t) {
+			return t
+		}
+	}
+	return after.AddDate(1, 0, 0)
+}
+
+type scheduleItem struct {
+	ID         int64    `json:"id"`
+	Name       string   `json:"name"`
+	Rule       string   `json:"rule"`
+	Title      string   `json:"title"`
+	Template   string   `json:"template,omitempty"`
+	Tags       []string `json:"tags"`
+	FolderID   *int64   `json:"folder_id,omitempty"`
+	NextRunAt  string   `json:"next_run_at"`
+	LastRunAt  string   `json:"last_run_at,omitempty"`
+	LastNoteID *int64   `json:"last_note_id,omitempty"`
+}
+
+func formatScheduleItem(s db.Schedule) scheduleItem {
+	item := scheduleItem{
+		ID:        s.ID,
+		Name:      s.Name,
+		Rule:      s.Rule,
+		Title:     s.Title,
+		Tags:      splitScheduleTags(s.Tags),
+		NextRunAt: s.NextRunAt.Format(time.RFC3339),
+	}
+	if s.TemplateName.Valid {
+		item.Template = s.TemplateName.String
+	}
+	if s.FolderID.Valid {
+		item.FolderID = &s.FolderID.Int64
+	}
+	if s.LastRunAt.Valid {
+		item.LastRunAt = s.LastRunAt.Time.Format(time.RFC3339)
+	}
+	if s.LastNoteID.Valid {
+		item.LastNoteID = &s.LastNoteID.Int64
+	}
+	return item
+}
+
+func splitScheduleTags(tags string) []string {
+	out := []string{}
+	for _, t := range strings.Split(tags, ",") {
+		if t = strings.TrimSpace(t); t != "" {
+			out = append(out, t)
+		}
+	}
+	return out
+}
+
+type scheduleRunItem struct {
+	Schedule  string `json:"schedule"`
+	NoteID    int64  `json:"note_id,omitempty"`
+	Title     string `json:"title"`
+	NextRunAt string `json:"next_run_at"`
+}
+
+// runDueSchedules creates one note for every schedule whose next run is at or before now, then
+// advances the schedule past now. Occurrences missed while nothing ran are not back-filled: a schedule
+// that is several periods overdue produces a single note, dated for the occurrence that was due.
+func runDueSchedules(ctx context.Context, vlt *vault.Vault, now time.Time, dryRun bool) ([]scheduleRunItem, error) {
+	schedules, err := database.ListSchedules(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list schedules: %w", err)
+	}
+
+	var results []scheduleRunItem
+	for _, s := range schedules {
+		if s.NextRunAt.After(now) {
+			continue
+		}
+		rule, err := parseScheduleRule(s.Rule)
+		if err != nil {
+			return results, fmt.Errorf("schedule %q: %w", s.Name, err)
+		}
+
+		due := s.NextRunAt.In(now.Location())
+		title := interpolateTemplateAt(s.Title, s.Name, due)
+		next := rule.next(now)
+		item := scheduleRunItem{Schedule: s.Name, Title: title, NextRunAt: next.Format(time.RFC3339)}
+
+		if !dryRun {
+			note, err := runSchedule(ctx, s, title, due, now, next)
+			if err != nil {
+				return results, fmt.Errorf("schedule %q: %w", s.Name, err)
+			}
+			notesync.WriteThrough(ctx, database, vlt, note)
+			item.NoteID = note.ID
+		}
+		results = append(results, item)
+	}
+	return results, nil
+}
+
+// runSchedule creates a schedule's note and advances the schedule to next in one transaction, so
+// a failure part way leaves neither a stray note nor a schedule that would create it again.
+func runSchedule(ctx context.Context, s db.Schedule, title string, due, now, next time.Time) (db.Note, error) {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return db.Note{}, err
+	}
+	defer func() { _ = tx.Rollback() }()
+	q := database.WithTx(tx)
+
+	note, err := createScheduledNote(ctx, q, s, title, due)
+	if err != nil {
+		return db.Note{}, err
+	}
+	err = q.UpdateScheduleRun(ctx, db.UpdateScheduleRunParams{
+		LastRunAt:  sql.NullTime{Time: now, Valid: true},
+		NextRunAt:  next,
+		LastNoteID: sql.NullInt64{Int64: note.ID, Valid: true},
+		ID:         s.ID,
+	})
+	if err != nil {
+		return db.Note{}, fmt.Errorf("failed to advance schedule: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return db.Note{}, err
+	}
+	return note, nil
+}
+
+func createScheduledNote(ctx context.Context, q *db.Queries, s db.Schedule, title string, due time.Time) (db.Note, error) {
+	var content string
+	if s.TemplateName.Valid {
+		tmpl, err := q.GetTemplateByName(ctx, s.TemplateName.String)
+		if err != nil {
+			return db.Note{}, fmt.Errorf("template %q not found: %w", s.TemplateName.String, err)
+		}
+		content, _, _ = cutCursor(interpolateTemplateAt(tmpl.Content, title, due))
+	}
+
+	note, err := q.CreateNoteWithTTL(ctx, db.CreateNoteWithTTLParams{
+		Title:     title,
+		Content:   content,
+		Source:    sql.NullString{String: scheduleSource, Valid: true},
+		SourceRef: sql.NullString{String: s.Name, Valid: true},
+	})
+	if err != nil {
+		return db.Note{}, fmt.Errorf("failed to create note: %w", err)
+	}
+
+	if s.FolderID.Valid {
+		err = q.MoveNoteToFolder(ctx, db.MoveNoteToFolderParams{
+			FolderID: s.FolderID,
+			ID:       note.ID,
+		})
+		if err != nil {
+			return db.Note{}, fmt.Errorf("failed to assign folder: %w", err)
+		}
+	}
+
+	for _, tagName := range splitScheduleTags(s.Tags) {
+		tag, err := q.CreateTag(ctx, tagName)
+		if err != nil {
+			return db.Note{}, err
+		}
+		if err := q.AddTagToNote(ctx, db.AddTagToNoteParams{NoteID: note.ID, TagID: tag.ID}); err != nil {
+			return db.Note{}, err
+		}
+	}
+
+	return q.GetNote(ctx, note.ID)
+}
+
+var scheduleCmd = &cobra.Command{
+	Use:   "schedule",
+	Short: "Manage recurring notes",
+	Long: `Create notes on a recurring schedule, each with a template, tags, and folder.
+
+Rules: daily, weekdays, weekly:<day>, monthly:<day-of-month>, each with an
+optional @HH:MM time (default 09:00).
+
+Due notes are created by "noted schedule run", which is safe to call from cron:
+  */15 * * * * noted schedule run
+
+Examples:
+  noted schedule add retro --every weekly:mon@09:00 --template retro --tags retro,team
+  noted schedule add standup --every weekdays --title "Standup {{date}}"
+  noted schedule list
+  noted schedule run --dry-run`,
+}
+
+var scheduleAddCmd = &cobra.Command{
+	Use:   "add <name>",
+	Short: "Add a recurring note schedule",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name := args[0]
+		every, _ := cmd.Flags().GetString("every")
+		title, _ := cmd.Flags().GetString("title")
+		templateName, _ := cmd.Flags().GetString("template")
+		tags, _ := cmd.Flags().GetString("tags")
+		folderID, _ := cmd.Flags().GetInt64("folder")
+		asJSON, _ := cmd.Flags().GetBool("json")
+
+		rule, err := parseScheduleRule(every)
+		if err != nil {
+			return err
+		}
+		if title == "" {
+			title = name + " {{date}}"
+		}
+
+		ctx := context.Background()
+		params := db.CreateScheduleParams{
+			Name:      name,
+			Rule:      every,
+			Title:     title,
+			Tags:      strings.Join(splitScheduleTags(tags), ","),
+			NextRunAt: rule.next(time.Now()),
+		}
+		if templateName != "" {
+			if _, err := database.GetTemplateByName(ctx, templateName); err != nil {
+				if err == sql.ErrNoRows {
+					return fmt.Errorf("template %q not found", templateName)
+				}
+				return err
+			}
+			params.TemplateName = sql.NullString{String: templateName, Valid: true}
+		}
+		if cmd.Flags().Changed("folder") {
+			params.FolderID = sql.NullInt64{Int64: folderID, Valid: true}
+		}
+
+		s, err := database.CreateSchedule(ctx, params)
+		if err != nil {
+			return fmt.Errorf("failed to create schedule: %w", err)
+		}
+
+		if asJSON {
+			return outputJSON(formatScheduleItem(s))
+		}
+
+		fmt.Printf("Created schedule %q (%s), next note %s\n", s.Name, s.Rule, s.NextRunAt.Format("2006-01-02 15:04"))
+		return nil
+	},
+}
+
+var scheduleListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List recurring note schedules",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		asJSON, _ := cmd.Flags().GetBool("json")
+
+		ctx := context.Background()
+		schedules, err := database.ListSchedules(ctx)
+		if err != nil {
+			return err
+		}
+
+		if asJSON {
+			items := make([]scheduleItem, len(schedules))
+			for i, s := range schedules {
+				items[i] = formatScheduleItem(s)
+			}
+			return outputJSON(items)
+		}
+
+		if len(schedules) == 0 {
+			fmt.Println("No schedules found.")
+			return nil
+		}
+
+		for _, s := range schedules {
+			fmt.Printf("%-20s %-20s next %s\n", s.Name, s.Rule, s.NextRunAt.Format("2006-01-02 15:04"))
+		}
+		return nil
+	},
+}
+
+var scheduleDeleteCmd = &cobra.Command{
+	Use:   "delete <name>",
+	Short: "Delete a recurring note schedule",
+	Long:  "Delete a schedule. Notes it already created are kept.",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		asJSON, _ := cmd.Flags().GetBool("json")
+
+		ctx := context.Background()
+		n, err := database.DeleteScheduleByName(ctx, args[0])
+		if err != nil {
+			return fmt.Errorf("failed to delete schedule: %w", err)
+		}
+		if n == 0 {
+			return fmt.Errorf("schedule %q not found", args[0])
+		}
+
+		if asJSON {
+			return outputJSON(map[string]any{"deleted_name": args[0]})
+		}
+
+		fmt.Printf("Deleted schedule %q\n", args[0])
+		return nil
+	},
+}
+
+var scheduleRunCmd = &cobra.Command{
+	Use:   "run",
+	Short: "Create notes for schedules that are due",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		asJSON, _ := cmd.Flags().GetBool("json")
+
+		ctx := context.Background()
+		results, err := runDueSchedules(ctx, openVault(cmd), time.Now(), dryRun)
+		if err != nil {
+			return err
+		}
+
+		if asJSON {
+			if results == nil {
+				results = []scheduleRunItem{}
+			}
+			return outputJSON(results)
+		}
+
+		if len(results) == 0 {
+			fmt.Println("No schedules due.")
+			return nil
+		}
+
+		for _, r := range results {
+			if dryRun {
+				fmt.Printf("Would create %q from schedule %q\n", r.Title, r.Schedule)
+			} else {
+				fmt.Printf("Created note #%d: %s (schedule %q)\n", r.NoteID, r.Title, r.Schedule)
+			}
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(scheduleCmd)
+	scheduleCmd.AddCommand(scheduleAddCmd)
+	scheduleCmd.AddCommand(scheduleListCmd)
+	scheduleCmd.AddCommand(scheduleDeleteCmd)
+	scheduleCmd.AddCommand(scheduleRunCmd)
+
+	scheduleAddCmd.Flags().String("every", "", "Recurrence rule (e.g., 'daily', 'weekly:mon@09:00', 'monthly:1')")
+	scheduleAddCmd.Flags().StringP("title", "t", "", "Title pattern for created notes (default: '<name> {{date}}')")
+	scheduleAddCmd.Flags().String("template", "", "Template applied to created notes")
+	scheduleAddCmd.Flags().StringP("tags", "T", "", "Comma-separated tags for created notes")
+	scheduleAddCmd.Flags().Int64("folder", 0, "Folder ID for created notes")
+	scheduleAddCmd.Flags().BoolP("json", "j", false, "Output as JSON")
+	_ = scheduleAddCmd.MarkFlagRequired("every")
+
+	scheduleListCmd.Flags().BoolP("json", "j", false, "Output as JSON")
+	scheduleDeleteCmd.Flags().BoolP("json", "j", false, "Output as JSON")
+	scheduleRunCmd.Flags().Bool("dry-run", false, "Show what would be created without creating notes")
+	scheduleRunCmd.Flags().BoolP("json", "j", false, "Output as JSON")
+}
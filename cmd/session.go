@@ -0,0 +1,130 @@
+/*
+Copyright © 2026 abdul hamid <abdulachik@icloud.com>
+*/
+package cmd
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/abdul-hamid-achik/noted/internal/config"
+	"github.com/abdul-hamid-achik/noted/internal/memory"
+	"github.com/abdul-hamid-achik/noted/internal/veclite"
+	"github.com/spf13/cobra"
+)
+
+type sessionLogResult struct {
+	ID           int64   `json:"id"`
+	Title        string  `json:"title"`
+	Category     string  `json:"category"`
+	StartedAt    string  `json:"started_at,omitempty"`
+	EndedAt      string  `json:"ended_at"`
+	NotesTouched []int64 `json:"notes_touched"`
+}
+
+var sessionCmd = &cobra.Command{
+	Use:   "session",
+	Short: "Keep a work log of sessions",
+}
+
+var sessionLogCmd = &cobra.Command{
+	Use:   "log <summary>",
+	Short: "Log a summary of a work session",
+	Long: `Store a summary of what was done in a work session as a memory with source
+"session", so past sessions can be recalled like any other memory. The log
+records when the session ended and links the notes worked on.
+
+--since gives the session's start (a date, an RFC 3339 time, or a duration
+ago like "2h"); every note updated since then is linked. --notes links more
+notes by ID. Agents log sessions with the noted_session_end MCP tool, which
+tracks the notes they touched by itself.
+
+Examples:
+  noted session log "Rewrote the importer; decided to drop the CSV format" --since 3h
+  noted session log "Chose SQLite over Postgres" --category decision --notes 12,15
+  noted recall "importer" --source session`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		title, _ := cmd.Flags().GetString("title")
+		category, _ := cmd.Flags().GetString("category")
+		importance, _ := cmd.Flags().GetInt("importance")
+		sinceStr, _ := cmd.Flags().GetString("since")
+		noteIDs, _ := cmd.Flags().GetInt64Slice("notes")
+		asJSON, _ := cmd.Flags().GetBool("json")
+
+		ctx := context.Background()
+		now := time.Now()
+		started, err := memory.ParseTimeBound(sinceStr, now, false)
+		if err != nil {
+			return fmt.Errorf("invalid --since: %w", err)
+		}
+		if !started.IsZero() {
+			updated, err := database.GetNotesUpdatedSince(ctx, sql.NullTime{Time: started.UTC(), Valid: true})
+			if err != nil {
+				return fmt.Errorf("failed to get notes updated since %s: %w", sinceStr, err)
+			}
+			for _, n := range updated {
+				noteIDs = append(noteIDs, n.ID)
+			}
+		}
+
+		var syncer *veclite.Syncer
+		if cfg, err := config.Load(); err == nil && cfg.VeclitePath != "" {
+			syncer, _ = openSyncer(cfg, false)
+			if syncer != nil {
+				defer func() { _ = syncer.Close() }()
+			}
+		}
+
+		mem, err := memory.LogSession(ctx, database, syncer, memory.SessionInput{
+			Summary:    args[0],
+			Title:      title,
+			Category:   category,
+			Importance: importance,
+			Started:    started,
+			Ended:      now,
+			NoteIDs:    noteIDs,
+		})
+		if err != nil {
+			return err
+		}
+
+		if asJSON {
+			res := sessionLogResult{
+				ID:           mem.ID,
+				Title:        mem.Title,
+				Category:     mem.Category,
+				EndedAt:      now.Format(time.RFC3339),
+				NotesTouched: noteIDs,
+			}
+			if !started.IsZero() {
+				res.StartedAt = started.Format(time.RFC3339)
+			}
+			if res.NotesTouched == nil {
+				res.NotesTouched = []int64{}
+			}
+			return outputJSON(res)
+		}
+
+		fmt.Printf("Logged session #%d: %s\n", mem.ID, mem.Title)
+		fmt.Printf("  Category: %s\n", mem.Category)
+		if len(noteIDs) > 0 {
+			fmt.Printf("  Notes:    %d linked\n", len(noteIDs))
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(sessionCmd)
+	sessionCmd.AddCommand(sessionLogCmd)
+
+	sessionLogCmd.Flags().String("title", "", "Title for the log entry (default \"Session <date time>\")")
+	sessionLogCmd.Flags().StringP("category", "c", "project", "Memory category: project or decision")
+	sessionLogCmd.Flags().IntP("importance", "i", 3, "Importance level 1-5")
+	sessionLogCmd.Flags().String("since", "", "When the session started; notes updated since then are linked")
+	sessionLogCmd.Flags().Int64Slice("notes", nil, "IDs of more notes to link (comma-separated)")
+	sessionLogCmd.Flags().BoolP("json", "j", false, "Output as JSON")
+}
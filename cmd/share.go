@@ -0,0 +1,274 @@
+/*
+Copyright © 2026 abdul hamid <abdulachik@icloud.com>
+*/
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"fmt"
+	"html/template"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/abdul-hamid-achik/noted/internal/config"
+	"github.com/abdul-hamid-achik/noted/internal/db"
+	"github.com/abdul-hamid-achik/noted/internal/markdown"
+	"github.com/spf13/cobra"
+)
+
+type shareResult struct {
+	ID     int64    `json:"id"`
+	To     []string `json:"to,omitempty"`
+	Sent   bool     `json:"sent"`
+	Mailto string   `json:"mailto,omitempty"`
+}
+
+// notePage lays out a rendered note as a standalone HTML document that reads well on screen,
+// in a mail client, and on paper.
+var notePage = template.Must(template.New("note").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Title}}</title>
+<style>
+body { font: 16px/1.6 -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; color: #1f2328; max-width: 44rem; margin: 2rem auto; padding: 0 1rem; }
+pre, code { font-family: ui-monospace, Menlo, Consolas, monospace; font-size: 0.9em; background: #f6f8fa; border-radius: 4px; }
+pre { padding: 0.75rem; overflow-x: auto; }
+code { padding: 0.1em 0.3em; }
+pre code { padding: 0; }
+blockquote { margin: 0; padding-left: 1rem; border-left: 3px solid #d0d7de; color: #59636e; }
+table { border-collapse: collapse; }
+th, td { border: 1px solid #d0d7de; padding: 0.3rem 0.6rem; }
+img { max-width: 100%; }
+.meta { color: #59636e; font-size: 0.9em; }
+@media print {
+  body { margin: 0; max-width: none; font-size: 12pt; }
+  a { color: inherit; }
+  pre, blockquote, table, img { break-inside: avoid; }
+}
+</style>
+</head>
+<body>
+<article>
+<h1>{{.Title}}</h1>
+{{- if .Meta}}
+<p class="meta">{{.Meta}}</p>
+{{- end}}
+{{.Body}}
+</article>
+</body>
+</html>
+`))
+
+// renderNotePage returns a note as a standalone HTML document, its content rendered by
+// markdown.HTML with href resolving [[wikilinks]]. meta is a line shown under the title, if any.
+func renderNotePage(title, content, meta string, href func(l markdown.Link) (string, bool)) (string, error) {
+	body, err := markdown.HTML(content, href)
+	if err != nil {
+		return "", fmt.Errorf("failed to render note: %w", err)
+	}
+	var b strings.Builder
+	// markdown.HTML leaves out raw HTML, so its output is trusted as is
+	err = notePage.Execute(&b, struct {
+		Title, Meta string
+		Body        template.HTML
+	}{title, meta, template.HTML(body)})
+	return b.String(), err
+}
+
+// noLinks resolves no wikilinks, so they render as their text: for HTML read outside noted.
+func noLinks(markdown.Link) (string, bool) { return "", false }
+
+// shareMessage builds an email with a note as its text and HTML alternatives.
+func shareMessage(from string, to []string, subject, text, html string, date time.Time) ([]byte, error) {
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+	fmt.Fprintf(&buf, "From: %s\r\n", from)
+	fmt.Fprintf(&buf, "To: %s\r\n", strings.Join(to, ", "))
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	fmt.Fprintf(&buf, "Date: %s\r\n", date.Format(time.RFC1123Z))
+	fmt.Fprintf(&buf, "MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&buf, "Content-Type: multipart/alternative; boundary=%s\r\n\r\n", mw.Boundary())
+
+	for _, part := range []struct{ contentType, body string }{
+		{"text/plain; charset=utf-8", text},
+		{"text/html; charset=utf-8", html},
+	} {
+		w, err := mw.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		qw := quotedprintable.NewWriter(w)
+		if _, err := qw.Write([]byte(part.body)); err != nil {
+			return nil, err
+		}
+		if err := qw.Close(); err != nil {
+			return nil, err
+		}
+	}
+	if err := mw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// sendMail sends msg through the configured SMTP server, which must offer STARTTLS to
+// authenticate anywhere but localhost.
+func sendMail(cfg config.SMTP, to []string, msg []byte) error {
+	if cfg.Host == "" {
+		return fmt.Errorf("no SMTP server configured; set NOTED_SMTP_HOST (or use --mailto)")
+	}
+	from, err := mail.ParseAddress(cfg.From)
+	if err != nil {
+		return fmt.Errorf("invalid sender %q; set NOTED_SMTP_FROM: %w", cfg.From, err)
+	}
+	rcpt := make([]string, len(to))
+	for i, a := range to {
+		if rcpt[i], err = bareAddress(a); err != nil {
+			return err
+		}
+	}
+	var auth smtp.Auth
+	if cfg.Username != "" {
+		auth = smtp.PlainAuth("", cfg.Username, cfg.Password, cfg.Host)
+	}
+	addr := cfg.Host + ":" + strconv.Itoa(cfg.Port)
+	if err := smtp.SendMail(addr, auth, from.Address, rcpt, msg); err != nil {
+		return fmt.Errorf("failed to send mail: %w", err)
+	}
+	return nil
+}
+
+// bareAddress returns the address in "Ana <ana@example.com>" or "ana@example.com".
+func bareAddress(s string) (string, error) {
+	a, err := mail.ParseAddress(s)
+	if err != nil {
+		return "", fmt.Errorf("invalid address %q: %w", s, err)
+	}
+	return a.Address, nil
+}
+
+// mailtoURL returns a mailto: link opening a new mail to the bare addresses with subject and body
+// filled in (RFC 6068).
+func mailtoURL(to []string, subject, body string) string {
+	escape := func(s string) string { return strings.ReplaceAll(url.QueryEscape(s), "+", "%20") }
+	addrs := make([]string, len(to))
+	for i, a := range to {
+		addrs[i] = escape(a)
+	}
+	// Mail clients expect CRLF line breaks in a mailto body
+	body = strings.ReplaceAll(strings.ReplaceAll(body, "\r\n", "\n"), "\n", "\r\n")
+	return "mailto:" + strings.Join(addrs, ",") + "?subject=" + escape(subject) + "&body=" + escape(body)
+}
+
+var shareCmd = &cobra.Command{
+	Use:   "share <id>",
+	Short: "Send a note by email",
+	Long: `Send a note to someone by email, as both formatted HTML and plain text.
+![[Embedded]] notes are expanded and [[wikilinks]] become their text, since the
+reader can't follow them.
+
+--email sends the mail through the SMTP server set by NOTED_SMTP_HOST,
+NOTED_SMTP_PORT (default 587), NOTED_SMTP_USERNAME, NOTED_SMTP_PASSWORD and
+NOTED_SMTP_FROM. Repeat it, or separate addresses with commas, for several
+recipients.
+
+--mailto sends nothing; it prints a mailto: link with the note as its subject
+and plain-text body, for your own mail client to open. Addresses from --email
+fill in its recipients.
+
+Examples:
+  noted share 42 --email ana@example.com
+  noted share 42 --mailto
+  xdg-open "$(noted share 42 --mailto --email ana@example.com)"`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		to, _ := cmd.Flags().GetStringSlice("email")
+		mailto, _ := cmd.Flags().GetBool("mailto")
+		asJSON, _ := cmd.Flags().GetBool("json")
+
+		id, err := strconv.ParseInt(args[0], 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid note ID: %s", args[0])
+		}
+		if len(to) == 0 && !mailto {
+			return fmt.Errorf("give --email addresses to send to, or --mailto")
+		}
+		addrs := make([]string, len(to))
+		for i, a := range to {
+			if addrs[i], err = bareAddress(a); err != nil {
+				return err
+			}
+		}
+
+		ctx := context.Background()
+		note, err := database.GetNote(ctx, id)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return fmt.Errorf("note #%d not found", id)
+			}
+			return fmt.Errorf("failed to get note: %w", err)
+		}
+		note.Content = expandEmbeds(ctx, note)
+		text := markdown.PlainText(note.Content)
+		res := shareResult{ID: id, To: to}
+
+		if mailto {
+			res.Mailto = mailtoURL(addrs, note.Title, text)
+			if asJSON {
+				return outputJSON(res)
+			}
+			fmt.Println(res.Mailto)
+			return nil
+		}
+
+		if err := sendShare(note, to, text); err != nil {
+			return err
+		}
+		res.Sent = true
+		if asJSON {
+			return outputJSON(res)
+		}
+		fmt.Printf("Sent #%d %q to %s\n", note.ID, note.Title, strings.Join(to, ", "))
+		return nil
+	},
+}
+
+// sendShare emails a note, its embeds already expanded, text being its plain-text rendering.
+func sendShare(note db.Note, to []string, text string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	html, err := renderNotePage(note.Title, note.Content, "", noLinks)
+	if err != nil {
+		return err
+	}
+	msg, err := shareMessage(cfg.SMTP.From, to, note.Title, note.Title+"\n\n"+text, html, time.Now())
+	if err != nil {
+		return err
+	}
+	return sendMail(cfg.SMTP, to, msg)
+}
+
+func init() {
+	rootCmd.AddCommand(shareCmd)
+
+	shareCmd.Flags().StringSlice("email", nil, "Send the note to these addresses")
+	shareCmd.Flags().Bool("mailto", false, "Print a mailto: link instead of sending")
+	shareCmd.Flags().BoolP("json", "j", false, "Output as JSON")
+}
@@ -0,0 +1,271 @@
+/*
+Copyright © 2026 abdul hamid <abdulachik@icloud.com>
+*/
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/abdul-hamid-achik/noted/internal/db"
+	"github.com/abdul-hamid-achik/noted/internal/notesync"
+	"github.com/spf13/cobra"
+)
+
+// shellSource is the note source for `noted exec` captures; source_ref holds the working directory.
+const shellSource = "shell"
+
+// maxExecOutput caps how much command output `noted exec` stores.
+const maxExecOutput = 32 * 1024
+
+var shellInitScripts = map[string]string{
+	"bash": `# noted shell integration for bash. Add to ~/.bashrc:
+#   eval "$(noted shell-init bash)"
+
+# Ctrl-N: capture the current command line into the noted inbox
+__noted_capture_line() {
+  [ -n "$READLINE_LINE" ] || return
+  noted quick -T shell -- "$READLINE_LINE" >/dev/null && printf 'noted: captured command line\n' >&2
+}
+bind -x '"\C-n": __noted_capture_line'
+
+# nx: run a command and store it with its output, e.g. nx make test
+nx() { noted exec -- "$@"; }
+`,
+	"zsh": `# noted shell integration for zsh. Add to ~/.zshrc:
+#   eval "$(noted shell-init zsh)"
+
+# Ctrl-N: capture the current command line into the noted inbox
+__noted_capture_line() {
+  [[ -n $BUFFER ]] || return
+  noted quick -T shell -- "$BUFFER" >/dev/null && zle -M "noted: captured command line"
+}
+zle -N __noted_capture_line
+bindkey '^N' __noted_capture_line
+
+# nx: run a command and store it with its output, e.g. nx make test
+nx() { noted exec -- "$@" }
+`,
+	"fish": `# noted shell integration for fish. Add to ~/.config/fish/config.fish:
+#   noted shell-init fish | source
+
+# Ctrl-N: capture the current command line into the noted inbox
+function __noted_capture_line
+    set -l line (commandline)
+    test -n "$line"; or return
+    noted quick -T shell -- "$line" >/dev/null; and echo "noted: captured command line" >&2
+    commandline -f repaint
+end
+bind \cn __noted_capture_line
+
+# nx: run a command and store it with its output, e.g. nx make test
+function nx
+    noted exec -- $argv
+end
+`,
+}
+
+var shellInitCmd = &cobra.Command{
+	Use:       "shell-init bash|zsh|fish",
+	Short:     "Print shell functions and key bindings for quick capture",
+	ValidArgs: []string{"bash", "zsh", "fish"},
+	Long: `Print shell integration to evaluate from your shell's startup file:
+
+  Ctrl-N   capture the command line being edited into the inbox (tag "shell")
+  nx CMD   run CMD and store it with its output (same as "noted exec -- CMD")
+
+Setup:
+  bash:  eval "$(noted shell-init bash)"      # in ~/.bashrc
+  zsh:   eval "$(noted shell-init zsh)"       # in ~/.zshrc
+  fish:  noted shell-init fish | source       # in ~/.config/fish/config.fish`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		script, ok := shellInitScripts[args[0]]
+		if !ok {
+			return fmt.Errorf("unsupported shell %q (supported: bash, zsh, fish)", args[0])
+		}
+		fmt.Print(script)
+		return nil
+	},
+}
+
+// exitCodeError makes noted exit with a wrapped command's exit status.
+type exitCodeError struct{ code int }
+
+func (e *exitCodeError) Error() string { return fmt.Sprintf("exit status %d", e.code) }
+
+var execCmd = &cobra.Command{
+	Use:   "exec -- <command> [args...]",
+	Short: "Run a command and store it with its output as a note",
+	Long: `Run a command, showing its output as usual, then store the command line,
+its output, and its exit status as a note (source "shell", source ref = the
+working directory, tag "shell"). noted exits with the command's exit status.
+
+Output is captured up to 32 KiB. Because output is piped, programs that
+detect a terminal may disable colors or progress bars.
+
+Examples:
+  noted exec -- make test
+  noted exec -t "Failing migration" -T bug -- go run ./cmd/migrate`,
+	Args:          cobra.MinimumNArgs(1),
+	SilenceErrors: true, // errors are printed by RunE, except the command's own exit status
+	SilenceUsage:  true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		err := runExec(cmd, args)
+		var exitErr *exitCodeError
+		if err != nil && !errors.As(err, &exitErr) {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		}
+		return err
+	},
+}
+
+// cappedBuffer keeps the first max bytes written to it and counts the rest. It is safe for the
+// concurrent stdout and stderr copies.
+type cappedBuffer struct {
+	mu      sync.Mutex
+	buf     bytes.Buffer
+	max     int
+	dropped int
+}
+
+func (c *cappedBuffer) Write(p []byte) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if room := c.max - c.buf.Len(); room > 0 {
+		c.buf.Write(p[:min(room, len(p))])
+		c.dropped += max(len(p)-room, 0)
+	} else {
+		c.dropped += len(p)
+	}
+	return len(p), nil
+}
+
+func runExec(cmd *cobra.Command, args []string) error {
+	title, _ := cmd.Flags().GetString("title")
+	tags, _ := cmd.Flags().GetString("tags")
+	asJSON, _ := cmd.Flags().GetBool("json")
+
+	dir, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+
+	output := &cappedBuffer{max: maxExecOutput}
+	run := exec.Command(args[0], args[1:]...)
+	run.Stdin = os.Stdin
+	run.Stdout = io.MultiWriter(os.Stdout, output)
+	run.Stderr = io.MultiWriter(os.Stderr, output)
+
+	start := time.Now()
+	runErr := run.Run()
+	elapsed := time.Since(start)
+
+	code := 0
+	var exitErr *exec.ExitError
+	switch {
+	case errors.As(runErr, &exitErr):
+		code = exitErr.ExitCode()
+	case runErr != nil:
+		return fmt.Errorf("failed to run %s: %w", args[0], runErr) // not started; nothing to store
+	}
+
+	commandLine := shellJoin(args)
+	if title == "" {
+		title = quickTitle("$ " + commandLine)
+	}
+
+	ctx := context.Background()
+	note, err := database.CreateNoteWithTTL(ctx, db.CreateNoteWithTTLParams{
+		Title:     title,
+		Content:   execNoteContent(commandLine, output.buf.String(), output.dropped, code, elapsed, dir),
+		Source:    sql.NullString{String: shellSource, Valid: true},
+		SourceRef: sql.NullString{String: dir, Valid: true},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to store command: %w", err)
+	}
+	for _, tagName := range append([]string{shellSource}, strings.Split(tags, ",")...) {
+		tagName = strings.TrimSpace(tagName)
+		if tagName == "" {
+			continue
+		}
+		tag, err := database.CreateTag(ctx, tagName)
+		if err != nil {
+			return err
+		}
+		if err := database.AddTagToNote(ctx, db.AddTagToNoteParams{NoteID: note.ID, TagID: tag.ID}); err != nil {
+			return err
+		}
+	}
+	if note, err = applyProject(ctx, captureProject("."), note, false); err != nil {
+		return err
+	}
+	notesync.WriteThrough(ctx, database, openVault(cmd), note)
+
+	if asJSON {
+		if err := outputJSON(map[string]any{
+			"id":        note.ID,
+			"title":     note.Title,
+			"exit_code": code,
+		}); err != nil {
+			return err
+		}
+	} else {
+		fmt.Fprintf(os.Stderr, "noted: stored as note #%d (exit status %d)\n", note.ID, code)
+	}
+
+	if code != 0 {
+		return &exitCodeError{code: code}
+	}
+	return nil
+}
+
+// execNoteContent renders a captured command as markdown: a console block with the command line
+// and its output, then the exit status, duration, and directory.
+func execNoteContent(commandLine, output string, dropped, code int, elapsed time.Duration, dir string) string {
+	var b strings.Builder
+	b.WriteString("```console\n$ " + commandLine + "\n")
+	if output != "" {
+		b.WriteString(strings.TrimRight(output, "\n") + "\n")
+	}
+	if dropped > 0 {
+		fmt.Fprintf(&b, "[... %d more bytes of output not stored]\n", dropped)
+	}
+	b.WriteString("```\n\n")
+	fmt.Fprintf(&b, "Exit status: %d · %s · %s\n", code, elapsed.Round(time.Millisecond), dir)
+	return b.String()
+}
+
+// shellJoin joins arguments into a command line, single-quoting the ones a shell would split or
+// expand.
+func shellJoin(args []string) string {
+	quoted := make([]string, len(args))
+	for i, a := range args {
+		if a != "" && !strings.ContainsAny(a, " \t\n'\"\\$`*?[]{}()<>|&;#~!") {
+			quoted[i] = a
+			continue
+		}
+		quoted[i] = "'" + strings.ReplaceAll(a, "'", `'\''`) + "'"
+	}
+	return strings.Join(quoted, " ")
+}
+
+func init() {
+	rootCmd.AddCommand(shellInitCmd)
+	rootCmd.AddCommand(execCmd)
+
+	execCmd.Flags().StringP("title", "t", "", "Note title (default: the command line)")
+	execCmd.Flags().StringP("tags", "T", "", "Extra comma-separated tags (shell is always added)")
+	execCmd.Flags().BoolP("json", "j", false, "Output the stored note as JSON")
+	execCmd.Flags().SetInterspersed(false) // flags after the command name belong to the command
+}
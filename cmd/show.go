@@ -7,9 +7,15 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
 
+	"github.com/abdul-hamid-achik/noted/internal/db"
+	"github.com/abdul-hamid-achik/noted/internal/markdown"
+	"github.com/abdul-hamid-achik/noted/internal/vault"
 	"github.com/spf13/cobra"
 )
 
@@ -18,17 +24,42 @@ type noteDetail struct {
 	Title     string   `json:"title"`
 	Content   string   `json:"content"`
 	Tags      []string `json:"tags"`
+	Aliases   []string `json:"aliases,omitempty"`
 	CreatedAt string   `json:"created_at"`
 	UpdatedAt string   `json:"updated_at"`
+	CreatedBy string   `json:"created_by,omitempty"`
 }
 
 var showCmd = &cobra.Command{
 	Use:   "show <id>",
 	Short: "Display a note",
-	Args:  cobra.ExactArgs(1),
+	Long: `Display a note with its metadata.
+
+--expand inlines embeds: ![[Note]] is replaced by that note's content and
+![[Note#Section]] by the section under that heading. Embeds inside embedded
+notes are expanded too; unresolved embeds are left as written.
+
+--section shows only the part of the note under a heading (case-insensitive),
+down to the next heading of the same or higher level. See noted outline.
+
+--html prints the note as a standalone, print-friendly HTML page, ready for a
+browser, a PDF printer, or a share view, with its embeds expanded as --expand
+does. [[Wikilinks]] become links to
+<slug>.html, with [[Note#Section]] linking to the heading; links to missing
+notes become plain text. Raw HTML in the note is left out.
+
+Examples:
+  noted show 42
+  noted show 42 --raw --expand
+  noted show 42 --section Design
+  noted show 42 --html > note.html`,
+	Args: cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		raw, _ := cmd.Flags().GetBool("raw")
 		asJSON, _ := cmd.Flags().GetBool("json")
+		expand, _ := cmd.Flags().GetBool("expand")
+		section, _ := cmd.Flags().GetString("section")
+		asHTML, _ := cmd.Flags().GetBool("html")
 
 		id, err := strconv.ParseInt(args[0], 10, 64)
 		if err != nil {
@@ -43,11 +74,29 @@ var showCmd = &cobra.Command{
 			}
 			return fmt.Errorf("failed to get note: %w", err)
 		}
+		if section != "" {
+			content, ok := markdown.Section(note.Content, section)
+			if !ok {
+				return fmt.Errorf("note #%d has no section %q", id, section)
+			}
+			note.Content = content
+		}
+		if expand && !asHTML { // noteHTML expands embeds itself
+			note.Content = expandEmbeds(ctx, note)
+		}
 
 		if raw {
 			fmt.Print(note.Content)
 			return nil
 		}
+		if asHTML {
+			page, err := noteHTML(ctx, note, vaultDir(cmd))
+			if err != nil {
+				return err
+			}
+			fmt.Print(page)
+			return nil
+		}
 
 		tags, err := database.GetTagsForNote(ctx, id)
 		if err != nil {
@@ -59,14 +108,21 @@ var showCmd = &cobra.Command{
 			tagNames[i] = t.Name
 		}
 
+		aliases, err := database.GetNoteAliases(ctx, id)
+		if err != nil {
+			return err
+		}
+
 		if asJSON {
 			detail := noteDetail{
 				ID:        note.ID,
 				Title:     note.Title,
 				Content:   note.Content,
 				Tags:      tagNames,
+				Aliases:   aliases,
 				CreatedAt: note.CreatedAt.Time.Format("2006-01-02T15:04:05Z07:00"),
 				UpdatedAt: note.UpdatedAt.Time.Format("2006-01-02T15:04:05Z07:00"),
+				CreatedBy: note.CreatedBy.String,
 			}
 			return outputJSON(detail)
 		}
@@ -75,10 +131,16 @@ var showCmd = &cobra.Command{
 		fmt.Printf("ID: %d\n", note.ID)
 		fmt.Printf("Created: %s\n", note.CreatedAt.Time.Format("2006-01-02 15:04"))
 		fmt.Printf("Updated: %s\n", note.UpdatedAt.Time.Format("2006-01-02 15:04"))
+		if note.CreatedBy.Valid {
+			fmt.Printf("Created by: %s\n", note.CreatedBy.String)
+		}
 
 		if len(tags) > 0 {
 			fmt.Printf("Tags: %s\n", strings.Join(tagNames, ", "))
 		}
+		if len(aliases) > 0 {
+			fmt.Printf("Aliases: %s\n", strings.Join(aliases, ", "))
+		}
 
 		fmt.Printf("\n---\n\n%s", note.Content)
 		if len(note.Content) > 0 && note.Content[len(note.Content)-1] != '\n' {
@@ -94,4 +156,59 @@ func init() {
 
 	showCmd.Flags().BoolP("raw", "r", false, "Output raw markdown only")
 	showCmd.Flags().BoolP("json", "j", false, "Output as JSON")
+	showCmd.Flags().BoolP("expand", "e", false, "Inline ![[embedded]] notes and sections")
+	showCmd.Flags().StringP("section", "s", "", "Show only the section under this heading")
+	showCmd.Flags().Bool("html", false, "Output a standalone, print-friendly HTML page")
+}
+
+// expandEmbeds returns a note's content with its ![[Note]] and ![[Note#Section]] embeds replaced
+// by the embedded text. Targets resolve by title or alias, as links do.
+func expandEmbeds(ctx context.Context, note db.Note) string {
+	return markdown.Expand(note.Title, note.Content, func(title string) (string, bool) {
+		target, err := database.ResolveNoteTitle(ctx, title)
+		if err != nil {
+			return "", false
+		}
+		return target.Content, true
+	})
+}
+
+// noteHTML renders a note as a print-friendly HTML page with its ![[Note]] embeds expanded.
+// Wikilinks to notes link to their <slug>.html, and ![[attachment]] embeds to the file in the
+// vault at vaultRoot.
+func noteHTML(ctx context.Context, note db.Note, vaultRoot string) (string, error) {
+	tags, err := database.GetTagsForNote(ctx, note.ID)
+	if err != nil {
+		return "", err
+	}
+	meta := "Updated " + note.UpdatedAt.Time.Format("January 2, 2006")
+	if len(tags) > 0 {
+		names := make([]string, len(tags))
+		for i, t := range tags {
+			names[i] = "#" + t.Name
+		}
+		meta += " · " + strings.Join(names, " ")
+	}
+	return renderNotePage(note.Title, expandEmbeds(ctx, note), meta, func(l markdown.Link) (string, bool) {
+		if markdown.IsAttachment(l.Target) {
+			path := filepath.FromSlash(l.Target)
+			if !filepath.IsAbs(path) {
+				path = filepath.Join(vaultRoot, path)
+			}
+			if _, err := os.Stat(path); err != nil {
+				return "", false
+			}
+			// An absolute path rather than a file: URL, which the renderer drops as unsafe
+			return (&url.URL{Path: filepath.ToSlash(path)}).String(), true
+		}
+		target, err := database.ResolveNoteTitle(ctx, l.Target)
+		if err != nil {
+			return "", false
+		}
+		href := url.PathEscape(vault.Slugify(target.Title)) + ".html"
+		if l.Section != "" {
+			href += "#" + markdown.HeadingID(l.Section)
+		}
+		return href, true
+	})
 }
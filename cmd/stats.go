@@ -5,18 +5,31 @@ package cmd
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
 	"os"
 
 	"github.com/abdul-hamid-achik/noted/internal/config"
+	"github.com/abdul-hamid-achik/noted/internal/db"
+	"github.com/abdul-hamid-achik/noted/internal/quota"
 	"github.com/spf13/cobra"
 )
 
 type statsResult struct {
-	Notes  int64  `json:"notes"`
-	Tags   int64  `json:"tags"`
-	DBSize int64  `json:"db_size_bytes"`
-	DBPath string `json:"db_path"`
+	Notes            int64  `json:"notes"`
+	Tags             int64  `json:"tags"`
+	Unsynced         int64  `json:"unsynced"` // notes waiting for `noted sync` to embed them
+	DBSize           int64  `json:"db_size_bytes"`
+	DBPath           string `json:"db_path"`
+	Attachments      int    `json:"attachments"`
+	AttachmentsSize  int64  `json:"attachments_bytes"`
+	Reclaimable      int    `json:"reclaimable_attachments"`
+	ReclaimableBytes int64  `json:"reclaimable_bytes"`
+	LargestNote      int64  `json:"largest_note_bytes"`
+	LargestNoteID    int64  `json:"largest_note_id,omitempty"`
+
+	Quotas []quota.Usage `json:"quotas"` // only the quotas that are set
 }
 
 var statsCmd = &cobra.Command{
@@ -37,6 +50,11 @@ var statsCmd = &cobra.Command{
 			return err
 		}
 
+		unsynced, err := database.CountUnsynced(ctx)
+		if err != nil {
+			return err
+		}
+
 		cfg, err := config.Load()
 		if err != nil {
 			return err
@@ -47,37 +65,65 @@ var statsCmd = &cobra.Command{
 			dbSize = info.Size()
 		}
 
+		// Attachments live in the vault; unlinked files and duplicate copies could be freed
+		var assets, reclaim []assetFile
+		if dir := vaultDir(cmd); dir != "" {
+			if assets, err = assetUsage(ctx, dir); err != nil {
+				return fmt.Errorf("failed to scan attachments: %w", err)
+			}
+			reclaim = reclaimableAssets(assets)
+		}
+
+		var largest db.GetLargestNoteRow
+		if largest, err = database.GetLargestNote(ctx); err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+
+		result := statsResult{
+			Notes:         noteCount,
+			Tags:          tagCount,
+			Unsynced:      unsynced,
+			DBSize:        dbSize,
+			DBPath:        cfg.DBPath,
+			Attachments:   len(assets),
+			Reclaimable:   len(reclaim),
+			LargestNote:   largest.Size,
+			LargestNoteID: largest.ID,
+		}
+		for _, f := range assets {
+			result.AttachmentsSize += f.Size
+		}
+		for _, f := range reclaim {
+			result.ReclaimableBytes += f.Size
+		}
+		result.Quotas = quotaUsage(cfg.Quotas, result.LargestNote, dbSize, result.AttachmentsSize)
+
 		if asJSON {
-			return outputJSON(statsResult{
-				Notes:  noteCount,
-				Tags:   tagCount,
-				DBSize: dbSize,
-				DBPath: cfg.DBPath,
-			})
+			return outputJSON(result)
 		}
 
 		fmt.Printf("%-12s %d\n", "Notes:", noteCount)
 		fmt.Printf("%-12s %d\n", "Tags:", tagCount)
-		fmt.Printf("%-12s %s\n", "DB size:", formatBytes(dbSize))
+		fmt.Printf("%-12s %d\n", "Unsynced:", unsynced)
+		fmt.Printf("%-12s %s\n", "DB size:", quota.FormatSize(dbSize))
 		fmt.Printf("%-12s %s\n", "DB path:", cfg.DBPath)
+		if result.Attachments > 0 {
+			fmt.Printf("%-12s %d (%s)\n", "Attachments:", result.Attachments, quota.FormatSize(result.AttachmentsSize))
+			fmt.Printf("%-12s %s in %d files\n", "Reclaimable:", quota.FormatSize(result.ReclaimableBytes), result.Reclaimable)
+		}
+		if largest.ID != 0 {
+			fmt.Printf("%-12s %s (#%d %s)\n", "Largest:", quota.FormatSize(largest.Size), largest.ID, largest.Title)
+		}
+		for _, u := range result.Quotas {
+			if msg := u.Message(); msg != "" {
+				fmt.Printf("Warning: %s\n", msg)
+			}
+		}
 
 		return nil
 	},
 }
 
-func formatBytes(b int64) string {
-	const unit = 1024
-	if b < unit {
-		return fmt.Sprintf("%d B", b)
-	}
-	div, exp := int64(unit), 0
-	for n := b / unit; n >= unit; n /= unit {
-		div *= unit
-		exp++
-	}
-	return fmt.Sprintf("%.1f %cB", float64(b)/float64(div), "KMGTPE"[exp])
-}
-
 func init() {
 	rootCmd.AddCommand(statsCmd)
 
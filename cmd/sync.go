@@ -5,9 +5,15 @@ package cmd
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
 
 	"github.com/abdul-hamid-achik/noted/internal/config"
+	"github.com/abdul-hamid-achik/noted/internal/db"
+	"github.com/abdul-hamid-achik/noted/internal/embeddings"
+	"github.com/abdul-hamid-achik/noted/internal/memory"
+	"github.com/abdul-hamid-achik/noted/internal/quota"
 	"github.com/abdul-hamid-achik/noted/internal/veclite"
 	"github.com/spf13/cobra"
 )
@@ -21,22 +27,43 @@ This command syncs unembedded notes to the veclite vector database,
 enabling semantic search capabilities. Notes that have already been
 synced will be skipped unless --force is used.
 
+Each note is marked synced as soon as its embedding is stored, so an
+interrupted sync (including a forced one) resumes where it left off the
+next time you run "noted sync".
+
+Memories are embedded into a separate "memories" collection, which can use
+its own model.
+
 Environment variables:
-  NOTED_VECLITE_PATH     Path to veclite database (required)
-  NOTED_EMBEDDING_MODEL  Embedding model name (default: nomic-embed-text)
-  OLLAMA_HOST            Ollama server URL (default: http://localhost:11434)
+  NOTED_VECLITE_PATH            Path to veclite database (required)
+  NOTED_EMBEDDING_MODEL         Embedding model name (default: nomic-embed-text)
+  NOTED_MEMORY_EMBEDDING_MODEL  Embedding model for memories (default: NOTED_EMBEDDING_MODEL)
+  OLLAMA_HOST                   Ollama server URL (default: http://localhost:11434)
 
 Example:
-  noted sync           # Sync only unsynced notes
-  noted sync --force   # Re-sync all notes`,
+  noted sync              # Sync only unsynced notes
+  noted sync --force      # Re-sync all notes
+  noted sync --limit 100  # Sync the next 100 unsynced notes
+  noted sync --ids 4,8,15 # Sync specific notes
+  noted sync --status     # Show index size, model, last sync and pending notes`,
 	RunE: runSync,
 }
 
-var syncForce bool
+var (
+	syncForce  bool
+	syncLimit  int
+	syncIDs    []int64
+	syncStatus bool
+	syncJSON   bool
+)
 
 func init() {
 	rootCmd.AddCommand(syncCmd)
 	syncCmd.Flags().BoolVarP(&syncForce, "force", "f", false, "Re-sync all notes even if already synced")
+	syncCmd.Flags().IntVarP(&syncLimit, "limit", "n", 0, "Sync at most this many notes")
+	syncCmd.Flags().Int64SliceVar(&syncIDs, "ids", nil, "Sync only these note IDs (comma-separated)")
+	syncCmd.Flags().BoolVar(&syncStatus, "status", false, "Show vector index statistics instead of syncing")
+	syncCmd.Flags().BoolVarP(&syncJSON, "json", "j", false, "Output --status as JSON")
 }
 
 func runSync(cmd *cobra.Command, args []string) error {
@@ -56,8 +83,20 @@ func runSync(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("database not initialized")
 	}
 
+	if syncStatus {
+		res, err := readSyncStatus(context.Background(), cfg)
+		if err != nil {
+			return err
+		}
+		if syncJSON {
+			return outputJSON(res)
+		}
+		printSyncStatus(res)
+		return nil
+	}
+
 	// Create syncer
-	syncer, err := veclite.NewSyncer(cfg.VeclitePath, cfg.EmbeddingModel)
+	syncer, err := openSyncer(cfg, false)
 	if err != nil {
 		return fmt.Errorf("failed to initialize veclite: %w", err)
 	}
@@ -65,41 +104,233 @@ func runSync(cmd *cobra.Command, args []string) error {
 
 	ctx := context.Background()
 
-	if syncForce {
-		// Get all notes
-		notes, err := database.GetAllNotes(ctx)
-		if err != nil {
-			return fmt.Errorf("failed to get notes: %w", err)
-		}
+	if err := splitMemories(ctx, syncer); err != nil {
+		return err
+	}
+
+	notes, err := notesToSync(ctx)
+	if err != nil {
+		return err
+	}
+	if len(notes) == 0 {
+		fmt.Println("All notes are already synced.")
+		return nil
+	}
 
-		fmt.Printf("Syncing %d notes...\n", len(notes))
+	fmt.Printf("Syncing %d notes...\n", len(notes))
+	res := syncNotes(ctx, syncer, notes, newProgress(len(notes)))
+	for _, f := range res.failures {
+		fmt.Printf("  Failed to sync note #%d: %v\n", f.id, f.err)
+	}
+	if res.stopped != nil {
+		// Not fatal: unsynced notes are still found by keyword search and the next sync resumes
+		fmt.Printf("Synced %d notes before Ollama became unavailable (%v).\n", res.synced, res.stopped)
+		fmt.Println("Remaining notes stay unsynced; run `noted sync` again once Ollama is back.")
+		return nil
+	}
 
-		synced := 0
-		failed := 0
-		for _, note := range notes {
-			if err := syncer.SyncNote(note.ID, note.Title, note.Content); err != nil {
-				fmt.Printf("  Failed to sync note #%d: %v\n", note.ID, err)
-				failed++
-				continue
+	fmt.Printf("Done! Synced: %d, Failed: %d\n", res.synced, len(res.failures))
+	if pending := len(notes) - res.synced - len(res.failures); pending > 0 {
+		fmt.Printf("%d notes still pending.\n", pending)
+	}
+	return nil
+}
+
+// notesToSync picks the notes a sync run embeds: the --ids notes if given, otherwise every note
+// still marked unsynced, capped by --limit. --force first clears every sync marker, so an
+// interrupted forced sync resumes with a plain `noted sync`.
+func notesToSync(ctx context.Context) ([]db.Note, error) {
+	var notes []db.Note
+	if len(syncIDs) > 0 {
+		for _, id := range syncIDs {
+			note, err := database.GetNote(ctx, id)
+			if err != nil {
+				if errors.Is(err, sql.ErrNoRows) {
+					return nil, fmt.Errorf("note #%d not found", id)
+				}
+				return nil, fmt.Errorf("failed to get note #%d: %w", id, err)
 			}
-			_ = database.MarkEmbeddingSynced(ctx, note.ID)
-			synced++
+			notes = append(notes, note)
 		}
-
-		fmt.Printf("\nDone! Synced: %d, Failed: %d\n", synced, failed)
 	} else {
-		// Sync only unsynced notes
-		synced, err := syncer.SyncAll(database)
-		if err != nil {
-			return fmt.Errorf("sync failed: %w", err)
+		if syncForce {
+			if err := database.ResetEmbeddingSynced(ctx); err != nil {
+				return nil, fmt.Errorf("failed to reset sync state: %w", err)
+			}
 		}
-
-		if synced == 0 {
-			fmt.Println("All notes are already synced.")
-		} else {
-			fmt.Printf("Done! Synced %d notes.\n", synced)
+		var err error
+		if notes, err = database.GetUnsynced(ctx); err != nil {
+			return nil, fmt.Errorf("failed to get unsynced notes: %w", err)
 		}
 	}
+	if syncLimit > 0 && len(notes) > syncLimit {
+		notes = notes[:syncLimit]
+	}
+	return notes, nil
+}
 
+// openSyncer opens the vector index with the configured index settings and per-collection
+// models. readOnly opens it with a shared lock for search-only commands.
+func openSyncer(cfg *config.Config, readOnly bool) (*veclite.Syncer, error) {
+	open := veclite.NewSyncer
+	if readOnly {
+		open = veclite.NewSearcher
+	}
+	s, err := open(cfg.VeclitePath, cfg.EmbeddingModel)
+	if err != nil {
+		return nil, err
+	}
+	return s.WithIndex(indexConfig(cfg)).
+		WithCollectionModel(veclite.CollectionMemories, cfg.MemoryEmbeddingModel), nil
+}
+
+// splitMemories moves memories out of the notes collection of an index synced before memories
+// had their own collection. Memories that can't reuse their vectors (the memories collection
+// uses another model) are marked unsynced so this run re-embeds them.
+func splitMemories(ctx context.Context, s *veclite.Syncer) error {
+	if s.HasCollection(veclite.CollectionMemories) || !s.HasCollection(veclite.CollectionNotes) {
+		return nil
+	}
+	memories, err := database.GetNotesByTagName(ctx, "memory")
+	if err != nil {
+		return fmt.Errorf("failed to get memories: %w", err)
+	}
+	ids := make([]int64, len(memories))
+	for i, m := range memories {
+		ids[i] = m.ID
+	}
+	moved, reembed, err := s.MoveToMemories(ids)
+	if err != nil {
+		return err
+	}
+	for _, id := range reembed {
+		if err := database.MarkEmbeddingUnsynced(ctx, id); err != nil {
+			return fmt.Errorf("failed to mark memory #%d unsynced: %w", id, err)
+		}
+	}
+	if moved+len(reembed) > 0 {
+		fmt.Printf("Moved %d memories to the memories collection (%d to re-embed).\n", moved+len(reembed), len(reembed))
+	}
 	return nil
 }
+
+// noteSyncer embeds one note; satisfied by *veclite.Syncer.
+type noteSyncer interface {
+	SyncNote(id int64, title, content string) error
+	SyncMemory(id int64, title, content string) error
+}
+
+type syncFailure struct {
+	id  int64
+	err error
+}
+
+type syncResult struct {
+	synced   int
+	failures []syncFailure
+	stopped  error // set when the embedder became unavailable part-way
+}
+
+// syncNotes embeds notes in order, marking each synced as soon as it is stored so an interrupted
+// run loses no work.
+func syncNotes(ctx context.Context, s noteSyncer, notes []db.Note, bar *progress) syncResult {
+	var res syncResult
+	for i, note := range notes {
+		err := memory.SyncNote(ctx, database, s, note.ID, note.Title, note.Content)
+		switch {
+		case errors.Is(err, embeddings.ErrUnavailable):
+			res.stopped = err
+		case err != nil:
+			res.failures = append(res.failures, syncFailure{id: note.ID, err: err})
+		default:
+			_ = database.MarkEmbeddingSynced(ctx, note.ID)
+			res.synced++
+		}
+		if res.stopped != nil {
+			break
+		}
+		bar.update(i + 1)
+	}
+	bar.finish()
+	return res
+}
+
+// syncStatusResult is `noted sync --status` output.
+type syncStatusResult struct {
+	veclite.Status
+	ConfiguredModels map[string]string `json:"configured_models"` // collection -> model
+	Notes            int64             `json:"notes"`
+	Pending          int64             `json:"pending"`
+}
+
+// collectionModels returns the embedding model configured for each collection.
+func collectionModels(cfg *config.Config) map[string]string {
+	notes := cfg.EmbeddingModel
+	if notes == "" {
+		notes = embeddings.DefaultModel
+	}
+	memories := cfg.MemoryEmbeddingModel
+	if memories == "" {
+		memories = notes
+	}
+	return map[string]string{veclite.CollectionNotes: notes, veclite.CollectionMemories: memories}
+}
+
+// readSyncStatus combines the index on disk with the notes still waiting to be embedded. It
+// doesn't need Ollama.
+func readSyncStatus(ctx context.Context, cfg *config.Config) (syncStatusResult, error) {
+	res := syncStatusResult{ConfiguredModels: collectionModels(cfg)}
+
+	var err error
+	if res.Status, err = veclite.ReadStatus(cfg.VeclitePath); err != nil {
+		return res, fmt.Errorf("failed to read vector index: %w", err)
+	}
+	if res.Notes, err = database.CountNotes(ctx); err != nil {
+		return res, fmt.Errorf("failed to count notes: %w", err)
+	}
+	if res.Pending, err = database.CountUnsynced(ctx); err != nil {
+		return res, fmt.Errorf("failed to count unsynced notes: %w", err)
+	}
+	return res, nil
+}
+
+func printSyncStatus(res syncStatusResult) {
+	if !res.Exists {
+		fmt.Printf("%-12s %s (not created yet; run `noted sync`)\n", "Index:", res.Path)
+		fmt.Printf("%-12s %d of %d notes\n", "Pending:", res.Pending, res.Notes)
+		return
+	}
+
+	fmt.Printf("%-12s %s (%s)\n", "Index:", res.Path, quota.FormatSize(res.SizeBytes))
+	fmt.Printf("%-12s %d\n", "Vectors:", res.Vectors)
+	for _, c := range res.Collections {
+		vectors := fmt.Sprintf("%d", c.Vectors)
+		if c.Dimension > 0 {
+			index := veclite.IndexFlat
+			if c.IndexType == veclite.IndexHNSW {
+				index = veclite.IndexHNSW
+			}
+			vectors += fmt.Sprintf(" (%d dimensions, %s index)", c.Dimension, index)
+		}
+
+		configured := res.ConfiguredModels[c.Name]
+		model := c.Model
+		switch {
+		case model == "":
+			model = "unknown (configured: " + configured + ")"
+		case model != configured:
+			model += " (configured: " + configured + "; run `noted sync --force` to re-embed)"
+		}
+
+		last := "never"
+		if !c.LastSyncedAt.IsZero() {
+			last = c.LastSyncedAt.Local().Format("2006-01-02 15:04")
+		}
+
+		fmt.Printf("\n%s\n", c.Name)
+		fmt.Printf("  %-10s %s\n", "Vectors:", vectors)
+		fmt.Printf("  %-10s %s\n", "Model:", model)
+		fmt.Printf("  %-10s %s\n", "Last sync:", last)
+	}
+	fmt.Printf("\n%-12s %d of %d notes\n", "Pending:", res.Pending, res.Notes)
+}
@@ -5,14 +5,46 @@ package cmd
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
+	"regexp"
+	"strings"
 
+	"github.com/abdul-hamid-achik/noted/internal/db"
+	"github.com/abdul-hamid-achik/noted/internal/notesync"
 	"github.com/spf13/cobra"
 )
 
+type tagRenameResult struct {
+	Name      string `json:"name"`
+	NewName   string `json:"new_name"`
+	Merged    bool   `json:"merged"`
+	NoteCount int    `json:"note_count"`
+}
+
 type tagItem struct {
-	Name  string `json:"name"`
-	Count *int64 `json:"count,omitempty"`
+	Name        string `json:"name"`
+	Count       *int64 `json:"count,omitempty"`
+	Color       string `json:"color,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+var tagColorPattern = regexp.MustCompile(`^#(?:[0-9a-f]{3}|[0-9a-f]{6})$`)
+
+// normalizeTagColor validates a hex color ("#f80", "ff8800") and returns it lowercased with a
+// leading '#'. An empty color clears it.
+func normalizeTagColor(color string) (string, error) {
+	color = strings.ToLower(strings.TrimSpace(color))
+	if color == "" {
+		return "", nil
+	}
+	if !strings.HasPrefix(color, "#") {
+		color = "#" + color
+	}
+	if !tagColorPattern.MatchString(color) {
+		return "", fmt.Errorf("invalid color %q (use a hex color like #ff8800)", color)
+	}
+	return color, nil
 }
 
 var tagsCmd = &cobra.Command{
@@ -48,8 +80,10 @@ var tagsCmd = &cobra.Command{
 				for i, tag := range tags {
 					count := tag.NoteCount
 					items[i] = tagItem{
-						Name:  tag.Name,
-						Count: &count,
+						Name:        tag.Name,
+						Count:       &count,
+						Color:       tag.Color,
+						Description: tag.Description,
 					}
 				}
 				return outputJSON(items)
@@ -61,7 +95,14 @@ var tagsCmd = &cobra.Command{
 			}
 
 			for _, tag := range tags {
-				fmt.Printf("%s (%d)\n", tag.Name, tag.NoteCount)
+				line := fmt.Sprintf("%s (%d)", tag.Name, tag.NoteCount)
+				if tag.Color != "" {
+					line += " " + tag.Color
+				}
+				if tag.Description != "" {
+					line += " - " + tag.Description
+				}
+				fmt.Println(line)
 			}
 		} else {
 			tags, err := database.ListTags(ctx)
@@ -72,7 +113,7 @@ var tagsCmd = &cobra.Command{
 			if asJSON {
 				items := make([]tagItem, len(tags))
 				for i, tag := range tags {
-					items[i] = tagItem{Name: tag.Name}
+					items[i] = tagItem{Name: tag.Name, Color: tag.Color, Description: tag.Description}
 				}
 				return outputJSON(items)
 			}
@@ -91,10 +132,119 @@ var tagsCmd = &cobra.Command{
 	},
 }
 
+var tagsEditCmd = &cobra.Command{
+	Use:   "edit <tag>",
+	Short: "Set a tag's color and description",
+	Long: `Set the color and description shown for a tag in "noted tags", the TUI, and MCP
+tag listings. Only the flags you pass are changed; pass an empty value to clear.
+
+Examples:
+  noted tags edit work --color "#3b82f6"
+  noted tags edit work --description "Day job notes"
+  noted tags edit work --color ""`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		asJSON, _ := cmd.Flags().GetBool("json")
+
+		if !cmd.Flags().Changed("color") && !cmd.Flags().Changed("description") {
+			return fmt.Errorf("nothing to change: pass --color and/or --description")
+		}
+
+		ctx := context.Background()
+		tag, err := database.GetTagByName(ctx, args[0])
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return fmt.Errorf("tag %q not found", args[0])
+			}
+			return err
+		}
+
+		color, description := tag.Color, tag.Description
+		if cmd.Flags().Changed("color") {
+			c, _ := cmd.Flags().GetString("color")
+			if color, err = normalizeTagColor(c); err != nil {
+				return err
+			}
+		}
+		if cmd.Flags().Changed("description") {
+			description, _ = cmd.Flags().GetString("description")
+			description = strings.TrimSpace(description)
+		}
+
+		updated, err := database.UpdateTagMetadata(ctx, db.UpdateTagMetadataParams{
+			Color:       color,
+			Description: description,
+			ID:          tag.ID,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to update tag: %w", err)
+		}
+
+		if asJSON {
+			return outputJSON(tagItem{Name: updated.Name, Color: updated.Color, Description: updated.Description})
+		}
+
+		fmt.Printf("Updated tag %s\n", updated.Name)
+		return nil
+	},
+}
+
+var tagsRenameCmd = &cobra.Command{
+	Use:   "rename <old> <new>",
+	Short: "Rename a tag on every note",
+	Long: `Rename a tag on every note that has it, in one transaction. If a tag called
+<new> already exists, the two are merged: notes with <old> get <new> and <old>
+is deleted.
+
+Examples:
+  noted tags rename golang go
+  noted tags rename todo tasks --json`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		asJSON, _ := cmd.Flags().GetBool("json")
+
+		ctx := context.Background()
+		result, err := db.RenameTagByName(ctx, conn, args[0], args[1])
+		if err != nil {
+			return err
+		}
+		vlt := openVault(cmd)
+		for _, id := range result.NoteIDs {
+			if note, err := database.GetNote(ctx, id); err == nil {
+				notesync.WriteThrough(ctx, database, vlt, note)
+			}
+		}
+
+		res := tagRenameResult{
+			Name:      args[0],
+			NewName:   strings.TrimSpace(args[1]),
+			Merged:    result.Merged,
+			NoteCount: len(result.NoteIDs),
+		}
+		if asJSON {
+			return outputJSON(res)
+		}
+		if res.Merged {
+			fmt.Printf("Merged tag %s into %s (%d notes)\n", res.Name, res.NewName, res.NoteCount)
+		} else {
+			fmt.Printf("Renamed tag %s to %s (%d notes)\n", res.Name, res.NewName, res.NoteCount)
+		}
+		return nil
+	},
+}
+
 func init() {
 	rootCmd.AddCommand(tagsCmd)
+	tagsCmd.AddCommand(tagsEditCmd)
+	tagsCmd.AddCommand(tagsRenameCmd)
 
 	tagsCmd.Flags().BoolP("count", "c", false, "Show note count per tag")
 	tagsCmd.Flags().BoolP("delete-unused", "d", false, "Delete orphan tags")
 	tagsCmd.Flags().BoolP("json", "j", false, "Output as JSON")
+
+	tagsEditCmd.Flags().String("color", "", "Hex color, e.g. #ff8800 (empty to clear)")
+	tagsEditCmd.Flags().String("description", "", "Short description (empty to clear)")
+	tagsEditCmd.Flags().BoolP("json", "j", false, "Output as JSON")
+
+	tagsRenameCmd.Flags().BoolP("json", "j", false, "Output as JSON")
 }
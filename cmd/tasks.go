@@ -5,11 +5,15 @@ package cmd
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
-	"regexp"
+	"strconv"
 	"strings"
 
 	"github.com/abdul-hamid-achik/noted/internal/db"
+	"github.com/abdul-hamid-achik/noted/internal/markdown"
+	"github.com/abdul-hamid-achik/noted/internal/notesync"
+	"github.com/abdul-hamid-achik/noted/internal/vault"
 	"github.com/spf13/cobra"
 )
 
@@ -19,29 +23,40 @@ type extractedTask struct {
 	NoteID    int64  `json:"note_id"`
 	NoteTitle string `json:"note_title"`
 	Line      int    `json:"line"`
+	Index     int    `json:"index"` // position among the note's tasks; "<note_id>:<index>" names the task
+	Due       string `json:"due,omitempty"`
 }
 
-var taskRegex = regexp.MustCompile(`^\s*-\s*\[([ xX])\]\s*(.+)$`)
-
 func extractTasks(note db.Note) []extractedTask {
 	var tasks []extractedTask
-	lines := strings.Split(note.Content, "\n")
-	for i, line := range lines {
-		matches := taskRegex.FindStringSubmatch(line)
-		if matches == nil {
-			continue
-		}
+	for _, t := range markdown.Tasks(note.Content) {
 		tasks = append(tasks, extractedTask{
-			Text:      strings.TrimSpace(matches[2]),
-			Completed: matches[1] != " ",
+			Text:      t.Text,
+			Completed: t.Done,
 			NoteID:    note.ID,
 			NoteTitle: note.Title,
-			Line:      i + 1,
+			Line:      t.Line,
+			Index:     t.Index,
+			Due:       t.Due,
 		})
 	}
 	return tasks
 }
 
+// parseTaskRef parses a "<note_id>:<n>" task reference.
+func parseTaskRef(ref string) (int64, int, error) {
+	id, n, _ := strings.Cut(ref, ":")
+	noteID, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid task %q: expected <note_id>:<n>, e.g. 42:3", ref)
+	}
+	index, err := strconv.Atoi(n)
+	if err != nil || index < 1 {
+		return 0, 0, fmt.Errorf("invalid task %q: expected <note_id>:<n>, e.g. 42:3", ref)
+	}
+	return noteID, index, nil
+}
+
 var tasksCmd = &cobra.Command{
 	Use:   "tasks",
 	Short: "List tasks extracted from notes",
@@ -146,7 +161,7 @@ Examples:
 			} else {
 				pendingCount++
 			}
-			fmt.Printf("[%s] %-45s (%s #%d)\n", check, t.Text, t.NoteTitle, t.NoteID)
+			fmt.Printf("[%s] %-45s (%s #%d:%d)\n", check, t.Text, t.NoteTitle, t.NoteID, t.Index)
 		}
 		fmt.Printf("\nTasks: %d pending, %d completed, %d total\n", pendingCount, completedCount, pendingCount+completedCount)
 
@@ -154,13 +169,125 @@ Examples:
 	},
 }
 
+type todoDoneResult struct {
+	NoteID int64  `json:"note_id"`
+	Index  int    `json:"index"`
+	Text   string `json:"text"`
+	Done   bool   `json:"done"`
+}
+
+var todoCmd = &cobra.Command{
+	Use:   "todo",
+	Short: "List and check off tasks across notes",
+	Long: `Aggregate the markdown checkboxes in all notes and check them off.
+
+A task is named <note_id>:<n>, the nth checkbox in the note, as shown by
+"noted todo list".
+
+Examples:
+  noted todo list --pending
+  noted todo done 42:3
+  noted todo done 42:3 42:4
+  noted todo done 42:3 --undo`,
+}
+
+var todoListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List tasks across notes",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return tasksCmd.RunE(cmd, args)
+	},
+}
+
+var todoDoneCmd = &cobra.Command{
+	Use:   "done <note_id>:<n> [<note_id>:<n>...]",
+	Short: "Check off tasks (--undo unchecks them)",
+	Args:  cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		undo, _ := cmd.Flags().GetBool("undo")
+		asJSON, _ := cmd.Flags().GetBool("json")
+
+		ctx := context.Background()
+		vlt := openVault(cmd)
+		var results []todoDoneResult
+		for _, ref := range args {
+			noteID, index, err := parseTaskRef(ref)
+			if err != nil {
+				return err
+			}
+			task, err := setTaskDone(ctx, vlt, noteID, index, !undo)
+			if err != nil {
+				return err
+			}
+			results = append(results, todoDoneResult{NoteID: noteID, Index: index, Text: task.Text, Done: task.Done})
+		}
+
+		if asJSON {
+			return outputJSON(results)
+		}
+		for _, r := range results {
+			check := " "
+			if r.Done {
+				check = "x"
+			}
+			fmt.Printf("[%s] %s (#%d:%d)\n", check, r.Text, r.NoteID, r.Index)
+		}
+		return nil
+	},
+}
+
+// setTaskDone checks or unchecks the nth task of a note and saves the note, snapshotting the
+// previous version first. A task already in the wanted state leaves the note untouched.
+func setTaskDone(ctx context.Context, vlt *vault.Vault, noteID int64, n int, done bool) (markdown.Task, error) {
+	note, err := database.GetNote(ctx, noteID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return markdown.Task{}, fmt.Errorf("note #%d not found", noteID)
+		}
+		return markdown.Task{}, fmt.Errorf("failed to get note: %w", err)
+	}
+	content, task, ok := markdown.SetTaskDone(note.Content, n, done)
+	if !ok {
+		return markdown.Task{}, fmt.Errorf("note #%d has no task %d", noteID, n)
+	}
+	if content == note.Content {
+		return task, nil
+	}
+
+	if err := notesync.SnapshotVersion(ctx, database, vlt, note.ID, note.Title, note.Content); err != nil {
+		return markdown.Task{}, fmt.Errorf("failed to save version: %w", err)
+	}
+	updated, err := database.UpdateNote(ctx, db.UpdateNoteParams{
+		ID:      note.ID,
+		Title:   note.Title,
+		Content: content,
+	})
+	if err != nil {
+		return markdown.Task{}, fmt.Errorf("failed to update note: %w", err)
+	}
+	notesync.WriteThrough(ctx, database, vlt, updated)
+	return task, nil
+}
+
+// addTaskListFlags adds the filters shared by "noted tasks" and "noted todo list".
+func addTaskListFlags(c *cobra.Command) {
+	c.Flags().Bool("pending", false, "Show only pending tasks")
+	c.Flags().Bool("completed", false, "Show only completed tasks")
+	c.Flags().StringP("tag", "T", "", "Filter by tag name")
+	c.Flags().Int64("note", 0, "Filter by note ID")
+	c.Flags().Bool("count", false, "Show task counts only")
+	c.Flags().BoolP("json", "j", false, "Output as JSON")
+}
+
 func init() {
 	rootCmd.AddCommand(tasksCmd)
+	rootCmd.AddCommand(todoCmd)
+	todoCmd.AddCommand(todoListCmd)
+	todoCmd.AddCommand(todoDoneCmd)
 
-	tasksCmd.Flags().Bool("pending", false, "Show only pending tasks")
-	tasksCmd.Flags().Bool("completed", false, "Show only completed tasks")
-	tasksCmd.Flags().StringP("tag", "T", "", "Filter by tag name")
-	tasksCmd.Flags().Int64("note", 0, "Filter by note ID")
-	tasksCmd.Flags().Bool("count", false, "Show task counts only")
-	tasksCmd.Flags().BoolP("json", "j", false, "Output as JSON")
+	addTaskListFlags(tasksCmd)
+	addTaskListFlags(todoListCmd)
+	todoDoneCmd.Flags().Bool("undo", false, "Uncheck the tasks instead")
+	todoDoneCmd.Flags().BoolP("json", "j", false, "Output as JSON")
 }
@@ -4,13 +4,19 @@ Copyright © 2026 abdul hamid <abdulachik@icloud.com>
 package cmd
 
 import (
+	"bufio"
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"io"
+	"os"
+	"regexp"
 	"strings"
 	"time"
 
 	"github.com/abdul-hamid-achik/noted/internal/db"
+	"github.com/abdul-hamid-achik/noted/internal/notesync"
 	"github.com/spf13/cobra"
 )
 
@@ -38,20 +44,102 @@ func formatTemplateItem(t db.Template) templateItem {
 }
 
 func interpolateTemplate(content, title string) string {
-	now := time.Now()
+	return interpolateTemplateAt(content, title, time.Now())
+}
+
+// interpolateTemplateAt is interpolateTemplate with the date variables taken from t instead of now.
+func interpolateTemplateAt(content, title string, t time.Time) string {
 	r := strings.NewReplacer(
-		"{{date}}", now.Format("2006-01-02"),
-		"{{time}}", now.Format("15:04"),
-		"{{datetime}}", now.Format("2006-01-02 15:04"),
+		"{{date}}", t.Format("2006-01-02"),
+		"{{time}}", t.Format("15:04"),
+		"{{datetime}}", t.Format("2006-01-02 15:04"),
 		"{{title}}", title,
 	)
 	return r.Replace(content)
 }
 
+// templateCursor marks where the editor's cursor starts when a template is opened.
+const templateCursor = "{{cursor}}"
+
+var templateVarPattern = regexp.MustCompile(`\{\{\s*([A-Za-z_][\w-]*)\s*\}\}`)
+
+// templateVars returns the custom placeholders in a template, in order of first appearance. The
+// built-in variables and the cursor marker are not included.
+func templateVars(content string) []string {
+	var names []string
+	seen := map[string]bool{"date": true, "time": true, "datetime": true, "title": true, "cursor": true}
+	for _, m := range templateVarPattern.FindAllStringSubmatch(content, -1) {
+		if !seen[m[1]] {
+			seen[m[1]] = true
+			names = append(names, m[1])
+		}
+	}
+	return names
+}
+
+// fillTemplateVars replaces {{name}} placeholders with the given values. Placeholders without a
+// value are left in place.
+func fillTemplateVars(content string, vars map[string]string) string {
+	return templateVarPattern.ReplaceAllStringFunc(content, func(m string) string {
+		name := templateVarPattern.FindStringSubmatch(m)[1]
+		if v, ok := vars[name]; ok {
+			return v
+		}
+		return m
+	})
+}
+
+// parseTemplateVars parses --var key=value flags.
+func parseTemplateVars(pairs []string) (map[string]string, error) {
+	vars := make(map[string]string, len(pairs))
+	for _, pair := range pairs {
+		key, value, ok := strings.Cut(pair, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid --var %q: expected key=value", pair)
+		}
+		vars[key] = value
+	}
+	return vars, nil
+}
+
+// promptTemplateVars asks for each placeholder in names that has no value in vars yet and
+// records the answers in vars.
+func promptTemplateVars(names []string, vars map[string]string, in io.Reader, out io.Writer) error {
+	reader := bufio.NewReader(in)
+	for _, name := range names {
+		if _, ok := vars[name]; ok {
+			continue
+		}
+		fmt.Fprintf(out, "%s: ", name)
+		answer, err := reader.ReadString('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return err
+		}
+		vars[name] = strings.TrimSpace(answer)
+		if errors.Is(err, io.EOF) {
+			fmt.Fprintln(out)
+		}
+	}
+	return nil
+}
+
+// cutCursor removes the first cursor marker from content and returns its 1-based line and
+// column, or line 0 when there is no marker. Any further markers are removed too.
+func cutCursor(content string) (string, int, int) {
+	before, after, ok := strings.Cut(content, templateCursor)
+	if !ok {
+		return content, 0, 0
+	}
+	line := strings.Count(before, "\n") + 1
+	col := len(before) - strings.LastIndex(before, "\n")
+	return before + strings.ReplaceAll(after, templateCursor, ""), line, col
+}
+
 var templateCmd = &cobra.Command{
 	Use:   "template",
 	Short: "Manage note templates",
-	Long:  "Create, list, show, edit, and delete note templates.",
+	Long:  "Create, list, show, edit, and delete note templates, and create notes from them.",
 }
 
 var templateListCmd = &cobra.Command{
@@ -227,6 +315,82 @@ var templateEditCmd = &cobra.Command{
 	},
 }
 
+var templateUseCmd = &cobra.Command{
+	Use:   "use <name>",
+	Short: "Create a note from a template",
+	Long: `Create a note from a template without opening the editor.
+
+{{date}}, {{time}}, {{datetime}} and {{title}} are filled in, and custom
+placeholders take their values from --var. In a terminal, noted asks for any
+custom placeholder --var did not set.
+
+Examples:
+  noted template use meeting -t "Standup {{date}}"
+  noted template use meeting -t "Acme kickoff" --var client=Acme -T work,meetings`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		title, _ := cmd.Flags().GetString("title")
+		tags, _ := cmd.Flags().GetString("tags")
+		varPairs, _ := cmd.Flags().GetStringArray("var")
+		asJSON, _ := cmd.Flags().GetBool("json")
+
+		ctx := context.Background()
+		tmpl, err := database.GetTemplateByName(ctx, args[0])
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return fmt.Errorf("template %q not found", args[0])
+			}
+			return err
+		}
+		vars, err := parseTemplateVars(varPairs)
+		if err != nil {
+			return err
+		}
+
+		title = interpolateTemplate(title, "")
+		content := interpolateTemplate(tmpl.Content, title)
+		if stat, _ := os.Stdin.Stat(); !asJSON && (stat.Mode()&os.ModeCharDevice) != 0 {
+			if err := promptTemplateVars(templateVars(content), vars, os.Stdin, os.Stdout); err != nil {
+				return err
+			}
+		}
+		content, _, _ = cutCursor(fillTemplateVars(content, vars))
+
+		note, err := database.CreateNote(ctx, db.CreateNoteParams{
+			Title:   title,
+			Content: content,
+		})
+		if err != nil {
+			return err
+		}
+		for _, tagName := range strings.Split(tags, ",") {
+			tagName = strings.TrimSpace(tagName)
+			if tagName == "" {
+				continue
+			}
+			tag, err := database.CreateTag(ctx, tagName)
+			if err != nil {
+				return err
+			}
+			if err := database.AddTagToNote(ctx, db.AddTagToNoteParams{NoteID: note.ID, TagID: tag.ID}); err != nil {
+				return err
+			}
+		}
+
+		note, err = applyProject(ctx, captureProject("."), note, false)
+		if err != nil {
+			return err
+		}
+		notesync.WriteThrough(ctx, database, openVault(cmd), note)
+
+		if asJSON {
+			return outputJSON(addResult{ID: note.ID, Title: note.Title})
+		}
+		fmt.Printf("Created note #%d from template %q: %s\n", note.ID, tmpl.Name, note.Title)
+		return nil
+	},
+}
+
 func init() {
 	rootCmd.AddCommand(templateCmd)
 	templateCmd.AddCommand(templateListCmd)
@@ -234,6 +398,7 @@ func init() {
 	templateCmd.AddCommand(templateShowCmd)
 	templateCmd.AddCommand(templateDeleteCmd)
 	templateCmd.AddCommand(templateEditCmd)
+	templateCmd.AddCommand(templateUseCmd)
 
 	templateListCmd.Flags().BoolP("json", "j", false, "Output as JSON")
 	templateCreateCmd.Flags().BoolP("json", "j", false, "Output as JSON")
@@ -244,4 +409,9 @@ func init() {
 	templateDeleteCmd.Flags().BoolP("json", "j", false, "Output as JSON")
 	templateDeleteCmd.Flags().BoolP("force", "f", false, "Skip confirmation")
 	templateEditCmd.Flags().BoolP("json", "j", false, "Output as JSON")
+	templateUseCmd.Flags().BoolP("json", "j", false, "Output as JSON")
+	templateUseCmd.Flags().StringP("title", "t", "", "Note title (required); may use {{date}}, {{time}}, {{datetime}}")
+	templateUseCmd.Flags().StringP("tags", "T", "", "Comma-separated tags")
+	templateUseCmd.Flags().StringArray("var", nil, "Template placeholder value as key=value (repeatable)")
+	_ = templateUseCmd.MarkFlagRequired("title")
 }
@@ -0,0 +1,247 @@
+/*
+Copyright © 2026 abdul hamid <abdulachik@icloud.com>
+*/
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/abdul-hamid-achik/noted/internal/config"
+	"github.com/abdul-hamid-achik/noted/internal/db"
+	"github.com/abdul-hamid-achik/noted/internal/notesync"
+	"github.com/spf13/cobra"
+)
+
+type trashItem struct {
+	ID        int64  `json:"id"`
+	Title     string `json:"title"`
+	DeletedAt string `json:"deleted_at"`
+}
+
+type trashResult struct {
+	Count int     `json:"count"`
+	IDs   []int64 `json:"ids"`
+}
+
+var trashCmd = &cobra.Command{
+	Use:   "trash",
+	Short: "List, restore, or empty deleted notes",
+	Long: `Deleted notes go to the trash first. They are hidden from lists, search, and
+the vault, but keep their tags, links, and version history until purged.
+
+Notes are purged once they have been in the trash for NOTED_TRASH_DAYS days
+(default 30; 0 keeps them until you empty the trash). "trash list",
+"trash empty", and "noted gc" run the purge.
+
+Examples:
+  noted trash list
+  noted trash restore 42
+  noted trash empty --older-than 7d
+  noted trash empty 42 43 --force`,
+}
+
+var trashListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List notes in the trash",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		asJSON, _ := cmd.Flags().GetBool("json")
+		purgeOldTrash(cmd)
+
+		notes, err := database.ListTrashedNotes(context.Background())
+		if err != nil {
+			return fmt.Errorf("failed to list trash: %w", err)
+		}
+
+		if asJSON {
+			items := make([]trashItem, len(notes))
+			for i, n := range notes {
+				items[i] = trashItem{ID: n.ID, Title: n.Title, DeletedAt: n.DeletedAt.Time.Format(time.RFC3339)}
+			}
+			return outputJSON(items)
+		}
+
+		if len(notes) == 0 {
+			fmt.Println("The trash is empty.")
+			return nil
+		}
+		for _, n := range notes {
+			fmt.Printf("#%-4d %-40s deleted %s\n", n.ID, n.Title, n.DeletedAt.Time.Local().Format("2006-01-02 15:04"))
+		}
+		fmt.Printf("\n%d note(s) in the trash\n", len(notes))
+		return nil
+	},
+}
+
+var trashRestoreCmd = &cobra.Command{
+	Use:   "restore <id> [id...]",
+	Short: "Restore notes from the trash",
+	Args:  cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		asJSON, _ := cmd.Flags().GetBool("json")
+
+		ids, err := parseNoteIDs(args)
+		if err != nil {
+			return err
+		}
+
+		ctx := context.Background()
+		vlt := openVault(cmd)
+		restored := make([]int64, 0, len(ids))
+		for _, id := range ids {
+			note, err := notesync.Restore(ctx, database, vlt, id)
+			if err != nil {
+				if err == sql.ErrNoRows {
+					if !asJSON {
+						fmt.Fprintf(os.Stderr, "note #%d is not in the trash\n", id)
+					}
+					continue
+				}
+				return fmt.Errorf("failed to restore note #%d: %w", id, err)
+			}
+			if !asJSON {
+				fmt.Printf("Restored note #%d: %s\n", note.ID, note.Title)
+			}
+			restored = append(restored, id)
+		}
+
+		if asJSON {
+			return outputJSON(trashResult{Count: len(restored), IDs: restored})
+		}
+		if len(restored) > 0 {
+			fmt.Println("Run `noted sync` to add restored notes back to semantic search.")
+		}
+		return nil
+	},
+}
+
+var trashEmptyCmd = &cobra.Command{
+	Use:   "empty [id...]",
+	Short: "Permanently delete notes in the trash",
+	Long: `Permanently delete notes in the trash: all of them, the given ids, or those
+deleted longer ago than --older-than. This cannot be undone.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		force, _ := cmd.Flags().GetBool("force")
+		asJSON, _ := cmd.Flags().GetBool("json")
+		olderThan, _ := cmd.Flags().GetString("older-than")
+		purgeOldTrash(cmd)
+
+		ctx := context.Background()
+		var notes []db.Note
+		var err error
+		switch {
+		case len(args) > 0 && olderThan != "":
+			return fmt.Errorf("pass note ids or --older-than, not both")
+		case len(args) > 0:
+			ids, err := parseNoteIDs(args)
+			if err != nil {
+				return err
+			}
+			for _, id := range ids {
+				n, err := database.GetTrashedNote(ctx, id)
+				if err == sql.ErrNoRows {
+					return fmt.Errorf("note #%d is not in the trash", id)
+				} else if err != nil {
+					return err
+				}
+				notes = append(notes, n)
+			}
+		case olderThan != "":
+			d, err := parseDuration(olderThan)
+			if err != nil {
+				return err
+			}
+			notes, err = database.GetTrashedNotesBefore(ctx, sql.NullTime{Time: time.Now().Add(-d).UTC(), Valid: true})
+			if err != nil {
+				return fmt.Errorf("failed to list trash: %w", err)
+			}
+		default:
+			if notes, err = database.ListTrashedNotes(ctx); err != nil {
+				return fmt.Errorf("failed to list trash: %w", err)
+			}
+		}
+
+		if len(notes) == 0 {
+			if asJSON {
+				return outputJSON(trashResult{IDs: []int64{}})
+			}
+			fmt.Println("Nothing to purge.")
+			return nil
+		}
+
+		if !force {
+			fmt.Printf("Permanently delete %d note(s)? This cannot be undone. [y/N]: ", len(notes))
+			response, err := bufio.NewReader(os.Stdin).ReadString('\n')
+			if err != nil {
+				return err
+			}
+			response = strings.TrimSpace(strings.ToLower(response))
+			if response != "y" && response != "yes" {
+				fmt.Println("Aborted.")
+				return nil
+			}
+		}
+
+		vlt := openVault(cmd)
+		purged := make([]int64, 0, len(notes))
+		for _, n := range notes {
+			if err := notesync.Purge(ctx, database, vlt, n.ID); err != nil {
+				return err
+			}
+			purged = append(purged, n.ID)
+		}
+
+		if asJSON {
+			return outputJSON(trashResult{Count: len(purged), IDs: purged})
+		}
+		fmt.Printf("Permanently deleted %d note(s).\n", len(purged))
+		return nil
+	},
+}
+
+// parseNoteIDs parses note id arguments.
+func parseNoteIDs(args []string) ([]int64, error) {
+	ids := make([]int64, 0, len(args))
+	for _, arg := range args {
+		id, err := strconv.ParseInt(arg, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid note ID: %s", arg)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// purgeOldTrash permanently deletes notes that have been in the trash for longer than
+// NOTED_TRASH_DAYS. It runs before "trash list" and "trash empty" ("noted gc" purges on its own);
+// a failure is reported but does not stop the command.
+func purgeOldTrash(cmd *cobra.Command) {
+	cfg, err := config.Load()
+	if err != nil || cfg.TrashDays <= 0 {
+		return
+	}
+	cutoff := time.Now().AddDate(0, 0, -cfg.TrashDays)
+	if _, err := notesync.PurgeTrash(context.Background(), database, openVault(cmd), cutoff); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: failed to purge expired trash: %v\n", err)
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(trashCmd)
+	trashCmd.AddCommand(trashListCmd)
+	trashCmd.AddCommand(trashRestoreCmd)
+	trashCmd.AddCommand(trashEmptyCmd)
+
+	for _, c := range []*cobra.Command{trashListCmd, trashRestoreCmd, trashEmptyCmd} {
+		c.Flags().BoolP("json", "j", false, "Output as JSON")
+	}
+	trashEmptyCmd.Flags().BoolP("force", "f", false, "Skip confirmation")
+	trashEmptyCmd.Flags().String("older-than", "", "Only purge notes deleted longer ago than this (e.g. 7d, 12h)")
+}
@@ -82,11 +82,6 @@ var vaultExportCmd = &cobra.Command{
 		seen := map[string]bool{} // keep filenames stable + collision-free
 		count := 0
 		for _, n := range notes {
-			tags, _ := database.GetTagsForNote(ctx, n.ID)
-			tnames := make([]string, len(tags))
-			for i, t := range tags {
-				tnames[i] = t.Name
-			}
 			base := vault.Slugify(n.Title)
 			name := base
 			for i := 2; seen[name]; i++ { // key uniqueness on the final filename, not the base slug
@@ -94,23 +89,8 @@ var vaultExportCmd = &cobra.Command{
 			}
 			seen[name] = true
 
-			vn := vault.Note{
-				ID:      n.ID,
-				Path:    filepath.Join(vpath, name+".md"),
-				Title:   n.Title,
-				Tags:    tnames,
-				Pinned:  n.Pinned.Valid && n.Pinned.Bool,
-				Content: n.Content,
-			}
-			if n.FolderID.Valid {
-				vn.Folder = notesync.FolderPath(ctx, database, n.FolderID.Int64)
-			}
-			if n.CreatedAt.Valid {
-				vn.Created = n.CreatedAt.Time
-			}
-			if n.UpdatedAt.Valid {
-				vn.Updated = n.UpdatedAt.Time
-			}
+			vn := notesync.VaultNote(ctx, database, n)
+			vn.Path = filepath.Join(vpath, name+".md")
 			if _, err := vlt.WriteRaw(vn); err != nil {
 				return err
 			}
@@ -51,6 +51,7 @@ export default defineConfig({
             { text: 'MCP server', link: '/reference/mcp' },
             { text: 'Configuration', link: '/reference/configuration' },
             { text: 'Vault format', link: '/reference/vault-format' },
+            { text: 'Go library', link: '/reference/go-library' },
           ],
         },
       ],
@@ -3,6 +3,12 @@ package config
 import (
 	"os"
 	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/abdul-hamid-achik/noted/internal/quota"
+	"github.com/abdul-hamid-achik/noted/internal/sanitize"
 )
 
 type Config struct {
@@ -11,6 +17,64 @@ type Config struct {
 	VaultPath      string
 	VeclitePath    string
 	EmbeddingModel string
+	// Embedding model for the memories collection (NOTED_MEMORY_EMBEDDING_MODEL); empty = EmbeddingModel
+	MemoryEmbeddingModel string
+	SearchHistory  bool   // record recent searches (NOTED_SEARCH_HISTORY=off disables)
+	MCPTools       string // comma-separated MCP tool groups to expose (NOTED_MCP_TOOLS); empty = all
+	MCPSafe        bool   // hide destructive MCP tools (NOTED_MCP_SAFE)
+
+	// MCP mutation limits; 0 disables a limit.
+	MCPMaxCreatesPerMinute int // NOTED_MCP_MAX_CREATES_PER_MINUTE
+	MCPMaxForget           int // memories one noted_forget call may delete (NOTED_MCP_MAX_FORGET)
+
+	// Vector index tuning, applied when the index is created or rebuilt with `noted reindex`.
+	VectorIndex        string // "flat" (exact) or "hnsw" (NOTED_VECTOR_INDEX)
+	HNSWM              int    // NOTED_HNSW_M; 0 = default
+	HNSWEfConstruction int    // NOTED_HNSW_EF_CONSTRUCTION; 0 = default
+	HNSWEfSearch       int    // NOTED_HNSW_EF_SEARCH; 0 = default
+
+	// Paths (prefixes or globs) whose commits `noted capture-git` stores (NOTED_CAPTURE_GIT_PATHS,
+	// comma-separated); empty = every commit.
+	CaptureGitPaths []string
+
+	// Editor command with arguments, e.g. "code --wait" (NOTED_EDITOR); takes precedence over
+	// $VISUAL and $EDITOR.
+	Editor string
+
+	// Days a deleted note stays in the trash before it is purged (NOTED_TRASH_DAYS); 0 keeps
+	// trashed notes until "noted trash empty".
+	TrashDays int
+
+	// Template new daily notes start from (NOTED_DAILY_TEMPLATE, default "daily"); ignored when
+	// no template has that name.
+	DailyTemplate string
+
+	// Queries slower than this many milliseconds are logged to stderr (NOTED_SLOW_QUERY_MS);
+	// 0 turns the log off.
+	SlowQueryMS int
+
+	// Size warnings and hard limits for one note's content (NOTED_NOTE_SIZE_WARN, default 1MB;
+	// NOTED_NOTE_SIZE_MAX), the database (NOTED_DB_SIZE_WARN, NOTED_DB_SIZE_MAX), and the vault's
+	// attachments (NOTED_ATTACHMENTS_WARN, NOTED_ATTACHMENTS_MAX). Unset or 0 disables a limit.
+	Quotas quota.Quotas
+
+	// Clean-up of content written through MCP (NOTED_SANITIZE, off by default): control characters
+	// and line endings always, blank-line runs past NOTED_SANITIZE_MAX_BLANK_LINES (default 2), and
+	// HTML markup with NOTED_SANITIZE_HTML.
+	Sanitize sanitize.Policy
+
+	// Outgoing mail for "noted share --email".
+	SMTP SMTP
+}
+
+// SMTP is the server "noted share --email" sends through (NOTED_SMTP_HOST, NOTED_SMTP_PORT default
+// 587, NOTED_SMTP_USERNAME, NOTED_SMTP_PASSWORD). Mail is from NOTED_SMTP_FROM, or else the username.
+type SMTP struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
 }
 
 func Load() (*Config, error) {
@@ -20,7 +84,7 @@ func Load() (*Config, error) {
     }
 
     c := &Config{}
-	c.DataDir = filepath.Join(homeDir, ".local", "share", "noted")
+	c.DataDir = dataDirFor(runtime.GOOS, homeDir, os.Getenv("APPDATA"))
 	c.DBPath = filepath.Join(c.DataDir, "noted.db")
 
 	// Markdown vault directory (source of truth for notes). Override with NOTED_VAULT.
@@ -40,6 +104,51 @@ func Load() (*Config, error) {
 
 	// Optional: embedding model from environment
 	c.EmbeddingModel = os.Getenv("NOTED_EMBEDDING_MODEL")
+	c.MemoryEmbeddingModel = os.Getenv("NOTED_MEMORY_EMBEDDING_MODEL")
+
+	// Search history is on by default; opt out with NOTED_SEARCH_HISTORY=off (or 0/false/no).
+	c.SearchHistory = envBool("NOTED_SEARCH_HISTORY", true)
+
+	// MCP tool gating; "noted mcp --tools/--safe" override these.
+	c.MCPTools = os.Getenv("NOTED_MCP_TOOLS")
+	c.MCPSafe = envBool("NOTED_MCP_SAFE", false)
+	c.MCPMaxCreatesPerMinute = envInt("NOTED_MCP_MAX_CREATES_PER_MINUTE", 60)
+	c.MCPMaxForget = envInt("NOTED_MCP_MAX_FORGET", 100)
+
+	c.VectorIndex = os.Getenv("NOTED_VECTOR_INDEX")
+	c.HNSWM = envInt("NOTED_HNSW_M", 0)
+	c.HNSWEfConstruction = envInt("NOTED_HNSW_EF_CONSTRUCTION", 0)
+	c.HNSWEfSearch = envInt("NOTED_HNSW_EF_SEARCH", 0)
+
+	c.CaptureGitPaths = envList("NOTED_CAPTURE_GIT_PATHS")
+	c.Editor = strings.TrimSpace(os.Getenv("NOTED_EDITOR"))
+	c.TrashDays = envInt("NOTED_TRASH_DAYS", 30)
+	c.DailyTemplate = strings.TrimSpace(os.Getenv("NOTED_DAILY_TEMPLATE"))
+	if c.DailyTemplate == "" {
+		c.DailyTemplate = "daily"
+	}
+	c.SlowQueryMS = envInt("NOTED_SLOW_QUERY_MS", 0)
+	c.Quotas = quota.Quotas{
+		NoteSize:    quota.Limit{Warn: envSize("NOTED_NOTE_SIZE_WARN", 1<<20), Max: envSize("NOTED_NOTE_SIZE_MAX", 0)},
+		DBSize:      quota.Limit{Warn: envSize("NOTED_DB_SIZE_WARN", 0), Max: envSize("NOTED_DB_SIZE_MAX", 0)},
+		Attachments: quota.Limit{Warn: envSize("NOTED_ATTACHMENTS_WARN", 0), Max: envSize("NOTED_ATTACHMENTS_MAX", 0)},
+	}
+	c.Sanitize = sanitize.Policy{
+		Enabled:       envBool("NOTED_SANITIZE", false),
+		MaxBlankLines: envInt("NOTED_SANITIZE_MAX_BLANK_LINES", 2),
+		StripHTML:     envBool("NOTED_SANITIZE_HTML", false),
+	}
+
+	c.SMTP = SMTP{
+		Host:     strings.TrimSpace(os.Getenv("NOTED_SMTP_HOST")),
+		Port:     envInt("NOTED_SMTP_PORT", 587),
+		Username: os.Getenv("NOTED_SMTP_USERNAME"),
+		Password: os.Getenv("NOTED_SMTP_PASSWORD"),
+		From:     strings.TrimSpace(os.Getenv("NOTED_SMTP_FROM")),
+	}
+	if c.SMTP.From == "" {
+		c.SMTP.From = c.SMTP.Username
+	}
 
 	if err := os.MkdirAll(c.DataDir, os.ModePerm); err != nil {
 		return nil, err
@@ -47,3 +156,61 @@ func Load() (*Config, error) {
 
 	return c, nil
 }
+
+// envBool reads a boolean setting from the environment, returning def when unset or unrecognized.
+func envBool(name string, def bool) bool {
+	switch strings.ToLower(strings.TrimSpace(os.Getenv(name))) {
+	case "1", "true", "yes", "on":
+		return true
+	case "0", "false", "no", "off":
+		return false
+	}
+	return def
+}
+
+// envInt reads a non-negative integer setting from the environment, returning def when unset or
+// invalid.
+func envInt(name string, def int) int {
+	n, err := strconv.Atoi(strings.TrimSpace(os.Getenv(name)))
+	if err != nil || n < 0 {
+		return def
+	}
+	return n
+}
+
+// envSize reads a byte size such as "10MB" from the environment (see quota.ParseSize), returning
+// def when unset or invalid.
+func envSize(name string, def int64) int64 {
+	v := os.Getenv(name)
+	if strings.TrimSpace(v) == "" {
+		return def
+	}
+	n, err := quota.ParseSize(v)
+	if err != nil {
+		return def
+	}
+	return n
+}
+
+// dataDirFor returns the default data directory: %APPDATA%\noted on Windows and
+// ~/.local/share/noted elsewhere.
+func dataDirFor(goos, homeDir, appData string) string {
+	if goos == "windows" {
+		if appData == "" {
+			appData = filepath.Join(homeDir, "AppData", "Roaming")
+		}
+		return filepath.Join(appData, "noted")
+	}
+	return filepath.Join(homeDir, ".local", "share", "noted")
+}
+
+// envList reads a comma-separated list from the environment, dropping empty items.
+func envList(name string) []string {
+	var out []string
+	for _, item := range strings.Split(os.Getenv(name), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			out = append(out, item)
+		}
+	}
+	return out
+}
@@ -3,6 +3,7 @@ package config
 import (
 	"os"
 	"path/filepath"
+	"runtime"
 	"strings"
 	"testing"
 )
@@ -19,6 +20,9 @@ func TestLoad_DefaultPaths(t *testing.T) {
 
 	homeDir, _ := os.UserHomeDir()
 	expectedDataDir := filepath.Join(homeDir, ".local", "share", "noted")
+	if runtime.GOOS == "windows" {
+		expectedDataDir = filepath.Join(os.Getenv("APPDATA"), "noted")
+	}
 
 	if cfg.DataDir != expectedDataDir {
 		t.Errorf("expected DataDir=%q, got %q", expectedDataDir, cfg.DataDir)
@@ -34,6 +38,20 @@ func TestLoad_DefaultPaths(t *testing.T) {
 	}
 }
 
+func TestDataDirFor(t *testing.T) {
+	home := filepath.Join("home", "ana")
+	if got, want := dataDirFor("linux", home, ""), filepath.Join(home, ".local", "share", "noted"); got != want {
+		t.Errorf("linux: expected %q, got %q", want, got)
+	}
+	appData := filepath.Join("C:", "Users", "ana", "AppData", "Roaming")
+	if got, want := dataDirFor("windows", home, appData), filepath.Join(appData, "noted"); got != want {
+		t.Errorf("windows: expected %q, got %q", want, got)
+	}
+	if got, want := dataDirFor("windows", home, ""), filepath.Join(home, "AppData", "Roaming", "noted"); got != want {
+		t.Errorf("windows without APPDATA: expected %q, got %q", want, got)
+	}
+}
+
 func TestLoad_CustomVeclitePath(t *testing.T) {
 	customPath := "/tmp/test-vectors.veclite"
 	t.Setenv("NOTED_VECLITE_PATH", customPath)
@@ -75,3 +93,147 @@ func TestLoad_CreatesDataDir(t *testing.T) {
 		t.Error("expected DataDir to be a directory")
 	}
 }
+
+func TestLoad_SearchHistoryOptOut(t *testing.T) {
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if !cfg.SearchHistory {
+		t.Error("expected search history to be enabled by default")
+	}
+
+	t.Setenv("NOTED_SEARCH_HISTORY", "off")
+	cfg, err = Load()
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if cfg.SearchHistory {
+		t.Error("expected NOTED_SEARCH_HISTORY=off to disable search history")
+	}
+}
+
+func TestLoad_CaptureGitPaths(t *testing.T) {
+	t.Setenv("NOTED_CAPTURE_GIT_PATHS", " internal/db, ,docs/*.md ")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if len(cfg.CaptureGitPaths) != 2 || cfg.CaptureGitPaths[0] != "internal/db" || cfg.CaptureGitPaths[1] != "docs/*.md" {
+		t.Errorf("unexpected CaptureGitPaths: %q", cfg.CaptureGitPaths)
+	}
+}
+
+func TestLoad_Editor(t *testing.T) {
+	t.Setenv("NOTED_EDITOR", "  code --wait ")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if cfg.Editor != "code --wait" {
+		t.Errorf("expected Editor %q, got %q", "code --wait", cfg.Editor)
+	}
+}
+
+func TestLoad_TrashDays(t *testing.T) {
+	t.Setenv("NOTED_TRASH_DAYS", "")
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if cfg.TrashDays != 30 {
+		t.Errorf("expected default TrashDays 30, got %d", cfg.TrashDays)
+	}
+
+	t.Setenv("NOTED_TRASH_DAYS", "0")
+	if cfg, _ = Load(); cfg.TrashDays != 0 {
+		t.Errorf("expected TrashDays 0, got %d", cfg.TrashDays)
+	}
+}
+
+func TestLoad_Quotas(t *testing.T) {
+	t.Setenv("NOTED_NOTE_SIZE_WARN", "")
+	t.Setenv("NOTED_NOTE_SIZE_MAX", "5MB")
+	t.Setenv("NOTED_DB_SIZE_WARN", "bogus")
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if cfg.Quotas.NoteSize.Warn != 1<<20 || cfg.Quotas.NoteSize.Max != 5<<20 {
+		t.Errorf("note size quota = %+v", cfg.Quotas.NoteSize)
+	}
+	if cfg.Quotas.DBSize.Warn != 0 {
+		t.Errorf("an invalid size should leave the limit off, got %d", cfg.Quotas.DBSize.Warn)
+	}
+}
+
+func TestLoad_Sanitize(t *testing.T) {
+	t.Setenv("NOTED_SANITIZE", "")
+	t.Setenv("NOTED_SANITIZE_MAX_BLANK_LINES", "")
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if cfg.Sanitize.Enabled || cfg.Sanitize.MaxBlankLines != 2 {
+		t.Errorf("default sanitize policy = %+v", cfg.Sanitize)
+	}
+
+	t.Setenv("NOTED_SANITIZE", "on")
+	t.Setenv("NOTED_SANITIZE_HTML", "yes")
+	if cfg, _ = Load(); !cfg.Sanitize.Enabled || !cfg.Sanitize.StripHTML {
+		t.Errorf("sanitize policy = %+v", cfg.Sanitize)
+	}
+}
+
+func TestLoad_SMTP(t *testing.T) {
+	t.Setenv("NOTED_SMTP_HOST", "smtp.example.com")
+	t.Setenv("NOTED_SMTP_PORT", "")
+	t.Setenv("NOTED_SMTP_USERNAME", "me@example.com")
+	t.Setenv("NOTED_SMTP_FROM", "")
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if cfg.SMTP.Host != "smtp.example.com" || cfg.SMTP.Port != 587 || cfg.SMTP.From != "me@example.com" {
+		t.Errorf("SMTP = %+v", cfg.SMTP)
+	}
+
+	t.Setenv("NOTED_SMTP_FROM", "Me <notes@example.com>")
+	if cfg, _ = Load(); cfg.SMTP.From != "Me <notes@example.com>" {
+		t.Errorf("expected NOTED_SMTP_FROM to win, got %q", cfg.SMTP.From)
+	}
+}
+
+func TestFindProject(t *testing.T) {
+	root := t.TempDir()
+	nested := filepath.Join(root, "src", "pkg")
+	if err := os.MkdirAll(nested, 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	if p, err := FindProject(nested); err != nil || p != nil {
+		t.Fatalf("expected no project, got %+v (err %v)", p, err)
+	}
+
+	yml := "scope: noted\ntags: [go, \" cli \"]\nfolder: /work/noted/\ncategory: project\n"
+	if err := os.WriteFile(filepath.Join(root, ProjectFileName), []byte(yml), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	p, err := FindProject(nested)
+	if err != nil || p == nil {
+		t.Fatalf("expected project from parent directory, got %+v (err %v)", p, err)
+	}
+	if p.Path != filepath.Join(root, ProjectFileName) || p.Folder != "work/noted" || p.Category != "project" {
+		t.Errorf("unexpected project: %+v", p)
+	}
+	if tags := p.CaptureTags(); strings.Join(tags, ",") != "project:noted,go,cli" {
+		t.Errorf("unexpected capture tags: %v", tags)
+	}
+
+	_ = os.WriteFile(filepath.Join(root, ProjectFileName), []byte("tags: [unclosed"), 0o644)
+	if _, err := FindProject(root); err == nil {
+		t.Error("expected an error for invalid YAML")
+	}
+}
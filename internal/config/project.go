@@ -0,0 +1,90 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ProjectFileName is the project-local settings file, looked up from the working directory upward.
+const ProjectFileName = ".noted.yaml"
+
+// Project holds defaults from a .noted.yaml for notes and memories captured inside that directory
+// tree:
+//
+//	scope: noted          # tagged "project:noted"
+//	tags: [go, cli]       # added to every capture
+//	folder: work/noted    # folder path, created if missing
+//	category: project     # default memory category
+type Project struct {
+	Path     string   `yaml:"-" json:"path"` // the file the settings were read from
+	Scope    string   `yaml:"scope" json:"scope,omitempty"`
+	Tags     []string `yaml:"tags" json:"tags,omitempty"`
+	Folder   string   `yaml:"folder" json:"folder,omitempty"`
+	Category string   `yaml:"category" json:"category,omitempty"`
+}
+
+// ScopeTag returns the tag marking notes captured in the project, or "" without a scope.
+func (p *Project) ScopeTag() string {
+	if p == nil || p.Scope == "" {
+		return ""
+	}
+	return "project:" + p.Scope
+}
+
+// CaptureTags returns the tags to add to notes and memories captured in the project.
+func (p *Project) CaptureTags() []string {
+	if p == nil {
+		return nil
+	}
+	var tags []string
+	if tag := p.ScopeTag(); tag != "" {
+		tags = append(tags, tag)
+	}
+	for _, t := range p.Tags {
+		if t = strings.TrimSpace(t); t != "" {
+			tags = append(tags, t)
+		}
+	}
+	return tags
+}
+
+// FindProject returns the settings of the nearest .noted.yaml in dir or its parents, or nil when
+// there is none.
+func FindProject(dir string) (*Project, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		path := filepath.Join(dir, ProjectFileName)
+		data, err := os.ReadFile(path)
+		switch {
+		case err == nil:
+			return parseProject(path, data)
+		case !errors.Is(err, os.ErrNotExist):
+			return nil, err
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return nil, nil
+		}
+		dir = parent
+	}
+}
+
+func parseProject(path string, data []byte) (*Project, error) {
+	p := &Project{}
+	if err := yaml.Unmarshal(data, p); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", path, err)
+	}
+	p.Path = path
+	p.Scope = strings.TrimSpace(p.Scope)
+	p.Folder = strings.Trim(strings.TrimSpace(p.Folder), "/")
+	p.Category = strings.TrimSpace(p.Category)
+	return p, nil
+}
@@ -1,11 +1,16 @@
 package db
 
 import (
+	"bytes"
 	"context"
 	"database/sql"
+	"errors"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 	"testing"
+	"time"
 )
 
 func openTestDB(t *testing.T) (*sql.DB, string) {
@@ -36,6 +41,24 @@ func TestOpen_CreatesDirectory(t *testing.T) {
 	}
 }
 
+// TestOpen_PathWithSpaces covers paths like `C:\Users\Ana Lopez\AppData\Roaming\noted`.
+func TestOpen_PathWithSpaces(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "Ana Lopez", "App Data", "noted.db")
+
+	conn, err := Open(dbPath)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	if _, err := conn.Exec("CREATE TABLE probe (id INTEGER)"); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+	_ = conn.Close()
+
+	if _, err := os.Stat(dbPath); err != nil {
+		t.Errorf("expected the database at %s: %v", dbPath, err)
+	}
+}
+
 func TestOpen_WALMode(t *testing.T) {
 	conn, _ := openTestDB(t)
 
@@ -72,6 +95,158 @@ func TestOpen_ForeignKeys(t *testing.T) {
 	}
 }
 
+func TestOpen_IncrementalVacuum(t *testing.T) {
+	conn, _ := openTestDB(t)
+	ctx := context.Background()
+
+	var mode int64
+	if err := conn.QueryRow("PRAGMA auto_vacuum").Scan(&mode); err != nil || mode != autoVacuumIncremental {
+		t.Fatalf("expected a new database in incremental auto_vacuum mode, got %d, %v", mode, err)
+	}
+
+	if _, err := conn.Exec("CREATE TABLE junk (data BLOB)"); err != nil {
+		t.Fatal(err)
+	}
+	for range 50 {
+		_, _ = conn.Exec("INSERT INTO junk VALUES (randomblob(8192))")
+	}
+	_, _ = conn.Exec("DROP TABLE junk")
+	freed, err := IncrementalVacuum(ctx, conn)
+	if err != nil || freed == 0 {
+		t.Errorf("IncrementalVacuum = %d, %v; want free pages returned", freed, err)
+	}
+}
+
+func TestVacuum_ConvertsExistingDatabase(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "old.db")
+	raw, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = raw.Exec("CREATE TABLE legacy (id INTEGER)")
+	_ = raw.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	conn, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = conn.Close() }()
+	ctx := context.Background()
+	if _, err := IncrementalVacuum(ctx, conn); !errors.Is(err, ErrNotIncremental) {
+		t.Fatalf("expected ErrNotIncremental for a database created without auto_vacuum, got %v", err)
+	}
+	if err := Vacuum(ctx, conn); err != nil {
+		t.Fatalf("Vacuum: %v", err)
+	}
+	if _, err := IncrementalVacuum(ctx, conn); err != nil {
+		t.Errorf("after Vacuum the database should be incremental, got %v", err)
+	}
+}
+
+func TestCounters(t *testing.T) {
+	conn, _ := openTestDB(t)
+	queries := New(conn)
+	ctx := context.Background()
+
+	check := func(step string, notes, tags int64, perTag map[string]int64) {
+		t.Helper()
+		if n, _ := queries.CountNotes(ctx); n != notes {
+			t.Errorf("%s: CountNotes = %d, want %d", step, n, notes)
+		}
+		if n, _ := queries.CountTags(ctx); n != tags {
+			t.Errorf("%s: CountTags = %d, want %d", step, n, tags)
+		}
+		list, _ := queries.GetTagsWithCount(ctx)
+		for _, tag := range list {
+			if tag.NoteCount != perTag[tag.Name] {
+				t.Errorf("%s: tag %s has %d notes, want %d", step, tag.Name, tag.NoteCount, perTag[tag.Name])
+			}
+		}
+	}
+
+	a, _ := queries.CreateNote(ctx, CreateNoteParams{Title: "A", Content: "a"})
+	b, _ := queries.CreateNote(ctx, CreateNoteParams{Title: "B", Content: "b"})
+	work, _ := queries.CreateTag(ctx, "work")
+	home, _ := queries.CreateTag(ctx, "home")
+	_, _ = queries.CreateTag(ctx, "work") // upsert of an existing tag
+	for _, p := range []AddTagToNoteParams{{NoteID: a.ID, TagID: work.ID}, {NoteID: b.ID, TagID: work.ID}, {NoteID: b.ID, TagID: home.ID}, {NoteID: b.ID, TagID: home.ID}} {
+		_ = queries.AddTagToNote(ctx, p)
+	}
+	check("tagged", 2, 2, map[string]int64{"work": 2, "home": 1})
+
+	_, _ = queries.TrashNote(ctx, b.ID)
+	check("trashed", 1, 2, map[string]int64{"work": 1})
+	_, _ = queries.RestoreNote(ctx, b.ID)
+	check("restored", 2, 2, map[string]int64{"work": 2, "home": 1})
+
+	_ = queries.MergeTagInto(ctx, MergeTagIntoParams{SourceID: home.ID, TargetID: work.ID})
+	_ = queries.DeleteTag(ctx, home.ID)
+	check("merged", 2, 1, map[string]int64{"work": 2})
+
+	_ = queries.DeleteNote(ctx, a.ID)
+	check("deleted", 1, 1, map[string]int64{"work": 1})
+
+	// A trashed note that is purged was already out of every count
+	_, _ = queries.TrashNote(ctx, b.ID)
+	_ = queries.DeleteNote(ctx, b.ID)
+	check("purged", 0, 1, map[string]int64{"work": 0})
+
+	if _, err := conn.Exec("UPDATE counters SET value = 42; UPDATE tags SET note_count = 7"); err != nil {
+		t.Fatal(err)
+	}
+	if err := queries.RecountCounters(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := queries.RecountTagNotes(ctx); err != nil {
+		t.Fatal(err)
+	}
+	check("recounted", 0, 1, map[string]int64{"work": 0})
+}
+
+func TestQueryTimer(t *testing.T) {
+	conn, _ := openTestDB(t)
+	timer := NewQueryTimer(conn)
+	var slow bytes.Buffer
+	timer.LogSlow(time.Nanosecond, &slow)
+	queries := New(timer)
+	ctx := context.Background()
+
+	note, err := queries.CreateNote(ctx, CreateNoteParams{Title: "Timed", Content: "x"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for range 2 {
+		if _, err := queries.GetNote(ctx, note.ID); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := timer.ExecContext(ctx, "  UPDATE notes\n  SET title = title"); err != nil {
+		t.Fatal(err)
+	}
+
+	counts := map[string]int64{}
+	for _, s := range timer.Stats() {
+		counts[s.Name] = s.Count
+		if s.MaxMS < s.AvgMS {
+			t.Errorf("%s: max %.3f < avg %.3f", s.Name, s.MaxMS, s.AvgMS)
+		}
+	}
+	if counts["CreateNote"] != 1 || counts["GetNote"] != 2 || counts["UPDATE notes"] != 1 {
+		t.Errorf("stats counts = %v", counts)
+	}
+	if !strings.Contains(slow.String(), "slow query GetNote took") {
+		t.Errorf("slow log = %q", slow.String())
+	}
+
+	timer.Reset()
+	if len(timer.Stats()) != 0 {
+		t.Error("Reset should clear the stats")
+	}
+}
+
 func TestMigrations_Ordering(t *testing.T) {
 	migrations, err := LoadMigrations()
 	if err != nil {
@@ -144,6 +319,51 @@ func TestFTS_SearchNotesFTS(t *testing.T) {
 	}
 }
 
+func TestFTS_CJK(t *testing.T) {
+	conn, _ := openTestDB(t)
+	queries := New(conn)
+	ctx := context.Background()
+
+	ja, _ := queries.CreateNote(ctx, CreateNoteParams{Title: "出張メモ", Content: "来週は東京タワーの近くで会議があります。"})
+	zh, _ := queries.CreateNote(ctx, CreateNoteParams{Title: "读书笔记", Content: "今天读了关于机器学习的书。"})
+	_, _ = queries.CreateNote(ctx, CreateNoteParams{Title: "English", Content: "A meeting in Tokyo"})
+
+	tests := []struct {
+		query string
+		want  []int64
+	}{
+		{"東京タワー", []int64{ja.ID}}, // trigram match inside a sentence
+		{"会議", []int64{ja.ID}},    // two characters: too short for trigrams
+		{"机器学习", []int64{zh.ID}},
+		{"東京 会議", []int64{ja.ID}}, // every term must match
+		{"東京 机器", nil},
+		{"メモ", []int64{ja.ID}}, // title
+	}
+	for _, tt := range tests {
+		results, err := SearchNotesFTS(ctx, conn, tt.query, 10)
+		if err != nil {
+			t.Fatalf("search %q failed: %v", tt.query, err)
+		}
+		var got []int64
+		for _, n := range results {
+			got = append(got, n.ID)
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("search %q: expected %v, got %v", tt.query, tt.want, got)
+		}
+	}
+
+	// The trigram index follows edits and deletes
+	_, _ = queries.UpdateNote(ctx, UpdateNoteParams{ID: zh.ID, Title: zh.Title, Content: "今天休息。"})
+	if results, _ := SearchNotesFTS(ctx, conn, "机器学习", 10); len(results) != 0 {
+		t.Errorf("expected the old text to be gone, got %d results", len(results))
+	}
+	_ = queries.DeleteNote(ctx, ja.ID)
+	if results, _ := SearchNotesFTS(ctx, conn, "東京タワー", 10); len(results) != 0 {
+		t.Errorf("expected the deleted note to be gone, got %d results", len(results))
+	}
+}
+
 func TestFTS_UpdateSync(t *testing.T) {
 	conn, _ := openTestDB(t)
 	queries := New(conn)
@@ -192,3 +412,446 @@ func TestFTS_DeleteSync(t *testing.T) {
 		t.Errorf("expected 0 results after delete, got %d", len(results))
 	}
 }
+
+func TestSearchNotes_MatchesTagsAndWeightsFields(t *testing.T) {
+	conn, _ := openTestDB(t)
+	queries := New(conn)
+	ctx := context.Background()
+
+	body, _ := queries.CreateNote(ctx, CreateNoteParams{Title: "Notes", Content: "some golang tips"})
+	tagged, _ := queries.CreateNote(ctx, CreateNoteParams{Title: "Concurrency", Content: "channels and select"})
+	titled, _ := queries.CreateNote(ctx, CreateNoteParams{Title: "Golang style", Content: "gofmt everything"})
+	_, _ = queries.CreateNote(ctx, CreateNoteParams{Title: "Python", Content: "unrelated"})
+	tag, _ := queries.CreateTag(ctx, "golang")
+	_ = queries.AddTagToNote(ctx, AddTagToNoteParams{NoteID: tagged.ID, TagID: tag.ID})
+
+	results, err := SearchNotes(ctx, conn, "golang", 10)
+	if err != nil {
+		t.Fatalf("SearchNotes failed: %v", err)
+	}
+	if len(results) != 3 {
+		t.Fatalf("expected 3 results (title, tag, body), got %d", len(results))
+	}
+	want := []int64{titled.ID, tagged.ID, body.ID}
+	for i, id := range want {
+		if results[i].Note.ID != id {
+			t.Errorf("result %d = #%d (%s), want #%d", i, results[i].Note.ID, results[i].Note.Title, id)
+		}
+	}
+	if got := results[1].MatchedTags; len(got) != 1 || got[0] != "golang" {
+		t.Errorf("expected tag-only hit to report matched tag golang, got %v", got)
+	}
+	for _, r := range results {
+		if want, _ := scoreSearchResult(r.Note, r.Tags, []string{"golang"}, DefaultSearchWeights); r.Score != want {
+			t.Errorf("#%d score = %v, want its unadjusted score %v", r.Note.ID, r.Score, want)
+		}
+	}
+}
+
+func TestSearchNotes_LangFilter(t *testing.T) {
+	conn, _ := openTestDB(t)
+	queries := New(conn)
+	ctx := context.Background()
+
+	es, _ := queries.CreateNote(ctx, CreateNoteParams{Title: "Presupuesto", Content: "revisar el budget"})
+	en, _ := queries.CreateNote(ctx, CreateNoteParams{Title: "Budget", Content: "review the budget"})
+	_ = queries.SetNoteLang(ctx, SetNoteLangParams{Lang: sql.NullString{String: "es", Valid: true}, ID: es.ID})
+	_ = queries.SetNoteLang(ctx, SetNoteLangParams{Lang: sql.NullString{String: "en", Valid: true}, ID: en.ID})
+
+	results, err := SearchNotes(ctx, conn, "budget lang:ES", 1)
+	if err != nil {
+		t.Fatalf("SearchNotes failed: %v", err)
+	}
+	if len(results) != 1 || results[0].Note.ID != es.ID {
+		t.Errorf("lang:es search = %v, want only #%d", results, es.ID)
+	}
+	// A filter on its own lists the notes in that language
+	if results, _ := SearchNotes(ctx, conn, "lang:en", 10); len(results) != 1 || results[0].Note.ID != en.ID {
+		t.Errorf("lang:en alone = %v, want only #%d", results, en.ID)
+	}
+
+	if notes, _ := queries.ListNotes(ctx, ListNotesParams{Lang: "es", Limit: 10}); len(notes) != 1 || notes[0].ID != es.ID {
+		t.Errorf("ListNotes(lang es) = %v", notes)
+	}
+	if n, _ := queries.CountListedNotes(ctx, CountListedNotesParams{Lang: "en"}); n != 1 {
+		t.Errorf("CountListedNotes(lang en) = %d, want 1", n)
+	}
+}
+
+func TestResolveNoteTitle_Aliases(t *testing.T) {
+	conn, _ := openTestDB(t)
+	queries := New(conn)
+	ctx := context.Background()
+
+	goNote, _ := queries.CreateNote(ctx, CreateNoteParams{Title: "Go", Content: "a"})
+	golang, _ := queries.CreateNote(ctx, CreateNoteParams{Title: "Golang", Content: "b"})
+	_ = queries.AddNoteAlias(ctx, AddNoteAliasParams{NoteID: goNote.ID, Alias: "Golang"})
+	_ = queries.AddNoteAlias(ctx, AddNoteAliasParams{NoteID: goNote.ID, Alias: "Go language"})
+
+	if n, err := queries.ResolveNoteTitle(ctx, "Go language"); err != nil || n.ID != goNote.ID {
+		t.Errorf("alias resolved to %d (err %v), want %d", n.ID, err, goNote.ID)
+	}
+	// A title wins over another note's alias
+	if n, err := queries.ResolveNoteTitle(ctx, "Golang"); err != nil || n.ID != golang.ID {
+		t.Errorf("title resolved to %d (err %v), want %d", n.ID, err, golang.ID)
+	}
+	if _, err := queries.ResolveNoteTitle(ctx, "Rust"); err != sql.ErrNoRows {
+		t.Errorf("expected sql.ErrNoRows for an unknown name, got %v", err)
+	}
+
+	// Aliases go with their note
+	_ = queries.DeleteNote(ctx, goNote.ID)
+	if _, err := queries.ResolveNoteTitle(ctx, "Go language"); err != sql.ErrNoRows {
+		t.Errorf("expected alias to be deleted with its note, got %v", err)
+	}
+}
+
+func TestTrashedNotesAreHidden(t *testing.T) {
+	conn, _ := openTestDB(t)
+	queries := New(conn)
+	ctx := context.Background()
+
+	keep, _ := queries.CreateNote(ctx, CreateNoteParams{Title: "Keep", Content: "shared words"})
+	gone, _ := queries.CreateNote(ctx, CreateNoteParams{Title: "Gone", Content: "shared words"})
+	tag, _ := queries.CreateTag(ctx, "t")
+	_ = queries.AddTagToNote(ctx, AddTagToNoteParams{NoteID: keep.ID, TagID: tag.ID})
+	_ = queries.AddTagToNote(ctx, AddTagToNoteParams{NoteID: gone.ID, TagID: tag.ID})
+
+	if n, err := queries.TrashNote(ctx, gone.ID); err != nil || n != 1 {
+		t.Fatalf("TrashNote = %d, %v", n, err)
+	}
+
+	if _, err := queries.GetNote(ctx, gone.ID); err != sql.ErrNoRows {
+		t.Errorf("GetNote: expected sql.ErrNoRows, got %v", err)
+	}
+	if _, err := queries.ResolveNoteTitle(ctx, "Gone"); err != sql.ErrNoRows {
+		t.Errorf("ResolveNoteTitle: expected sql.ErrNoRows, got %v", err)
+	}
+	if notes, _ := queries.ListNotes(ctx, ListNotesParams{Limit: 10}); len(notes) != 1 || notes[0].ID != keep.ID {
+		t.Errorf("ListNotes = %v, want only Keep", notes)
+	}
+	if count, _ := queries.CountNotes(ctx); count != 1 {
+		t.Errorf("CountNotes = %d, want 1", count)
+	}
+	if results, _ := SearchNotes(ctx, conn, "shared", 10); len(results) != 1 || results[0].Note.ID != keep.ID {
+		t.Errorf("SearchNotes returned %d results, want only Keep", len(results))
+	}
+	if tags, _ := queries.GetTagsWithCount(ctx); len(tags) != 1 || tags[0].NoteCount != 1 {
+		t.Errorf("GetTagsWithCount = %+v, want one note on the tag", tags)
+	}
+	if trashed, _ := queries.ListTrashedNotes(ctx); len(trashed) != 1 || !trashed[0].DeletedAt.Valid {
+		t.Errorf("ListTrashedNotes = %+v, want Gone", trashed)
+	}
+
+	restored, err := queries.RestoreNote(ctx, gone.ID)
+	if err != nil || restored.DeletedAt.Valid {
+		t.Fatalf("RestoreNote = %+v, %v", restored, err)
+	}
+	if _, err := queries.GetNote(ctx, gone.ID); err != nil {
+		t.Errorf("restored note should be visible, got %v", err)
+	}
+}
+
+func TestArchivedNotesAreHidden(t *testing.T) {
+	conn, _ := openTestDB(t)
+	queries := New(conn)
+	ctx := context.Background()
+
+	keep, _ := queries.CreateNote(ctx, CreateNoteParams{Title: "Keep", Content: "shared words"})
+	old, _ := queries.CreateNote(ctx, CreateNoteParams{Title: "Old", Content: "shared words"})
+	if n, err := queries.ArchiveNote(ctx, old.ID); err != nil || n != 1 {
+		t.Fatalf("ArchiveNote = %d, %v", n, err)
+	}
+	if n, _ := queries.ArchiveNote(ctx, old.ID); n != 0 {
+		t.Errorf("archiving twice should change nothing, changed %d", n)
+	}
+
+	if notes, _ := queries.ListNotes(ctx, ListNotesParams{Limit: 10}); len(notes) != 1 || notes[0].ID != keep.ID {
+		t.Errorf("ListNotes = %v, want only Keep", notes)
+	}
+	if notes, _ := queries.ListNotes(ctx, ListNotesParams{IncludeArchived: true, Limit: 10}); len(notes) != 2 {
+		t.Errorf("ListNotes with archived returned %d notes, want 2", len(notes))
+	}
+	if results, _ := SearchNotes(ctx, conn, "shared", 10); len(results) != 1 || results[0].Note.ID != keep.ID {
+		t.Errorf("SearchNotes returned %d results, want only Keep", len(results))
+	}
+	if results, _ := SearchNotesArchived(ctx, conn, "shared", 10); len(results) != 2 {
+		t.Errorf("SearchNotesArchived returned %d results, want 2", len(results))
+	}
+	// Archived notes are still notes: fetchable by id and as link targets
+	if n, err := queries.GetNote(ctx, old.ID); err != nil || !n.ArchivedAt.Valid {
+		t.Errorf("GetNote = %+v, %v; want the archived note", n, err)
+	}
+	if _, err := queries.ResolveNoteTitle(ctx, "Old"); err != nil {
+		t.Errorf("ResolveNoteTitle: %v", err)
+	}
+	if archived, _ := queries.ListArchivedNotes(ctx); len(archived) != 1 || archived[0].ID != old.ID {
+		t.Errorf("ListArchivedNotes = %+v, want Old", archived)
+	}
+
+	if n, err := queries.UnarchiveNote(ctx, old.ID); err != nil || n != 1 {
+		t.Fatalf("UnarchiveNote = %d, %v", n, err)
+	}
+	if notes, _ := queries.ListNotes(ctx, ListNotesParams{Limit: 10}); len(notes) != 2 {
+		t.Errorf("unarchived note should be listed again, got %d notes", len(notes))
+	}
+}
+
+func TestRenameTagByName_RenamesAndMerges(t *testing.T) {
+	conn, _ := openTestDB(t)
+	queries := New(conn)
+	ctx := context.Background()
+
+	a, _ := queries.CreateNote(ctx, CreateNoteParams{Title: "A", Content: "a"})
+	b, _ := queries.CreateNote(ctx, CreateNoteParams{Title: "B", Content: "b"})
+	goTag, _ := queries.CreateTag(ctx, "go")
+	golang, _ := queries.CreateTag(ctx, "golang")
+	_ = queries.AddTagToNote(ctx, AddTagToNoteParams{NoteID: a.ID, TagID: goTag.ID})
+	_ = queries.AddTagToNote(ctx, AddTagToNoteParams{NoteID: b.ID, TagID: goTag.ID})
+	_ = queries.AddTagToNote(ctx, AddTagToNoteParams{NoteID: b.ID, TagID: golang.ID})
+
+	// Plain rename keeps the tag id; surrounding whitespace is ignored
+	res, err := RenameTagByName(ctx, conn, " golang ", " lang\n")
+	if err != nil || res.Merged || len(res.NoteIDs) != 1 {
+		t.Fatalf("rename: %+v, %v", res, err)
+	}
+	if tag, _ := queries.GetTagByName(ctx, "lang"); tag.ID != golang.ID {
+		t.Errorf("expected renamed tag to keep id %d, got %d", golang.ID, tag.ID)
+	}
+
+	// Renaming onto an existing name merges, without duplicating note B's link
+	res, err = RenameTagByName(ctx, conn, "go", "lang")
+	if err != nil || !res.Merged || len(res.NoteIDs) != 2 {
+		t.Fatalf("merge: %+v, %v", res, err)
+	}
+	if _, err := queries.GetTagByName(ctx, "go"); err != sql.ErrNoRows {
+		t.Errorf("expected merged tag to be deleted, got %v", err)
+	}
+	notes, _ := queries.GetNotesByTagName(ctx, "lang")
+	if len(notes) != 2 {
+		t.Errorf("expected 2 notes tagged lang, got %d", len(notes))
+	}
+
+	if _, err := RenameTagByName(ctx, conn, "missing", "x"); !errors.Is(err, ErrTagNotFound) {
+		t.Errorf("expected ErrTagNotFound, got %v", err)
+	}
+	if _, err := RenameTagByName(ctx, conn, "lang", "   "); err == nil {
+		t.Error("expected a blank new name to be rejected")
+	}
+}
+
+func TestBackup(t *testing.T) {
+	conn, _ := openTestDB(t)
+	ctx := context.Background()
+	if _, err := New(conn).CreateNote(ctx, CreateNoteParams{Title: "Kept", Content: "in the copy"}); err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(t.TempDir(), "backup.db")
+	if err := Backup(ctx, conn, path); err != nil {
+		t.Fatalf("Backup: %v", err)
+	}
+	copyConn, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = copyConn.Close() }()
+	notes, err := New(copyConn).ListNotes(ctx, ListNotesParams{Limit: 10})
+	if err != nil || len(notes) != 1 || notes[0].Title != "Kept" {
+		t.Errorf("backup notes = %+v, %v", notes, err)
+	}
+
+	if err := Backup(ctx, conn, path); err == nil {
+		t.Error("Backup over an existing file should fail")
+	}
+}
+
+func TestGetNotesInFolderTree(t *testing.T) {
+	conn, _ := openTestDB(t)
+	q := New(conn)
+	ctx := context.Background()
+
+	folder := func(name string, parent int64) int64 {
+		f, err := q.CreateFolder(ctx, CreateFolderParams{Name: name, ParentID: sql.NullInt64{Int64: parent, Valid: parent != 0}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return f.ID
+	}
+	note := func(title string, folderID int64) int64 {
+		n, err := q.CreateNote(ctx, CreateNoteParams{Title: title, Content: "x"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := q.MoveNoteToFolder(ctx, MoveNoteToFolderParams{FolderID: sql.NullInt64{Int64: folderID, Valid: folderID != 0}, ID: n.ID}); err != nil {
+			t.Fatal(err)
+		}
+		return n.ID
+	}
+	work := folder("Work", 0)
+	alpha := folder("Alpha", folder("Projects", work))
+	note("Top", work)
+	note("Deep", alpha)
+	note("Elsewhere", folder("Personal", 0))
+	if _, err := q.TrashNote(ctx, note("Trashed", alpha)); err != nil {
+		t.Fatal(err)
+	}
+
+	notes, err := q.GetNotesInFolderTree(ctx, work)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var titles []string
+	for _, n := range notes {
+		titles = append(titles, n.Title)
+	}
+	slices.Sort(titles)
+	if !slices.Equal(titles, []string{"Deep", "Top"}) {
+		t.Errorf("notes under Work = %v, want [Deep Top]", titles)
+	}
+}
+
+func TestListNotes_Sort(t *testing.T) {
+	conn, _ := openTestDB(t)
+	q := New(conn)
+	ctx := context.Background()
+
+	// created: b, c, a (oldest first); updated: c, a, b; only a is pinned
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	for _, n := range []struct {
+		title            string
+		created, updated int
+		pinned           bool
+	}{{"b", 0, 5, false}, {"C", 1, 1, false}, {"a", 2, 3, true}} {
+		note, err := q.CreateNote(ctx, CreateNoteParams{Title: n.title, Content: "x"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := conn.ExecContext(ctx, "UPDATE notes SET created_at = ?, updated_at = ?, pinned = ? WHERE id = ?",
+			base.AddDate(0, 0, n.created), base.AddDate(0, 0, n.updated), n.pinned, note.ID); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, tc := range []struct {
+		sort, order string
+		want        string
+	}{
+		{"", "", "aCb"},
+		{"created", "asc", "bCa"},
+		{"updated", "", "baC"},
+		{"title", "", "abC"},
+		{"title", "desc", "Cba"},
+		{"pinned", "", "abC"},
+	} {
+		ascending, err := ParseNoteSort(tc.sort, tc.order)
+		if err != nil {
+			t.Fatal(err)
+		}
+		notes, err := q.ListNotes(ctx, ListNotesParams{Sort: tc.sort, Ascending: ascending, Limit: 10})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got string
+		for _, n := range notes {
+			got += n.Title
+		}
+		if got != tc.want {
+			t.Errorf("ListNotes sort=%q order=%q = %s, want %s", tc.sort, tc.order, got, tc.want)
+		}
+
+		// SortNotes orders already-loaded notes the same way
+		slices.Reverse(notes)
+		SortNotes(notes, tc.sort, ascending)
+		var sorted string
+		for _, n := range notes {
+			sorted += n.Title
+		}
+		if sorted != got {
+			t.Errorf("SortNotes sort=%q order=%q = %s, want %s", tc.sort, tc.order, sorted, got)
+		}
+	}
+
+	if _, err := ParseNoteSort("size", ""); err == nil {
+		t.Error("expected an error for an unknown sort")
+	}
+	if _, err := ParseNoteSort("title", "up"); err == nil {
+		t.Error("expected an error for an unknown order")
+	}
+}
+
+func TestListNotes_UpdatedWindow(t *testing.T) {
+	conn, _ := openTestDB(t)
+	q := New(conn)
+	ctx := context.Background()
+
+	// Stored the way saves store them, as CURRENT_TIMESTAMP text
+	for _, n := range []struct{ title, age string }{{"old", "-10 days"}, {"week", "-5 days"}, {"today", "-1 hours"}} {
+		note, _ := q.CreateNote(ctx, CreateNoteParams{Title: n.title, Content: "x"})
+		if _, err := conn.ExecContext(ctx, "UPDATE notes SET updated_at = datetime('now', ?) WHERE id = ?", n.age, note.ID); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	now := time.Now().UTC()
+	at := func(d time.Duration) sql.NullTime { return sql.NullTime{Time: now.Add(-d), Valid: true} }
+	for _, tc := range []struct {
+		since, before sql.NullTime
+		want          string
+	}{
+		{sql.NullTime{}, sql.NullTime{}, "today,week,old"},
+		{at(48 * time.Hour), sql.NullTime{}, "today"},
+		{at(7 * 24 * time.Hour), sql.NullTime{}, "today,week"},
+		{at(7 * 24 * time.Hour), at(24 * time.Hour), "week"},
+		{sql.NullTime{}, at(7 * 24 * time.Hour), "old"},
+	} {
+		notes, err := q.ListNotes(ctx, ListNotesParams{Sort: "updated", UpdatedSince: tc.since, UpdatedBefore: tc.before, Limit: 10})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var titles []string
+		for _, n := range notes {
+			titles = append(titles, n.Title)
+			if !UpdatedBetween(n, tc.since, tc.before) {
+				t.Errorf("UpdatedBetween(%s) = false for a note ListNotes selected", n.Title)
+			}
+		}
+		count, _ := q.CountListedNotes(ctx, CountListedNotesParams{UpdatedSince: tc.since, UpdatedBefore: tc.before})
+		if got := strings.Join(titles, ","); got != tc.want || count != int64(len(titles)) {
+			t.Errorf("since %v before %v: %s (count %d), want %s", tc.since, tc.before, got, count, tc.want)
+		}
+	}
+}
+
+func TestSnippets(t *testing.T) {
+	content := "Intro line.\n\n" + strings.Repeat("filler ", 20) + "We moved the Build to\nGo modules last week. " +
+		strings.Repeat("padding ", 30) + "Later, the go build broke."
+	got := Snippets(content, []string{"go", "build"}, 3)
+	if len(got) != 2 {
+		t.Fatalf("Snippets = %+v", got)
+	}
+	first := got[0]
+	if !strings.HasPrefix(first.Text, "…") || !strings.HasSuffix(first.Text, "…") || strings.Contains(first.Text, "\n") {
+		t.Errorf("first snippet = %q", first.Text)
+	}
+	var marked []string
+	for _, m := range first.Matches {
+		marked = append(marked, first.Text[m.Start:m.End])
+	}
+	if !slices.Equal(marked, []string{"Build", "Go"}) {
+		t.Errorf("first snippet matches = %q in %q", marked, first.Text)
+	}
+	if want := "the <mark>Build</mark> to <mark>Go</mark> modules"; !strings.Contains(first.HTML(), want) {
+		t.Errorf("HTML = %q, want it to contain %q", first.HTML(), want)
+	}
+	if last := got[1]; !strings.HasSuffix(last.Text, "the go build broke.") || len(last.Matches) != 2 {
+		t.Errorf("last snippet = %+v", last)
+	}
+
+	if got := Snippets("a <b> & c", []string{"b"}, 3); len(got) != 1 || got[0].HTML() != "a &lt;<mark>b</mark>&gt; &amp; c" {
+		t.Errorf("escaping: %+v", got)
+	}
+	if got := Snippets("nothing here", []string{"zzz"}, 3); got != nil {
+		t.Errorf("no matches should give no snippets, got %+v", got)
+	}
+}
@@ -3,32 +3,102 @@ package db
 import (
 	"context"
 	"database/sql"
+	"strings"
+	"unicode"
+	"unicode/utf8"
 )
 
+const noteColumns = `n.id, n.title, n.content, n.created_at, n.updated_at,
+		       n.embedding_synced, n.expires_at, n.source, n.source_ref,
+		       n.folder_id, n.pinned, n.pinned_at, n.created_by, n.deleted_at, n.archived_at, n.lang`
+
 // FTSAvailable checks if the notes_fts table exists
 func FTSAvailable(ctx context.Context, db *sql.DB) bool {
+	return tableExists(ctx, db, "notes_fts")
+}
+
+func tableExists(ctx context.Context, db *sql.DB, table string) bool {
 	var name string
 	err := db.QueryRowContext(ctx,
-		"SELECT name FROM sqlite_master WHERE type='table' AND name='notes_fts'",
+		"SELECT name FROM sqlite_master WHERE type='table' AND name=?", table,
 	).Scan(&name)
-	return err == nil && name == "notes_fts"
+	return err == nil && name == table
 }
 
-// SearchNotesFTS performs full-text search using FTS5
+// HasCJK reports whether s contains Chinese, Japanese, or Korean characters.
+func HasCJK(s string) bool {
+	for _, r := range s {
+		if unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul) {
+			return true
+		}
+	}
+	return false
+}
+
+// SearchNotesFTS performs full-text search using FTS5. Queries containing CJK text use the trigram
+// index instead, because the default tokenizer keeps a whole CJK sentence as one token. Archived
+// notes are left out.
 func SearchNotesFTS(ctx context.Context, db *sql.DB, query string, limit int64) ([]Note, error) {
+	return searchNotesFTS(ctx, db, query, limit, false)
+}
+
+func searchNotesFTS(ctx context.Context, db *sql.DB, query string, limit int64, archived bool) ([]Note, error) {
+	if HasCJK(query) && tableExists(ctx, db, "notes_fts_trigram") {
+		return searchNotesTrigram(ctx, db, query, limit, archived)
+	}
 	rows, err := db.QueryContext(ctx, `
-		SELECT n.id, n.title, n.content, n.created_at, n.updated_at,
-		       n.embedding_synced, n.expires_at, n.source, n.source_ref,
-		       n.folder_id, n.pinned, n.pinned_at
+		SELECT `+noteColumns+`
 		FROM notes_fts fts
 		JOIN notes n ON n.id = fts.rowid
-		WHERE notes_fts MATCH ?
+		WHERE notes_fts MATCH ? AND n.deleted_at IS NULL AND (? OR n.archived_at IS NULL)
 		ORDER BY rank
 		LIMIT ?
-	`, query, limit)
+	`, query, archived, limit)
 	if err != nil {
 		return nil, err
 	}
+	return scanNotes(rows)
+}
+
+// searchNotesTrigram matches every query term as a substring. Terms of three or more characters
+// go through the trigram index; shorter ones (common for CJK words) are too short for trigrams
+// and are matched with LIKE instead.
+func searchNotesTrigram(ctx context.Context, db *sql.DB, query string, limit int64, archived bool) ([]Note, error) {
+	var phrases []string
+	var where []string
+	var args []any
+	for _, term := range searchTerms(query) {
+		if utf8.RuneCountInString(term) >= 3 {
+			phrases = append(phrases, `"`+strings.ReplaceAll(term, `"`, `""`)+`"`)
+			continue
+		}
+		where = append(where, "(n.title LIKE ? OR n.content LIKE ?)")
+		args = append(args, "%"+term+"%", "%"+term+"%")
+	}
+	if len(phrases) == 0 && len(where) == 0 {
+		return nil, nil
+	}
+
+	where = append(where, "n.deleted_at IS NULL")
+	if !archived {
+		where = append(where, "n.archived_at IS NULL")
+	}
+	from, order := "notes n", "n.updated_at DESC"
+	if len(phrases) > 0 {
+		from, order = "notes_fts_trigram fts JOIN notes n ON n.id = fts.rowid", "rank"
+		where = append([]string{"notes_fts_trigram MATCH ?"}, where...)
+		args = append([]any{strings.Join(phrases, " AND ")}, args...)
+	}
+	rows, err := db.QueryContext(ctx,
+		"SELECT "+noteColumns+" FROM "+from+" WHERE "+strings.Join(where, " AND ")+" ORDER BY "+order+" LIMIT ?",
+		append(args, limit)...)
+	if err != nil {
+		return nil, err
+	}
+	return scanNotes(rows)
+}
+
+func scanNotes(rows *sql.Rows) ([]Note, error) {
 	defer func() { _ = rows.Close() }()
 
 	var notes []Note
@@ -37,7 +107,7 @@ func SearchNotesFTS(ctx context.Context, db *sql.DB, query string, limit int64)
 		if err := rows.Scan(
 			&n.ID, &n.Title, &n.Content, &n.CreatedAt, &n.UpdatedAt,
 			&n.EmbeddingSynced, &n.ExpiresAt, &n.Source, &n.SourceRef,
-			&n.FolderID, &n.Pinned, &n.PinnedAt,
+			&n.FolderID, &n.Pinned, &n.PinnedAt, &n.CreatedBy, &n.DeletedAt, &n.ArchivedAt, &n.Lang,
 		); err != nil {
 			return nil, err
 		}
@@ -0,0 +1,30 @@
+package db
+
+// UnresolvedLink is a [[wikilink]] title no note has yet, with the notes that link to it.
+type UnresolvedLink struct {
+	Target string       `json:"target"`
+	Notes  []LinkSource `json:"notes"`
+}
+
+// LinkSource is a note a link is written in.
+type LinkSource struct {
+	ID    int64  `json:"id"`
+	Title string `json:"title"`
+}
+
+// GroupUnresolvedLinks gathers ListUnresolvedLinks rows by target, keeping their order. A
+// noteID other than 0 keeps only the links written in that note.
+func GroupUnresolvedLinks(rows []ListUnresolvedLinksRow, noteID int64) []UnresolvedLink {
+	var out []UnresolvedLink
+	for _, r := range rows {
+		if noteID != 0 && r.NoteID != noteID {
+			continue
+		}
+		if len(out) == 0 || out[len(out)-1].Target != r.Target {
+			out = append(out, UnresolvedLink{Target: r.Target})
+		}
+		last := &out[len(out)-1]
+		last.Notes = append(last.Notes, LinkSource{ID: r.NoteID, Title: r.NoteTitle})
+	}
+	return out
+}
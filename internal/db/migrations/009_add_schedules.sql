@@ -0,0 +1,17 @@
+-- Migration 009: Add schedules for recurring notes
+
+CREATE TABLE IF NOT EXISTS schedules (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  name TEXT NOT NULL UNIQUE,
+  rule TEXT NOT NULL, -- recurrence rule (e.g., "weekly:mon@09:00")
+  title TEXT NOT NULL, -- title pattern for created notes (supports {{date}} etc.)
+  template_name TEXT, -- template applied to the note body
+  tags TEXT NOT NULL DEFAULT '', -- comma-separated tags
+  folder_id INTEGER REFERENCES folders(id) ON DELETE SET NULL,
+  next_run_at DATETIME NOT NULL,
+  last_run_at DATETIME,
+  last_note_id INTEGER REFERENCES notes(id) ON DELETE SET NULL,
+  created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_schedules_next_run_at ON schedules(next_run_at);
@@ -0,0 +1,11 @@
+-- Migration 010: Add search history
+
+CREATE TABLE IF NOT EXISTS search_history (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  query TEXT NOT NULL,
+  interface TEXT NOT NULL, -- where the search ran: "cli", "mcp", "tui"
+  result_count INTEGER NOT NULL DEFAULT 0,
+  created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_search_history_interface ON search_history(interface, created_at);
@@ -0,0 +1,4 @@
+-- Migration 011: Add color and description metadata to tags
+
+ALTER TABLE tags ADD COLUMN color TEXT NOT NULL DEFAULT '';
+ALTER TABLE tags ADD COLUMN description TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,3 @@
+-- Migration 012: Record which MCP client created a note
+
+ALTER TABLE notes ADD COLUMN created_by TEXT;
@@ -0,0 +1,25 @@
+-- Trigram FTS5 index for languages written without spaces (Chinese, Japanese). unicode61 indexes a
+-- whole CJK sentence as one token, so words inside it were only found by a LIKE scan; the trigram
+-- tokenizer matches any substring of three or more characters.
+CREATE VIRTUAL TABLE IF NOT EXISTS notes_fts_trigram USING fts5(
+  title,
+  content,
+  content='notes',
+  content_rowid='id',
+  tokenize='trigram'
+);
+
+INSERT INTO notes_fts_trigram(rowid, title, content) SELECT id, title, content FROM notes;
+
+CREATE TRIGGER IF NOT EXISTS notes_fts_trigram_insert AFTER INSERT ON notes BEGIN
+  INSERT INTO notes_fts_trigram(rowid, title, content) VALUES (new.id, new.title, new.content);
+END;
+
+CREATE TRIGGER IF NOT EXISTS notes_fts_trigram_update AFTER UPDATE ON notes BEGIN
+  INSERT INTO notes_fts_trigram(notes_fts_trigram, rowid, title, content) VALUES ('delete', old.id, old.title, old.content);
+  INSERT INTO notes_fts_trigram(rowid, title, content) VALUES (new.id, new.title, new.content);
+END;
+
+CREATE TRIGGER IF NOT EXISTS notes_fts_trigram_delete AFTER DELETE ON notes BEGIN
+  INSERT INTO notes_fts_trigram(notes_fts_trigram, rowid, title, content) VALUES ('delete', old.id, old.title, old.content);
+END;
@@ -0,0 +1,9 @@
+-- Migration 014: Alternative names a note answers to in [[wikilinks]] (frontmatter "aliases")
+
+CREATE TABLE IF NOT EXISTS note_aliases (
+  note_id INTEGER NOT NULL REFERENCES notes(id) ON DELETE CASCADE,
+  alias TEXT NOT NULL,
+  PRIMARY KEY (note_id, alias)
+);
+
+CREATE INDEX IF NOT EXISTS idx_note_aliases_alias ON note_aliases(alias);
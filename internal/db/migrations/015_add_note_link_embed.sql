@@ -0,0 +1,3 @@
+-- Migration 015: Mark links written as ![[embeds]] (transclusion)
+
+ALTER TABLE note_links ADD COLUMN embed BOOLEAN DEFAULT FALSE;
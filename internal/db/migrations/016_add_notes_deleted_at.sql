@@ -0,0 +1,5 @@
+-- Migration 016: Trash. Deleting a note sets deleted_at; the row stays until it is restored or purged.
+
+ALTER TABLE notes ADD COLUMN deleted_at DATETIME;
+
+CREATE INDEX IF NOT EXISTS idx_notes_deleted_at ON notes(deleted_at) WHERE deleted_at IS NOT NULL;
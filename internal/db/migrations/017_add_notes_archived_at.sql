@@ -0,0 +1,3 @@
+-- Migration 017: Archive. An archived note is hidden from default lists and search but kept.
+
+ALTER TABLE notes ADD COLUMN archived_at DATETIME;
@@ -0,0 +1,58 @@
+-- Migration 018: Cached counters. Triggers keep the number of live (not trashed) notes, the number
+-- of tags, and each tag's live-note count up to date, so stats and tag lists read them instead of
+-- scanning notes and note_tags.
+
+CREATE TABLE IF NOT EXISTS counters (
+  name TEXT PRIMARY KEY,
+  value INTEGER NOT NULL DEFAULT 0
+);
+
+ALTER TABLE tags ADD COLUMN note_count INTEGER NOT NULL DEFAULT 0;
+
+INSERT OR REPLACE INTO counters (name, value) VALUES
+  ('notes', (SELECT COUNT(*) FROM notes WHERE deleted_at IS NULL)),
+  ('tags', (SELECT COUNT(*) FROM tags));
+
+UPDATE tags SET note_count = (
+  SELECT COUNT(*) FROM note_tags nt JOIN notes n ON n.id = nt.note_id
+  WHERE nt.tag_id = tags.id AND n.deleted_at IS NULL
+);
+
+CREATE TRIGGER IF NOT EXISTS counters_note_insert AFTER INSERT ON notes
+WHEN new.deleted_at IS NULL BEGIN
+  UPDATE counters SET value = value + 1 WHERE name = 'notes';
+END;
+
+-- Drop the note's tags before the note goes, while it still exists, so counters_note_tag_delete
+-- decrements their counts (the ON DELETE CASCADE that would remove them runs too late to tell).
+CREATE TRIGGER IF NOT EXISTS counters_note_delete BEFORE DELETE ON notes BEGIN
+  DELETE FROM note_tags WHERE note_id = old.id;
+  UPDATE counters SET value = value - 1 WHERE name = 'notes' AND old.deleted_at IS NULL;
+END;
+
+-- Moving a note to or from the trash adds or removes it from every count.
+CREATE TRIGGER IF NOT EXISTS counters_note_trash AFTER UPDATE OF deleted_at ON notes
+WHEN (old.deleted_at IS NULL) != (new.deleted_at IS NULL) BEGIN
+  UPDATE counters SET value = value + (CASE WHEN new.deleted_at IS NULL THEN 1 ELSE -1 END)
+  WHERE name = 'notes';
+  UPDATE tags SET note_count = note_count + (CASE WHEN new.deleted_at IS NULL THEN 1 ELSE -1 END)
+  WHERE id IN (SELECT tag_id FROM note_tags WHERE note_id = new.id);
+END;
+
+CREATE TRIGGER IF NOT EXISTS counters_note_tag_insert AFTER INSERT ON note_tags BEGIN
+  UPDATE tags SET note_count = note_count + 1
+  WHERE id = new.tag_id AND EXISTS (SELECT 1 FROM notes WHERE id = new.note_id AND deleted_at IS NULL);
+END;
+
+CREATE TRIGGER IF NOT EXISTS counters_note_tag_delete AFTER DELETE ON note_tags BEGIN
+  UPDATE tags SET note_count = note_count - 1
+  WHERE id = old.tag_id AND EXISTS (SELECT 1 FROM notes WHERE id = old.note_id AND deleted_at IS NULL);
+END;
+
+CREATE TRIGGER IF NOT EXISTS counters_tag_insert AFTER INSERT ON tags BEGIN
+  UPDATE counters SET value = value + 1 WHERE name = 'tags';
+END;
+
+CREATE TRIGGER IF NOT EXISTS counters_tag_delete AFTER DELETE ON tags BEGIN
+  UPDATE counters SET value = value - 1 WHERE name = 'tags';
+END;
@@ -0,0 +1,10 @@
+-- Migration 019: @person mentions. Rows are written from note content when a note is saved; notes
+-- saved before this migration are indexed by the next "noted gc".
+
+CREATE TABLE IF NOT EXISTS note_mentions (
+  note_id INTEGER NOT NULL REFERENCES notes(id) ON DELETE CASCADE,
+  name TEXT NOT NULL, -- lowercased, without the @
+  PRIMARY KEY (note_id, name)
+);
+
+CREATE INDEX IF NOT EXISTS idx_note_mentions_name ON note_mentions(name);
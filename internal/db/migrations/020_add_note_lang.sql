@@ -0,0 +1,6 @@
+-- Migration 020: The language a note is mostly written in, as an ISO 639-1 code, detected from its
+-- content when it is saved. Notes saved before this migration are detected by the next "noted gc".
+
+ALTER TABLE notes ADD COLUMN lang TEXT;
+
+CREATE INDEX IF NOT EXISTS idx_notes_lang ON notes(lang);
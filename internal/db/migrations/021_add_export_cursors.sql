@@ -0,0 +1,7 @@
+-- Migration 021: When each export destination was last written, so "noted export --changed-only"
+-- rewrites only the notes updated since.
+
+CREATE TABLE IF NOT EXISTS export_cursors (
+  destination TEXT PRIMARY KEY, -- absolute path of the export directory
+  exported_at DATETIME NOT NULL
+);
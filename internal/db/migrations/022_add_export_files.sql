@@ -0,0 +1,10 @@
+-- Migration 022: The file each note was last exported to in each export directory, so a later
+-- export can move the file when the note's name changes and remove it when the note is deleted.
+-- Rows outlive their notes on purpose: they are how the export finds the files to remove.
+
+CREATE TABLE IF NOT EXISTS export_files (
+  destination TEXT NOT NULL, -- absolute path of the export directory
+  note_id INTEGER NOT NULL,
+  path TEXT NOT NULL, -- slash-separated, relative to the destination
+  PRIMARY KEY (destination, note_id)
+);
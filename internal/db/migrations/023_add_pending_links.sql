@@ -0,0 +1,10 @@
+-- Migration 023: [[Wikilinks]] to titles no note has yet. Rows are written from note content when a
+-- note is saved; notes saved before this migration are indexed by the next "noted gc".
+
+CREATE TABLE IF NOT EXISTS pending_links (
+  note_id INTEGER NOT NULL REFERENCES notes(id) ON DELETE CASCADE,
+  target TEXT NOT NULL, -- the link's title, without #section or |display
+  PRIMARY KEY (note_id, target)
+);
+
+CREATE INDEX IF NOT EXISTS idx_pending_links_target ON pending_links(target);
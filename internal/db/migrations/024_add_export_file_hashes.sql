@@ -0,0 +1,8 @@
+-- Migration 024: A hash of the file each note was last exported to, so "noted export --changed-only"
+-- rewrites a file only when its content would change. That covers edits to a note's tags, pin,
+-- aliases, or folder, and to the export's templates, which its updated_at doesn't reflect; it
+-- replaces the per-destination export cursor.
+
+ALTER TABLE export_files ADD COLUMN hash TEXT NOT NULL DEFAULT ''; -- sha256 of the file, hex
+
+DROP TABLE IF EXISTS export_cursors;
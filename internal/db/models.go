@@ -6,8 +6,21 @@ package db
 
 import (
 	"database/sql"
+	"time"
 )
 
+type Counter struct {
+	Name  string `json:"name"`
+	Value int64  `json:"value"`
+}
+
+type ExportFile struct {
+	Destination string `json:"destination"`
+	NoteID      int64  `json:"note_id"`
+	Path        string `json:"path"`
+	Hash        string `json:"hash"`
+}
+
 type Folder struct {
 	ID        int64         `json:"id"`
 	Name      string        `json:"name"`
@@ -29,6 +42,15 @@ type Note struct {
 	FolderID        sql.NullInt64  `json:"folder_id"`
 	Pinned          sql.NullBool   `json:"pinned"`
 	PinnedAt        sql.NullTime   `json:"pinned_at"`
+	CreatedBy       sql.NullString `json:"created_by"`
+	DeletedAt       sql.NullTime   `json:"deleted_at"`
+	ArchivedAt      sql.NullTime   `json:"archived_at"`
+	Lang            sql.NullString `json:"lang"`
+}
+
+type NoteAlias struct {
+	NoteID int64  `json:"note_id"`
+	Alias  string `json:"alias"`
 }
 
 type NoteLink struct {
@@ -37,6 +59,12 @@ type NoteLink struct {
 	TargetNoteID int64        `json:"target_note_id"`
 	LinkText     string       `json:"link_text"`
 	CreatedAt    sql.NullTime `json:"created_at"`
+	Embed        sql.NullBool `json:"embed"`
+}
+
+type NoteMention struct {
+	NoteID int64  `json:"note_id"`
+	Name   string `json:"name"`
 }
 
 type NoteTag struct {
@@ -53,9 +81,49 @@ type NoteVersion struct {
 	CreatedAt     sql.NullTime `json:"created_at"`
 }
 
+type NotesFt struct {
+	Title   string `json:"title"`
+	Content string `json:"content"`
+}
+
+type NotesFtsTrigram struct {
+	Title   string `json:"title"`
+	Content string `json:"content"`
+}
+
+type PendingLink struct {
+	NoteID int64  `json:"note_id"`
+	Target string `json:"target"`
+}
+
+type Schedule struct {
+	ID           int64          `json:"id"`
+	Name         string         `json:"name"`
+	Rule         string         `json:"rule"`
+	Title        string         `json:"title"`
+	TemplateName sql.NullString `json:"template_name"`
+	Tags         string         `json:"tags"`
+	FolderID     sql.NullInt64  `json:"folder_id"`
+	NextRunAt    time.Time      `json:"next_run_at"`
+	LastRunAt    sql.NullTime   `json:"last_run_at"`
+	LastNoteID   sql.NullInt64  `json:"last_note_id"`
+	CreatedAt    sql.NullTime   `json:"created_at"`
+}
+
+type SearchHistory struct {
+	ID          int64        `json:"id"`
+	Query       string       `json:"query"`
+	Interface   string       `json:"interface"`
+	ResultCount int64        `json:"result_count"`
+	CreatedAt   sql.NullTime `json:"created_at"`
+}
+
 type Tag struct {
-	ID   int64  `json:"id"`
-	Name string `json:"name"`
+	ID          int64  `json:"id"`
+	Name        string `json:"name"`
+	Color       string `json:"color"`
+	Description string `json:"description"`
+	NoteCount   int64  `json:"note_count"`
 }
 
 type Template struct {
@@ -20,6 +20,13 @@ func Open(path string) (*sql.DB, error) {
 	// connection happened to serve it, leaving FK enforcement — e.g. note_versions ON DELETE CASCADE,
 	// folder ON DELETE SET NULL — nondeterministic under the database/sql pool.)
 	dsn := path + "?_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)"
+
+	// auto_vacuum is fixed when the first table is created, so it can only be chosen for a new
+	// database (Vacuum converts an existing one). Incremental mode lets IncrementalVacuum return free
+	// pages without rewriting the whole file.
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		dsn += "&_pragma=auto_vacuum(incremental)"
+	}
 	db, err := sql.Open("sqlite", dsn)
 	if err != nil {
 		return nil, err
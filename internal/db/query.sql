@@ -10,12 +10,40 @@ RETURNING *;
 
 -- name: GetNote :one
 SELECT * FROM notes
-WHERE id = ?;
+WHERE id = ? AND deleted_at IS NULL;
 
--- name: ListNotes :many
+-- name: GetNoteBySource :one
 SELECT * FROM notes
-ORDER BY created_at DESC
-LIMIT ? OFFSET ?;
+WHERE source = ? AND source_ref = ? AND deleted_at IS NULL
+ORDER BY id
+LIMIT 1;
+
+-- name: ListNotes :many
+-- sort is "created" (or ""), "updated", "title", or "pinned": pinned notes first, then by last
+-- update. Keep SortNotes in step.
+SELECT notes.* FROM notes,
+  (SELECT CAST(sqlc.arg(sort) AS TEXT) AS sort_key, CAST(sqlc.arg(ascending) AS BOOLEAN) AS ascending) AS o
+WHERE deleted_at IS NULL AND (CAST(sqlc.arg(include_archived) AS BOOLEAN) OR archived_at IS NULL)
+  AND (CAST(sqlc.arg(lang) AS TEXT) = '' OR lang = sqlc.arg(lang))
+  AND (CAST(sqlc.narg(updated_since) AS DATETIME) IS NULL OR updated_at >= sqlc.narg(updated_since))
+  AND (CAST(sqlc.narg(updated_before) AS DATETIME) IS NULL OR updated_at < sqlc.narg(updated_before))
+ORDER BY
+  CASE WHEN o.sort_key = 'pinned' THEN COALESCE(pinned, 0) END DESC,
+  CASE WHEN o.sort_key = 'title' AND o.ascending THEN title COLLATE NOCASE END ASC,
+  CASE WHEN o.sort_key = 'title' AND NOT o.ascending THEN title COLLATE NOCASE END DESC,
+  CASE WHEN o.sort_key IN ('updated', 'pinned') AND o.ascending THEN updated_at END ASC,
+  CASE WHEN o.sort_key IN ('updated', 'pinned') AND NOT o.ascending THEN updated_at END DESC,
+  CASE WHEN o.ascending THEN created_at END ASC,
+  CASE WHEN o.ascending THEN id END ASC,
+  created_at DESC, id DESC
+LIMIT sqlc.arg(limit) OFFSET sqlc.arg(offset);
+
+-- name: CountListedNotes :one
+SELECT COUNT(*) FROM notes
+WHERE deleted_at IS NULL AND (CAST(sqlc.arg(include_archived) AS BOOLEAN) OR archived_at IS NULL)
+  AND (CAST(sqlc.arg(lang) AS TEXT) = '' OR lang = sqlc.arg(lang))
+  AND (CAST(sqlc.narg(updated_since) AS DATETIME) IS NULL OR updated_at >= sqlc.narg(updated_since))
+  AND (CAST(sqlc.narg(updated_before) AS DATETIME) IS NULL OR updated_at < sqlc.narg(updated_before));
 
 -- name: UpdateNote :one
 UPDATE notes
@@ -27,9 +55,42 @@ RETURNING *;
 DELETE FROM notes
 WHERE id = ?;
 
+-- Trash: a deleted note keeps its row with deleted_at set until it is restored or purged.
+
+-- name: TrashNote :execrows
+UPDATE notes SET deleted_at = CURRENT_TIMESTAMP
+WHERE id = ? AND deleted_at IS NULL;
+
+-- name: RestoreNote :one
+UPDATE notes SET deleted_at = NULL, embedding_synced = FALSE
+WHERE id = ? AND deleted_at IS NOT NULL
+RETURNING *;
+
+-- Archive: an archived note stays live but is left out of default lists and search.
+
+-- name: ArchiveNote :execrows
+UPDATE notes SET archived_at = CURRENT_TIMESTAMP
+WHERE id = ? AND deleted_at IS NULL AND archived_at IS NULL;
+
+-- name: UnarchiveNote :execrows
+UPDATE notes SET archived_at = NULL
+WHERE id = ? AND deleted_at IS NULL AND archived_at IS NOT NULL;
+
+-- name: ListArchivedNotes :many
+SELECT * FROM notes WHERE archived_at IS NOT NULL AND deleted_at IS NULL ORDER BY archived_at DESC, id DESC;
+
+-- name: GetTrashedNote :one
+SELECT * FROM notes WHERE id = ? AND deleted_at IS NOT NULL;
+
+-- name: ListTrashedNotes :many
+SELECT * FROM notes WHERE deleted_at IS NOT NULL ORDER BY deleted_at DESC, id DESC;
+
+-- name: GetTrashedNotesBefore :many
+SELECT * FROM notes WHERE deleted_at IS NOT NULL AND deleted_at < ? ORDER BY id;
+
 -- name: SearchNotesByTitle :many
 SELECT * FROM notes
-WHERE title LIKE ?
+WHERE title LIKE ? AND deleted_at IS NULL
 ORDER BY created_at DESC;
 
 -- name: MarkEmbeddingSynced :exec
@@ -37,9 +98,29 @@ UPDATE notes
 SET embedding_synced = TRUE
 WHERE id = ?;
 
+-- name: MarkEmbeddingUnsynced :exec
+UPDATE notes
+SET embedding_synced = FALSE
+WHERE id = ?;
+
 -- name: GetUnsynced :many
 SELECT * FROM notes
-WHERE embedding_synced = FALSE;
+WHERE embedding_synced = FALSE AND deleted_at IS NULL
+ORDER BY id;
+
+-- name: CountUnsynced :one
+SELECT COUNT(*) FROM notes
+WHERE embedding_synced = FALSE AND deleted_at IS NULL;
+
+-- name: GetLargestNote :one
+SELECT id, title, CAST(LENGTH(CAST(content AS BLOB)) AS INTEGER) AS size FROM notes
+WHERE deleted_at IS NULL
+ORDER BY size DESC, id
+LIMIT 1;
+
+-- name: ResetEmbeddingSynced :exec
+UPDATE notes
+SET embedding_synced = FALSE;
 
 -- Tags --
 
@@ -57,6 +138,11 @@ WHERE name = ?;
 SELECT * FROM tags
 ORDER BY name;
 
+-- name: UpdateTagMetadata :one
+UPDATE tags SET color = ?, description = ?
+WHERE id = ?
+RETURNING *;
+
 -- name: DeleteTag :exec
 DELETE FROM tags
 WHERE id = ?;
@@ -81,38 +167,61 @@ ORDER BY t.name;
 -- name: GetNotesForTag :many
 SELECT n.* FROM notes n
 INNER JOIN note_tags nt ON n.id = nt.note_id
-WHERE nt.tag_id = ?
+WHERE nt.tag_id = ? AND n.deleted_at IS NULL
 ORDER BY n.created_at DESC;
 
 -- name: GetNotesByTagName :many
 SELECT n.* FROM notes n
 INNER JOIN note_tags nt ON n.id = nt.note_id
 INNER JOIN tags t ON nt.tag_id = t.id
-WHERE t.name = ?
+WHERE t.name = ? AND n.deleted_at IS NULL
 ORDER BY n.created_at DESC;
 
 -- name: RemoveAllTagsFromNote :exec
 DELETE FROM note_tags WHERE note_id = ?;
 
 -- name: GetTagsWithCount :many
-SELECT t.id, t.name, COUNT(nt.note_id) as note_count
-FROM tags t
-LEFT JOIN note_tags nt ON t.id = nt.tag_id
-GROUP BY t.id
-ORDER BY t.name;
+SELECT id, name, color, description, note_count
+FROM tags
+ORDER BY name;
+
+-- name: RenameTag :exec
+UPDATE tags SET name = ? WHERE id = ?;
+
+-- name: MergeTagInto :exec
+INSERT OR IGNORE INTO note_tags (note_id, tag_id)
+SELECT nt.note_id, sqlc.arg(target_id) FROM note_tags nt
+WHERE nt.tag_id = sqlc.arg(source_id);
+
+-- name: GetNoteIDsForTag :many
+SELECT note_id FROM note_tags WHERE tag_id = ? ORDER BY note_id;
+
+-- name: GetUnusedTags :many
+SELECT * FROM tags
+WHERE id NOT IN (SELECT DISTINCT tag_id FROM note_tags)
+ORDER BY name;
 
 -- name: DeleteUnusedTags :execrows
 DELETE FROM tags
 WHERE id NOT IN (SELECT DISTINCT tag_id FROM note_tags);
 
+-- name: CountDanglingNoteTags :one
+SELECT COUNT(*) FROM note_tags
+WHERE note_id NOT IN (SELECT id FROM notes) OR tag_id NOT IN (SELECT id FROM tags);
+
+-- name: DeleteDanglingNoteTags :execrows
+DELETE FROM note_tags
+WHERE note_id NOT IN (SELECT id FROM notes) OR tag_id NOT IN (SELECT id FROM tags);
+
 -- name: SearchNotesContent :many
 SELECT * FROM notes
-WHERE content LIKE ? OR title LIKE ?
+WHERE (content LIKE sqlc.arg(content) OR title LIKE sqlc.arg(title)) AND deleted_at IS NULL
+  AND (CAST(sqlc.arg(include_archived) AS BOOLEAN) OR archived_at IS NULL)
 ORDER BY updated_at DESC
-LIMIT ?;
+LIMIT sqlc.arg(limit);
 
 -- name: GetAllNotes :many
-SELECT * FROM notes ORDER BY created_at DESC;
+SELECT * FROM notes WHERE deleted_at IS NULL ORDER BY created_at DESC;
 
 -- name: DeleteExpiredNotes :execresult
 DELETE FROM notes WHERE expires_at IS NOT NULL AND expires_at < datetime('now');
@@ -123,8 +232,17 @@ SELECT * FROM notes WHERE expires_at IS NOT NULL AND expires_at < datetime('now'
 -- name: UpdateNoteSource :exec
 UPDATE notes SET source = ?, source_ref = ? WHERE id = ?;
 
+-- name: SetNoteExpiry :exec
+UPDATE notes SET expires_at = ? WHERE id = ?;
+
+-- name: SetNoteCreatedBy :exec
+UPDATE notes SET created_by = ? WHERE id = ?;
+
 -- name: GetNotesSince :many
-SELECT * FROM notes WHERE created_at >= ? ORDER BY created_at DESC;
+SELECT * FROM notes WHERE created_at >= ? AND deleted_at IS NULL ORDER BY created_at DESC;
+
+-- name: GetNotesUpdatedSince :many
+SELECT * FROM notes WHERE updated_at >= ? AND deleted_at IS NULL ORDER BY updated_at DESC;
 
 -- Folders --
 
@@ -148,14 +266,41 @@ RETURNING *;
 -- name: DeleteFolder :exec
 DELETE FROM folders WHERE id = ?;
 
+-- name: FolderTree :many
+-- Every folder with the number of live, unarchived notes directly in it and in it or any folder
+-- below it. UNION rather than UNION ALL stops the walk should parent_id ever form a cycle.
+WITH RECURSIVE descendants(root_id, id) AS (
+  SELECT folders.id, folders.id FROM folders
+  UNION
+  SELECT d.root_id, f.id FROM folders f JOIN descendants d ON f.parent_id = d.id
+)
+SELECT f.id, f.name, f.parent_id,
+  (SELECT COUNT(*) FROM notes n
+   WHERE n.folder_id = f.id AND n.deleted_at IS NULL AND n.archived_at IS NULL) AS note_count,
+  (SELECT COUNT(*) FROM descendants d JOIN notes n ON n.folder_id = d.id
+   WHERE d.root_id = f.id AND n.deleted_at IS NULL AND n.archived_at IS NULL) AS total_count
+FROM folders f
+ORDER BY f.name, f.id;
+
 -- name: GetNotesByFolder :many
 SELECT * FROM notes
-WHERE folder_id = ?
+WHERE folder_id = ? AND deleted_at IS NULL
+ORDER BY created_at DESC;
+
+-- name: GetNotesInFolderTree :many
+-- Notes in a folder or any folder below it. UNION stops the walk should parent_id form a cycle.
+WITH RECURSIVE subfolders(id) AS (
+  SELECT CAST(sqlc.arg(folder_id) AS INTEGER)
+  UNION
+  SELECT f.id FROM folders f JOIN subfolders s ON f.parent_id = s.id
+)
+SELECT * FROM notes
+WHERE folder_id IN (SELECT id FROM subfolders) AND deleted_at IS NULL
 ORDER BY created_at DESC;
 
 -- name: GetNotesWithoutFolder :many
 SELECT * FROM notes
-WHERE folder_id IS NULL
+WHERE folder_id IS NULL AND deleted_at IS NULL
 ORDER BY created_at DESC;
 
 -- name: MoveNoteToFolder :exec
@@ -168,17 +313,31 @@ SELECT * FROM tags WHERE id = ?;
 
 -- Count queries (avoid loading all rows)
 
+-- CountNotes and CountTags read the counters triggers keep (migration 018); the Recount queries
+-- rebuild them from the tables.
+
 -- name: CountNotes :one
-SELECT COUNT(*) FROM notes;
+SELECT value FROM counters WHERE name = 'notes';
 
 -- name: CountTags :one
-SELECT COUNT(*) FROM tags;
+SELECT value FROM counters WHERE name = 'tags';
+
+-- name: RecountCounters :exec
+INSERT OR REPLACE INTO counters (name, value) VALUES
+  ('notes', (SELECT COUNT(*) FROM notes WHERE deleted_at IS NULL)),
+  ('tags', (SELECT COUNT(*) FROM tags));
+
+-- name: RecountTagNotes :exec
+UPDATE tags SET note_count = (
+  SELECT COUNT(*) FROM note_tags nt JOIN notes n ON n.id = nt.note_id
+  WHERE nt.tag_id = tags.id AND n.deleted_at IS NULL
+);
 
 -- Note links (wikilinks / bidirectional linking)
 
 -- name: CreateNoteLink :exec
-INSERT INTO note_links (source_note_id, target_note_id, link_text)
-VALUES (?, ?, ?)
+INSERT INTO note_links (source_note_id, target_note_id, link_text, embed)
+VALUES (?, ?, ?, ?)
 ON CONFLICT DO NOTHING;
 
 -- name: DeleteNoteLinks :exec
@@ -187,20 +346,104 @@ DELETE FROM note_links WHERE source_note_id = ?;
 -- name: GetBacklinks :many
 SELECT n.* FROM notes n
 INNER JOIN note_links nl ON n.id = nl.source_note_id
-WHERE nl.target_note_id = ?
+WHERE nl.target_note_id = ? AND n.deleted_at IS NULL
 ORDER BY n.updated_at DESC;
 
 -- name: GetOutlinks :many
 SELECT n.* FROM notes n
 INNER JOIN note_links nl ON n.id = nl.target_note_id
-WHERE nl.source_note_id = ?
+WHERE nl.source_note_id = ? AND n.deleted_at IS NULL
 ORDER BY n.title;
 
 -- name: GetAllNoteLinks :many
 SELECT * FROM note_links;
 
+-- name: CountDanglingNoteLinks :one
+SELECT COUNT(*) FROM note_links
+WHERE source_note_id NOT IN (SELECT id FROM notes) OR target_note_id NOT IN (SELECT id FROM notes);
+
+-- name: DeleteDanglingNoteLinks :execrows
+DELETE FROM note_links
+WHERE source_note_id NOT IN (SELECT id FROM notes) OR target_note_id NOT IN (SELECT id FROM notes);
+
 -- name: GetNoteByTitle :one
-SELECT * FROM notes WHERE title = ? LIMIT 1;
+SELECT * FROM notes WHERE title = ? AND deleted_at IS NULL LIMIT 1;
+
+-- Note aliases
+
+-- name: ResolveNoteTitle :one
+-- A wikilink target: the note with this exact title, else the note with this alias.
+SELECT notes.* FROM notes
+LEFT JOIN note_aliases na ON na.note_id = notes.id AND na.alias = sqlc.arg(name)
+WHERE (notes.title = sqlc.arg(name) OR na.alias IS NOT NULL) AND notes.deleted_at IS NULL
+ORDER BY na.alias IS NULL DESC, notes.id
+LIMIT 1;
+
+-- name: GetNoteAliases :many
+SELECT alias FROM note_aliases WHERE note_id = ? ORDER BY alias;
+
+-- name: ListNoteAliases :many
+SELECT note_id, alias FROM note_aliases ORDER BY note_id, alias;
+
+-- name: AddNoteAlias :exec
+INSERT OR IGNORE INTO note_aliases (note_id, alias) VALUES (?, ?);
+
+-- name: RemoveNoteAlias :exec
+DELETE FROM note_aliases WHERE note_id = ? AND alias = ?;
+
+-- name: RemoveAllNoteAliases :exec
+DELETE FROM note_aliases WHERE note_id = ?;
+
+-- @mentions
+
+-- name: AddNoteMention :exec
+INSERT OR IGNORE INTO note_mentions (note_id, name) VALUES (?, ?);
+
+-- name: RemoveAllNoteMentions :exec
+DELETE FROM note_mentions WHERE note_id = ?;
+
+-- name: ListNoteContents :many
+SELECT id, content FROM notes ORDER BY id;
+
+-- name: SetNoteLang :exec
+-- Leaves updated_at alone: the language is derived from the content, not an edit.
+UPDATE notes SET lang = ? WHERE id = ?;
+
+-- name: GetNoteMentions :many
+SELECT name FROM note_mentions WHERE note_id = ? ORDER BY name;
+
+-- name: GetNotesMentioning :many
+SELECT n.* FROM notes n
+INNER JOIN note_mentions m ON n.id = m.note_id
+WHERE m.name = ? AND n.deleted_at IS NULL
+ORDER BY n.updated_at DESC;
+
+-- name: ListMentionNames :many
+SELECT m.name, COUNT(*) AS note_count FROM note_mentions m
+INNER JOIN notes n ON n.id = m.note_id
+WHERE n.deleted_at IS NULL
+GROUP BY m.name
+ORDER BY note_count DESC, m.name;
+
+-- Pending links
+
+-- name: AddPendingLink :exec
+INSERT OR IGNORE INTO pending_links (note_id, target) VALUES (?, ?);
+
+-- name: RemoveAllPendingLinks :exec
+DELETE FROM pending_links WHERE note_id = ?;
+
+-- name: ListUnresolvedLinks :many
+-- Link targets that match no live note's title or alias, from live notes.
+SELECT p.target, n.id AS note_id, n.title AS note_title FROM pending_links p
+INNER JOIN notes n ON n.id = p.note_id
+WHERE n.deleted_at IS NULL
+AND NOT EXISTS (SELECT 1 FROM notes t WHERE t.title = p.target AND t.deleted_at IS NULL)
+AND NOT EXISTS (
+  SELECT 1 FROM note_aliases a INNER JOIN notes t ON t.id = a.note_id
+  WHERE a.alias = p.target AND t.deleted_at IS NULL
+)
+ORDER BY p.target, n.title, n.id;
 
 -- Pin/star support
 
@@ -211,7 +454,12 @@ UPDATE notes SET pinned = TRUE, pinned_at = CURRENT_TIMESTAMP WHERE id = ?;
 UPDATE notes SET pinned = FALSE, pinned_at = NULL WHERE id = ?;
 
 -- name: GetPinnedNotes :many
-SELECT * FROM notes WHERE pinned = TRUE ORDER BY pinned_at DESC;
+SELECT * FROM notes WHERE pinned = TRUE AND deleted_at IS NULL ORDER BY pinned_at DESC;
+
+-- name: ListRecentlyUpdatedNotes :many
+SELECT * FROM notes WHERE deleted_at IS NULL AND archived_at IS NULL
+ORDER BY updated_at DESC, id DESC
+LIMIT ?;
 
 -- Templates --
 
@@ -265,10 +513,91 @@ DELETE FROM note_versions WHERE note_id = ?;
 SELECT * FROM notes
 WHERE id NOT IN (SELECT source_note_id FROM note_links)
 AND id NOT IN (SELECT target_note_id FROM note_links)
+AND deleted_at IS NULL
 ORDER BY title;
 
 -- name: GetDeadEndNotes :many
 SELECT * FROM notes
 WHERE id IN (SELECT target_note_id FROM note_links)
 AND id NOT IN (SELECT source_note_id FROM note_links)
+AND deleted_at IS NULL
 ORDER BY title;
+
+-- Schedules (recurring notes) --
+
+-- name: CreateSchedule :one
+INSERT INTO schedules (name, rule, title, template_name, tags, folder_id, next_run_at)
+VALUES (?, ?, ?, ?, ?, ?, ?)
+RETURNING *;
+
+-- name: GetScheduleByName :one
+SELECT * FROM schedules WHERE name = ?;
+
+-- name: ListSchedules :many
+SELECT * FROM schedules ORDER BY next_run_at, name;
+
+-- name: DeleteScheduleByName :execrows
+DELETE FROM schedules WHERE name = ?;
+
+-- name: UpdateScheduleRun :exec
+UPDATE schedules
+SET last_run_at = ?, next_run_at = ?, last_note_id = ?
+WHERE id = ?;
+
+-- name: SearchNotesByTagName :many
+SELECT DISTINCT n.* FROM notes n
+INNER JOIN note_tags nt ON n.id = nt.note_id
+INNER JOIN tags t ON nt.tag_id = t.id
+WHERE t.name LIKE sqlc.arg(name) AND n.deleted_at IS NULL
+  AND (CAST(sqlc.arg(include_archived) AS BOOLEAN) OR n.archived_at IS NULL)
+ORDER BY n.updated_at DESC
+LIMIT sqlc.arg(limit);
+
+-- Search history --
+
+-- name: RecordSearch :exec
+INSERT INTO search_history (query, interface, result_count)
+VALUES (?, ?, ?);
+
+-- name: ListSearchHistory :many
+SELECT query, interface, COUNT(*) AS uses, CAST(MAX(id) AS INTEGER) AS last_id, CAST(MAX(created_at) AS TEXT) AS last_searched_at
+FROM search_history
+WHERE CAST(sqlc.arg(interface_filter) AS TEXT) = '' OR interface = sqlc.arg(interface_filter)
+GROUP BY query, interface
+ORDER BY last_id DESC
+LIMIT sqlc.arg(limit);
+
+-- name: PruneSearchHistory :exec
+DELETE FROM search_history
+WHERE id NOT IN (SELECT id FROM search_history ORDER BY id DESC LIMIT ?);
+
+-- name: ClearSearchHistory :execrows
+DELETE FROM search_history;
+
+-- Browsing by date --
+
+-- name: GetNotesOnDate :many
+SELECT * FROM notes
+WHERE (date(created_at, 'localtime') = CAST(sqlc.arg(day) AS TEXT)
+   OR date(updated_at, 'localtime') = CAST(sqlc.arg(day) AS TEXT))
+  AND deleted_at IS NULL
+ORDER BY created_at;
+
+-- name: GetNotesOnThisDay :many
+SELECT * FROM notes
+WHERE strftime('%m-%d', created_at, 'localtime') = CAST(sqlc.arg(month_day) AS TEXT)
+  AND strftime('%Y', created_at, 'localtime') < CAST(sqlc.arg(before_year) AS TEXT)
+  AND deleted_at IS NULL
+ORDER BY created_at DESC;
+
+-- Exported files --
+
+-- name: ListExportFiles :many
+SELECT note_id, path, hash FROM export_files WHERE destination = ? ORDER BY note_id;
+
+-- name: SetExportFile :exec
+INSERT INTO export_files (destination, note_id, path, hash) VALUES (?, ?, ?, ?)
+ON CONFLICT(destination, note_id) DO UPDATE SET path = excluded.path, hash = excluded.hash;
+
+-- name: DeleteExportFile :exec
+DELETE FROM export_files WHERE destination = ? AND note_id = ?;
@@ -8,8 +8,55 @@ package db
 import (
 	"context"
 	"database/sql"
+	"time"
 )
 
+const addNoteAlias = `-- name: AddNoteAlias :exec
+INSERT OR IGNORE INTO note_aliases (note_id, alias) VALUES (?, ?)
+`
+
+type AddNoteAliasParams struct {
+	NoteID int64  `json:"note_id"`
+	Alias  string `json:"alias"`
+}
+
+func (q *Queries) AddNoteAlias(ctx context.Context, arg AddNoteAliasParams) error {
+	_, err := q.db.ExecContext(ctx, addNoteAlias, arg.NoteID, arg.Alias)
+	return err
+}
+
+const addNoteMention = `-- name: AddNoteMention :exec
+
+INSERT OR IGNORE INTO note_mentions (note_id, name) VALUES (?, ?)
+`
+
+type AddNoteMentionParams struct {
+	NoteID int64  `json:"note_id"`
+	Name   string `json:"name"`
+}
+
+// @mentions
+func (q *Queries) AddNoteMention(ctx context.Context, arg AddNoteMentionParams) error {
+	_, err := q.db.ExecContext(ctx, addNoteMention, arg.NoteID, arg.Name)
+	return err
+}
+
+const addPendingLink = `-- name: AddPendingLink :exec
+
+INSERT OR IGNORE INTO pending_links (note_id, target) VALUES (?, ?)
+`
+
+type AddPendingLinkParams struct {
+	NoteID int64  `json:"note_id"`
+	Target string `json:"target"`
+}
+
+// Pending links
+func (q *Queries) AddPendingLink(ctx context.Context, arg AddPendingLinkParams) error {
+	_, err := q.db.ExecContext(ctx, addPendingLink, arg.NoteID, arg.Target)
+	return err
+}
+
 const addTagToNote = `-- name: AddTagToNote :exec
 
 INSERT INTO note_tags (note_id, tag_id)
@@ -28,25 +75,118 @@ func (q *Queries) AddTagToNote(ctx context.Context, arg AddTagToNoteParams) erro
 	return err
 }
 
-const countNotes = `-- name: CountNotes :one
+const archiveNote = `-- name: ArchiveNote :execrows
+
+UPDATE notes SET archived_at = CURRENT_TIMESTAMP
+WHERE id = ? AND deleted_at IS NULL AND archived_at IS NULL
+`
+
+// Archive: an archived note stays live but is left out of default lists and search.
+func (q *Queries) ArchiveNote(ctx context.Context, id int64) (int64, error) {
+	result, err := q.db.ExecContext(ctx, archiveNote, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
 
+const clearSearchHistory = `-- name: ClearSearchHistory :execrows
+DELETE FROM search_history
+`
+
+func (q *Queries) ClearSearchHistory(ctx context.Context) (int64, error) {
+	result, err := q.db.ExecContext(ctx, clearSearchHistory)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const countDanglingNoteLinks = `-- name: CountDanglingNoteLinks :one
+SELECT COUNT(*) FROM note_links
+WHERE source_note_id NOT IN (SELECT id FROM notes) OR target_note_id NOT IN (SELECT id FROM notes)
+`
+
+func (q *Queries) CountDanglingNoteLinks(ctx context.Context) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countDanglingNoteLinks)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countDanglingNoteTags = `-- name: CountDanglingNoteTags :one
+SELECT COUNT(*) FROM note_tags
+WHERE note_id NOT IN (SELECT id FROM notes) OR tag_id NOT IN (SELECT id FROM tags)
+`
+
+func (q *Queries) CountDanglingNoteTags(ctx context.Context) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countDanglingNoteTags)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countListedNotes = `-- name: CountListedNotes :one
 SELECT COUNT(*) FROM notes
+WHERE deleted_at IS NULL AND (CAST(?1 AS BOOLEAN) OR archived_at IS NULL)
+  AND (CAST(?2 AS TEXT) = '' OR lang = ?2)
+  AND (CAST(?3 AS DATETIME) IS NULL OR updated_at >= ?3)
+  AND (CAST(?4 AS DATETIME) IS NULL OR updated_at < ?4)
 `
 
-// Count queries (avoid loading all rows)
-func (q *Queries) CountNotes(ctx context.Context) (int64, error) {
-	row := q.db.QueryRowContext(ctx, countNotes)
+type CountListedNotesParams struct {
+	IncludeArchived bool         `json:"include_archived"`
+	Lang            string       `json:"lang"`
+	UpdatedSince    sql.NullTime `json:"updated_since"`
+	UpdatedBefore   sql.NullTime `json:"updated_before"`
+}
+
+func (q *Queries) CountListedNotes(ctx context.Context, arg CountListedNotesParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countListedNotes,
+		arg.IncludeArchived,
+		arg.Lang,
+		arg.UpdatedSince,
+		arg.UpdatedBefore,
+	)
 	var count int64
 	err := row.Scan(&count)
 	return count, err
 }
 
+const countNotes = `-- name: CountNotes :one
+
+
+SELECT value FROM counters WHERE name = 'notes'
+`
+
+// Count queries (avoid loading all rows)
+// CountNotes and CountTags read the counters triggers keep (migration 018); the Recount queries
+// rebuild them from the tables.
+func (q *Queries) CountNotes(ctx context.Context) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countNotes)
+	var value int64
+	err := row.Scan(&value)
+	return value, err
+}
+
 const countTags = `-- name: CountTags :one
-SELECT COUNT(*) FROM tags
+SELECT value FROM counters WHERE name = 'tags'
 `
 
 func (q *Queries) CountTags(ctx context.Context) (int64, error) {
 	row := q.db.QueryRowContext(ctx, countTags)
+	var value int64
+	err := row.Scan(&value)
+	return value, err
+}
+
+const countUnsynced = `-- name: CountUnsynced :one
+SELECT COUNT(*) FROM notes
+WHERE embedding_synced = FALSE AND deleted_at IS NULL
+`
+
+func (q *Queries) CountUnsynced(ctx context.Context) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countUnsynced)
 	var count int64
 	err := row.Scan(&count)
 	return count, err
@@ -81,7 +221,7 @@ func (q *Queries) CreateFolder(ctx context.Context, arg CreateFolderParams) (Fol
 const createNote = `-- name: CreateNote :one
 INSERT INTO notes (title, content)
 VALUES (?, ?)
-RETURNING id, title, content, created_at, updated_at, embedding_synced, expires_at, source, source_ref, folder_id, pinned, pinned_at
+RETURNING id, title, content, created_at, updated_at, embedding_synced, expires_at, source, source_ref, folder_id, pinned, pinned_at, created_by, deleted_at, archived_at, lang
 `
 
 type CreateNoteParams struct {
@@ -105,26 +245,36 @@ func (q *Queries) CreateNote(ctx context.Context, arg CreateNoteParams) (Note, e
 		&i.FolderID,
 		&i.Pinned,
 		&i.PinnedAt,
+		&i.CreatedBy,
+		&i.DeletedAt,
+		&i.ArchivedAt,
+		&i.Lang,
 	)
 	return i, err
 }
 
 const createNoteLink = `-- name: CreateNoteLink :exec
 
-INSERT INTO note_links (source_note_id, target_note_id, link_text)
-VALUES (?, ?, ?)
+INSERT INTO note_links (source_note_id, target_note_id, link_text, embed)
+VALUES (?, ?, ?, ?)
 ON CONFLICT DO NOTHING
 `
 
 type CreateNoteLinkParams struct {
-	SourceNoteID int64  `json:"source_note_id"`
-	TargetNoteID int64  `json:"target_note_id"`
-	LinkText     string `json:"link_text"`
+	SourceNoteID int64        `json:"source_note_id"`
+	TargetNoteID int64        `json:"target_note_id"`
+	LinkText     string       `json:"link_text"`
+	Embed        sql.NullBool `json:"embed"`
 }
 
 // Note links (wikilinks / bidirectional linking)
 func (q *Queries) CreateNoteLink(ctx context.Context, arg CreateNoteLinkParams) error {
-	_, err := q.db.ExecContext(ctx, createNoteLink, arg.SourceNoteID, arg.TargetNoteID, arg.LinkText)
+	_, err := q.db.ExecContext(ctx, createNoteLink,
+		arg.SourceNoteID,
+		arg.TargetNoteID,
+		arg.LinkText,
+		arg.Embed,
+	)
 	return err
 }
 
@@ -165,7 +315,7 @@ func (q *Queries) CreateNoteVersion(ctx context.Context, arg CreateNoteVersionPa
 const createNoteWithTTL = `-- name: CreateNoteWithTTL :one
 INSERT INTO notes (title, content, expires_at, source, source_ref)
 VALUES (?, ?, ?, ?, ?)
-RETURNING id, title, content, created_at, updated_at, embedding_synced, expires_at, source, source_ref, folder_id, pinned, pinned_at
+RETURNING id, title, content, created_at, updated_at, embedding_synced, expires_at, source, source_ref, folder_id, pinned, pinned_at, created_by, deleted_at, archived_at, lang
 `
 
 type CreateNoteWithTTLParams struct {
@@ -198,6 +348,55 @@ func (q *Queries) CreateNoteWithTTL(ctx context.Context, arg CreateNoteWithTTLPa
 		&i.FolderID,
 		&i.Pinned,
 		&i.PinnedAt,
+		&i.CreatedBy,
+		&i.DeletedAt,
+		&i.ArchivedAt,
+		&i.Lang,
+	)
+	return i, err
+}
+
+const createSchedule = `-- name: CreateSchedule :one
+
+INSERT INTO schedules (name, rule, title, template_name, tags, folder_id, next_run_at)
+VALUES (?, ?, ?, ?, ?, ?, ?)
+RETURNING id, name, rule, title, template_name, tags, folder_id, next_run_at, last_run_at, last_note_id, created_at
+`
+
+type CreateScheduleParams struct {
+	Name         string         `json:"name"`
+	Rule         string         `json:"rule"`
+	Title        string         `json:"title"`
+	TemplateName sql.NullString `json:"template_name"`
+	Tags         string         `json:"tags"`
+	FolderID     sql.NullInt64  `json:"folder_id"`
+	NextRunAt    time.Time      `json:"next_run_at"`
+}
+
+// Schedules (recurring notes) --
+func (q *Queries) CreateSchedule(ctx context.Context, arg CreateScheduleParams) (Schedule, error) {
+	row := q.db.QueryRowContext(ctx, createSchedule,
+		arg.Name,
+		arg.Rule,
+		arg.Title,
+		arg.TemplateName,
+		arg.Tags,
+		arg.FolderID,
+		arg.NextRunAt,
+	)
+	var i Schedule
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Rule,
+		&i.Title,
+		&i.TemplateName,
+		&i.Tags,
+		&i.FolderID,
+		&i.NextRunAt,
+		&i.LastRunAt,
+		&i.LastNoteID,
+		&i.CreatedAt,
 	)
 	return i, err
 }
@@ -207,14 +406,20 @@ const createTag = `-- name: CreateTag :one
 INSERT INTO tags (name)
 VALUES (?)
 ON CONFLICT (name) DO UPDATE SET name = name
-RETURNING id, name
+RETURNING id, name, color, description, note_count
 `
 
 // Tags --
 func (q *Queries) CreateTag(ctx context.Context, name string) (Tag, error) {
 	row := q.db.QueryRowContext(ctx, createTag, name)
 	var i Tag
-	err := row.Scan(&i.ID, &i.Name)
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Color,
+		&i.Description,
+		&i.NoteCount,
+	)
 	return i, err
 }
 
@@ -242,6 +447,32 @@ func (q *Queries) CreateTemplate(ctx context.Context, arg CreateTemplateParams)
 	return i, err
 }
 
+const deleteDanglingNoteLinks = `-- name: DeleteDanglingNoteLinks :execrows
+DELETE FROM note_links
+WHERE source_note_id NOT IN (SELECT id FROM notes) OR target_note_id NOT IN (SELECT id FROM notes)
+`
+
+func (q *Queries) DeleteDanglingNoteLinks(ctx context.Context) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteDanglingNoteLinks)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deleteDanglingNoteTags = `-- name: DeleteDanglingNoteTags :execrows
+DELETE FROM note_tags
+WHERE note_id NOT IN (SELECT id FROM notes) OR tag_id NOT IN (SELECT id FROM tags)
+`
+
+func (q *Queries) DeleteDanglingNoteTags(ctx context.Context) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteDanglingNoteTags)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
 const deleteExpiredNotes = `-- name: DeleteExpiredNotes :execresult
 DELETE FROM notes WHERE expires_at IS NOT NULL AND expires_at < datetime('now')
 `
@@ -250,6 +481,20 @@ func (q *Queries) DeleteExpiredNotes(ctx context.Context) (sql.Result, error) {
 	return q.db.ExecContext(ctx, deleteExpiredNotes)
 }
 
+const deleteExportFile = `-- name: DeleteExportFile :exec
+DELETE FROM export_files WHERE destination = ? AND note_id = ?
+`
+
+type DeleteExportFileParams struct {
+	Destination string `json:"destination"`
+	NoteID      int64  `json:"note_id"`
+}
+
+func (q *Queries) DeleteExportFile(ctx context.Context, arg DeleteExportFileParams) error {
+	_, err := q.db.ExecContext(ctx, deleteExportFile, arg.Destination, arg.NoteID)
+	return err
+}
+
 const deleteFolder = `-- name: DeleteFolder :exec
 DELETE FROM folders WHERE id = ?
 `
@@ -287,6 +532,18 @@ func (q *Queries) DeleteNoteVersions(ctx context.Context, noteID int64) error {
 	return err
 }
 
+const deleteScheduleByName = `-- name: DeleteScheduleByName :execrows
+DELETE FROM schedules WHERE name = ?
+`
+
+func (q *Queries) DeleteScheduleByName(ctx context.Context, name string) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteScheduleByName, name)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
 const deleteTag = `-- name: DeleteTag :exec
 DELETE FROM tags
 WHERE id = ?
@@ -328,8 +585,62 @@ func (q *Queries) DeleteUnusedTags(ctx context.Context) (int64, error) {
 	return result.RowsAffected()
 }
 
+const folderTree = `-- name: FolderTree :many
+WITH RECURSIVE descendants(root_id, id) AS (
+  SELECT folders.id, folders.id FROM folders
+  UNION
+  SELECT d.root_id, f.id FROM folders f JOIN descendants d ON f.parent_id = d.id
+)
+SELECT f.id, f.name, f.parent_id,
+  (SELECT COUNT(*) FROM notes n
+   WHERE n.folder_id = f.id AND n.deleted_at IS NULL AND n.archived_at IS NULL) AS note_count,
+  (SELECT COUNT(*) FROM descendants d JOIN notes n ON n.folder_id = d.id
+   WHERE d.root_id = f.id AND n.deleted_at IS NULL AND n.archived_at IS NULL) AS total_count
+FROM folders f
+ORDER BY f.name, f.id
+`
+
+type FolderTreeRow struct {
+	ID         int64         `json:"id"`
+	Name       string        `json:"name"`
+	ParentID   sql.NullInt64 `json:"parent_id"`
+	NoteCount  int64         `json:"note_count"`
+	TotalCount int64         `json:"total_count"`
+}
+
+// Every folder with the number of live, unarchived notes directly in it and in it or any folder
+// below it. UNION rather than UNION ALL stops the walk should parent_id ever form a cycle.
+func (q *Queries) FolderTree(ctx context.Context) ([]FolderTreeRow, error) {
+	rows, err := q.db.QueryContext(ctx, folderTree)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []FolderTreeRow{}
+	for rows.Next() {
+		var i FolderTreeRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.ParentID,
+			&i.NoteCount,
+			&i.TotalCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getAllNoteLinks = `-- name: GetAllNoteLinks :many
-SELECT id, source_note_id, target_note_id, link_text, created_at FROM note_links
+SELECT id, source_note_id, target_note_id, link_text, created_at, embed FROM note_links
 `
 
 func (q *Queries) GetAllNoteLinks(ctx context.Context) ([]NoteLink, error) {
@@ -347,6 +658,7 @@ func (q *Queries) GetAllNoteLinks(ctx context.Context) ([]NoteLink, error) {
 			&i.TargetNoteID,
 			&i.LinkText,
 			&i.CreatedAt,
+			&i.Embed,
 		); err != nil {
 			return nil, err
 		}
@@ -362,7 +674,7 @@ func (q *Queries) GetAllNoteLinks(ctx context.Context) ([]NoteLink, error) {
 }
 
 const getAllNotes = `-- name: GetAllNotes :many
-SELECT id, title, content, created_at, updated_at, embedding_synced, expires_at, source, source_ref, folder_id, pinned, pinned_at FROM notes ORDER BY created_at DESC
+SELECT id, title, content, created_at, updated_at, embedding_synced, expires_at, source, source_ref, folder_id, pinned, pinned_at, created_by, deleted_at, archived_at, lang FROM notes WHERE deleted_at IS NULL ORDER BY created_at DESC
 `
 
 func (q *Queries) GetAllNotes(ctx context.Context) ([]Note, error) {
@@ -387,6 +699,10 @@ func (q *Queries) GetAllNotes(ctx context.Context) ([]Note, error) {
 			&i.FolderID,
 			&i.Pinned,
 			&i.PinnedAt,
+			&i.CreatedBy,
+			&i.DeletedAt,
+			&i.ArchivedAt,
+			&i.Lang,
 		); err != nil {
 			return nil, err
 		}
@@ -402,9 +718,9 @@ func (q *Queries) GetAllNotes(ctx context.Context) ([]Note, error) {
 }
 
 const getBacklinks = `-- name: GetBacklinks :many
-SELECT n.id, n.title, n.content, n.created_at, n.updated_at, n.embedding_synced, n.expires_at, n.source, n.source_ref, n.folder_id, n.pinned, n.pinned_at FROM notes n
+SELECT n.id, n.title, n.content, n.created_at, n.updated_at, n.embedding_synced, n.expires_at, n.source, n.source_ref, n.folder_id, n.pinned, n.pinned_at, n.created_by, n.deleted_at, n.archived_at, n.lang FROM notes n
 INNER JOIN note_links nl ON n.id = nl.source_note_id
-WHERE nl.target_note_id = ?
+WHERE nl.target_note_id = ? AND n.deleted_at IS NULL
 ORDER BY n.updated_at DESC
 `
 
@@ -430,6 +746,10 @@ func (q *Queries) GetBacklinks(ctx context.Context, targetNoteID int64) ([]Note,
 			&i.FolderID,
 			&i.Pinned,
 			&i.PinnedAt,
+			&i.CreatedBy,
+			&i.DeletedAt,
+			&i.ArchivedAt,
+			&i.Lang,
 		); err != nil {
 			return nil, err
 		}
@@ -445,9 +765,10 @@ func (q *Queries) GetBacklinks(ctx context.Context, targetNoteID int64) ([]Note,
 }
 
 const getDeadEndNotes = `-- name: GetDeadEndNotes :many
-SELECT id, title, content, created_at, updated_at, embedding_synced, expires_at, source, source_ref, folder_id, pinned, pinned_at FROM notes
+SELECT id, title, content, created_at, updated_at, embedding_synced, expires_at, source, source_ref, folder_id, pinned, pinned_at, created_by, deleted_at, archived_at, lang FROM notes
 WHERE id IN (SELECT target_note_id FROM note_links)
 AND id NOT IN (SELECT source_note_id FROM note_links)
+AND deleted_at IS NULL
 ORDER BY title
 `
 
@@ -473,6 +794,10 @@ func (q *Queries) GetDeadEndNotes(ctx context.Context) ([]Note, error) {
 			&i.FolderID,
 			&i.Pinned,
 			&i.PinnedAt,
+			&i.CreatedBy,
+			&i.DeletedAt,
+			&i.ArchivedAt,
+			&i.Lang,
 		); err != nil {
 			return nil, err
 		}
@@ -488,7 +813,7 @@ func (q *Queries) GetDeadEndNotes(ctx context.Context) ([]Note, error) {
 }
 
 const getExpiredNotes = `-- name: GetExpiredNotes :many
-SELECT id, title, content, created_at, updated_at, embedding_synced, expires_at, source, source_ref, folder_id, pinned, pinned_at FROM notes WHERE expires_at IS NOT NULL AND expires_at < datetime('now')
+SELECT id, title, content, created_at, updated_at, embedding_synced, expires_at, source, source_ref, folder_id, pinned, pinned_at, created_by, deleted_at, archived_at, lang FROM notes WHERE expires_at IS NOT NULL AND expires_at < datetime('now')
 `
 
 func (q *Queries) GetExpiredNotes(ctx context.Context) ([]Note, error) {
@@ -513,6 +838,10 @@ func (q *Queries) GetExpiredNotes(ctx context.Context) ([]Note, error) {
 			&i.FolderID,
 			&i.Pinned,
 			&i.PinnedAt,
+			&i.CreatedBy,
+			&i.DeletedAt,
+			&i.ArchivedAt,
+			&i.Lang,
 		); err != nil {
 			return nil, err
 		}
@@ -544,6 +873,26 @@ func (q *Queries) GetFolder(ctx context.Context, id int64) (Folder, error) {
 	return i, err
 }
 
+const getLargestNote = `-- name: GetLargestNote :one
+SELECT id, title, CAST(LENGTH(CAST(content AS BLOB)) AS INTEGER) AS size FROM notes
+WHERE deleted_at IS NULL
+ORDER BY size DESC, id
+LIMIT 1
+`
+
+type GetLargestNoteRow struct {
+	ID    int64  `json:"id"`
+	Title string `json:"title"`
+	Size  int64  `json:"size"`
+}
+
+func (q *Queries) GetLargestNote(ctx context.Context) (GetLargestNoteRow, error) {
+	row := q.db.QueryRowContext(ctx, getLargestNote)
+	var i GetLargestNoteRow
+	err := row.Scan(&i.ID, &i.Title, &i.Size)
+	return i, err
+}
+
 const getLatestVersionNumber = `-- name: GetLatestVersionNumber :one
 SELECT COALESCE(MAX(version_number), 0) FROM note_versions
 WHERE note_id = ?
@@ -557,8 +906,8 @@ func (q *Queries) GetLatestVersionNumber(ctx context.Context, noteID int64) (int
 }
 
 const getNote = `-- name: GetNote :one
-SELECT id, title, content, created_at, updated_at, embedding_synced, expires_at, source, source_ref, folder_id, pinned, pinned_at FROM notes
-WHERE id = ?
+SELECT id, title, content, created_at, updated_at, embedding_synced, expires_at, source, source_ref, folder_id, pinned, pinned_at, created_by, deleted_at, archived_at, lang FROM notes
+WHERE id = ? AND deleted_at IS NULL
 `
 
 func (q *Queries) GetNote(ctx context.Context, id int64) (Note, error) {
@@ -577,12 +926,79 @@ func (q *Queries) GetNote(ctx context.Context, id int64) (Note, error) {
 		&i.FolderID,
 		&i.Pinned,
 		&i.PinnedAt,
+		&i.CreatedBy,
+		&i.DeletedAt,
+		&i.ArchivedAt,
+		&i.Lang,
+	)
+	return i, err
+}
+
+const getNoteAliases = `-- name: GetNoteAliases :many
+SELECT alias FROM note_aliases WHERE note_id = ? ORDER BY alias
+`
+
+func (q *Queries) GetNoteAliases(ctx context.Context, noteID int64) ([]string, error) {
+	rows, err := q.db.QueryContext(ctx, getNoteAliases, noteID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []string{}
+	for rows.Next() {
+		var alias string
+		if err := rows.Scan(&alias); err != nil {
+			return nil, err
+		}
+		items = append(items, alias)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getNoteBySource = `-- name: GetNoteBySource :one
+SELECT id, title, content, created_at, updated_at, embedding_synced, expires_at, source, source_ref, folder_id, pinned, pinned_at, created_by, deleted_at, archived_at, lang FROM notes
+WHERE source = ? AND source_ref = ? AND deleted_at IS NULL
+ORDER BY id
+LIMIT 1
+`
+
+type GetNoteBySourceParams struct {
+	Source    sql.NullString `json:"source"`
+	SourceRef sql.NullString `json:"source_ref"`
+}
+
+func (q *Queries) GetNoteBySource(ctx context.Context, arg GetNoteBySourceParams) (Note, error) {
+	row := q.db.QueryRowContext(ctx, getNoteBySource, arg.Source, arg.SourceRef)
+	var i Note
+	err := row.Scan(
+		&i.ID,
+		&i.Title,
+		&i.Content,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.EmbeddingSynced,
+		&i.ExpiresAt,
+		&i.Source,
+		&i.SourceRef,
+		&i.FolderID,
+		&i.Pinned,
+		&i.PinnedAt,
+		&i.CreatedBy,
+		&i.DeletedAt,
+		&i.ArchivedAt,
+		&i.Lang,
 	)
 	return i, err
 }
 
 const getNoteByTitle = `-- name: GetNoteByTitle :one
-SELECT id, title, content, created_at, updated_at, embedding_synced, expires_at, source, source_ref, folder_id, pinned, pinned_at FROM notes WHERE title = ? LIMIT 1
+SELECT id, title, content, created_at, updated_at, embedding_synced, expires_at, source, source_ref, folder_id, pinned, pinned_at, created_by, deleted_at, archived_at, lang FROM notes WHERE title = ? AND deleted_at IS NULL LIMIT 1
 `
 
 func (q *Queries) GetNoteByTitle(ctx context.Context, title string) (Note, error) {
@@ -601,10 +1017,68 @@ func (q *Queries) GetNoteByTitle(ctx context.Context, title string) (Note, error
 		&i.FolderID,
 		&i.Pinned,
 		&i.PinnedAt,
+		&i.CreatedBy,
+		&i.DeletedAt,
+		&i.ArchivedAt,
+		&i.Lang,
 	)
 	return i, err
 }
 
+const getNoteIDsForTag = `-- name: GetNoteIDsForTag :many
+SELECT note_id FROM note_tags WHERE tag_id = ? ORDER BY note_id
+`
+
+func (q *Queries) GetNoteIDsForTag(ctx context.Context, tagID int64) ([]int64, error) {
+	rows, err := q.db.QueryContext(ctx, getNoteIDsForTag, tagID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []int64{}
+	for rows.Next() {
+		var note_id int64
+		if err := rows.Scan(&note_id); err != nil {
+			return nil, err
+		}
+		items = append(items, note_id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getNoteMentions = `-- name: GetNoteMentions :many
+SELECT name FROM note_mentions WHERE note_id = ? ORDER BY name
+`
+
+func (q *Queries) GetNoteMentions(ctx context.Context, noteID int64) ([]string, error) {
+	rows, err := q.db.QueryContext(ctx, getNoteMentions, noteID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []string{}
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		items = append(items, name)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getNoteVersion = `-- name: GetNoteVersion :one
 SELECT id, note_id, title, content, version_number, created_at FROM note_versions
 WHERE note_id = ? AND version_number = ?
@@ -666,8 +1140,8 @@ func (q *Queries) GetNoteVersions(ctx context.Context, noteID int64) ([]NoteVers
 }
 
 const getNotesByFolder = `-- name: GetNotesByFolder :many
-SELECT id, title, content, created_at, updated_at, embedding_synced, expires_at, source, source_ref, folder_id, pinned, pinned_at FROM notes
-WHERE folder_id = ?
+SELECT id, title, content, created_at, updated_at, embedding_synced, expires_at, source, source_ref, folder_id, pinned, pinned_at, created_by, deleted_at, archived_at, lang FROM notes
+WHERE folder_id = ? AND deleted_at IS NULL
 ORDER BY created_at DESC
 `
 
@@ -693,6 +1167,10 @@ func (q *Queries) GetNotesByFolder(ctx context.Context, folderID sql.NullInt64)
 			&i.FolderID,
 			&i.Pinned,
 			&i.PinnedAt,
+			&i.CreatedBy,
+			&i.DeletedAt,
+			&i.ArchivedAt,
+			&i.Lang,
 		); err != nil {
 			return nil, err
 		}
@@ -708,10 +1186,10 @@ func (q *Queries) GetNotesByFolder(ctx context.Context, folderID sql.NullInt64)
 }
 
 const getNotesByTagName = `-- name: GetNotesByTagName :many
-SELECT n.id, n.title, n.content, n.created_at, n.updated_at, n.embedding_synced, n.expires_at, n.source, n.source_ref, n.folder_id, n.pinned, n.pinned_at FROM notes n
+SELECT n.id, n.title, n.content, n.created_at, n.updated_at, n.embedding_synced, n.expires_at, n.source, n.source_ref, n.folder_id, n.pinned, n.pinned_at, n.created_by, n.deleted_at, n.archived_at, n.lang FROM notes n
 INNER JOIN note_tags nt ON n.id = nt.note_id
 INNER JOIN tags t ON nt.tag_id = t.id
-WHERE t.name = ?
+WHERE t.name = ? AND n.deleted_at IS NULL
 ORDER BY n.created_at DESC
 `
 
@@ -737,6 +1215,10 @@ func (q *Queries) GetNotesByTagName(ctx context.Context, name string) ([]Note, e
 			&i.FolderID,
 			&i.Pinned,
 			&i.PinnedAt,
+			&i.CreatedBy,
+			&i.DeletedAt,
+			&i.ArchivedAt,
+			&i.Lang,
 		); err != nil {
 			return nil, err
 		}
@@ -752,9 +1234,9 @@ func (q *Queries) GetNotesByTagName(ctx context.Context, name string) ([]Note, e
 }
 
 const getNotesForTag = `-- name: GetNotesForTag :many
-SELECT n.id, n.title, n.content, n.created_at, n.updated_at, n.embedding_synced, n.expires_at, n.source, n.source_ref, n.folder_id, n.pinned, n.pinned_at FROM notes n
+SELECT n.id, n.title, n.content, n.created_at, n.updated_at, n.embedding_synced, n.expires_at, n.source, n.source_ref, n.folder_id, n.pinned, n.pinned_at, n.created_by, n.deleted_at, n.archived_at, n.lang FROM notes n
 INNER JOIN note_tags nt ON n.id = nt.note_id
-WHERE nt.tag_id = ?
+WHERE nt.tag_id = ? AND n.deleted_at IS NULL
 ORDER BY n.created_at DESC
 `
 
@@ -780,6 +1262,10 @@ func (q *Queries) GetNotesForTag(ctx context.Context, tagID int64) ([]Note, erro
 			&i.FolderID,
 			&i.Pinned,
 			&i.PinnedAt,
+			&i.CreatedBy,
+			&i.DeletedAt,
+			&i.ArchivedAt,
+			&i.Lang,
 		); err != nil {
 			return nil, err
 		}
@@ -794,12 +1280,20 @@ func (q *Queries) GetNotesForTag(ctx context.Context, tagID int64) ([]Note, erro
 	return items, nil
 }
 
-const getNotesSince = `-- name: GetNotesSince :many
-SELECT id, title, content, created_at, updated_at, embedding_synced, expires_at, source, source_ref, folder_id, pinned, pinned_at FROM notes WHERE created_at >= ? ORDER BY created_at DESC
+const getNotesInFolderTree = `-- name: GetNotesInFolderTree :many
+WITH RECURSIVE subfolders(id) AS (
+  SELECT CAST(?1 AS INTEGER)
+  UNION
+  SELECT f.id FROM folders f JOIN subfolders s ON f.parent_id = s.id
+)
+SELECT id, title, content, created_at, updated_at, embedding_synced, expires_at, source, source_ref, folder_id, pinned, pinned_at, created_by, deleted_at, archived_at, lang FROM notes
+WHERE folder_id IN (SELECT id FROM subfolders) AND deleted_at IS NULL
+ORDER BY created_at DESC
 `
 
-func (q *Queries) GetNotesSince(ctx context.Context, createdAt sql.NullTime) ([]Note, error) {
-	rows, err := q.db.QueryContext(ctx, getNotesSince, createdAt)
+// Notes in a folder or any folder below it. UNION stops the walk should parent_id form a cycle.
+func (q *Queries) GetNotesInFolderTree(ctx context.Context, folderID int64) ([]Note, error) {
+	rows, err := q.db.QueryContext(ctx, getNotesInFolderTree, folderID)
 	if err != nil {
 		return nil, err
 	}
@@ -820,6 +1314,10 @@ func (q *Queries) GetNotesSince(ctx context.Context, createdAt sql.NullTime) ([]
 			&i.FolderID,
 			&i.Pinned,
 			&i.PinnedAt,
+			&i.CreatedBy,
+			&i.DeletedAt,
+			&i.ArchivedAt,
+			&i.Lang,
 		); err != nil {
 			return nil, err
 		}
@@ -834,14 +1332,15 @@ func (q *Queries) GetNotesSince(ctx context.Context, createdAt sql.NullTime) ([]
 	return items, nil
 }
 
-const getNotesWithoutFolder = `-- name: GetNotesWithoutFolder :many
-SELECT id, title, content, created_at, updated_at, embedding_synced, expires_at, source, source_ref, folder_id, pinned, pinned_at FROM notes
-WHERE folder_id IS NULL
-ORDER BY created_at DESC
+const getNotesMentioning = `-- name: GetNotesMentioning :many
+SELECT n.id, n.title, n.content, n.created_at, n.updated_at, n.embedding_synced, n.expires_at, n.source, n.source_ref, n.folder_id, n.pinned, n.pinned_at, n.created_by, n.deleted_at, n.archived_at, n.lang FROM notes n
+INNER JOIN note_mentions m ON n.id = m.note_id
+WHERE m.name = ? AND n.deleted_at IS NULL
+ORDER BY n.updated_at DESC
 `
 
-func (q *Queries) GetNotesWithoutFolder(ctx context.Context) ([]Note, error) {
-	rows, err := q.db.QueryContext(ctx, getNotesWithoutFolder)
+func (q *Queries) GetNotesMentioning(ctx context.Context, name string) ([]Note, error) {
+	rows, err := q.db.QueryContext(ctx, getNotesMentioning, name)
 	if err != nil {
 		return nil, err
 	}
@@ -862,6 +1361,10 @@ func (q *Queries) GetNotesWithoutFolder(ctx context.Context) ([]Note, error) {
 			&i.FolderID,
 			&i.Pinned,
 			&i.PinnedAt,
+			&i.CreatedBy,
+			&i.DeletedAt,
+			&i.ArchivedAt,
+			&i.Lang,
 		); err != nil {
 			return nil, err
 		}
@@ -876,17 +1379,18 @@ func (q *Queries) GetNotesWithoutFolder(ctx context.Context) ([]Note, error) {
 	return items, nil
 }
 
-const getOrphanNotes = `-- name: GetOrphanNotes :many
+const getNotesOnDate = `-- name: GetNotesOnDate :many
 
-SELECT id, title, content, created_at, updated_at, embedding_synced, expires_at, source, source_ref, folder_id, pinned, pinned_at FROM notes
-WHERE id NOT IN (SELECT source_note_id FROM note_links)
-AND id NOT IN (SELECT target_note_id FROM note_links)
-ORDER BY title
+SELECT id, title, content, created_at, updated_at, embedding_synced, expires_at, source, source_ref, folder_id, pinned, pinned_at, created_by, deleted_at, archived_at, lang FROM notes
+WHERE (date(created_at, 'localtime') = CAST(?1 AS TEXT)
+   OR date(updated_at, 'localtime') = CAST(?1 AS TEXT))
+  AND deleted_at IS NULL
+ORDER BY created_at
 `
 
-// Link health queries
-func (q *Queries) GetOrphanNotes(ctx context.Context) ([]Note, error) {
-	rows, err := q.db.QueryContext(ctx, getOrphanNotes)
+// Browsing by date --
+func (q *Queries) GetNotesOnDate(ctx context.Context, day string) ([]Note, error) {
+	rows, err := q.db.QueryContext(ctx, getNotesOnDate, day)
 	if err != nil {
 		return nil, err
 	}
@@ -907,6 +1411,10 @@ func (q *Queries) GetOrphanNotes(ctx context.Context) ([]Note, error) {
 			&i.FolderID,
 			&i.Pinned,
 			&i.PinnedAt,
+			&i.CreatedBy,
+			&i.DeletedAt,
+			&i.ArchivedAt,
+			&i.Lang,
 		); err != nil {
 			return nil, err
 		}
@@ -921,15 +1429,21 @@ func (q *Queries) GetOrphanNotes(ctx context.Context) ([]Note, error) {
 	return items, nil
 }
 
-const getOutlinks = `-- name: GetOutlinks :many
-SELECT n.id, n.title, n.content, n.created_at, n.updated_at, n.embedding_synced, n.expires_at, n.source, n.source_ref, n.folder_id, n.pinned, n.pinned_at FROM notes n
-INNER JOIN note_links nl ON n.id = nl.target_note_id
-WHERE nl.source_note_id = ?
-ORDER BY n.title
+const getNotesOnThisDay = `-- name: GetNotesOnThisDay :many
+SELECT id, title, content, created_at, updated_at, embedding_synced, expires_at, source, source_ref, folder_id, pinned, pinned_at, created_by, deleted_at, archived_at, lang FROM notes
+WHERE strftime('%m-%d', created_at, 'localtime') = CAST(?1 AS TEXT)
+  AND strftime('%Y', created_at, 'localtime') < CAST(?2 AS TEXT)
+  AND deleted_at IS NULL
+ORDER BY created_at DESC
 `
 
-func (q *Queries) GetOutlinks(ctx context.Context, sourceNoteID int64) ([]Note, error) {
-	rows, err := q.db.QueryContext(ctx, getOutlinks, sourceNoteID)
+type GetNotesOnThisDayParams struct {
+	MonthDay   string `json:"month_day"`
+	BeforeYear string `json:"before_year"`
+}
+
+func (q *Queries) GetNotesOnThisDay(ctx context.Context, arg GetNotesOnThisDayParams) ([]Note, error) {
+	rows, err := q.db.QueryContext(ctx, getNotesOnThisDay, arg.MonthDay, arg.BeforeYear)
 	if err != nil {
 		return nil, err
 	}
@@ -950,6 +1464,10 @@ func (q *Queries) GetOutlinks(ctx context.Context, sourceNoteID int64) ([]Note,
 			&i.FolderID,
 			&i.Pinned,
 			&i.PinnedAt,
+			&i.CreatedBy,
+			&i.DeletedAt,
+			&i.ArchivedAt,
+			&i.Lang,
 		); err != nil {
 			return nil, err
 		}
@@ -964,12 +1482,12 @@ func (q *Queries) GetOutlinks(ctx context.Context, sourceNoteID int64) ([]Note,
 	return items, nil
 }
 
-const getPinnedNotes = `-- name: GetPinnedNotes :many
-SELECT id, title, content, created_at, updated_at, embedding_synced, expires_at, source, source_ref, folder_id, pinned, pinned_at FROM notes WHERE pinned = TRUE ORDER BY pinned_at DESC
+const getNotesSince = `-- name: GetNotesSince :many
+SELECT id, title, content, created_at, updated_at, embedding_synced, expires_at, source, source_ref, folder_id, pinned, pinned_at, created_by, deleted_at, archived_at, lang FROM notes WHERE created_at >= ? AND deleted_at IS NULL ORDER BY created_at DESC
 `
 
-func (q *Queries) GetPinnedNotes(ctx context.Context) ([]Note, error) {
-	rows, err := q.db.QueryContext(ctx, getPinnedNotes)
+func (q *Queries) GetNotesSince(ctx context.Context, createdAt sql.NullTime) ([]Note, error) {
+	rows, err := q.db.QueryContext(ctx, getNotesSince, createdAt)
 	if err != nil {
 		return nil, err
 	}
@@ -990,6 +1508,10 @@ func (q *Queries) GetPinnedNotes(ctx context.Context) ([]Note, error) {
 			&i.FolderID,
 			&i.Pinned,
 			&i.PinnedAt,
+			&i.CreatedBy,
+			&i.DeletedAt,
+			&i.ArchivedAt,
+			&i.Lang,
 		); err != nil {
 			return nil, err
 		}
@@ -1004,46 +1526,37 @@ func (q *Queries) GetPinnedNotes(ctx context.Context) ([]Note, error) {
 	return items, nil
 }
 
-const getTag = `-- name: GetTag :one
-SELECT id, name FROM tags WHERE id = ?
-`
-
-func (q *Queries) GetTag(ctx context.Context, id int64) (Tag, error) {
-	row := q.db.QueryRowContext(ctx, getTag, id)
-	var i Tag
-	err := row.Scan(&i.ID, &i.Name)
-	return i, err
-}
-
-const getTagByName = `-- name: GetTagByName :one
-SELECT id, name FROM tags
-WHERE name = ?
-`
-
-func (q *Queries) GetTagByName(ctx context.Context, name string) (Tag, error) {
-	row := q.db.QueryRowContext(ctx, getTagByName, name)
-	var i Tag
-	err := row.Scan(&i.ID, &i.Name)
-	return i, err
-}
-
-const getTagsForNote = `-- name: GetTagsForNote :many
-SELECT t.id, t.name FROM tags t
-INNER JOIN note_tags nt ON t.id = nt.tag_id
-WHERE nt.note_id = ?
-ORDER BY t.name
+const getNotesUpdatedSince = `-- name: GetNotesUpdatedSince :many
+SELECT id, title, content, created_at, updated_at, embedding_synced, expires_at, source, source_ref, folder_id, pinned, pinned_at, created_by, deleted_at, archived_at, lang FROM notes WHERE updated_at >= ? AND deleted_at IS NULL ORDER BY updated_at DESC
 `
 
-func (q *Queries) GetTagsForNote(ctx context.Context, noteID int64) ([]Tag, error) {
-	rows, err := q.db.QueryContext(ctx, getTagsForNote, noteID)
+func (q *Queries) GetNotesUpdatedSince(ctx context.Context, updatedAt sql.NullTime) ([]Note, error) {
+	rows, err := q.db.QueryContext(ctx, getNotesUpdatedSince, updatedAt)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	items := []Tag{}
+	items := []Note{}
 	for rows.Next() {
-		var i Tag
-		if err := rows.Scan(&i.ID, &i.Name); err != nil {
+		var i Note
+		if err := rows.Scan(
+			&i.ID,
+			&i.Title,
+			&i.Content,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.EmbeddingSynced,
+			&i.ExpiresAt,
+			&i.Source,
+			&i.SourceRef,
+			&i.FolderID,
+			&i.Pinned,
+			&i.PinnedAt,
+			&i.CreatedBy,
+			&i.DeletedAt,
+			&i.ArchivedAt,
+			&i.Lang,
+		); err != nil {
 			return nil, err
 		}
 		items = append(items, i)
@@ -1057,30 +1570,39 @@ func (q *Queries) GetTagsForNote(ctx context.Context, noteID int64) ([]Tag, erro
 	return items, nil
 }
 
-const getTagsWithCount = `-- name: GetTagsWithCount :many
-SELECT t.id, t.name, COUNT(nt.note_id) as note_count
-FROM tags t
-LEFT JOIN note_tags nt ON t.id = nt.tag_id
-GROUP BY t.id
-ORDER BY t.name
+const getNotesWithoutFolder = `-- name: GetNotesWithoutFolder :many
+SELECT id, title, content, created_at, updated_at, embedding_synced, expires_at, source, source_ref, folder_id, pinned, pinned_at, created_by, deleted_at, archived_at, lang FROM notes
+WHERE folder_id IS NULL AND deleted_at IS NULL
+ORDER BY created_at DESC
 `
 
-type GetTagsWithCountRow struct {
-	ID        int64  `json:"id"`
-	Name      string `json:"name"`
-	NoteCount int64  `json:"note_count"`
-}
-
-func (q *Queries) GetTagsWithCount(ctx context.Context) ([]GetTagsWithCountRow, error) {
-	rows, err := q.db.QueryContext(ctx, getTagsWithCount)
+func (q *Queries) GetNotesWithoutFolder(ctx context.Context) ([]Note, error) {
+	rows, err := q.db.QueryContext(ctx, getNotesWithoutFolder)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	items := []GetTagsWithCountRow{}
+	items := []Note{}
 	for rows.Next() {
-		var i GetTagsWithCountRow
-		if err := rows.Scan(&i.ID, &i.Name, &i.NoteCount); err != nil {
+		var i Note
+		if err := rows.Scan(
+			&i.ID,
+			&i.Title,
+			&i.Content,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.EmbeddingSynced,
+			&i.ExpiresAt,
+			&i.Source,
+			&i.SourceRef,
+			&i.FolderID,
+			&i.Pinned,
+			&i.PinnedAt,
+			&i.CreatedBy,
+			&i.DeletedAt,
+			&i.ArchivedAt,
+			&i.Lang,
+		); err != nil {
 			return nil, err
 		}
 		items = append(items, i)
@@ -1094,47 +1616,18 @@ func (q *Queries) GetTagsWithCount(ctx context.Context) ([]GetTagsWithCountRow,
 	return items, nil
 }
 
-const getTemplate = `-- name: GetTemplate :one
-SELECT id, name, content, created_at, updated_at FROM templates WHERE id = ?
-`
-
-func (q *Queries) GetTemplate(ctx context.Context, id int64) (Template, error) {
-	row := q.db.QueryRowContext(ctx, getTemplate, id)
-	var i Template
-	err := row.Scan(
-		&i.ID,
-		&i.Name,
-		&i.Content,
-		&i.CreatedAt,
-		&i.UpdatedAt,
-	)
-	return i, err
-}
-
-const getTemplateByName = `-- name: GetTemplateByName :one
-SELECT id, name, content, created_at, updated_at FROM templates WHERE name = ?
-`
-
-func (q *Queries) GetTemplateByName(ctx context.Context, name string) (Template, error) {
-	row := q.db.QueryRowContext(ctx, getTemplateByName, name)
-	var i Template
-	err := row.Scan(
-		&i.ID,
-		&i.Name,
-		&i.Content,
-		&i.CreatedAt,
-		&i.UpdatedAt,
-	)
-	return i, err
-}
+const getOrphanNotes = `-- name: GetOrphanNotes :many
 
-const getUnsynced = `-- name: GetUnsynced :many
-SELECT id, title, content, created_at, updated_at, embedding_synced, expires_at, source, source_ref, folder_id, pinned, pinned_at FROM notes
-WHERE embedding_synced = FALSE
+SELECT id, title, content, created_at, updated_at, embedding_synced, expires_at, source, source_ref, folder_id, pinned, pinned_at, created_by, deleted_at, archived_at, lang FROM notes
+WHERE id NOT IN (SELECT source_note_id FROM note_links)
+AND id NOT IN (SELECT target_note_id FROM note_links)
+AND deleted_at IS NULL
+ORDER BY title
 `
 
-func (q *Queries) GetUnsynced(ctx context.Context) ([]Note, error) {
-	rows, err := q.db.QueryContext(ctx, getUnsynced)
+// Link health queries
+func (q *Queries) GetOrphanNotes(ctx context.Context) ([]Note, error) {
+	rows, err := q.db.QueryContext(ctx, getOrphanNotes)
 	if err != nil {
 		return nil, err
 	}
@@ -1155,6 +1648,10 @@ func (q *Queries) GetUnsynced(ctx context.Context) ([]Note, error) {
 			&i.FolderID,
 			&i.Pinned,
 			&i.PinnedAt,
+			&i.CreatedBy,
+			&i.DeletedAt,
+			&i.ArchivedAt,
+			&i.Lang,
 		); err != nil {
 			return nil, err
 		}
@@ -1169,25 +1666,39 @@ func (q *Queries) GetUnsynced(ctx context.Context) ([]Note, error) {
 	return items, nil
 }
 
-const listFolders = `-- name: ListFolders :many
-SELECT id, name, parent_id, created_at, updated_at FROM folders ORDER BY name
+const getOutlinks = `-- name: GetOutlinks :many
+SELECT n.id, n.title, n.content, n.created_at, n.updated_at, n.embedding_synced, n.expires_at, n.source, n.source_ref, n.folder_id, n.pinned, n.pinned_at, n.created_by, n.deleted_at, n.archived_at, n.lang FROM notes n
+INNER JOIN note_links nl ON n.id = nl.target_note_id
+WHERE nl.source_note_id = ? AND n.deleted_at IS NULL
+ORDER BY n.title
 `
 
-func (q *Queries) ListFolders(ctx context.Context) ([]Folder, error) {
-	rows, err := q.db.QueryContext(ctx, listFolders)
+func (q *Queries) GetOutlinks(ctx context.Context, sourceNoteID int64) ([]Note, error) {
+	rows, err := q.db.QueryContext(ctx, getOutlinks, sourceNoteID)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	items := []Folder{}
+	items := []Note{}
 	for rows.Next() {
-		var i Folder
+		var i Note
 		if err := rows.Scan(
 			&i.ID,
-			&i.Name,
-			&i.ParentID,
+			&i.Title,
+			&i.Content,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.EmbeddingSynced,
+			&i.ExpiresAt,
+			&i.Source,
+			&i.SourceRef,
+			&i.FolderID,
+			&i.Pinned,
+			&i.PinnedAt,
+			&i.CreatedBy,
+			&i.DeletedAt,
+			&i.ArchivedAt,
+			&i.Lang,
 		); err != nil {
 			return nil, err
 		}
@@ -1202,19 +1713,12 @@ func (q *Queries) ListFolders(ctx context.Context) ([]Folder, error) {
 	return items, nil
 }
 
-const listNotes = `-- name: ListNotes :many
-SELECT id, title, content, created_at, updated_at, embedding_synced, expires_at, source, source_ref, folder_id, pinned, pinned_at FROM notes
-ORDER BY created_at DESC
-LIMIT ? OFFSET ?
+const getPinnedNotes = `-- name: GetPinnedNotes :many
+SELECT id, title, content, created_at, updated_at, embedding_synced, expires_at, source, source_ref, folder_id, pinned, pinned_at, created_by, deleted_at, archived_at, lang FROM notes WHERE pinned = TRUE AND deleted_at IS NULL ORDER BY pinned_at DESC
 `
 
-type ListNotesParams struct {
-	Limit  int64 `json:"limit"`
-	Offset int64 `json:"offset"`
-}
-
-func (q *Queries) ListNotes(ctx context.Context, arg ListNotesParams) ([]Note, error) {
-	rows, err := q.db.QueryContext(ctx, listNotes, arg.Limit, arg.Offset)
+func (q *Queries) GetPinnedNotes(ctx context.Context) ([]Note, error) {
+	rows, err := q.db.QueryContext(ctx, getPinnedNotes)
 	if err != nil {
 		return nil, err
 	}
@@ -1235,6 +1739,10 @@ func (q *Queries) ListNotes(ctx context.Context, arg ListNotesParams) ([]Note, e
 			&i.FolderID,
 			&i.Pinned,
 			&i.PinnedAt,
+			&i.CreatedBy,
+			&i.DeletedAt,
+			&i.ArchivedAt,
+			&i.Lang,
 		); err != nil {
 			return nil, err
 		}
@@ -1249,13 +1757,73 @@ func (q *Queries) ListNotes(ctx context.Context, arg ListNotesParams) ([]Note, e
 	return items, nil
 }
 
-const listTags = `-- name: ListTags :many
-SELECT id, name FROM tags
-ORDER BY name
+const getScheduleByName = `-- name: GetScheduleByName :one
+SELECT id, name, rule, title, template_name, tags, folder_id, next_run_at, last_run_at, last_note_id, created_at FROM schedules WHERE name = ?
 `
 
-func (q *Queries) ListTags(ctx context.Context) ([]Tag, error) {
-	rows, err := q.db.QueryContext(ctx, listTags)
+func (q *Queries) GetScheduleByName(ctx context.Context, name string) (Schedule, error) {
+	row := q.db.QueryRowContext(ctx, getScheduleByName, name)
+	var i Schedule
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Rule,
+		&i.Title,
+		&i.TemplateName,
+		&i.Tags,
+		&i.FolderID,
+		&i.NextRunAt,
+		&i.LastRunAt,
+		&i.LastNoteID,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getTag = `-- name: GetTag :one
+SELECT id, name, color, description, note_count FROM tags WHERE id = ?
+`
+
+func (q *Queries) GetTag(ctx context.Context, id int64) (Tag, error) {
+	row := q.db.QueryRowContext(ctx, getTag, id)
+	var i Tag
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Color,
+		&i.Description,
+		&i.NoteCount,
+	)
+	return i, err
+}
+
+const getTagByName = `-- name: GetTagByName :one
+SELECT id, name, color, description, note_count FROM tags
+WHERE name = ?
+`
+
+func (q *Queries) GetTagByName(ctx context.Context, name string) (Tag, error) {
+	row := q.db.QueryRowContext(ctx, getTagByName, name)
+	var i Tag
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Color,
+		&i.Description,
+		&i.NoteCount,
+	)
+	return i, err
+}
+
+const getTagsForNote = `-- name: GetTagsForNote :many
+SELECT t.id, t.name, t.color, t.description, t.note_count FROM tags t
+INNER JOIN note_tags nt ON t.id = nt.tag_id
+WHERE nt.note_id = ?
+ORDER BY t.name
+`
+
+func (q *Queries) GetTagsForNote(ctx context.Context, noteID int64) ([]Tag, error) {
+	rows, err := q.db.QueryContext(ctx, getTagsForNote, noteID)
 	if err != nil {
 		return nil, err
 	}
@@ -1263,7 +1831,13 @@ func (q *Queries) ListTags(ctx context.Context) ([]Tag, error) {
 	items := []Tag{}
 	for rows.Next() {
 		var i Tag
-		if err := rows.Scan(&i.ID, &i.Name); err != nil {
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Color,
+			&i.Description,
+			&i.NoteCount,
+		); err != nil {
 			return nil, err
 		}
 		items = append(items, i)
@@ -1277,25 +1851,27 @@ func (q *Queries) ListTags(ctx context.Context) ([]Tag, error) {
 	return items, nil
 }
 
-const listTemplates = `-- name: ListTemplates :many
-SELECT id, name, content, created_at, updated_at FROM templates ORDER BY name
+const getTagsWithCount = `-- name: GetTagsWithCount :many
+SELECT id, name, color, description, note_count
+FROM tags
+ORDER BY name
 `
 
-func (q *Queries) ListTemplates(ctx context.Context) ([]Template, error) {
-	rows, err := q.db.QueryContext(ctx, listTemplates)
+func (q *Queries) GetTagsWithCount(ctx context.Context) ([]Tag, error) {
+	rows, err := q.db.QueryContext(ctx, getTagsWithCount)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	items := []Template{}
+	items := []Tag{}
 	for rows.Next() {
-		var i Template
+		var i Tag
 		if err := rows.Scan(
 			&i.ID,
 			&i.Name,
-			&i.Content,
-			&i.CreatedAt,
-			&i.UpdatedAt,
+			&i.Color,
+			&i.Description,
+			&i.NoteCount,
 		); err != nil {
 			return nil, err
 		}
@@ -1310,19 +1886,810 @@ func (q *Queries) ListTemplates(ctx context.Context) ([]Template, error) {
 	return items, nil
 }
 
-const markEmbeddingSynced = `-- name: MarkEmbeddingSynced :exec
-UPDATE notes
-SET embedding_synced = TRUE
-WHERE id = ?
+const getTemplate = `-- name: GetTemplate :one
+SELECT id, name, content, created_at, updated_at FROM templates WHERE id = ?
 `
 
-func (q *Queries) MarkEmbeddingSynced(ctx context.Context, id int64) error {
-	_, err := q.db.ExecContext(ctx, markEmbeddingSynced, id)
-	return err
+func (q *Queries) GetTemplate(ctx context.Context, id int64) (Template, error) {
+	row := q.db.QueryRowContext(ctx, getTemplate, id)
+	var i Template
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Content,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
 }
 
-const moveNoteToFolder = `-- name: MoveNoteToFolder :exec
-UPDATE notes
+const getTemplateByName = `-- name: GetTemplateByName :one
+SELECT id, name, content, created_at, updated_at FROM templates WHERE name = ?
+`
+
+func (q *Queries) GetTemplateByName(ctx context.Context, name string) (Template, error) {
+	row := q.db.QueryRowContext(ctx, getTemplateByName, name)
+	var i Template
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Content,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getTrashedNote = `-- name: GetTrashedNote :one
+SELECT id, title, content, created_at, updated_at, embedding_synced, expires_at, source, source_ref, folder_id, pinned, pinned_at, created_by, deleted_at, archived_at, lang FROM notes WHERE id = ? AND deleted_at IS NOT NULL
+`
+
+func (q *Queries) GetTrashedNote(ctx context.Context, id int64) (Note, error) {
+	row := q.db.QueryRowContext(ctx, getTrashedNote, id)
+	var i Note
+	err := row.Scan(
+		&i.ID,
+		&i.Title,
+		&i.Content,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.EmbeddingSynced,
+		&i.ExpiresAt,
+		&i.Source,
+		&i.SourceRef,
+		&i.FolderID,
+		&i.Pinned,
+		&i.PinnedAt,
+		&i.CreatedBy,
+		&i.DeletedAt,
+		&i.ArchivedAt,
+		&i.Lang,
+	)
+	return i, err
+}
+
+const getTrashedNotesBefore = `-- name: GetTrashedNotesBefore :many
+SELECT id, title, content, created_at, updated_at, embedding_synced, expires_at, source, source_ref, folder_id, pinned, pinned_at, created_by, deleted_at, archived_at, lang FROM notes WHERE deleted_at IS NOT NULL AND deleted_at < ? ORDER BY id
+`
+
+func (q *Queries) GetTrashedNotesBefore(ctx context.Context, deletedAt sql.NullTime) ([]Note, error) {
+	rows, err := q.db.QueryContext(ctx, getTrashedNotesBefore, deletedAt)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Note{}
+	for rows.Next() {
+		var i Note
+		if err := rows.Scan(
+			&i.ID,
+			&i.Title,
+			&i.Content,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.EmbeddingSynced,
+			&i.ExpiresAt,
+			&i.Source,
+			&i.SourceRef,
+			&i.FolderID,
+			&i.Pinned,
+			&i.PinnedAt,
+			&i.CreatedBy,
+			&i.DeletedAt,
+			&i.ArchivedAt,
+			&i.Lang,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getUnsynced = `-- name: GetUnsynced :many
+SELECT id, title, content, created_at, updated_at, embedding_synced, expires_at, source, source_ref, folder_id, pinned, pinned_at, created_by, deleted_at, archived_at, lang FROM notes
+WHERE embedding_synced = FALSE AND deleted_at IS NULL
+ORDER BY id
+`
+
+func (q *Queries) GetUnsynced(ctx context.Context) ([]Note, error) {
+	rows, err := q.db.QueryContext(ctx, getUnsynced)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Note{}
+	for rows.Next() {
+		var i Note
+		if err := rows.Scan(
+			&i.ID,
+			&i.Title,
+			&i.Content,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.EmbeddingSynced,
+			&i.ExpiresAt,
+			&i.Source,
+			&i.SourceRef,
+			&i.FolderID,
+			&i.Pinned,
+			&i.PinnedAt,
+			&i.CreatedBy,
+			&i.DeletedAt,
+			&i.ArchivedAt,
+			&i.Lang,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getUnusedTags = `-- name: GetUnusedTags :many
+SELECT id, name, color, description, note_count FROM tags
+WHERE id NOT IN (SELECT DISTINCT tag_id FROM note_tags)
+ORDER BY name
+`
+
+func (q *Queries) GetUnusedTags(ctx context.Context) ([]Tag, error) {
+	rows, err := q.db.QueryContext(ctx, getUnusedTags)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Tag{}
+	for rows.Next() {
+		var i Tag
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Color,
+			&i.Description,
+			&i.NoteCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listArchivedNotes = `-- name: ListArchivedNotes :many
+SELECT id, title, content, created_at, updated_at, embedding_synced, expires_at, source, source_ref, folder_id, pinned, pinned_at, created_by, deleted_at, archived_at, lang FROM notes WHERE archived_at IS NOT NULL AND deleted_at IS NULL ORDER BY archived_at DESC, id DESC
+`
+
+func (q *Queries) ListArchivedNotes(ctx context.Context) ([]Note, error) {
+	rows, err := q.db.QueryContext(ctx, listArchivedNotes)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Note{}
+	for rows.Next() {
+		var i Note
+		if err := rows.Scan(
+			&i.ID,
+			&i.Title,
+			&i.Content,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.EmbeddingSynced,
+			&i.ExpiresAt,
+			&i.Source,
+			&i.SourceRef,
+			&i.FolderID,
+			&i.Pinned,
+			&i.PinnedAt,
+			&i.CreatedBy,
+			&i.DeletedAt,
+			&i.ArchivedAt,
+			&i.Lang,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listExportFiles = `-- name: ListExportFiles :many
+
+SELECT note_id, path, hash FROM export_files WHERE destination = ? ORDER BY note_id
+`
+
+type ListExportFilesRow struct {
+	NoteID int64  `json:"note_id"`
+	Path   string `json:"path"`
+	Hash   string `json:"hash"`
+}
+
+// Export cursors --
+func (q *Queries) ListExportFiles(ctx context.Context, destination string) ([]ListExportFilesRow, error) {
+	rows, err := q.db.QueryContext(ctx, listExportFiles, destination)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListExportFilesRow{}
+	for rows.Next() {
+		var i ListExportFilesRow
+		if err := rows.Scan(&i.NoteID, &i.Path, &i.Hash); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listFolders = `-- name: ListFolders :many
+SELECT id, name, parent_id, created_at, updated_at FROM folders ORDER BY name
+`
+
+func (q *Queries) ListFolders(ctx context.Context) ([]Folder, error) {
+	rows, err := q.db.QueryContext(ctx, listFolders)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Folder{}
+	for rows.Next() {
+		var i Folder
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.ParentID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listMentionNames = `-- name: ListMentionNames :many
+SELECT m.name, COUNT(*) AS note_count FROM note_mentions m
+INNER JOIN notes n ON n.id = m.note_id
+WHERE n.deleted_at IS NULL
+GROUP BY m.name
+ORDER BY note_count DESC, m.name
+`
+
+type ListMentionNamesRow struct {
+	Name      string `json:"name"`
+	NoteCount int64  `json:"note_count"`
+}
+
+func (q *Queries) ListMentionNames(ctx context.Context) ([]ListMentionNamesRow, error) {
+	rows, err := q.db.QueryContext(ctx, listMentionNames)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListMentionNamesRow{}
+	for rows.Next() {
+		var i ListMentionNamesRow
+		if err := rows.Scan(&i.Name, &i.NoteCount); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listNoteAliases = `-- name: ListNoteAliases :many
+SELECT note_id, alias FROM note_aliases ORDER BY note_id, alias
+`
+
+func (q *Queries) ListNoteAliases(ctx context.Context) ([]NoteAlias, error) {
+	rows, err := q.db.QueryContext(ctx, listNoteAliases)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []NoteAlias{}
+	for rows.Next() {
+		var i NoteAlias
+		if err := rows.Scan(&i.NoteID, &i.Alias); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listNoteContents = `-- name: ListNoteContents :many
+SELECT id, content FROM notes ORDER BY id
+`
+
+type ListNoteContentsRow struct {
+	ID      int64  `json:"id"`
+	Content string `json:"content"`
+}
+
+func (q *Queries) ListNoteContents(ctx context.Context) ([]ListNoteContentsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listNoteContents)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListNoteContentsRow{}
+	for rows.Next() {
+		var i ListNoteContentsRow
+		if err := rows.Scan(&i.ID, &i.Content); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listNotes = `-- name: ListNotes :many
+SELECT notes.id, notes.title, notes.content, notes.created_at, notes.updated_at, notes.embedding_synced, notes.expires_at, notes.source, notes.source_ref, notes.folder_id, notes.pinned, notes.pinned_at, notes.created_by, notes.deleted_at, notes.archived_at, notes.lang FROM notes,
+  (SELECT CAST(?1 AS TEXT) AS sort_key, CAST(?2 AS BOOLEAN) AS ascending) AS o
+WHERE deleted_at IS NULL AND (CAST(?3 AS BOOLEAN) OR archived_at IS NULL)
+  AND (CAST(?4 AS TEXT) = '' OR lang = ?4)
+  AND (CAST(?5 AS DATETIME) IS NULL OR updated_at >= ?5)
+  AND (CAST(?6 AS DATETIME) IS NULL OR updated_at < ?6)
+ORDER BY
+  CASE WHEN o.sort_key = 'pinned' THEN COALESCE(pinned, 0) END DESC,
+  CASE WHEN o.sort_key = 'title' AND o.ascending THEN title COLLATE NOCASE END ASC,
+  CASE WHEN o.sort_key = 'title' AND NOT o.ascending THEN title COLLATE NOCASE END DESC,
+  CASE WHEN o.sort_key IN ('updated', 'pinned') AND o.ascending THEN updated_at END ASC,
+  CASE WHEN o.sort_key IN ('updated', 'pinned') AND NOT o.ascending THEN updated_at END DESC,
+  CASE WHEN o.ascending THEN created_at END ASC,
+  CASE WHEN o.ascending THEN id END ASC,
+  created_at DESC, id DESC
+LIMIT ?8 OFFSET ?7
+`
+
+type ListNotesParams struct {
+	Sort            string       `json:"sort"`
+	Ascending       bool         `json:"ascending"`
+	IncludeArchived bool         `json:"include_archived"`
+	Lang            string       `json:"lang"`
+	UpdatedSince    sql.NullTime `json:"updated_since"`
+	UpdatedBefore   sql.NullTime `json:"updated_before"`
+	Offset          int64        `json:"offset"`
+	Limit           int64        `json:"limit"`
+}
+
+// sort is "created" (or ""), "updated", "title", or "pinned": pinned notes first, then by last
+// update. Keep SortNotes in step.
+func (q *Queries) ListNotes(ctx context.Context, arg ListNotesParams) ([]Note, error) {
+	rows, err := q.db.QueryContext(ctx, listNotes,
+		arg.Sort,
+		arg.Ascending,
+		arg.IncludeArchived,
+		arg.Lang,
+		arg.UpdatedSince,
+		arg.UpdatedBefore,
+		arg.Offset,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Note{}
+	for rows.Next() {
+		var i Note
+		if err := rows.Scan(
+			&i.ID,
+			&i.Title,
+			&i.Content,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.EmbeddingSynced,
+			&i.ExpiresAt,
+			&i.Source,
+			&i.SourceRef,
+			&i.FolderID,
+			&i.Pinned,
+			&i.PinnedAt,
+			&i.CreatedBy,
+			&i.DeletedAt,
+			&i.ArchivedAt,
+			&i.Lang,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listRecentlyUpdatedNotes = `-- name: ListRecentlyUpdatedNotes :many
+SELECT id, title, content, created_at, updated_at, embedding_synced, expires_at, source, source_ref, folder_id, pinned, pinned_at, created_by, deleted_at, archived_at, lang FROM notes WHERE deleted_at IS NULL AND archived_at IS NULL
+ORDER BY updated_at DESC, id DESC
+LIMIT ?
+`
+
+func (q *Queries) ListRecentlyUpdatedNotes(ctx context.Context, limit int64) ([]Note, error) {
+	rows, err := q.db.QueryContext(ctx, listRecentlyUpdatedNotes, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Note{}
+	for rows.Next() {
+		var i Note
+		if err := rows.Scan(
+			&i.ID,
+			&i.Title,
+			&i.Content,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.EmbeddingSynced,
+			&i.ExpiresAt,
+			&i.Source,
+			&i.SourceRef,
+			&i.FolderID,
+			&i.Pinned,
+			&i.PinnedAt,
+			&i.CreatedBy,
+			&i.DeletedAt,
+			&i.ArchivedAt,
+			&i.Lang,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listSchedules = `-- name: ListSchedules :many
+SELECT id, name, rule, title, template_name, tags, folder_id, next_run_at, last_run_at, last_note_id, created_at FROM schedules ORDER BY next_run_at, name
+`
+
+func (q *Queries) ListSchedules(ctx context.Context) ([]Schedule, error) {
+	rows, err := q.db.QueryContext(ctx, listSchedules)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Schedule{}
+	for rows.Next() {
+		var i Schedule
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Rule,
+			&i.Title,
+			&i.TemplateName,
+			&i.Tags,
+			&i.FolderID,
+			&i.NextRunAt,
+			&i.LastRunAt,
+			&i.LastNoteID,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listSearchHistory = `-- name: ListSearchHistory :many
+SELECT query, interface, COUNT(*) AS uses, CAST(MAX(id) AS INTEGER) AS last_id, CAST(MAX(created_at) AS TEXT) AS last_searched_at
+FROM search_history
+WHERE CAST(?1 AS TEXT) = '' OR interface = ?1
+GROUP BY query, interface
+ORDER BY last_id DESC
+LIMIT ?2
+`
+
+type ListSearchHistoryParams struct {
+	InterfaceFilter string `json:"interface_filter"`
+	Limit           int64  `json:"limit"`
+}
+
+type ListSearchHistoryRow struct {
+	Query          string `json:"query"`
+	Interface      string `json:"interface"`
+	Uses           int64  `json:"uses"`
+	LastID         int64  `json:"last_id"`
+	LastSearchedAt string `json:"last_searched_at"`
+}
+
+func (q *Queries) ListSearchHistory(ctx context.Context, arg ListSearchHistoryParams) ([]ListSearchHistoryRow, error) {
+	rows, err := q.db.QueryContext(ctx, listSearchHistory, arg.InterfaceFilter, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListSearchHistoryRow{}
+	for rows.Next() {
+		var i ListSearchHistoryRow
+		if err := rows.Scan(
+			&i.Query,
+			&i.Interface,
+			&i.Uses,
+			&i.LastID,
+			&i.LastSearchedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTags = `-- name: ListTags :many
+SELECT id, name, color, description, note_count FROM tags
+ORDER BY name
+`
+
+func (q *Queries) ListTags(ctx context.Context) ([]Tag, error) {
+	rows, err := q.db.QueryContext(ctx, listTags)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Tag{}
+	for rows.Next() {
+		var i Tag
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Color,
+			&i.Description,
+			&i.NoteCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTemplates = `-- name: ListTemplates :many
+SELECT id, name, content, created_at, updated_at FROM templates ORDER BY name
+`
+
+func (q *Queries) ListTemplates(ctx context.Context) ([]Template, error) {
+	rows, err := q.db.QueryContext(ctx, listTemplates)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Template{}
+	for rows.Next() {
+		var i Template
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Content,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTrashedNotes = `-- name: ListTrashedNotes :many
+SELECT id, title, content, created_at, updated_at, embedding_synced, expires_at, source, source_ref, folder_id, pinned, pinned_at, created_by, deleted_at, archived_at, lang FROM notes WHERE deleted_at IS NOT NULL ORDER BY deleted_at DESC, id DESC
+`
+
+func (q *Queries) ListTrashedNotes(ctx context.Context) ([]Note, error) {
+	rows, err := q.db.QueryContext(ctx, listTrashedNotes)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Note{}
+	for rows.Next() {
+		var i Note
+		if err := rows.Scan(
+			&i.ID,
+			&i.Title,
+			&i.Content,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.EmbeddingSynced,
+			&i.ExpiresAt,
+			&i.Source,
+			&i.SourceRef,
+			&i.FolderID,
+			&i.Pinned,
+			&i.PinnedAt,
+			&i.CreatedBy,
+			&i.DeletedAt,
+			&i.ArchivedAt,
+			&i.Lang,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listUnresolvedLinks = `-- name: ListUnresolvedLinks :many
+SELECT p.target, n.id AS note_id, n.title AS note_title FROM pending_links p
+INNER JOIN notes n ON n.id = p.note_id
+WHERE n.deleted_at IS NULL
+AND NOT EXISTS (SELECT 1 FROM notes t WHERE t.title = p.target AND t.deleted_at IS NULL)
+AND NOT EXISTS (
+  SELECT 1 FROM note_aliases a INNER JOIN notes t ON t.id = a.note_id
+  WHERE a.alias = p.target AND t.deleted_at IS NULL
+)
+ORDER BY p.target, n.title, n.id
+`
+
+type ListUnresolvedLinksRow struct {
+	Target    string `json:"target"`
+	NoteID    int64  `json:"note_id"`
+	NoteTitle string `json:"note_title"`
+}
+
+// Link targets that match no live note's title or alias, from live notes.
+func (q *Queries) ListUnresolvedLinks(ctx context.Context) ([]ListUnresolvedLinksRow, error) {
+	rows, err := q.db.QueryContext(ctx, listUnresolvedLinks)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListUnresolvedLinksRow{}
+	for rows.Next() {
+		var i ListUnresolvedLinksRow
+		if err := rows.Scan(&i.Target, &i.NoteID, &i.NoteTitle); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const markEmbeddingSynced = `-- name: MarkEmbeddingSynced :exec
+UPDATE notes
+SET embedding_synced = TRUE
+WHERE id = ?
+`
+
+func (q *Queries) MarkEmbeddingSynced(ctx context.Context, id int64) error {
+	_, err := q.db.ExecContext(ctx, markEmbeddingSynced, id)
+	return err
+}
+
+const markEmbeddingUnsynced = `-- name: MarkEmbeddingUnsynced :exec
+UPDATE notes
+SET embedding_synced = FALSE
+WHERE id = ?
+`
+
+func (q *Queries) MarkEmbeddingUnsynced(ctx context.Context, id int64) error {
+	_, err := q.db.ExecContext(ctx, markEmbeddingUnsynced, id)
+	return err
+}
+
+const mergeTagInto = `-- name: MergeTagInto :exec
+INSERT OR IGNORE INTO note_tags (note_id, tag_id)
+SELECT nt.note_id, ?1 FROM note_tags nt
+WHERE nt.tag_id = ?2
+`
+
+type MergeTagIntoParams struct {
+	TargetID int64 `json:"target_id"`
+	SourceID int64 `json:"source_id"`
+}
+
+func (q *Queries) MergeTagInto(ctx context.Context, arg MergeTagIntoParams) error {
+	_, err := q.db.ExecContext(ctx, mergeTagInto, arg.TargetID, arg.SourceID)
+	return err
+}
+
+const moveNoteToFolder = `-- name: MoveNoteToFolder :exec
+UPDATE notes
 SET folder_id = ?, updated_at = CURRENT_TIMESTAMP
 WHERE id = ?
 `
@@ -1348,6 +2715,84 @@ func (q *Queries) PinNote(ctx context.Context, id int64) error {
 	return err
 }
 
+const pruneSearchHistory = `-- name: PruneSearchHistory :exec
+DELETE FROM search_history
+WHERE id NOT IN (SELECT id FROM search_history ORDER BY id DESC LIMIT ?)
+`
+
+func (q *Queries) PruneSearchHistory(ctx context.Context, limit int64) error {
+	_, err := q.db.ExecContext(ctx, pruneSearchHistory, limit)
+	return err
+}
+
+const recordSearch = `-- name: RecordSearch :exec
+
+INSERT INTO search_history (query, interface, result_count)
+VALUES (?, ?, ?)
+`
+
+type RecordSearchParams struct {
+	Query       string `json:"query"`
+	Interface   string `json:"interface"`
+	ResultCount int64  `json:"result_count"`
+}
+
+// Search history --
+func (q *Queries) RecordSearch(ctx context.Context, arg RecordSearchParams) error {
+	_, err := q.db.ExecContext(ctx, recordSearch, arg.Query, arg.Interface, arg.ResultCount)
+	return err
+}
+
+const recountCounters = `-- name: RecountCounters :exec
+INSERT OR REPLACE INTO counters (name, value) VALUES
+  ('notes', (SELECT COUNT(*) FROM notes WHERE deleted_at IS NULL)),
+  ('tags', (SELECT COUNT(*) FROM tags))
+`
+
+func (q *Queries) RecountCounters(ctx context.Context) error {
+	_, err := q.db.ExecContext(ctx, recountCounters)
+	return err
+}
+
+const recountTagNotes = `-- name: RecountTagNotes :exec
+UPDATE tags SET note_count = (
+  SELECT COUNT(*) FROM note_tags nt JOIN notes n ON n.id = nt.note_id
+  WHERE nt.tag_id = tags.id AND n.deleted_at IS NULL
+)
+`
+
+func (q *Queries) RecountTagNotes(ctx context.Context) error {
+	_, err := q.db.ExecContext(ctx, recountTagNotes)
+	return err
+}
+
+const removeAllNoteAliases = `-- name: RemoveAllNoteAliases :exec
+DELETE FROM note_aliases WHERE note_id = ?
+`
+
+func (q *Queries) RemoveAllNoteAliases(ctx context.Context, noteID int64) error {
+	_, err := q.db.ExecContext(ctx, removeAllNoteAliases, noteID)
+	return err
+}
+
+const removeAllNoteMentions = `-- name: RemoveAllNoteMentions :exec
+DELETE FROM note_mentions WHERE note_id = ?
+`
+
+func (q *Queries) RemoveAllNoteMentions(ctx context.Context, noteID int64) error {
+	_, err := q.db.ExecContext(ctx, removeAllNoteMentions, noteID)
+	return err
+}
+
+const removeAllPendingLinks = `-- name: RemoveAllPendingLinks :exec
+DELETE FROM pending_links WHERE note_id = ?
+`
+
+func (q *Queries) RemoveAllPendingLinks(ctx context.Context, noteID int64) error {
+	_, err := q.db.ExecContext(ctx, removeAllPendingLinks, noteID)
+	return err
+}
+
 const removeAllTagsFromNote = `-- name: RemoveAllTagsFromNote :exec
 DELETE FROM note_tags WHERE note_id = ?
 `
@@ -1357,6 +2802,20 @@ func (q *Queries) RemoveAllTagsFromNote(ctx context.Context, noteID int64) error
 	return err
 }
 
+const removeNoteAlias = `-- name: RemoveNoteAlias :exec
+DELETE FROM note_aliases WHERE note_id = ? AND alias = ?
+`
+
+type RemoveNoteAliasParams struct {
+	NoteID int64  `json:"note_id"`
+	Alias  string `json:"alias"`
+}
+
+func (q *Queries) RemoveNoteAlias(ctx context.Context, arg RemoveNoteAliasParams) error {
+	_, err := q.db.ExecContext(ctx, removeNoteAlias, arg.NoteID, arg.Alias)
+	return err
+}
+
 const removeTagFromNote = `-- name: RemoveTagFromNote :exec
 DELETE FROM note_tags
 WHERE note_id = ? AND tag_id = ?
@@ -1372,9 +2831,154 @@ func (q *Queries) RemoveTagFromNote(ctx context.Context, arg RemoveTagFromNotePa
 	return err
 }
 
+const renameTag = `-- name: RenameTag :exec
+UPDATE tags SET name = ? WHERE id = ?
+`
+
+type RenameTagParams struct {
+	Name string `json:"name"`
+	ID   int64  `json:"id"`
+}
+
+func (q *Queries) RenameTag(ctx context.Context, arg RenameTagParams) error {
+	_, err := q.db.ExecContext(ctx, renameTag, arg.Name, arg.ID)
+	return err
+}
+
+const resetEmbeddingSynced = `-- name: ResetEmbeddingSynced :exec
+UPDATE notes
+SET embedding_synced = FALSE
+`
+
+func (q *Queries) ResetEmbeddingSynced(ctx context.Context) error {
+	_, err := q.db.ExecContext(ctx, resetEmbeddingSynced)
+	return err
+}
+
+const resolveNoteTitle = `-- name: ResolveNoteTitle :one
+
+SELECT notes.id, notes.title, notes.content, notes.created_at, notes.updated_at, notes.embedding_synced, notes.expires_at, notes.source, notes.source_ref, notes.folder_id, notes.pinned, notes.pinned_at, notes.created_by, notes.deleted_at, notes.archived_at, notes.lang FROM notes
+LEFT JOIN note_aliases na ON na.note_id = notes.id AND na.alias = ?1
+WHERE (notes.title = ?1 OR na.alias IS NOT NULL) AND notes.deleted_at IS NULL
+ORDER BY na.alias IS NULL DESC, notes.id
+LIMIT 1
+`
+
+// Note aliases
+// A wikilink target: the note with this exact title, else the note with this alias.
+func (q *Queries) ResolveNoteTitle(ctx context.Context, name string) (Note, error) {
+	row := q.db.QueryRowContext(ctx, resolveNoteTitle, name)
+	var i Note
+	err := row.Scan(
+		&i.ID,
+		&i.Title,
+		&i.Content,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.EmbeddingSynced,
+		&i.ExpiresAt,
+		&i.Source,
+		&i.SourceRef,
+		&i.FolderID,
+		&i.Pinned,
+		&i.PinnedAt,
+		&i.CreatedBy,
+		&i.DeletedAt,
+		&i.ArchivedAt,
+		&i.Lang,
+	)
+	return i, err
+}
+
+const restoreNote = `-- name: RestoreNote :one
+UPDATE notes SET deleted_at = NULL, embedding_synced = FALSE
+WHERE id = ? AND deleted_at IS NOT NULL
+RETURNING id, title, content, created_at, updated_at, embedding_synced, expires_at, source, source_ref, folder_id, pinned, pinned_at, created_by, deleted_at, archived_at, lang
+`
+
+func (q *Queries) RestoreNote(ctx context.Context, id int64) (Note, error) {
+	row := q.db.QueryRowContext(ctx, restoreNote, id)
+	var i Note
+	err := row.Scan(
+		&i.ID,
+		&i.Title,
+		&i.Content,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.EmbeddingSynced,
+		&i.ExpiresAt,
+		&i.Source,
+		&i.SourceRef,
+		&i.FolderID,
+		&i.Pinned,
+		&i.PinnedAt,
+		&i.CreatedBy,
+		&i.DeletedAt,
+		&i.ArchivedAt,
+		&i.Lang,
+	)
+	return i, err
+}
+
+const searchNotesByTagName = `-- name: SearchNotesByTagName :many
+SELECT DISTINCT n.id, n.title, n.content, n.created_at, n.updated_at, n.embedding_synced, n.expires_at, n.source, n.source_ref, n.folder_id, n.pinned, n.pinned_at, n.created_by, n.deleted_at, n.archived_at, n.lang FROM notes n
+INNER JOIN note_tags nt ON n.id = nt.note_id
+INNER JOIN tags t ON nt.tag_id = t.id
+WHERE t.name LIKE ?1 AND n.deleted_at IS NULL
+  AND (CAST(?2 AS BOOLEAN) OR n.archived_at IS NULL)
+ORDER BY n.updated_at DESC
+LIMIT ?3
+`
+
+type SearchNotesByTagNameParams struct {
+	Name            string `json:"name"`
+	IncludeArchived bool   `json:"include_archived"`
+	Limit           int64  `json:"limit"`
+}
+
+func (q *Queries) SearchNotesByTagName(ctx context.Context, arg SearchNotesByTagNameParams) ([]Note, error) {
+	rows, err := q.db.QueryContext(ctx, searchNotesByTagName, arg.Name, arg.IncludeArchived, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Note{}
+	for rows.Next() {
+		var i Note
+		if err := rows.Scan(
+			&i.ID,
+			&i.Title,
+			&i.Content,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.EmbeddingSynced,
+			&i.ExpiresAt,
+			&i.Source,
+			&i.SourceRef,
+			&i.FolderID,
+			&i.Pinned,
+			&i.PinnedAt,
+			&i.CreatedBy,
+			&i.DeletedAt,
+			&i.ArchivedAt,
+			&i.Lang,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const searchNotesByTitle = `-- name: SearchNotesByTitle :many
-SELECT id, title, content, created_at, updated_at, embedding_synced, expires_at, source, source_ref, folder_id, pinned, pinned_at FROM notes
-WHERE title LIKE ?
+SELECT id, title, content, created_at, updated_at, embedding_synced, expires_at, source, source_ref, folder_id, pinned, pinned_at, created_by, deleted_at, archived_at, lang FROM notes
+WHERE title LIKE ? AND deleted_at IS NULL
 ORDER BY created_at DESC
 `
 
@@ -1400,6 +3004,10 @@ func (q *Queries) SearchNotesByTitle(ctx context.Context, title string) ([]Note,
 			&i.FolderID,
 			&i.Pinned,
 			&i.PinnedAt,
+			&i.CreatedBy,
+			&i.DeletedAt,
+			&i.ArchivedAt,
+			&i.Lang,
 		); err != nil {
 			return nil, err
 		}
@@ -1415,20 +3023,27 @@ func (q *Queries) SearchNotesByTitle(ctx context.Context, title string) ([]Note,
 }
 
 const searchNotesContent = `-- name: SearchNotesContent :many
-SELECT id, title, content, created_at, updated_at, embedding_synced, expires_at, source, source_ref, folder_id, pinned, pinned_at FROM notes
-WHERE content LIKE ? OR title LIKE ?
+SELECT id, title, content, created_at, updated_at, embedding_synced, expires_at, source, source_ref, folder_id, pinned, pinned_at, created_by, deleted_at, archived_at, lang FROM notes
+WHERE (content LIKE ?1 OR title LIKE ?2) AND deleted_at IS NULL
+  AND (CAST(?3 AS BOOLEAN) OR archived_at IS NULL)
 ORDER BY updated_at DESC
-LIMIT ?
+LIMIT ?4
 `
 
 type SearchNotesContentParams struct {
-	Content string `json:"content"`
-	Title   string `json:"title"`
-	Limit   int64  `json:"limit"`
+	Content         string `json:"content"`
+	Title           string `json:"title"`
+	IncludeArchived bool   `json:"include_archived"`
+	Limit           int64  `json:"limit"`
 }
 
 func (q *Queries) SearchNotesContent(ctx context.Context, arg SearchNotesContentParams) ([]Note, error) {
-	rows, err := q.db.QueryContext(ctx, searchNotesContent, arg.Content, arg.Title, arg.Limit)
+	rows, err := q.db.QueryContext(ctx, searchNotesContent,
+		arg.Content,
+		arg.Title,
+		arg.IncludeArchived,
+		arg.Limit,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -1449,6 +3064,10 @@ func (q *Queries) SearchNotesContent(ctx context.Context, arg SearchNotesContent
 			&i.FolderID,
 			&i.Pinned,
 			&i.PinnedAt,
+			&i.CreatedBy,
+			&i.DeletedAt,
+			&i.ArchivedAt,
+			&i.Lang,
 		); err != nil {
 			return nil, err
 		}
@@ -1463,6 +3082,99 @@ func (q *Queries) SearchNotesContent(ctx context.Context, arg SearchNotesContent
 	return items, nil
 }
 
+const setExportFile = `-- name: SetExportFile :exec
+INSERT INTO export_files (destination, note_id, path, hash) VALUES (?, ?, ?, ?)
+ON CONFLICT(destination, note_id) DO UPDATE SET path = excluded.path, hash = excluded.hash
+`
+
+type SetExportFileParams struct {
+	Destination string `json:"destination"`
+	NoteID      int64  `json:"note_id"`
+	Path        string `json:"path"`
+	Hash        string `json:"hash"`
+}
+
+func (q *Queries) SetExportFile(ctx context.Context, arg SetExportFileParams) error {
+	_, err := q.db.ExecContext(ctx, setExportFile,
+		arg.Destination,
+		arg.NoteID,
+		arg.Path,
+		arg.Hash,
+	)
+	return err
+}
+
+const setNoteCreatedBy = `-- name: SetNoteCreatedBy :exec
+UPDATE notes SET created_by = ? WHERE id = ?
+`
+
+type SetNoteCreatedByParams struct {
+	CreatedBy sql.NullString `json:"created_by"`
+	ID        int64          `json:"id"`
+}
+
+func (q *Queries) SetNoteCreatedBy(ctx context.Context, arg SetNoteCreatedByParams) error {
+	_, err := q.db.ExecContext(ctx, setNoteCreatedBy, arg.CreatedBy, arg.ID)
+	return err
+}
+
+const setNoteExpiry = `-- name: SetNoteExpiry :exec
+UPDATE notes SET expires_at = ? WHERE id = ?
+`
+
+type SetNoteExpiryParams struct {
+	ExpiresAt sql.NullTime `json:"expires_at"`
+	ID        int64        `json:"id"`
+}
+
+func (q *Queries) SetNoteExpiry(ctx context.Context, arg SetNoteExpiryParams) error {
+	_, err := q.db.ExecContext(ctx, setNoteExpiry, arg.ExpiresAt, arg.ID)
+	return err
+}
+
+const setNoteLang = `-- name: SetNoteLang :exec
+UPDATE notes SET lang = ? WHERE id = ?
+`
+
+type SetNoteLangParams struct {
+	Lang sql.NullString `json:"lang"`
+	ID   int64          `json:"id"`
+}
+
+// Leaves updated_at alone: the language is derived from the content, not an edit.
+func (q *Queries) SetNoteLang(ctx context.Context, arg SetNoteLangParams) error {
+	_, err := q.db.ExecContext(ctx, setNoteLang, arg.Lang, arg.ID)
+	return err
+}
+
+const trashNote = `-- name: TrashNote :execrows
+
+UPDATE notes SET deleted_at = CURRENT_TIMESTAMP
+WHERE id = ? AND deleted_at IS NULL
+`
+
+// Trash: a deleted note keeps its row with deleted_at set until it is restored or purged.
+func (q *Queries) TrashNote(ctx context.Context, id int64) (int64, error) {
+	result, err := q.db.ExecContext(ctx, trashNote, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const unarchiveNote = `-- name: UnarchiveNote :execrows
+UPDATE notes SET archived_at = NULL
+WHERE id = ? AND deleted_at IS NULL AND archived_at IS NOT NULL
+`
+
+func (q *Queries) UnarchiveNote(ctx context.Context, id int64) (int64, error) {
+	result, err := q.db.ExecContext(ctx, unarchiveNote, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
 const unpinNote = `-- name: UnpinNote :exec
 UPDATE notes SET pinned = FALSE, pinned_at = NULL WHERE id = ?
 `
@@ -1502,7 +3214,7 @@ const updateNote = `-- name: UpdateNote :one
 UPDATE notes
 SET title = ?, content = ?, updated_at = CURRENT_TIMESTAMP
 WHERE id = ?
-RETURNING id, title, content, created_at, updated_at, embedding_synced, expires_at, source, source_ref, folder_id, pinned, pinned_at
+RETURNING id, title, content, created_at, updated_at, embedding_synced, expires_at, source, source_ref, folder_id, pinned, pinned_at, created_by, deleted_at, archived_at, lang
 `
 
 type UpdateNoteParams struct {
@@ -1527,6 +3239,10 @@ func (q *Queries) UpdateNote(ctx context.Context, arg UpdateNoteParams) (Note, e
 		&i.FolderID,
 		&i.Pinned,
 		&i.PinnedAt,
+		&i.CreatedBy,
+		&i.DeletedAt,
+		&i.ArchivedAt,
+		&i.Lang,
 	)
 	return i, err
 }
@@ -1546,6 +3262,54 @@ func (q *Queries) UpdateNoteSource(ctx context.Context, arg UpdateNoteSourcePara
 	return err
 }
 
+const updateScheduleRun = `-- name: UpdateScheduleRun :exec
+UPDATE schedules
+SET last_run_at = ?, next_run_at = ?, last_note_id = ?
+WHERE id = ?
+`
+
+type UpdateScheduleRunParams struct {
+	LastRunAt  sql.NullTime  `json:"last_run_at"`
+	NextRunAt  time.Time     `json:"next_run_at"`
+	LastNoteID sql.NullInt64 `json:"last_note_id"`
+	ID         int64         `json:"id"`
+}
+
+func (q *Queries) UpdateScheduleRun(ctx context.Context, arg UpdateScheduleRunParams) error {
+	_, err := q.db.ExecContext(ctx, updateScheduleRun,
+		arg.LastRunAt,
+		arg.NextRunAt,
+		arg.LastNoteID,
+		arg.ID,
+	)
+	return err
+}
+
+const updateTagMetadata = `-- name: UpdateTagMetadata :one
+UPDATE tags SET color = ?, description = ?
+WHERE id = ?
+RETURNING id, name, color, description, note_count
+`
+
+type UpdateTagMetadataParams struct {
+	Color       string `json:"color"`
+	Description string `json:"description"`
+	ID          int64  `json:"id"`
+}
+
+func (q *Queries) UpdateTagMetadata(ctx context.Context, arg UpdateTagMetadataParams) (Tag, error) {
+	row := q.db.QueryRowContext(ctx, updateTagMetadata, arg.Color, arg.Description, arg.ID)
+	var i Tag
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Color,
+		&i.Description,
+		&i.NoteCount,
+	)
+	return i, err
+}
+
 const updateTemplate = `-- name: UpdateTemplate :one
 UPDATE templates SET content = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ? RETURNING id, name, content, created_at, updated_at
 `
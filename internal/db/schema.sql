@@ -22,13 +22,20 @@ CREATE TABLE IF NOT EXISTS notes (
   source_ref TEXT, -- reference to source location (e.g., "main.go:50")
   folder_id INTEGER REFERENCES folders(id) ON DELETE SET NULL,
   pinned BOOLEAN DEFAULT FALSE,
-  pinned_at DATETIME
+  pinned_at DATETIME,
+  created_by TEXT, -- MCP client ("name/version") that created the note; NULL for CLI/TUI notes
+  deleted_at DATETIME, -- set while the note is in the trash
+  archived_at DATETIME, -- set while the note is archived (hidden from default lists and search)
+  lang TEXT -- detected language (ISO 639-1, e.g. "es"); NULL when undetermined
 );
 
 -- Tags table (normalized)
 CREATE TABLE IF NOT EXISTS tags (
   id INTEGER PRIMARY KEY AUTOINCREMENT,
-  name TEXT NOT NULL UNIQUE
+  name TEXT NOT NULL UNIQUE,
+  color TEXT NOT NULL DEFAULT '', -- hex color (e.g. "#ff8800") for UI/graph color-coding
+  description TEXT NOT NULL DEFAULT '',
+  note_count INTEGER NOT NULL DEFAULT 0 -- live notes with the tag, kept by triggers (migration 018)
 );
 
 -- Join table for many-to-many
@@ -56,6 +63,7 @@ CREATE TABLE IF NOT EXISTS note_links (
   target_note_id INTEGER NOT NULL REFERENCES notes(id) ON DELETE CASCADE,
   link_text TEXT NOT NULL,
   created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+  embed BOOLEAN DEFAULT FALSE,
   UNIQUE(source_note_id, target_note_id, link_text)
 );
 
@@ -93,3 +101,82 @@ CREATE VIRTUAL TABLE IF NOT EXISTS notes_fts USING fts5(
   content='notes',
   content_rowid='id'
 );
+
+-- Trigram FTS5 index for CJK text, which has no spaces between words
+CREATE VIRTUAL TABLE IF NOT EXISTS notes_fts_trigram USING fts5(
+  title,
+  content,
+  content='notes',
+  content_rowid='id',
+  tokenize='trigram'
+);
+
+-- Schedules for recurring notes (materialized by `noted schedule run`)
+CREATE TABLE IF NOT EXISTS schedules (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  name TEXT NOT NULL UNIQUE,
+  rule TEXT NOT NULL, -- recurrence rule (e.g., "weekly:mon@09:00")
+  title TEXT NOT NULL, -- title pattern for created notes (supports {{date}} etc.)
+  template_name TEXT, -- template applied to the note body
+  tags TEXT NOT NULL DEFAULT '', -- comma-separated tags
+  folder_id INTEGER REFERENCES folders(id) ON DELETE SET NULL,
+  next_run_at DATETIME NOT NULL,
+  last_run_at DATETIME,
+  last_note_id INTEGER REFERENCES notes(id) ON DELETE SET NULL,
+  created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_schedules_next_run_at ON schedules(next_run_at);
+
+-- Recent searches per interface (opt out with NOTED_SEARCH_HISTORY=off)
+CREATE TABLE IF NOT EXISTS search_history (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  query TEXT NOT NULL,
+  interface TEXT NOT NULL, -- where the search ran: "cli", "mcp", "tui"
+  result_count INTEGER NOT NULL DEFAULT 0,
+  created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_search_history_interface ON search_history(interface, created_at);
+
+-- Alternative names a note answers to in [[wikilinks]] (frontmatter "aliases")
+CREATE TABLE IF NOT EXISTS note_aliases (
+  note_id INTEGER NOT NULL REFERENCES notes(id) ON DELETE CASCADE,
+  alias TEXT NOT NULL,
+  PRIMARY KEY (note_id, alias)
+);
+
+CREATE INDEX IF NOT EXISTS idx_note_aliases_alias ON note_aliases(alias);
+
+-- Cached counts ("notes": live notes, "tags": all tags), kept by triggers (migration 018)
+CREATE TABLE IF NOT EXISTS counters (
+  name TEXT PRIMARY KEY,
+  value INTEGER NOT NULL DEFAULT 0
+);
+
+-- @person mentions in note content (migration 019)
+CREATE TABLE IF NOT EXISTS note_mentions (
+  note_id INTEGER NOT NULL REFERENCES notes(id) ON DELETE CASCADE,
+  name TEXT NOT NULL, -- lowercased, without the @
+  PRIMARY KEY (note_id, name)
+);
+
+CREATE INDEX IF NOT EXISTS idx_note_mentions_name ON note_mentions(name);
+
+-- The file each note was last exported to in each export directory (migrations 022, 024)
+CREATE TABLE IF NOT EXISTS export_files (
+  destination TEXT NOT NULL, -- absolute path of the export directory
+  note_id INTEGER NOT NULL,
+  path TEXT NOT NULL, -- slash-separated, relative to the destination
+  hash TEXT NOT NULL DEFAULT '', -- sha256 of the file, hex
+  PRIMARY KEY (destination, note_id)
+);
+
+-- Every note's [[wikilink]] targets; ListUnresolvedLinks reports those no live note matches (migration 023)
+CREATE TABLE IF NOT EXISTS pending_links (
+  note_id INTEGER NOT NULL REFERENCES notes(id) ON DELETE CASCADE,
+  target TEXT NOT NULL, -- the link's title, without #section or |display
+  PRIMARY KEY (note_id, target)
+);
+
+CREATE INDEX IF NOT EXISTS idx_pending_links_target ON pending_links(target);
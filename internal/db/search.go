@@ -0,0 +1,208 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// SearchWeights sets how much a query term matching each field adds to a note's score.
+type SearchWeights struct {
+	Title   float64
+	Tag     float64
+	Content float64
+}
+
+// DefaultSearchWeights ranks title hits above tag hits above body hits.
+var DefaultSearchWeights = SearchWeights{Title: 3, Tag: 2, Content: 1}
+
+// SearchResult is a note matched by SearchNotes, with its score, the tags that matched, and
+// excerpts of its content around the matches.
+type SearchResult struct {
+	Note        Note
+	Score       float64
+	Tags        []string
+	MatchedTags []string
+	Snippets    []Snippet
+}
+
+// SearchNotes searches titles, content, and tag names, so a query for "golang" also finds notes
+// that are only tagged golang. Text matches come from FTS5 (falling back to LIKE when FTS is
+// unavailable, errors on the query syntax, or finds nothing); tag matches come from the tag names.
+// Every candidate is then scored per query term with DefaultSearchWeights and returned best first.
+// A "lang:es" term keeps only notes detected as that language; given alone it lists them.
+// A negative limit returns every match. Archived notes are left out; SearchNotesArchived includes
+// them.
+func SearchNotes(ctx context.Context, db *sql.DB, query string, limit int64) ([]SearchResult, error) {
+	return searchNotes(ctx, db, query, limit, false)
+}
+
+// SearchNotesArchived is SearchNotes including archived notes, for "noted grep --archived".
+func SearchNotesArchived(ctx context.Context, db *sql.DB, query string, limit int64) ([]SearchResult, error) {
+	return searchNotes(ctx, db, query, limit, true)
+}
+
+func searchNotes(ctx context.Context, db *sql.DB, query string, limit int64, archived bool) ([]SearchResult, error) {
+	q := New(db)
+
+	// With a language filter every match is a candidate, so the limit counts only notes in it
+	query, lang := splitLangFilter(query)
+	fetch := limit
+	if lang != "" {
+		fetch = -1
+	}
+
+	var notes []Note
+	var err error
+	if FTSAvailable(ctx, db) {
+		notes, err = searchNotesFTS(ctx, db, query, fetch, archived)
+	}
+	if notes == nil || err != nil {
+		pattern := "%" + query + "%"
+		notes, err = q.SearchNotesContent(ctx, SearchNotesContentParams{
+			Content:         pattern,
+			Title:           pattern,
+			IncludeArchived: archived,
+			Limit:           fetch,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	terms := searchTerms(query)
+	seen := make(map[int64]bool, len(notes))
+	for _, n := range notes {
+		seen[n.ID] = true
+	}
+	for _, term := range terms {
+		tagged, err := q.SearchNotesByTagName(ctx, SearchNotesByTagNameParams{
+			Name:            "%" + term + "%",
+			IncludeArchived: archived,
+			Limit:           fetch,
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, n := range tagged {
+			if !seen[n.ID] {
+				seen[n.ID] = true
+				notes = append(notes, n)
+			}
+		}
+	}
+
+	if lang != "" {
+		notes = slices.DeleteFunc(notes, func(n Note) bool { return n.Lang.String != lang })
+	}
+
+	results := make([]SearchResult, 0, len(notes))
+	for _, n := range notes {
+		tags, err := q.GetTagsForNote(ctx, n.ID)
+		if err != nil {
+			return nil, err
+		}
+		r := SearchResult{Note: n, Tags: make([]string, len(tags))}
+		for j, t := range tags {
+			r.Tags[j] = t.Name
+		}
+		r.Score, r.MatchedTags = scoreSearchResult(n, r.Tags, terms, DefaultSearchWeights)
+		if r.Score == 0 {
+			// A text hit none of the plain terms explain (stemming, prefix or phrase syntax):
+			// keep it, ranked as a body match.
+			r.Score = DefaultSearchWeights.Content
+		}
+		results = append(results, r)
+	}
+
+	// A stable sort breaks ties by the order the text search returned (its own relevance ranking).
+	sort.SliceStable(results, func(i, j int) bool { return results[i].Score > results[j].Score })
+	if limit >= 0 && int64(len(results)) > limit {
+		results = results[:limit]
+	}
+	for i := range results {
+		results[i].Snippets = Snippets(results[i].Note.Content, terms, maxSnippets)
+	}
+	return results, nil
+}
+
+// splitLangFilter takes a "lang:xx" term out of a search query, returning the rest of the query and
+// the lowercased language code, or "" when there is none.
+func splitLangFilter(query string) (string, string) {
+	var rest []string
+	var lang string
+	for _, f := range strings.Fields(query) {
+		if code, ok := strings.CutPrefix(strings.ToLower(f), "lang:"); ok && code != "" {
+			lang = code
+			continue
+		}
+		rest = append(rest, f)
+	}
+	return strings.Join(rest, " "), lang
+}
+
+// searchTerms splits a query into lowercase terms, dropping FTS5 operators and syntax characters.
+func searchTerms(query string) []string {
+	var terms []string
+	for _, f := range strings.Fields(strings.ToLower(query)) {
+		f = strings.Trim(f, `"*()^:`)
+		switch f {
+		case "", "and", "or", "not", "near":
+			continue
+		}
+		terms = append(terms, f)
+	}
+	return terms
+}
+
+func scoreSearchResult(n Note, tags, terms []string, w SearchWeights) (float64, []string) {
+	title := strings.ToLower(n.Title)
+	content := strings.ToLower(n.Content)
+	var score float64
+	var matched []string
+	for _, term := range terms {
+		if strings.Contains(title, term) {
+			score += w.Title
+		}
+		if strings.Contains(content, term) {
+			score += w.Content
+		}
+		for _, tag := range tags {
+			lt := strings.ToLower(tag)
+			switch {
+			case lt == term:
+				score += w.Tag
+			case strings.Contains(lt, term):
+				score += w.Tag / 2
+			default:
+				continue
+			}
+			if !slices.Contains(matched, tag) {
+				matched = append(matched, tag)
+			}
+		}
+	}
+	return score, matched
+}
+
+// searchHistoryLimit bounds the search_history table; older entries are pruned as new ones land.
+const searchHistoryLimit = 1000
+
+// LogSearch records a search in the history for the given interface ("cli", "mcp", ...) and prunes
+// the history to the most recent searchHistoryLimit entries.
+func LogSearch(ctx context.Context, q *Queries, query, iface string, resultCount int) error {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil
+	}
+	if err := q.RecordSearch(ctx, RecordSearchParams{
+		Query:       query,
+		Interface:   iface,
+		ResultCount: int64(resultCount),
+	}); err != nil {
+		return err
+	}
+	return q.PruneSearchHistory(ctx, searchHistoryLimit)
+}
@@ -0,0 +1,140 @@
+package db
+
+import (
+	"html"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// snippetContext is roughly how many bytes of text a snippet keeps on each side of a match.
+const snippetContext = 60
+
+// maxSnippets is how many snippets a search result carries at most.
+const maxSnippets = 3
+
+// Snippet is a one-line excerpt of a note around search matches.
+type Snippet struct {
+	Text    string  `json:"text"`
+	Matches []Match `json:"matches"`
+}
+
+// Match is where a search term matched in a snippet's Text, as byte offsets.
+type Match struct {
+	Start int `json:"start"`
+	End   int `json:"end"`
+}
+
+// HTML returns the snippet as escaped HTML with each match wrapped in <mark>.
+func (s Snippet) HTML() string {
+	var b strings.Builder
+	at := 0
+	for _, m := range s.Matches {
+		b.WriteString(html.EscapeString(s.Text[at:m.Start]))
+		b.WriteString("<mark>" + html.EscapeString(s.Text[m.Start:m.End]) + "</mark>")
+		at = m.End
+	}
+	b.WriteString(html.EscapeString(s.Text[at:]))
+	return b.String()
+}
+
+// Highlight returns the snippet with each match wrapped in before and after, such as terminal
+// escape codes.
+func (s Snippet) Highlight(before, after string) string {
+	var b strings.Builder
+	at := 0
+	for _, m := range s.Matches {
+		b.WriteString(s.Text[at:m.Start] + before + s.Text[m.Start:m.End] + after)
+		at = m.End
+	}
+	b.WriteString(s.Text[at:])
+	return b.String()
+}
+
+// Snippets returns up to limit excerpts of content around case-insensitive matches of terms, in
+// order, each cut at word boundaries about snippetContext bytes either side of its matches and
+// marked with "…" where text was left out. Line breaks become spaces.
+func Snippets(content string, terms []string, limit int) []Snippet {
+	re := termsRegexp(terms)
+	if re == nil {
+		return nil
+	}
+	locs := re.FindAllStringIndex(content, -1)
+	var out []Snippet
+	prevEnd := 0
+	for i := 0; i < len(locs) && len(out) < limit; {
+		start := max(wordStart(content, locs[i][0]-snippetContext), prevEnd)
+		end := wordEnd(content, locs[i][1]+snippetContext)
+		// Take in the matches that fall inside this window, growing it to fit a partial one
+		j := i
+		for j < len(locs) && locs[j][0] < end {
+			end = max(end, locs[j][1])
+			j++
+		}
+
+		text := strings.Map(func(r rune) rune {
+			if r == '\n' || r == '\r' || r == '\t' {
+				return ' '
+			}
+			return r
+		}, content[start:end])
+		trimmed := strings.TrimLeft(text, " ")
+		var s Snippet
+		if start > 0 {
+			s.Text = "…"
+		}
+		// Where content's offsets land in s.Text
+		offset := len(s.Text) - start - (len(text) - len(trimmed))
+		s.Text += strings.TrimRight(trimmed, " ")
+		for _, loc := range locs[i:j] {
+			s.Matches = append(s.Matches, Match{Start: loc[0] + offset, End: loc[1] + offset})
+		}
+		if end < len(content) {
+			s.Text += "…"
+		}
+		out = append(out, s)
+		i, prevEnd = j, end
+	}
+	return out
+}
+
+// termsRegexp matches any of terms, case-insensitively and longest first, or is nil for none.
+func termsRegexp(terms []string) *regexp.Regexp {
+	var quoted []string
+	for _, t := range terms {
+		if t != "" {
+			quoted = append(quoted, regexp.QuoteMeta(t))
+		}
+	}
+	if len(quoted) == 0 {
+		return nil
+	}
+	sort.SliceStable(quoted, func(i, j int) bool { return len(quoted[i]) > len(quoted[j]) })
+	return regexp.MustCompile("(?i)" + strings.Join(quoted, "|"))
+}
+
+// wordStart moves i back to the start of the word it falls in, or to 0.
+func wordStart(s string, i int) int {
+	if i <= 0 {
+		return 0
+	}
+	for i > 0 && !utf8.RuneStart(s[i]) {
+		i--
+	}
+	if j := strings.LastIndexAny(s[:i], " \n\t"); j >= 0 {
+		return j + 1
+	}
+	return 0
+}
+
+// wordEnd moves i forward to the end of the word it falls in, or to len(s).
+func wordEnd(s string, i int) int {
+	if i >= len(s) {
+		return len(s)
+	}
+	if j := strings.IndexAny(s[i:], " \n\t"); j >= 0 {
+		return i + j
+	}
+	return len(s)
+}
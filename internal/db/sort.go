@@ -0,0 +1,66 @@
+package db
+
+import (
+	"cmp"
+	"database/sql"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// NoteSorts are the orders ListNotes and SortNotes can list notes in. "pinned" puts pinned notes
+// first, then orders by last update.
+var NoteSorts = []string{"created", "updated", "title", "pinned"}
+
+// ParseNoteSort checks a sort and its order ("asc", "desc", or "" for the sort's natural order:
+// A to Z for titles, newest first otherwise) and reports whether the listing is ascending.
+func ParseNoteSort(sort, order string) (ascending bool, err error) {
+	if sort != "" && !slices.Contains(NoteSorts, sort) {
+		return false, fmt.Errorf("unknown sort %q (use %s)", sort, strings.Join(NoteSorts, ", "))
+	}
+	switch order {
+	case "":
+		return sort == "title", nil
+	case "asc":
+		return true, nil
+	case "desc":
+		return false, nil
+	}
+	return false, fmt.Errorf("unknown order %q (use asc or desc)", order)
+}
+
+// SortNotes orders notes already loaded the way ListNotes orders them with the same sort.
+func SortNotes(notes []Note, sort string, ascending bool) {
+	slices.SortStableFunc(notes, func(a, b Note) int {
+		if sort == "pinned" && a.Pinned.Bool != b.Pinned.Bool {
+			if a.Pinned.Bool {
+				return -1
+			}
+			return 1
+		}
+		var c int
+		switch sort {
+		case "title":
+			c = cmp.Compare(strings.ToLower(a.Title), strings.ToLower(b.Title))
+		case "updated", "pinned":
+			c = a.UpdatedAt.Time.Compare(b.UpdatedAt.Time)
+		}
+		if c == 0 {
+			c = a.CreatedAt.Time.Compare(b.CreatedAt.Time)
+		}
+		if c == 0 {
+			c = cmp.Compare(a.ID, b.ID)
+		}
+		if !ascending {
+			c = -c
+		}
+		return c
+	})
+}
+
+// UpdatedBetween reports whether a note was last updated at or after since and before before, the
+// window ListNotes' UpdatedSince and UpdatedBefore select. An unset bound is open.
+func UpdatedBetween(n Note, since, before sql.NullTime) bool {
+	t := n.UpdatedAt.Time
+	return (!since.Valid || !t.Before(since.Time)) && (!before.Valid || t.Before(before.Time))
+}
@@ -0,0 +1,106 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrTagNotFound is returned by RenameTagByName and DeleteTagByName for an unknown tag.
+var ErrTagNotFound = errors.New("tag not found")
+
+// RenameTagResult describes a tag rename.
+type RenameTagResult struct {
+	Merged  bool    // the new name already existed and the two tags were merged
+	NoteIDs []int64 // notes that carried the old tag (mirror them to the vault)
+}
+
+// RenameTagByName renames a tag in one transaction. If a tag called newName already exists the two
+// are merged: every note tagged oldName gets newName and oldName is deleted. Both names are trimmed.
+func RenameTagByName(ctx context.Context, conn *sql.DB, oldName, newName string) (RenameTagResult, error) {
+	oldName, newName = strings.TrimSpace(oldName), strings.TrimSpace(newName)
+	if newName == "" {
+		return RenameTagResult{}, fmt.Errorf("new tag name is required")
+	}
+	if newName == oldName {
+		return RenameTagResult{}, fmt.Errorf("tag is already named %q", newName)
+	}
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return RenameTagResult{}, err
+	}
+	defer func() { _ = tx.Rollback() }()
+	q := New(tx)
+
+	src, err := q.GetTagByName(ctx, oldName)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return RenameTagResult{}, fmt.Errorf("%w: %q", ErrTagNotFound, oldName)
+		}
+		return RenameTagResult{}, err
+	}
+	noteIDs, err := q.GetNoteIDsForTag(ctx, src.ID)
+	if err != nil {
+		return RenameTagResult{}, err
+	}
+
+	var result RenameTagResult
+	result.NoteIDs = noteIDs
+	target, err := q.GetTagByName(ctx, newName)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		if err := q.RenameTag(ctx, RenameTagParams{Name: newName, ID: src.ID}); err != nil {
+			return RenameTagResult{}, fmt.Errorf("failed to rename tag: %w", err)
+		}
+	case err != nil:
+		return RenameTagResult{}, err
+	default:
+		result.Merged = true
+		if err := q.MergeTagInto(ctx, MergeTagIntoParams{TargetID: target.ID, SourceID: src.ID}); err != nil {
+			return RenameTagResult{}, fmt.Errorf("failed to merge tags: %w", err)
+		}
+		if err := q.DeleteTag(ctx, src.ID); err != nil {
+			return RenameTagResult{}, fmt.Errorf("failed to delete merged tag: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return RenameTagResult{}, err
+	}
+	return result, nil
+}
+
+// DeleteTagByName removes a tag from every note and deletes it. Returns the ids of the notes that
+// carried it.
+func DeleteTagByName(ctx context.Context, conn *sql.DB, name string) ([]int64, error) {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback() }()
+	q := New(tx)
+
+	tag, err := q.GetTagByName(ctx, name)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("%w: %q", ErrTagNotFound, name)
+		}
+		return nil, err
+	}
+	noteIDs, err := q.GetNoteIDsForTag(ctx, tag.ID)
+	if err != nil {
+		return nil, err
+	}
+	// note_tags rows go with it (ON DELETE CASCADE)
+	if err := q.DeleteTag(ctx, tag.ID); err != nil {
+		return nil, fmt.Errorf("failed to delete tag: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return noteIDs, nil
+}
@@ -0,0 +1,148 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// QueryTimer is a DBTX that records how long each query takes. Pass it to New in place of the
+// connection to time every generated query; queries are told apart by their sqlc name, or by their
+// first line for hand-written SQL. For QueryContext the time covers running the query up to the
+// first row, not reading the rows.
+type QueryTimer struct {
+	db DBTX
+
+	mu    sync.Mutex
+	stats map[string]*queryStat
+
+	slow    time.Duration
+	slowLog io.Writer
+}
+
+type queryStat struct {
+	count int64
+	total time.Duration
+	max   time.Duration
+}
+
+// QueryStat is the recorded latency of one query.
+type QueryStat struct {
+	Name    string  `json:"name"`
+	Count   int64   `json:"count"`
+	TotalMS float64 `json:"total_ms"`
+	AvgMS   float64 `json:"avg_ms"`
+	MaxMS   float64 `json:"max_ms"`
+}
+
+// NewQueryTimer wraps db.
+func NewQueryTimer(db DBTX) *QueryTimer {
+	return &QueryTimer{db: db, stats: map[string]*queryStat{}}
+}
+
+// LogSlow writes a line to w for every query that takes longer than threshold. A threshold of 0
+// turns the log off.
+func (t *QueryTimer) LogSlow(threshold time.Duration, w io.Writer) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.slow, t.slowLog = threshold, w
+}
+
+// Stats returns the recorded queries, the most total time first.
+func (t *QueryTimer) Stats() []QueryStat {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	out := make([]QueryStat, 0, len(t.stats))
+	for name, s := range t.stats {
+		out = append(out, QueryStat{
+			Name:    name,
+			Count:   s.count,
+			TotalMS: ms(s.total),
+			AvgMS:   ms(s.total / time.Duration(s.count)),
+			MaxMS:   ms(s.max),
+		})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].TotalMS != out[j].TotalMS {
+			return out[i].TotalMS > out[j].TotalMS
+		}
+		return out[i].Name < out[j].Name
+	})
+	return out
+}
+
+// Reset forgets the recorded stats.
+func (t *QueryTimer) Reset() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.stats = map[string]*queryStat{}
+}
+
+func (t *QueryTimer) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	defer t.record(query, time.Now())
+	return t.db.ExecContext(ctx, query, args...)
+}
+
+func (t *QueryTimer) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	defer t.record(query, time.Now())
+	return t.db.PrepareContext(ctx, query)
+}
+
+func (t *QueryTimer) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	defer t.record(query, time.Now())
+	return t.db.QueryContext(ctx, query, args...)
+}
+
+func (t *QueryTimer) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	defer t.record(query, time.Now())
+	return t.db.QueryRowContext(ctx, query, args...)
+}
+
+func (t *QueryTimer) record(query string, start time.Time) {
+	d := time.Since(start)
+	name := queryName(query)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	s := t.stats[name]
+	if s == nil {
+		s = &queryStat{}
+		t.stats[name] = s
+	}
+	s.count++
+	s.total += d
+	s.max = max(s.max, d)
+
+	if t.slow > 0 && d > t.slow && t.slowLog != nil {
+		_, _ = fmt.Fprintf(t.slowLog, "%s slow query %s took %s\n", time.Now().Format(time.RFC3339), name, d.Round(time.Millisecond))
+	}
+}
+
+// maxQueryNameLen bounds the name of a hand-written query, which is its first line.
+const maxQueryNameLen = 60
+
+// queryName returns the name sqlc gives query ("-- name: GetNote :one"), or for other SQL its first
+// line with whitespace collapsed.
+func queryName(query string) string {
+	query = strings.TrimSpace(query)
+	if rest, ok := strings.CutPrefix(query, "-- name: "); ok {
+		if name, _, ok := strings.Cut(rest, " "); ok {
+			return name
+		}
+	}
+	line, _, _ := strings.Cut(query, "\n")
+	line = strings.Join(strings.Fields(line), " ")
+	if len(line) > maxQueryNameLen {
+		line = line[:maxQueryNameLen] + "..."
+	}
+	return line
+}
+
+func ms(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
@@ -0,0 +1,69 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// ErrNotIncremental is returned by IncrementalVacuum for a database that is not in incremental
+// auto-vacuum mode. One Vacuum converts it.
+var ErrNotIncremental = errors.New("database is not in incremental auto-vacuum mode")
+
+// autoVacuumIncremental is the value PRAGMA auto_vacuum reports for incremental mode.
+const autoVacuumIncremental = 2
+
+// Vacuum rebuilds the database file to reclaim all free space, switching it to incremental
+// auto-vacuum on the way so later cleanups can use IncrementalVacuum. It rewrites the whole file
+// and blocks other writers while it runs.
+func Vacuum(ctx context.Context, conn *sql.DB) error {
+	// The new mode only takes effect through a VACUUM on the same connection
+	c, err := conn.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = c.Close() }()
+	if _, err := c.ExecContext(ctx, "PRAGMA auto_vacuum = INCREMENTAL"); err != nil {
+		return fmt.Errorf("failed to set auto_vacuum: %w", err)
+	}
+	if _, err := c.ExecContext(ctx, "VACUUM"); err != nil {
+		return fmt.Errorf("failed to vacuum: %w", err)
+	}
+	return nil
+}
+
+// IncrementalVacuum returns the database's free pages to the filesystem without rebuilding the
+// file, and reports how many it freed. Unlike Vacuum it does not defragment, but it is quick and
+// holds the write lock only briefly.
+func IncrementalVacuum(ctx context.Context, conn *sql.DB) (int64, error) {
+	var mode, before, after int64
+	if err := conn.QueryRowContext(ctx, "PRAGMA auto_vacuum").Scan(&mode); err != nil {
+		return 0, err
+	}
+	if mode != autoVacuumIncremental {
+		return 0, ErrNotIncremental
+	}
+	if err := conn.QueryRowContext(ctx, "PRAGMA freelist_count").Scan(&before); err != nil {
+		return 0, err
+	}
+	// incremental_vacuum returns no rows but does its work while the statement is stepped, so run
+	// it with Exec rather than a query that may never be read
+	if _, err := conn.ExecContext(ctx, "PRAGMA incremental_vacuum"); err != nil {
+		return 0, fmt.Errorf("failed to vacuum: %w", err)
+	}
+	if err := conn.QueryRowContext(ctx, "PRAGMA freelist_count").Scan(&after); err != nil {
+		return 0, err
+	}
+	return before - after, nil
+}
+
+// Backup writes a consistent copy of the database to path with VACUUM INTO, which reads it in
+// one transaction, so the copy is safe to take while other connections write. The copy is
+// compacted on the way. path must not exist yet.
+func Backup(ctx context.Context, conn *sql.DB, path string) error {
+	if _, err := conn.ExecContext(ctx, "VACUUM INTO ?", path); err != nil {
+		return fmt.Errorf("failed to back up to %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+// Package embeddings turns note text into vectors for semantic search. It holds the one Ollama
+// client used by the veclite syncer, the MCP server, and the library API, so configuration and
+// fixes apply everywhere.
+package embeddings
+
+import (
+	"errors"
+	"net/url"
+	"os"
+	"strings"
+)
+
+const (
+	// DefaultModel is used when NOTED_EMBEDDING_MODEL is not set.
+	DefaultModel = "nomic-embed-text"
+	// DefaultHost is used when OLLAMA_HOST is not set.
+	DefaultHost = "http://localhost:11434"
+
+	defaultPort = "11434"
+)
+
+// ErrUnavailable is returned while the embedding backend is considered down: after repeated
+// transient failures the embedder stops calling it for a cooldown period, so callers can fall back
+// to keyword search instead of waiting on every request.
+var ErrUnavailable = errors.New("embedder unavailable")
+
+// Embedder generates embedding vectors. It satisfies veclite.Embedder.
+type Embedder interface {
+	// Embed generates an embedding for a single text.
+	Embed(text string) ([]float32, error)
+	// EmbedBatch generates embeddings for multiple texts.
+	EmbedBatch(texts []string) ([][]float32, error)
+	// Dimension returns the length of the vectors, probed when the embedder was created.
+	Dimension() int
+	// IsAvailable reports whether the backend is reachable and has the model.
+	IsAvailable() bool
+}
+
+// HostFromEnv returns the Ollama base URL from OLLAMA_HOST, which may be a full URL or a bare
+// host with or without a port (as the ollama CLI accepts).
+func HostFromEnv() string {
+	return normalizeHost(os.Getenv("OLLAMA_HOST"))
+}
+
+func normalizeHost(host string) string {
+	host = strings.TrimRight(strings.TrimSpace(host), "/")
+	if host == "" {
+		return DefaultHost
+	}
+	if strings.HasPrefix(host, "http://") || strings.HasPrefix(host, "https://") {
+		return host // full URLs are used as given, e.g. behind a proxy on the default port
+	}
+	host = "http://" + host
+	if u, err := url.Parse(host); err == nil && u.Port() == "" {
+		host += ":" + defaultPort
+	}
+	return host
+}
@@ -0,0 +1,226 @@
+package embeddings
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+const (
+	embedAttempts    = 3                      // tries per embedding for transient failures
+	embedBackoff     = 250 * time.Millisecond // first retry delay, doubled per attempt
+	breakerThreshold = 3                      // consecutive failed embeddings that open the breaker
+	breakerCooldown  = 30 * time.Second       // how long the breaker stays open
+)
+
+// httpClient is shared by every embedder so connections to Ollama are pooled and reused.
+var httpClient = &http.Client{
+	Timeout: 60 * time.Second, // embedding a long note on CPU can be slow
+	Transport: &http.Transport{
+		Proxy:                 http.ProxyFromEnvironment,
+		DialContext:           (&net.Dialer{Timeout: 5 * time.Second, KeepAlive: 30 * time.Second}).DialContext,
+		MaxIdleConns:          8,
+		MaxIdleConnsPerHost:   8,
+		IdleConnTimeout:       90 * time.Second,
+		ResponseHeaderTimeout: 60 * time.Second,
+	},
+}
+
+// Ollama implements Embedder using the Ollama embeddings API.
+type Ollama struct {
+	model     string
+	host      string
+	dimension int
+	client    *http.Client
+	backoff   time.Duration
+
+	mu        sync.Mutex
+	failures  int       // consecutive failed embeddings
+	openUntil time.Time // breaker is open (Ollama skipped) until then
+}
+
+// NewOllama creates an Ollama embedder and probes the model's vector dimension. An empty model
+// means DefaultModel and an empty host DefaultHost.
+func NewOllama(model, host string) (*Ollama, error) {
+	if model == "" {
+		model = DefaultModel
+	}
+	if host == "" {
+		host = DefaultHost
+	}
+	e := &Ollama{
+		model:   model,
+		host:    host,
+		client:  httpClient,
+		backoff: embedBackoff,
+	}
+
+	// Probe dimension by embedding a test string. One attempt: if Ollama isn't running, callers
+	// should fall back quickly rather than wait out the retries.
+	vec, err := e.embedOnce("test")
+	if err != nil {
+		return nil, fmt.Errorf("failed to probe embedder dimension: %w", err)
+	}
+	e.dimension = len(vec)
+
+	return e, nil
+}
+
+// statusError is a non-200 response from Ollama.
+type statusError struct {
+	code int
+	body string
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("ollama returned status %d: %s", e.code, e.body)
+}
+
+// transient reports whether a failed request is worth retrying: network errors, rate limiting and
+// server errors are; a bad request or a missing model is not.
+func transient(err error) bool {
+	var se *statusError
+	if errors.As(err, &se) {
+		return se.code == http.StatusTooManyRequests || se.code >= 500
+	}
+	var ne net.Error
+	return errors.As(err, &ne) || errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.ECONNRESET)
+}
+
+// Embed generates an embedding for a single text, retrying transient failures with exponential
+// backoff. After breakerThreshold consecutive failures it returns ErrUnavailable without calling
+// Ollama until breakerCooldown has passed.
+func (e *Ollama) Embed(text string) ([]float32, error) {
+	e.mu.Lock()
+	if wait := time.Until(e.openUntil); wait > 0 {
+		e.mu.Unlock()
+		return nil, fmt.Errorf("%w (retrying in %ds)", ErrUnavailable, int(wait.Seconds())+1)
+	}
+	e.mu.Unlock()
+
+	var err error
+	delay := e.backoff
+	for attempt := 1; attempt <= embedAttempts; attempt++ {
+		var vec []float32
+		if vec, err = e.embedOnce(text); err == nil {
+			e.mu.Lock()
+			e.failures = 0
+			e.mu.Unlock()
+			return vec, nil
+		}
+		if !transient(err) {
+			return nil, err
+		}
+		if attempt < embedAttempts {
+			time.Sleep(delay)
+			delay *= 2
+		}
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.failures++
+	if e.failures >= breakerThreshold {
+		e.failures = 0
+		e.openUntil = time.Now().Add(breakerCooldown)
+		return nil, fmt.Errorf("%w: %v", ErrUnavailable, err)
+	}
+	return nil, err
+}
+
+// embedOnce makes a single embedding request.
+func (e *Ollama) embedOnce(text string) ([]float32, error) {
+	reqBody := map[string]any{
+		"model":  e.model,
+		"prompt": text,
+	}
+	bodyBytes, _ := json.Marshal(reqBody)
+
+	resp, err := e.client.Post(e.host+"/api/embeddings", "application/json", bytes.NewReader(bodyBytes))
+	if err != nil {
+		return nil, fmt.Errorf("ollama request failed: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, &statusError{code: resp.StatusCode, body: string(body)}
+	}
+
+	var result struct {
+		Embedding []float64 `json:"embedding"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode ollama response: %w", err)
+	}
+
+	// Convert float64 to float32
+	vec := make([]float32, len(result.Embedding))
+	for i, v := range result.Embedding {
+		vec[i] = float32(v)
+	}
+
+	return vec, nil
+}
+
+// EmbedBatch generates embeddings for multiple texts
+func (e *Ollama) EmbedBatch(texts []string) ([][]float32, error) {
+	results := make([][]float32, len(texts))
+	for i, text := range texts {
+		vec, err := e.Embed(text)
+		if err != nil {
+			return nil, err
+		}
+		results[i] = vec
+	}
+	return results, nil
+}
+
+// Dimension returns the embedding dimension
+func (e *Ollama) Dimension() int {
+	return e.dimension
+}
+
+// IsAvailable checks that Ollama is running and the model has been pulled. It reports false while
+// the breaker is open.
+func (e *Ollama) IsAvailable() bool {
+	e.mu.Lock()
+	open := time.Now().Before(e.openUntil)
+	e.mu.Unlock()
+	if open {
+		return false
+	}
+
+	resp, err := e.client.Get(e.host + "/api/tags")
+	if err != nil {
+		return false
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return false
+	}
+
+	var tags struct {
+		Models []struct {
+			Name string `json:"name"`
+		} `json:"models"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&tags); err != nil {
+		return false
+	}
+	for _, m := range tags.Models {
+		if m.Name == e.model || strings.TrimSuffix(m.Name, ":latest") == e.model {
+			return true
+		}
+	}
+	return false
+}
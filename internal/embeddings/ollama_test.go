@@ -0,0 +1,110 @@
+package embeddings
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+// fakeOllama serves /api/embeddings, failing with the given statuses before succeeding.
+func fakeOllama(t *testing.T, failures ...int) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := int(calls.Add(1))
+		if n <= len(failures) {
+			http.Error(w, "busy", failures[n-1])
+			return
+		}
+		_, _ = fmt.Fprint(w, `{"embedding":[0.1,0.2,0.3]}`)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &calls
+}
+
+func testEmbedder(host string) *Ollama {
+	return &Ollama{model: "test", host: host, client: httpClient, backoff: 0}
+}
+
+func TestEmbed_RetriesTransientFailures(t *testing.T) {
+	srv, calls := fakeOllama(t, http.StatusServiceUnavailable, http.StatusTooManyRequests)
+
+	vec, err := testEmbedder(srv.URL).Embed("hello")
+	if err != nil {
+		t.Fatalf("Embed failed: %v", err)
+	}
+	if len(vec) != 3 || calls.Load() != 3 {
+		t.Errorf("expected 3 values after 3 calls, got %d values after %d calls", len(vec), calls.Load())
+	}
+}
+
+func TestEmbed_DoesNotRetryClientErrors(t *testing.T) {
+	srv, calls := fakeOllama(t, http.StatusNotFound)
+
+	if _, err := testEmbedder(srv.URL).Embed("hello"); err == nil {
+		t.Fatal("expected error for missing model")
+	}
+	if calls.Load() != 1 {
+		t.Errorf("expected a single call, got %d", calls.Load())
+	}
+}
+
+func TestEmbed_BreakerOpensAfterRepeatedFailures(t *testing.T) {
+	failures := make([]int, embedAttempts*breakerThreshold)
+	for i := range failures {
+		failures[i] = http.StatusInternalServerError
+	}
+	srv, calls := fakeOllama(t, failures...)
+	e := testEmbedder(srv.URL)
+
+	var err error
+	for i := 0; i < breakerThreshold; i++ {
+		_, err = e.Embed("hello")
+	}
+	if !errors.Is(err, ErrUnavailable) {
+		t.Fatalf("expected ErrUnavailable once the breaker opens, got %v", err)
+	}
+
+	before := calls.Load()
+	if _, err := e.Embed("hello"); !errors.Is(err, ErrUnavailable) {
+		t.Errorf("expected open breaker to short-circuit, got %v", err)
+	}
+	if calls.Load() != before {
+		t.Error("open breaker should not call Ollama")
+	}
+}
+
+func TestIsAvailable_ChecksModel(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprint(w, `{"models":[{"name":"nomic-embed-text:latest"}]}`)
+	}))
+	defer srv.Close()
+
+	e := testEmbedder(srv.URL)
+	e.model = "nomic-embed-text"
+	if !e.IsAvailable() {
+		t.Error("expected pulled model to be available")
+	}
+	e.model = "mxbai-embed-large"
+	if e.IsAvailable() {
+		t.Error("expected missing model to be unavailable")
+	}
+}
+
+func TestNormalizeHost(t *testing.T) {
+	tests := map[string]string{
+		"":                        DefaultHost,
+		"localhost":               "http://localhost:11434",
+		"gpu-box:8080":            "http://gpu-box:8080",
+		"http://gpu-box:11434/":   "http://gpu-box:11434",
+		"https://ollama.internal": "https://ollama.internal",
+	}
+	for in, want := range tests {
+		if got := normalizeHost(in); got != want {
+			t.Errorf("normalizeHost(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,100 @@
+// Package importer reads notes exported from other tools. Each input format implements Importer
+// and registers itself with Register, so "noted import" can pick one by name or by sniffing the
+// input without knowing about any of them.
+package importer
+
+import (
+	"fmt"
+	"slices"
+)
+
+// Entry is one item read from an import source, still in the source's format: a markdown file,
+// or one page of an export that holds many.
+type Entry struct {
+	Path string // file the entry was read from, for messages
+	Name string // the entry's name inside Path, for formats that hold many notes; "" otherwise
+	Data []byte
+}
+
+// String names the entry for messages: its path, and its name inside the file if it has one.
+func (e Entry) String() string {
+	if e.Name == "" {
+		return e.Path
+	}
+	return e.Path + "#" + e.Name
+}
+
+// Note is an entry mapped to a note, ready to store.
+type Note struct {
+	Title   string
+	Content string
+	Tags    []string
+	Aliases []string
+	Dir     string // directory relative links in Content resolve against; "" when they can't be
+}
+
+// Options controls how an input is read.
+type Options struct {
+	Recursive bool // read subdirectories of a directory input
+}
+
+// Importer reads one input format.
+type Importer interface {
+	// Name is the format's name for "noted import --format".
+	Name() string
+	// Detect reports whether path, a file or a directory, holds this format.
+	Detect(path string) bool
+	// Parse reads the entries at path.
+	Parse(path string, opts Options) ([]Entry, error)
+	// Map turns an entry into a note.
+	Map(e Entry) (Note, error)
+}
+
+// registry holds the registered formats in registration order. Markdown is not in it: it is the
+// fallback, tried after every other format.
+var registry []Importer
+
+// Register makes a format available to Lookup and Detect. Formats call it from init.
+func Register(imp Importer) {
+	if _, ok := Lookup(imp.Name()); ok {
+		panic("importer: format " + imp.Name() + " registered twice")
+	}
+	registry = append(registry, imp)
+}
+
+// Lookup returns the importer for a format name.
+func Lookup(name string) (Importer, bool) {
+	if name == (Markdown{}).Name() {
+		return Markdown{}, true
+	}
+	for _, imp := range registry {
+		if imp.Name() == name {
+			return imp, true
+		}
+	}
+	return nil, false
+}
+
+// Formats returns the names of every format, sorted.
+func Formats() []string {
+	names := []string{(Markdown{}).Name()}
+	for _, imp := range registry {
+		names = append(names, imp.Name())
+	}
+	slices.Sort(names)
+	return names
+}
+
+// Detect returns the importer for the format path is in: the first registered one that
+// recognizes it, or Markdown.
+func Detect(path string) (Importer, error) {
+	for _, imp := range registry {
+		if imp.Detect(path) {
+			return imp, nil
+		}
+	}
+	if (Markdown{}).Detect(path) {
+		return Markdown{}, nil
+	}
+	return nil, fmt.Errorf("can't tell the format of %s (formats: %v)", path, Formats())
+}
@@ -0,0 +1,316 @@
+package importer
+
+import (
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// parseFile reads a single markdown file the way "noted import" does.
+func parseFile(path string) (Note, error) {
+	entries, err := Markdown{}.Parse(path, Options{})
+	if err != nil {
+		return Note{}, err
+	}
+	return Markdown{}.Map(entries[0])
+}
+
+func TestMarkdownMap(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	tests := []struct {
+		name        string
+		content     string
+		wantTitle   string
+		wantTags    []string
+		wantContent string
+	}{
+		{
+			name: "with frontmatter",
+			content: `---
+title: "My Title"
+tags: [go, testing]
+---
+
+Content here`,
+			wantTitle:   "My Title",
+			wantTags:    []string{"go", "testing"},
+			wantContent: "Content here",
+		},
+		{
+			name:        "with H1 heading",
+			content:     "# Heading Title\n\nSome content",
+			wantTitle:   "Heading Title",
+			wantTags:    nil,
+			wantContent: "# Heading Title\n\nSome content",
+		},
+		{
+			name:        "plain content",
+			content:     "Just plain content\nNo heading",
+			wantTitle:   "", // Will use filename
+			wantTags:    nil,
+			wantContent: "Just plain content\nNo heading",
+		},
+		{
+			name:        "CRLF line endings",
+			content:     "---\r\ntitle: Windows Note\r\ntags: [win]\r\n---\r\n\r\nSaved in Notepad\r\n",
+			wantTitle:   "Windows Note",
+			wantTags:    []string{"win"},
+			wantContent: "Saved in Notepad",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filename := "test.md"
+			if tt.wantTitle == "" {
+				filename = "expected-title.md"
+			}
+			mdFile := filepath.Join(tmpDir, filename)
+			if err := os.WriteFile(mdFile, []byte(tt.content), 0644); err != nil {
+				t.Fatalf("failed to write test file: %v", err)
+			}
+
+			note, err := parseFile(mdFile)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			title, content, tags := note.Title, note.Content, note.Tags
+
+			expectedTitle := tt.wantTitle
+			if expectedTitle == "" {
+				expectedTitle = "expected-title"
+			}
+
+			if title != expectedTitle {
+				t.Errorf("title: expected %q, got %q", expectedTitle, title)
+			}
+
+			if len(tags) != len(tt.wantTags) {
+				t.Errorf("tags: expected %v, got %v", tt.wantTags, tags)
+			}
+
+			if !strings.Contains(content, strings.Split(tt.wantContent, "\n")[0]) {
+				t.Errorf("content should contain %q", tt.wantContent)
+			}
+		})
+	}
+}
+
+func TestMarkdownMap_UppercaseExtension(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "Meeting Notes.MD")
+	if err := os.WriteFile(path, []byte("no heading"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if note, err := parseFile(path); err != nil || note.Title != "Meeting Notes" {
+		t.Errorf("expected title %q, got %q (err %v)", "Meeting Notes", note.Title, err)
+	}
+}
+
+func TestMarkdownMap_Aliases(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "go.md")
+	if err := os.WriteFile(path, []byte("---\ntitle: Go\naliases: [Golang, \"Go language\"]\n---\nbody"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	note, err := parseFile(path)
+	if err != nil || !slices.Equal(note.Aliases, []string{"Golang", "Go language"}) {
+		t.Errorf("aliases = %v (err %v)", note.Aliases, err)
+	}
+}
+
+func TestMarkdownParse(t *testing.T) {
+	dir := t.TempDir()
+	for _, f := range []string{"a.md", "sub/b.MD", "sub/image.png"} {
+		path := filepath.Join(dir, f)
+		_ = os.MkdirAll(filepath.Dir(path), 0o755)
+		if err := os.WriteFile(path, []byte("x"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if entries, _ := (Markdown{}).Parse(dir, Options{}); len(entries) != 1 {
+		t.Errorf("top level: %d entries, want 1", len(entries))
+	}
+	if entries, _ := (Markdown{}).Parse(dir, Options{Recursive: true}); len(entries) != 2 {
+		t.Errorf("recursive: %d entries, want 2", len(entries))
+	}
+	note, _ := parseFile(filepath.Join(dir, "sub", "b.MD"))
+	if note.Dir != filepath.Join(dir, "sub") {
+		t.Errorf("Dir = %q, want the file's directory", note.Dir)
+	}
+}
+
+func TestDetectAndLookup(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := Detect(dir); err == nil {
+		t.Error("expected an error for a directory with nothing to import")
+	}
+	_ = os.WriteFile(filepath.Join(dir, "note.txt"), []byte("plain text"), 0o644)
+	if imp, err := Detect(filepath.Join(dir, "note.txt")); err != nil || imp.Name() != "markdown" {
+		t.Errorf("Detect(file) = %v, %v; want markdown", imp, err)
+	}
+
+	if _, ok := Lookup("markdown"); !ok {
+		t.Error("markdown is always available")
+	}
+	if _, ok := Lookup("nope"); ok {
+		t.Error("Lookup of an unknown format succeeded")
+	}
+	if !slices.Contains(Formats(), "markdown") {
+		t.Errorf("Formats() = %v", Formats())
+	}
+}
+
+// importAll parses and maps every entry of path with imp.
+func importAll(t *testing.T, imp Importer, path string) map[string]Note {
+	t.Helper()
+	entries, err := imp.Parse(path, Options{})
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	notes := make(map[string]Note)
+	for _, e := range entries {
+		n, err := imp.Map(e)
+		if err != nil {
+			t.Fatalf("Map(%s): %v", e, err)
+		}
+		notes[n.Title] = n
+	}
+	return notes
+}
+
+func TestRoam(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "graph.json")
+	graph := `[
+  {"title": "Project X", "children": [
+    {"string": "Goals", "uid": "h1", "heading": 2, "children": [
+      {"string": "Ship the #[[Import Tool]]", "uid": "a1", "children": [
+        {"string": "by Friday", "uid": "a2"}
+      ]}
+    ]},
+    {"string": "See ((b1)) and ((zzz))", "uid": "a3"}
+  ]},
+  {"title": "Import Tool", "children": [
+    {"string": "Reads [[Project X]] exports", "uid": "b1"}
+  ]}
+]`
+	_ = os.WriteFile(path, []byte(graph), 0o644)
+
+	imp, err := Detect(path)
+	if err != nil || imp.Name() != "roam" {
+		t.Fatalf("Detect = %v, %v; want roam", imp, err)
+	}
+	notes := importAll(t, imp, path)
+	if len(notes) != 2 {
+		t.Fatalf("got %d notes, want 2", len(notes))
+	}
+	want := "## Goals\n\n- Ship the [[Import Tool]]\n  - by Friday\n- See Reads [[Project X]] exports ([[Import Tool]]) and ((zzz))\n"
+	if got := notes["Project X"].Content; got != want {
+		t.Errorf("content = %q, want %q", got, want)
+	}
+	if got := notes["Import Tool"].Content; got != "- Reads [[Project X]] exports\n" {
+		t.Errorf("content = %q", got)
+	}
+}
+
+func TestRoam_Logseq(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "logseq.json")
+	graph := `{"version": 1, "blocks": [
+  {"id": "p1", "page-name": "Reading", "properties": {"tags": ["books", "2026"]}, "children": [
+    {"id": "c0", "content": "tags:: books, 2026", "children": []},
+    {"id": "c1", "content": "## Queue", "children": [
+      {"id": "c2", "content": "Dune\nid:: c2\nby Herbert", "children": []}
+    ]},
+    {"id": "c3", "content": "Next: ((c2))", "children": []}
+  ]}
+]}`
+	_ = os.WriteFile(path, []byte(graph), 0o644)
+
+	if !(Roam{}).Detect(path) {
+		t.Fatal("Detect rejected a Logseq export")
+	}
+	notes := importAll(t, Roam{}, path)
+	n, ok := notes["Reading"]
+	if !ok {
+		t.Fatalf("notes = %v", notes)
+	}
+	want := "## Queue\n\n- Dune\n  by Herbert\n- Next: Dune\n  by Herbert\n"
+	if n.Content != want {
+		t.Errorf("content = %q, want %q", n.Content, want)
+	}
+	if !slices.Equal(n.Tags, []string{"books", "2026"}) {
+		t.Errorf("tags = %v", n.Tags)
+	}
+
+	other := filepath.Join(dir, "data.json")
+	_ = os.WriteFile(other, []byte(`{"name": "not a graph"}`), 0o644)
+	if (Roam{}).Detect(other) {
+		t.Error("Detect accepted JSON that isn't an export")
+	}
+}
+
+func TestTiddlyWiki(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "tiddlers.json")
+	_ = os.WriteFile(path, []byte(`[
+  {"title": "Reading List", "tags": "books [[to read]]", "text": "! Books\n* ''Dune'' by //Herbert//\n** see [[Sci-fi|Science Fiction]]\n# first\nSite: [[home|https://example.com]] or https://example.com/x\n{{Quote}} and `+"`''raw''`"+`"},
+  {"title": "Notes.md", "type": "text/x-markdown", "text": "# Already *markdown*"},
+  {"title": "$:/config/Theme", "text": "system"},
+  {"title": "Draft of 'Reading List'", "draft.of": "Reading List", "text": "draft"},
+  {"title": "logo.png", "type": "image/png", "text": "iVBOR"}
+]`), 0o644)
+
+	imp, err := Detect(path)
+	if err != nil || imp.Name() != "tiddlywiki" {
+		t.Fatalf("Detect = %v, %v; want tiddlywiki", imp, err)
+	}
+	notes := importAll(t, imp, path)
+	if len(notes) != 2 {
+		t.Fatalf("got %d notes, want 2: %v", len(notes), slices.Collect(maps.Keys(notes)))
+	}
+	n := notes["Reading List"]
+	want := "# Books\n- **Dune** by *Herbert*\n  - see [[Science Fiction|Sci-fi]]\n1. first\n" +
+		"Site: [home](https://example.com) or https://example.com/x\n![[Quote]] and `''raw''`"
+	if n.Content != want {
+		t.Errorf("content = %q, want %q", n.Content, want)
+	}
+	if !slices.Equal(n.Tags, []string{"books", "to read"}) {
+		t.Errorf("tags = %v", n.Tags)
+	}
+	if got := notes["Notes.md"].Content; got != "# Already *markdown*" {
+		t.Errorf("markdown tiddler changed: %q", got)
+	}
+}
+
+func TestTiddlyWiki_HTML(t *testing.T) {
+	dir := t.TempDir()
+	modern := filepath.Join(dir, "wiki.html")
+	_ = os.WriteFile(modern, []byte(`<html><body>
+<script class="tiddlywiki-tiddler-store" type="application/json">[{"title":"Hello","tags":"greeting","text":"Hi <there>"}]</script>
+</body></html>`), 0o644)
+	classic := filepath.Join(dir, "classic.html")
+	_ = os.WriteFile(classic, []byte(`<div id="storeArea">
+<div title="Old &amp; Gold" tags="[[old stuff]]" modified="200901011200">
+<pre>A &lt;b&gt; tag</pre>
+</div>
+</div>`), 0o644)
+
+	for path, want := range map[string]Note{
+		modern:  {Title: "Hello", Content: "Hi <there>", Tags: []string{"greeting"}},
+		classic: {Title: "Old & Gold", Content: "A <b> tag", Tags: []string{"old stuff"}},
+	} {
+		if !(TiddlyWiki{}).Detect(path) {
+			t.Errorf("Detect(%s) = false", path)
+			continue
+		}
+		n, ok := importAll(t, TiddlyWiki{}, path)[want.Title]
+		if !ok || n.Content != want.Content || !slices.Equal(n.Tags, want.Tags) {
+			t.Errorf("%s: got %+v, want %+v", path, n, want)
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package importer
+
+import (
+	"bufio"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/abdul-hamid-achik/noted/internal/vault"
+	"gopkg.in/yaml.v3"
+)
+
+// Markdown imports markdown files, optionally with YAML frontmatter giving the title, tags, and
+// aliases, from a file or a directory of them.
+type Markdown struct{}
+
+type frontmatter struct {
+	Title   string           `yaml:"title"`
+	Tags    []string         `yaml:"tags"`
+	Aliases vault.StringList `yaml:"aliases"`
+}
+
+func (Markdown) Name() string { return "markdown" }
+
+func isMarkdown(name string) bool {
+	return strings.EqualFold(filepath.Ext(name), ".md")
+}
+
+// errFound stops a directory walk at the first markdown file.
+var errFound = errors.New("found")
+
+// Detect accepts any file, since it is tried after every other format and plain text reads as
+// markdown, and a directory with a markdown file anywhere inside it.
+func (Markdown) Detect(path string) bool {
+	info, err := os.Stat(path)
+	if err != nil {
+		return false
+	}
+	if !info.IsDir() {
+		return true
+	}
+	err = filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+		if err == nil && !d.IsDir() && isMarkdown(p) {
+			return errFound
+		}
+		return nil
+	})
+	return errors.Is(err, errFound)
+}
+
+// Parse reads path if it is a file, or the markdown files in it if it is a directory, descending
+// into subdirectories with opts.Recursive.
+func (Markdown) Parse(path string, opts Options) ([]Entry, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	if !info.IsDir() {
+		files = []string{path}
+	} else if opts.Recursive {
+		err = filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if !d.IsDir() && isMarkdown(p) {
+				files = append(files, p)
+			}
+			return nil
+		})
+	} else {
+		var entries []os.DirEntry
+		entries, err = os.ReadDir(path)
+		for _, e := range entries {
+			if !e.IsDir() && isMarkdown(e.Name()) {
+				files = append(files, filepath.Join(path, e.Name()))
+			}
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]Entry, 0, len(files))
+	for _, f := range files {
+		data, err := os.ReadFile(f)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, Entry{Path: f, Data: data})
+	}
+	return out, nil
+}
+
+// Map reads a file's frontmatter. Without a frontmatter title, the title is the first H1 heading,
+// or else the file name.
+func (Markdown) Map(e Entry) (Note, error) {
+	text := strings.ReplaceAll(string(e.Data), "\r\n", "\n") // files saved on Windows
+	fm := frontmatter{}
+
+	if strings.HasPrefix(text, "---\n") {
+		parts := strings.SplitN(text[4:], "\n---\n", 2)
+		if len(parts) == 2 {
+			if err := yaml.Unmarshal([]byte(parts[0]), &fm); err == nil {
+				text = strings.TrimPrefix(parts[1], "\n")
+			}
+		}
+	}
+
+	title := fm.Title
+	if title == "" {
+		scanner := bufio.NewScanner(strings.NewReader(text))
+		for scanner.Scan() {
+			if line := scanner.Text(); strings.HasPrefix(line, "# ") {
+				title = strings.TrimPrefix(line, "# ")
+				break
+			}
+		}
+	}
+	if title == "" {
+		base := filepath.Base(e.Path)
+		title = base[:len(base)-len(filepath.Ext(base))]
+	}
+
+	return Note{Title: title, Content: text, Tags: fm.Tags, Aliases: fm.Aliases, Dir: filepath.Dir(e.Path)}, nil
+}
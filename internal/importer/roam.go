@@ -0,0 +1,286 @@
+package importer
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Roam imports a Roam Research JSON export, or a Logseq one ("Export graph" as JSON), both of
+// which hold pages of nested blocks. Each page becomes a note whose blocks are a nested markdown
+// list; ((block references)) are replaced by the text of the block they point to, linked to its
+// page when that is another page.
+type Roam struct{}
+
+func init() { Register(Roam{}) }
+
+// graphPage is a page of either export, with its block references resolved, as a Roam entry holds
+// it between Parse and Map.
+type graphPage struct {
+	Title  string       `json:"title"`
+	Tags   []string     `json:"tags,omitempty"`
+	Blocks []graphBlock `json:"blocks,omitempty"`
+}
+
+type graphBlock struct {
+	Text     string       `json:"text"`
+	Heading  int          `json:"heading,omitempty"`
+	Children []graphBlock `json:"children,omitempty"`
+}
+
+// roamPage and roamBlock are the parts of a Roam export that are read.
+type roamPage struct {
+	Title    string      `json:"title"`
+	EditTime int64       `json:"edit-time"`
+	Children []roamBlock `json:"children"`
+}
+
+type roamBlock struct {
+	String   string      `json:"string"`
+	UID      string      `json:"uid"`
+	Heading  int         `json:"heading"`
+	Children []roamBlock `json:"children"`
+}
+
+// logseqExport and logseqBlock are the parts of a Logseq export that are read.
+type logseqExport struct {
+	Blocks []logseqBlock `json:"blocks"`
+}
+
+type logseqBlock struct {
+	ID         string         `json:"id"`
+	PageName   string         `json:"page-name"`
+	Content    string         `json:"content"`
+	Properties map[string]any `json:"properties"`
+	Children   []logseqBlock  `json:"children"`
+}
+
+var (
+	blockRefRe    = regexp.MustCompile(`\(\(([\w-]+)\)\)`)
+	tagPageRe     = regexp.MustCompile(`#\[\[([^\]]+)\]\]`)
+	propertyRe    = regexp.MustCompile(`^[A-Za-z][\w-]*:: `)
+	logseqHeading = regexp.MustCompile(`^(#{1,6}) `)
+)
+
+func (Roam) Name() string { return "roam" }
+
+// Detect accepts a .json file holding a Roam page array or a Logseq export.
+func (Roam) Detect(path string) bool {
+	if !strings.EqualFold(filepath.Ext(path), ".json") {
+		return false
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return false
+	}
+	_, err = readGraph(data)
+	return err == nil
+}
+
+// Parse reads every page of the export, resolving block references across the whole graph.
+func (Roam) Parse(path string, opts Options) ([]Entry, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	pages, err := readGraph(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	entries := make([]Entry, 0, len(pages))
+	for _, p := range pages {
+		b, err := json.Marshal(p)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, Entry{Path: path, Name: p.Title, Data: b})
+	}
+	return entries, nil
+}
+
+// Map flattens a page's blocks into a nested markdown list. Top-level heading blocks become
+// markdown headings.
+func (Roam) Map(e Entry) (Note, error) {
+	var p graphPage
+	if err := json.Unmarshal(e.Data, &p); err != nil {
+		return Note{}, err
+	}
+	var b strings.Builder
+	var write func(blocks []graphBlock, depth int)
+	write = func(blocks []graphBlock, depth int) {
+		for _, blk := range blocks {
+			indent := strings.Repeat("  ", depth)
+			lines := strings.Split(strings.TrimSpace(blk.Text), "\n")
+			if depth == 0 && blk.Heading > 0 {
+				fmt.Fprintf(&b, "%s %s\n\n", strings.Repeat("#", blk.Heading), strings.Join(lines, " "))
+				write(blk.Children, 0)
+				continue
+			}
+			fmt.Fprintf(&b, "%s- %s\n", indent, lines[0])
+			for _, l := range lines[1:] {
+				fmt.Fprintf(&b, "%s  %s\n", indent, l)
+			}
+			write(blk.Children, depth+1)
+		}
+	}
+	write(p.Blocks, 0)
+	return Note{Title: p.Title, Content: strings.TrimSpace(b.String()) + "\n", Tags: p.Tags}, nil
+}
+
+// readGraph reads a Roam or Logseq export into pages with their block references resolved.
+func readGraph(data []byte) ([]graphPage, error) {
+	var roam []roamPage
+	// Every Roam page has an edit time or blocks, which tells the export from other arrays of
+	// titled objects, such as TiddlyWiki's
+	if err := json.Unmarshal(data, &roam); err == nil && len(roam) > 0 && roam[0].Title != "" &&
+		(roam[0].EditTime != 0 || roam[0].Children != nil) {
+		return roamGraph(roam), nil
+	}
+	var logseq logseqExport
+	if err := json.Unmarshal(data, &logseq); err == nil && len(logseq.Blocks) > 0 && logseq.Blocks[0].PageName != "" {
+		return logseqGraph(logseq.Blocks), nil
+	}
+	return nil, fmt.Errorf("not a Roam or Logseq JSON export")
+}
+
+// blockRef is a block's text and the page it is on, for resolving references to it.
+type blockRef struct {
+	text string
+	page string
+}
+
+func roamGraph(pages []roamPage) []graphPage {
+	refs := make(map[string]blockRef)
+	var index func(page string, blocks []roamBlock)
+	index = func(page string, blocks []roamBlock) {
+		for _, b := range blocks {
+			if b.UID != "" {
+				refs[b.UID] = blockRef{text: b.String, page: page}
+			}
+			index(page, b.Children)
+		}
+	}
+	for _, p := range pages {
+		index(p.Title, p.Children)
+	}
+
+	var convert func(page string, blocks []roamBlock) []graphBlock
+	convert = func(page string, blocks []roamBlock) []graphBlock {
+		out := make([]graphBlock, 0, len(blocks))
+		for _, b := range blocks {
+			out = append(out, graphBlock{
+				Text:     graphText(b.String, page, refs),
+				Heading:  b.Heading,
+				Children: convert(page, b.Children),
+			})
+		}
+		return out
+	}
+	out := make([]graphPage, 0, len(pages))
+	for _, p := range pages {
+		out = append(out, graphPage{Title: p.Title, Blocks: convert(p.Title, p.Children)})
+	}
+	return out
+}
+
+func logseqGraph(pages []logseqBlock) []graphPage {
+	refs := make(map[string]blockRef)
+	var index func(page string, blocks []logseqBlock)
+	index = func(page string, blocks []logseqBlock) {
+		for _, b := range blocks {
+			if b.ID != "" {
+				refs[b.ID] = blockRef{text: logseqText(b.Content), page: page}
+			}
+			index(page, b.Children)
+		}
+	}
+	for _, p := range pages {
+		index(p.PageName, p.Children)
+	}
+
+	var convert func(page string, blocks []logseqBlock) []graphBlock
+	convert = func(page string, blocks []logseqBlock) []graphBlock {
+		out := make([]graphBlock, 0, len(blocks))
+		for _, b := range blocks {
+			text := logseqText(b.Content)
+			if text == "" && len(b.Children) == 0 {
+				continue // a page's properties block
+			}
+			heading := 0
+			if m := logseqHeading.FindStringSubmatch(text); m != nil {
+				heading, text = len(m[1]), text[len(m[0]):]
+			}
+			out = append(out, graphBlock{
+				Text:     graphText(text, page, refs),
+				Heading:  heading,
+				Children: convert(page, b.Children),
+			})
+		}
+		return out
+	}
+	out := make([]graphPage, 0, len(pages))
+	for _, p := range pages {
+		out = append(out, graphPage{
+			Title:  p.PageName,
+			Tags:   propertyList(p.Properties["tags"]),
+			Blocks: convert(p.PageName, p.Children),
+		})
+	}
+	return out
+}
+
+// logseqText drops the "key:: value" property lines Logseq keeps in block content.
+func logseqText(content string) string {
+	var kept []string
+	for _, l := range strings.Split(content, "\n") {
+		if !propertyRe.MatchString(strings.TrimSpace(l)) {
+			kept = append(kept, l)
+		}
+	}
+	return strings.TrimSpace(strings.Join(kept, "\n"))
+}
+
+// graphText converts a block's links to noted's: #[[Page]] tags become [[Page]] links, and
+// ((block references)) become the referenced text, followed by a link to its page when that is
+// another page. References to blocks outside the export are kept as written.
+func graphText(text, page string, refs map[string]blockRef) string {
+	text = tagPageRe.ReplaceAllString(text, "[[$1]]")
+	return blockRefRe.ReplaceAllStringFunc(text, func(m string) string {
+		ref, ok := refs[blockRefRe.FindStringSubmatch(m)[1]]
+		if !ok {
+			return m
+		}
+		// Nested references are dropped rather than followed, so a cycle can't recurse
+		resolved := tagPageRe.ReplaceAllString(blockRefRe.ReplaceAllString(ref.text, ""), "[[$1]]")
+		if ref.page != page {
+			resolved += " ([[" + ref.page + "]])"
+		}
+		return resolved
+	})
+}
+
+// propertyList reads a Logseq list property, which the export writes as an array or as a
+// comma-separated string.
+func propertyList(v any) []string {
+	var items []string
+	switch v := v.(type) {
+	case []any:
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				items = append(items, s)
+			}
+		}
+	case string:
+		items = strings.Split(v, ",")
+	}
+	var out []string
+	for _, s := range items {
+		if s = strings.TrimSpace(strings.Trim(strings.TrimSpace(s), "[]#")); s != "" {
+			out = append(out, s)
+		}
+	}
+	return out
+}
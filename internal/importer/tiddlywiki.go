@@ -0,0 +1,247 @@
+package importer
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// TiddlyWiki imports tiddlers from a TiddlyWiki JSON export or from a wiki's HTML file. Each
+// tiddler becomes a note with the tiddler's tags; wikitext is converted to markdown, and its
+// links and transclusions to wikilinks and embeds. System tiddlers ($:/...), drafts, and
+// tiddlers that aren't text are skipped.
+type TiddlyWiki struct{}
+
+func init() { Register(TiddlyWiki{}) }
+
+// tiddler is a tiddler's fields that are imported, as a TiddlyWiki entry holds it between Parse
+// and Map.
+type tiddler struct {
+	Title string   `json:"title"`
+	Text  string   `json:"text"`
+	Tags  []string `json:"tags,omitempty"`
+	Type  string   `json:"type,omitempty"`
+}
+
+var (
+	tiddlerStoreRe = regexp.MustCompile(`(?s)<script[^>]*class="tiddlywiki-tiddler-store"[^>]*>(.*?)</script>`)
+	storeDivRe     = regexp.MustCompile(`(?s)<div ([^>]*)>\s*<pre>(.*?)</pre>\s*</div>`)
+	attributeRe    = regexp.MustCompile(`([\w.-]+)="([^"]*)"`)
+
+	twHeadingRe    = regexp.MustCompile(`^(!{1,6})\s*(.*)$`)
+	twListRe       = regexp.MustCompile(`^([*#]+)\s*(.*)$`)
+	twLinkRe       = regexp.MustCompile(`\[\[([^\]|]+)\|([^\]]+)\]\]`)
+	twPlainLinkRe  = regexp.MustCompile(`\[\[([^\]|]+)\]\]`)
+	twTranscludeRe = regexp.MustCompile(`\{\{([^{}|]+)\}\}`)
+	twBoldRe       = regexp.MustCompile(`''(.+?)''`)
+	twItalicRe     = regexp.MustCompile(`(^|[^:])//(.+?)//`)
+)
+
+func (TiddlyWiki) Name() string { return "tiddlywiki" }
+
+// Detect accepts a .json file holding an array of tiddlers, and an .html file holding a wiki.
+func (TiddlyWiki) Detect(path string) bool {
+	ext := strings.ToLower(filepath.Ext(path))
+	if ext != ".json" && ext != ".html" && ext != ".htm" {
+		return false
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return false
+	}
+	if ext == ".json" {
+		var raw []map[string]any
+		if json.Unmarshal(data, &raw) != nil || len(raw) == 0 {
+			return false
+		}
+		_, hasTitle := raw[0]["title"]
+		_, hasText := raw[0]["text"]
+		return hasTitle && hasText
+	}
+	return tiddlerStoreRe.Match(data) || bytes.Contains(data, []byte(`<div id="storeArea"`))
+}
+
+// Parse reads the tiddlers in a JSON export or a wiki's HTML file.
+func (TiddlyWiki) Parse(path string, opts Options) ([]Entry, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var raw []map[string]any
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		if err := json.Unmarshal(data, &raw); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+	} else if raw, err = htmlTiddlers(data); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	var entries []Entry
+	for _, fields := range raw {
+		t := tiddler{
+			Title: fieldString(fields["title"]),
+			Text:  fieldString(fields["text"]),
+			Tags:  tiddlerTags(fields["tags"]),
+			Type:  fieldString(fields["type"]),
+		}
+		if t.Title == "" || strings.HasPrefix(t.Title, "$:/") || fields["draft.of"] != nil || !isTextTiddler(t.Type) {
+			continue
+		}
+		b, err := json.Marshal(t)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, Entry{Path: path, Name: t.Title, Data: b})
+	}
+	return entries, nil
+}
+
+// Map converts a tiddler to a note, translating wikitext to markdown.
+func (TiddlyWiki) Map(e Entry) (Note, error) {
+	var t tiddler
+	if err := json.Unmarshal(e.Data, &t); err != nil {
+		return Note{}, err
+	}
+	content := t.Text
+	if t.Type == "" || t.Type == "text/vnd.tiddlywiki" {
+		content = wikitextToMarkdown(content)
+	}
+	return Note{Title: t.Title, Content: content, Tags: t.Tags}, nil
+}
+
+// isTextTiddler reports whether a tiddler's content type is text worth a note: wikitext,
+// markdown, or plain text. Images, scripts, and stylesheets are not.
+func isTextTiddler(typ string) bool {
+	switch typ {
+	case "", "text/vnd.tiddlywiki", "text/x-markdown", "text/markdown", "text/plain":
+		return true
+	}
+	return false
+}
+
+// htmlTiddlers reads the tiddlers of a wiki's HTML file: the JSON tiddler stores of TiddlyWiki
+// 5.2 and later, or the storeArea divs of older versions.
+func htmlTiddlers(data []byte) ([]map[string]any, error) {
+	var out []map[string]any
+	for _, m := range tiddlerStoreRe.FindAllSubmatch(data, -1) {
+		var store []map[string]any
+		if err := json.Unmarshal(m[1], &store); err != nil {
+			return nil, fmt.Errorf("reading tiddler store: %w", err)
+		}
+		out = append(out, store...)
+	}
+	if len(out) > 0 {
+		return out, nil
+	}
+	for _, m := range storeDivRe.FindAllSubmatch(data, -1) {
+		fields := make(map[string]any)
+		for _, a := range attributeRe.FindAllSubmatch(m[1], -1) {
+			fields[string(a[1])] = html.UnescapeString(string(a[2]))
+		}
+		fields["text"] = html.UnescapeString(string(m[2]))
+		out = append(out, fields)
+	}
+	if len(out) == 0 {
+		return nil, fmt.Errorf("no tiddlers found")
+	}
+	return out, nil
+}
+
+func fieldString(v any) string {
+	s, _ := v.(string)
+	return s
+}
+
+// tiddlerTags reads a tiddler's tags, a space-separated list where tags with spaces are written
+// [[like this]], or an array in some exports.
+func tiddlerTags(v any) []string {
+	var tags []string
+	switch v := v.(type) {
+	case []any:
+		for _, item := range v {
+			if s, ok := item.(string); ok && s != "" {
+				tags = append(tags, s)
+			}
+		}
+	case string:
+		for v = strings.TrimSpace(v); v != ""; v = strings.TrimSpace(v) {
+			if strings.HasPrefix(v, "[[") {
+				if end := strings.Index(v, "]]"); end >= 0 {
+					if tag := strings.TrimSpace(v[2:end]); tag != "" {
+						tags = append(tags, tag)
+					}
+					v = v[end+2:]
+					continue
+				}
+			}
+			tag, rest, _ := strings.Cut(v, " ")
+			tags = append(tags, tag)
+			v = rest
+		}
+	}
+	return tags
+}
+
+// wikitextToMarkdown converts the common parts of TiddlyWiki wikitext to markdown: headings,
+// lists, bold and italic text, links, and transclusions. [[Text|Title]] links become
+// [[Title|Text]], and links to URLs become markdown links. Code blocks and inline code are kept
+// as written.
+func wikitextToMarkdown(text string) string {
+	lines := strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+	inCode := false
+	for i, line := range lines {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			inCode = !inCode
+			continue
+		}
+		if inCode {
+			continue
+		}
+		if m := twHeadingRe.FindStringSubmatch(line); m != nil {
+			line = strings.Repeat("#", len(m[1])) + " " + m[2]
+		} else if m := twListRe.FindStringSubmatch(line); m != nil {
+			marker := "- "
+			if strings.HasSuffix(m[1], "#") {
+				marker = "1. "
+			}
+			line = strings.Repeat("  ", len(m[1])-1) + marker + m[2]
+		}
+
+		// Odd segments between backticks are inline code
+		segments := strings.Split(line, "`")
+		for j := 0; j < len(segments); j += 2 {
+			segments[j] = wikitextInline(segments[j])
+		}
+		lines[i] = strings.Join(segments, "`")
+	}
+	return strings.Join(lines, "\n")
+}
+
+func wikitextInline(s string) string {
+	s = twLinkRe.ReplaceAllStringFunc(s, func(m string) string {
+		parts := twLinkRe.FindStringSubmatch(m)
+		text, target := parts[1], parts[2]
+		if isURL(target) {
+			return "[" + text + "](" + target + ")"
+		}
+		return "[[" + target + "|" + text + "]]"
+	})
+	s = twPlainLinkRe.ReplaceAllStringFunc(s, func(m string) string {
+		if target := twPlainLinkRe.FindStringSubmatch(m)[1]; isURL(target) {
+			return "<" + target + ">"
+		}
+		return m
+	})
+	s = twTranscludeRe.ReplaceAllString(s, "![[$1]]")
+	s = twBoldRe.ReplaceAllString(s, "**$1**")
+	return twItalicRe.ReplaceAllString(s, "$1*$2*")
+}
+
+func isURL(s string) bool {
+	return strings.Contains(s, "://") || strings.HasPrefix(s, "mailto:")
+}
@@ -0,0 +1,111 @@
+// Package lang guesses the language a note is written in from the common function words ("the",
+// "que", "und") it uses, which is enough to tell apart the languages of a personal knowledge base
+// without a model or a dictionary.
+package lang
+
+import (
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// minHits is how many function words a text needs before Detect names its language; shorter texts
+// and code are left undetermined.
+const minHits = 3
+
+// stopwords are frequent words of each language. A word may belong to several; the language with
+// the most hits wins.
+var stopwords = map[string][]string{
+	"de": {"aber", "als", "am", "auch", "auf", "aus", "bei", "bin", "das", "dass", "dem", "den", "der",
+		"des", "die", "du", "ein", "eine", "einen", "er", "es", "für", "haben", "hat", "ich", "im", "ist",
+		"kann", "mit", "nach", "nicht", "noch", "nur", "oder", "sich", "sie", "sind", "und", "von", "war",
+		"was", "wenn", "wie", "wir", "wird", "zu", "zum", "zur", "über"},
+	"en": {"a", "about", "all", "also", "an", "and", "are", "as", "at", "be", "been", "but", "by", "can",
+		"do", "for", "from", "has", "have", "how", "i", "if", "in", "into", "is", "it", "its", "just",
+		"my", "not", "of", "on", "or", "our", "should", "so", "than", "that", "the", "their", "then",
+		"there", "these", "they", "this", "to", "was", "we", "were", "what", "when", "which", "who",
+		"will", "with", "would", "you"},
+	"es": {"al", "algo", "como", "con", "cuando", "de", "del", "desde", "donde", "el", "ella", "en",
+		"entre", "es", "esta", "este", "esto", "está", "están", "fue", "hay", "la", "las", "le", "lo",
+		"los", "me", "mi", "muy", "más", "no", "nos", "para", "pero", "por", "porque", "que", "qué",
+		"se", "ser", "sin", "sobre", "son", "su", "sus", "también", "tiene", "todo", "un", "una", "y",
+		"ya", "yo", "él"},
+	"fr": {"au", "aussi", "aux", "avec", "ce", "cette", "comme", "dans", "de", "des", "du", "elle",
+		"est", "et", "il", "ils", "je", "la", "le", "les", "leur", "mais", "ne", "nous", "on", "ou",
+		"par", "pas", "plus", "pour", "qui", "que", "sa", "se", "ses", "son", "sont", "sur", "tout",
+		"très", "un", "une", "vous", "été", "être"},
+	"it": {"anche", "che", "ci", "come", "con", "da", "del", "della", "delle", "di", "e", "gli", "ha",
+		"il", "in", "io", "la", "le", "lo", "ma", "mi", "molto", "nel", "nella", "non", "per",
+		"perché", "più", "questa", "questo", "se", "si", "sono", "sua", "suo", "un", "una", "è"},
+	"pt": {"ao", "as", "com", "como", "da", "das", "de", "do", "dos", "e", "ela", "ele", "em", "eu",
+		"foi", "isso", "já", "mais", "mas", "muito", "na", "no", "não", "o", "os", "ou", "para", "pela",
+		"pelo", "por", "que", "se", "ser", "seu", "sua", "são", "também", "tem", "um", "uma", "você",
+		"é"},
+}
+
+// languagesOf maps each stopword to the languages it belongs to.
+var languagesOf = func() map[string][]string {
+	m := make(map[string][]string)
+	for code, words := range stopwords {
+		for _, w := range words {
+			m[w] = append(m[w], code)
+		}
+	}
+	return m
+}()
+
+// Codes returns the ISO 639-1 codes of the languages Detect recognizes, sorted.
+func Codes() []string {
+	codes := make([]string, 0, len(stopwords))
+	for code := range stopwords {
+		codes = append(codes, code)
+	}
+	slices.Sort(codes)
+	return codes
+}
+
+// Detect returns the ISO 639-1 code of the language text is mostly written in, or "" when it has
+// too few words to tell or two languages tie. Fenced code blocks are ignored.
+func Detect(text string) string {
+	hits := make(map[string]int)
+	for _, w := range strings.FieldsFunc(strings.ToLower(prose(text)), func(r rune) bool { return !unicode.IsLetter(r) }) {
+		for _, code := range languagesOf[w] {
+			hits[code]++
+		}
+	}
+
+	best, bestHits, tie := "", 0, false
+	for _, code := range Codes() {
+		switch n := hits[code]; {
+		case n > bestHits:
+			best, bestHits, tie = code, n, false
+		case n == bestHits:
+			tie = true
+		}
+	}
+	if bestHits < minHits || tie {
+		return ""
+	}
+	return best
+}
+
+// prose returns text without its fenced code blocks.
+func prose(text string) string {
+	var b strings.Builder
+	var fence string
+	for _, line := range strings.Split(text, "\n") {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case fence != "":
+			if strings.HasPrefix(trimmed, fence) {
+				fence = ""
+			}
+		case strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~"):
+			fence = trimmed[:3]
+		default:
+			b.WriteString(line)
+			b.WriteByte('\n')
+		}
+	}
+	return b.String()
+}
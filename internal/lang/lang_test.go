@@ -0,0 +1,32 @@
+package lang
+
+import "testing"
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"Meeting notes: we should ship the release this week and then look at the backlog.", "en"},
+		{"Notas de la reunión: tenemos que revisar el presupuesto y hablar con el equipo de ventas.", "es"},
+		{"Il faut que nous parlions de la réunion avec le client, mais pas avant mardi.", "fr"},
+		{"Ich habe das Buch gelesen und es ist nicht so gut wie der Film.", "de"},
+		{"Eu não sei se ele vai para a reunião, mas você pode perguntar.", "pt"},
+		{"Questo è il libro che mi ha dato mia sorella, ma non l'ho ancora letto.", "it"},
+		{"TODO list", ""},
+		{"```go\nfunc main() { if the := a; is != nil { return it } }\n```\nok", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := Detect(tt.text); got != tt.want {
+			t.Errorf("Detect(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestCodes(t *testing.T) {
+	codes := Codes()
+	if len(codes) != 6 || codes[0] != "de" || codes[5] != "pt" {
+		t.Errorf("Codes() = %v", codes)
+	}
+}
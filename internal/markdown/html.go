@@ -0,0 +1,83 @@
+package markdown
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/parser"
+)
+
+// renderer converts GitHub-flavored Markdown. It leaves out raw HTML and drops javascript: and
+// similar link destinations, so its output is safe to show whatever a note contains.
+var renderer = goldmark.New(
+	goldmark.WithExtensions(extension.GFM),
+	goldmark.WithParserOptions(parser.WithAutoHeadingID()),
+)
+
+// HTML renders content to an HTML fragment. Each [[wikilink]] becomes a link to the URL href
+// returns for it, or its text when href reports false; ![[embeds]] of attachments are shown as
+// images the same way.
+func HTML(content string, href func(l Link) (string, bool)) (string, error) {
+	content = RewriteLinks(content, func(l Link) (string, bool) {
+		url, ok := href(l)
+		if !ok {
+			return escapeText(LinkText(l)), true
+		}
+		if l.Embed && IsAttachment(l.Target) {
+			return "![" + escapeText(l.Display) + "](<" + url + ">)", true
+		}
+		return "[" + escapeText(LinkText(l)) + "](<" + url + ">)", true
+	})
+	var b bytes.Buffer
+	if err := renderer.Convert([]byte(content), &b); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// HeadingID returns the id HTML gives a heading, for linking to it: ASCII letters and digits
+// lowercased, spaces, hyphens and underscores as hyphens, and everything else dropped. A later
+// heading with the same text gets -1, -2, ... appended.
+func HeadingID(heading string) string {
+	var b strings.Builder
+	for _, r := range strings.TrimSpace(heading) {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+			b.WriteRune(r)
+		case r >= 'A' && r <= 'Z':
+			b.WriteRune(r + 'a' - 'A')
+		case r == ' ', r == '\t', r == '-', r == '_':
+			b.WriteByte('-')
+		}
+	}
+	if b.Len() == 0 {
+		return "heading"
+	}
+	return b.String()
+}
+
+// PlainText returns content with each [[wikilink]] and ![[embed]] replaced by its text, for
+// readers outside noted.
+func PlainText(content string) string {
+	return RewriteLinks(content, func(l Link) (string, bool) {
+		return LinkText(l), true
+	})
+}
+
+// LinkText is what a link reads as: its display text, or else its target and section.
+func LinkText(l Link) string {
+	switch {
+	case l.Display != "":
+		return l.Display
+	case l.Section != "":
+		return l.Target + " › " + l.Section
+	}
+	return l.Target
+}
+
+// escapeText backslash-escapes the characters that would start Markdown syntax in link text.
+func escapeText(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `[`, `\[`, `]`, `\]`, `*`, `\*`, `_`, `\_`, "`", "\\`").Replace(s)
+}
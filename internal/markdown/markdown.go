@@ -0,0 +1,478 @@
+// Package markdown parses the parts of note content that noted gives meaning to: [[wikilinks]],
+// ![[embeds]] and the heading sections they can point at, links to attached files, task checkboxes
+// with their @due(YYYY-MM-DD) dates, and @person mentions. It also renders notes to HTML. It only
+// handles text, with no database or filesystem; callers resolve titles and paths themselves.
+package markdown
+
+import (
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// linkRe matches [[wikilinks]] and ![[embeds]].
+var linkRe = regexp.MustCompile(`(!?)\[\[([^\]]+)\]\]`)
+
+// fileLinkRe matches Markdown links and images: [text](dest) and ![alt](dest "title").
+var fileLinkRe = regexp.MustCompile(`(!?\[[^\]]*\]\()(<[^>]*>|[^)\s]+)((?:\s+"[^"]*")?\))`)
+
+// taskRe matches a task checkbox line: "- [ ] todo" or "- [x] done".
+var taskRe = regexp.MustCompile(`^\s*-\s*\[([ xX])\]\s*(.+)$`)
+
+// dueRe matches a due-date marker: "@due(2025-07-01)".
+var dueRe = regexp.MustCompile(`@due\((\d{4}-\d{2}-\d{2})\)`)
+
+// mentionRe matches an @mention: "@ana", "@jane.doe". The @ must not follow a word character, so
+// e-mail addresses are not mentions. A trailing "(" marks a marker like @due(...), not a name.
+var mentionRe = regexp.MustCompile(`(?:^|[^\p{L}\p{N}_@./])@([\p{L}\p{N}_](?:[\p{L}\p{N}_.-]*[\p{L}\p{N}_])?)(\(?)`)
+
+// maxEmbedDepth bounds how deeply Expand follows embeds inside embedded notes.
+const maxEmbedDepth = 5
+
+// Link is one [[Target#Section|Display]] reference in note content.
+type Link struct {
+	Text    string // everything between the brackets
+	Target  string // note title or alias
+	Section string // heading after '#', "" for the whole note
+	Display string // text after '|', "" if none
+	Embed   bool   // written as ![[...]]
+}
+
+// ParseLink splits the text between a link's brackets into its target, section, and display text.
+func ParseLink(text string) Link {
+	l := Link{Text: text}
+	target := text
+	if i := strings.Index(target, "|"); i >= 0 {
+		target, l.Display = target[:i], strings.TrimSpace(target[i+1:])
+	}
+	if i := strings.Index(target, "#"); i >= 0 {
+		target, l.Section = target[:i], strings.TrimSpace(target[i+1:])
+	}
+	l.Target = strings.TrimSpace(target)
+	return l
+}
+
+// ParseLinks returns every link and embed in content, in order. Links without a target (such as
+// [[#Section]]) are skipped.
+func ParseLinks(content string) []Link {
+	var out []Link
+	for _, m := range linkRe.FindAllStringSubmatch(content, -1) {
+		l := ParseLink(m[2])
+		if l.Target == "" {
+			continue
+		}
+		l.Embed = m[1] == "!"
+		out = append(out, l)
+	}
+	return out
+}
+
+// LinkTargets returns the distinct notes content links to, in order of first mention. Embed is set
+// when any reference to the target is an embed; Section and Display are dropped.
+func LinkTargets(content string) []Link {
+	var out []Link
+	index := map[string]int{}
+	for _, l := range ParseLinks(content) {
+		if i, ok := index[l.Target]; ok {
+			out[i].Embed = out[i].Embed || l.Embed
+			continue
+		}
+		index[l.Target] = len(out)
+		out = append(out, Link{Text: l.Target, Target: l.Target, Embed: l.Embed})
+	}
+	return out
+}
+
+// Heading is an ATX heading ("## Design") and the section it opens.
+type Heading struct {
+	Level int    `json:"level"` // 1-6
+	Text  string `json:"text"`  // heading text without the #s
+	Line  int    `json:"line"`  // 1-based line number of the heading
+	Start int    `json:"start"` // byte offset of the heading line
+	End   int    `json:"end"`   // byte offset where the section ends: the next heading of the same or higher level, or EOF
+}
+
+// OutlineNode is a heading with the headings of its section nested under it.
+type OutlineNode struct {
+	Heading
+	Children []OutlineNode `json:"children,omitempty"`
+}
+
+// Headings returns the headings in content in order. Lines inside fenced code blocks are ignored.
+func Headings(content string) []Heading {
+	var out []Heading
+	var fence string
+	offset := 0
+	for i, line := range strings.SplitAfter(content, "\n") {
+		start := offset
+		offset += len(line)
+		trimmed := strings.TrimSpace(line)
+		if fence != "" {
+			if strings.HasPrefix(trimmed, fence) {
+				fence = ""
+			}
+			continue
+		}
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			fence = trimmed[:3]
+			continue
+		}
+		if level, text, ok := parseHeading(line); ok {
+			out = append(out, Heading{Level: level, Text: text, Line: i + 1, Start: start})
+		}
+	}
+	for i := range out {
+		out[i].End = len(content)
+		for _, next := range out[i+1:] {
+			if next.Level <= out[i].Level {
+				out[i].End = next.Start
+				break
+			}
+		}
+	}
+	return out
+}
+
+// Outline returns the headings in content as a tree: each heading holds the deeper headings of its
+// section. A heading that skips levels (## straight to ####) still nests under the nearest one.
+func Outline(content string) []OutlineNode {
+	return outline(Headings(content))
+}
+
+func outline(hs []Heading) []OutlineNode {
+	var out []OutlineNode
+	for i := 0; i < len(hs); {
+		j := i + 1
+		for j < len(hs) && hs[j].Level > hs[i].Level {
+			j++
+		}
+		out = append(out, OutlineNode{Heading: hs[i], Children: outline(hs[i+1 : j])})
+		i = j
+	}
+	return out
+}
+
+// parseHeading reports whether line is an ATX heading, returning its level and text.
+func parseHeading(line string) (int, string, bool) {
+	line = strings.TrimRight(line, "\r\n")
+	if strings.HasPrefix(line, "    ") {
+		return 0, "", false // indented code
+	}
+	line = strings.TrimLeft(line, " ")
+	level := 0
+	for level < len(line) && line[level] == '#' {
+		level++
+	}
+	if level == 0 || level > 6 || (level < len(line) && line[level] != ' ' && line[level] != '\t') {
+		return 0, "", false
+	}
+	text := strings.TrimSpace(line[level:])
+	// A closing run of #s is not part of the text
+	if trimmed := strings.TrimRight(text, "#"); trimmed != text && (trimmed == "" || strings.HasSuffix(trimmed, " ")) {
+		text = strings.TrimSpace(trimmed)
+	}
+	return level, text, true
+}
+
+// Section returns the section of content under the heading named name (case-insensitive), heading
+// line included. The first match wins.
+func Section(content, name string) (string, bool) {
+	h, ok := findHeading(content, name)
+	if !ok {
+		return "", false
+	}
+	return content[h.Start:h.End], true
+}
+
+// ReplaceSection replaces the body of the section under the heading named name (everything after
+// the heading line, subsections included) with body, keeping the heading line. A blank line
+// separates the new body from the next section.
+func ReplaceSection(content, name, body string) (string, bool) {
+	h, ok := findHeading(content, name)
+	if !ok {
+		return content, false
+	}
+	bodyStart := h.End
+	if i := strings.IndexByte(content[h.Start:h.End], '\n'); i >= 0 {
+		bodyStart = h.Start + i + 1
+	}
+
+	var b strings.Builder
+	b.WriteString(content[:bodyStart])
+	if !strings.HasSuffix(content[:bodyStart], "\n") {
+		b.WriteString("\n")
+	}
+	if body = strings.Trim(body, "\n"); body != "" {
+		b.WriteString(body + "\n")
+	}
+	if h.End < len(content) {
+		b.WriteString("\n" + content[h.End:])
+	}
+	return b.String(), true
+}
+
+// AppendToSection adds text at the end of the section under the heading named name, after any
+// subsections and before the next heading of the same or higher level.
+func AppendToSection(content, name, text string) (string, bool) {
+	section, ok := Section(content, name)
+	if !ok {
+		return content, false
+	}
+	body := ""
+	if i := strings.IndexByte(section, '\n'); i >= 0 {
+		body = strings.TrimRight(section[i+1:], "\n")
+	}
+	if body != "" {
+		body += "\n"
+	}
+	return ReplaceSection(content, name, body+strings.Trim(text, "\n"))
+}
+
+// findHeading returns the first heading named name (case-insensitive).
+func findHeading(content, name string) (Heading, bool) {
+	name = strings.TrimSpace(name)
+	for _, h := range Headings(content) {
+		if strings.EqualFold(h.Text, name) {
+			return h, true
+		}
+	}
+	return Heading{}, false
+}
+
+// Expand replaces each ![[Note]] or ![[Note#Section]] embed in the content of the note titled
+// title with the embedded note's content, or just that section, looking notes up with resolve.
+// Embedded notes are expanded in turn, up to a fixed depth. Unresolved embeds, missing sections,
+// and embeds that would loop back to a note being expanded are left as written.
+func Expand(title, content string, resolve func(title string) (string, bool)) string {
+	return expand(content, resolve, []string{strings.ToLower(title)})
+}
+
+func expand(content string, resolve func(string) (string, bool), stack []string) string {
+	if len(stack) > maxEmbedDepth {
+		return content
+	}
+	return linkRe.ReplaceAllStringFunc(content, func(match string) string {
+		if match[0] != '!' {
+			return match
+		}
+		l := ParseLink(match[3 : len(match)-2])
+		if l.Target == "" {
+			return match
+		}
+		key := strings.ToLower(l.Target)
+		for _, s := range stack {
+			if s == key {
+				return match
+			}
+		}
+		body, ok := resolve(l.Target)
+		if !ok {
+			return match
+		}
+		if l.Section != "" {
+			if body, ok = Section(body, l.Section); !ok {
+				return match
+			}
+		}
+		return strings.TrimRight(expand(body, resolve, append(stack, key)), "\n")
+	})
+}
+
+// RewriteLinks replaces every [[link]] and ![[embed]] in content, attachment embeds included, with
+// what rewrite returns for it, leaving it as written when rewrite reports false. Links without a
+// target (such as [[#Section]]) are left as written.
+func RewriteLinks(content string, rewrite func(l Link) (string, bool)) string {
+	return linkRe.ReplaceAllStringFunc(content, func(match string) string {
+		link := strings.TrimPrefix(match, "!")
+		l := ParseLink(link[2 : len(link)-2])
+		if l.Target == "" {
+			return match
+		}
+		l.Embed = len(link) < len(match)
+		next, ok := rewrite(l)
+		if !ok {
+			return match
+		}
+		return next
+	})
+}
+
+// Attachments returns the distinct local files content links to, in order of first mention:
+// images and links ("![alt](img/diagram.png)", "[spec](spec.pdf)") and Obsidian-style file embeds
+// ("![[diagram.png]]"). URLs, anchors, and links to other Markdown notes are not attachments.
+// Paths are returned as written, with percent-escapes decoded.
+func Attachments(content string) []string {
+	var out []string
+	seen := map[string]bool{}
+	RewriteAttachments(content, func(p string) (string, bool) {
+		if !seen[p] {
+			seen[p] = true
+			out = append(out, p)
+		}
+		return "", false
+	})
+	return out
+}
+
+// RewriteAttachments replaces the path of every attachment link in content (see Attachments) with
+// what rewrite returns for it, leaving the link as written when rewrite reports false.
+func RewriteAttachments(content string, rewrite func(path string) (string, bool)) string {
+	content = fileLinkRe.ReplaceAllStringFunc(content, func(match string) string {
+		m := fileLinkRe.FindStringSubmatch(match)
+		dest := strings.TrimSuffix(strings.TrimPrefix(m[2], "<"), ">")
+		if unescaped, err := url.PathUnescape(dest); err == nil {
+			dest = unescaped
+		}
+		if !IsAttachment(dest) {
+			return match
+		}
+		next, ok := rewrite(dest)
+		if !ok {
+			return match
+		}
+		if strings.ContainsAny(next, " ()") {
+			next = "<" + next + ">"
+		}
+		return m[1] + next + m[3]
+	})
+	return linkRe.ReplaceAllStringFunc(content, func(match string) string {
+		if match[0] != '!' {
+			return match
+		}
+		l := ParseLink(match[3 : len(match)-2])
+		if !IsAttachment(l.Target) {
+			return match
+		}
+		next, ok := rewrite(l.Target)
+		if !ok {
+			return match
+		}
+		if l.Display != "" {
+			next += "|" + l.Display
+		}
+		return "![[" + next + "]]"
+	})
+}
+
+// IsAttachment reports whether a link destination names a local non-Markdown file.
+func IsAttachment(dest string) bool {
+	if dest == "" || strings.HasPrefix(dest, "#") || strings.HasPrefix(dest, "//") {
+		return false
+	}
+	if u, err := url.Parse(dest); err == nil && len(u.Scheme) > 1 { // "C:" is a drive, not a scheme
+		return false
+	}
+	if i := strings.IndexAny(dest, "?#"); i >= 0 {
+		dest = dest[:i]
+	}
+	ext := strings.ToLower(path.Ext(strings.ReplaceAll(dest, "\\", "/")))
+	return ext != "" && ext != ".md" && ext != ".markdown"
+}
+
+// Task is a checkbox item ("- [ ] call Ana", "- [x] ship it") in note content.
+type Task struct {
+	Index int    `json:"index"` // 1-based position among the content's tasks
+	Line  int    `json:"line"`  // 1-based line number
+	Text  string `json:"text"`
+	Done  bool   `json:"done"`
+	Due   string `json:"due,omitempty"` // YYYY-MM-DD from an @due(...) marker in the text
+}
+
+// Tasks returns the tasks in content, in order.
+func Tasks(content string) []Task {
+	var out []Task
+	for i, line := range strings.Split(content, "\n") {
+		m := taskRe.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		text := strings.TrimSpace(m[2])
+		out = append(out, Task{Index: len(out) + 1, Line: i + 1, Text: text, Done: m[1] != " ", Due: Due(text)})
+	}
+	return out
+}
+
+// Due returns the date of the first valid @due(YYYY-MM-DD) marker in text, or "" if there is none.
+func Due(text string) string {
+	for _, m := range dueRe.FindAllStringSubmatch(text, -1) {
+		if _, err := time.Parse(time.DateOnly, m[1]); err == nil {
+			return m[1]
+		}
+	}
+	return ""
+}
+
+// StripDue returns text without its @due markers.
+func StripDue(text string) string {
+	return strings.Join(strings.Fields(dueRe.ReplaceAllString(text, "")), " ")
+}
+
+// NoteDue returns the due date of the note as a whole: the first @due marker in content that is not
+// on a task line, since those belong to their task. It returns "" if there is none.
+func NoteDue(content string) string {
+	for _, line := range strings.Split(content, "\n") {
+		if taskRe.MatchString(line) {
+			continue
+		}
+		if due := Due(line); due != "" {
+			return due
+		}
+	}
+	return ""
+}
+
+// Mentions returns the distinct @mentions in content, lowercased, in order of first mention. Lines
+// inside fenced code blocks are ignored.
+func Mentions(content string) []string {
+	var out []string
+	seen := map[string]bool{}
+	var fence string
+	for _, line := range strings.Split(content, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if fence != "" {
+			if strings.HasPrefix(trimmed, fence) {
+				fence = ""
+			}
+			continue
+		}
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			fence = trimmed[:3]
+			continue
+		}
+		for _, m := range mentionRe.FindAllStringSubmatch(line, -1) {
+			name := strings.ToLower(m[1])
+			if m[2] != "" || seen[name] {
+				continue
+			}
+			seen[name] = true
+			out = append(out, name)
+		}
+	}
+	return out
+}
+
+// SetTaskDone checks (or, with done false, unchecks) the nth task in content, counting from 1 as
+// Tasks does. It returns the new content and the task as it is now; ok is false when content has
+// fewer than n tasks. A checked task keeps the "x" or "X" it was written with.
+func SetTaskDone(content string, n int, done bool) (string, Task, bool) {
+	lines := strings.Split(content, "\n")
+	for _, t := range Tasks(content) {
+		if t.Index != n {
+			continue
+		}
+		if t.Done != done {
+			line := lines[t.Line-1]
+			loc := taskRe.FindStringSubmatchIndex(line)
+			mark := " "
+			if done {
+				mark = "x"
+			}
+			lines[t.Line-1] = line[:loc[2]] + mark + line[loc[3]:]
+			t.Done = done
+		}
+		return strings.Join(lines, "\n"), t, true
+	}
+	return content, Task{}, false
+}
@@ -0,0 +1,251 @@
+package markdown
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestParseLinks(t *testing.T) {
+	got := ParseLinks("see [[Alpha]], ![[Beta#Design|the design]], [[#Local]] and [[ Gamma | g ]]")
+	want := []Link{
+		{Text: "Alpha", Target: "Alpha"},
+		{Text: "Beta#Design|the design", Target: "Beta", Section: "Design", Display: "the design", Embed: true},
+		{Text: " Gamma | g ", Target: "Gamma", Display: "g"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("ParseLinks = %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("link[%d] = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestLinkTargets(t *testing.T) {
+	got := LinkTargets("[[A]] ![[A#Intro]] [[B|b]] [[B]]")
+	if len(got) != 2 || got[0].Target != "A" || !got[0].Embed || got[1].Target != "B" || got[1].Embed {
+		t.Errorf("LinkTargets = %+v", got)
+	}
+}
+
+func TestRewriteLinks(t *testing.T) {
+	got := RewriteLinks("[[A]], ![[B#Intro|b]], [[#Local]] and [[C]]", func(l Link) (string, bool) {
+		if l.Target == "C" {
+			return "", false
+		}
+		return fmt.Sprintf("<%s|%s|%s|%v>", l.Target, l.Section, l.Display, l.Embed), true
+	})
+	if want := "<A|||false>, <B|Intro|b|true>, [[#Local]] and [[C]]"; got != want {
+		t.Errorf("RewriteLinks = %q, want %q", got, want)
+	}
+}
+
+func TestHTML(t *testing.T) {
+	content := "# Plan\n\nSee [[Design#Goals]], [[Old]] and ![[chart.png]].\n\n<script>alert(1)</script>\n\n- [x] done\n"
+	got, err := HTML(content, func(l Link) (string, bool) {
+		switch l.Target {
+		case "Design":
+			return "design.html#" + l.Section, true
+		case "chart.png":
+			return "assets/chart.png", true
+		}
+		return "", false
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`<h1 id="plan">Plan</h1>`,
+		`<a href="design.html#Goals">Design › Goals</a>`,
+		` Old and <img src="assets/chart.png" alt="">`,
+		`<input checked="" disabled="" type="checkbox"> done`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("HTML missing %q:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "<script>") {
+		t.Errorf("raw HTML should be left out:\n%s", got)
+	}
+
+	if got := PlainText("Ask [[Ana|her]] about ![[Roadmap#Q3]]"); got != "Ask her about Roadmap › Q3" {
+		t.Errorf("PlainText = %q", got)
+	}
+}
+
+func TestHeadingsAndSection(t *testing.T) {
+	content := "# Doc\nintro\n## Design\nplan\n```\n# not a heading\n```\n### Detail\nmore\n## Notes ##\nend\n"
+	hs := Headings(content)
+	var texts []string
+	for _, h := range hs {
+		texts = append(texts, h.Text)
+	}
+	if len(hs) != 4 || texts[1] != "Design" || texts[3] != "Notes" || hs[2].Level != 3 {
+		t.Fatalf("Headings = %+v", hs)
+	}
+	if hs[0].End != len(content) {
+		t.Errorf("top-level section should run to EOF, ends at %d", hs[0].End)
+	}
+
+	got, ok := Section(content, "design")
+	if want := "## Design\nplan\n```\n# not a heading\n```\n### Detail\nmore\n"; !ok || got != want {
+		t.Errorf("Section = %q, %v; want %q", got, ok, want)
+	}
+	if _, ok := Section(content, "Missing"); ok {
+		t.Error("expected no section for a missing heading")
+	}
+	if _, _, ok := parseHeading("#hashtag"); ok {
+		t.Error("#hashtag is not a heading")
+	}
+}
+
+func TestReplaceAndAppendSection(t *testing.T) {
+	content := "# Doc\nintro\n## Goals\n- ship\n\n## Risks\nnone\n"
+
+	got, ok := ReplaceSection(content, "goals", "- ship v2\n- test it\n")
+	if want := "# Doc\nintro\n## Goals\n- ship v2\n- test it\n\n## Risks\nnone\n"; !ok || got != want {
+		t.Errorf("ReplaceSection = %q, want %q", got, want)
+	}
+	got, _ = ReplaceSection(content, "Risks", "")
+	if want := "# Doc\nintro\n## Goals\n- ship\n\n## Risks\n"; got != want {
+		t.Errorf("clearing the last section = %q, want %q", got, want)
+	}
+	got, _ = ReplaceSection("# Title", "Title", "body")
+	if got != "# Title\nbody\n" {
+		t.Errorf("heading without a newline = %q", got)
+	}
+
+	got, ok = AppendToSection(content, "Goals", "- write docs")
+	if want := "# Doc\nintro\n## Goals\n- ship\n- write docs\n\n## Risks\nnone\n"; !ok || got != want {
+		t.Errorf("AppendToSection = %q, want %q", got, want)
+	}
+	if _, ok := AppendToSection(content, "Missing", "x"); ok {
+		t.Error("expected no match for a missing section")
+	}
+}
+
+func TestAttachments(t *testing.T) {
+	content := "![diagram](img/diagram.png) and [spec](<docs/the spec.pdf> \"Spec\")\n" +
+		"![[photo.jpg|300]] [[Other note]] ![[Note#Part]] [site](https://example.com/a.png)\n" +
+		"[note](other.md) [top](#intro) ![again](img/diagram.png) ![esc](my%20pic.png)"
+
+	got := Attachments(content)
+	want := []string{"img/diagram.png", "docs/the spec.pdf", "my pic.png", "photo.jpg"}
+	if len(got) != len(want) {
+		t.Fatalf("Attachments = %q, want %q", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("attachment[%d] = %q, want %q", i, got[i], want[i])
+		}
+	}
+
+	rewritten := RewriteAttachments(content, func(p string) (string, bool) {
+		if p == "my pic.png" {
+			return "", false
+		}
+		return "assets/" + p[strings.LastIndex(p, "/")+1:], true
+	})
+	for _, s := range []string{"![diagram](assets/diagram.png)", "[spec](<assets/the spec.pdf> \"Spec\")", "![[assets/photo.jpg|300]]", "![esc](my%20pic.png)", "[site](https://example.com/a.png)", "![[Note#Part]]"} {
+		if !strings.Contains(rewritten, s) {
+			t.Errorf("rewritten content is missing %q:\n%s", s, rewritten)
+		}
+	}
+}
+
+func TestTasks(t *testing.T) {
+	content := "# Plan\n- [ ] write spec\n  - [X] review\n- not a task\n-[x] ship it\n"
+	got := Tasks(content)
+	if len(got) != 3 || got[0].Text != "write spec" || got[0].Done || !got[1].Done || got[2].Index != 3 || got[2].Line != 5 {
+		t.Fatalf("Tasks = %+v", got)
+	}
+
+	checked, task, ok := SetTaskDone(content, 1, true)
+	if want := strings.Replace(content, "- [ ] write spec", "- [x] write spec", 1); !ok || checked != want || !task.Done {
+		t.Errorf("SetTaskDone = %q, %+v, %v", checked, task, ok)
+	}
+	unchecked, _, _ := SetTaskDone(content, 2, false)
+	if !strings.Contains(unchecked, "  - [ ] review\n") {
+		t.Errorf("unchecking = %q", unchecked)
+	}
+	if same, _, _ := SetTaskDone(content, 2, true); same != content {
+		t.Error("checking a checked task should leave content as is")
+	}
+	if _, _, ok := SetTaskDone(content, 4, true); ok {
+		t.Error("expected no fourth task")
+	}
+}
+
+func TestDue(t *testing.T) {
+	content := "Review @due(2025-07-01)\n- [ ] send draft @due(2025-06-20)\n- [ ] bad date @due(2025-13-01)\n- [ ] no date\n"
+	tasks := Tasks(content)
+	if len(tasks) != 3 || tasks[0].Due != "2025-06-20" || tasks[1].Due != "" || tasks[2].Due != "" {
+		t.Errorf("task due dates = %+v", tasks)
+	}
+	if got := NoteDue(content); got != "2025-07-01" {
+		t.Errorf("NoteDue = %q", got)
+	}
+	if got := NoteDue("- [ ] only a task @due(2025-06-20)"); got != "" {
+		t.Errorf("a task's due date is not the note's, got %q", got)
+	}
+	if got := StripDue("send @due(2025-06-20) the draft"); got != "send the draft" {
+		t.Errorf("StripDue = %q", got)
+	}
+}
+
+func TestMentions(t *testing.T) {
+	content := "1:1 with @Ana and @jane.doe.\nMail ana@example.com, ship @due(2025-07-01), ask @ana again\n```\n@not_me\n```\n(@bob)"
+	want := []string{"ana", "jane.doe", "bob"}
+	if got := Mentions(content); !slices.Equal(got, want) {
+		t.Errorf("Mentions = %q, want %q", got, want)
+	}
+}
+
+func TestOutline(t *testing.T) {
+	content := "intro\n# Doc\n## Design\n#### Deep\n### Detail\n## Notes\n# Appendix\n"
+	got := Outline(content)
+	if len(got) != 2 || got[0].Text != "Doc" || got[1].Text != "Appendix" {
+		t.Fatalf("Outline roots = %+v", got)
+	}
+	doc := got[0]
+	if doc.Line != 2 || len(doc.Children) != 2 || doc.Children[0].Text != "Design" || doc.Children[1].Text != "Notes" {
+		t.Fatalf("Doc children = %+v", doc.Children)
+	}
+	design := doc.Children[0]
+	if len(design.Children) != 2 || design.Children[0].Text != "Deep" || design.Children[1].Text != "Detail" {
+		t.Errorf("Design children = %+v", design.Children)
+	}
+	if design.End != doc.Children[1].Start {
+		t.Errorf("Design should end where Notes starts: %d != %d", design.End, doc.Children[1].Start)
+	}
+	if Outline("no headings here") != nil {
+		t.Error("expected no outline for content without headings")
+	}
+}
+
+func TestExpand(t *testing.T) {
+	notes := map[string]string{
+		"Intro": "Hello from intro.\n",
+		"Spec":  "# Spec\n## Goals\nship it\n## Risks\n![[Intro]]\n",
+		"Loop":  "before ![[Loop2]] after",
+		"Loop2": "inner ![[Loop]]",
+	}
+	resolve := func(title string) (string, bool) {
+		c, ok := notes[title]
+		return c, ok
+	}
+
+	got := Expand("Page", "a ![[Intro]] b\n![[Spec#goals]]\n![[Spec#Risks]]\n[[Intro]] ![[Missing]] ![[Spec#Nope]]", resolve)
+	want := "a Hello from intro. b\n## Goals\nship it\n## Risks\nHello from intro.\n[[Intro]] ![[Missing]] ![[Spec#Nope]]"
+	if got != want {
+		t.Errorf("Expand =\n%q\nwant\n%q", got, want)
+	}
+
+	// A loop is cut where it comes back to a note already being expanded
+	if got := Expand("Loop", notes["Loop"], resolve); got != "before inner ![[Loop]] after" {
+		t.Errorf("Expand loop = %q", got)
+	}
+}
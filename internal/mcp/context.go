@@ -0,0 +1,178 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/abdul-hamid-achik/noted/internal/db"
+	"github.com/abdul-hamid-achik/noted/internal/memory"
+	"github.com/abdul-hamid-achik/noted/internal/veclite"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const (
+	defaultContextBudget = 2000 // tokens
+	contextMemories      = 10   // memories considered for a context bundle
+	contextRecent        = 10   // recently updated notes considered for a context bundle
+	minTruncatedChars    = 200  // the shortest cut-down item worth including
+	charsPerToken        = 4    // rough size of a token in English text, for budgeting
+)
+
+// contextBundle builds a markdown document section by section until its character budget runs
+// out, cutting down the item that reaches the budget and counting the ones left out after it.
+type contextBundle struct {
+	b       strings.Builder
+	left    int // characters
+	section string
+	items   int
+	omitted int
+}
+
+// add appends an item under heading, writing the heading before the section's first item.
+func (c *contextBundle) add(heading, item string) {
+	if c.left <= 0 {
+		c.omitted++
+		return
+	}
+	if heading != c.section {
+		item = "\n## " + heading + "\n\n" + item
+	}
+	if len(item) > c.left {
+		if c.left < minTruncatedChars {
+			c.left = 0
+			c.omitted++
+			return
+		}
+		item = strings.TrimRight(truncateUTF8(item, c.left-len("…\n")), " \n") + "…\n"
+	}
+	c.section = heading
+	c.b.WriteString(item)
+	c.left -= len(item)
+	c.items++
+}
+
+// truncateUTF8 cuts s to at most n bytes without splitting a character.
+func truncateUTF8(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !isUTF8Start(s[n]) {
+		n--
+	}
+	return s[:n]
+}
+
+func isUTF8Start(b byte) bool {
+	return b&0xC0 != 0x80
+}
+
+func (s *Server) toolContext(ctx context.Context, input contextInput) (*mcp.CallToolResult, any, error) {
+	budget := input.Budget
+	if budget <= 0 {
+		budget = defaultContextBudget
+	}
+	topic := strings.TrimSpace(input.Topic)
+
+	pinned, err := s.queries.GetPinnedNotes(ctx)
+	if err != nil {
+		return errorResult(fmt.Sprintf("failed to get pinned notes: %v", err))
+	}
+
+	var memories []memory.Memory
+	if topic == "" {
+		memories, err = memory.Top(ctx, s.queries, contextMemories)
+	} else {
+		var syncer *veclite.Syncer
+		if vs, ok := s.syncer.(*veclite.Syncer); ok {
+			syncer = vs
+		}
+		var result *memory.RecallResult
+		result, err = memory.Recall(ctx, s.queries, s.conn, syncer, memory.RecallInput{
+			Query:       topic,
+			Limit:       contextMemories,
+			UseSemantic: syncer != nil,
+		})
+		if result != nil {
+			memories = result.Memories
+			memory.SortByImportance(memories)
+		}
+	}
+	if err != nil {
+		return errorResult(fmt.Sprintf("failed to get memories: %v", err))
+	}
+
+	// Recent notes leave out memories, which have their own section
+	var candidates []db.Note
+	if topic == "" {
+		candidates, err = s.queries.ListRecentlyUpdatedNotes(ctx, contextRecent*2)
+	} else {
+		var results []db.SearchResult
+		results, err = db.SearchNotes(ctx, s.conn, topic, contextRecent*2)
+		for _, r := range results {
+			candidates = append(candidates, r.Note)
+		}
+	}
+	if err != nil {
+		return errorResult(fmt.Sprintf("failed to get recent notes: %v", err))
+	}
+	seen := make(map[int64]bool)
+	for _, n := range pinned {
+		seen[n.ID] = true
+	}
+	for _, m := range memories {
+		seen[m.ID] = true
+	}
+	var recent []db.Note
+	for _, n := range candidates {
+		if len(recent) < contextRecent && !seen[n.ID] && !memory.IsMemory(ctx, s.queries, n.ID) {
+			recent = append(recent, n)
+		}
+	}
+	if topic != "" {
+		slices.SortStableFunc(recent, func(a, b db.Note) int { return b.UpdatedAt.Time.Compare(a.UpdatedAt.Time) })
+	}
+
+	c := &contextBundle{left: budget * charsPerToken}
+	title := "# Context"
+	if topic != "" {
+		title += ": " + topic
+	}
+	c.b.WriteString(title + "\n")
+	c.left -= len(title) + 1
+
+	for _, n := range pinned {
+		c.add("Pinned notes", fmt.Sprintf("### %s (#%d)\n\n%s\n\n", n.Title, n.ID, strings.TrimSpace(n.Content)))
+	}
+	for _, m := range memories {
+		item := fmt.Sprintf("- **%s** (memory #%d", m.Title, m.ID)
+		if m.Category != "" {
+			item += fmt.Sprintf(", %s, importance %d", m.Category, m.Importance)
+		}
+		item += ")\n"
+		if content := strings.TrimSpace(m.Content); content != "" && content != m.Title {
+			for _, line := range strings.Split(content, "\n") {
+				item += strings.TrimRight("  "+line, " ") + "\n"
+			}
+		}
+		c.add("Memories", item)
+	}
+	for _, n := range recent {
+		c.add("Recently updated", fmt.Sprintf("### %s (#%d, updated %s)\n\n%s\n\n",
+			n.Title, n.ID, n.UpdatedAt.Time.Format("2006-01-02"), strings.TrimSpace(n.Content)))
+	}
+
+	if c.items == 0 {
+		c.b.WriteString("\nNothing pinned, remembered, or recently updated")
+		if topic != "" {
+			c.b.WriteString(" for this topic")
+		}
+		c.b.WriteString(".\n")
+	}
+	if c.omitted > 0 {
+		fmt.Fprintf(&c.b, "\n_%d more items left out to fit the %d-token budget._\n", c.omitted, budget)
+	}
+
+	return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: c.b.String()}}}, nil, nil
+}
@@ -0,0 +1,90 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/abdul-hamid-achik/noted/internal/quota"
+)
+
+// Limits caps how much agents can change through MCP tools, so a runaway agent loop cannot flood
+// or empty the database. Zero disables a limit.
+type Limits struct {
+	CreatesPerMinute int // notes and memories created per rolling minute
+	MaxForgetPerCall int // memories a single noted_forget call may delete
+}
+
+// rateWindow counts events in a rolling time window.
+type rateWindow struct {
+	mu     sync.Mutex
+	events []time.Time
+}
+
+// allow records an event at now unless limit events already happened within window; when it
+// refuses, it returns how long until the oldest event leaves the window.
+func (w *rateWindow) allow(now time.Time, limit int, window time.Duration) (bool, time.Duration) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	cutoff := now.Add(-window)
+	kept := w.events[:0]
+	for _, t := range w.events {
+		if t.After(cutoff) {
+			kept = append(kept, t)
+		}
+	}
+	w.events = kept
+
+	if len(w.events) >= limit {
+		return false, w.events[0].Sub(cutoff)
+	}
+	w.events = append(w.events, now)
+	return true, 0
+}
+
+// WithLimits enables the mutation limits. Returns the server for chaining.
+func (s *Server) WithLimits(l Limits) *Server {
+	s.limits = l
+	return s
+}
+
+// allowCreate enforces Limits.CreatesPerMinute before a tool creates a note or memory.
+func (s *Server) allowCreate() error {
+	limit := s.limits.CreatesPerMinute
+	if limit <= 0 {
+		return nil
+	}
+	if ok, wait := s.creates.allow(time.Now(), limit, time.Minute); !ok {
+		return fmt.Errorf("rate limit reached: at most %d notes per minute can be created through MCP; retry in %ds (NOTED_MCP_MAX_CREATES_PER_MINUTE)",
+			limit, int(wait.Seconds())+1)
+	}
+	return nil
+}
+
+// WithQuotas checks note content the tools write against q: past a warning level the tool result
+// carries a warning, at a hard limit the write is refused. Returns the server for chaining.
+func (s *Server) WithQuotas(q quota.Quotas) *Server {
+	s.quotas = q
+	return s
+}
+
+// checkQuota checks content a tool is about to write. A new note is also checked against the
+// database and attachment limits; updates are not, so an agent can still trim an over-quota vault.
+func (s *Server) checkQuota(ctx context.Context, content string, isNew bool) ([]string, error) {
+	var dbSize, attachments func() int64
+	if isNew {
+		dbSize = func() int64 {
+			var pages, size int64
+			_ = s.conn.QueryRowContext(ctx, "PRAGMA page_count").Scan(&pages)
+			_ = s.conn.QueryRowContext(ctx, "PRAGMA page_size").Scan(&size)
+			return pages * size
+		}
+		if s.vlt != nil {
+			attachments = func() int64 { return quota.DirSize(filepath.Join(s.vlt.Dir(), "assets")) }
+		}
+	}
+	return s.quotas.CheckNote(content, dbSize, attachments)
+}
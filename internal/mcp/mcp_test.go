@@ -9,12 +9,17 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"fmt"
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/abdul-hamid-achik/noted/internal/db"
+	"github.com/abdul-hamid-achik/noted/internal/embeddings"
 	"github.com/abdul-hamid-achik/noted/internal/notesync"
+	"github.com/abdul-hamid-achik/noted/internal/quota"
+	"github.com/abdul-hamid-achik/noted/internal/sanitize"
 	"github.com/abdul-hamid-achik/noted/internal/vault"
 	"github.com/abdul-hamid-achik/noted/internal/veclite"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
@@ -23,16 +28,22 @@ import (
 // mockSyncer implements Syncer interface for testing without Ollama
 type mockSyncer struct {
 	notes    map[int64]string // note_id -> content
+	memories map[int64]string // note_id -> content, synced via SyncMemory
 	searches []veclite.SemanticResult
+	err      error // returned by Search and SyncNote when set
 }
 
 func newMockSyncer() *mockSyncer {
 	return &mockSyncer{
-		notes: make(map[int64]string),
+		notes:    make(map[int64]string),
+		memories: make(map[int64]string),
 	}
 }
 
 func (m *mockSyncer) Search(query string, limit int) ([]veclite.SemanticResult, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
 	// Return pre-configured results or empty
 	if len(m.searches) > limit {
 		return m.searches[:limit], nil
@@ -41,12 +52,24 @@ func (m *mockSyncer) Search(query string, limit int) ([]veclite.SemanticResult,
 }
 
 func (m *mockSyncer) SyncNote(id int64, title, content string) error {
+	if m.err != nil {
+		return m.err
+	}
 	m.notes[id] = title + "\n\n" + content
 	return nil
 }
 
+func (m *mockSyncer) SyncMemory(id int64, title, content string) error {
+	if m.err != nil {
+		return m.err
+	}
+	m.memories[id] = title + "\n\n" + content
+	return nil
+}
+
 func (m *mockSyncer) Delete(noteID int64) error {
 	delete(m.notes, noteID)
+	delete(m.memories, noteID)
 	return nil
 }
 
@@ -218,6 +241,49 @@ func TestToolCreate_MissingContent(t *testing.T) {
 	}
 }
 
+func TestToolCreate_FolderAndPin(t *testing.T) {
+	queries, conn, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	server := NewServer(queries, conn, nil)
+	ctx := context.Background()
+	work, _ := queries.CreateFolder(ctx, db.CreateFolderParams{Name: "Work"})
+
+	result, _, _ := server.toolCreate(ctx, createInput{Title: "Runbook", Content: "x", FolderID: work.ID, Pinned: true})
+	if result.IsError {
+		t.Fatalf("unexpected error: %s", getResultText(result))
+	}
+	data := parseResultJSON(t, result)
+	note, _ := queries.GetNote(ctx, int64(data["id"].(float64)))
+	if note.FolderID.Int64 != work.ID || !note.Pinned.Bool {
+		t.Errorf("note = %+v, want pinned in folder #%d", note, work.ID)
+	}
+
+	// A folder path is created as needed, and reuses folders that exist
+	result, _, _ = server.toolCreate(ctx, createInput{Title: "Plan", Content: "x", Folder: "Work/noted"})
+	if result.IsError {
+		t.Fatalf("unexpected error: %s", getResultText(result))
+	}
+	data = parseResultJSON(t, result)
+	note, _ = queries.GetNote(ctx, int64(data["id"].(float64)))
+	folder, _ := queries.GetFolder(ctx, note.FolderID.Int64)
+	if folder.Name != "noted" || folder.ParentID.Int64 != work.ID || note.Pinned.Bool {
+		t.Errorf("note = %+v in folder %+v", note, folder)
+	}
+
+	for _, input := range []createInput{
+		{Title: "Missing", Content: "x", FolderID: 999},
+		{Title: "Both", Content: "x", FolderID: work.ID, Folder: "Work"},
+	} {
+		if result, _, _ := server.toolCreate(ctx, input); !result.IsError {
+			t.Errorf("%s: expected an error", input.Title)
+		}
+	}
+	if n, _ := queries.CountNotes(ctx); n != 2 {
+		t.Errorf("failed creates left notes behind: %d notes", n)
+	}
+}
+
 func TestToolCreate_WithSyncer(t *testing.T) {
 	queries, conn, cleanup := setupTestDB(t)
 	defer cleanup()
@@ -305,6 +371,123 @@ func TestToolList_WithTagFilter(t *testing.T) {
 	}
 }
 
+func TestToolList_Sort(t *testing.T) {
+	queries, conn, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	createTestNote(t, queries, "Banana", "Content", []string{"fruit"})
+	createTestNote(t, queries, "apple", "Content", []string{"fruit"})
+	createTestNote(t, queries, "Cherry", "Content", []string{"fruit"})
+
+	server := NewServer(queries, conn, nil)
+	ctx := context.Background()
+	for _, input := range []listInput{{Sort: "title"}, {Sort: "title", Tag: "fruit"}} {
+		result, _, _ := server.toolList(ctx, input)
+		data := parseResultJSON(t, result)
+		var titles []string
+		for _, n := range data["notes"].([]any) {
+			titles = append(titles, n.(map[string]any)["title"].(string))
+		}
+		if strings.Join(titles, ",") != "apple,Banana,Cherry" {
+			t.Errorf("%+v: titles = %v", input, titles)
+		}
+	}
+
+	result, _, _ := server.toolList(ctx, listInput{Sort: "size"})
+	if !result.IsError {
+		t.Error("expected an error for an unknown sort")
+	}
+}
+
+func TestToolList_ModifiedWindow(t *testing.T) {
+	queries, conn, cleanup := setupTestDB(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	for _, n := range []struct{ title, age string }{{"Stale", "-10 days"}, {"Fresh", "-1 hours"}} {
+		id := createTestNote(t, queries, n.title, "Content", []string{"go"})
+		if _, err := conn.ExecContext(ctx, "UPDATE notes SET updated_at = datetime('now', ?) WHERE id = ?", n.age, id); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	server := NewServer(queries, conn, nil)
+	for _, tc := range []struct {
+		input listInput
+		want  string
+	}{
+		{listInput{ModifiedSince: "2d"}, "Fresh"},
+		{listInput{ModifiedSince: "2d", Tag: "go"}, "Fresh"},
+		{listInput{ModifiedUntil: "7d"}, "Stale"},
+		{listInput{ModifiedUntil: "7d", Tag: "go"}, "Stale"},
+	} {
+		result, _, _ := server.toolList(ctx, tc.input)
+		data := parseResultJSON(t, result)
+		notes := data["notes"].([]any)
+		if len(notes) != 1 || notes[0].(map[string]any)["title"] != tc.want || data["total"].(float64) != 1 {
+			t.Errorf("%+v: %v, want only %s", tc.input, data, tc.want)
+		}
+	}
+
+	result, _, _ := server.toolList(ctx, listInput{ModifiedSince: "last week"})
+	if !result.IsError {
+		t.Error("expected an error for an invalid modified_since")
+	}
+}
+
+func TestToolList_WithFolderFilter(t *testing.T) {
+	queries, conn, cleanup := setupTestDB(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	work, _ := queries.CreateFolder(ctx, db.CreateFolderParams{Name: "Work"})
+	projects, _ := queries.CreateFolder(ctx, db.CreateFolderParams{Name: "Projects", ParentID: sql.NullInt64{Int64: work.ID, Valid: true}})
+	for _, n := range []struct {
+		folder int64
+		tags   []string
+	}{{work.ID, []string{"go"}}, {projects.ID, []string{"go"}}, {projects.ID, nil}, {0, []string{"go"}}} {
+		id := createTestNote(t, queries, "Note", "Content", n.tags)
+		if n.folder != 0 {
+			_ = queries.MoveNoteToFolder(ctx, db.MoveNoteToFolderParams{FolderID: sql.NullInt64{Int64: n.folder, Valid: true}, ID: id})
+		}
+	}
+
+	server := NewServer(queries, conn, nil)
+	for _, tc := range []struct {
+		input listInput
+		want  float64
+	}{
+		{listInput{FolderID: work.ID}, 1},
+		{listInput{FolderID: work.ID, IncludeSubfolders: true}, 3},
+		{listInput{FolderID: work.ID, IncludeSubfolders: true, Tag: "go"}, 2},
+		{listInput{FolderID: projects.ID, IncludeSubfolders: true, Limit: 1}, 1},
+	} {
+		result, _, _ := server.toolList(ctx, tc.input)
+		data := parseResultJSON(t, result)
+		if data["count"] != tc.want {
+			t.Errorf("%+v: count = %v, want %v", tc.input, data["count"], tc.want)
+		}
+	}
+}
+
+func TestToolList_WithLangFilter(t *testing.T) {
+	queries, conn, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	server := NewServer(queries, conn, nil)
+	ctx := context.Background()
+
+	_, _, _ = server.toolCreate(ctx, createInput{Title: "Standup", Content: "We need to finish the migration and then review the docs."})
+	_, _, _ = server.toolCreate(ctx, createInput{Title: "Pendientes", Content: "Tenemos que terminar la migración y revisar la documentación."})
+
+	result, _, _ := server.toolList(ctx, listInput{Lang: "es"})
+	data := parseResultJSON(t, result)
+	notes := data["notes"].([]any)
+	if len(notes) != 1 || notes[0].(map[string]any)["lang"] != "es" || data["total"].(float64) != 1 {
+		t.Errorf("lang es list = %v", data)
+	}
+}
+
 func TestToolList_Pagination(t *testing.T) {
 	queries, conn, cleanup := setupTestDB(t)
 	defer cleanup()
@@ -331,6 +514,33 @@ func TestToolList_Pagination(t *testing.T) {
 	if int(data["count"].(float64)) != 2 {
 		t.Errorf("expected 2 notes on second page, got %v", data["count"])
 	}
+	if data["total"] != float64(5) || data["has_more"] != true || data["next_offset"] != float64(4) || data["prev_offset"] != float64(0) {
+		t.Errorf("unexpected page info: %v", data)
+	}
+
+	// Last page
+	result, _, _ = server.toolList(ctx, listInput{Limit: 2, Offset: 4})
+	data = parseResultJSON(t, result)
+	if data["count"] != float64(1) || data["has_more"] != false || data["next_offset"] != nil {
+		t.Errorf("unexpected last page: %v", data)
+	}
+}
+
+func TestToolList_TagPagination(t *testing.T) {
+	queries, conn, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	for i := 1; i <= 3; i++ {
+		createTestNote(t, queries, "Go Note", "Content", []string{"go"})
+	}
+	createTestNote(t, queries, "Python Note", "Content", []string{"python"})
+
+	server := NewServer(queries, conn, nil)
+	result, _, _ := server.toolList(context.Background(), listInput{Tag: "go", Limit: 2, Offset: 2})
+	data := parseResultJSON(t, result)
+	if data["count"] != float64(1) || data["total"] != float64(3) || data["has_more"] != false {
+		t.Errorf("unexpected tag page: %v", data)
+	}
 }
 
 // ============================================================================
@@ -402,6 +612,39 @@ func TestToolSearch_Success(t *testing.T) {
 	}
 }
 
+func TestToolSearch_Snippets(t *testing.T) {
+	queries, conn, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	createTestNote(t, queries, "Release", "Checklist\n\nTag the release, then deploy to staging.", nil)
+
+	server := NewServer(queries, conn, nil)
+	ctx := context.Background()
+
+	result, _, _ := server.toolSearch(ctx, searchInput{Query: "deploy"})
+	note := parseResultJSON(t, result)["notes"].([]any)[0].(map[string]any)
+	if _, ok := note["content"]; ok {
+		t.Errorf("search results should leave the content out, got %v", note["content"])
+	}
+	snippets, _ := note["snippets"].([]any)
+	if len(snippets) != 1 {
+		t.Fatalf("snippets = %v", note["snippets"])
+	}
+	snippet := snippets[0].(map[string]any)
+	if snippet["html"] != "Checklist  Tag the release, then <mark>deploy</mark> to staging." {
+		t.Errorf("snippet html = %v", snippet["html"])
+	}
+	if m := snippet["matches"].([]any)[0].(map[string]any); m["start"] != float64(33) || m["end"] != float64(39) {
+		t.Errorf("match = %v", m)
+	}
+
+	result, _, _ = server.toolSearch(ctx, searchInput{Query: "deploy", FullContent: true})
+	note = parseResultJSON(t, result)["notes"].([]any)[0].(map[string]any)
+	if !strings.HasPrefix(note["content"].(string), "Checklist") {
+		t.Errorf("full_content should include the content, got %v", note["content"])
+	}
+}
+
 func TestToolSearch_EmptyQuery(t *testing.T) {
 	queries, conn, cleanup := setupTestDB(t)
 	defer cleanup()
@@ -433,6 +676,49 @@ func TestToolSearch_NoResults(t *testing.T) {
 	}
 }
 
+func TestToolSearch_MatchesTagNames(t *testing.T) {
+	queries, conn, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	id := createTestNote(t, queries, "Concurrency", "channels and select", []string{"golang"})
+	createTestNote(t, queries, "Python", "list comprehensions", nil)
+
+	server := NewServer(queries, conn, nil)
+	result, _, _ := server.toolSearch(context.Background(), searchInput{Query: "golang"})
+
+	data := parseResultJSON(t, result)
+	if int(data["count"].(float64)) != 1 {
+		t.Fatalf("expected 1 tag-only match, got %v", data["count"])
+	}
+	note := data["notes"].([]any)[0].(map[string]any)
+	if int64(note["id"].(float64)) != id {
+		t.Errorf("expected note #%d, got %v", id, note["id"])
+	}
+	if tags, _ := note["matched_tags"].([]any); len(tags) != 1 || tags[0] != "golang" {
+		t.Errorf("expected matched_tags [golang], got %v", note["matched_tags"])
+	}
+}
+
+func TestToolSearch_RecordsHistory(t *testing.T) {
+	queries, conn, cleanup := setupTestDB(t)
+	defer cleanup()
+	createTestNote(t, queries, "Go", "gofmt", nil)
+	ctx := context.Background()
+
+	// Off by default
+	_, _, _ = NewServer(queries, conn, nil).toolSearch(ctx, searchInput{Query: "gofmt"})
+	if rows, _ := queries.ListSearchHistory(ctx, db.ListSearchHistoryParams{Limit: 10}); len(rows) != 0 {
+		t.Fatalf("expected no history without WithSearchHistory, got %d", len(rows))
+	}
+
+	server := NewServer(queries, conn, nil).WithSearchHistory(true)
+	_, _, _ = server.toolSearch(ctx, searchInput{Query: "gofmt"})
+	rows, _ := queries.ListSearchHistory(ctx, db.ListSearchHistoryParams{Limit: 10})
+	if len(rows) != 1 || rows[0].Interface != "mcp" || rows[0].Query != "gofmt" {
+		t.Errorf("expected one mcp search for gofmt, got %+v", rows)
+	}
+}
+
 // ============================================================================
 // Tool: noted_update Tests
 // ============================================================================
@@ -716,6 +1002,144 @@ func TestToolUpdate_PartialUpdate(t *testing.T) {
 	}
 }
 
+func TestToolCreate_Quota(t *testing.T) {
+	queries, conn, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	server := NewServer(queries, conn, nil).WithQuotas(quota.Quotas{NoteSize: quota.Limit{Warn: 10, Max: 40}})
+	ctx := context.Background()
+
+	result, _, _ := server.toolCreate(ctx, createInput{Title: "Log", Content: strings.Repeat("x", 50)})
+	if !result.IsError || !strings.Contains(getResultText(result), "quota exceeded") {
+		t.Fatalf("oversized note should be refused, got %s", getResultText(result))
+	}
+	if n, _ := queries.CountNotes(ctx); n != 0 {
+		t.Errorf("refused note was created")
+	}
+
+	result, _, _ = server.toolCreate(ctx, createInput{Title: "Log", Content: strings.Repeat("x", 20)})
+	data := parseResultJSON(t, result)
+	if warnings, _ := data["warnings"].([]any); len(warnings) != 1 {
+		t.Errorf("expected a size warning, got %v", data)
+	}
+
+	id := int64(data["id"].(float64))
+	result, _, _ = server.toolUpdate(ctx, updateInput{ID: id, Content: strings.Repeat("y", 41)})
+	if !result.IsError {
+		t.Error("update past the note size limit should be refused")
+	}
+
+	// Section edits and daily appends are held to the same limit
+	_, _, _ = server.toolUpdate(ctx, updateInput{ID: id, Content: "## Log\nok"})
+	result, _, _ = server.toolUpdateSection(ctx, updateSectionInput{ID: id, Section: "Log", Content: strings.Repeat("z", 50), Append: true})
+	if !result.IsError || !strings.Contains(getResultText(result), "quota exceeded") {
+		t.Errorf("section edit past the note size limit should be refused, got %s", getResultText(result))
+	}
+	if note, _ := queries.GetNote(ctx, id); note.Content != "## Log\nok" {
+		t.Errorf("refused section edit was saved: %q", note.Content)
+	}
+	result, _, _ = server.toolDaily(ctx, dailyInput{Date: "2026-01-02", Append: strings.Repeat("z", 50)})
+	if !result.IsError {
+		t.Error("daily append past the note size limit should be refused")
+	}
+}
+
+func TestToolCreate_Sanitize(t *testing.T) {
+	queries, conn, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	server := NewServer(queries, conn, nil).WithSanitize(sanitize.Policy{Enabled: true, MaxBlankLines: 1})
+	ctx := context.Background()
+
+	result, _, _ := server.toolCreate(ctx, createInput{Title: "Build\x1b[0m\nlog", Content: "ok\r\n\x00\n\n\n\ndone"})
+	data := parseResultJSON(t, result)
+	note, _ := queries.GetNote(ctx, int64(data["id"].(float64)))
+	if note.Title != "Build[0m log" || note.Content != "ok\n\ndone" {
+		t.Errorf("title %q, content %q", note.Title, note.Content)
+	}
+
+	_, _, _ = server.toolUpdate(ctx, updateInput{ID: note.ID, Content: "new\r\nbody"})
+	if note, _ = queries.GetNote(ctx, note.ID); note.Content != "new\nbody" {
+		t.Errorf("updated content %q", note.Content)
+	}
+
+	result, _, _ = server.toolDaily(ctx, dailyInput{Date: "2026-01-02", Append: "a\x00\r\nb", Prepend: "top\x07"})
+	daily, _ := queries.GetNote(ctx, int64(parseResultJSON(t, result)["id"].(float64)))
+	if daily.Content != "top\na\nb" {
+		t.Errorf("daily content %q", daily.Content)
+	}
+
+	_, _, _ = server.toolTemplateCreate(ctx, templateCreateInput{Name: "report", Content: "# {{title}}\r\n{{body}}"})
+	result, _, _ = server.toolTemplateApply(ctx, templateApplyInput{TemplateName: "report", Title: "Weekly\nreport", Vars: map[string]string{"body": "x\x1b\n\n\n\ny"}})
+	applied, _ := queries.GetNote(ctx, int64(parseResultJSON(t, result)["id"].(float64)))
+	if applied.Title != "Weekly report" || applied.Content != "# Weekly report\nx\n\ny" {
+		t.Errorf("template note title %q, content %q", applied.Title, applied.Content)
+	}
+}
+
+func TestToolUpdate_FolderAndPin(t *testing.T) {
+	queries, conn, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	noteID := createTestNote(t, queries, "Title", "Content", []string{"keep"})
+	server := NewServer(queries, conn, nil)
+	ctx := context.Background()
+	folder, err := queries.CreateFolder(ctx, db.CreateFolderParams{Name: "Work"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pinned := true
+	result, _, _ := server.toolUpdate(ctx, updateInput{ID: noteID, Folder: &folder.ID, Pinned: &pinned})
+	if result.IsError {
+		t.Fatalf("unexpected error: %s", getResultText(result))
+	}
+	note, _ := queries.GetNote(ctx, noteID)
+	if note.FolderID.Int64 != folder.ID || !note.Pinned.Bool || note.Title != "Title" || note.Content != "Content" {
+		t.Errorf("note = %+v", note)
+	}
+	if tags, _ := queries.GetTagsForNote(ctx, noteID); len(tags) != 1 {
+		t.Errorf("tags should be untouched, got %v", tags)
+	}
+
+	none, unpinned := int64(0), false
+	_, _, _ = server.toolUpdate(ctx, updateInput{ID: noteID, Folder: &none, Pinned: &unpinned})
+	if note, _ := queries.GetNote(ctx, noteID); note.FolderID.Valid || note.Pinned.Bool {
+		t.Errorf("expected no folder and unpinned, got %+v", note)
+	}
+}
+
+func TestToolUpdate_Metadata(t *testing.T) {
+	queries, conn, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	noteID := createTestNote(t, queries, "Title", "Content", nil)
+	server := NewServer(queries, conn, nil)
+	ctx := context.Background()
+
+	source, ref, ttl := "code-review", "pr-42", "24h"
+	result, _, _ := server.toolUpdate(ctx, updateInput{ID: noteID, Source: &source, SourceRef: &ref, TTL: &ttl})
+	if result.IsError {
+		t.Fatalf("unexpected error: %s", getResultText(result))
+	}
+	result, _, _ = server.toolGet(ctx, getInput{ID: noteID})
+	data := parseResultJSON(t, result)
+	if data["source"] != "code-review" || data["source_ref"] != "pr-42" || data["expires_at"] == nil {
+		t.Errorf("noted_get = %v", data)
+	}
+
+	empty := ""
+	_, _, _ = server.toolUpdate(ctx, updateInput{ID: noteID, SourceRef: &empty, TTL: &empty})
+	if note, _ := queries.GetNote(ctx, noteID); note.Source.String != "code-review" || note.SourceRef.Valid || note.ExpiresAt.Valid {
+		t.Errorf("expected source_ref and expiry cleared, got %+v", note)
+	}
+
+	bad := "soon"
+	if result, _, _ := server.toolUpdate(ctx, updateInput{ID: noteID, TTL: &bad}); !result.IsError {
+		t.Error("expected an error for an invalid ttl")
+	}
+}
+
 func TestToolUpdate_NotFound(t *testing.T) {
 	queries, conn, cleanup := setupTestDB(t)
 	defer cleanup()
@@ -769,6 +1193,40 @@ func TestToolUpdate_Tags(t *testing.T) {
 	}
 }
 
+func TestToolUpdate_Aliases(t *testing.T) {
+	queries, conn, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	noteID := createTestNote(t, queries, "Go", "Content", nil)
+
+	server := NewServer(queries, conn, nil)
+	ctx := context.Background()
+
+	result, _, _ := server.toolUpdate(ctx, updateInput{ID: noteID, Aliases: []string{"Golang"}})
+	if result.IsError {
+		t.Fatalf("unexpected error")
+	}
+	if n, err := queries.ResolveNoteTitle(ctx, "Golang"); err != nil || n.ID != noteID {
+		t.Errorf("alias resolved to %d (err %v), want %d", n.ID, err, noteID)
+	}
+
+	result, _, _ = server.toolGet(ctx, getInput{ID: noteID})
+	data := parseResultJSON(t, result)
+	if aliases, _ := data["aliases"].([]any); len(aliases) != 1 || aliases[0] != "Golang" {
+		t.Errorf("expected aliases [Golang], got %v", data["aliases"])
+	}
+
+	// Leaving aliases out keeps them; an empty list clears them
+	_, _, _ = server.toolUpdate(ctx, updateInput{ID: noteID, Content: "New"})
+	if got, _ := queries.GetNoteAliases(ctx, noteID); len(got) != 1 {
+		t.Errorf("expected aliases to be kept, got %v", got)
+	}
+	_, _, _ = server.toolUpdate(ctx, updateInput{ID: noteID, Aliases: []string{}})
+	if got, _ := queries.GetNoteAliases(ctx, noteID); len(got) != 0 {
+		t.Errorf("expected aliases to be cleared, got %v", got)
+	}
+}
+
 // ============================================================================
 // Tool: noted_delete Tests
 // ============================================================================
@@ -795,49 +1253,187 @@ func TestToolDelete_Success(t *testing.T) {
 	}
 }
 
-func TestToolDelete_NotFound(t *testing.T) {
+func TestToolTrashListAndRestore(t *testing.T) {
 	queries, conn, cleanup := setupTestDB(t)
 	defer cleanup()
 
+	noteID := createTestNote(t, queries, "Oops", "Content", []string{"keep"})
+
 	server := NewServer(queries, conn, nil)
 	ctx := context.Background()
 
-	result, _, _ := server.toolDelete(ctx, deleteInput{ID: 999})
+	result, _, _ := server.toolDelete(ctx, deleteInput{ID: noteID})
+	if data := parseResultJSON(t, result); data["status"] != "trashed" {
+		t.Errorf("expected status trashed, got %v", data["status"])
+	}
+
+	result, _, _ = server.toolTrashList(ctx)
+	data := parseResultJSON(t, result)
+	if data["count"] != float64(1) {
+		t.Errorf("expected 1 trashed note, got %v", data["count"])
+	}
+
+	result, _, _ = server.toolTrashRestore(ctx, trashRestoreInput{ID: noteID})
+	if result.IsError {
+		t.Fatalf("unexpected error restoring")
+	}
+	if _, err := queries.GetNote(ctx, noteID); err != nil {
+		t.Errorf("restored note should be visible, got %v", err)
+	}
+	if tags, _ := queries.GetTagsForNote(ctx, noteID); len(tags) != 1 {
+		t.Errorf("restored note lost its tags: %v", tags)
+	}
 
+	result, _, _ = server.toolTrashRestore(ctx, trashRestoreInput{ID: noteID})
 	if !result.IsError {
-		t.Error("expected error for non-existent note")
+		t.Error("expected an error restoring a note that is not in the trash")
 	}
 }
 
-func TestToolDelete_WithSyncer(t *testing.T) {
+func TestToolArchive(t *testing.T) {
 	queries, conn, cleanup := setupTestDB(t)
 	defer cleanup()
 
-	syncer := newMockSyncer()
-	syncer.notes[1] = "content"
-
-	noteID := createTestNote(t, queries, "To Delete", "Content", nil)
+	noteID := createTestNote(t, queries, "Old roadmap", "roadmap details", nil)
+	createTestNote(t, queries, "New roadmap", "roadmap details", nil)
 
-	server := NewServer(queries, conn, syncer)
+	server := NewServer(queries, conn, nil)
 	ctx := context.Background()
 
-	result, _, _ := server.toolDelete(ctx, deleteInput{ID: noteID})
+	result, _, _ := server.toolArchive(ctx, archiveInput{ID: noteID})
+	if data := parseResultJSON(t, result); data["status"] != "archived" {
+		t.Fatalf("expected status archived, got %v", data["status"])
+	}
 
-	if result.IsError {
-		t.Errorf("unexpected error")
+	result, _, _ = server.toolList(ctx, listInput{})
+	if data := parseResultJSON(t, result); data["count"] != float64(1) {
+		t.Errorf("noted_list should hide the archived note, got %v", data["count"])
+	}
+	result, _, _ = server.toolList(ctx, listInput{Archived: true})
+	if data := parseResultJSON(t, result); data["count"] != float64(2) {
+		t.Errorf("noted_list archived=true should include it, got %v", data["count"])
+	}
+	result, _, _ = server.toolSearch(ctx, searchInput{Query: "roadmap"})
+	if data := parseResultJSON(t, result); data["count"] != float64(1) {
+		t.Errorf("noted_search should hide the archived note, got %v", data["count"])
+	}
+	result, _, _ = server.toolSearch(ctx, searchInput{Query: "roadmap", Archived: true})
+	if data := parseResultJSON(t, result); data["count"] != float64(2) {
+		t.Errorf("noted_search archived=true should include it, got %v", data["count"])
 	}
 
-	// Verify syncer was notified
-	if _, ok := syncer.notes[noteID]; ok {
-		t.Error("note should be removed from syncer")
+	result, _, _ = server.toolArchive(ctx, archiveInput{ID: noteID, Unarchive: true})
+	if data := parseResultJSON(t, result); data["status"] != "unarchived" {
+		t.Errorf("expected status unarchived, got %v", data["status"])
+	}
+	result, _, _ = server.toolArchive(ctx, archiveInput{ID: 99999})
+	if !result.IsError {
+		t.Error("expected an error archiving a missing note")
 	}
 }
 
-// ============================================================================
-// Tool: noted_tags Tests
-// ============================================================================
-
-func TestToolTags_Empty(t *testing.T) {
+func TestToolOutline(t *testing.T) {
+	queries, conn, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	noteID := createTestNote(t, queries, "Spec", "# Spec\n## Goals\nship\n## Risks\nnone\n", nil)
+	server := NewServer(queries, conn, nil)
+
+	result, _, _ := server.toolOutline(context.Background(), outlineInput{ID: noteID})
+	data := parseResultJSON(t, result)
+	outline, ok := data["outline"].([]any)
+	if !ok || len(outline) != 1 {
+		t.Fatalf("expected one top-level heading, got %v", data["outline"])
+	}
+	root := outline[0].(map[string]any)
+	if root["text"] != "Spec" || len(root["children"].([]any)) != 2 {
+		t.Errorf("unexpected outline root: %v", root)
+	}
+
+	result, _, _ = server.toolOutline(context.Background(), outlineInput{ID: 99999})
+	if !result.IsError {
+		t.Error("expected an error for a missing note")
+	}
+}
+
+func TestToolSections(t *testing.T) {
+	queries, conn, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	noteID := createTestNote(t, queries, "Design", "# Design\n## Goals\n- ship\n\n## Risks\nnone\n", nil)
+	server := NewServer(queries, conn, nil)
+	ctx := context.Background()
+
+	result, _, _ := server.toolGetSection(ctx, getSectionInput{ID: noteID, Section: "goals"})
+	if data := parseResultJSON(t, result); data["content"] != "## Goals\n- ship\n\n" {
+		t.Errorf("unexpected section content: %q", data["content"])
+	}
+
+	result, _, _ = server.toolUpdateSection(ctx, updateSectionInput{ID: noteID, Section: "Goals", Content: "- test", Append: true})
+	if result.IsError {
+		t.Fatalf("append failed: %v", result.Content)
+	}
+	result, _, _ = server.toolUpdateSection(ctx, updateSectionInput{ID: noteID, Section: "Risks", Content: "schedule"})
+	if result.IsError {
+		t.Fatalf("replace failed: %v", result.Content)
+	}
+	note, _ := queries.GetNote(ctx, noteID)
+	if want := "# Design\n## Goals\n- ship\n- test\n\n## Risks\nschedule\n"; note.Content != want {
+		t.Errorf("content = %q, want %q", note.Content, want)
+	}
+	if versions, _ := queries.GetNoteVersions(ctx, noteID); len(versions) != 2 {
+		t.Errorf("expected a version per section edit, got %d", len(versions))
+	}
+
+	result, _, _ = server.toolUpdateSection(ctx, updateSectionInput{ID: noteID, Section: "Nope", Content: "x"})
+	if !result.IsError {
+		t.Error("expected an error for a missing section")
+	}
+}
+
+func TestToolDelete_NotFound(t *testing.T) {
+	queries, conn, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	server := NewServer(queries, conn, nil)
+	ctx := context.Background()
+
+	result, _, _ := server.toolDelete(ctx, deleteInput{ID: 999})
+
+	if !result.IsError {
+		t.Error("expected error for non-existent note")
+	}
+}
+
+func TestToolDelete_WithSyncer(t *testing.T) {
+	queries, conn, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	syncer := newMockSyncer()
+	syncer.notes[1] = "content"
+
+	noteID := createTestNote(t, queries, "To Delete", "Content", nil)
+
+	server := NewServer(queries, conn, syncer)
+	ctx := context.Background()
+
+	result, _, _ := server.toolDelete(ctx, deleteInput{ID: noteID})
+
+	if result.IsError {
+		t.Errorf("unexpected error")
+	}
+
+	// Verify syncer was notified
+	if _, ok := syncer.notes[noteID]; ok {
+		t.Error("note should be removed from syncer")
+	}
+}
+
+// ============================================================================
+// Tool: noted_tags Tests
+// ============================================================================
+
+func TestToolTags_Empty(t *testing.T) {
 	queries, conn, cleanup := setupTestDB(t)
 	defer cleanup()
 
@@ -881,6 +1477,83 @@ func TestToolTags_WithCounts(t *testing.T) {
 	}
 }
 
+func TestToolTagRename_MergesAndWritesThrough(t *testing.T) {
+	queries, conn, cleanup := setupTestDB(t)
+	defer cleanup()
+	vlt, err := vault.Open(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := NewServer(queries, conn, nil).WithVault(vlt)
+	ctx := context.Background()
+
+	id := createTestNote(t, queries, "Note", "Content", []string{"golang"})
+	createTestNote(t, queries, "Other", "Content", []string{"go"})
+
+	result, _, _ := server.toolTagRename(ctx, tagRenameInput{Name: "golang", NewName: "go"})
+	if result.IsError {
+		t.Fatalf("rename failed: %s", getResultText(result))
+	}
+	data := parseResultJSON(t, result)
+	if data["merged"] != true || int(data["note_count"].(float64)) != 1 {
+		t.Errorf("unexpected result: %v", data)
+	}
+	vn, ok := vaultNote(t, vlt, id)
+	if !ok || !hasStr(vn.Tags, "go") || hasStr(vn.Tags, "golang") {
+		t.Errorf("vault tags = %v, want go only", vn.Tags)
+	}
+
+	if r, _, _ := server.toolTagRename(ctx, tagRenameInput{Name: "missing", NewName: "x"}); !r.IsError {
+		t.Error("expected error for unknown tag")
+	}
+}
+
+func TestToolTagDelete(t *testing.T) {
+	queries, conn, cleanup := setupTestDB(t)
+	defer cleanup()
+	server := NewServer(queries, conn, nil)
+	ctx := context.Background()
+
+	id := createTestNote(t, queries, "Note", "Content", []string{"draft", "keep"})
+
+	result, _, _ := server.toolTagDelete(ctx, tagDeleteInput{Name: "draft"})
+	if result.IsError {
+		t.Fatalf("delete failed: %s", getResultText(result))
+	}
+	tags, _ := queries.GetTagsForNote(ctx, id)
+	if len(tags) != 1 || tags[0].Name != "keep" {
+		t.Errorf("expected only 'keep' left, got %v", tags)
+	}
+	if _, err := queries.GetNote(ctx, id); err != nil {
+		t.Errorf("note should survive tag deletion: %v", err)
+	}
+}
+
+func TestToolTagCleanup(t *testing.T) {
+	queries, conn, cleanup := setupTestDB(t)
+	defer cleanup()
+	server := NewServer(queries, conn, nil)
+	ctx := context.Background()
+
+	createTestNote(t, queries, "Note", "Content", []string{"used"})
+	_, _ = queries.CreateTag(ctx, "orphan")
+
+	result, _, _ := server.toolTagCleanup(ctx, tagCleanupInput{DryRun: true})
+	data := parseResultJSON(t, result)
+	if int(data["would_delete"].(float64)) != 1 {
+		t.Errorf("dry run: expected 1 unused tag, got %v", data)
+	}
+	if _, err := queries.GetTagByName(ctx, "orphan"); err != nil {
+		t.Error("dry run must not delete")
+	}
+
+	result, _, _ = server.toolTagCleanup(ctx, tagCleanupInput{})
+	data = parseResultJSON(t, result)
+	if int(data["deleted"].(float64)) != 1 {
+		t.Errorf("expected 1 deleted, got %v", data)
+	}
+}
+
 // ============================================================================
 // Tool: noted_remember Tests
 // ============================================================================
@@ -1019,6 +1692,118 @@ func TestToolRecall_EmptyQuery(t *testing.T) {
 	}
 }
 
+func TestToolRecallBatch(t *testing.T) {
+	queries, conn, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	server := NewServer(queries, conn, nil)
+	ctx := context.Background()
+
+	_, _, _ = server.toolRemember(ctx, rememberInput{Title: "Deploy steps", Content: "deploy with make release after the database migration"})
+	_, _, _ = server.toolRemember(ctx, rememberInput{Title: "Migration rules", Content: "every database migration needs a down script"})
+
+	result, _, _ := server.toolRecallBatch(ctx, recallBatchInput{Queries: []string{"deploy", "migration", "", "unrelated"}})
+	data := parseResultJSON(t, result)
+	groups := data["results"].([]any)
+	if len(groups) != 3 || int(data["count"].(float64)) != 2 {
+		t.Fatalf("expected 3 groups with 2 memories in all, got %v", data)
+	}
+
+	deploy := groups[0].(map[string]any)
+	if deploy["query"] != "deploy" || len(deploy["memories"].([]any)) != 1 {
+		t.Errorf("deploy group = %v", deploy)
+	}
+	// The deploy memory also mentions the migration, but is only returned once
+	migration := groups[1].(map[string]any)
+	if len(migration["memories"].([]any)) != 1 || len(migration["also_matched"].([]any)) != 1 {
+		t.Errorf("migration group = %v", migration)
+	}
+	if len(groups[2].(map[string]any)["memories"].([]any)) != 0 {
+		t.Errorf("unrelated group = %v", groups[2])
+	}
+
+	if result, _, _ := server.toolRecallBatch(ctx, recallBatchInput{}); !result.IsError {
+		t.Error("expected error for no queries")
+	}
+}
+
+func TestToolContext(t *testing.T) {
+	queries, conn, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	server := NewServer(queries, conn, nil)
+	ctx := context.Background()
+	contextText := func(result *mcp.CallToolResult, _ any, _ error) string { return getResultText(result) }
+
+	if text := contextText(server.toolContext(ctx, contextInput{})); !strings.Contains(text, "Nothing pinned") {
+		t.Errorf("empty context = %q", text)
+	}
+
+	pinned := createTestNote(t, queries, "Conventions", "Use tabs. Wrap errors with %w.", nil)
+	_ = queries.PinNote(ctx, pinned)
+	createTestNote(t, queries, "Release plan", "Ship the importer on Friday.", nil)
+	_, _, _ = server.toolRemember(ctx, rememberInput{Title: "Prefers short commits", Content: "Prefers short commits", Category: "user-pref", Importance: 5})
+	_, _, _ = server.toolRemember(ctx, rememberInput{Title: "Minor fact", Content: "The importer lives in cmd/import.go", Importance: 1})
+
+	text := contextText(server.toolContext(ctx, contextInput{}))
+	for _, want := range []string{"## Pinned notes", "### Conventions", "## Memories", "## Recently updated", "### Release plan"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("context missing %q:\n%s", want, text)
+		}
+	}
+	if strings.Index(text, "Prefers short commits") > strings.Index(text, "Minor fact") {
+		t.Errorf("memories should be ordered by importance:\n%s", text)
+	}
+	// Memories are not repeated as recent notes
+	if strings.Count(text, "Minor fact") != 1 {
+		t.Errorf("memory listed more than once:\n%s", text)
+	}
+
+	text = contextText(server.toolContext(ctx, contextInput{Topic: "importer"}))
+	if !strings.Contains(text, "Release plan") || !strings.Contains(text, "Minor fact") || strings.Contains(text, "Prefers short commits") {
+		t.Errorf("topic context:\n%s", text)
+	}
+
+	// A small budget cuts the bundle short and says so
+	createTestNote(t, queries, "Long", strings.Repeat("word ", 2000), nil)
+	text = contextText(server.toolContext(ctx, contextInput{Budget: 100}))
+	if len(text) > 100*charsPerToken+100 || !strings.Contains(text, "left out to fit the 100-token budget") {
+		t.Errorf("budgeted context (%d bytes):\n%s", len(text), text)
+	}
+}
+
+func TestToolSessionEnd(t *testing.T) {
+	queries, conn, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	server := NewServer(queries, conn, nil)
+	ctx := context.Background()
+
+	result, _, _ := server.toolCreate(ctx, createInput{Title: "Design", Content: "draft"})
+	created := int64(parseResultJSON(t, result)["id"].(float64))
+	other := createTestNote(t, queries, "Other", "untouched", nil)
+
+	result, _, _ = server.toolSessionEnd(ctx, sessionEndInput{Summary: "Drafted the design", NoteIDs: []int64{other}})
+	data := parseResultJSON(t, result)
+	if touched := data["notes_touched"].([]any); len(touched) != 1 || int64(touched[0].(float64)) != created {
+		t.Errorf("notes_touched = %v, want [%d]", data["notes_touched"], created)
+	}
+	note, _ := queries.GetNote(ctx, int64(data["id"].(float64)))
+	if !strings.Contains(note.Content, "[[Design]]") || !strings.Contains(note.Content, "[[Other]]") || note.Source.String != "session" {
+		t.Errorf("session log = %q (source %q)", note.Content, note.Source.String)
+	}
+
+	// The next session starts empty
+	result, _, _ = server.toolSessionEnd(ctx, sessionEndInput{Summary: "Nothing else", Category: "decision"})
+	if touched := parseResultJSON(t, result)["notes_touched"].([]any); len(touched) != 0 {
+		t.Errorf("second session touched %v, want none", touched)
+	}
+
+	if result, _, _ := server.toolSessionEnd(ctx, sessionEndInput{Summary: "x", Category: "fact"}); !result.IsError {
+		t.Error("expected error for category fact")
+	}
+}
+
 // ============================================================================
 // Tool: noted_forget Tests
 // ============================================================================
@@ -1133,6 +1918,25 @@ func TestToolSync_WithSyncer(t *testing.T) {
 	}
 }
 
+func TestToolSync_Status(t *testing.T) {
+	queries, conn, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	createTestNote(t, queries, "Synced", "Content", nil)
+	createTestNote(t, queries, "Unsynced", "Content", nil)
+	_ = queries.MarkEmbeddingSynced(context.Background(), 1)
+
+	// Status works without a syncer: it only counts
+	result, _, _ := NewServer(queries, conn, nil).toolSync(context.Background(), syncInput{Status: true})
+	if result.IsError {
+		t.Fatalf("unexpected error: %s", getResultText(result))
+	}
+	data := parseResultJSON(t, result)
+	if data["pending"] != float64(1) || data["notes"] != float64(2) || data["available"] != false {
+		t.Errorf("unexpected status: %v", data)
+	}
+}
+
 // ============================================================================
 // Tool: noted_semantic_search Tests
 // ============================================================================
@@ -1257,6 +2061,36 @@ func TestToolDaily_CreateNew(t *testing.T) {
 	}
 }
 
+func TestToolDaily_Template(t *testing.T) {
+	queries, conn, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	server := NewServer(queries, conn, nil)
+	ctx := context.Background()
+
+	_, _ = queries.CreateTemplate(ctx, db.CreateTemplateParams{Name: "daily", Content: "# {{date}}\n## Log\n"})
+	_, _ = queries.CreateTemplate(ctx, db.CreateTemplateParams{Name: "journal", Content: "Dear diary, {{title}}"})
+
+	result, _, _ := server.toolDaily(ctx, dailyInput{Date: "2026-02-17"})
+	if data := parseResultJSON(t, result); data["content"] != "# 2026-02-17\n## Log\n" {
+		t.Errorf("expected the daily template for the note's date, got %q", data["content"])
+	}
+	result, _, _ = server.toolDaily(ctx, dailyInput{Date: "2026-02-18", Template: "journal"})
+	if data := parseResultJSON(t, result); data["content"] != "Dear diary, 2026-02-18" {
+		t.Errorf("expected the journal template, got %q", data["content"])
+	}
+	result, _, _ = server.toolDaily(ctx, dailyInput{Date: "2026-02-19", Template: "missing"})
+	if !result.IsError {
+		t.Error("expected error for a missing template")
+	}
+
+	// Without a daily template, new daily notes start empty
+	result, _, _ = server.WithDailyTemplate("none").toolDaily(ctx, dailyInput{Date: "2026-02-20"})
+	if data := parseResultJSON(t, result); data["content"] != "" {
+		t.Errorf("expected an empty note, got %q", data["content"])
+	}
+}
+
 func TestToolDaily_GetExisting(t *testing.T) {
 	queries, conn, cleanup := setupTestDB(t)
 	defer cleanup()
@@ -1509,6 +2343,34 @@ func TestToolTemplateApply(t *testing.T) {
 	}
 }
 
+func TestToolTemplateApply_Vars(t *testing.T) {
+	queries, conn, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	server := NewServer(queries, conn, nil)
+	ctx := context.Background()
+
+	_, _ = queries.CreateTemplate(ctx, db.CreateTemplateParams{
+		Name:    "kickoff",
+		Content: "# {{title}} with {{client}}\n\n{{cursor}}Attendees: {{attendees}}\n",
+	})
+
+	result, _, _ := server.toolTemplateApply(ctx, templateApplyInput{
+		TemplateName: "kickoff",
+		Title:        "Kickoff",
+		Vars:         map[string]string{"client": "Acme"},
+	})
+	if result.IsError {
+		t.Fatalf("unexpected error: %s", getResultText(result))
+	}
+
+	data := parseResultJSON(t, result)
+	note, _ := queries.GetNote(ctx, int64(data["id"].(float64)))
+	if want := "# Kickoff with Acme\n\nAttendees: {{attendees}}\n"; note.Content != want {
+		t.Errorf("expected %q, got %q", want, note.Content)
+	}
+}
+
 func TestToolTemplateApply_NotFound(t *testing.T) {
 	queries, conn, cleanup := setupTestDB(t)
 	defer cleanup()
@@ -1526,6 +2388,39 @@ func TestToolTemplateApply_NotFound(t *testing.T) {
 	}
 }
 
+func TestToolCreate_FromTemplate(t *testing.T) {
+	queries, conn, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	server := NewServer(queries, conn, nil)
+	ctx := context.Background()
+
+	_, _ = queries.CreateTemplate(ctx, db.CreateTemplateParams{
+		Name:    "meeting",
+		Content: "# {{title}}\nClient: {{client}}",
+	})
+
+	result, _, _ := server.toolCreate(ctx, createInput{
+		Title:    "Kickoff",
+		Content:  "- agreed on scope",
+		Template: "meeting",
+		Vars:     map[string]string{"client": "Acme"},
+	})
+	if result.IsError {
+		t.Fatalf("unexpected error: %s", getResultText(result))
+	}
+	data := parseResultJSON(t, result)
+	note, _ := queries.GetNote(ctx, int64(data["id"].(float64)))
+	if want := "# Kickoff\nClient: Acme\n- agreed on scope"; note.Content != want {
+		t.Errorf("expected %q, got %q", want, note.Content)
+	}
+
+	result, _, _ = server.toolCreate(ctx, createInput{Title: "X", Template: "nonexistent"})
+	if !result.IsError {
+		t.Error("expected error for non-existent template")
+	}
+}
+
 // ============================================================================
 // Tool: noted_tasks Tests
 // ============================================================================
@@ -1606,6 +2501,64 @@ func TestToolTasks_FilterByTag(t *testing.T) {
 	}
 }
 
+func TestToolQueryStats(t *testing.T) {
+	_, conn, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	if result, _, _ := NewServer(db.New(conn), conn, nil).toolQueryStats(queryStatsInput{}); !result.IsError {
+		t.Error("expected an error without a query timer")
+	}
+
+	timer := db.NewQueryTimer(conn)
+	server := NewServer(db.New(timer), conn, nil).WithQueryTimer(timer)
+	createTestNote(t, server.queries, "Timed", "x", nil)
+
+	result, _, _ := server.toolQueryStats(queryStatsInput{Reset: true})
+	if result.IsError {
+		t.Fatalf("unexpected error: %s", getResultText(result))
+	}
+	data := parseResultJSON(t, result)
+	if data["count"].(float64) < 1 {
+		t.Errorf("expected recorded queries, got %v", data)
+	}
+	if len(timer.Stats()) != 0 {
+		t.Error("reset should clear the stats")
+	}
+}
+
+func TestToolTaskDone(t *testing.T) {
+	queries, conn, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	server := NewServer(queries, conn, nil)
+	ctx := context.Background()
+
+	id := createTestNote(t, queries, "Todo", "- [ ] Task A\n- [ ] Task B", nil)
+
+	result, _, _ := server.toolTaskDone(ctx, taskDoneInput{NoteID: id, Index: 2})
+	if result.IsError {
+		t.Fatalf("unexpected error: %s", getResultText(result))
+	}
+	if data := parseResultJSON(t, result); data["text"] != "Task B" || data["completed"] != true {
+		t.Errorf("unexpected result: %v", data)
+	}
+	if note, _ := queries.GetNote(ctx, id); note.Content != "- [ ] Task A\n- [x] Task B" {
+		t.Errorf("task not checked off: %q", note.Content)
+	}
+	if versions, _ := queries.GetNoteVersions(ctx, id); len(versions) != 1 {
+		t.Errorf("expected a version snapshot, got %d", len(versions))
+	}
+
+	_, _, _ = server.toolTaskDone(ctx, taskDoneInput{NoteID: id, Index: 2, Undo: true})
+	if note, _ := queries.GetNote(ctx, id); note.Content != "- [ ] Task A\n- [ ] Task B" {
+		t.Errorf("task not unchecked: %q", note.Content)
+	}
+
+	if result, _, _ := server.toolTaskDone(ctx, taskDoneInput{NoteID: id, Index: 3}); !result.IsError {
+		t.Error("expected error for a missing task")
+	}
+}
+
 func TestToolTasks_NoTasks(t *testing.T) {
 	queries, conn, cleanup := setupTestDB(t)
 	defer cleanup()
@@ -1877,6 +2830,30 @@ func TestToolBacklinks_NotFound(t *testing.T) {
 	}
 }
 
+func TestToolMentions(t *testing.T) {
+	queries, conn, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	server := NewServer(queries, conn, nil)
+	ctx := context.Background()
+
+	_, _, _ = server.toolCreate(ctx, createInput{Title: "1:1 prep", Content: "Ask @Ana about the launch"})
+	_, _, _ = server.toolCreate(ctx, createInput{Title: "Standup", Content: "@ana and @bob are out"})
+
+	result, _, _ := server.toolMentions(ctx, mentionsInput{Name: "@ana"})
+	data := parseResultJSON(t, result)
+	if int(data["count"].(float64)) != 2 || data["name"] != "ana" {
+		t.Errorf("mentions of ana = %v", data)
+	}
+
+	result, _, _ = server.toolMentions(ctx, mentionsInput{})
+	data = parseResultJSON(t, result)
+	mentions := data["mentions"].([]any)
+	if len(mentions) != 2 || mentions[0].(map[string]any)["name"] != "ana" {
+		t.Errorf("mention names = %v", mentions)
+	}
+}
+
 // ============================================================================
 // Tool: noted_orphans Tests
 // ============================================================================
@@ -1929,3 +2906,321 @@ func TestToolOrphans_Empty(t *testing.T) {
 		t.Errorf("expected 0 orphans, got %v", data["orphan_count"])
 	}
 }
+
+func TestToolUnresolvedLinks(t *testing.T) {
+	queries, conn, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	server := NewServer(queries, conn, nil)
+	ctx := context.Background()
+
+	result, _, _ := server.toolUnresolvedLinks(ctx, unresolvedLinksInput{})
+	if data := parseResultJSON(t, result); data["count"].(float64) != 0 {
+		t.Errorf("expected no unresolved links, got %v", data)
+	}
+
+	_, _, _ = server.toolCreate(ctx, createInput{Title: "Plan", Content: "Read [[Design Doc]] first"})
+	result, _, _ = server.toolUnresolvedLinks(ctx, unresolvedLinksInput{})
+	data := parseResultJSON(t, result)
+	unresolved := data["unresolved"].([]any)
+	if len(unresolved) != 1 {
+		t.Fatalf("unresolved = %v", data)
+	}
+	link := unresolved[0].(map[string]any)
+	notes := link["notes"].([]any)
+	if link["target"] != "Design Doc" || len(notes) != 1 || notes[0].(map[string]any)["title"] != "Plan" {
+		t.Errorf("unresolved[0] = %v", link)
+	}
+
+	_, _, _ = server.toolCreate(ctx, createInput{Title: "Design Doc", Content: "x"})
+	result, _, _ = server.toolUnresolvedLinks(ctx, unresolvedLinksInput{})
+	if data := parseResultJSON(t, result); data["count"].(float64) != 0 {
+		t.Errorf("expected the link to resolve once the note exists, got %v", data)
+	}
+}
+
+// ============================================================================
+// Tool gating Tests
+// ============================================================================
+
+// connectTestClient registers the server's tools and connects a client over in-memory transports.
+func connectTestClient(t *testing.T, server *Server, client *mcp.Implementation) *mcp.ClientSession {
+	t.Helper()
+	ctx := context.Background()
+
+	server.server = mcp.NewServer(&mcp.Implementation{Name: "noted", Version: "test"}, nil)
+	server.registerTools()
+
+	clientTransport, serverTransport := mcp.NewInMemoryTransports()
+	ss, err := server.server.Connect(ctx, serverTransport, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = ss.Close() })
+	cs, err := mcp.NewClient(client, nil).Connect(ctx, clientTransport, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = cs.Close() })
+	return cs
+}
+
+// listRegisteredTools lists the server's tools through an in-memory client.
+func listRegisteredTools(t *testing.T, server *Server) []string {
+	t.Helper()
+	cs := connectTestClient(t, server, &mcp.Implementation{Name: "test"})
+
+	res, err := cs.ListTools(context.Background(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := make([]string, len(res.Tools))
+	for i, tool := range res.Tools {
+		names[i] = tool.Name
+	}
+	return names
+}
+
+func TestToolRecall_ContextFormat(t *testing.T) {
+	queries, conn, cleanup := setupTestDB(t)
+	defer cleanup()
+	server := NewServer(queries, conn, nil)
+	ctx := context.Background()
+
+	_, _, _ = server.toolRemember(ctx, rememberInput{Title: "Deploy day", Content: "Deploys happen on Tuesdays", Source: "manual"})
+
+	result, _, _ := server.toolRecall(ctx, recallInput{Query: "Tuesdays", Format: "context"})
+	text := getResultText(result)
+	if result.IsError || !strings.HasPrefix(text, "## Relevant memories") || !strings.Contains(text, "source: manual") {
+		t.Errorf("unexpected context output: %s", text)
+	}
+
+	result, _, _ = server.toolRecall(ctx, recallInput{Query: "Tuesdays", Format: "xml"})
+	if !result.IsError {
+		t.Error("expected an error for an unknown format")
+	}
+}
+
+func TestToolMemoryAdjust(t *testing.T) {
+	queries, conn, cleanup := setupTestDB(t)
+	defer cleanup()
+	server := NewServer(queries, conn, nil)
+	ctx := context.Background()
+
+	result, _, _ := server.toolRemember(ctx, rememberInput{Content: "Prefers short PRs", Importance: 2})
+	id := int64(parseResultJSON(t, result)["id"].(float64))
+
+	result, _, _ = server.toolMemoryAdjust(ctx, memoryAdjustInput{ID: id, ImportanceDelta: 2, Category: "user-pref"})
+	if result.IsError {
+		t.Fatalf("adjust failed: %s", getResultText(result))
+	}
+	out := parseResultJSON(t, result)
+	if out["importance"] != float64(4) || out["category"] != "user-pref" {
+		t.Errorf("unexpected result: %v", out)
+	}
+
+	if result, _, _ = server.toolMemoryAdjust(ctx, memoryAdjustInput{ID: id}); !result.IsError {
+		t.Error("expected an error without importance_delta or category")
+	}
+}
+
+func TestRegisterTools_AllToolsHaveGroups(t *testing.T) {
+	queries, conn, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	names := listRegisteredTools(t, NewServer(queries, conn, newMockSyncer()))
+	if len(names) != len(toolGroups) {
+		t.Errorf("registered %d tools, toolGroups lists %d", len(names), len(toolGroups))
+	}
+	for _, name := range names {
+		if toolGroups[name] == "" {
+			t.Errorf("tool %s has no group", name)
+		}
+	}
+}
+
+func TestRegisterTools_GroupsAndSafeMode(t *testing.T) {
+	queries, conn, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	groups, err := ParseToolGroups("memory, search")
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := listRegisteredTools(t, NewServer(queries, conn, nil).WithToolGroups(groups).WithSafeMode(true))
+
+	want := []string{"noted_context", "noted_memory_adjust", "noted_recall", "noted_recall_batch", "noted_remember", "noted_search", "noted_session_end"}
+	if strings.Join(names, ",") != strings.Join(want, ",") {
+		t.Errorf("tools = %v, want %v", names, want)
+	}
+}
+
+func TestParseToolGroups(t *testing.T) {
+	if groups, err := ParseToolGroups(""); err != nil || groups != nil {
+		t.Errorf("empty spec: %v, %v", groups, err)
+	}
+	if groups, err := ParseToolGroups("memory,all"); err != nil || groups != nil {
+		t.Errorf("all: %v, %v", groups, err)
+	}
+	if _, err := ParseToolGroups("memory,bogus"); err == nil {
+		t.Error("expected error for unknown group")
+	}
+}
+
+// ============================================================================
+// Client identity Tests
+// ============================================================================
+
+func TestToolCreate_RecordsClientIdentity(t *testing.T) {
+	queries, conn, cleanup := setupTestDB(t)
+	defer cleanup()
+	server := NewServer(queries, conn, nil)
+	cs := connectTestClient(t, server, &mcp.Implementation{Name: "review-bot", Version: "1.2.0"})
+	ctx := context.Background()
+
+	for _, call := range []*mcp.CallToolParams{
+		{Name: "noted_create", Arguments: map[string]any{"title": "From agent", "content": "x"}},
+		{Name: "noted_remember", Arguments: map[string]any{"content": "Agent memory"}},
+	} {
+		res, err := cs.CallTool(ctx, call)
+		if err != nil || res.IsError {
+			t.Fatalf("%s failed: %v %v", call.Name, err, res)
+		}
+	}
+
+	notes, _ := queries.GetAllNotes(ctx)
+	if len(notes) != 2 {
+		t.Fatalf("expected 2 notes, got %d", len(notes))
+	}
+	for _, n := range notes {
+		if n.CreatedBy.String != "review-bot/1.2.0" {
+			t.Errorf("note %q created_by = %q, want review-bot/1.2.0", n.Title, n.CreatedBy.String)
+		}
+	}
+
+	// Direct calls without a session leave it unset
+	result, _, _ := server.toolCreate(ctx, createInput{Title: "Local", Content: "y"})
+	data := parseResultJSON(t, result)
+	note, _ := queries.GetNote(ctx, int64(data["id"].(float64)))
+	if note.CreatedBy.Valid {
+		t.Errorf("expected no created_by without a client, got %q", note.CreatedBy.String)
+	}
+}
+
+// ============================================================================
+// Mutation Limit Tests
+// ============================================================================
+
+func TestRateWindow(t *testing.T) {
+	var w rateWindow
+	start := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	for i := 0; i < 2; i++ {
+		if ok, _ := w.allow(start.Add(time.Duration(i)*time.Second), 2, time.Minute); !ok {
+			t.Fatalf("event %d should be allowed", i)
+		}
+	}
+	ok, wait := w.allow(start.Add(10*time.Second), 2, time.Minute)
+	if ok {
+		t.Fatal("third event within the window should be refused")
+	}
+	if wait != 50*time.Second {
+		t.Errorf("expected 50s wait, got %v", wait)
+	}
+	if ok, _ := w.allow(start.Add(61*time.Second), 2, time.Minute); !ok {
+		t.Error("event after the oldest left the window should be allowed")
+	}
+}
+
+func TestToolCreate_RateLimited(t *testing.T) {
+	queries, conn, cleanup := setupTestDB(t)
+	defer cleanup()
+	ctx := context.Background()
+	server := NewServer(queries, conn, nil).WithLimits(Limits{CreatesPerMinute: 2})
+
+	_, _, _ = server.toolCreate(ctx, createInput{Title: "One", Content: "1"})
+	_, _, _ = server.toolRemember(ctx, rememberInput{Content: "Two"})
+	result, _, _ := server.toolCreate(ctx, createInput{Title: "Three", Content: "3"})
+	if !result.IsError {
+		t.Fatal("expected the third create to be rate limited")
+	}
+	if text := getResultText(result); !strings.Contains(text, "at most 2 notes per minute") {
+		t.Errorf("unexpected error: %s", text)
+	}
+	if notes, _ := queries.GetAllNotes(ctx); len(notes) != 2 {
+		t.Errorf("expected 2 notes, got %d", len(notes))
+	}
+}
+
+func TestToolForget_MaxPerCall(t *testing.T) {
+	queries, conn, cleanup := setupTestDB(t)
+	defer cleanup()
+	ctx := context.Background()
+	server := NewServer(queries, conn, nil).WithLimits(Limits{MaxForgetPerCall: 1})
+
+	for _, content := range []string{"first", "second"} {
+		_, _, _ = server.toolRemember(ctx, rememberInput{Content: content, Importance: 1})
+	}
+
+	result, _, _ := server.toolForget(ctx, forgetInput{ImportanceBelow: 2})
+	if !result.IsError {
+		t.Fatal("expected forget over the limit to fail")
+	}
+	if text := getResultText(result); !strings.Contains(text, "would delete 2 memories") {
+		t.Errorf("unexpected error: %s", text)
+	}
+	if notes, _ := queries.GetAllNotes(ctx); len(notes) != 2 {
+		t.Errorf("expected nothing deleted, got %d notes left", len(notes))
+	}
+
+	// Previews are not limited
+	result, _, _ = server.toolForget(ctx, forgetInput{ImportanceBelow: 2, DryRun: true})
+	if result.IsError {
+		t.Errorf("dry run should not be limited: %s", getResultText(result))
+	}
+}
+
+// ============================================================================
+// Embedder Outage Tests
+// ============================================================================
+
+func TestToolSemanticSearch_FallsBackToKeyword(t *testing.T) {
+	queries, conn, cleanup := setupTestDB(t)
+	defer cleanup()
+	ctx := context.Background()
+	createTestNote(t, queries, "Ollama setup", "pull nomic-embed-text", nil)
+
+	syncer := newMockSyncer()
+	syncer.err = fmt.Errorf("%w: connection refused", embeddings.ErrUnavailable)
+	server := NewServer(queries, conn, syncer)
+
+	result, _, _ := server.toolSemanticSearch(ctx, semanticSearchInput{Query: "ollama"})
+	if result.IsError {
+		t.Fatalf("expected keyword fallback, got error: %s", getResultText(result))
+	}
+	data := parseResultJSON(t, result)
+	if data["method"] != "keyword" || int(data["count"].(float64)) != 1 {
+		t.Errorf("unexpected fallback result: %v", data)
+	}
+}
+
+func TestToolSync_StopsWhenEmbedderUnavailable(t *testing.T) {
+	queries, conn, cleanup := setupTestDB(t)
+	defer cleanup()
+	ctx := context.Background()
+	createTestNote(t, queries, "One", "1", nil)
+	createTestNote(t, queries, "Two", "2", nil)
+
+	syncer := newMockSyncer()
+	syncer.err = embeddings.ErrUnavailable
+	server := NewServer(queries, conn, syncer)
+
+	result, _, _ := server.toolSync(ctx, syncInput{})
+	data := parseResultJSON(t, result)
+	if data["status"] != "interrupted: embedder unavailable" {
+		t.Errorf("unexpected status: %v", data["status"])
+	}
+	if int(data["failed"].(float64)) != 0 || int(data["remaining"].(float64)) != 2 {
+		t.Errorf("expected 0 failed and 2 remaining, got %v", data)
+	}
+}
@@ -5,8 +5,11 @@ import (
 	"database/sql"
 	"fmt"
 	"os"
+	"time"
 
 	"github.com/abdul-hamid-achik/noted/internal/db"
+	"github.com/abdul-hamid-achik/noted/internal/quota"
+	"github.com/abdul-hamid-achik/noted/internal/sanitize"
 	"github.com/abdul-hamid-achik/noted/internal/vault"
 	"github.com/abdul-hamid-achik/noted/internal/veclite"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
@@ -22,12 +25,28 @@ type Server struct {
 	server  *mcp.Server
 	syncer  Syncer
 	vlt     *vault.Vault // optional markdown vault for write-through; nil disables it
+
+	searchHistory bool     // record noted_search queries in the search history
+	dailyTemplate string   // template new daily notes start from, if it exists
+	toolGroups    []string // tool groups to register; empty registers all (see toolsets.go)
+	safeMode      bool     // leave out destructive tools
+
+	queryTimer *db.QueryTimer // times queries for noted_query_stats; nil when not wrapped
+
+	limits  Limits       // mutation limits; zero values disable them (see limits.go)
+	quotas  quota.Quotas // note, database, and attachment size limits (see limits.go)
+	creates rateWindow   // recent note/memory creations, for Limits.CreatesPerMinute
+
+	sanitize sanitize.Policy // cleans up content tools write; the zero policy leaves it alone
+
+	session sessionNotes // notes saved this session, for noted_session_end (see session.go)
 }
 
 // Syncer interface for optional semantic search integration
 type Syncer interface {
 	Search(query string, limit int) ([]veclite.SemanticResult, error)
 	SyncNote(id int64, title, content string) error
+	SyncMemory(id int64, title, content string) error
 	Delete(noteID int64) error
 	Close() error
 }
@@ -38,6 +57,9 @@ func NewServer(queries *db.Queries, conn *sql.DB, syncer Syncer) *Server {
 		queries: queries,
 		conn:    conn,
 		syncer:  syncer,
+
+		dailyTemplate: "daily",
+		session:       sessionNotes{started: time.Now()},
 	}
 }
 
@@ -49,6 +71,69 @@ func (s *Server) WithVault(v *vault.Vault) *Server {
 	return s
 }
 
+// WithSearchHistory records noted_search queries in the shared search history (interface "mcp"),
+// alongside CLI searches. Off by default; the CLI enables it unless NOTED_SEARCH_HISTORY=off.
+func (s *Server) WithSearchHistory(enabled bool) *Server {
+	s.searchHistory = enabled
+	return s
+}
+
+// WithDailyTemplate names the template noted_daily starts new daily notes from when the call
+// doesn't pass one (default "daily"). A name no template has leaves new daily notes empty.
+func (s *Server) WithDailyTemplate(name string) *Server {
+	s.dailyTemplate = name
+	return s
+}
+
+// WithQueryTimer lets noted_query_stats report the per-query latency t records. t should be the
+// DBTX the server's queries were built on.
+func (s *Server) WithQueryTimer(t *db.QueryTimer) *Server {
+	s.queryTimer = t
+	return s
+}
+
+// WithSanitize cleans up the titles and content tools write with p before they are saved, since
+// agent output sometimes carries control characters or stray markup. Returns the server for chaining.
+func (s *Server) WithSanitize(p sanitize.Policy) *Server {
+	s.sanitize = p
+	return s
+}
+
+type clientKey struct{}
+
+// withClient stores the calling MCP client's identity ("name/version", from the initialize
+// handshake) in the tool context.
+func withClient(ctx context.Context, req *mcp.CallToolRequest) context.Context {
+	if req == nil || req.Session == nil {
+		return ctx
+	}
+	params := req.Session.InitializeParams()
+	if params == nil || params.ClientInfo == nil || params.ClientInfo.Name == "" {
+		return ctx
+	}
+	client := params.ClientInfo.Name
+	if params.ClientInfo.Version != "" {
+		client += "/" + params.ClientInfo.Version
+	}
+	return context.WithValue(ctx, clientKey{}, client)
+}
+
+// clientFrom returns the MCP client identity stored by withClient, or "".
+func clientFrom(ctx context.Context) string {
+	client, _ := ctx.Value(clientKey{}).(string)
+	return client
+}
+
+// recordCreatedBy stamps a note created through a tool with the calling client's identity.
+func (s *Server) recordCreatedBy(ctx context.Context, noteID int64) {
+	if client := clientFrom(ctx); client != "" {
+		_ = s.queries.SetNoteCreatedBy(ctx, db.SetNoteCreatedByParams{
+			CreatedBy: sql.NullString{String: client, Valid: true},
+			ID:        noteID,
+		})
+	}
+}
+
 // Run starts the MCP server with stdio transport
 func (s *Server) Run(ctx context.Context) error {
 	// Create MCP server with implementation info
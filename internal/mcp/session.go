@@ -0,0 +1,103 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/abdul-hamid-achik/noted/internal/db"
+	"github.com/abdul-hamid-achik/noted/internal/memory"
+	"github.com/abdul-hamid-achik/noted/internal/notesync"
+	"github.com/abdul-hamid-achik/noted/internal/veclite"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// sessionNotes tracks the notes tools saved since the session started: when the server started,
+// or at the last noted_session_end.
+type sessionNotes struct {
+	mu      sync.Mutex
+	started time.Time
+	ids     []int64
+}
+
+func (n *sessionNotes) touch(id int64) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if !slices.Contains(n.ids, id) {
+		n.ids = append(n.ids, id)
+	}
+}
+
+// end returns the session's start time and notes, and starts a new session at now.
+func (n *sessionNotes) end(now time.Time) (time.Time, []int64) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	started, ids := n.started, n.ids
+	n.started, n.ids = now, nil
+	return started, ids
+}
+
+// writeThrough records a saved note's derived data and mirrors it to the vault (see
+// notesync.WriteThrough), and counts it as touched in this session.
+func (s *Server) writeThrough(ctx context.Context, note db.Note) {
+	notesync.WriteThrough(ctx, s.queries, s.vlt, note)
+	s.session.touch(note.ID)
+}
+
+func (s *Server) toolSessionEnd(ctx context.Context, input sessionEndInput) (*mcp.CallToolResult, any, error) {
+	input.Title, input.Summary = s.sanitize.Title(input.Title), s.sanitize.Apply(input.Summary)
+	if input.Summary == "" {
+		return errorResult("summary is required")
+	}
+	if input.Category != "" && input.Category != "project" && input.Category != "decision" {
+		return errorResult(fmt.Sprintf("invalid category %q (valid: project, decision)", input.Category))
+	}
+
+	var syncer *veclite.Syncer
+	if vs, ok := s.syncer.(*veclite.Syncer); ok {
+		syncer = vs
+	}
+
+	if err := s.allowCreate(); err != nil {
+		return errorResult(err.Error())
+	}
+	warnings, err := s.checkQuota(ctx, input.Summary, true)
+	if err != nil {
+		return errorResult(err.Error())
+	}
+
+	now := time.Now()
+	started, touched := s.session.end(now)
+	if touched == nil {
+		touched = []int64{}
+	}
+	mem, err := memory.LogSession(ctx, s.queries, syncer, memory.SessionInput{
+		Summary:    input.Summary,
+		Title:      input.Title,
+		Category:   input.Category,
+		Importance: input.Importance,
+		Started:    started,
+		Ended:      now,
+		NoteIDs:    append(touched, input.NoteIDs...),
+	})
+	if err != nil {
+		return errorResult(fmt.Sprintf("failed to log session: %v", err))
+	}
+	s.recordCreatedBy(ctx, mem.ID)
+
+	result := map[string]any{
+		"id":            mem.ID,
+		"title":         mem.Title,
+		"category":      mem.Category,
+		"started_at":    started.Format(time.RFC3339),
+		"ended_at":      now.Format(time.RFC3339),
+		"notes_touched": touched,
+		"status":        "logged",
+	}
+	if len(warnings) > 0 {
+		result["warnings"] = warnings
+	}
+	return textResult(result)
+}
@@ -4,35 +4,50 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"math/rand/v2"
-	"regexp"
+	"slices"
 	"strings"
 	"time"
 
 	"github.com/abdul-hamid-achik/noted/internal/db"
+	"github.com/abdul-hamid-achik/noted/internal/embeddings"
+	"github.com/abdul-hamid-achik/noted/internal/markdown"
 	"github.com/abdul-hamid-achik/noted/internal/memory"
 	"github.com/abdul-hamid-achik/noted/internal/notesync"
 	"github.com/abdul-hamid-achik/noted/internal/veclite"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
 
-var taskRegex = regexp.MustCompile(`^\s*-\s*\[([ xX])\]\s*(.+)$`)
-
 // Input types for MCP tools
 // The jsonschema tag provides the description for the JSON schema.
 // Fields without omitempty in json tag are considered required.
 
 type createInput struct {
-	Title   string   `json:"title" jsonschema:"Note title"`
-	Content string   `json:"content" jsonschema:"Note content"`
-	Tags    []string `json:"tags,omitempty" jsonschema:"Tags for categorization"`
+	Title    string            `json:"title" jsonschema:"Note title"`
+	Content  string            `json:"content,omitempty" jsonschema:"Note content (required unless template is set)"`
+	Tags     []string          `json:"tags,omitempty" jsonschema:"Tags for categorization"`
+	Aliases  []string          `json:"aliases,omitempty" jsonschema:"Other names [[wikilinks]] may use for this note"`
+	Template string            `json:"template,omitempty" jsonschema:"Start the note from this template; content, if given, is appended after it"`
+	Vars     map[string]string `json:"vars,omitempty" jsonschema:"Values for the template's custom placeholders such as {{client}}"`
+	FolderID int64             `json:"folder_id,omitempty" jsonschema:"Put the note in this folder"`
+	Folder   string            `json:"folder,omitempty" jsonschema:"Put the note in this folder path, such as 'work/noted', creating folders that don't exist (instead of folder_id)"`
+	Pinned   bool              `json:"pinned,omitempty" jsonschema:"Pin the note"`
 }
 
 type listInput struct {
-	Limit  int    `json:"limit,omitempty" jsonschema:"Max notes to return (default 20)"`
-	Tag    string `json:"tag,omitempty" jsonschema:"Filter by tag name"`
-	Offset int    `json:"offset,omitempty" jsonschema:"Pagination offset"`
+	Limit             int    `json:"limit,omitempty" jsonschema:"Max notes to return (default 20)"`
+	Tag               string `json:"tag,omitempty" jsonschema:"Filter by tag name"`
+	Offset            int    `json:"offset,omitempty" jsonschema:"Pagination offset; the result's next_offset and prev_offset give the neighbouring pages"`
+	Archived          bool   `json:"archived,omitempty" jsonschema:"Include archived notes"`
+	Lang              string `json:"lang,omitempty" jsonschema:"Filter by detected language, as an ISO 639-1 code (e.g. en, es)"`
+	FolderID          int64  `json:"folder_id,omitempty" jsonschema:"Only notes in this folder"`
+	IncludeSubfolders bool   `json:"include_subfolders,omitempty" jsonschema:"With folder_id, also include notes in the folders below it"`
+	Sort              string `json:"sort,omitempty" jsonschema:"Order by created (default), updated, title, or pinned (pinned notes first, then by last update)"`
+	Order             string `json:"order,omitempty" jsonschema:"asc or desc (default: desc, asc for title)"`
+	ModifiedSince     string `json:"modified_since,omitempty" jsonschema:"Only notes updated since a date (YYYY-MM-DD), RFC 3339 time, or duration ago (e.g. '2d')"`
+	ModifiedUntil     string `json:"modified_until,omitempty" jsonschema:"Only notes updated up to a date (YYYY-MM-DD, inclusive), RFC 3339 time, or duration ago"`
 }
 
 type getInput struct {
@@ -40,23 +55,69 @@ type getInput struct {
 }
 
 type searchInput struct {
-	Query string `json:"query" jsonschema:"Search query for title and content"`
-	Limit int    `json:"limit,omitempty" jsonschema:"Max results (default 20)"`
+	Query       string `json:"query" jsonschema:"Search query for title, content, and tag names"`
+	Limit       int    `json:"limit,omitempty" jsonschema:"Max results (default 20)"`
+	Archived    bool   `json:"archived,omitempty" jsonschema:"Include archived notes"`
+	FullContent bool   `json:"full_content,omitempty" jsonschema:"Return each note's whole content instead of only snippets around the matches"`
 }
 
 type updateInput struct {
-	ID      int64    `json:"id" jsonschema:"Note ID"`
-	Title   string   `json:"title,omitempty" jsonschema:"New title (optional)"`
-	Content string   `json:"content,omitempty" jsonschema:"New content (optional)"`
-	Tags    []string `json:"tags,omitempty" jsonschema:"Replace tags (optional)"`
+	ID        int64    `json:"id" jsonschema:"Note ID"`
+	Title     string   `json:"title,omitempty" jsonschema:"New title (optional)"`
+	Content   string   `json:"content,omitempty" jsonschema:"New content (optional)"`
+	Tags      []string `json:"tags,omitempty" jsonschema:"Replace tags (optional)"`
+	Aliases   []string `json:"aliases,omitempty" jsonschema:"Replace aliases, the other names [[wikilinks]] may use (optional; [] clears them)"`
+	Folder    *int64   `json:"folder_id,omitempty" jsonschema:"Move to this folder (optional; 0 removes the note from its folder)"`
+	Pinned    *bool    `json:"pinned,omitempty" jsonschema:"Pin or unpin the note (optional)"`
+	Source    *string  `json:"source,omitempty" jsonschema:"Set the source identifier (optional; empty clears it)"`
+	SourceRef *string  `json:"source_ref,omitempty" jsonschema:"Set the source reference (optional; empty clears it)"`
+	TTL       *string  `json:"ttl,omitempty" jsonschema:"Expire the note this long from now, e.g. '24h' or '7d' (optional; empty means never)"`
 }
 
 type deleteInput struct {
 	ID int64 `json:"id" jsonschema:"Note ID to delete"`
 }
 
+type outlineInput struct {
+	ID int64 `json:"id" jsonschema:"Note ID"`
+}
+
+type getSectionInput struct {
+	ID      int64  `json:"id" jsonschema:"Note ID"`
+	Section string `json:"section" jsonschema:"Heading text of the section (case-insensitive, without the #s)"`
+}
+
+type updateSectionInput struct {
+	ID      int64  `json:"id" jsonschema:"Note ID"`
+	Section string `json:"section" jsonschema:"Heading text of the section (case-insensitive, without the #s)"`
+	Content string `json:"content" jsonschema:"New section body, without the heading line"`
+	Append  bool   `json:"append,omitempty" jsonschema:"Add content at the end of the section instead of replacing its body"`
+}
+
+type archiveInput struct {
+	ID        int64 `json:"id" jsonschema:"Note ID"`
+	Unarchive bool  `json:"unarchive,omitempty" jsonschema:"Bring an archived note back into lists and search instead"`
+}
+
+type trashRestoreInput struct {
+	ID int64 `json:"id" jsonschema:"ID of the trashed note to restore"`
+}
+
 type emptyInput struct{}
 
+type tagRenameInput struct {
+	Name    string `json:"name" jsonschema:"Current tag name"`
+	NewName string `json:"new_name" jsonschema:"New tag name; if it already exists the two tags are merged"`
+}
+
+type tagDeleteInput struct {
+	Name string `json:"name" jsonschema:"Tag to remove from all notes and delete"`
+}
+
+type tagCleanupInput struct {
+	DryRun bool `json:"dry_run,omitempty" jsonschema:"List unused tags without deleting them"`
+}
+
 type semanticSearchInput struct {
 	Query string `json:"query" jsonschema:"Natural language query for semantic search"`
 	Limit int    `json:"limit,omitempty" jsonschema:"Max results (default 10)"`
@@ -76,25 +137,61 @@ type recallInput struct {
 	Query    string `json:"query" jsonschema:"What to recall (semantic search query)"`
 	Limit    int    `json:"limit,omitempty" jsonschema:"Max results (default 5)"`
 	Category string `json:"category,omitempty" jsonschema:"Filter by category"`
+	Source   string `json:"source,omitempty" jsonschema:"Only memories from this source (e.g., 'code-review')"`
+	Since    string `json:"since,omitempty" jsonschema:"Only memories created since a date (YYYY-MM-DD), RFC 3339 time, or duration ago (e.g., '30d')"`
+	Until    string `json:"until,omitempty" jsonschema:"Only memories created up to a date (YYYY-MM-DD, inclusive), RFC 3339 time, or duration ago"`
+	Format   string `json:"format,omitempty" jsonschema:"Output format: json (default) or context (a markdown 'Relevant memories' block with citations, ready for a prompt)"`
+}
+
+type recallBatchInput struct {
+	Queries  []string `json:"queries" jsonschema:"Queries to recall for, e.g. the steps of the current task"`
+	Limit    int      `json:"limit,omitempty" jsonschema:"Max results per query (default 5)"`
+	Category string   `json:"category,omitempty" jsonschema:"Filter by category"`
+	Source   string   `json:"source,omitempty" jsonschema:"Only memories from this source (e.g., 'code-review')"`
+	Since    string   `json:"since,omitempty" jsonschema:"Only memories created since a date (YYYY-MM-DD), RFC 3339 time, or duration ago (e.g., '30d')"`
+	Until    string   `json:"until,omitempty" jsonschema:"Only memories created up to a date (YYYY-MM-DD, inclusive), RFC 3339 time, or duration ago"`
+}
+
+type contextInput struct {
+	Topic  string `json:"topic,omitempty" jsonschema:"Topic to focus on; memories and notes are then searched for it instead of taken by importance and recency"`
+	Budget int    `json:"budget,omitempty" jsonschema:"Approximate size limit of the bundle in tokens (default 2000)"`
+}
+
+type sessionEndInput struct {
+	Summary    string  `json:"summary" jsonschema:"What was done this session: changes, decisions, open questions"`
+	Title      string  `json:"title,omitempty" jsonschema:"Title for the log entry (default 'Session <date time>')"`
+	Category   string  `json:"category,omitempty" jsonschema:"Memory category: project (default) or decision"`
+	Importance int     `json:"importance,omitempty" jsonschema:"Importance level 1-5 (default 3)"`
+	NoteIDs    []int64 `json:"note_ids,omitempty" jsonschema:"Other notes to link from the log, besides the ones saved through this server during the session"`
 }
 
 type forgetInput struct {
 	OlderThanDays   int    `json:"older_than_days,omitempty" jsonschema:"Delete memories older than N days"`
 	ImportanceBelow int    `json:"importance_below,omitempty" jsonschema:"Delete memories below this importance level (1-5)"`
 	Category        string `json:"category,omitempty" jsonschema:"Only delete memories in this category"`
+	Source          string `json:"source,omitempty" jsonschema:"Only delete memories from this source (e.g., 'code-review')"`
+	TagPrefix       string `json:"tag_prefix,omitempty" jsonschema:"Only delete memories with a tag starting with this prefix (e.g., 'temp:')"`
 	DryRun          bool   `json:"dry_run,omitempty" jsonschema:"Preview what would be deleted without actually deleting (default true)"`
 }
 
+type memoryAdjustInput struct {
+	ID              int64  `json:"id" jsonschema:"Memory ID"`
+	ImportanceDelta int    `json:"importance_delta,omitempty" jsonschema:"Steps to raise (positive) or lower (negative) the importance; the result stays within 1-5"`
+	Category        string `json:"category,omitempty" jsonschema:"Move to this category: user-pref, project, decision, fact, or todo"`
+}
+
 type syncInput struct {
-	Force bool `json:"force,omitempty" jsonschema:"Re-sync all notes even if already synced"`
+	Force  bool `json:"force,omitempty" jsonschema:"Re-sync all notes even if already synced"`
+	Status bool `json:"status,omitempty" jsonschema:"Only report how many notes are waiting to be synced"`
 }
 
 // Daily notes input types
 
 type dailyInput struct {
-	Date    string `json:"date,omitempty" jsonschema:"Date in YYYY-MM-DD format (default: today)"`
-	Append  string `json:"append,omitempty" jsonschema:"Text to append to the daily note"`
-	Prepend string `json:"prepend,omitempty" jsonschema:"Text to prepend to the daily note"`
+	Date     string `json:"date,omitempty" jsonschema:"Date in YYYY-MM-DD format (default: today)"`
+	Append   string `json:"append,omitempty" jsonschema:"Text to append to the daily note"`
+	Prepend  string `json:"prepend,omitempty" jsonschema:"Text to prepend to the daily note"`
+	Template string `json:"template,omitempty" jsonschema:"Template a new daily note starts from (default: the configured daily template, if it exists)"`
 }
 
 type dailyListInput struct {
@@ -119,9 +216,10 @@ type templateDeleteInput struct {
 }
 
 type templateApplyInput struct {
-	TemplateName string   `json:"template_name" jsonschema:"Name of the template to apply"`
-	Title        string   `json:"title" jsonschema:"Title for the new note"`
-	Tags         []string `json:"tags,omitempty" jsonschema:"Tags for the new note"`
+	TemplateName string            `json:"template_name" jsonschema:"Name of the template to apply"`
+	Title        string            `json:"title" jsonschema:"Title for the new note"`
+	Tags         []string          `json:"tags,omitempty" jsonschema:"Tags for the new note"`
+	Vars         map[string]string `json:"vars,omitempty" jsonschema:"Values for custom placeholders such as {{client}}; unset ones are left as is"`
 }
 
 // Task extraction input types
@@ -133,6 +231,12 @@ type tasksInput struct {
 	Completed bool   `json:"completed,omitempty" jsonschema:"Show only completed tasks"`
 }
 
+type taskDoneInput struct {
+	NoteID int64 `json:"note_id" jsonschema:"ID of the note holding the task"`
+	Index  int   `json:"index" jsonschema:"Position of the task among the note's tasks, starting at 1 (the index noted_tasks returns)"`
+	Undo   bool  `json:"undo,omitempty" jsonschema:"Uncheck the task instead of checking it"`
+}
+
 // History/versioning input types
 
 type historyInput struct {
@@ -163,6 +267,18 @@ type backlinksInput struct {
 
 type linkHealthInput struct{}
 
+type unresolvedLinksInput struct {
+	NoteID int64 `json:"note_id,omitempty" jsonschema:"Only the broken links written in this note (optional)"`
+}
+
+type mentionsInput struct {
+	Name string `json:"name,omitempty" jsonschema:"Person to find mentions of, with or without the @. Omit to list everyone mentioned"`
+}
+
+type queryStatsInput struct {
+	Reset bool `json:"reset,omitempty" jsonschema:"Clear the recorded stats after returning them"`
+}
+
 // Output types for formatted responses
 
 type noteOutput struct {
@@ -170,36 +286,61 @@ type noteOutput struct {
 	Title     string   `json:"title"`
 	Content   string   `json:"content"`
 	Tags      []string `json:"tags,omitempty"`
+	Aliases   []string `json:"aliases,omitempty"`
 	CreatedAt string   `json:"created_at,omitempty"`
 	UpdatedAt string   `json:"updated_at,omitempty"`
+	CreatedBy string   `json:"created_by,omitempty"`
+	Archived  bool     `json:"archived,omitempty"`
+	Lang      string   `json:"lang,omitempty"`
+	Source    string   `json:"source,omitempty"`
+	SourceRef string   `json:"source_ref,omitempty"`
+	ExpiresAt string   `json:"expires_at,omitempty"`
+}
+
+type searchOutput struct {
+	noteOutput
+	// Content shadows noteOutput's, so it is left out unless full_content is asked for
+	Content     string          `json:"content,omitempty"`
+	Score       float64         `json:"score"`
+	MatchedTags []string        `json:"matched_tags,omitempty"`
+	Snippets    []searchSnippet `json:"snippets,omitempty"`
+}
+
+// searchSnippet is an excerpt around a search's matches: offsets in bytes into text, and the
+// matches <mark>-wrapped in html.
+type searchSnippet struct {
+	db.Snippet
+	HTML string `json:"html"`
 }
 
 type tagOutput struct {
-	ID        int64  `json:"id"`
-	Name      string `json:"name"`
-	NoteCount int64  `json:"note_count,omitempty"`
+	ID          int64  `json:"id"`
+	Name        string `json:"name"`
+	NoteCount   int64  `json:"note_count,omitempty"`
+	Color       string `json:"color,omitempty"`
+	Description string `json:"description,omitempty"`
 }
 
 // registerTools registers all MCP tools
 func (s *Server) registerTools() {
 	// noted_create - Create a new note
-	mcp.AddTool(s.server, &mcp.Tool{
+	addTool(s, &mcp.Tool{
 		Name:        "noted_create",
-		Description: "Create a new note with title, content, and optional tags",
+		Description: "Create a new note with title, content, and optional tags, folder, and pin, optionally starting from a template",
 	}, func(ctx context.Context, req *mcp.CallToolRequest, input createInput) (*mcp.CallToolResult, any, error) {
 		return s.toolCreate(ctx, input)
 	})
 
 	// noted_list - List notes with optional tag filter
-	mcp.AddTool(s.server, &mcp.Tool{
+	addTool(s, &mcp.Tool{
 		Name:        "noted_list",
-		Description: "List notes with optional tag filter and pagination",
+		Description: "List notes with optional tag, folder, language, or last-modified (modified_since, modified_until) filter, sorting, and pagination. include_subfolders=true widens folder_id to the folders below it. Archived notes are left out unless archived=true.",
 	}, func(ctx context.Context, req *mcp.CallToolRequest, input listInput) (*mcp.CallToolResult, any, error) {
 		return s.toolList(ctx, input)
 	})
 
 	// noted_get - Get a note by ID
-	mcp.AddTool(s.server, &mcp.Tool{
+	addTool(s, &mcp.Tool{
 		Name:        "noted_get",
 		Description: "Get a note by its ID, including tags",
 	}, func(ctx context.Context, req *mcp.CallToolRequest, input getInput) (*mcp.CallToolResult, any, error) {
@@ -207,40 +348,112 @@ func (s *Server) registerTools() {
 	})
 
 	// noted_search - Full-text search
-	mcp.AddTool(s.server, &mcp.Tool{
+	addTool(s, &mcp.Tool{
 		Name:        "noted_search",
-		Description: "Search notes by title and content using text matching",
+		Description: "Search notes by title, content, and tag names using text matching. Title matches rank above tag matches, which rank above content matches. A lang:<code> term (e.g. lang:es) keeps only notes detected as that language. Archived notes are left out unless archived=true. Each result has snippets of the content around the matches rather than the whole note; use noted_get, or full_content=true, for the full text.",
 	}, func(ctx context.Context, req *mcp.CallToolRequest, input searchInput) (*mcp.CallToolResult, any, error) {
 		return s.toolSearch(ctx, input)
 	})
 
 	// noted_update - Update a note
-	mcp.AddTool(s.server, &mcp.Tool{
+	addTool(s, &mcp.Tool{
 		Name:        "noted_update",
-		Description: "Update a note's title, content, or tags",
+		Description: "Update a note's title, content, tags, folder, pin, or metadata (source, source_ref, ttl)",
 	}, func(ctx context.Context, req *mcp.CallToolRequest, input updateInput) (*mcp.CallToolResult, any, error) {
 		return s.toolUpdate(ctx, input)
 	})
 
-	// noted_delete - Delete a note
-	mcp.AddTool(s.server, &mcp.Tool{
+	// noted_delete - Move a note to the trash
+	addTool(s, &mcp.Tool{
 		Name:        "noted_delete",
-		Description: "Delete a note by ID",
+		Description: "Delete a note by ID. The note moves to the trash and can be brought back with noted_trash_restore.",
 	}, func(ctx context.Context, req *mcp.CallToolRequest, input deleteInput) (*mcp.CallToolResult, any, error) {
 		return s.toolDelete(ctx, input)
 	})
 
+	// noted_outline - Heading tree of a note
+	addTool(s, &mcp.Tool{
+		Name:        "noted_outline",
+		Description: "Get the heading outline of a note as a tree, with each heading's line and the byte offsets where its section starts and ends. Use it to find sections in long notes without reading the whole content.",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, input outlineInput) (*mcp.CallToolResult, any, error) {
+		return s.toolOutline(ctx, input)
+	})
+
+	// noted_get_section - Read one section of a note
+	addTool(s, &mcp.Tool{
+		Name:        "noted_get_section",
+		Description: "Get one heading-delimited section of a note: the heading line and everything under it up to the next heading of the same or higher level. Cheaper than noted_get for long notes; see noted_outline for the headings.",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, input getSectionInput) (*mcp.CallToolResult, any, error) {
+		return s.toolGetSection(ctx, input)
+	})
+
+	// noted_update_section - Rewrite or append to one section of a note
+	addTool(s, &mcp.Tool{
+		Name:        "noted_update_section",
+		Description: "Replace the body of one heading-delimited section of a note (the heading line is kept; subsections are part of the body), or add to its end with append=true. The rest of the note is left untouched and the previous version is saved to history.",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, input updateSectionInput) (*mcp.CallToolResult, any, error) {
+		return s.toolUpdateSection(ctx, input)
+	})
+
+	// noted_archive - Archive or unarchive a note
+	addTool(s, &mcp.Tool{
+		Name:        "noted_archive",
+		Description: "Archive a note by ID: it stays intact but is hidden from noted_list and noted_search unless they are called with archived=true. Set unarchive=true to undo.",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, input archiveInput) (*mcp.CallToolResult, any, error) {
+		return s.toolArchive(ctx, input)
+	})
+
+	// noted_trash_list - List trashed notes
+	addTool(s, &mcp.Tool{
+		Name:        "noted_trash_list",
+		Description: "List notes in the trash, most recently deleted first",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, input struct{}) (*mcp.CallToolResult, any, error) {
+		return s.toolTrashList(ctx)
+	})
+
+	// noted_trash_restore - Restore a trashed note
+	addTool(s, &mcp.Tool{
+		Name:        "noted_trash_restore",
+		Description: "Restore a deleted note from the trash",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, input trashRestoreInput) (*mcp.CallToolResult, any, error) {
+		return s.toolTrashRestore(ctx, input)
+	})
+
 	// noted_tags - List all tags with counts
-	mcp.AddTool(s.server, &mcp.Tool{
+	addTool(s, &mcp.Tool{
 		Name:        "noted_tags",
 		Description: "List all tags with their note counts",
 	}, func(ctx context.Context, req *mcp.CallToolRequest, input emptyInput) (*mcp.CallToolResult, any, error) {
 		return s.toolTags(ctx)
 	})
 
+	// noted_tag_rename - Rename or merge a tag
+	addTool(s, &mcp.Tool{
+		Name:        "noted_tag_rename",
+		Description: "Rename a tag across all notes. Renaming to an existing tag merges the two.",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, input tagRenameInput) (*mcp.CallToolResult, any, error) {
+		return s.toolTagRename(ctx, input)
+	})
+
+	// noted_tag_delete - Delete a tag
+	addTool(s, &mcp.Tool{
+		Name:        "noted_tag_delete",
+		Description: "Remove a tag from all notes and delete it (the notes are kept)",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, input tagDeleteInput) (*mcp.CallToolResult, any, error) {
+		return s.toolTagDelete(ctx, input)
+	})
+
+	// noted_tag_cleanup - Delete unused tags
+	addTool(s, &mcp.Tool{
+		Name:        "noted_tag_cleanup",
+		Description: "Delete tags that no note uses",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, input tagCleanupInput) (*mcp.CallToolResult, any, error) {
+		return s.toolTagCleanup(ctx, input)
+	})
+
 	// noted_semantic_search - Only register if veclite is available
 	if s.HasSemanticSearch() {
-		mcp.AddTool(s.server, &mcp.Tool{
+		addTool(s, &mcp.Tool{
 			Name:        "noted_semantic_search",
 			Description: "Search notes using semantic similarity (requires veclite)",
 		}, func(ctx context.Context, req *mcp.CallToolRequest, input semanticSearchInput) (*mcp.CallToolResult, any, error) {
@@ -249,7 +462,7 @@ func (s *Server) registerTools() {
 	}
 
 	// noted_remember - Store a memory for later recall
-	mcp.AddTool(s.server, &mcp.Tool{
+	addTool(s, &mcp.Tool{
 		Name:        "noted_remember",
 		Description: "Store a memory for later recall. Memories are notes with special tags for categorization and importance.",
 	}, func(ctx context.Context, req *mcp.CallToolRequest, input rememberInput) (*mcp.CallToolResult, any, error) {
@@ -257,39 +470,71 @@ func (s *Server) registerTools() {
 	})
 
 	// noted_recall - Recall memories by query
-	mcp.AddTool(s.server, &mcp.Tool{
+	addTool(s, &mcp.Tool{
 		Name:        "noted_recall",
 		Description: "Recall relevant memories by query. Uses semantic search if available, otherwise falls back to keyword search.",
 	}, func(ctx context.Context, req *mcp.CallToolRequest, input recallInput) (*mcp.CallToolResult, any, error) {
 		return s.toolRecall(ctx, input)
 	})
 
+	// noted_recall_batch - Recall memories for several queries at once
+	addTool(s, &mcp.Tool{
+		Name:        "noted_recall_batch",
+		Description: "Recall memories for several queries in one call, e.g. one per step of a plan. Results are grouped per query; a memory is returned in full only under the first query that finds it, and later queries list its ID in also_matched.",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, input recallBatchInput) (*mcp.CallToolResult, any, error) {
+		return s.toolRecallBatch(ctx, input)
+	})
+
+	// noted_context - Session-start context bundle
+	addTool(s, &mcp.Tool{
+		Name:        "noted_context",
+		Description: "Load context at the start of a session: pinned notes, the most important memories, and recently updated notes, as one markdown document cut to a token budget. With a topic, memories and notes are searched for it.",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, input contextInput) (*mcp.CallToolResult, any, error) {
+		return s.toolContext(ctx, input)
+	})
+
+	// noted_session_end - Log a summary of the session
+	addTool(s, &mcp.Tool{
+		Name:        "noted_session_end",
+		Description: "Log a summary of the work done in this session as a memory (source 'session'), with its start and end time and links to every note created or updated through noted since the session began. The next session starts when this returns.",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, input sessionEndInput) (*mcp.CallToolResult, any, error) {
+		return s.toolSessionEnd(ctx, input)
+	})
+
 	// noted_forget - Delete old or low-importance memories
-	mcp.AddTool(s.server, &mcp.Tool{
+	addTool(s, &mcp.Tool{
 		Name:        "noted_forget",
-		Description: "Delete old or low-importance memories based on criteria. Use dry_run=true to preview deletions.",
+		Description: "Delete old or low-importance memories based on criteria. Deleted memories move to the trash and can be brought back with noted_trash_restore. Use dry_run=true to preview deletions.",
 	}, func(ctx context.Context, req *mcp.CallToolRequest, input forgetInput) (*mcp.CallToolResult, any, error) {
 		return s.toolForget(ctx, input)
 	})
 
+	// noted_memory_adjust - Promote/demote a memory or change its category
+	addTool(s, &mcp.Tool{
+		Name:        "noted_memory_adjust",
+		Description: "Raise or lower a memory's importance and/or move it to another category, keeping its importance and category tags consistent.",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, input memoryAdjustInput) (*mcp.CallToolResult, any, error) {
+		return s.toolMemoryAdjust(ctx, input)
+	})
+
 	// noted_sync - Sync notes to semantic search index
-	mcp.AddTool(s.server, &mcp.Tool{
+	addTool(s, &mcp.Tool{
 		Name:        "noted_sync",
-		Description: "Sync unembedded notes to the semantic search index (requires veclite)",
+		Description: "Sync unembedded notes to the semantic search index (requires veclite); with status, only count the notes waiting to be synced",
 	}, func(ctx context.Context, req *mcp.CallToolRequest, input syncInput) (*mcp.CallToolResult, any, error) {
 		return s.toolSync(ctx, input)
 	})
 
 	// --- Daily Notes ---
 
-	mcp.AddTool(s.server, &mcp.Tool{
+	addTool(s, &mcp.Tool{
 		Name:        "noted_daily",
-		Description: "Get or create today's daily note. Optionally append or prepend content.",
+		Description: "Get or create today's (or a given date's) daily note, new ones from the daily template. Optionally append or prepend content.",
 	}, func(ctx context.Context, req *mcp.CallToolRequest, input dailyInput) (*mcp.CallToolResult, any, error) {
 		return s.toolDaily(ctx, input)
 	})
 
-	mcp.AddTool(s.server, &mcp.Tool{
+	addTool(s, &mcp.Tool{
 		Name:        "noted_daily_list",
 		Description: "List recent daily notes (last 30 days by default)",
 	}, func(ctx context.Context, req *mcp.CallToolRequest, input dailyListInput) (*mcp.CallToolResult, any, error) {
@@ -298,67 +543,74 @@ func (s *Server) registerTools() {
 
 	// --- Templates ---
 
-	mcp.AddTool(s.server, &mcp.Tool{
+	addTool(s, &mcp.Tool{
 		Name:        "noted_template_list",
 		Description: "List all note templates",
 	}, func(ctx context.Context, req *mcp.CallToolRequest, input templateListInput) (*mcp.CallToolResult, any, error) {
 		return s.toolTemplateList(ctx)
 	})
 
-	mcp.AddTool(s.server, &mcp.Tool{
+	addTool(s, &mcp.Tool{
 		Name:        "noted_template_create",
 		Description: "Create a new note template. Supports variables: {{date}}, {{time}}, {{datetime}}, {{title}}",
 	}, func(ctx context.Context, req *mcp.CallToolRequest, input templateCreateInput) (*mcp.CallToolResult, any, error) {
 		return s.toolTemplateCreate(ctx, input)
 	})
 
-	mcp.AddTool(s.server, &mcp.Tool{
+	addTool(s, &mcp.Tool{
 		Name:        "noted_template_get",
 		Description: "Get a template by name",
 	}, func(ctx context.Context, req *mcp.CallToolRequest, input templateGetInput) (*mcp.CallToolResult, any, error) {
 		return s.toolTemplateGet(ctx, input)
 	})
 
-	mcp.AddTool(s.server, &mcp.Tool{
+	addTool(s, &mcp.Tool{
 		Name:        "noted_template_delete",
 		Description: "Delete a template by name",
 	}, func(ctx context.Context, req *mcp.CallToolRequest, input templateDeleteInput) (*mcp.CallToolResult, any, error) {
 		return s.toolTemplateDelete(ctx, input)
 	})
 
-	mcp.AddTool(s.server, &mcp.Tool{
+	addTool(s, &mcp.Tool{
 		Name:        "noted_template_apply",
-		Description: "Apply a template to create a new note. Variables like {{date}}, {{title}} are interpolated.",
+		Description: "Apply a template to create a new note. Variables like {{date}}, {{title}} are interpolated; pass vars for custom placeholders like {{client}}.",
 	}, func(ctx context.Context, req *mcp.CallToolRequest, input templateApplyInput) (*mcp.CallToolResult, any, error) {
 		return s.toolTemplateApply(ctx, input)
 	})
 
 	// --- Task Extraction ---
 
-	mcp.AddTool(s.server, &mcp.Tool{
+	addTool(s, &mcp.Tool{
 		Name:        "noted_tasks",
 		Description: "Extract markdown tasks (checkboxes) from notes. Filter by note, tag, or completion status.",
 	}, func(ctx context.Context, req *mcp.CallToolRequest, input tasksInput) (*mcp.CallToolResult, any, error) {
 		return s.toolTasks(ctx, input)
 	})
 
+	addTool(s, &mcp.Tool{
+		Name:        "noted_task_done",
+		Description: "Check off (or, with undo, uncheck) a task checkbox in a note, by note ID and the task's index from noted_tasks",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, input taskDoneInput) (*mcp.CallToolResult, any, error) {
+		return s.toolTaskDone(ctx, input)
+	})
+
 	// --- Version History ---
 
-	mcp.AddTool(s.server, &mcp.Tool{
+	addTool(s, &mcp.Tool{
 		Name:        "noted_history",
 		Description: "List version history for a note",
 	}, func(ctx context.Context, req *mcp.CallToolRequest, input historyInput) (*mcp.CallToolResult, any, error) {
 		return s.toolHistory(ctx, input)
 	})
 
-	mcp.AddTool(s.server, &mcp.Tool{
+	addTool(s, &mcp.Tool{
 		Name:        "noted_version_get",
 		Description: "Get a specific version of a note",
 	}, func(ctx context.Context, req *mcp.CallToolRequest, input versionGetInput) (*mcp.CallToolResult, any, error) {
 		return s.toolVersionGet(ctx, input)
 	})
 
-	mcp.AddTool(s.server, &mcp.Tool{
+	addTool(s, &mcp.Tool{
 		Name:        "noted_restore",
 		Description: "Restore a note to a previous version. Saves current state as a new version first.",
 	}, func(ctx context.Context, req *mcp.CallToolRequest, input restoreInput) (*mcp.CallToolResult, any, error) {
@@ -367,7 +619,7 @@ func (s *Server) registerTools() {
 
 	// --- Random Note ---
 
-	mcp.AddTool(s.server, &mcp.Tool{
+	addTool(s, &mcp.Tool{
 		Name:        "noted_random",
 		Description: "Get a random note, optionally filtered by tag",
 	}, func(ctx context.Context, req *mcp.CallToolRequest, input randomInput) (*mcp.CallToolResult, any, error) {
@@ -376,31 +628,84 @@ func (s *Server) registerTools() {
 
 	// --- Link Health ---
 
-	mcp.AddTool(s.server, &mcp.Tool{
+	addTool(s, &mcp.Tool{
 		Name:        "noted_backlinks",
 		Description: "Get all notes that link to a given note (backlinks/incoming links)",
 	}, func(ctx context.Context, req *mcp.CallToolRequest, input backlinksInput) (*mcp.CallToolResult, any, error) {
 		return s.toolBacklinks(ctx, input)
 	})
 
-	mcp.AddTool(s.server, &mcp.Tool{
+	addTool(s, &mcp.Tool{
 		Name:        "noted_orphans",
 		Description: "Find orphan notes (no incoming or outgoing links) and dead-end notes (incoming but no outgoing)",
 	}, func(ctx context.Context, req *mcp.CallToolRequest, input linkHealthInput) (*mcp.CallToolResult, any, error) {
 		return s.toolOrphans(ctx)
 	})
+
+	addTool(s, &mcp.Tool{
+		Name:        "noted_unresolved_links",
+		Description: "Find [[wikilinks]] to titles no note has: notes referenced but never written, or renamed or deleted since, each with the notes linking to it",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, input unresolvedLinksInput) (*mcp.CallToolResult, any, error) {
+		return s.toolUnresolvedLinks(ctx, input)
+	})
+
+	addTool(s, &mcp.Tool{
+		Name:        "noted_mentions",
+		Description: "Get the notes that mention @name (e.g. for meeting prep), or without a name, everyone mentioned and in how many notes",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, input mentionsInput) (*mcp.CallToolResult, any, error) {
+		return s.toolMentions(ctx, input)
+	})
+
+	// --- Diagnostics ---
+
+	addTool(s, &mcp.Tool{
+		Name:        "noted_query_stats",
+		Description: "Per-query database latency since the server started (count, total, average, max in ms), slowest total first",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, input queryStatsInput) (*mcp.CallToolResult, any, error) {
+		return s.toolQueryStats(input)
+	})
 }
 
 // Tool implementations
 
 func (s *Server) toolCreate(ctx context.Context, input createInput) (*mcp.CallToolResult, any, error) {
+	input.Title, input.Content = s.sanitize.Title(input.Title), s.sanitize.Apply(input.Content)
 	if input.Title == "" {
 		return errorResult("title is required")
 	}
+	if input.Template != "" {
+		tmpl, err := s.queries.GetTemplateByName(ctx, input.Template)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return errorResult(fmt.Sprintf("template %q not found", input.Template))
+			}
+			return errorResult(fmt.Sprintf("failed to get template: %v", err))
+		}
+		content := interpolateTemplate(tmpl.Content, input.Title)
+		for name, value := range input.Vars {
+			content = strings.ReplaceAll(content, "{{"+name+"}}", value)
+		}
+		if input.Content != "" {
+			content += "\n" + input.Content
+		}
+		input.Content = content
+	}
 	if input.Content == "" {
 		return errorResult("content is required")
 	}
 
+	if err := s.allowCreate(); err != nil {
+		return errorResult(err.Error())
+	}
+	warnings, err := s.checkQuota(ctx, input.Content, true)
+	if err != nil {
+		return errorResult(err.Error())
+	}
+	folderID, err := s.createFolder(ctx, input)
+	if err != nil {
+		return errorResult(err.Error())
+	}
+
 	// Create the note
 	note, err := s.queries.CreateNote(ctx, db.CreateNoteParams{
 		Title:   input.Title,
@@ -410,6 +715,27 @@ func (s *Server) toolCreate(ctx context.Context, input createInput) (*mcp.CallTo
 		return errorResult(fmt.Sprintf("failed to create note: %v", err))
 	}
 
+	s.recordCreatedBy(ctx, note.ID)
+
+	if folderID != 0 {
+		if err := s.queries.MoveNoteToFolder(ctx, db.MoveNoteToFolderParams{
+			FolderID: sql.NullInt64{Int64: folderID, Valid: true},
+			ID:       note.ID,
+		}); err != nil {
+			return errorResult(fmt.Sprintf("failed to move note: %v", err))
+		}
+	}
+	if input.Pinned {
+		if err := s.queries.PinNote(ctx, note.ID); err != nil {
+			return errorResult(fmt.Sprintf("failed to pin note: %v", err))
+		}
+	}
+	if folderID != 0 || input.Pinned {
+		if note, err = s.queries.GetNote(ctx, note.ID); err != nil {
+			return errorResult(fmt.Sprintf("failed to get note: %v", err))
+		}
+	}
+
 	// Add tags if provided
 	for _, tagName := range input.Tags {
 		tag, err := s.queries.CreateTag(ctx, tagName)
@@ -421,20 +747,55 @@ func (s *Server) toolCreate(ctx context.Context, input createInput) (*mcp.CallTo
 			TagID:  tag.ID,
 		})
 	}
+	if len(input.Aliases) > 0 {
+		if err := notesync.SetAliases(ctx, s.queries, note, input.Aliases); err != nil {
+			return errorResult(err.Error())
+		}
+	}
 
 	// Sync to veclite if available
 	if s.syncer != nil {
-		_ = s.syncer.SyncNote(note.ID, note.Title, note.Content)
+		_ = memory.SyncNote(ctx, s.queries, s.syncer, note.ID, note.Title, note.Content)
 	}
-	notesync.WriteThrough(ctx, s.queries, s.vlt, note) // mirror to the markdown vault
+	s.writeThrough(ctx, note) // mirror to the markdown vault
 
-	return textResult(map[string]any{
+	result := map[string]any{
 		"id":      note.ID,
 		"title":   note.Title,
 		"status":  "created",
 		"tags":    input.Tags,
 		"message": fmt.Sprintf("Note #%d created successfully", note.ID),
-	})
+	}
+	if folderID != 0 {
+		result["folder_id"] = folderID
+	}
+	if input.Pinned {
+		result["pinned"] = true
+	}
+	if len(warnings) > 0 {
+		result["warnings"] = warnings
+	}
+	return textResult(result)
+}
+
+// createFolder returns the folder a new note goes in, from its folder_id or its folder path
+// (created as needed), or 0 for none.
+func (s *Server) createFolder(ctx context.Context, input createInput) (int64, error) {
+	switch {
+	case input.FolderID != 0 && input.Folder != "":
+		return 0, fmt.Errorf("give folder_id or folder, not both")
+	case input.FolderID != 0:
+		if _, err := s.queries.GetFolder(ctx, input.FolderID); err != nil {
+			if err == sql.ErrNoRows {
+				return 0, fmt.Errorf("folder #%d not found", input.FolderID)
+			}
+			return 0, fmt.Errorf("failed to get folder: %w", err)
+		}
+		return input.FolderID, nil
+	case input.Folder != "":
+		return notesync.EnsureFolderPath(ctx, s.queries, input.Folder)
+	}
+	return 0, nil
 }
 
 func (s *Server) toolList(ctx context.Context, input listInput) (*mcp.CallToolResult, any, error) {
@@ -443,18 +804,60 @@ func (s *Server) toolList(ctx context.Context, input listInput) (*mcp.CallToolRe
 		limit = 20
 	}
 
+	offset := max(input.Offset, 0)
+	lang := strings.ToLower(input.Lang)
+	ascending, err := db.ParseNoteSort(input.Sort, input.Order)
+	if err != nil {
+		return errorResult(err.Error())
+	}
+	now := time.Now()
+	since, err := memory.ParseTimeBound(input.ModifiedSince, now, false)
+	if err != nil {
+		return errorResult(fmt.Sprintf("invalid modified_since: %v", err))
+	}
+	until, err := memory.ParseTimeBound(input.ModifiedUntil, now, true)
+	if err != nil {
+		return errorResult(fmt.Sprintf("invalid modified_until: %v", err))
+	}
+	updatedSince := sql.NullTime{Time: since.UTC(), Valid: !since.IsZero()}
+	updatedBefore := sql.NullTime{Time: until.UTC(), Valid: !until.IsZero()}
+
 	var notes []db.Note
-	var err error
+	var total int64
 
-	if input.Tag != "" {
-		// Filter by tag
-		notes, err = s.queries.GetNotesByTagName(ctx, input.Tag)
+	if input.Tag != "" || input.FolderID != 0 {
+		// Filter by tag or folder, paginating the filtered list
+		notes, err = s.listFiltered(ctx, input)
+		if !input.Archived {
+			notes = slices.DeleteFunc(notes, func(n db.Note) bool { return n.ArchivedAt.Valid })
+		}
+		if lang != "" {
+			notes = slices.DeleteFunc(notes, func(n db.Note) bool { return n.Lang.String != lang })
+		}
+		notes = slices.DeleteFunc(notes, func(n db.Note) bool { return !db.UpdatedBetween(n, updatedSince, updatedBefore) })
+		db.SortNotes(notes, input.Sort, ascending)
+		total = int64(len(notes))
+		notes = notes[min(offset, len(notes)):min(offset+limit, len(notes))]
 	} else {
 		// List all with pagination
 		notes, err = s.queries.ListNotes(ctx, db.ListNotesParams{
-			Limit:  int64(limit),
-			Offset: int64(input.Offset),
+			Sort:            input.Sort,
+			Ascending:       ascending,
+			IncludeArchived: input.Archived,
+			Lang:            lang,
+			UpdatedSince:    updatedSince,
+			UpdatedBefore:   updatedBefore,
+			Limit:           int64(limit),
+			Offset:          int64(offset),
 		})
+		if err == nil {
+			total, err = s.queries.CountListedNotes(ctx, db.CountListedNotesParams{
+				IncludeArchived: input.Archived,
+				Lang:            lang,
+				UpdatedSince:    updatedSince,
+				UpdatedBefore:   updatedBefore,
+			})
+		}
 	}
 
 	if err != nil {
@@ -475,10 +878,48 @@ func (s *Server) toolList(ctx context.Context, input listInput) (*mcp.CallToolRe
 		}
 	}
 
-	return textResult(map[string]any{
-		"count": len(output),
-		"notes": output,
-	})
+	result := map[string]any{
+		"count":    len(output),
+		"notes":    output,
+		"total":    total,
+		"limit":    limit,
+		"offset":   offset,
+		"has_more": int64(offset+len(output)) < total,
+	}
+	// Offsets to pass back for the neighbouring pages
+	if int64(offset+len(output)) < total {
+		result["next_offset"] = offset + len(output)
+	}
+	if offset > 0 {
+		result["prev_offset"] = max(offset-limit, 0)
+	}
+	return textResult(result)
+}
+
+// listFiltered returns the notes noted_list's tag and folder filters select, newest first.
+func (s *Server) listFiltered(ctx context.Context, input listInput) ([]db.Note, error) {
+	var notes []db.Note
+	var err error
+	switch {
+	case input.FolderID != 0 && input.IncludeSubfolders:
+		notes, err = s.queries.GetNotesInFolderTree(ctx, input.FolderID)
+	case input.FolderID != 0:
+		notes, err = s.queries.GetNotesByFolder(ctx, sql.NullInt64{Int64: input.FolderID, Valid: true})
+	default:
+		return s.queries.GetNotesByTagName(ctx, input.Tag)
+	}
+	if err != nil || input.Tag == "" {
+		return notes, err
+	}
+	tagged, err := s.queries.GetNotesByTagName(ctx, input.Tag)
+	if err != nil {
+		return nil, err
+	}
+	ids := make(map[int64]bool, len(tagged))
+	for _, n := range tagged {
+		ids[n.ID] = true
+	}
+	return slices.DeleteFunc(notes, func(n db.Note) bool { return !ids[n.ID] }), nil
 }
 
 func (s *Server) toolGet(ctx context.Context, input getInput) (*mcp.CallToolResult, any, error) {
@@ -500,6 +941,7 @@ func (s *Server) toolGet(ctx context.Context, input getInput) (*mcp.CallToolResu
 			output.Tags[i] = t.Name
 		}
 	}
+	output.Aliases, _ = s.queries.GetNoteAliases(ctx, note.ID)
 
 	return textResult(output)
 }
@@ -514,28 +956,30 @@ func (s *Server) toolSearch(ctx context.Context, input searchInput) (*mcp.CallTo
 		limit = 20
 	}
 
-	// Try FTS5 first, fall back to LIKE
-	var notes []db.Note
-	var err error
-	if db.FTSAvailable(ctx, s.conn) {
-		notes, err = db.SearchNotesFTS(ctx, s.conn, input.Query, int64(limit))
-	}
-	if notes == nil || err != nil {
-		pattern := "%" + input.Query + "%"
-		notes, err = s.queries.SearchNotesContent(ctx, db.SearchNotesContentParams{
-			Content: pattern,
-			Title:   pattern,
-			Limit:   int64(limit),
-		})
+	// Titles, content (FTS5, falling back to LIKE) and tag names, ranked per field
+	search := db.SearchNotes
+	if input.Archived {
+		search = db.SearchNotesArchived
 	}
+	results, err := search(ctx, s.conn, input.Query, int64(limit))
 	if err != nil {
 		return errorResult(fmt.Sprintf("search failed: %v", err))
 	}
 
 	// Format output
-	output := make([]noteOutput, len(notes))
-	for i, note := range notes {
-		output[i] = formatNote(note)
+	output := make([]searchOutput, len(results))
+	for i, r := range results {
+		output[i] = searchOutput{noteOutput: formatNote(r.Note), Score: r.Score, MatchedTags: r.MatchedTags}
+		output[i].Tags = r.Tags
+		if input.FullContent {
+			output[i].Content = r.Note.Content
+		}
+		for _, sn := range r.Snippets {
+			output[i].Snippets = append(output[i].Snippets, searchSnippet{Snippet: sn, HTML: sn.HTML()})
+		}
+	}
+	if s.searchHistory {
+		_ = db.LogSearch(ctx, s.queries, input.Query, "mcp", len(output)) // best-effort
 	}
 
 	return textResult(map[string]any{
@@ -546,6 +990,8 @@ func (s *Server) toolSearch(ctx context.Context, input searchInput) (*mcp.CallTo
 }
 
 func (s *Server) toolUpdate(ctx context.Context, input updateInput) (*mcp.CallToolResult, any, error) {
+	input.Title, input.Content = s.sanitize.Title(input.Title), s.sanitize.Apply(input.Content)
+
 	// Get existing note
 	existing, err := s.queries.GetNote(ctx, input.ID)
 	if err != nil {
@@ -564,6 +1010,20 @@ func (s *Server) toolUpdate(ctx context.Context, input updateInput) (*mcp.CallTo
 	if input.Content != "" {
 		content = input.Content
 	}
+	var warnings []string
+	if content != existing.Content {
+		if warnings, err = s.checkQuota(ctx, content, false); err != nil {
+			return errorResult(err.Error())
+		}
+	}
+	var expiresAt sql.NullTime
+	if input.TTL != nil && *input.TTL != "" {
+		ttl, err := parseDuration(*input.TTL)
+		if err != nil {
+			return errorResult(fmt.Sprintf("invalid TTL: %v", err))
+		}
+		expiresAt = sql.NullTime{Time: time.Now().Add(ttl), Valid: true}
+	}
 
 	// Snapshot the pre-edit state as a version before overwriting it (only when it changed), so
 	// agent edits build the same history as the CLI and TUI. Abort on failure rather than silently
@@ -601,70 +1061,343 @@ func (s *Server) toolUpdate(ctx context.Context, input updateInput) (*mcp.CallTo
 			})
 		}
 	}
+	if input.Aliases != nil {
+		if err := notesync.SetAliases(ctx, s.queries, note, input.Aliases); err != nil {
+			return errorResult(err.Error())
+		}
+	}
+	if input.Folder != nil {
+		folder := sql.NullInt64{Int64: *input.Folder, Valid: *input.Folder != 0}
+		if err := s.queries.MoveNoteToFolder(ctx, db.MoveNoteToFolderParams{FolderID: folder, ID: note.ID}); err != nil {
+			return errorResult(fmt.Sprintf("failed to move note: %v", err))
+		}
+	}
+	if input.Pinned != nil {
+		pin := s.queries.UnpinNote
+		if *input.Pinned {
+			pin = s.queries.PinNote
+		}
+		if err := pin(ctx, note.ID); err != nil {
+			return errorResult(fmt.Sprintf("failed to pin note: %v", err))
+		}
+	}
+	if input.Source != nil || input.SourceRef != nil {
+		params := db.UpdateNoteSourceParams{ID: note.ID, Source: existing.Source, SourceRef: existing.SourceRef}
+		if input.Source != nil {
+			params.Source = sql.NullString{String: *input.Source, Valid: *input.Source != ""}
+		}
+		if input.SourceRef != nil {
+			params.SourceRef = sql.NullString{String: *input.SourceRef, Valid: *input.SourceRef != ""}
+		}
+		if err := s.queries.UpdateNoteSource(ctx, params); err != nil {
+			return errorResult(fmt.Sprintf("failed to set source: %v", err))
+		}
+	}
+	if input.TTL != nil {
+		if err := s.queries.SetNoteExpiry(ctx, db.SetNoteExpiryParams{ExpiresAt: expiresAt, ID: note.ID}); err != nil {
+			return errorResult(fmt.Sprintf("failed to set expiry: %v", err))
+		}
+	}
+
+	// Sync to veclite if available
+	if s.syncer != nil {
+		_ = memory.SyncNote(ctx, s.queries, s.syncer, note.ID, note.Title, note.Content)
+	}
+	if updated, err := s.queries.GetNote(ctx, note.ID); err == nil {
+		s.writeThrough(ctx, updated) // mirror to the markdown vault
+	}
+
+	result := map[string]any{
+		"id":      note.ID,
+		"title":   note.Title,
+		"status":  "updated",
+		"message": fmt.Sprintf("Note #%d updated successfully", note.ID),
+	}
+	if len(warnings) > 0 {
+		result["warnings"] = warnings
+	}
+	return textResult(result)
+}
+
+func (s *Server) toolDelete(ctx context.Context, input deleteInput) (*mcp.CallToolResult, any, error) {
+	// Verify note exists
+	_, err := s.queries.GetNote(ctx, input.ID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return errorResult(fmt.Sprintf("note #%d not found", input.ID))
+		}
+		return errorResult(fmt.Sprintf("failed to get note: %v", err))
+	}
+
+	// Move to the trash; tags, links, and history stay for a restore
+	if err := notesync.Trash(ctx, s.queries, s.vlt, input.ID); err != nil {
+		return errorResult(fmt.Sprintf("failed to delete note: %v", err))
+	}
+	if s.syncer != nil {
+		_ = s.syncer.Delete(input.ID)
+	}
+
+	return textResult(map[string]any{
+		"id":      input.ID,
+		"status":  "trashed",
+		"message": fmt.Sprintf("Note #%d moved to the trash (restore with noted_trash_restore)", input.ID),
+	})
+}
+
+func (s *Server) toolOutline(ctx context.Context, input outlineInput) (*mcp.CallToolResult, any, error) {
+	note, err := s.queries.GetNote(ctx, input.ID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return errorResult(fmt.Sprintf("note #%d not found", input.ID))
+		}
+		return errorResult(fmt.Sprintf("failed to get note: %v", err))
+	}
+
+	outline := markdown.Outline(note.Content)
+	if outline == nil {
+		outline = []markdown.OutlineNode{}
+	}
+	return textResult(map[string]any{
+		"id":      note.ID,
+		"title":   note.Title,
+		"outline": outline,
+	})
+}
+
+func (s *Server) toolGetSection(ctx context.Context, input getSectionInput) (*mcp.CallToolResult, any, error) {
+	note, err := s.queries.GetNote(ctx, input.ID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return errorResult(fmt.Sprintf("note #%d not found", input.ID))
+		}
+		return errorResult(fmt.Sprintf("failed to get note: %v", err))
+	}
+
+	section, ok := markdown.Section(note.Content, input.Section)
+	if !ok {
+		return errorResult(fmt.Sprintf("note #%d has no section %q (use noted_outline to list its headings)", note.ID, input.Section))
+	}
+	return textResult(map[string]any{
+		"id":      note.ID,
+		"title":   note.Title,
+		"section": input.Section,
+		"content": section,
+	})
+}
+
+func (s *Server) toolUpdateSection(ctx context.Context, input updateSectionInput) (*mcp.CallToolResult, any, error) {
+	existing, err := s.queries.GetNote(ctx, input.ID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return errorResult(fmt.Sprintf("note #%d not found", input.ID))
+		}
+		return errorResult(fmt.Sprintf("failed to get note: %v", err))
+	}
+
+	edit := markdown.ReplaceSection
+	if input.Append {
+		edit = markdown.AppendToSection
+	}
+	content, ok := edit(existing.Content, input.Section, s.sanitize.Apply(input.Content))
+	if !ok {
+		return errorResult(fmt.Sprintf("note #%d has no section %q (use noted_outline to list its headings)", existing.ID, input.Section))
+	}
+
+	var warnings []string
+	if content != existing.Content {
+		if warnings, err = s.checkQuota(ctx, content, false); err != nil {
+			return errorResult(err.Error())
+		}
+		if err := notesync.SnapshotVersion(ctx, s.queries, s.vlt, existing.ID, existing.Title, existing.Content); err != nil {
+			return errorResult(fmt.Sprintf("failed to save version: %v", err))
+		}
+		note, err := s.queries.UpdateNote(ctx, db.UpdateNoteParams{
+			ID:      existing.ID,
+			Title:   existing.Title,
+			Content: content,
+		})
+		if err != nil {
+			return errorResult(fmt.Sprintf("failed to update note: %v", err))
+		}
+		if s.syncer != nil {
+			_ = memory.SyncNote(ctx, s.queries, s.syncer, note.ID, note.Title, note.Content)
+		}
+		s.writeThrough(ctx, note)
+	}
+
+	result := map[string]any{
+		"id":      existing.ID,
+		"title":   existing.Title,
+		"section": input.Section,
+		"status":  "updated",
+		"message": fmt.Sprintf("Section %q of note #%d updated", input.Section, existing.ID),
+	}
+	if len(warnings) > 0 {
+		result["warnings"] = warnings
+	}
+	return textResult(result)
+}
+
+func (s *Server) toolArchive(ctx context.Context, input archiveInput) (*mcp.CallToolResult, any, error) {
+	note, err := notesync.SetArchived(ctx, s.queries, s.vlt, input.ID, !input.Unarchive)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return errorResult(fmt.Sprintf("note #%d not found", input.ID))
+		}
+		return errorResult(fmt.Sprintf("failed to archive note: %v", err))
+	}
+
+	status, message := "archived", fmt.Sprintf("Note #%d archived (hidden from default lists and search)", note.ID)
+	if input.Unarchive {
+		status, message = "unarchived", fmt.Sprintf("Note #%d unarchived", note.ID)
+	}
+	return textResult(map[string]any{
+		"id":      note.ID,
+		"title":   note.Title,
+		"status":  status,
+		"message": message,
+	})
+}
+
+func (s *Server) toolTrashList(ctx context.Context) (*mcp.CallToolResult, any, error) {
+	notes, err := s.queries.ListTrashedNotes(ctx)
+	if err != nil {
+		return errorResult(fmt.Sprintf("failed to list trash: %v", err))
+	}
+
+	type trashItem struct {
+		ID        int64  `json:"id"`
+		Title     string `json:"title"`
+		DeletedAt string `json:"deleted_at"`
+	}
+	items := make([]trashItem, len(notes))
+	for i, n := range notes {
+		items[i] = trashItem{ID: n.ID, Title: n.Title, DeletedAt: n.DeletedAt.Time.Format(time.RFC3339)}
+	}
+	return textResult(map[string]any{
+		"notes": items,
+		"count": len(items),
+	})
+}
+
+func (s *Server) toolTrashRestore(ctx context.Context, input trashRestoreInput) (*mcp.CallToolResult, any, error) {
+	note, err := notesync.Restore(ctx, s.queries, s.vlt, input.ID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return errorResult(fmt.Sprintf("note #%d is not in the trash", input.ID))
+		}
+		return errorResult(fmt.Sprintf("failed to restore note: %v", err))
+	}
+	if s.syncer != nil {
+		_ = memory.SyncNote(ctx, s.queries, s.syncer, note.ID, note.Title, note.Content)
+	}
+
+	return textResult(map[string]any{
+		"id":      note.ID,
+		"title":   note.Title,
+		"status":  "restored",
+		"message": fmt.Sprintf("Note #%d restored from the trash", note.ID),
+	})
+}
+
+func (s *Server) toolTags(ctx context.Context) (*mcp.CallToolResult, any, error) {
+	tags, err := s.queries.GetTagsWithCount(ctx)
+	if err != nil {
+		return errorResult(fmt.Sprintf("failed to list tags: %v", err))
+	}
+
+	output := make([]tagOutput, len(tags))
+	for i, t := range tags {
+		output[i] = tagOutput{
+			ID:          t.ID,
+			Name:        t.Name,
+			NoteCount:   t.NoteCount,
+			Color:       t.Color,
+			Description: t.Description,
+		}
+	}
+
+	return textResult(map[string]any{
+		"count": len(output),
+		"tags":  output,
+	})
+}
+
+// writeThroughIDs mirrors notes whose tags changed in bulk to the vault.
+func (s *Server) writeThroughIDs(ctx context.Context, ids []int64) {
+	if s.vlt == nil {
+		return
+	}
+	for _, id := range ids {
+		if note, err := s.queries.GetNote(ctx, id); err == nil {
+			s.writeThrough(ctx, note)
+		}
+	}
+}
 
-	// Sync to veclite if available
-	if s.syncer != nil {
-		_ = s.syncer.SyncNote(note.ID, note.Title, note.Content)
+func (s *Server) toolTagRename(ctx context.Context, input tagRenameInput) (*mcp.CallToolResult, any, error) {
+	if input.Name == "" || input.NewName == "" {
+		return errorResult("name and new_name are required")
 	}
-	if updated, err := s.queries.GetNote(ctx, note.ID); err == nil {
-		notesync.WriteThrough(ctx, s.queries, s.vlt, updated) // mirror to the markdown vault
+
+	result, err := db.RenameTagByName(ctx, s.conn, input.Name, input.NewName)
+	if err != nil {
+		return errorResult(fmt.Sprintf("failed to rename tag: %v", err))
 	}
+	s.writeThroughIDs(ctx, result.NoteIDs)
 
 	return textResult(map[string]any{
-		"id":      note.ID,
-		"title":   note.Title,
-		"status":  "updated",
-		"message": fmt.Sprintf("Note #%d updated successfully", note.ID),
+		"name":       input.Name,
+		"new_name":   strings.TrimSpace(input.NewName),
+		"merged":     result.Merged,
+		"note_count": len(result.NoteIDs),
 	})
 }
 
-func (s *Server) toolDelete(ctx context.Context, input deleteInput) (*mcp.CallToolResult, any, error) {
-	// Verify note exists
-	_, err := s.queries.GetNote(ctx, input.ID)
-	if err != nil {
-		if err == sql.ErrNoRows {
-			return errorResult(fmt.Sprintf("note #%d not found", input.ID))
-		}
-		return errorResult(fmt.Sprintf("failed to get note: %v", err))
-	}
-
-	// Delete from veclite first if available
-	if s.syncer != nil {
-		_ = s.syncer.Delete(input.ID)
+func (s *Server) toolTagDelete(ctx context.Context, input tagDeleteInput) (*mcp.CallToolResult, any, error) {
+	if input.Name == "" {
+		return errorResult("name is required")
 	}
 
-	// Delete the note (cascades to note_tags)
-	err = s.queries.DeleteNote(ctx, input.ID)
+	noteIDs, err := db.DeleteTagByName(ctx, s.conn, input.Name)
 	if err != nil {
-		return errorResult(fmt.Sprintf("failed to delete note: %v", err))
+		return errorResult(fmt.Sprintf("failed to delete tag: %v", err))
 	}
-	notesync.Delete(s.vlt, input.ID) // remove the note's .md (and version snapshots) from the vault
+	s.writeThroughIDs(ctx, noteIDs)
 
 	return textResult(map[string]any{
-		"id":      input.ID,
-		"status":  "deleted",
-		"message": fmt.Sprintf("Note #%d deleted successfully", input.ID),
+		"name":       input.Name,
+		"status":     "deleted",
+		"note_count": len(noteIDs),
 	})
 }
 
-func (s *Server) toolTags(ctx context.Context) (*mcp.CallToolResult, any, error) {
-	tags, err := s.queries.GetTagsWithCount(ctx)
+func (s *Server) toolTagCleanup(ctx context.Context, input tagCleanupInput) (*mcp.CallToolResult, any, error) {
+	unused, err := s.queries.GetUnusedTags(ctx)
 	if err != nil {
-		return errorResult(fmt.Sprintf("failed to list tags: %v", err))
+		return errorResult(fmt.Sprintf("failed to find unused tags: %v", err))
+	}
+	names := make([]string, len(unused))
+	for i, t := range unused {
+		names[i] = t.Name
 	}
 
-	output := make([]tagOutput, len(tags))
-	for i, t := range tags {
-		output[i] = tagOutput{
-			ID:        t.ID,
-			Name:      t.Name,
-			NoteCount: t.NoteCount,
-		}
+	if input.DryRun {
+		return textResult(map[string]any{
+			"dry_run":      true,
+			"would_delete": len(names),
+			"tags":         names,
+		})
 	}
 
+	deleted, err := s.queries.DeleteUnusedTags(ctx)
+	if err != nil {
+		return errorResult(fmt.Sprintf("failed to delete unused tags: %v", err))
+	}
 	return textResult(map[string]any{
-		"count": len(output),
-		"tags":  output,
+		"dry_run": false,
+		"deleted": deleted,
+		"tags":    names,
 	})
 }
 
@@ -683,6 +1416,9 @@ func (s *Server) toolSemanticSearch(ctx context.Context, input semanticSearchInp
 	}
 
 	results, err := s.syncer.Search(input.Query, limit)
+	if errors.Is(err, embeddings.ErrUnavailable) {
+		return s.keywordFallback(ctx, input.Query, limit, err)
+	}
 	if err != nil {
 		return errorResult(fmt.Sprintf("semantic search failed: %v", err))
 	}
@@ -716,18 +1452,53 @@ func (s *Server) toolSemanticSearch(ctx context.Context, input semanticSearchInp
 
 	return textResult(map[string]any{
 		"query":   input.Query,
+		"method":  "semantic",
 		"count":   len(output),
 		"results": output,
 	})
 }
 
+// keywordFallback answers noted_semantic_search with keyword results while the embedder is
+// unavailable, in the same shape as semantic results.
+func (s *Server) keywordFallback(ctx context.Context, query string, limit int, cause error) (*mcp.CallToolResult, any, error) {
+	results, err := db.SearchNotes(ctx, s.conn, query, int64(limit))
+	if err != nil {
+		return errorResult(fmt.Sprintf("search failed: %v", err))
+	}
+	output := make([]map[string]any, 0, len(results))
+	for _, r := range results {
+		output = append(output, map[string]any{
+			"id":      r.Note.ID,
+			"title":   r.Note.Title,
+			"content": r.Note.Content,
+			"tags":    r.Tags,
+			"score":   r.Score,
+		})
+	}
+	return textResult(map[string]any{
+		"query":    query,
+		"method":   "keyword",
+		"fallback": cause.Error(),
+		"count":    len(output),
+		"results":  output,
+	})
+}
+
 // Helper functions
 
 func formatNote(note db.Note) noteOutput {
 	out := noteOutput{
-		ID:      note.ID,
-		Title:   note.Title,
-		Content: note.Content,
+		ID:        note.ID,
+		Title:     note.Title,
+		Content:   note.Content,
+		CreatedBy: note.CreatedBy.String,
+		Archived:  note.ArchivedAt.Valid,
+		Lang:      note.Lang.String,
+		Source:    note.Source.String,
+		SourceRef: note.SourceRef.String,
+	}
+	if note.ExpiresAt.Valid {
+		out.ExpiresAt = note.ExpiresAt.Time.Format(time.RFC3339)
 	}
 	if note.CreatedAt.Valid {
 		out.CreatedAt = note.CreatedAt.Time.Format(time.RFC3339)
@@ -759,6 +1530,7 @@ func errorResult(msg string) (*mcp.CallToolResult, any, error) {
 // Memory tool implementations
 
 func (s *Server) toolRemember(ctx context.Context, input rememberInput) (*mcp.CallToolResult, any, error) {
+	input.Title, input.Content = s.sanitize.Title(input.Title), s.sanitize.Apply(input.Content)
 	if input.Content == "" {
 		return errorResult("content is required")
 	}
@@ -781,6 +1553,14 @@ func (s *Server) toolRemember(ctx context.Context, input rememberInput) (*mcp.Ca
 		}
 	}
 
+	if err := s.allowCreate(); err != nil {
+		return errorResult(err.Error())
+	}
+	warnings, err := s.checkQuota(ctx, input.Content, true)
+	if err != nil {
+		return errorResult(err.Error())
+	}
+
 	mem, err := memory.Remember(ctx, s.queries, syncer, memory.RememberInput{
 		Content:    input.Content,
 		Title:      input.Title,
@@ -793,6 +1573,7 @@ func (s *Server) toolRemember(ctx context.Context, input rememberInput) (*mcp.Ca
 	if err != nil {
 		return errorResult(fmt.Sprintf("failed to create memory: %v", err))
 	}
+	s.recordCreatedBy(ctx, mem.ID)
 
 	result := map[string]any{
 		"id":         mem.ID,
@@ -812,6 +1593,9 @@ func (s *Server) toolRemember(ctx context.Context, input rememberInput) (*mcp.Ca
 	if mem.SourceRef != "" {
 		result["source_ref"] = mem.SourceRef
 	}
+	if len(warnings) > 0 {
+		result["warnings"] = warnings
+	}
 
 	return textResult(result)
 }
@@ -834,6 +1618,9 @@ func (s *Server) toolRecall(ctx context.Context, input recallInput) (*mcp.CallTo
 	if input.Query == "" {
 		return errorResult("query is required")
 	}
+	if input.Format != "" && input.Format != "json" && input.Format != "context" {
+		return errorResult(fmt.Sprintf("invalid format %q (valid: json, context)", input.Format))
+	}
 
 	// Get veclite syncer (may be nil)
 	var syncer *veclite.Syncer
@@ -843,40 +1630,40 @@ func (s *Server) toolRecall(ctx context.Context, input recallInput) (*mcp.CallTo
 		}
 	}
 
+	now := time.Now()
+	since, err := memory.ParseTimeBound(input.Since, now, false)
+	if err != nil {
+		return errorResult(fmt.Sprintf("invalid since: %v", err))
+	}
+	until, err := memory.ParseTimeBound(input.Until, now, true)
+	if err != nil {
+		return errorResult(fmt.Sprintf("invalid until: %v", err))
+	}
+
 	result, err := memory.Recall(ctx, s.queries, s.conn, syncer, memory.RecallInput{
 		Query:       input.Query,
 		Limit:       input.Limit,
 		Category:    input.Category,
+		Source:      input.Source,
+		Since:       since,
+		Until:       until,
 		UseSemantic: syncer != nil, // Use semantic search if available
 	})
 	if err != nil {
 		return errorResult(fmt.Sprintf("recall failed: %v", err))
 	}
 
-	// Convert memories to output format
+	if input.Format == "context" {
+		text := memory.FormatContext(result.Memories)
+		if text == "" {
+			text = "No relevant memories found."
+		}
+		return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: text}}}, nil, nil
+	}
+
 	output := make([]map[string]any, len(result.Memories))
 	for i, mem := range result.Memories {
-		m := map[string]any{
-			"id":         mem.ID,
-			"title":      mem.Title,
-			"content":    mem.Content,
-			"category":   mem.Category,
-			"importance": mem.Importance,
-			"tags":       mem.Tags,
-		}
-		if mem.Score > 0 {
-			m["score"] = mem.Score
-		}
-		if !mem.ExpiresAt.IsZero() {
-			m["expires_at"] = mem.ExpiresAt.Format(time.RFC3339)
-		}
-		if mem.Source != "" {
-			m["source"] = mem.Source
-		}
-		if mem.SourceRef != "" {
-			m["source_ref"] = mem.SourceRef
-		}
-		output[i] = m
+		output[i] = memoryOutput(mem)
 	}
 
 	return textResult(map[string]any{
@@ -887,6 +1674,102 @@ func (s *Server) toolRecall(ctx context.Context, input recallInput) (*mcp.CallTo
 	})
 }
 
+func (s *Server) toolRecallBatch(ctx context.Context, input recallBatchInput) (*mcp.CallToolResult, any, error) {
+	if len(input.Queries) == 0 {
+		return errorResult("queries is required")
+	}
+
+	var syncer *veclite.Syncer
+	if s.syncer != nil {
+		if vs, ok := s.syncer.(*veclite.Syncer); ok {
+			syncer = vs
+		}
+	}
+
+	now := time.Now()
+	since, err := memory.ParseTimeBound(input.Since, now, false)
+	if err != nil {
+		return errorResult(fmt.Sprintf("invalid since: %v", err))
+	}
+	until, err := memory.ParseTimeBound(input.Until, now, true)
+	if err != nil {
+		return errorResult(fmt.Sprintf("invalid until: %v", err))
+	}
+
+	seen := make(map[int64]bool)
+	groups := make([]map[string]any, 0, len(input.Queries))
+	total := 0
+	for _, query := range input.Queries {
+		if strings.TrimSpace(query) == "" {
+			continue
+		}
+		result, err := memory.Recall(ctx, s.queries, s.conn, syncer, memory.RecallInput{
+			Query:       query,
+			Limit:       input.Limit,
+			Category:    input.Category,
+			Source:      input.Source,
+			Since:       since,
+			Until:       until,
+			UseSemantic: syncer != nil,
+		})
+		if err != nil {
+			return errorResult(fmt.Sprintf("recall failed for %q: %v", query, err))
+		}
+
+		memories := []map[string]any{}
+		also := []int64{}
+		for _, mem := range result.Memories {
+			if seen[mem.ID] {
+				also = append(also, mem.ID)
+				continue
+			}
+			seen[mem.ID] = true
+			memories = append(memories, memoryOutput(mem))
+		}
+		total += len(memories)
+		groups = append(groups, map[string]any{
+			"query":        result.Query,
+			"method":       result.Method,
+			"count":        len(memories),
+			"memories":     memories,
+			"also_matched": also,
+		})
+	}
+
+	return textResult(map[string]any{
+		"results": groups,
+		"count":   total,
+	})
+}
+
+// memoryOutput is a recalled memory as the recall tools return it.
+func memoryOutput(mem memory.Memory) map[string]any {
+	m := map[string]any{
+		"id":         mem.ID,
+		"title":      mem.Title,
+		"content":    mem.Content,
+		"category":   mem.Category,
+		"importance": mem.Importance,
+		"tags":       mem.Tags,
+	}
+	if mem.Score > 0 {
+		m["score"] = mem.Score
+	}
+	if !mem.ExpiresAt.IsZero() {
+		m["expires_at"] = mem.ExpiresAt.Format(time.RFC3339)
+	}
+	if mem.Source != "" {
+		m["source"] = mem.Source
+	}
+	if mem.SourceRef != "" {
+		m["source_ref"] = mem.SourceRef
+	}
+	if mem.CreatedBy != "" {
+		m["created_by"] = mem.CreatedBy
+	}
+	return m
+}
+
 func (s *Server) toolForget(ctx context.Context, input forgetInput) (*mcp.CallToolResult, any, error) {
 	// Get veclite syncer (may be nil)
 	var syncer *veclite.Syncer
@@ -896,12 +1779,30 @@ func (s *Server) toolForget(ctx context.Context, input forgetInput) (*mcp.CallTo
 		}
 	}
 
-	result, err := memory.Forget(ctx, s.queries, syncer, memory.ForgetInput{
+	criteria := memory.ForgetInput{
 		OlderThanDays:   input.OlderThanDays,
 		ImportanceBelow: input.ImportanceBelow,
 		Category:        input.Category,
+		Source:          input.Source,
+		TagPrefix:       input.TagPrefix,
 		DryRun:          input.DryRun,
-	})
+	}
+
+	// Refuse oversized deletions up front rather than deleting part of them.
+	if max := s.limits.MaxForgetPerCall; max > 0 && !input.DryRun {
+		preview := criteria
+		preview.DryRun = true
+		planned, err := memory.Forget(ctx, s.queries, syncer, preview)
+		if err != nil {
+			return errorResult(fmt.Sprintf("forget failed: %v", err))
+		}
+		if planned.WouldDelete > max {
+			return errorResult(fmt.Sprintf("forget would delete %d memories, more than the limit of %d per call (NOTED_MCP_MAX_FORGET); narrow the criteria or preview with dry_run",
+				planned.WouldDelete, max))
+		}
+	}
+
+	result, err := memory.Forget(ctx, s.queries, syncer, criteria)
 	if err != nil {
 		return errorResult(fmt.Sprintf("forget failed: %v", err))
 	}
@@ -926,6 +1827,8 @@ func (s *Server) toolForget(ctx context.Context, input forgetInput) (*mcp.CallTo
 				"older_than_days":  input.OlderThanDays,
 				"importance_below": input.ImportanceBelow,
 				"category":         input.Category,
+				"source":           input.Source,
+				"tag_prefix":       input.TagPrefix,
 			},
 		})
 	}
@@ -937,9 +1840,33 @@ func (s *Server) toolForget(ctx context.Context, input forgetInput) (*mcp.CallTo
 	})
 }
 
+func (s *Server) toolMemoryAdjust(ctx context.Context, input memoryAdjustInput) (*mcp.CallToolResult, any, error) {
+	if input.ImportanceDelta == 0 && input.Category == "" {
+		return errorResult("importance_delta or category is required")
+	}
+	mem, err := memory.Adjust(ctx, s.queries, s.conn, memory.AdjustInput{
+		ID:       input.ID,
+		Delta:    input.ImportanceDelta,
+		Category: input.Category,
+	})
+	if err != nil {
+		return errorResult(fmt.Sprintf("adjust failed: %v", err))
+	}
+	s.writeThroughIDs(ctx, []int64{mem.ID})
+
+	return textResult(map[string]any{
+		"id":         mem.ID,
+		"title":      mem.Title,
+		"category":   mem.Category,
+		"importance": mem.Importance,
+		"status":     "adjusted",
+	})
+}
+
 // --- Daily Notes tool implementations ---
 
 func (s *Server) toolDaily(ctx context.Context, input dailyInput) (*mcp.CallToolResult, any, error) {
+	input.Append, input.Prepend = s.sanitize.Apply(input.Append), s.sanitize.Apply(input.Prepend)
 	targetDate := time.Now()
 	if input.Date != "" {
 		parsed, err := time.Parse("2006-01-02", input.Date)
@@ -951,6 +1878,7 @@ func (s *Server) toolDaily(ctx context.Context, input dailyInput) (*mcp.CallTool
 
 	title := targetDate.Format("2006-01-02")
 	mutated := false // write through to the vault only if we create/append/prepend
+	var warnings []string
 
 	// Try to get existing daily note
 	note, err := s.queries.GetNoteByTitle(ctx, title)
@@ -958,14 +1886,37 @@ func (s *Server) toolDaily(ctx context.Context, input dailyInput) (*mcp.CallTool
 		if err != sql.ErrNoRows {
 			return errorResult(fmt.Sprintf("failed to look up daily note: %v", err))
 		}
-		// Create new daily note
+		// Create new daily note, from the daily template if there is one
+		tmplName := input.Template
+		if tmplName == "" {
+			tmplName = s.dailyTemplate
+		}
+		var content string
+		if tmplName != "" {
+			tmpl, err := s.queries.GetTemplateByName(ctx, tmplName)
+			switch {
+			case err == nil:
+				content = interpolateTemplateAt(tmpl.Content, title, targetDate)
+			case err != sql.ErrNoRows:
+				return errorResult(fmt.Sprintf("failed to get template: %v", err))
+			case input.Template != "":
+				return errorResult(fmt.Sprintf("template %q not found", input.Template))
+			}
+		}
+		if err := s.allowCreate(); err != nil {
+			return errorResult(err.Error())
+		}
+		if warnings, err = s.checkQuota(ctx, content, true); err != nil {
+			return errorResult(err.Error())
+		}
 		note, err = s.queries.CreateNoteWithTTL(ctx, db.CreateNoteWithTTLParams{
 			Title:   title,
-			Content: "",
+			Content: content,
 		})
 		if err != nil {
 			return errorResult(fmt.Sprintf("failed to create daily note: %v", err))
 		}
+		s.recordCreatedBy(ctx, note.ID)
 		mutated = true
 		// Tag as "daily"
 		tag, err := s.queries.CreateTag(ctx, "daily")
@@ -1005,6 +1956,9 @@ func (s *Server) toolDaily(ctx context.Context, input dailyInput) (*mcp.CallTool
 			content += "\n"
 		}
 		content += input.Append
+		if warnings, err = s.checkQuota(ctx, content, false); err != nil {
+			return errorResult(err.Error())
+		}
 		note, err = s.queries.UpdateNote(ctx, db.UpdateNoteParams{
 			Title:   note.Title,
 			Content: content,
@@ -1022,6 +1976,9 @@ func (s *Server) toolDaily(ctx context.Context, input dailyInput) (*mcp.CallTool
 		if note.Content != "" {
 			content += "\n" + note.Content
 		}
+		if warnings, err = s.checkQuota(ctx, content, false); err != nil {
+			return errorResult(err.Error())
+		}
 		note, err = s.queries.UpdateNote(ctx, db.UpdateNoteParams{
 			Title:   note.Title,
 			Content: content,
@@ -1035,7 +1992,7 @@ func (s *Server) toolDaily(ctx context.Context, input dailyInput) (*mcp.CallTool
 
 	if mutated {
 		if updated, err := s.queries.GetNote(ctx, note.ID); err == nil {
-			notesync.WriteThrough(ctx, s.queries, s.vlt, updated) // mirror the daily note to the vault
+			s.writeThrough(ctx, updated) // mirror the daily note to the vault
 		}
 	}
 
@@ -1046,7 +2003,10 @@ func (s *Server) toolDaily(ctx context.Context, input dailyInput) (*mcp.CallTool
 		out.Tags[i] = t.Name
 	}
 
-	return textResult(out)
+	return textResult(struct {
+		noteOutput
+		Warnings []string `json:"warnings,omitempty"`
+	}{out, warnings})
 }
 
 func (s *Server) toolDailyList(ctx context.Context, input dailyListInput) (*mcp.CallToolResult, any, error) {
@@ -1101,6 +2061,7 @@ func (s *Server) toolTemplateCreate(ctx context.Context, input templateCreateInp
 	if input.Name == "" {
 		return errorResult("name is required")
 	}
+	input.Content = s.sanitize.Apply(input.Content)
 	if input.Content == "" {
 		return errorResult("content is required")
 	}
@@ -1167,6 +2128,7 @@ func (s *Server) toolTemplateDelete(ctx context.Context, input templateDeleteInp
 }
 
 func (s *Server) toolTemplateApply(ctx context.Context, input templateApplyInput) (*mcp.CallToolResult, any, error) {
+	input.Title = s.sanitize.Title(input.Title)
 	if input.TemplateName == "" {
 		return errorResult("template_name is required")
 	}
@@ -1184,6 +2146,18 @@ func (s *Server) toolTemplateApply(ctx context.Context, input templateApplyInput
 
 	// Interpolate template variables
 	content := interpolateTemplate(tmpl.Content, input.Title)
+	for name, value := range input.Vars {
+		content = strings.ReplaceAll(content, "{{"+name+"}}", value)
+	}
+	content = s.sanitize.Apply(content) // the variables are agent-supplied
+
+	if err := s.allowCreate(); err != nil {
+		return errorResult(err.Error())
+	}
+	warnings, err := s.checkQuota(ctx, content, true)
+	if err != nil {
+		return errorResult(err.Error())
+	}
 
 	note, err := s.queries.CreateNote(ctx, db.CreateNoteParams{
 		Title:   input.Title,
@@ -1192,6 +2166,7 @@ func (s *Server) toolTemplateApply(ctx context.Context, input templateApplyInput
 	if err != nil {
 		return errorResult(fmt.Sprintf("failed to create note: %v", err))
 	}
+	s.recordCreatedBy(ctx, note.ID)
 
 	for _, tagName := range input.Tags {
 		tag, err := s.queries.CreateTag(ctx, tagName)
@@ -1205,27 +2180,36 @@ func (s *Server) toolTemplateApply(ctx context.Context, input templateApplyInput
 	}
 
 	if s.syncer != nil {
-		_ = s.syncer.SyncNote(note.ID, note.Title, note.Content)
+		_ = memory.SyncNote(ctx, s.queries, s.syncer, note.ID, note.Title, note.Content)
 	}
-	notesync.WriteThrough(ctx, s.queries, s.vlt, note) // mirror the new note to the vault
+	s.writeThrough(ctx, note) // mirror the new note to the vault
 
-	return textResult(map[string]any{
+	result := map[string]any{
 		"id":       note.ID,
 		"title":    note.Title,
 		"template": input.TemplateName,
 		"status":   "created",
 		"message":  fmt.Sprintf("Note #%d created from template %q", note.ID, input.TemplateName),
-	})
+	}
+	if len(warnings) > 0 {
+		result["warnings"] = warnings
+	}
+	return textResult(result)
 }
 
 // interpolateTemplate replaces template variables with actual values
 func interpolateTemplate(content, title string) string {
-	now := time.Now()
+	return interpolateTemplateAt(content, title, time.Now())
+}
+
+// interpolateTemplateAt is interpolateTemplate with the date variables taken from t instead of now.
+func interpolateTemplateAt(content, title string, t time.Time) string {
 	r := strings.NewReplacer(
-		"{{date}}", now.Format("2006-01-02"),
-		"{{time}}", now.Format("15:04"),
-		"{{datetime}}", now.Format("2006-01-02 15:04"),
+		"{{date}}", t.Format("2006-01-02"),
+		"{{time}}", t.Format("15:04"),
+		"{{datetime}}", t.Format("2006-01-02 15:04"),
 		"{{title}}", title,
+		"{{cursor}}", "",
 	)
 	return r.Replace(content)
 }
@@ -1260,29 +2244,27 @@ func (s *Server) toolTasks(ctx context.Context, input tasksInput) (*mcp.CallTool
 		NoteID    int64  `json:"note_id"`
 		NoteTitle string `json:"note_title"`
 		Line      int    `json:"line"`
+		Index     int    `json:"index"`
+		Due       string `json:"due,omitempty"`
 	}
 
 	var tasks []taskItem
 	for _, note := range notes {
-		lines := strings.Split(note.Content, "\n")
-		for i, line := range lines {
-			matches := taskRegex.FindStringSubmatch(line)
-			if matches == nil {
-				continue
-			}
-			completed := matches[1] != " "
-			if input.Pending && completed {
+		for _, t := range markdown.Tasks(note.Content) {
+			if input.Pending && t.Done {
 				continue
 			}
-			if input.Completed && !completed {
+			if input.Completed && !t.Done {
 				continue
 			}
 			tasks = append(tasks, taskItem{
-				Text:      strings.TrimSpace(matches[2]),
-				Completed: completed,
+				Text:      t.Text,
+				Completed: t.Done,
 				NoteID:    note.ID,
 				NoteTitle: note.Title,
-				Line:      i + 1,
+				Line:      t.Line,
+				Index:     t.Index,
+				Due:       t.Due,
 			})
 		}
 	}
@@ -1309,6 +2291,53 @@ func (s *Server) toolTasks(ctx context.Context, input tasksInput) (*mcp.CallTool
 	})
 }
 
+func (s *Server) toolTaskDone(ctx context.Context, input taskDoneInput) (*mcp.CallToolResult, any, error) {
+	note, err := s.queries.GetNote(ctx, input.NoteID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return errorResult(fmt.Sprintf("note #%d not found", input.NoteID))
+		}
+		return errorResult(fmt.Sprintf("failed to get note: %v", err))
+	}
+	content, task, ok := markdown.SetTaskDone(note.Content, input.Index, !input.Undo)
+	if !ok {
+		return errorResult(fmt.Sprintf("note #%d has no task %d", note.ID, input.Index))
+	}
+
+	var warnings []string
+	if content != note.Content {
+		if warnings, err = s.checkQuota(ctx, content, false); err != nil {
+			return errorResult(err.Error())
+		}
+		if err := notesync.SnapshotVersion(ctx, s.queries, s.vlt, note.ID, note.Title, note.Content); err != nil {
+			return errorResult(fmt.Sprintf("failed to save version: %v", err))
+		}
+		note, err = s.queries.UpdateNote(ctx, db.UpdateNoteParams{
+			ID:      note.ID,
+			Title:   note.Title,
+			Content: content,
+		})
+		if err != nil {
+			return errorResult(fmt.Sprintf("failed to update note: %v", err))
+		}
+		if s.syncer != nil {
+			_ = memory.SyncNote(ctx, s.queries, s.syncer, note.ID, note.Title, note.Content)
+		}
+		s.writeThrough(ctx, note)
+	}
+
+	result := map[string]any{
+		"note_id":   note.ID,
+		"index":     task.Index,
+		"text":      task.Text,
+		"completed": task.Done,
+	}
+	if len(warnings) > 0 {
+		result["warnings"] = warnings
+	}
+	return textResult(result)
+}
+
 // --- Version history tool implementations ---
 
 func (s *Server) toolHistory(ctx context.Context, input historyInput) (*mcp.CallToolResult, any, error) {
@@ -1394,6 +2423,13 @@ func (s *Server) toolRestore(ctx context.Context, input restoreInput) (*mcp.Call
 		return errorResult(fmt.Sprintf("failed to get version: %v", err))
 	}
 
+	var warnings []string
+	if version.Content != note.Content {
+		if warnings, err = s.checkQuota(ctx, version.Content, false); err != nil {
+			return errorResult(err.Error())
+		}
+	}
+
 	// Save current state as a new version before restoring — only if the target differs from current.
 	if version.Title != note.Title || version.Content != note.Content {
 		if err := notesync.SnapshotVersion(ctx, s.queries, s.vlt, input.NoteID, note.Title, note.Content); err != nil {
@@ -1411,19 +2447,23 @@ func (s *Server) toolRestore(ctx context.Context, input restoreInput) (*mcp.Call
 	}
 
 	if s.syncer != nil {
-		_ = s.syncer.SyncNote(input.NoteID, version.Title, version.Content)
+		_ = memory.SyncNote(ctx, s.queries, s.syncer, input.NoteID, version.Title, version.Content)
 	}
 	if updated, err := s.queries.GetNote(ctx, input.NoteID); err == nil {
-		notesync.WriteThrough(ctx, s.queries, s.vlt, updated) // mirror the restored content to the vault
+		s.writeThrough(ctx, updated) // mirror the restored content to the vault
 	}
 
-	return textResult(map[string]any{
+	result := map[string]any{
 		"note_id":          input.NoteID,
 		"restored_version": version.VersionNumber,
 		"title":            version.Title,
 		"status":           "restored",
 		"message":          fmt.Sprintf("Note #%d restored to version %d", input.NoteID, version.VersionNumber),
-	})
+	}
+	if len(warnings) > 0 {
+		result["warnings"] = warnings
+	}
+	return textResult(result)
 }
 
 // --- Random note tool implementation ---
@@ -1485,6 +2525,34 @@ func (s *Server) toolBacklinks(ctx context.Context, input backlinksInput) (*mcp.
 	})
 }
 
+func (s *Server) toolMentions(ctx context.Context, input mentionsInput) (*mcp.CallToolResult, any, error) {
+	name := strings.ToLower(strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(input.Name), "@")))
+	if name == "" {
+		names, err := s.queries.ListMentionNames(ctx)
+		if err != nil {
+			return errorResult(fmt.Sprintf("failed to list mentions: %v", err))
+		}
+		if names == nil {
+			names = []db.ListMentionNamesRow{}
+		}
+		return textResult(map[string]any{"count": len(names), "mentions": names})
+	}
+
+	notes, err := s.queries.GetNotesMentioning(ctx, name)
+	if err != nil {
+		return errorResult(fmt.Sprintf("failed to get notes: %v", err))
+	}
+	output := make([]noteOutput, len(notes))
+	for i, n := range notes {
+		output[i] = formatNote(n)
+	}
+	return textResult(map[string]any{
+		"name":  name,
+		"count": len(output),
+		"notes": output,
+	})
+}
+
 func (s *Server) toolOrphans(ctx context.Context) (*mcp.CallToolResult, any, error) {
 	orphans, err := s.queries.GetOrphanNotes(ctx)
 	if err != nil {
@@ -1519,7 +2587,39 @@ func (s *Server) toolOrphans(ctx context.Context) (*mcp.CallToolResult, any, err
 	})
 }
 
+func (s *Server) toolUnresolvedLinks(ctx context.Context, input unresolvedLinksInput) (*mcp.CallToolResult, any, error) {
+	rows, err := s.queries.ListUnresolvedLinks(ctx)
+	if err != nil {
+		return errorResult(fmt.Sprintf("failed to get unresolved links: %v", err))
+	}
+	broken := db.GroupUnresolvedLinks(rows, input.NoteID)
+	if broken == nil {
+		broken = []db.UnresolvedLink{}
+	}
+	return textResult(map[string]any{
+		"unresolved": broken,
+		"count":      len(broken),
+	})
+}
+
+func (s *Server) toolQueryStats(input queryStatsInput) (*mcp.CallToolResult, any, error) {
+	if s.queryTimer == nil {
+		return errorResult("query timing is not enabled")
+	}
+	stats := s.queryTimer.Stats()
+	if input.Reset {
+		s.queryTimer.Reset()
+	}
+	return textResult(map[string]any{
+		"queries": stats,
+		"count":   len(stats),
+	})
+}
+
 func (s *Server) toolSync(ctx context.Context, input syncInput) (*mcp.CallToolResult, any, error) {
+	if input.Status {
+		return s.syncStatus(ctx)
+	}
 	if s.syncer == nil {
 		return errorResult("semantic search not available (veclite not configured)")
 	}
@@ -1539,8 +2639,14 @@ func (s *Server) toolSync(ctx context.Context, input syncInput) (*mcp.CallToolRe
 
 	synced := 0
 	failed := 0
+	status := "completed"
 	for _, note := range notes {
-		err := s.syncer.SyncNote(note.ID, note.Title, note.Content)
+		err := memory.SyncNote(ctx, s.queries, s.syncer, note.ID, note.Title, note.Content)
+		if errors.Is(err, embeddings.ErrUnavailable) {
+			// Stop rather than fail every remaining note; they stay unsynced for the next run
+			status = "interrupted: embedder unavailable"
+			break
+		}
 		if err != nil {
 			failed++
 			continue
@@ -1555,6 +2661,23 @@ func (s *Server) toolSync(ctx context.Context, input syncInput) (*mcp.CallToolRe
 		"failed":    failed,
 		"total":     len(notes),
 		"remaining": len(notes) - synced,
-		"status":    "completed",
+		"status":    status,
+	})
+}
+
+// syncStatus reports the notes still waiting to be embedded, counted without loading them.
+func (s *Server) syncStatus(ctx context.Context) (*mcp.CallToolResult, any, error) {
+	notes, err := s.queries.CountNotes(ctx)
+	if err != nil {
+		return errorResult(fmt.Sprintf("failed to count notes: %v", err))
+	}
+	pending, err := s.queries.CountUnsynced(ctx)
+	if err != nil {
+		return errorResult(fmt.Sprintf("failed to count unsynced notes: %v", err))
+	}
+	return textResult(map[string]any{
+		"notes":     notes,
+		"pending":   pending,
+		"available": s.syncer != nil,
 	})
 }
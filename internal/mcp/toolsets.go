@@ -0,0 +1,145 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// toolGroups assigns every tool to a group that `noted mcp --tools` can enable.
+var toolGroups = map[string]string{
+	"noted_create":  "notes",
+	"noted_list":    "notes",
+	"noted_get":     "notes",
+	"noted_update":  "notes",
+	"noted_delete":  "notes",
+	"noted_random":  "notes",
+	"noted_archive": "notes",
+	"noted_outline": "notes",
+
+	"noted_get_section":    "notes",
+	"noted_update_section": "notes",
+
+	"noted_trash_list":    "notes",
+	"noted_trash_restore": "notes",
+
+	"noted_search":          "search",
+	"noted_semantic_search": "search",
+
+	"noted_tags":        "tags",
+	"noted_tag_rename":  "tags",
+	"noted_tag_delete":  "tags",
+	"noted_tag_cleanup": "tags",
+
+	"noted_remember":      "memory",
+	"noted_recall":        "memory",
+	"noted_recall_batch":  "memory",
+	"noted_context":       "memory",
+	"noted_session_end":   "memory",
+	"noted_forget":        "memory",
+	"noted_memory_adjust": "memory",
+
+	"noted_sync": "sync",
+
+	"noted_daily":      "daily",
+	"noted_daily_list": "daily",
+
+	"noted_template_list":   "templates",
+	"noted_template_create": "templates",
+	"noted_template_get":    "templates",
+	"noted_template_delete": "templates",
+	"noted_template_apply":  "templates",
+
+	"noted_tasks":     "tasks",
+	"noted_task_done": "tasks",
+
+	"noted_history":     "history",
+	"noted_version_get": "history",
+	"noted_restore":     "history",
+
+	"noted_backlinks":        "links",
+	"noted_orphans":          "links",
+	"noted_unresolved_links": "links",
+	"noted_mentions":         "links",
+
+	"noted_query_stats": "stats",
+}
+
+// destructiveTools delete data outright; safe mode does not register them.
+var destructiveTools = map[string]bool{
+	"noted_delete":          true,
+	"noted_forget":          true,
+	"noted_tag_delete":      true,
+	"noted_tag_cleanup":     true,
+	"noted_template_delete": true,
+}
+
+// ToolGroups returns the tool group names accepted by ParseToolGroups, sorted.
+func ToolGroups() []string {
+	var groups []string
+	for _, g := range toolGroups {
+		if !slices.Contains(groups, g) {
+			groups = append(groups, g)
+		}
+	}
+	sort.Strings(groups)
+	return groups
+}
+
+// ParseToolGroups parses a comma-separated group list such as "memory,search". An empty spec or
+// "all" enables every group and returns nil.
+func ParseToolGroups(spec string) ([]string, error) {
+	valid := ToolGroups()
+	var groups []string
+	for _, g := range strings.Split(spec, ",") {
+		g = strings.ToLower(strings.TrimSpace(g))
+		switch {
+		case g == "":
+			continue
+		case g == "all":
+			return nil, nil
+		case !slices.Contains(valid, g):
+			return nil, fmt.Errorf("unknown tool group %q (valid: %s)", g, strings.Join(valid, ", "))
+		}
+		if !slices.Contains(groups, g) {
+			groups = append(groups, g)
+		}
+	}
+	return groups, nil
+}
+
+// WithToolGroups limits the registered tools to the given groups (see ToolGroups). Nil or empty
+// registers every group.
+func (s *Server) WithToolGroups(groups []string) *Server {
+	s.toolGroups = groups
+	return s
+}
+
+// WithSafeMode leaves out tools that delete notes, memories, tags, or templates.
+func (s *Server) WithSafeMode(safe bool) *Server {
+	s.safeMode = safe
+	return s
+}
+
+// toolEnabled reports whether a tool passes the group and safe-mode settings.
+func (s *Server) toolEnabled(name string) bool {
+	if s.safeMode && destructiveTools[name] {
+		return false
+	}
+	return len(s.toolGroups) == 0 || slices.Contains(s.toolGroups, toolGroups[name])
+}
+
+// addTool registers a tool unless it is disabled by WithToolGroups or WithSafeMode. Handlers see
+// the calling client's identity through clientFrom.
+func addTool[In any](s *Server, t *mcp.Tool, h mcp.ToolHandlerFor[In, any]) {
+	if !s.toolEnabled(t.Name) {
+		return
+	}
+	mcp.AddTool(s.server, t, func(ctx context.Context, req *mcp.CallToolRequest, input In) (*mcp.CallToolResult, any, error) {
+		return h(withClient(ctx, req), req, input)
+	})
+}
@@ -0,0 +1,83 @@
+package memory
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/abdul-hamid-achik/noted/internal/db"
+)
+
+// AdjustInput contains parameters for changing a memory's importance or category in place
+type AdjustInput struct {
+	ID       int64
+	Delta    int    // Added to the importance; the result is clamped to 1-5
+	Category string // New category; empty keeps the current one
+}
+
+// Adjust raises or lowers a memory's importance and optionally moves it to another category. All
+// existing importance:N and memory:<category> tags are replaced by exactly one of each, so a memory
+// whose tags were edited by hand ends up consistent again. The tags are rewritten in one
+// transaction, and a new category must be one of ValidCategories.
+func Adjust(ctx context.Context, queries *db.Queries, conn *sql.DB, input AdjustInput) (*Memory, error) {
+	note, err := queries.GetNote(ctx, input.ID)
+	if err != nil {
+		return nil, fmt.Errorf("memory #%d not found", input.ID)
+	}
+	mem, ok := noteToMemory(ctx, queries, note)
+	if !ok {
+		return nil, fmt.Errorf("note #%d is not a memory", input.ID)
+	}
+
+	importance := mem.Importance
+	if importance < 1 || importance > 5 {
+		importance = 3 // Same default as Remember
+	}
+	importance = min(max(importance+input.Delta, 1), 5)
+
+	category := strings.TrimSpace(input.Category)
+	if category != "" && !IsValidCategory(category) {
+		return nil, fmt.Errorf("invalid category %q (valid: %s)", category, strings.Join(ValidCategories, ", "))
+	}
+	if category == "" {
+		category = mem.Category
+	}
+	if category == "" {
+		category = "fact"
+	}
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback() }()
+	q := queries.WithTx(tx)
+
+	tags, err := q.GetTagsForNote(ctx, input.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tags: %w", err)
+	}
+	for _, t := range tags {
+		if strings.HasPrefix(t.Name, "memory:") || strings.HasPrefix(t.Name, "importance:") {
+			if err := q.RemoveTagFromNote(ctx, db.RemoveTagFromNoteParams{NoteID: input.ID, TagID: t.ID}); err != nil {
+				return nil, fmt.Errorf("failed to remove tag %s: %w", t.Name, err)
+			}
+		}
+	}
+	for _, name := range []string{"memory:" + category, fmt.Sprintf("importance:%d", importance)} {
+		tag, err := q.CreateTag(ctx, name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create tag %s: %w", name, err)
+		}
+		if err := q.AddTagToNote(ctx, db.AddTagToNoteParams{NoteID: input.ID, TagID: tag.ID}); err != nil {
+			return nil, fmt.Errorf("failed to add tag %s: %w", name, err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	updated, _ := noteToMemory(ctx, queries, note)
+	return &updated, nil
+}
@@ -0,0 +1,47 @@
+package memory
+
+import (
+	"fmt"
+	"strings"
+)
+
+// FormatContext renders memories as a markdown block meant to be pasted into a prompt: a
+// "## Relevant memories" heading and one numbered entry per memory, each ending with a citation
+// (memory ID, source, date) so answers can point back to it. No memories renders as "".
+func FormatContext(memories []Memory) string {
+	if len(memories) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteString("## Relevant memories\n")
+	for i, mem := range memories {
+		fmt.Fprintf(&b, "\n%d. **%s**", i+1, mem.Title)
+		if mem.Category != "" {
+			fmt.Fprintf(&b, " (%s, importance %d)", mem.Category, mem.Importance)
+		}
+		b.WriteString("\n")
+
+		// Indent every content line so multi-line memories stay inside the list item
+		content := strings.TrimSpace(mem.Content)
+		if content != "" && content != mem.Title {
+			for _, line := range strings.Split(content, "\n") {
+				b.WriteString(strings.TrimRight("   "+line, " ") + "\n")
+			}
+		}
+
+		cite := []string{fmt.Sprintf("memory #%d", mem.ID)}
+		if mem.Source != "" {
+			source := mem.Source
+			if mem.SourceRef != "" {
+				source += " @ " + mem.SourceRef
+			}
+			cite = append(cite, "source: "+source)
+		}
+		if !mem.CreatedAt.IsZero() {
+			cite = append(cite, mem.CreatedAt.Format("2006-01-02"))
+		}
+		fmt.Fprintf(&b, "   _[%s]_\n", strings.Join(cite, "; "))
+	}
+	return b.String()
+}
@@ -3,18 +3,21 @@ package memory
 import (
 	"context"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/abdul-hamid-achik/noted/internal/db"
+	"github.com/abdul-hamid-achik/noted/internal/notesync"
 	"github.com/abdul-hamid-achik/noted/internal/veclite"
 )
 
-// Forget deletes memories matching the given criteria
+// Forget moves memories matching the given criteria to the trash, where "noted trash restore" can
+// bring them back, and removes their vectors. Memories are not mirrored to the vault.
 func Forget(ctx context.Context, queries *db.Queries, syncer *veclite.Syncer, input ForgetInput) (*ForgetResult, error) {
 	// Default to dry run for safety if no criteria specified
 	dryRun := input.DryRun
 	if !input.DryRun && input.OlderThanDays == 0 && input.ImportanceBelow == 0 &&
-	   input.Category == "" && input.Query == "" && input.ID == 0 {
+	   input.Category == "" && input.Query == "" && input.Source == "" && input.TagPrefix == "" && input.ID == 0 {
 		dryRun = true
 	}
 
@@ -44,7 +47,7 @@ func Forget(ctx context.Context, queries *db.Queries, syncer *veclite.Syncer, in
 			_ = syncer.Delete(input.ID)
 		}
 
-		if err := queries.DeleteNote(ctx, input.ID); err != nil {
+		if err := notesync.Trash(ctx, queries, nil, input.ID); err != nil {
 			return nil, fmt.Errorf("failed to delete memory: %w", err)
 		}
 
@@ -103,6 +106,14 @@ func Forget(ctx context.Context, queries *db.Queries, syncer *veclite.Syncer, in
 			continue // Too important
 		}
 
+		// Check source and tag filters
+		if input.Source != "" && mem.Source != input.Source {
+			continue
+		}
+		if input.TagPrefix != "" && !hasTagPrefix(mem.Tags, input.TagPrefix) {
+			continue
+		}
+
 		toDelete = append(toDelete, mem)
 	}
 
@@ -121,7 +132,7 @@ func Forget(ctx context.Context, queries *db.Queries, syncer *veclite.Syncer, in
 		if syncer != nil {
 			_ = syncer.Delete(mem.ID)
 		}
-		if err := queries.DeleteNote(ctx, mem.ID); err == nil {
+		if err := notesync.Trash(ctx, queries, nil, mem.ID); err == nil {
 			deleted++
 		}
 	}
@@ -132,3 +143,13 @@ func Forget(ctx context.Context, queries *db.Queries, syncer *veclite.Syncer, in
 		Memories: toDelete,
 	}, nil
 }
+
+// hasTagPrefix reports whether any tag starts with prefix.
+func hasTagPrefix(tags []string, prefix string) bool {
+	for _, t := range tags {
+		if strings.HasPrefix(t, prefix) {
+			return true
+		}
+	}
+	return false
+}
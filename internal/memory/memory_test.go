@@ -2,6 +2,8 @@ package memory
 
 import (
 	"context"
+	"database/sql"
+	"fmt"
 	"path/filepath"
 	"testing"
 	"time"
@@ -163,6 +165,87 @@ func TestRecall_CategoryFilter(t *testing.T) {
 	}
 }
 
+func TestRecall_SourceAndTimeFilters(t *testing.T) {
+	queries, _, cleanup := setupMemoryTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	_, _ = Remember(ctx, queries, nil, RememberInput{Content: "Prefer table-driven tests", Source: "code-review"})
+	_, _ = Remember(ctx, queries, nil, RememberInput{Content: "Prefer short tests", Source: "manual"})
+
+	now := time.Now()
+	tests := []struct {
+		name  string
+		input RecallInput
+		want  int
+	}{
+		{"source", RecallInput{Query: "tests", Source: "code-review"}, 1},
+		{"since past", RecallInput{Query: "tests", Since: now.Add(-time.Hour)}, 2},
+		{"since future", RecallInput{Query: "tests", Since: now.Add(time.Hour)}, 0},
+		{"until past", RecallInput{Query: "tests", Until: now.Add(-time.Hour)}, 0},
+		{"source and window", RecallInput{Query: "tests", Source: "manual", Since: now.Add(-time.Hour), Until: now.Add(time.Hour)}, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Recall(ctx, queries, nil, nil, tt.input)
+			if err != nil {
+				t.Fatalf("Recall failed: %v", err)
+			}
+			if result.Count != tt.want {
+				t.Errorf("got %d memories, want %d", result.Count, tt.want)
+			}
+		})
+	}
+}
+
+func TestRecall_FilterFindsMatchesPastTheFirstCandidates(t *testing.T) {
+	queries, _, cleanup := setupMemoryTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	// The match is the oldest of 31 hits, well past the first candidates fetched for limit 1
+	want, _ := Remember(ctx, queries, nil, RememberInput{Content: "Deploy on Fridays is fine", Source: "code-review"})
+	for i := range 30 {
+		_, _ = Remember(ctx, queries, nil, RememberInput{Content: fmt.Sprintf("Deploy note %d", i), Source: "manual"})
+	}
+
+	result, err := Recall(ctx, queries, nil, nil, RecallInput{Query: "deploy", Limit: 1, Source: "code-review"})
+	if err != nil {
+		t.Fatalf("Recall failed: %v", err)
+	}
+	if result.Count != 1 || result.Memories[0].ID != want.ID {
+		t.Errorf("expected memory #%d, got %+v", want.ID, result.Memories)
+	}
+}
+
+func TestParseTimeBound(t *testing.T) {
+	now := time.Date(2025, 3, 14, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		in      string
+		end     bool
+		want    time.Time
+		wantErr bool
+	}{
+		{"", false, time.Time{}, false},
+		{"2025-03-01", false, time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC), false},
+		{"2025-03-01", true, time.Date(2025, 3, 2, 0, 0, 0, 0, time.UTC), false},
+		{"30d", false, now.Add(-30 * 24 * time.Hour), false},
+		{"12h", false, now.Add(-12 * time.Hour), false},
+		{"2025-03-01T08:00:00Z", false, time.Date(2025, 3, 1, 8, 0, 0, 0, time.UTC), false},
+		{"last week", false, time.Time{}, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseTimeBound(tt.in, now, tt.end)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseTimeBound(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if !got.Equal(tt.want) {
+			t.Errorf("ParseTimeBound(%q, end=%v) = %v, want %v", tt.in, tt.end, got, tt.want)
+		}
+	}
+}
+
 func TestForget_ByID(t *testing.T) {
 	queries, _, cleanup := setupMemoryTestDB(t)
 	defer cleanup()
@@ -181,6 +264,13 @@ func TestForget_ByID(t *testing.T) {
 	if result.Deleted != 1 {
 		t.Errorf("expected 1 deleted, got %d", result.Deleted)
 	}
+	// Forgotten memories go to the trash, so they can be restored
+	if _, err := queries.GetNote(ctx, mem.ID); err != sql.ErrNoRows {
+		t.Errorf("expected the memory to be hidden, got err=%v", err)
+	}
+	if _, err := queries.GetTrashedNote(ctx, mem.ID); err != nil {
+		t.Errorf("expected the memory in the trash, got err=%v", err)
+	}
 }
 
 func TestForget_DryRun(t *testing.T) {
@@ -212,6 +302,36 @@ func TestForget_DryRun(t *testing.T) {
 	}
 }
 
+func TestForget_BySourceAndTagPrefix(t *testing.T) {
+	queries, _, cleanup := setupMemoryTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	review, _ := Remember(ctx, queries, nil, RememberInput{Content: "Review note", Source: "code-review"})
+	manual, _ := Remember(ctx, queries, nil, RememberInput{Content: "Manual note", Source: "manual"})
+	temp, _ := queries.CreateTag(ctx, "temp:scratch")
+	_ = queries.AddTagToNote(ctx, db.AddTagToNoteParams{NoteID: manual.ID, TagID: temp.ID})
+
+	result, err := Forget(ctx, queries, nil, ForgetInput{Source: "code-review", DryRun: true})
+	if err != nil {
+		t.Fatalf("Forget failed: %v", err)
+	}
+	if result.WouldDelete != 1 || result.Memories[0].ID != review.ID {
+		t.Errorf("expected only the code-review memory, got %+v", result.Memories)
+	}
+
+	result, err = Forget(ctx, queries, nil, ForgetInput{TagPrefix: "temp:"})
+	if err != nil {
+		t.Fatalf("Forget failed: %v", err)
+	}
+	if result.Deleted != 1 || result.Memories[0].ID != manual.ID {
+		t.Errorf("expected only the temp:-tagged memory deleted, got %+v", result)
+	}
+	if _, err := queries.GetNote(ctx, review.ID); err != nil {
+		t.Error("memory without the tag prefix should not be deleted")
+	}
+}
+
 func TestForget_NonMemoryNote(t *testing.T) {
 	queries, _, cleanup := setupMemoryTestDB(t)
 	defer cleanup()
@@ -232,6 +352,120 @@ func TestForget_NonMemoryNote(t *testing.T) {
 	}
 }
 
+func TestAdjust(t *testing.T) {
+	conn, err := db.Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = conn.Close() }()
+	queries := db.New(conn)
+
+	ctx := context.Background()
+	mem, _ := Remember(ctx, queries, nil, RememberInput{Content: "Use tabs", Importance: 4})
+
+	// A stray hand-added tag is cleaned up along the way
+	stray, _ := queries.CreateTag(ctx, "importance:2")
+	_ = queries.AddTagToNote(ctx, db.AddTagToNoteParams{NoteID: mem.ID, TagID: stray.ID})
+
+	got, err := Adjust(ctx, queries, conn, AdjustInput{ID: mem.ID, Delta: 3, Category: "user-pref"})
+	if err != nil {
+		t.Fatalf("Adjust failed: %v", err)
+	}
+	if got.Importance != 5 || got.Category != "user-pref" {
+		t.Errorf("expected importance 5 (clamped) in user-pref, got %d in %s", got.Importance, got.Category)
+	}
+	tags, _ := queries.GetTagsForNote(ctx, mem.ID)
+	var names []string
+	for _, tag := range tags {
+		names = append(names, tag.Name)
+	}
+	if len(names) != 3 {
+		t.Errorf("expected memory, memory:user-pref, importance:5; got %v", names)
+	}
+
+	got, _ = Adjust(ctx, queries, conn, AdjustInput{ID: mem.ID, Delta: -10})
+	if got.Importance != 1 || got.Category != "user-pref" {
+		t.Errorf("expected importance 1 in user-pref, got %d in %s", got.Importance, got.Category)
+	}
+
+	// An unknown category is refused and leaves the tags alone
+	if _, err := Adjust(ctx, queries, conn, AdjustInput{ID: mem.ID, Category: "typo"}); err == nil {
+		t.Error("expected an error for an unknown category")
+	}
+	if _, err := queries.GetTagByName(ctx, "memory:typo"); err != sql.ErrNoRows {
+		t.Errorf("unknown category created a tag, err=%v", err)
+	}
+	if tags, _ := queries.GetTagsForNote(ctx, mem.ID); len(tags) != 3 {
+		t.Errorf("refused adjust changed the tags: %v", tags)
+	}
+
+	note, _ := queries.CreateNote(ctx, db.CreateNoteParams{Title: "Regular", Content: "Not a memory"})
+	if _, err := Adjust(ctx, queries, conn, AdjustInput{ID: note.ID, Delta: 1}); err == nil {
+		t.Error("expected error when adjusting a non-memory note")
+	}
+}
+
+func TestFormatContext(t *testing.T) {
+	if got := FormatContext(nil); got != "" {
+		t.Errorf("expected no output without memories, got %q", got)
+	}
+
+	got := FormatContext([]Memory{{
+		ID:         42,
+		Title:      "Use tabs",
+		Content:    "Tabs for indentation.\nSpaces for alignment.",
+		Category:   "user-pref",
+		Importance: 4,
+		Source:     "code-review",
+		SourceRef:  "main.go:50",
+		CreatedAt:  time.Date(2026, 3, 14, 9, 0, 0, 0, time.UTC),
+	}})
+	want := "## Relevant memories\n\n" +
+		"1. **Use tabs** (user-pref, importance 4)\n" +
+		"   Tabs for indentation.\n" +
+		"   Spaces for alignment.\n" +
+		"   _[memory #42; source: code-review @ main.go:50; 2026-03-14]_\n"
+	if got != want {
+		t.Errorf("FormatContext =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestLogSession(t *testing.T) {
+	queries, _, cleanup := setupMemoryTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	plan, _ := queries.CreateNote(ctx, db.CreateNoteParams{Title: "Plan", Content: "x"})
+	gone, _ := queries.CreateNote(ctx, db.CreateNoteParams{Title: "Gone", Content: "x"})
+	_ = queries.DeleteNote(ctx, gone.ID)
+
+	started := time.Date(2026, 3, 14, 9, 0, 0, 0, time.UTC)
+	ended := started.Add(2 * time.Hour)
+	mem, err := LogSession(ctx, queries, nil, SessionInput{
+		Summary: "Moved the importer behind an interface",
+		Started: started,
+		Ended:   ended,
+		NoteIDs: []int64{plan.ID, gone.ID, plan.ID},
+	})
+	if err != nil {
+		t.Fatalf("LogSession failed: %v", err)
+	}
+	if mem.Category != "project" || mem.Source != SessionSource || mem.Title != "Session 2026-03-14 11:00" {
+		t.Errorf("memory = %+v", mem)
+	}
+	want := "Moved the importer behind an interface\n\n**Started:** 2026-03-14T09:00:00Z\n**Ended:** 2026-03-14T11:00:00Z\n\n## Notes touched\n\n- [[Plan]] (#1)\n"
+	if mem.Content != want {
+		t.Errorf("content =\n%q\nwant\n%q", mem.Content, want)
+	}
+
+	if _, err := LogSession(ctx, queries, nil, SessionInput{Summary: "x", Category: "fact"}); err == nil {
+		t.Error("expected an error for a category other than project or decision")
+	}
+	if _, err := LogSession(ctx, queries, nil, SessionInput{Summary: "  "}); err == nil {
+		t.Error("expected an error for an empty summary")
+	}
+}
+
 func TestIsValidCategory(t *testing.T) {
 	tests := []struct {
 		category string
@@ -4,7 +4,9 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/abdul-hamid-achik/noted/internal/db"
 	"github.com/abdul-hamid-achik/noted/internal/veclite"
@@ -21,15 +23,34 @@ func Recall(ctx context.Context, queries *db.Queries, conn *sql.DB, syncer *vecl
 		limit = 5
 	}
 
+	// Candidates to fetch before filtering down to memories. Source and time filters can discard
+	// most hits, so search wider when they are set.
+	candidates := limit * 2
+	if input.narrow() {
+		candidates = limit * 10
+	}
+
 	// Clean up expired notes first (lazy cleanup)
 	_, _ = queries.DeleteExpiredNotes(ctx)
 
+	// Both searches widen until limit memories pass the filters or the search runs out of hits, so a
+	// filter that discards most candidates still finds every match.
+
 	// Try semantic search first if available and requested
 	if syncer != nil && input.UseSemantic {
-		results, err := syncer.Search(input.Query, limit*2) // Get extra for filtering
-		if err == nil && len(results) > 0 {
-			memories, err := filterMemoryResults(ctx, queries, results, input.Category, limit)
-			if err == nil && len(memories) > 0 {
+		for n := candidates; ; n *= 4 {
+			results, err := syncer.SearchMemories(input.Query, n)
+			if err != nil || len(results) == 0 {
+				break
+			}
+			memories, err := filterMemoryResults(ctx, queries, results, input, limit)
+			if err != nil {
+				break
+			}
+			if len(memories) < limit && len(results) >= n {
+				continue
+			}
+			if len(memories) > 0 {
 				return &RecallResult{
 					Query:    input.Query,
 					Method:   "semantic",
@@ -37,28 +58,51 @@ func Recall(ctx context.Context, queries *db.Queries, conn *sql.DB, syncer *vecl
 					Memories: memories,
 				}, nil
 			}
+			break
 		}
 	}
 
-	// Fallback: try FTS5 first, then LIKE
+	// Fallback: keyword search
+	var memories []Memory
+	for n := candidates; ; n *= 4 {
+		notes, err := searchCandidates(ctx, queries, conn, input.Query, n)
+		if err != nil {
+			return nil, fmt.Errorf("search failed: %w", err)
+		}
+		memories = filterMemoryNotes(ctx, queries, notes, input, limit)
+		if len(memories) >= limit || len(notes) < n {
+			break
+		}
+	}
+
+	return &RecallResult{
+		Query:    input.Query,
+		Method:   "keyword",
+		Count:    len(memories),
+		Memories: memories,
+	}, nil
+}
+
+// searchCandidates returns up to n notes matching query: FTS5 when available, else LIKE.
+func searchCandidates(ctx context.Context, queries *db.Queries, conn *sql.DB, query string, n int) ([]db.Note, error) {
 	var notes []db.Note
 	var err error
 	if conn != nil && db.FTSAvailable(ctx, conn) {
-		notes, err = db.SearchNotesFTS(ctx, conn, input.Query, int64(limit*2))
+		notes, err = db.SearchNotesFTS(ctx, conn, query, int64(n))
 	}
 	if notes == nil || err != nil {
-		pattern := "%" + input.Query + "%"
+		pattern := "%" + query + "%"
 		notes, err = queries.SearchNotesContent(ctx, db.SearchNotesContentParams{
 			Content: pattern,
 			Title:   pattern,
-			Limit:   int64(limit * 2),
+			Limit:   int64(n),
 		})
 	}
-	if err != nil {
-		return nil, fmt.Errorf("search failed: %w", err)
-	}
+	return notes, err
+}
 
-	// Filter to memories only
+// filterMemoryNotes keeps up to limit of the notes that are memories passing the input's filters.
+func filterMemoryNotes(ctx context.Context, queries *db.Queries, notes []db.Note, input RecallInput, limit int) []Memory {
 	memories := make([]Memory, 0, limit)
 	for _, note := range notes {
 		if len(memories) >= limit {
@@ -70,24 +114,47 @@ func Recall(ctx context.Context, queries *db.Queries, conn *sql.DB, syncer *vecl
 			continue // Not a memory
 		}
 
-		// Skip if category filter doesn't match
-		if input.Category != "" && mem.Category != input.Category {
+		// Skip if the category, source, or time filters don't match
+		if !input.matches(mem) {
 			continue
 		}
 
 		memories = append(memories, mem)
 	}
+	return memories
+}
 
-	return &RecallResult{
-		Query:    input.Query,
-		Method:   "keyword",
-		Count:    len(memories),
-		Memories: memories,
-	}, nil
+// Top returns up to limit memories, most important first and, among equally important ones, most
+// recently updated first.
+func Top(ctx context.Context, queries *db.Queries, limit int) ([]Memory, error) {
+	_, _ = queries.DeleteExpiredNotes(ctx)
+
+	notes, err := queries.GetNotesByTagName(ctx, "memory")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list memories: %w", err)
+	}
+	memories := make([]Memory, 0, len(notes))
+	for _, note := range notes {
+		if mem, ok := noteToMemory(ctx, queries, note); ok {
+			memories = append(memories, mem)
+		}
+	}
+	SortByImportance(memories)
+	return memories[:min(limit, len(memories))], nil
+}
+
+// SortByImportance orders memories most important first, then most recently updated first.
+func SortByImportance(memories []Memory) {
+	sort.SliceStable(memories, func(i, j int) bool {
+		if memories[i].Importance != memories[j].Importance {
+			return memories[i].Importance > memories[j].Importance
+		}
+		return memories[i].UpdatedAt.After(memories[j].UpdatedAt)
+	})
 }
 
 // filterMemoryResults filters semantic search results to only include memories
-func filterMemoryResults(ctx context.Context, queries *db.Queries, results []veclite.SemanticResult, category string, limit int) ([]Memory, error) {
+func filterMemoryResults(ctx context.Context, queries *db.Queries, results []veclite.SemanticResult, input RecallInput, limit int) ([]Memory, error) {
 	memories := make([]Memory, 0, limit)
 
 	for _, r := range results {
@@ -105,8 +172,8 @@ func filterMemoryResults(ctx context.Context, queries *db.Queries, results []vec
 			continue // Not a memory
 		}
 
-		// Skip if category filter doesn't match
-		if category != "" && mem.Category != category {
+		// Skip if the category, source, or time filters don't match
+		if !input.matches(mem) {
 			continue
 		}
 
@@ -170,6 +237,43 @@ func noteToMemory(ctx context.Context, queries *db.Queries, note db.Note) (Memor
 	if note.SourceRef.Valid {
 		mem.SourceRef = note.SourceRef.String
 	}
+	if note.CreatedBy.Valid {
+		mem.CreatedBy = note.CreatedBy.String
+	}
 
 	return mem, true
 }
+
+// ParseTimeBound parses a recall time filter: a date (YYYY-MM-DD), an RFC 3339 timestamp, or a
+// duration back from now ("30d", "12h"). With end set, a bare date means the end of that day, so
+// an until of "2025-03-14" includes memories from the 14th.
+func ParseTimeBound(s string, now time.Time, end bool) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", s, now.Location()); err == nil {
+		if end {
+			t = t.AddDate(0, 0, 1)
+		}
+		return t, nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+
+	var d time.Duration
+	var err error
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		var n int
+		if _, err = fmt.Sscanf(days, "%d", &n); err == nil {
+			d = time.Duration(n) * 24 * time.Hour
+		}
+	} else {
+		d, err = time.ParseDuration(s)
+	}
+	if err != nil || d < 0 {
+		return time.Time{}, fmt.Errorf("invalid time %q (use YYYY-MM-DD, RFC 3339, or a duration like 30d)", s)
+	}
+	return now.Add(-d), nil
+}
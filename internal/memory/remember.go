@@ -87,7 +87,7 @@ func Remember(ctx context.Context, queries *db.Queries, syncer *veclite.Syncer,
 
 	// Sync to veclite if available
 	if syncer != nil {
-		_ = syncer.SyncNote(note.ID, note.Title, note.Content)
+		_ = syncer.SyncMemory(note.ID, note.Title, note.Content)
 	}
 
 	mem := &Memory{
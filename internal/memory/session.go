@@ -0,0 +1,82 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/abdul-hamid-achik/noted/internal/db"
+	"github.com/abdul-hamid-achik/noted/internal/veclite"
+)
+
+// SessionSource is the source of session-log memories, so the work log can be listed by source.
+const SessionSource = "session"
+
+// SessionInput is a summary of a work session to log as a memory.
+type SessionInput struct {
+	Summary    string
+	Title      string    // Optional, "Session <ended>" if empty
+	Category   string    // "project" (default) or "decision"
+	Importance int       // 1-5, default: 3
+	Started    time.Time // Optional
+	Ended      time.Time // Default: now
+	NoteIDs    []int64   // Notes worked on during the session, linked from the log
+}
+
+// LogSession stores a session summary as a memory with source "session". Its content is the
+// summary followed by the session's start and end times and wikilinks to the notes it touched;
+// notes that no longer exist are left out.
+func LogSession(ctx context.Context, queries *db.Queries, syncer *veclite.Syncer, input SessionInput) (*Memory, error) {
+	summary := strings.TrimSpace(input.Summary)
+	if summary == "" {
+		return nil, fmt.Errorf("summary is required")
+	}
+	category := input.Category
+	if category == "" {
+		category = "project"
+	}
+	if category != "project" && category != "decision" {
+		return nil, fmt.Errorf("invalid session category %q (valid: project, decision)", category)
+	}
+	ended := input.Ended
+	if ended.IsZero() {
+		ended = time.Now()
+	}
+	title := input.Title
+	if title == "" {
+		title = "Session " + ended.Format("2006-01-02 15:04")
+	}
+
+	var b strings.Builder
+	b.WriteString(summary + "\n\n")
+	if !input.Started.IsZero() {
+		fmt.Fprintf(&b, "**Started:** %s\n", input.Started.Format(time.RFC3339))
+	}
+	fmt.Fprintf(&b, "**Ended:** %s\n", ended.Format(time.RFC3339))
+
+	var links []string
+	seen := make(map[int64]bool)
+	for _, id := range input.NoteIDs {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		note, err := queries.GetNote(ctx, id)
+		if err != nil || note.DeletedAt.Valid {
+			continue
+		}
+		links = append(links, fmt.Sprintf("- [[%s]] (#%d)", note.Title, note.ID))
+	}
+	if len(links) > 0 {
+		b.WriteString("\n## Notes touched\n\n" + strings.Join(links, "\n") + "\n")
+	}
+
+	return Remember(ctx, queries, syncer, RememberInput{
+		Content:    b.String(),
+		Title:      title,
+		Category:   category,
+		Importance: input.Importance,
+		Source:     SessionSource,
+	})
+}
@@ -0,0 +1,36 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/abdul-hamid-achik/noted/internal/db"
+)
+
+// Embedder stores note embeddings; satisfied by *veclite.Syncer.
+type Embedder interface {
+	SyncNote(id int64, title, content string) error
+	SyncMemory(id int64, title, content string) error
+}
+
+// SyncNote embeds a note into the collection matching its kind: memories (notes tagged "memory")
+// into the memories collection, everything else into the notes collection.
+func SyncNote(ctx context.Context, queries *db.Queries, e Embedder, id int64, title, content string) error {
+	if IsMemory(ctx, queries, id) {
+		return e.SyncMemory(id, title, content)
+	}
+	return e.SyncNote(id, title, content)
+}
+
+// IsMemory reports whether a note is tagged "memory".
+func IsMemory(ctx context.Context, queries *db.Queries, id int64) bool {
+	tags, err := queries.GetTagsForNote(ctx, id)
+	if err != nil {
+		return false
+	}
+	for _, t := range tags {
+		if t.Name == "memory" {
+			return true
+		}
+	}
+	return false
+}
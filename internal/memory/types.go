@@ -13,6 +13,7 @@ type Memory struct {
 	Importance int       `json:"importance"`
 	Source     string    `json:"source,omitempty"`
 	SourceRef  string    `json:"source_ref,omitempty"`
+	CreatedBy  string    `json:"created_by,omitempty"` // MCP client that stored it
 	ExpiresAt  time.Time `json:"expires_at,omitempty"`
 	CreatedAt  time.Time `json:"created_at"`
 	UpdatedAt  time.Time `json:"updated_at"`
@@ -36,9 +37,34 @@ type RecallInput struct {
 	Query        string
 	Limit        int    // Default: 5
 	Category     string // Optional filter
+	Source       string // Optional filter (exact match on the memory's source)
+	Since        time.Time // Optional: only memories created at or after this time
+	Until        time.Time // Optional: only memories created before this time
 	UseSemantic  bool   // Prefer semantic search if available
 }
 
+// matches reports whether a memory passes the input's category, source, and time filters.
+func (in RecallInput) matches(mem Memory) bool {
+	if in.Category != "" && mem.Category != in.Category {
+		return false
+	}
+	if in.Source != "" && mem.Source != in.Source {
+		return false
+	}
+	if !in.Since.IsZero() && mem.CreatedAt.Before(in.Since) {
+		return false
+	}
+	if !in.Until.IsZero() && !mem.CreatedAt.Before(in.Until) {
+		return false
+	}
+	return true
+}
+
+// narrow reports whether source or time filters are set, which can discard many candidates.
+func (in RecallInput) narrow() bool {
+	return in.Source != "" || !in.Since.IsZero() || !in.Until.IsZero()
+}
+
 // RecallResult contains the results of a recall operation
 type RecallResult struct {
 	Query    string    `json:"query"`
@@ -53,6 +79,8 @@ type ForgetInput struct {
 	ImportanceBelow int
 	Category        string
 	Query           string // Text search to match
+	Source          string // Only memories from this source (exact match)
+	TagPrefix       string // Only memories with a tag starting with this prefix, e.g. "temp:"
 	ID              int64  // Specific ID to delete
 	DryRun          bool   // Default: true
 }
@@ -0,0 +1,35 @@
+package notesync
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/abdul-hamid-achik/noted/internal/db"
+	"github.com/abdul-hamid-achik/noted/internal/vault"
+)
+
+// SetArchived archives or unarchives a note and mirrors the change to the vault. Archiving an
+// archived note (or unarchiving a live one) is a no-op. Returns sql.ErrNoRows when the note does not
+// exist or is in the trash.
+func SetArchived(ctx context.Context, dbq *db.Queries, vlt *vault.Vault, id int64, archived bool) (db.Note, error) {
+	note, err := dbq.GetNote(ctx, id)
+	if err != nil {
+		return db.Note{}, err
+	}
+	if note.ArchivedAt.Valid == archived {
+		return note, nil
+	}
+	if archived {
+		_, err = dbq.ArchiveNote(ctx, id)
+	} else {
+		_, err = dbq.UnarchiveNote(ctx, id)
+	}
+	if err != nil {
+		return db.Note{}, fmt.Errorf("failed to update note #%d: %w", id, err)
+	}
+	if note, err = dbq.GetNote(ctx, id); err != nil {
+		return db.Note{}, err
+	}
+	WriteThrough(ctx, dbq, vlt, note)
+	return note, nil
+}
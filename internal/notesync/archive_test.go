@@ -0,0 +1,56 @@
+package notesync
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	"github.com/abdul-hamid-achik/noted/internal/db"
+	"github.com/abdul-hamid-achik/noted/internal/vault"
+)
+
+func TestSetArchivedSurvivesRebuild(t *testing.T) {
+	ctx := context.Background()
+	conn, err := db.Open(filepath.Join(t.TempDir(), "t.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = conn.Close() }()
+	dbq := db.New(conn)
+	vlt, err := vault.Open(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	n, _ := dbq.CreateNote(ctx, db.CreateNoteParams{Title: "Old plan", Content: "body"})
+	WriteThrough(ctx, dbq, vlt, n)
+
+	archived, err := SetArchived(ctx, dbq, vlt, n.ID, true)
+	if err != nil || !archived.ArchivedAt.Valid {
+		t.Fatalf("SetArchived = %+v, %v", archived, err)
+	}
+	if again, err := SetArchived(ctx, dbq, vlt, n.ID, true); err != nil || again.ArchivedAt != archived.ArchivedAt {
+		t.Errorf("archiving twice = %+v, %v; want it unchanged", again, err)
+	}
+	if vn, ok := vfind(vlt, n.ID); !ok || vn.Archived.IsZero() {
+		t.Errorf("vault note should carry the archived time, got %+v", vn)
+	}
+
+	if _, err := Rebuild(ctx, conn, vlt); err != nil {
+		t.Fatalf("Rebuild: %v", err)
+	}
+	if got, err := dbq.GetNote(ctx, n.ID); err != nil || !got.ArchivedAt.Valid {
+		t.Fatalf("note after rebuild = %+v, %v; want it still archived", got, err)
+	}
+
+	if got, err := SetArchived(ctx, dbq, vlt, n.ID, false); err != nil || got.ArchivedAt.Valid {
+		t.Errorf("unarchive = %+v, %v", got, err)
+	}
+	if vn, _ := vfind(vlt, n.ID); !vn.Archived.IsZero() {
+		t.Error("unarchived note should drop the archived field")
+	}
+	if _, err := SetArchived(ctx, dbq, vlt, 999, true); err != sql.ErrNoRows {
+		t.Errorf("archiving a missing note: got %v, want sql.ErrNoRows", err)
+	}
+}
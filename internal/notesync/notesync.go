@@ -4,9 +4,14 @@ package notesync
 
 import (
 	"context"
+	"database/sql"
+	"fmt"
+	"slices"
 	"strings"
 
 	"github.com/abdul-hamid-achik/noted/internal/db"
+	"github.com/abdul-hamid-achik/noted/internal/lang"
+	"github.com/abdul-hamid-achik/noted/internal/markdown"
 	"github.com/abdul-hamid-achik/noted/internal/vault"
 )
 
@@ -30,20 +35,64 @@ func FolderPath(ctx context.Context, dbq *db.Queries, id int64) string {
 	return strings.Join(parts, "/")
 }
 
-// WriteThrough mirrors a saved note (and its current tags) to the vault. Best-effort and a no-op
-// when the vault is nil.
+// EnsureFolderPath find-or-creates a folder hierarchy ("A/B/C") and returns the leaf folder's id.
+func EnsureFolderPath(ctx context.Context, dbq *db.Queries, path string) (int64, error) {
+	folders, err := dbq.ListFolders(ctx)
+	if err != nil {
+		return 0, err
+	}
+	var parent sql.NullInt64 // invalid = root
+	for _, name := range strings.Split(path, "/") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		idx := slices.IndexFunc(folders, func(f db.Folder) bool { return f.Name == name && f.ParentID == parent })
+		if idx >= 0 {
+			parent = sql.NullInt64{Int64: folders[idx].ID, Valid: true}
+			continue
+		}
+		f, err := dbq.CreateFolder(ctx, db.CreateFolderParams{Name: name, ParentID: parent})
+		if err != nil {
+			return 0, err
+		}
+		folders = append(folders, f)
+		parent = sql.NullInt64{Int64: f.ID, Valid: true}
+	}
+	if !parent.Valid {
+		return 0, fmt.Errorf("empty folder path")
+	}
+	return parent.Int64, nil
+}
+
+// WriteThrough indexes a saved note's @mentions, pending links, and language, then mirrors the note
+// and its current tags to the vault. It is best-effort, and the vault write is skipped when the
+// vault is nil.
 func WriteThrough(ctx context.Context, dbq *db.Queries, vlt *vault.Vault, n db.Note) {
-	if vlt == nil || dbq == nil {
+	if dbq == nil {
 		return
 	}
+	_ = SetMentions(ctx, dbq, n.ID, n.Content)
+	_ = SetPendingLinks(ctx, dbq, n.ID, n.Content)
+	_ = SetLang(ctx, dbq, n.ID, n.Content)
+	if vlt == nil {
+		return
+	}
+	_, _ = vlt.Sync(VaultNote(ctx, dbq, n))
+}
+
+// VaultNote builds the vault form of a database note with its tags, aliases, and folder path.
+func VaultNote(ctx context.Context, dbq *db.Queries, n db.Note) vault.Note {
 	tags, _ := dbq.GetTagsForNote(ctx, n.ID)
 	tnames := make([]string, len(tags))
 	for i, t := range tags {
 		tnames[i] = t.Name
 	}
+	aliases, _ := dbq.GetNoteAliases(ctx, n.ID)
 	vn := vault.Note{
 		ID:      n.ID,
 		Title:   n.Title,
+		Aliases: aliases,
 		Tags:    tnames,
 		Pinned:  n.Pinned.Valid && n.Pinned.Bool,
 		Content: n.Content,
@@ -57,7 +106,121 @@ func WriteThrough(ctx context.Context, dbq *db.Queries, vlt *vault.Vault, n db.N
 	if n.UpdatedAt.Valid {
 		vn.Updated = n.UpdatedAt.Time
 	}
-	_, _ = vlt.Sync(vn)
+	if n.ArchivedAt.Valid {
+		vn.Archived = n.ArchivedAt.Time
+	}
+	if n.DeletedAt.Valid {
+		vn.Deleted = n.DeletedAt.Time
+	}
+	return vn
+}
+
+// SetAliases replaces a note's aliases, dropping blanks, duplicates, and the note's own title.
+func SetAliases(ctx context.Context, dbq *db.Queries, note db.Note, aliases []string) error {
+	if err := dbq.RemoveAllNoteAliases(ctx, note.ID); err != nil {
+		return fmt.Errorf("failed to clear aliases: %w", err)
+	}
+	for _, alias := range aliases {
+		alias = strings.TrimSpace(alias)
+		if alias == "" || alias == note.Title {
+			continue
+		}
+		if err := dbq.AddNoteAlias(ctx, db.AddNoteAliasParams{NoteID: note.ID, Alias: alias}); err != nil {
+			return fmt.Errorf("failed to add alias %q: %w", alias, err)
+		}
+	}
+	return nil
+}
+
+// SetMentions replaces a note's @mentions with the ones in content.
+func SetMentions(ctx context.Context, dbq *db.Queries, noteID int64, content string) error {
+	if err := dbq.RemoveAllNoteMentions(ctx, noteID); err != nil {
+		return fmt.Errorf("failed to clear mentions: %w", err)
+	}
+	for _, name := range markdown.Mentions(content) {
+		if err := dbq.AddNoteMention(ctx, db.AddNoteMentionParams{NoteID: noteID, Name: name}); err != nil {
+			return fmt.Errorf("failed to add mention %q: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// SetLinks replaces a note's outgoing links with the [[wikilinks]] in content, resolved by title
+// or alias. Links to notes that don't exist yet are skipped.
+func SetLinks(ctx context.Context, dbq *db.Queries, noteID int64, content string) error {
+	if err := dbq.DeleteNoteLinks(ctx, noteID); err != nil {
+		return fmt.Errorf("failed to clear links: %w", err)
+	}
+	added := make(map[int64]bool)
+	for _, l := range markdown.LinkTargets(content) {
+		target, err := dbq.ResolveNoteTitle(ctx, l.Target)
+		if err != nil || target.ID == noteID || added[target.ID] {
+			continue
+		}
+		added[target.ID] = true
+		if err := dbq.CreateNoteLink(ctx, db.CreateNoteLinkParams{
+			SourceNoteID: noteID,
+			TargetNoteID: target.ID,
+			LinkText:     l.Target,
+			Embed:        sql.NullBool{Bool: l.Embed, Valid: true},
+		}); err != nil {
+			return fmt.Errorf("failed to link to %q: %w", l.Target, err)
+		}
+	}
+	return nil
+}
+
+// SetPendingLinks replaces a note's pending links with the targets of the [[wikilinks]] in content.
+// Every target is kept, resolved or not: ListUnresolvedLinks checks them when it runs, so a link
+// also shows up once its note is renamed, trashed, or purged. Embedded attachments are not notes,
+// so they never count.
+func SetPendingLinks(ctx context.Context, dbq *db.Queries, noteID int64, content string) error {
+	if err := dbq.RemoveAllPendingLinks(ctx, noteID); err != nil {
+		return fmt.Errorf("failed to clear pending links: %w", err)
+	}
+	for _, l := range markdown.LinkTargets(content) {
+		if markdown.IsAttachment(l.Target) {
+			continue
+		}
+		if err := dbq.AddPendingLink(ctx, db.AddPendingLinkParams{NoteID: noteID, Target: l.Target}); err != nil {
+			return fmt.Errorf("failed to add pending link %q: %w", l.Target, err)
+		}
+	}
+	return nil
+}
+
+// SetLang stores the language detected in content as the note's language, or clears it when none
+// is detected.
+func SetLang(ctx context.Context, dbq *db.Queries, noteID int64, content string) error {
+	code := lang.Detect(content)
+	if err := dbq.SetNoteLang(ctx, db.SetNoteLangParams{
+		Lang: sql.NullString{String: code, Valid: code != ""},
+		ID:   noteID,
+	}); err != nil {
+		return fmt.Errorf("failed to set language: %w", err)
+	}
+	return nil
+}
+
+// Reindex rebuilds what is derived from every note's content, live or trashed: its @mentions, its
+// pending links, and its language.
+func Reindex(ctx context.Context, dbq *db.Queries) error {
+	notes, err := dbq.ListNoteContents(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get notes: %w", err)
+	}
+	for _, n := range notes {
+		if err := SetMentions(ctx, dbq, n.ID, n.Content); err != nil {
+			return err
+		}
+		if err := SetPendingLinks(ctx, dbq, n.ID, n.Content); err != nil {
+			return err
+		}
+		if err := SetLang(ctx, dbq, n.ID, n.Content); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 // Delete removes a note's vault file and its persisted version snapshots. Best-effort and a no-op
@@ -71,3 +71,92 @@ func TestWriteThroughMapsTagsFolderAndDeletes(t *testing.T) {
 		t.Error("note was not deleted from the vault")
 	}
 }
+
+func TestMentions(t *testing.T) {
+	ctx := context.Background()
+	conn, err := db.Open(filepath.Join(t.TempDir(), "t.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = conn.Close() }()
+	dbq := db.New(conn)
+
+	n, _ := dbq.CreateNote(ctx, db.CreateNoteParams{Title: "1:1", Content: "with @Ana and @bob"})
+	WriteThrough(ctx, dbq, nil, n) // indexes mentions without a vault
+	if got, _ := dbq.GetNoteMentions(ctx, n.ID); len(got) != 2 || got[0] != "ana" || got[1] != "bob" {
+		t.Errorf("mentions = %q", got)
+	}
+
+	es, _ := dbq.CreateNote(ctx, db.CreateNoteParams{Title: "Junta", Content: "Hablé con ella sobre el proyecto y la fecha de entrega."})
+	WriteThrough(ctx, dbq, nil, es)
+	if got, _ := dbq.GetNote(ctx, es.ID); got.Lang.String != "es" || got.UpdatedAt != es.UpdatedAt {
+		t.Errorf("lang = %q (updated_at %v, was %v), want es and unchanged", got.Lang.String, got.UpdatedAt, es.UpdatedAt)
+	}
+
+	// A note saved around WriteThrough is picked up by a reindex
+	_, _ = dbq.CreateNote(ctx, db.CreateNoteParams{Title: "Old", Content: "@ana said hi"})
+	if err := Reindex(ctx, dbq); err != nil {
+		t.Fatal(err)
+	}
+	notes, _ := dbq.GetNotesMentioning(ctx, "ana")
+	if len(notes) != 2 {
+		t.Errorf("notes mentioning ana = %d, want 2", len(notes))
+	}
+
+	n.Content = "just @bob now"
+	WriteThrough(ctx, dbq, nil, n)
+	names, _ := dbq.ListMentionNames(ctx)
+	if len(names) != 2 || names[0].Name != "ana" || names[0].NoteCount != 1 || names[1].Name != "bob" {
+		t.Errorf("mention names = %+v", names)
+	}
+}
+
+func TestPendingLinks(t *testing.T) {
+	ctx := context.Background()
+	conn, err := db.Open(filepath.Join(t.TempDir(), "t.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = conn.Close() }()
+	dbq := db.New(conn)
+
+	_, _ = dbq.CreateNote(ctx, db.CreateNoteParams{Title: "Go", Content: "x"})
+	a, _ := dbq.CreateNote(ctx, db.CreateNoteParams{Title: "A", Content: "[[Go]] [[Rust#Ownership]] ![[diagram.png]] [[Zig|zig]]"})
+	b, _ := dbq.CreateNote(ctx, db.CreateNoteParams{Title: "B", Content: "see [[Rust]]"})
+	WriteThrough(ctx, dbq, nil, a)
+	WriteThrough(ctx, dbq, nil, b)
+
+	rows, _ := dbq.ListUnresolvedLinks(ctx)
+	got := db.GroupUnresolvedLinks(rows, 0)
+	if len(got) != 2 || got[0].Target != "Rust" || len(got[0].Notes) != 2 || got[1].Target != "Zig" {
+		t.Fatalf("unresolved = %+v", got)
+	}
+	if got := db.GroupUnresolvedLinks(rows, b.ID); len(got) != 1 || got[0].Target != "Rust" || got[0].Notes[0].ID != b.ID {
+		t.Errorf("unresolved in B = %+v", got)
+	}
+
+	// Writing the note resolves the link, without re-saving the notes that link to it
+	_, _ = dbq.CreateNote(ctx, db.CreateNoteParams{Title: "Rust", Content: "x"})
+	rows, _ = dbq.ListUnresolvedLinks(ctx)
+	if got := db.GroupUnresolvedLinks(rows, 0); len(got) != 1 || got[0].Target != "Zig" {
+		t.Errorf("unresolved after writing Rust = %+v", got)
+	}
+
+	// Notes saved around WriteThrough are picked up by a reindex
+	_, _ = dbq.CreateNote(ctx, db.CreateNoteParams{Title: "C", Content: "[[Zig]]"})
+	if err := Reindex(ctx, dbq); err != nil {
+		t.Fatal(err)
+	}
+	rows, _ = dbq.ListUnresolvedLinks(ctx)
+	if got := db.GroupUnresolvedLinks(rows, 0); len(got) != 1 || len(got[0].Notes) != 2 {
+		t.Errorf("unresolved after reindex = %+v", got)
+	}
+
+	// Renaming a linked note breaks the links to its old title
+	goNote, _ := dbq.GetNoteByTitle(ctx, "Go")
+	_, _ = dbq.UpdateNote(ctx, db.UpdateNoteParams{ID: goNote.ID, Title: "Golang", Content: goNote.Content})
+	rows, _ = dbq.ListUnresolvedLinks(ctx)
+	if got := db.GroupUnresolvedLinks(rows, 0); len(got) != 2 || got[0].Target != "Go" || got[0].Notes[0].ID != a.ID {
+		t.Errorf("unresolved after renaming Go = %+v", got)
+	}
+}
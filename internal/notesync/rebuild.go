@@ -4,11 +4,11 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
-	"regexp"
 	"strings"
 	"time"
 
 	"github.com/abdul-hamid-achik/noted/internal/db"
+	"github.com/abdul-hamid-achik/noted/internal/markdown"
 	"github.com/abdul-hamid-achik/noted/internal/vault"
 )
 
@@ -21,25 +21,6 @@ type RebuildStats struct {
 	PreservedMemories int
 }
 
-var rebuildWikilinkRe = regexp.MustCompile(`\[\[([^\]]+)\]\]`)
-
-// rebuildLinkTitles returns the distinct [[wikilink]] targets in content ([[Title|alias]] → "Title").
-func rebuildLinkTitles(content string) []string {
-	var out []string
-	seen := map[string]bool{}
-	for _, m := range rebuildWikilinkRe.FindAllStringSubmatch(content, -1) {
-		t := strings.TrimSpace(m[1])
-		if i := strings.Index(t, "|"); i >= 0 {
-			t = strings.TrimSpace(t[:i])
-		}
-		if t != "" && !seen[t] {
-			seen[t] = true
-			out = append(out, t)
-		}
-	}
-	return out
-}
-
 // rebuildFolderPathID find-or-creates a folder hierarchy ("A/B/C") within the rebuild transaction,
 // preserving nesting and distinguishing same-name folders under different parents. Returns the leaf id.
 func rebuildFolderPathID(ctx context.Context, tx *sql.Tx, cache map[string]int64, path string) (int64, error) {
@@ -85,10 +66,12 @@ func rebuildFolderPathID(ctx context.Context, tx *sql.Tx, cache map[string]int64
 // Rebuild replaces the SQLite index (notes/tags/links/folders) with the contents of the vault,
 // treating the vault as the source of truth. Each note's frontmatter id is preserved where unique; a
 // duplicate id is re-inserted with a fresh autoincrement id (counted in RemappedIDs) rather than
-// aborting. Wikilinks resolve by title, skipping unknown or ambiguous (duplicate-title) targets.
+// aborting. Wikilinks resolve by title, then by frontmatter alias, skipping unknown or ambiguous
+// (duplicate-title or duplicate-alias) targets.
 // FTS stays current via the notes_fts triggers. Version history is preserved across the rebuild: it
 // is first persisted to the vault (.noted/versions/), then restored after notes are re-inserted —
 // because DELETE FROM notes cascades to note_versions, this persist-then-restore is what keeps history.
+// Trashed notes (.noted/trash/) come back trashed, with deleted_at set.
 func Rebuild(ctx context.Context, conn *sql.DB, vlt *vault.Vault) (RebuildStats, error) {
 	var stats RebuildStats
 	if conn == nil || vlt == nil {
@@ -98,6 +81,10 @@ func Rebuild(ctx context.Context, conn *sql.DB, vlt *vault.Vault) (RebuildStats,
 	if err != nil {
 		return stats, err
 	}
+	trashed, err := vlt.Trashed()
+	if err != nil {
+		return stats, err
+	}
 
 	// Persist current DB version history to the vault BEFORE clearing — DELETE FROM notes cascades to
 	// note_versions (ON DELETE CASCADE), so any snapshots not yet on disk would otherwise be lost.
@@ -120,7 +107,7 @@ func Rebuild(ctx context.Context, conn *sql.DB, vlt *vault.Vault) (RebuildStats,
 		return stats, fmt.Errorf("capture memories: %w", err)
 	}
 
-	for _, stmt := range []string{"DELETE FROM note_links", "DELETE FROM note_tags", "DELETE FROM notes", "DELETE FROM tags", "DELETE FROM folders"} {
+	for _, stmt := range []string{"DELETE FROM note_links", "DELETE FROM note_aliases", "DELETE FROM note_mentions", "DELETE FROM note_tags", "DELETE FROM notes", "DELETE FROM tags", "DELETE FROM folders"} {
 		if _, err := tx.ExecContext(ctx, stmt); err != nil {
 			return stats, fmt.Errorf("clear index: %w", err)
 		}
@@ -133,10 +120,14 @@ func Rebuild(ctx context.Context, conn *sql.DB, vlt *vault.Vault) (RebuildStats,
 	var refs []noteRef
 	titleToID := make(map[string]int64, len(notes))
 	titleCount := map[string]int{}
+	aliasToID := map[string]int64{}
+	aliasCount := map[string]int{}
 	usedID := map[int64]bool{}
 	folderCache := map[string]int64{}
 
-	for _, vn := range notes {
+	// Trashed notes come back trashed: they keep their tags and aliases for a later restore, but
+	// are not link targets.
+	for _, vn := range append(notes, trashed...) {
 		created, updated := vn.Created, vn.Updated
 		if created.IsZero() {
 			created = time.Now()
@@ -146,6 +137,13 @@ func Rebuild(ctx context.Context, conn *sql.DB, vlt *vault.Vault) (RebuildStats,
 		}
 		cs := created.UTC().Format("2006-01-02 15:04:05")
 		us := updated.UTC().Format("2006-01-02 15:04:05")
+		var archivedArg, deletedArg any
+		if !vn.Archived.IsZero() {
+			archivedArg = vn.Archived.UTC().Format("2006-01-02 15:04:05")
+		}
+		if !vn.Deleted.IsZero() {
+			deletedArg = vn.Deleted.UTC().Format("2006-01-02 15:04:05")
+		}
 
 		var folderArg any
 		if vn.Folder != "" {
@@ -159,8 +157,8 @@ func Rebuild(ctx context.Context, conn *sql.DB, vlt *vault.Vault) (RebuildStats,
 		var id int64
 		if vn.ID > 0 && !usedID[vn.ID] {
 			if _, err := tx.ExecContext(ctx,
-				"INSERT INTO notes (id, title, content, created_at, updated_at, pinned, folder_id) VALUES (?, ?, ?, ?, ?, ?, ?)",
-				vn.ID, vn.Title, vn.Content, cs, us, vn.Pinned, folderArg); err != nil {
+				"INSERT INTO notes (id, title, content, created_at, updated_at, pinned, folder_id, archived_at, deleted_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)",
+				vn.ID, vn.Title, vn.Content, cs, us, vn.Pinned, folderArg, archivedArg, deletedArg); err != nil {
 				return stats, fmt.Errorf("insert note %q: %w", vn.Title, err)
 			}
 			id = vn.ID
@@ -174,17 +172,35 @@ func Rebuild(ctx context.Context, conn *sql.DB, vlt *vault.Vault) (RebuildStats,
 				stats.RemappedIDs++
 			}
 			res, err := tx.ExecContext(ctx,
-				"INSERT INTO notes (title, content, created_at, updated_at, pinned, folder_id) VALUES (?, ?, ?, ?, ?, ?)",
-				vn.Title, vn.Content, cs, us, vn.Pinned, folderArg)
+				"INSERT INTO notes (title, content, created_at, updated_at, pinned, folder_id, archived_at, deleted_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?)",
+				vn.Title, vn.Content, cs, us, vn.Pinned, folderArg, archivedArg, deletedArg)
 			if err != nil {
 				return stats, fmt.Errorf("insert note %q: %w", vn.Title, err)
 			}
 			id, _ = res.LastInsertId()
 		}
 		usedID[id] = true
-		titleToID[vn.Title] = id
-		titleCount[vn.Title]++
 		refs = append(refs, noteRef{id: id, content: vn.Content})
+		live := deletedArg == nil
+		if live {
+			titleToID[vn.Title] = id
+			titleCount[vn.Title]++
+		}
+
+		for _, alias := range vn.Aliases {
+			alias = strings.TrimSpace(alias)
+			if alias == "" || alias == vn.Title {
+				continue
+			}
+			res, err := tx.ExecContext(ctx, "INSERT OR IGNORE INTO note_aliases (note_id, alias) VALUES (?, ?)", id, alias)
+			if err != nil {
+				return stats, err
+			}
+			if n, _ := res.RowsAffected(); n > 0 && live {
+				aliasToID[alias] = id
+				aliasCount[alias]++
+			}
+		}
 
 		for _, tname := range vn.Tags {
 			if _, err := tx.ExecContext(ctx, "INSERT OR IGNORE INTO tags (name) VALUES (?)", tname); err != nil {
@@ -201,14 +217,22 @@ func Rebuild(ctx context.Context, conn *sql.DB, vlt *vault.Vault) (RebuildStats,
 	}
 
 	for _, r := range refs {
-		for _, lt := range rebuildLinkTitles(r.content) {
-			if titleCount[lt] != 1 {
+		for _, l := range markdown.LinkTargets(r.content) {
+			lt := l.Target
+			// A title wins over an alias; either must name exactly one note
+			var tgt int64
+			switch {
+			case titleCount[lt] == 1:
+				tgt = titleToID[lt]
+			case titleCount[lt] == 0 && aliasCount[lt] == 1:
+				tgt = aliasToID[lt]
+			default:
 				continue
 			}
-			if tgt := titleToID[lt]; tgt != r.id {
+			if tgt != r.id {
 				if _, err := tx.ExecContext(ctx,
-					"INSERT INTO note_links (source_note_id, target_note_id, link_text) VALUES (?, ?, ?)",
-					r.id, tgt, lt); err != nil {
+					"INSERT INTO note_links (source_note_id, target_note_id, link_text, embed) VALUES (?, ?, ?, ?)",
+					r.id, tgt, lt, l.Embed); err != nil {
 					return stats, err
 				}
 				stats.Links++
@@ -223,6 +247,10 @@ func Rebuild(ctx context.Context, conn *sql.DB, vlt *vault.Vault) (RebuildStats,
 	}
 	stats.PreservedMemories = preservedMems
 
+	if err := Reindex(ctx, db.New(tx)); err != nil {
+		return stats, fmt.Errorf("index mentions and languages: %w", err)
+	}
+
 	// Restore version history from the vault into the freshly rebuilt index (idempotent).
 	restored, err := restoreVersions(ctx, tx, vlt)
 	if err != nil {
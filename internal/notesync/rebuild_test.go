@@ -59,3 +59,43 @@ func TestRebuildFromVault(t *testing.T) {
 		t.Fatalf("leaf folder = %v (err %v), want Reports", leaf.Name, err)
 	}
 }
+
+func TestRebuildResolvesLinksThroughAliases(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, body string) {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(body), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("go.md", "---\nid: 1\ntitle: Go\naliases: [Golang]\n---\n\ngo body\n")
+	write("notes.md", "---\nid: 2\ntitle: Notes\n---\n\nsee [[Golang]] and [[Go]]\n")
+
+	conn, err := db.Open(filepath.Join(t.TempDir(), "r.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = conn.Close() }()
+	vlt, err := vault.Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	if _, err := Rebuild(ctx, conn, vlt); err != nil {
+		t.Fatalf("Rebuild: %v", err)
+	}
+
+	q := db.New(conn)
+	aliases, err := q.GetNoteAliases(ctx, 1)
+	if err != nil || len(aliases) != 1 || aliases[0] != "Golang" {
+		t.Fatalf("aliases = %v (err %v), want [Golang]", aliases, err)
+	}
+	// Both the alias and the title link land on Go
+	links, err := q.GetOutlinks(ctx, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(links) != 2 || links[0].ID != 1 || links[1].ID != 1 {
+		t.Errorf("got %d links from Notes, want two to Go", len(links))
+	}
+}
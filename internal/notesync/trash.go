@@ -0,0 +1,77 @@
+package notesync
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/abdul-hamid-achik/noted/internal/db"
+	"github.com/abdul-hamid-achik/noted/internal/vault"
+)
+
+// Trash moves a note to the trash: deleted_at is set and its vault file moves to the vault's trash
+// directory. Tags, links, and version history are kept so Restore can bring the note back. Returns
+// sql.ErrNoRows when the note does not exist or is already in the trash. The caller removes the
+// note from the semantic index.
+func Trash(ctx context.Context, dbq *db.Queries, vlt *vault.Vault, id int64) error {
+	n, err := dbq.TrashNote(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to trash note #%d: %w", id, err)
+	}
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+	if vlt != nil {
+		if note, err := dbq.GetTrashedNote(ctx, id); err == nil {
+			_ = vlt.WriteTrashed(VaultNote(ctx, dbq, note))
+		}
+	}
+	return nil
+}
+
+// Restore takes a note out of the trash and writes it back to the vault. The note is marked for
+// re-embedding. Returns sql.ErrNoRows when the note is not in the trash.
+func Restore(ctx context.Context, dbq *db.Queries, vlt *vault.Vault, id int64) (db.Note, error) {
+	note, err := dbq.RestoreNote(ctx, id)
+	if err != nil {
+		return db.Note{}, err
+	}
+	if vlt != nil {
+		_ = vlt.DeleteTrashed(id)
+	}
+	WriteThrough(ctx, dbq, vlt, note)
+	return note, nil
+}
+
+// Purge permanently deletes a trashed note: its row, its trash file, and its version history.
+// Returns sql.ErrNoRows when the note is not in the trash.
+func Purge(ctx context.Context, dbq *db.Queries, vlt *vault.Vault, id int64) error {
+	if _, err := dbq.GetTrashedNote(ctx, id); err != nil {
+		return err
+	}
+	if err := dbq.DeleteNote(ctx, id); err != nil {
+		return fmt.Errorf("failed to purge note #%d: %w", id, err)
+	}
+	if vlt != nil {
+		_ = vlt.DeleteTrashed(id)
+		_ = vlt.DeleteVersions(id)
+	}
+	return nil
+}
+
+// PurgeTrash permanently deletes the notes trashed before cutoff and returns their ids.
+func PurgeTrash(ctx context.Context, dbq *db.Queries, vlt *vault.Vault, cutoff time.Time) ([]int64, error) {
+	notes, err := dbq.GetTrashedNotesBefore(ctx, sql.NullTime{Time: cutoff.UTC(), Valid: true})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list trash: %w", err)
+	}
+	var ids []int64
+	for _, n := range notes {
+		if err := Purge(ctx, dbq, vlt, n.ID); err != nil {
+			return ids, err
+		}
+		ids = append(ids, n.ID)
+	}
+	return ids, nil
+}
@@ -0,0 +1,116 @@
+package notesync
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/abdul-hamid-achik/noted/internal/db"
+	"github.com/abdul-hamid-achik/noted/internal/vault"
+)
+
+func TestTrashRestorePurge(t *testing.T) {
+	ctx := context.Background()
+	conn, err := db.Open(filepath.Join(t.TempDir(), "t.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = conn.Close() }()
+	dbq := db.New(conn)
+	vlt, err := vault.Open(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	n, _ := dbq.CreateNote(ctx, db.CreateNoteParams{Title: "Draft", Content: "body"})
+	tag, _ := dbq.CreateTag(ctx, "work")
+	_ = dbq.AddTagToNote(ctx, db.AddTagToNoteParams{NoteID: n.ID, TagID: tag.ID})
+	WriteThrough(ctx, dbq, vlt, n)
+
+	if err := Trash(ctx, dbq, vlt, n.ID); err != nil {
+		t.Fatalf("Trash: %v", err)
+	}
+	if err := Trash(ctx, dbq, vlt, n.ID); err != sql.ErrNoRows {
+		t.Errorf("trashing twice: got %v, want sql.ErrNoRows", err)
+	}
+	if _, err := dbq.GetNote(ctx, n.ID); err != sql.ErrNoRows {
+		t.Errorf("trashed note should be hidden from GetNote, got %v", err)
+	}
+	if _, ok := vfind(vlt, n.ID); ok {
+		t.Error("trashed note should leave the vault")
+	}
+	if trashed, _ := vlt.Trashed(); len(trashed) != 1 || trashed[0].Tags[0] != "work" {
+		t.Errorf("expected the note with its tags in the vault trash, got %+v", trashed)
+	}
+
+	restored, err := Restore(ctx, dbq, vlt, n.ID)
+	if err != nil || restored.DeletedAt.Valid || restored.EmbeddingSynced.Bool {
+		t.Fatalf("Restore = %+v, %v", restored, err)
+	}
+	if _, ok := vfind(vlt, n.ID); !ok {
+		t.Error("restored note should be back in the vault")
+	}
+	if tags, _ := dbq.GetTagsForNote(ctx, n.ID); len(tags) != 1 {
+		t.Errorf("restored note lost its tags: %v", tags)
+	}
+	if _, err := Restore(ctx, dbq, vlt, n.ID); err != sql.ErrNoRows {
+		t.Errorf("restoring a live note: got %v, want sql.ErrNoRows", err)
+	}
+
+	// Purge only touches trashed notes older than the cutoff
+	if err := Purge(ctx, dbq, vlt, n.ID); err != sql.ErrNoRows {
+		t.Errorf("purging a live note: got %v, want sql.ErrNoRows", err)
+	}
+	_ = Trash(ctx, dbq, vlt, n.ID)
+	if ids, err := PurgeTrash(ctx, dbq, vlt, time.Now().Add(-time.Hour)); err != nil || len(ids) != 0 {
+		t.Errorf("PurgeTrash with an old cutoff = %v, %v; want nothing", ids, err)
+	}
+	if ids, err := PurgeTrash(ctx, dbq, vlt, time.Now().Add(time.Minute)); err != nil || len(ids) != 1 {
+		t.Errorf("PurgeTrash = %v, %v; want [%d]", ids, err, n.ID)
+	}
+	if _, err := dbq.GetTrashedNote(ctx, n.ID); err != sql.ErrNoRows {
+		t.Errorf("purged note should be gone, got %v", err)
+	}
+	if trashed, _ := vlt.Trashed(); len(trashed) != 0 {
+		t.Errorf("purged note should leave the vault trash, got %d", len(trashed))
+	}
+}
+
+func TestRebuildKeepsTrash(t *testing.T) {
+	ctx := context.Background()
+	conn, err := db.Open(filepath.Join(t.TempDir(), "t.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = conn.Close() }()
+	dbq := db.New(conn)
+	vlt, err := vault.Open(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	live, _ := dbq.CreateNote(ctx, db.CreateNoteParams{Title: "Live", Content: "see [[Gone]]"})
+	gone, _ := dbq.CreateNote(ctx, db.CreateNoteParams{Title: "Gone", Content: "old"})
+	WriteThrough(ctx, dbq, vlt, live)
+	WriteThrough(ctx, dbq, vlt, gone)
+	if err := Trash(ctx, dbq, vlt, gone.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	stats, err := Rebuild(ctx, conn, vlt)
+	if err != nil {
+		t.Fatalf("Rebuild: %v", err)
+	}
+	if stats.Notes != 1 || stats.Links != 0 {
+		t.Errorf("stats = %+v, want 1 live note and no link to the trashed one", stats)
+	}
+	trashed, err := dbq.GetTrashedNote(ctx, gone.ID)
+	if err != nil || trashed.Title != "Gone" {
+		t.Fatalf("trashed note after rebuild = %+v, %v", trashed, err)
+	}
+	if _, err := Restore(ctx, dbq, vlt, gone.ID); err != nil {
+		t.Errorf("restore after rebuild: %v", err)
+	}
+}
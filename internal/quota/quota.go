@@ -0,0 +1,148 @@
+// Package quota checks note size, database size, and attachment storage against configurable soft
+// limits, which only warn, and hard limits, which refuse the write.
+package quota
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// ErrExceeded is wrapped by the error CheckNote returns when a hard limit is reached.
+var ErrExceeded = errors.New("quota exceeded")
+
+// Limit is a soft (Warn) and hard (Max) limit in bytes. Zero disables either.
+type Limit struct {
+	Warn int64
+	Max  int64
+}
+
+func (l Limit) enabled() bool { return l.Warn > 0 || l.Max > 0 }
+
+// Quotas are the limits on a single note's content, the database file, and the vault's
+// attachments.
+type Quotas struct {
+	NoteSize    Limit
+	DBSize      Limit
+	Attachments Limit
+}
+
+// Usage is how close one measured quantity is to its limits.
+type Usage struct {
+	Name   string `json:"name"`
+	Used   int64  `json:"used_bytes"`
+	Warn   int64  `json:"warn_bytes,omitempty"`
+	Max    int64  `json:"max_bytes,omitempty"`
+	Status string `json:"status"` // "ok", "warning", or "exceeded"
+}
+
+// Usage status values.
+const (
+	StatusOK       = "ok"
+	StatusWarning  = "warning"
+	StatusExceeded = "exceeded"
+)
+
+// Measure returns the usage of used bytes against l.
+func (l Limit) Measure(name string, used int64) Usage {
+	u := Usage{Name: name, Used: used, Warn: l.Warn, Max: l.Max, Status: StatusOK}
+	switch {
+	case l.Max > 0 && used >= l.Max:
+		u.Status = StatusExceeded
+	case l.Warn > 0 && used >= l.Warn:
+		u.Status = StatusWarning
+	}
+	return u
+}
+
+// Message describes a usage past its warning level or limit, or returns "" when it is within both.
+func (u Usage) Message() string {
+	switch u.Status {
+	case StatusExceeded:
+		return fmt.Sprintf("%s is %s, at or over its %s limit", u.Name, FormatSize(u.Used), FormatSize(u.Max))
+	case StatusWarning:
+		return fmt.Sprintf("%s is %s, past its %s warning level", u.Name, FormatSize(u.Used), FormatSize(u.Warn))
+	}
+	return ""
+}
+
+// CheckNote checks content about to be written to a note. For a new note, dbSize and attachments
+// measure the database and the vault's attachments; they are called only when a limit for them is
+// set, and may be nil to skip those checks, as edits do so that an over-quota vault can still be
+// trimmed. It returns a warning for every quota past its warning level, and an error wrapping
+// ErrExceeded for the first one at its hard limit.
+func (q Quotas) CheckNote(content string, dbSize, attachments func() int64) ([]string, error) {
+	usages := []Usage{q.NoteSize.Measure("note", int64(len(content)))}
+	if dbSize != nil && q.DBSize.enabled() {
+		usages = append(usages, q.DBSize.Measure("database", dbSize()))
+	}
+	if attachments != nil && q.Attachments.enabled() {
+		usages = append(usages, q.Attachments.Measure("attachments", attachments()))
+	}
+
+	var warnings []string
+	for _, u := range usages {
+		switch u.Status {
+		case StatusExceeded:
+			return warnings, fmt.Errorf("%w: %s", ErrExceeded, u.Message())
+		case StatusWarning:
+			warnings = append(warnings, u.Message())
+		}
+	}
+	return warnings, nil
+}
+
+// DirSize returns the total size of the regular files directly in dir, or 0 if it does not exist.
+func DirSize(dir string) int64 {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return 0
+	}
+	var total int64
+	for _, e := range entries {
+		if !e.Type().IsRegular() {
+			continue
+		}
+		if info, err := e.Info(); err == nil {
+			total += info.Size()
+		}
+	}
+	return total
+}
+
+// FormatSize formats a byte count for people: "512 B", "1.5 MB".
+func FormatSize(b int64) string {
+	const unit = 1024
+	if b < unit {
+		return fmt.Sprintf("%d B", b)
+	}
+	div, exp := int64(unit), 0
+	for n := b / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(b)/float64(div), "KMGTPE"[exp])
+}
+
+// ParseSize parses a byte count written as a number with an optional unit: "1048576", "512KB",
+// "10MB", "2 GB". Units are powers of 1024.
+func ParseSize(size string) (int64, error) {
+	s := strings.ToUpper(strings.TrimSpace(size))
+	mult := int64(1)
+	for _, u := range []struct {
+		suffix string
+		mult   int64
+	}{{"KB", 1 << 10}, {"MB", 1 << 20}, {"GB", 1 << 30}, {"K", 1 << 10}, {"M", 1 << 20}, {"G", 1 << 30}, {"B", 1}} {
+		if rest, ok := strings.CutSuffix(s, u.suffix); ok {
+			s, mult = strings.TrimSpace(rest), u.mult
+			break
+		}
+	}
+	n, err := strconv.ParseFloat(s, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid size %q", size)
+	}
+	return int64(n * float64(mult)), nil
+}
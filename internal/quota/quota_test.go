@@ -0,0 +1,65 @@
+package quota
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCheckNote(t *testing.T) {
+	q := Quotas{
+		NoteSize: Limit{Warn: 10, Max: 20},
+		DBSize:   Limit{Max: 100},
+	}
+	small := func() int64 { return 50 }
+
+	if w, err := q.CheckNote("short", small, nil); err != nil || len(w) != 0 {
+		t.Errorf("small note: warnings %q, err %v", w, err)
+	}
+	if w, err := q.CheckNote("a bit longer note", small, nil); err != nil || len(w) != 1 {
+		t.Errorf("note past its warning level: warnings %q, err %v", w, err)
+	}
+	if _, err := q.CheckNote("a note that is far too long", small, nil); !errors.Is(err, ErrExceeded) {
+		t.Errorf("oversized note: err = %v, want ErrExceeded", err)
+	}
+
+	full := func() int64 { return 100 }
+	if _, err := q.CheckNote("short", full, nil); !errors.Is(err, ErrExceeded) {
+		t.Errorf("full database: err = %v, want ErrExceeded", err)
+	}
+	if _, err := q.CheckNote("short", nil, nil); err != nil {
+		t.Errorf("edits skip the database limit, got %v", err)
+	}
+
+	called := false
+	_, _ = Quotas{}.CheckNote("x", func() int64 { called = true; return 0 }, nil)
+	if called {
+		t.Error("database size should not be measured without a database limit")
+	}
+}
+
+func TestParseSize(t *testing.T) {
+	for in, want := range map[string]int64{"1048576": 1 << 20, "512KB": 512 << 10, "10mb": 10 << 20, "2 GB": 2 << 30, "1.5M": 3 << 19, "0": 0} {
+		if got, err := ParseSize(in); err != nil || got != want {
+			t.Errorf("ParseSize(%q) = %d, %v; want %d", in, got, err, want)
+		}
+	}
+	for _, in := range []string{"", "lots", "-1MB"} {
+		if _, err := ParseSize(in); err == nil {
+			t.Errorf("ParseSize(%q) should fail", in)
+		}
+	}
+}
+
+func TestDirSize(t *testing.T) {
+	dir := t.TempDir()
+	_ = os.WriteFile(filepath.Join(dir, "a"), make([]byte, 3), 0o644)
+	_ = os.WriteFile(filepath.Join(dir, "b"), make([]byte, 4), 0o644)
+	if got := DirSize(dir); got != 7 {
+		t.Errorf("DirSize = %d, want 7", got)
+	}
+	if got := DirSize(filepath.Join(dir, "missing")); got != 0 {
+		t.Errorf("DirSize of a missing dir = %d", got)
+	}
+}
@@ -0,0 +1,128 @@
+// Package sanitize cleans up note content written by agents and other programs: control
+// characters, mixed line endings, runs of blank lines, and optionally HTML markup, which break
+// exports and the markdown vault.
+package sanitize
+
+import (
+	"html"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+var (
+	htmlDropRe  = regexp.MustCompile(`(?is)<(script|style|head)[^>]*>.*?</(script|style|head)>`)
+	htmlBreakRe = regexp.MustCompile(`(?i)<br\s*/?>|</(p|div|li|tr|h[1-6])>`)
+	htmlTagRe   = regexp.MustCompile(`</?[a-zA-Z!][^>]*>`)
+	blankRunRe  = regexp.MustCompile(`\n{3,}`)
+)
+
+// Policy says how Apply cleans content. The zero Policy leaves content alone.
+type Policy struct {
+	Enabled       bool
+	MaxBlankLines int  // longest run of blank lines kept; 0 keeps any
+	StripHTML     bool // reduce HTML markup to its text
+}
+
+// Apply returns content cleaned up by p: line endings become "\n", control characters other than
+// tabs and newlines are dropped, and outside fenced code blocks, HTML is stripped (with StripHTML)
+// and runs of blank lines are cut to MaxBlankLines.
+func (p Policy) Apply(content string) string {
+	if !p.Enabled {
+		return content
+	}
+	content = strings.ReplaceAll(content, "\r\n", "\n")
+	content = strings.ReplaceAll(content, "\r", "\n")
+	content = stripControl(content)
+
+	// Work on the text between fenced code blocks, leaving the blocks as written
+	var out, prose []string
+	flush := func() {
+		if len(prose) == 0 {
+			return
+		}
+		text := strings.Join(prose, "\n")
+		if p.StripHTML {
+			text = StripHTML(text)
+		}
+		out = append(out, capBlankLines(strings.Split(text, "\n"), p.MaxBlankLines)...)
+		prose = nil
+	}
+	var fence string
+	for _, line := range strings.Split(content, "\n") {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case fence != "":
+			out = append(out, line)
+			if strings.HasPrefix(trimmed, fence) {
+				fence = ""
+			}
+		case strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~"):
+			flush()
+			fence = trimmed[:3]
+			out = append(out, line)
+		default:
+			prose = append(prose, line)
+		}
+	}
+	flush()
+	return strings.Join(out, "\n")
+}
+
+// Title returns title cleaned up by p: control characters are dropped and line breaks become
+// spaces.
+func (p Policy) Title(title string) string {
+	if !p.Enabled {
+		return title
+	}
+	return strings.Join(strings.Fields(stripControl(strings.ReplaceAll(title, "\r", "\n"))), " ")
+}
+
+// stripControl drops control characters other than tab and newline.
+func stripControl(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r != '\t' && r != '\n' && unicode.IsControl(r) {
+			return -1
+		}
+		return r
+	}, s)
+}
+
+// capBlankLines keeps at most max consecutive blank lines; max 0 keeps them all.
+func capBlankLines(lines []string, max int) []string {
+	if max <= 0 {
+		return lines
+	}
+	out := lines[:0]
+	blank := 0
+	for _, l := range lines {
+		if strings.TrimSpace(l) == "" {
+			if blank++; blank > max {
+				continue
+			}
+		} else {
+			blank = 0
+		}
+		out = append(out, l)
+	}
+	return out
+}
+
+// StripHTML removes HTML tags, and script, style, and head elements with their contents, and
+// decodes entities. Line breaks and the ends of block elements become newlines; other whitespace
+// is kept as written.
+func StripHTML(s string) string {
+	s = htmlDropRe.ReplaceAllString(s, "")
+	s = htmlBreakRe.ReplaceAllString(s, "\n")
+	return html.UnescapeString(htmlTagRe.ReplaceAllString(s, ""))
+}
+
+// HTMLToText reduces an HTML document, such as an email body, to its text, keeping paragraph
+// breaks.
+func HTMLToText(s string) string {
+	lines := strings.Split(StripHTML(s), "\n")
+	for i, l := range lines {
+		lines[i] = strings.TrimSpace(l)
+	}
+	return blankRunRe.ReplaceAllString(strings.Join(lines, "\n"), "\n\n")
+}
@@ -0,0 +1,27 @@
+package sanitize
+
+import "testing"
+
+func TestApply(t *testing.T) {
+	p := Policy{Enabled: true, MaxBlankLines: 1}
+	in := "line one\r\nbell\x07 and nul\x00\r\n\n\n\nend\n```\nkeep\n\n\n\nblank lines\n```\n\tindented <b>bold</b>"
+	want := "line one\nbell and nul\n\nend\n```\nkeep\n\n\n\nblank lines\n```\n\tindented <b>bold</b>"
+	if got := p.Apply(in); got != want {
+		t.Errorf("Apply =\n%q\nwant\n%q", got, want)
+	}
+
+	p.StripHTML = true
+	if got := p.Apply("<p>Hi &amp; bye</p>\n- a < b, <i>c</i>\n```\n<div>code</div>\n```"); got != "Hi & bye\n\n- a < b, c\n```\n<div>code</div>\n```" {
+		t.Errorf("Apply with StripHTML = %q", got)
+	}
+
+	if got := (Policy{}).Apply("a\r\n\x07"); got != "a\r\n\x07" {
+		t.Errorf("the zero Policy should leave content alone, got %q", got)
+	}
+}
+
+func TestHTMLToText(t *testing.T) {
+	if got := HTMLToText("<html><head><title>x</title></head><body><p>Hello&amp;bye</p><br>next</body></html>"); got != "Hello&bye\n\nnext" {
+		t.Errorf("HTMLToText = %q", got)
+	}
+}
@@ -60,7 +60,7 @@ func (o *linksOverlay) refilter() {
 
 func (o *linksOverlay) follow(a *App, title string) tea.Cmd {
 	if a.db != nil {
-		if note, err := a.db.GetNoteByTitle(a.ctx, title); err == nil {
+		if note, err := a.db.ResolveNoteTitle(a.ctx, title); err == nil {
 			return a.openEditor(note, false)
 		}
 	}
@@ -25,12 +25,16 @@ type switcherOverlay struct {
 	title    string
 	input    textinput.Model
 	notes    []db.Note
+	aliases  map[int64][]string // matched alongside titles; nil for the backlinks list
 	filtered []int
 	cursor   int
 }
 
-func newSwitcher(notes []db.Note) (*switcherOverlay, tea.Cmd) {
-	return newNoteListOverlay("Jump to Note", "Jump to note…", notes)
+func newSwitcher(notes []db.Note, aliases map[int64][]string) (*switcherOverlay, tea.Cmd) {
+	s, cmd := newNoteListOverlay("Jump to Note", "Jump to note…", notes)
+	s.aliases = aliases
+	s.refilter()
+	return s, cmd
 }
 
 // newNoteListOverlay is a fuzzy note picker reused by the quick switcher and the backlinks panel.
@@ -56,7 +60,7 @@ func (s *switcherOverlay) refilter() {
 	} else {
 		titles := make([]string, len(s.notes))
 		for i, n := range s.notes {
-			titles[i] = strings.ToLower(noteItem{note: n}.Title())
+			titles[i] = strings.ToLower(strings.Join(append([]string{noteItem{note: n}.Title()}, s.aliases[n.ID]...), " "))
 		}
 		for _, m := range fuzzy.Find(strings.ToLower(q), titles) {
 			s.filtered = append(s.filtered, m.Index)
@@ -247,10 +247,15 @@ func (a *App) handleGlobalKey(msg tea.KeyMsg) (tea.Cmd, bool) {
 		return cmd, true
 	case "ctrl+o":
 		var notes []db.Note
+		aliases := map[int64][]string{}
 		if a.db != nil {
 			notes, _ = a.db.ListNotes(a.ctx, db.ListNotesParams{Limit: 500, Offset: 0})
+			rows, _ := a.db.ListNoteAliases(a.ctx)
+			for _, r := range rows {
+				aliases[r.NoteID] = append(aliases[r.NoteID], r.Alias)
+			}
 		}
-		ov, cmd := newSwitcher(notes)
+		ov, cmd := newSwitcher(notes, aliases)
 		a.overlay = ov
 		return cmd, true
 	case "?":
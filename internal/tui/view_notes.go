@@ -11,10 +11,12 @@ import (
 	"charm.land/lipgloss/v2"
 	zone "github.com/lrstanley/bubblezone/v2"
 
+	"github.com/abdul-hamid-achik/noted/internal/config"
 	"github.com/abdul-hamid-achik/noted/internal/db"
 	"github.com/abdul-hamid-achik/noted/internal/notesync"
 	"github.com/abdul-hamid-achik/noted/internal/tui/layout"
 	"github.com/abdul-hamid-achik/noted/internal/tui/theme"
+	"github.com/abdul-hamid-achik/noted/internal/veclite"
 )
 
 // noteItem adapts a db.Note to the bubbles list item interface.
@@ -187,11 +189,11 @@ func (v *notesView) update(a *App, msg tea.Msg) tea.Cmd {
 				if it, ok := v.list.SelectedItem().(noteItem); ok {
 					if v.pendingDelete == it.note.ID {
 						v.pendingDelete = 0
-						a.status = "note deleted"
+						a.status = "note moved to the trash"
 						return v.deleteCmd(a, it.note.ID)
 					}
 					v.pendingDelete = it.note.ID
-					a.status = "Press d again to delete: " + it.Title()
+					a.status = "Press d again to move to the trash: " + it.Title()
 				}
 				return nil
 			case "esc":
@@ -210,16 +212,18 @@ func (v *notesView) update(a *App, msg tea.Msg) tea.Cmd {
 	return cmd
 }
 
-// deleteCmd deletes a note (db + vault) and reloads the list.
+// deleteCmd moves a note to the trash (db + vault) and reloads the list.
 func (v *notesView) deleteCmd(a *App, id int64) tea.Cmd {
 	ctx, dbq, vlt, w := a.ctx, a.db, a.vlt, a.watcher
 	reload := v.load(a) // captures the current filter
 	return func() tea.Msg {
-		if err := dbq.DeleteNote(ctx, id); err != nil {
+		w.PauseSelfWrite() // our own delete — don't trigger a watcher rebuild
+		if err := notesync.Trash(ctx, dbq, vlt, id); err != nil {
 			return errMsg{err}
 		}
-		w.PauseSelfWrite() // our own delete — don't trigger a watcher rebuild
-		notesync.Delete(vlt, id)
+		if cfg, err := config.Load(); err == nil && cfg.VeclitePath != "" {
+			_ = veclite.DeleteNotes(cfg.VeclitePath, id) // best-effort; don't leave ghost search results
+		}
 		return reload()
 	}
 }
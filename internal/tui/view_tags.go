@@ -2,6 +2,7 @@ package tui
 
 import (
 	"fmt"
+	"io"
 	"strconv"
 
 	"charm.land/bubbles/v2/list"
@@ -17,22 +18,45 @@ import (
 const tagsZonePrefix = "tag:"
 
 type tagItem struct {
-	name  string
-	count int64
+	name        string
+	count       int64
+	color       string
+	description string
 }
 
-func (i tagItem) Title() string       { return "#" + i.name }
-func (i tagItem) Description() string  { return fmt.Sprintf("%d notes", i.count) }
+func (i tagItem) Title() string { return "#" + i.name }
+func (i tagItem) Description() string {
+	if i.description != "" {
+		return fmt.Sprintf("%d notes · %s", i.count, i.description)
+	}
+	return fmt.Sprintf("%d notes", i.count)
+}
 func (i tagItem) FilterValue() string { return i.name }
 
-type tagsLoadedMsg struct{ tags []db.GetTagsWithCountRow }
+// tagDelegate renders each tag's title in the color set with `noted tags edit --color`.
+// It recolors the title style rather than the title text so filter highlighting still
+// lines up with the plain name.
+type tagDelegate struct {
+	zoneDelegate
+}
+
+func (d tagDelegate) Render(w io.Writer, m list.Model, index int, item list.Item) {
+	if it, ok := item.(tagItem); ok && it.color != "" {
+		c := lipgloss.Color(it.color)
+		d.Styles.NormalTitle = d.Styles.NormalTitle.Foreground(c)
+		d.Styles.SelectedTitle = d.Styles.SelectedTitle.Foreground(c)
+	}
+	d.zoneDelegate.Render(w, m, index, item)
+}
+
+type tagsLoadedMsg struct{ tags []db.Tag }
 
 type tagsView struct {
 	list list.Model
 }
 
 func newTagsView() *tagsView {
-	d := zoneDelegate{DefaultDelegate: theme.NewItemDelegate(), prefix: tagsZonePrefix}
+	d := tagDelegate{zoneDelegate{DefaultDelegate: theme.NewItemDelegate(), prefix: tagsZonePrefix}}
 	l := list.New(nil, d, 0, 0)
 	l.Title = "Tags"
 	l.SetShowHelp(false)
@@ -69,7 +93,7 @@ func (v *tagsView) update(a *App, msg tea.Msg) tea.Cmd {
 	case tagsLoadedMsg:
 		items := make([]list.Item, len(msg.tags))
 		for i, t := range msg.tags {
-			items[i] = tagItem{name: t.Name, count: t.NoteCount}
+			items[i] = tagItem{name: t.Name, count: t.NoteCount, color: t.Color, description: t.Description}
 		}
 		v.list.SetItems(items)
 		return nil
@@ -0,0 +1,38 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	"charm.land/bubbles/v2/list"
+	zone "github.com/lrstanley/bubblezone/v2"
+
+	"github.com/abdul-hamid-achik/noted/internal/tui/theme"
+)
+
+func TestTagDelegateColorsTitle(t *testing.T) {
+	zone.NewGlobal()
+
+	v := newTagsView()
+	v.list.SetItems([]list.Item{
+		tagItem{name: "work", count: 2, color: "#ff8800"},
+		tagItem{name: "misc", count: 1},
+	})
+	v.list.SetSize(40, 20)
+
+	d := tagDelegate{zoneDelegate{DefaultDelegate: theme.NewItemDelegate(), prefix: tagsZonePrefix}}
+	render := func(index int) string {
+		var buf strings.Builder
+		d.Render(&buf, v.list, index, v.list.Items()[index])
+		return buf.String()
+	}
+
+	// #ff8800 as a 24-bit foreground SGR sequence.
+	const orange = "38;2;255;136;0"
+	if got := render(0); !strings.Contains(got, orange) || !strings.Contains(got, "#work") {
+		t.Errorf("colored tag title = %q, want #work in %s", got, orange)
+	}
+	if got := render(1); strings.Contains(got, orange) {
+		t.Errorf("uncolored tag title = %q, should not use the other tag's color", got)
+	}
+}
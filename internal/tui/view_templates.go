@@ -25,6 +25,7 @@ func interpolateTemplate(content, title string) string {
 		"{{time}}", t.Format("15:04"),
 		"{{datetime}}", t.Format("2006-01-02 15:04"),
 		"{{title}}", title,
+		"{{cursor}}", "",
 	).Replace(content)
 }
 
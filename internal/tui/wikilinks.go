@@ -2,28 +2,18 @@ package tui
 
 import (
 	"context"
-	"regexp"
-	"strings"
+	"database/sql"
 
 	"github.com/abdul-hamid-achik/noted/internal/db"
+	"github.com/abdul-hamid-achik/noted/internal/markdown"
 )
 
-// wikilinkRe matches Obsidian-style [[wikilinks]] (same pattern as the CLI's links command).
-var wikilinkRe = regexp.MustCompile(`\[\[([^\]]+)\]\]`)
-
-// parseWikilinks returns the distinct link targets in content. [[Title|alias]] resolves to "Title".
+// parseWikilinks returns the distinct link targets in content. [[Title|alias]] and
+// [[Title#Section]] resolve to "Title"; ![[embeds]] count as links.
 func parseWikilinks(content string) []string {
 	var out []string
-	seen := map[string]bool{}
-	for _, m := range wikilinkRe.FindAllStringSubmatch(content, -1) {
-		t := strings.TrimSpace(m[1])
-		if i := strings.Index(t, "|"); i >= 0 {
-			t = strings.TrimSpace(t[:i])
-		}
-		if t != "" && !seen[t] {
-			seen[t] = true
-			out = append(out, t)
-		}
+	for _, l := range markdown.LinkTargets(content) {
+		out = append(out, l.Target)
 	}
 	return out
 }
@@ -36,14 +26,15 @@ func syncNoteLinks(ctx context.Context, dbq *db.Queries, sourceID int64, content
 	}
 	_ = dbq.DeleteNoteLinks(ctx, sourceID)
 	added := map[int64]bool{}
-	for _, title := range parseWikilinks(content) {
-		target, err := dbq.GetNoteByTitle(ctx, title)
+	for _, l := range markdown.LinkTargets(content) {
+		target, err := dbq.ResolveNoteTitle(ctx, l.Target) // title or alias
 		if err != nil || target.ID == sourceID || added[target.ID] {
 			continue
 		}
 		added[target.ID] = true
 		_ = dbq.CreateNoteLink(ctx, db.CreateNoteLinkParams{
-			SourceNoteID: sourceID, TargetNoteID: target.ID, LinkText: title,
+			SourceNoteID: sourceID, TargetNoteID: target.ID, LinkText: l.Target,
+			Embed: sql.NullBool{Bool: l.Embed, Valid: true},
 		})
 	}
 }
@@ -75,6 +75,24 @@ func TestSyncNoteLinksRebuild(t *testing.T) {
 	}
 }
 
+func TestSyncNoteLinksEmbeds(t *testing.T) {
+	dbq, ctx := newTestQueries(t)
+	src, _ := dbq.CreateNote(ctx, db.CreateNoteParams{Title: "Page"})
+	spec, _ := dbq.CreateNote(ctx, db.CreateNoteParams{Title: "Spec"})
+	other, _ := dbq.CreateNote(ctx, db.CreateNoteParams{Title: "Other"})
+
+	syncNoteLinks(ctx, dbq, src.ID, "[[Spec]] then ![[Spec#Goals]], and [[Other#Intro|other]]")
+
+	links, _ := dbq.GetAllNoteLinks(ctx)
+	embeds := map[int64]bool{}
+	for _, l := range links {
+		embeds[l.TargetNoteID] = l.Embed.Bool
+	}
+	if len(links) != 2 || !embeds[spec.ID] || embeds[other.ID] {
+		t.Errorf("links = %+v, want Spec as an embed and Other as a plain link", links)
+	}
+}
+
 func TestDailyScheme(t *testing.T) {
 	const prefix = "Daily Note "
 	title := dailyTitle()
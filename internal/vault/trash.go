@@ -0,0 +1,73 @@
+package vault
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// trashDir holds the files of trashed notes, one <id>.md each with a "deleted" timestamp in the
+// frontmatter. Like the versions directory it is ignored by List, so trashed notes leave the vault
+// but a rebuild can still bring them back into the index as trashed.
+func (v *Vault) trashDir() string { return filepath.Join(v.dir, ".noted", "trash") }
+
+func (v *Vault) trashPath(id int64) string {
+	return filepath.Join(v.trashDir(), strconv.FormatInt(id, 10)+".md")
+}
+
+// WriteTrashed writes a trashed note to .noted/trash/<id>.md and removes its file from the vault.
+// n.Deleted should be set; it defaults to now.
+func (v *Vault) WriteTrashed(n Note) error {
+	if n.ID <= 0 {
+		return fmt.Errorf("vault: trashed note needs a positive id")
+	}
+	if n.Deleted.IsZero() {
+		n.Deleted = now()
+	}
+	if err := os.MkdirAll(v.trashDir(), 0o755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(v.trashPath(n.ID), []byte(Serialize(n)), 0o644); err != nil {
+		return err
+	}
+	return v.DeleteByID(n.ID)
+}
+
+// DeleteTrashed removes a note's trash file (no-op if absent), on restore or purge.
+func (v *Vault) DeleteTrashed(id int64) error {
+	err := os.Remove(v.trashPath(id))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
+
+// Trashed reads every trashed note, oldest deletion first.
+func (v *Vault) Trashed() ([]Note, error) {
+	entries, err := os.ReadDir(v.trashDir())
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var notes []Note
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".md") {
+			continue
+		}
+		n, err := v.Read(filepath.Join(v.trashDir(), e.Name()))
+		if err != nil || n.ID <= 0 {
+			continue
+		}
+		if n.Deleted.IsZero() {
+			n.Deleted = n.Updated
+		}
+		notes = append(notes, n)
+	}
+	sort.Slice(notes, func(i, j int) bool { return notes[i].Deleted.Before(notes[j].Deleted) })
+	return notes, nil
+}
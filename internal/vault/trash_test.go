@@ -0,0 +1,51 @@
+package vault
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTrashRoundTrip(t *testing.T) {
+	v, err := Open(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	written, err := v.Write(Note{ID: 7, Title: "Old idea", Tags: []string{"x"}, Content: "body"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(Serialize(written), "deleted:") {
+		t.Error("live notes should not have a deleted field")
+	}
+
+	deleted := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	written.Deleted = deleted
+	if err := v.WriteTrashed(written); err != nil {
+		t.Fatal(err)
+	}
+	if notes, _ := v.List(); len(notes) != 0 {
+		t.Errorf("trashed note should leave the vault, List = %d notes", len(notes))
+	}
+
+	trashed, err := v.Trashed()
+	if err != nil || len(trashed) != 1 {
+		t.Fatalf("Trashed = %v (err %v), want one note", trashed, err)
+	}
+	if got := trashed[0]; got.ID != 7 || got.Title != "Old idea" || !got.Deleted.Equal(deleted) || got.Content != "body" {
+		t.Errorf("unexpected trashed note: %+v", got)
+	}
+
+	if err := v.DeleteTrashed(7); err != nil {
+		t.Fatal(err)
+	}
+	if err := v.DeleteTrashed(7); err != nil {
+		t.Errorf("deleting a missing trash file should be a no-op, got %v", err)
+	}
+	if trashed, _ := v.Trashed(); len(trashed) != 0 {
+		t.Errorf("expected an empty trash, got %d", len(trashed))
+	}
+	if err := v.WriteTrashed(Note{Title: "no id"}); err == nil {
+		t.Error("expected an error for a note without an id")
+	}
+}
@@ -21,15 +21,18 @@ var now = time.Now
 
 // Note is a single vault note (distinct from db.Note).
 type Note struct {
-	ID      int64  // optional stable id (mirrors the SQLite index; 0 if unindexed)
-	Path    string // filesystem path (set on Read/Write)
-	Title   string
-	Tags    []string
-	Folder  string // folder name ("" = none)
-	Pinned  bool
-	Created time.Time
-	Updated time.Time
-	Content string // markdown body (without frontmatter)
+	ID       int64  // optional stable id (mirrors the SQLite index; 0 if unindexed)
+	Path     string // filesystem path (set on Read/Write)
+	Title    string
+	Aliases  []string // other names [[wikilinks]] may use for this note
+	Tags     []string
+	Folder   string // folder name ("" = none)
+	Pinned   bool
+	Created  time.Time
+	Updated  time.Time
+	Archived time.Time // when the note was archived; zero unless archived
+	Deleted  time.Time // when the note was trashed; zero for live notes
+	Content  string    // markdown body (without frontmatter)
 }
 
 // Vault is a directory of Markdown notes.
@@ -50,13 +53,36 @@ func Open(dir string) (*Vault, error) {
 func (v *Vault) Dir() string { return v.dir }
 
 type frontmatter struct {
-	ID      int64     `yaml:"id,omitempty"`
-	Title   string    `yaml:"title"`
-	Tags    []string  `yaml:"tags,omitempty"`
-	Folder  string    `yaml:"folder,omitempty"`
-	Pinned  bool      `yaml:"pinned,omitempty"`
-	Created time.Time `yaml:"created"`
-	Updated time.Time `yaml:"updated"`
+	ID       int64      `yaml:"id,omitempty"`
+	Title    string     `yaml:"title"`
+	Aliases  StringList `yaml:"aliases,omitempty"`
+	Tags     []string   `yaml:"tags,omitempty"`
+	Folder   string     `yaml:"folder,omitempty"`
+	Pinned   bool       `yaml:"pinned,omitempty"`
+	Created  time.Time  `yaml:"created"`
+	Updated  time.Time  `yaml:"updated"`
+	Archived time.Time  `yaml:"archived,omitempty"`
+	Deleted  time.Time  `yaml:"deleted,omitempty"`
+}
+
+// StringList is a YAML list of strings that also accepts a single string, as Obsidian allows for
+// aliases ("aliases: Go" as well as "aliases: [Go, Golang]").
+type StringList []string
+
+// UnmarshalYAML implements yaml.Unmarshaler.
+func (l *StringList) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		if node.Value != "" {
+			*l = StringList{node.Value}
+		}
+		return nil
+	}
+	var list []string
+	if err := node.Decode(&list); err != nil {
+		return err
+	}
+	*l = list
+	return nil
 }
 
 // Serialize renders a note to its on-disk Markdown+frontmatter form.
@@ -66,8 +92,8 @@ func Serialize(n Note) string {
 	enc := yaml.NewEncoder(&buf)
 	enc.SetIndent(2)
 	_ = enc.Encode(frontmatter{
-		ID: n.ID, Title: n.Title, Tags: n.Tags, Folder: n.Folder, Pinned: n.Pinned,
-		Created: n.Created, Updated: n.Updated,
+		ID: n.ID, Title: n.Title, Aliases: n.Aliases, Tags: n.Tags, Folder: n.Folder, Pinned: n.Pinned,
+		Created: n.Created, Updated: n.Updated, Archived: n.Archived, Deleted: n.Deleted,
 	})
 	_ = enc.Close()
 	buf.WriteString("---\n\n")
@@ -96,7 +122,8 @@ func Parse(data []byte) (Note, error) {
 			}
 			n.ID = fm.ID
 			n.Title, n.Tags, n.Folder, n.Pinned = fm.Title, fm.Tags, fm.Folder, fm.Pinned
-			n.Created, n.Updated = fm.Created, fm.Updated
+			n.Aliases = fm.Aliases
+			n.Created, n.Updated, n.Archived, n.Deleted = fm.Created, fm.Updated, fm.Archived, fm.Deleted
 			// Trim the blank line(s) bracketing the body (the newline ending the closing "---" line
 			// and the trailing newline Serialize always writes), so content round-trips cleanly.
 			n.Content = strings.Trim(body, "\n")
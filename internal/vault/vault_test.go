@@ -71,6 +71,29 @@ func TestParseNoFrontmatter(t *testing.T) {
 	}
 }
 
+func TestAliasesRoundTrip(t *testing.T) {
+	n, err := Parse([]byte(Serialize(Note{ID: 3, Title: "Go", Aliases: []string{"Golang", "Go language"}, Content: "body"})))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(n.Aliases) != 2 || n.Aliases[0] != "Golang" || n.Aliases[1] != "Go language" {
+		t.Errorf("aliases = %v, want [Golang Go language]", n.Aliases)
+	}
+
+	// A single alias may be written as a plain string
+	n, err = Parse([]byte("---\ntitle: Go\naliases: Golang\n---\n\nbody"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(n.Aliases) != 1 || n.Aliases[0] != "Golang" {
+		t.Errorf("scalar aliases = %v, want [Golang]", n.Aliases)
+	}
+
+	if strings.Contains(Serialize(Note{Title: "Plain"}), "aliases") {
+		t.Error("empty aliases should be omitted from frontmatter")
+	}
+}
+
 func TestReadTitleFromFilename(t *testing.T) {
 	dir := t.TempDir()
 	if err := os.WriteFile(filepath.Join(dir, "loose-note.md"), []byte("body only"), 0o644); err != nil {
@@ -0,0 +1,60 @@
+package veclite
+
+import (
+	"container/list"
+	"sync"
+)
+
+// queryCacheSize is how many query embeddings a Syncer keeps.
+const queryCacheSize = 256
+
+// queryCache is an LRU of query embeddings keyed by model and query text, so agents repeating the
+// same recall phrases don't pay an Ollama round trip each time.
+type queryCache struct {
+	mu    sync.Mutex
+	size  int
+	order *list.List               // front = most recently used
+	items map[string]*list.Element // key -> element holding a *cacheEntry
+}
+
+type cacheEntry struct {
+	key    string
+	vector []float32
+}
+
+func newQueryCache(size int) *queryCache {
+	return &queryCache{size: size, order: list.New(), items: make(map[string]*list.Element)}
+}
+
+func cacheKey(model, query string) string {
+	return model + "\x00" + query
+}
+
+// get returns the cached vector and marks it recently used.
+func (c *queryCache) get(key string) ([]float32, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	el, ok := c.items[key]
+	if !ok {
+		return nil, false
+	}
+	c.order.MoveToFront(el)
+	return el.Value.(*cacheEntry).vector, true
+}
+
+// put stores a vector, evicting the least recently used entry when full.
+func (c *queryCache) put(key string, vector []float32) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.items[key]; ok {
+		el.Value.(*cacheEntry).vector = vector
+		c.order.MoveToFront(el)
+		return
+	}
+	c.items[key] = c.order.PushFront(&cacheEntry{key: key, vector: vector})
+	if c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.items, oldest.Value.(*cacheEntry).key)
+	}
+}
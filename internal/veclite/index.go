@@ -0,0 +1,297 @@
+package veclite
+
+import (
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/abdul-hamid-achik/veclite"
+)
+
+// Index types accepted by IndexConfig.Type.
+const (
+	IndexFlat = "flat" // exact brute-force search
+	IndexHNSW = "hnsw" // approximate nearest neighbor graph
+)
+
+// IndexConfig tunes the vector index. Zero HNSW parameters use veclite's defaults.
+type IndexConfig struct {
+	Type           string `json:"type"`
+	M              int    `json:"m,omitempty"`               // max connections per node
+	EfConstruction int    `json:"ef_construction,omitempty"` // candidate list size while building
+	EfSearch       int    `json:"ef_search,omitempty"`       // candidate list size while searching
+}
+
+// Default HNSW parameters, matching veclite's.
+const (
+	defaultM              = 16
+	defaultEfConstruction = 200
+	defaultEfSearch       = 100
+)
+
+// Validate normalizes the index type and fills in default HNSW parameters.
+func (c IndexConfig) Validate() (IndexConfig, error) {
+	c.Type = strings.ToLower(strings.TrimSpace(c.Type))
+	switch c.Type {
+	case "", IndexFlat, "none":
+		return IndexConfig{Type: IndexFlat}, nil
+	case IndexHNSW:
+	default:
+		return c, fmt.Errorf("unknown index type %q (valid: %s, %s)", c.Type, IndexFlat, IndexHNSW)
+	}
+	if c.M == 0 {
+		c.M = defaultM
+	}
+	if c.EfConstruction == 0 {
+		c.EfConstruction = defaultEfConstruction
+	}
+	if c.EfSearch == 0 {
+		c.EfSearch = defaultEfSearch
+	}
+	if c.M < 2 || c.EfConstruction < c.M || c.EfSearch < 1 {
+		return c, fmt.Errorf("invalid HNSW parameters: m must be at least 2, ef_construction at least m, ef_search at least 1")
+	}
+	return c, nil
+}
+
+func (c IndexConfig) options() []veclite.CollectionOption {
+	if c.Type != IndexHNSW {
+		return nil
+	}
+	return []veclite.CollectionOption{veclite.WithHNSWConfig(veclite.HNSWConfig{
+		M:              c.M,
+		EfConstruction: c.EfConstruction,
+		EfSearch:       c.EfSearch,
+		UseHeuristic:   true,
+	})}
+}
+
+// Reindex rebuilds every collection at dbPath with the given index settings, keeping every vector,
+// so no embeddings are recomputed. Returns the number of vectors reindexed.
+func Reindex(dbPath string, ic IndexConfig) (int, error) {
+	ic, err := ic.Validate()
+	if err != nil {
+		return 0, err
+	}
+	if _, err := os.Stat(dbPath); errors.Is(err, os.ErrNotExist) {
+		return 0, fmt.Errorf("no vector index at %s (run `noted sync` first)", dbPath)
+	}
+	db, err := veclite.Open(dbPath)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open veclite database: %w", err)
+	}
+	defer func() { _ = db.Close() }()
+
+	total := 0
+	for _, name := range Collections {
+		old, err := db.GetCollection(name)
+		if err != nil {
+			continue // nothing synced into it yet
+		}
+		records := old.All()
+		meta := old.Metadata()
+
+		if err := db.DropCollection(name); err != nil {
+			return total, fmt.Errorf("failed to drop collection %s: %w", name, err)
+		}
+		coll, err := db.CreateCollection(name, ic.options()...)
+		if err != nil {
+			return total, fmt.Errorf("failed to create collection %s: %w", name, err)
+		}
+		n, err := copyRecords(coll, records)
+		total += n
+		if err != nil {
+			return total, err
+		}
+		_ = coll.SetMetadata(meta)
+	}
+
+	if err := db.Sync(); err != nil {
+		return total, fmt.Errorf("failed to save index: %w", err)
+	}
+	return total, nil
+}
+
+func copyRecords(coll *veclite.Collection, records []*veclite.Record) (int, error) {
+	n := 0
+	for _, r := range records {
+		if len(r.Vector) == 0 {
+			continue
+		}
+		if _, err := coll.InsertDocument(r.Vector, r.Content, r.Payload); err != nil {
+			return n, fmt.Errorf("failed to insert vector: %w", err)
+		}
+		n++
+	}
+	return n, nil
+}
+
+// BenchResult compares an index configuration against exact brute-force search.
+type BenchResult struct {
+	Index     IndexConfig   `json:"index"`
+	Vectors   int           `json:"vectors"`
+	Queries   int           `json:"queries"`
+	K         int           `json:"k"`
+	BuildTime time.Duration `json:"build_time_ns"`
+	ExactAvg  time.Duration `json:"exact_avg_ns"`
+	IndexAvg  time.Duration `json:"index_avg_ns"`
+	IndexP95  time.Duration `json:"index_p95_ns"`
+	RecallAtK float64       `json:"recall_at_k"` // share of the exact top k the index also returned
+}
+
+// Benchmark measures recall and latency of each index configuration against exact search over
+// the notes collection at dbPath. Queries reuse stored vectors, so no embedder is needed. The index on disk
+// is not modified.
+func Benchmark(dbPath string, configs []IndexConfig, queries, k int) ([]BenchResult, error) {
+	if _, err := os.Stat(dbPath); errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("no vector index at %s (run `noted sync` first)", dbPath)
+	}
+	src, err := veclite.Open(dbPath, veclite.WithReadOnly(true), veclite.WithSharedRead(true))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open veclite database: %w", err)
+	}
+	coll, err := src.GetCollection(CollectionNotes)
+	var records []*veclite.Record
+	if err == nil {
+		records = coll.All()
+	}
+	_ = src.Close()
+	return benchmarkRecords(records, configs, queries, k)
+}
+
+func benchmarkRecords(records []*veclite.Record, configs []IndexConfig, queries, k int) ([]BenchResult, error) {
+	records = slices.DeleteFunc(records, func(r *veclite.Record) bool { return len(r.Vector) == 0 })
+	if len(records) == 0 {
+		return nil, fmt.Errorf("the index has no vectors to benchmark")
+	}
+	if k < 1 {
+		k = 10
+	}
+	if queries < 1 || queries > len(records) {
+		queries = min(len(records), 100)
+	}
+	sample := rand.Perm(len(records))[:queries]
+
+	exact, err := buildCollection(records, IndexConfig{Type: IndexFlat})
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = exact.db.Close() }()
+	truth := make([]map[uint64]bool, queries)
+	var exactTotal time.Duration
+	for i, idx := range sample {
+		start := time.Now()
+		res, err := exact.coll.Search(records[idx].Vector, veclite.TopK(k))
+		exactTotal += time.Since(start)
+		if err != nil {
+			return nil, fmt.Errorf("exact search failed: %w", err)
+		}
+		truth[i] = resultKeys(res)
+	}
+
+	// ef_search only matters at query time, so configurations differing only in it share a build
+	type buildKey struct {
+		typ    string
+		m, efc int
+	}
+	builds := map[buildKey]memCollection{}
+	buildTimes := map[buildKey]time.Duration{}
+	defer func() {
+		for _, b := range builds {
+			_ = b.db.Close()
+		}
+	}()
+
+	results := make([]BenchResult, 0, len(configs))
+	for _, ic := range configs {
+		ic, err := ic.Validate()
+		if err != nil {
+			return nil, err
+		}
+		key := buildKey{ic.Type, ic.M, ic.EfConstruction}
+		built, ok := builds[key]
+		if !ok {
+			start := time.Now()
+			if built, err = buildCollection(records, ic); err != nil {
+				return nil, err
+			}
+			builds[key], buildTimes[key] = built, time.Since(start)
+		}
+		res := BenchResult{
+			Index:     ic,
+			Vectors:   len(records),
+			Queries:   queries,
+			K:         k,
+			BuildTime: buildTimes[key],
+			ExactAvg:  exactTotal / time.Duration(queries),
+		}
+
+		latencies := make([]time.Duration, queries)
+		hits, total := 0, 0
+		for i, idx := range sample {
+			start := time.Now()
+			found, err := built.coll.Search(records[idx].Vector, veclite.TopK(k), veclite.WithEfSearch(ic.EfSearch))
+			latencies[i] = time.Since(start)
+			if err != nil {
+				return nil, fmt.Errorf("index search failed: %w", err)
+			}
+			for key := range resultKeys(found) {
+				if truth[i][key] {
+					hits++
+				}
+			}
+			total += len(truth[i])
+		}
+		slices.Sort(latencies)
+		var sum time.Duration
+		for _, l := range latencies {
+			sum += l
+		}
+		res.IndexAvg = sum / time.Duration(queries)
+		res.IndexP95 = latencies[(len(latencies)*95-1)/100]
+		if total > 0 {
+			res.RecallAtK = float64(hits) / float64(total)
+		}
+		results = append(results, res)
+	}
+	return results, nil
+}
+
+type memCollection struct {
+	db   *veclite.DB
+	coll *veclite.Collection
+}
+
+// buildCollection loads records into an in-memory collection with the given index.
+func buildCollection(records []*veclite.Record, ic IndexConfig) (memCollection, error) {
+	db, err := veclite.Open(":memory:")
+	if err != nil {
+		return memCollection{}, err
+	}
+	coll, err := db.CreateCollection(CollectionNotes, ic.options()...)
+	if err != nil {
+		_ = db.Close()
+		return memCollection{}, err
+	}
+	if _, err := copyRecords(coll, records); err != nil {
+		_ = db.Close()
+		return memCollection{}, err
+	}
+	return memCollection{db: db, coll: coll}, nil
+}
+
+// resultKeys identifies results by note id, since record ids differ between collections.
+func resultKeys(results []veclite.Result) map[uint64]bool {
+	keys := make(map[uint64]bool, len(results))
+	for _, r := range results {
+		id, _ := r.Record.Payload["note_id"].(string)
+		n, _ := strconv.ParseUint(id, 10, 64)
+		keys[n] = true
+	}
+	return keys
+}
@@ -1,26 +1,39 @@
 package veclite
 
 import (
-	"bytes"
-	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
-	"io"
-	"net/http"
 	"os"
+	"slices"
 	"strconv"
-	"strings"
+	"sync"
+	"time"
 
-	"github.com/abdul-hamid-achik/noted/internal/db"
+	"github.com/abdul-hamid-achik/noted/internal/embeddings"
 	"github.com/abdul-hamid-achik/veclite"
 )
 
+// Collections notes are embedded into. Memories (notes tagged "memory") get their own so recall
+// searches only memories and semantic note search only notes; each can use its own model.
 const (
-	collectionName        = "notes"
-	defaultEmbeddingModel = "nomic-embed-text"
-	defaultOllamaHost     = "http://localhost:11434"
+	CollectionNotes    = "notes"
+	CollectionMemories = "memories"
 )
 
+// Collections lists every collection the syncer manages.
+var Collections = []string{CollectionNotes, CollectionMemories}
+
+const (
+	// Collection metadata keys written on every sync
+	metaModel      = "embedding_model"
+	metaLastSynced = "last_synced_at"
+)
+
+// newEmbedder creates the embedder for a model; replaced in tests.
+var newEmbedder = func(model string) (embeddings.Embedder, error) {
+	return embeddings.NewOllama(model, embeddings.HostFromEnv())
+}
+
 // SemanticResult represents a semantic search result
 type SemanticResult struct {
 	NoteID int64   `json:"note_id"`
@@ -28,88 +41,15 @@ type SemanticResult struct {
 	Title  string  `json:"title"`
 }
 
-// OllamaEmbedder implements veclite.Embedder using Ollama API
-type OllamaEmbedder struct {
-	model     string
-	host      string
-	dimension int
-}
-
-// NewOllamaEmbedder creates a new Ollama embedder
-func NewOllamaEmbedder(model, host string) (*OllamaEmbedder, error) {
-	e := &OllamaEmbedder{
-		model:     model,
-		host:      host,
-		dimension: 768, // Default for nomic-embed-text
-	}
-
-	// Probe dimension by embedding a test string
-	vec, err := e.Embed("test")
-	if err != nil {
-		return nil, fmt.Errorf("failed to probe embedder dimension: %w", err)
-	}
-	e.dimension = len(vec)
-
-	return e, nil
-}
-
-// Embed generates an embedding for a single text
-func (e *OllamaEmbedder) Embed(text string) ([]float32, error) {
-	reqBody := map[string]any{
-		"model":  e.model,
-		"prompt": text,
-	}
-	bodyBytes, _ := json.Marshal(reqBody)
-
-	resp, err := http.Post(e.host+"/api/embeddings", "application/json", bytes.NewReader(bodyBytes))
-	if err != nil {
-		return nil, fmt.Errorf("ollama request failed: %w", err)
-	}
-	defer func() { _ = resp.Body.Close() }()
-
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("ollama returned status %d: %s", resp.StatusCode, string(body))
-	}
-
-	var result struct {
-		Embedding []float64 `json:"embedding"`
-	}
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return nil, fmt.Errorf("failed to decode ollama response: %w", err)
-	}
-
-	// Convert float64 to float32
-	vec := make([]float32, len(result.Embedding))
-	for i, v := range result.Embedding {
-		vec[i] = float32(v)
-	}
-
-	return vec, nil
-}
-
-// EmbedBatch generates embeddings for multiple texts
-func (e *OllamaEmbedder) EmbedBatch(texts []string) ([][]float32, error) {
-	results := make([][]float32, len(texts))
-	for i, text := range texts {
-		vec, err := e.Embed(text)
-		if err != nil {
-			return nil, err
-		}
-		results[i] = vec
-	}
-	return results, nil
-}
-
-// Dimension returns the embedding dimension
-func (e *OllamaEmbedder) Dimension() int {
-	return e.dimension
-}
-
 // Syncer handles synchronization between noted and veclite
 type Syncer struct {
-	db       *veclite.DB
-	embedder *OllamaEmbedder
+	db      *veclite.DB
+	queries *queryCache // recent query embeddings (see cache.go)
+	index   IndexConfig // used when a collection is first created
+
+	mu        sync.Mutex
+	models    map[string]string              // collection -> embedding model
+	embedders map[string]embeddings.Embedder // model -> embedder, created on first use
 }
 
 // NewSyncer creates a new veclite syncer.
@@ -125,10 +65,6 @@ func NewSearcher(dbPath, embeddingModel string) (*Syncer, error) {
 }
 
 func newSyncer(dbPath, embeddingModel string, readOnly bool) (*Syncer, error) {
-	if embeddingModel == "" {
-		embeddingModel = defaultEmbeddingModel
-	}
-
 	// Open veclite database
 	opts := []veclite.Option{}
 	if readOnly {
@@ -139,54 +75,87 @@ func newSyncer(dbPath, embeddingModel string, readOnly bool) (*Syncer, error) {
 		return nil, fmt.Errorf("failed to open veclite database: %w", err)
 	}
 
-	// Create embedder using Ollama
-	ollamaHost := os.Getenv("OLLAMA_HOST")
-	if ollamaHost == "" {
-		ollamaHost = defaultOllamaHost
-	} else if !strings.HasPrefix(ollamaHost, "http://") && !strings.HasPrefix(ollamaHost, "https://") {
-		// Add http:// prefix if missing
-		ollamaHost = "http://" + ollamaHost + ":11434"
+	if embeddingModel == "" {
+		embeddingModel = embeddings.DefaultModel
 	}
-
-	embedder, err := NewOllamaEmbedder(embeddingModel, ollamaHost)
+	embedder, err := newEmbedder(embeddingModel)
 	if err != nil {
 		_ = db.Close()
 		return nil, fmt.Errorf("failed to create embedder: %w", err)
 	}
 
 	return &Syncer{
-		db:       db,
-		embedder: embedder,
+		db:        db,
+		queries:   newQueryCache(queryCacheSize),
+		models:    map[string]string{CollectionNotes: embeddingModel, CollectionMemories: embeddingModel},
+		embedders: map[string]embeddings.Embedder{embeddingModel: embedder},
 	}, nil
 }
 
+// WithCollectionModel embeds one collection with a different model than the one passed to
+// NewSyncer. The model is probed on first use. Returns the syncer for chaining.
+func (s *Syncer) WithCollectionModel(collection, model string) *Syncer {
+	if model != "" {
+		s.mu.Lock()
+		s.models[collection] = model
+		s.mu.Unlock()
+	}
+	return s
+}
+
+// embedderFor returns the model and embedder for a collection.
+func (s *Syncer) embedderFor(collection string) (string, embeddings.Embedder, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	model := s.models[collection]
+	if e, ok := s.embedders[model]; ok {
+		return model, e, nil
+	}
+	e, err := newEmbedder(model)
+	if err != nil {
+		return model, nil, fmt.Errorf("failed to create embedder for %s: %w", collection, err)
+	}
+	s.embedders[model] = e
+	return model, e, nil
+}
+
 // Close closes the syncer and releases resources
 func (s *Syncer) Close() error {
 	return s.db.Close()
 }
 
-// SyncNote syncs a single note to veclite
+// SyncNote syncs a single note to the notes collection
 func (s *Syncer) SyncNote(id int64, title, content string) error {
+	return s.syncInto(CollectionNotes, id, title, content)
+}
+
+// SyncMemory syncs a memory to the memories collection
+func (s *Syncer) SyncMemory(id int64, title, content string) error {
+	return s.syncInto(CollectionMemories, id, title, content)
+}
+
+func (s *Syncer) syncInto(collection string, id int64, title, content string) error {
 	// Create text to embed (title + content)
 	text := title + "\n\n" + content
 
 	// Generate embedding
-	vector, err := s.embedder.Embed(text)
+	model, embedder, err := s.embedderFor(collection)
+	if err != nil {
+		return err
+	}
+	vector, err := embedder.Embed(text)
 	if err != nil {
 		return fmt.Errorf("failed to generate embedding: %w", err)
 	}
 
-	// Get or create collection
-	coll := s.db.Collection(collectionName)
-
-	// Check if note already exists and delete it first
-	existing, err := coll.Find(veclite.Equal("note_id", strconv.FormatInt(id, 10)))
-	if err == nil && len(existing) > 0 {
-		for _, r := range existing {
-			_ = coll.Delete(r.ID)
-		}
+	coll, err := s.collection(collection)
+	if err != nil {
+		return err
 	}
 
+	// Drop the note's previous vectors, including from the other collection if it changed kind
+	deleteNotes(s.db, id)
+
 	// Insert with payload
 	payload := map[string]any{
 		"note_id": strconv.FormatInt(id, 10),
@@ -198,43 +167,34 @@ func (s *Syncer) SyncNote(id int64, title, content string) error {
 		return fmt.Errorf("failed to insert into veclite: %w", err)
 	}
 
+	// Recorded for `noted sync --status`
+	_ = coll.SetMetadataValue(metaModel, model)
+	_ = coll.SetMetadataValue(metaLastSynced, time.Now().UTC().Format(time.RFC3339))
+
 	// Sync to disk
 	_ = s.db.Sync()
 
 	return nil
 }
 
-// SyncAll syncs all unsynced notes from the database
-func (s *Syncer) SyncAll(queries *db.Queries) (int, error) {
-	ctx := context.Background()
-	notes, err := queries.GetUnsynced(ctx)
-	if err != nil {
-		return 0, fmt.Errorf("failed to get unsynced notes: %w", err)
-	}
-
-	synced := 0
-	for _, note := range notes {
-		if err := s.SyncNote(note.ID, note.Title, note.Content); err != nil {
-			continue // Skip failed notes
-		}
-		// Mark as synced
-		_ = queries.MarkEmbeddingSynced(ctx, note.ID)
-		synced++
-	}
+// Search performs semantic search on notes
+func (s *Syncer) Search(query string, limit int) ([]SemanticResult, error) {
+	return s.search(CollectionNotes, query, limit)
+}
 
-	return synced, nil
+// SearchMemories performs semantic search on memories
+func (s *Syncer) SearchMemories(query string, limit int) ([]SemanticResult, error) {
+	return s.search(CollectionMemories, query, limit)
 }
 
-// Search performs semantic search on notes
-func (s *Syncer) Search(query string, limit int) ([]SemanticResult, error) {
-	// Generate query embedding
-	vector, err := s.embedder.Embed(query)
+func (s *Syncer) search(collection, query string, limit int) ([]SemanticResult, error) {
+	vector, err := s.queryVector(collection, query)
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate query embedding: %w", err)
 	}
 
 	// Get collection
-	coll, err := s.db.GetCollection(collectionName)
+	coll, err := s.db.GetCollection(collection)
 	if err != nil {
 		return nil, fmt.Errorf("collection not found: %w", err)
 	}
@@ -270,23 +230,233 @@ func (s *Syncer) Search(query string, limit int) ([]SemanticResult, error) {
 	return output, nil
 }
 
+// MoveToMemories moves already-embedded memories out of the notes collection, where indexes
+// created before the memories collection existed kept them. Vectors are reused when both
+// collections use the same model; otherwise they are dropped and returned for re-embedding.
+func (s *Syncer) MoveToMemories(ids []int64) (moved int, reembed []int64, err error) {
+	src, err := s.db.GetCollection(CollectionNotes)
+	if err != nil {
+		return 0, nil, nil
+	}
+	dst, err := s.collection(CollectionMemories)
+	if err != nil {
+		return 0, nil, err
+	}
+	s.mu.Lock()
+	sameModel := s.models[CollectionNotes] == s.models[CollectionMemories]
+	model := s.models[CollectionMemories]
+	s.mu.Unlock()
+
+	for _, id := range ids {
+		records, err := src.Find(veclite.Equal("note_id", strconv.FormatInt(id, 10)))
+		if err != nil || len(records) == 0 {
+			continue
+		}
+		for _, r := range records {
+			if sameModel {
+				if _, err := dst.InsertDocument(r.Vector, r.Content, r.Payload); err != nil {
+					return moved, reembed, fmt.Errorf("failed to move memory #%d: %w", id, err)
+				}
+			}
+			_ = src.Delete(r.ID)
+		}
+		if sameModel {
+			moved++
+		} else {
+			reembed = append(reembed, id)
+		}
+	}
+	if moved > 0 {
+		_ = dst.SetMetadataValue(metaModel, model)
+	}
+	_ = s.db.Sync()
+	return moved, reembed, nil
+}
+
+// HasCollection reports whether a collection exists in the index.
+func (s *Syncer) HasCollection(name string) bool {
+	return s.db.HasCollection(name)
+}
+
+// WithIndex sets the index used for collections that don't exist yet; an existing collection keeps
+// its index until `noted reindex`. Returns the syncer for chaining.
+func (s *Syncer) WithIndex(ic IndexConfig) *Syncer {
+	s.index = ic
+	return s
+}
+
+// collection returns a collection, creating it with the configured index.
+func (s *Syncer) collection(name string) (*veclite.Collection, error) {
+	if coll, err := s.db.GetCollection(name); err == nil {
+		return coll, nil
+	}
+	ic, err := s.index.Validate()
+	if err != nil {
+		return nil, err
+	}
+	coll, err := s.db.CreateCollection(name, ic.options()...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create collection: %w", err)
+	}
+	return coll, nil
+}
+
+// queryVector embeds a search query for a collection, reusing a cached embedding for a repeated
+// query.
+func (s *Syncer) queryVector(collection, query string) ([]float32, error) {
+	model, embedder, err := s.embedderFor(collection)
+	if err != nil {
+		return nil, err
+	}
+	if s.queries == nil {
+		return embedder.Embed(query)
+	}
+	key := cacheKey(model, query)
+	if vec, ok := s.queries.get(key); ok {
+		return vec, nil
+	}
+	vec, err := embedder.Embed(query)
+	if err != nil {
+		return nil, err
+	}
+	s.queries.put(key, vec)
+	return vec, nil
+}
+
 // Delete removes a note from the veclite index
 func (s *Syncer) Delete(noteID int64) error {
-	coll, err := s.db.GetCollection(collectionName)
+	deleteNotes(s.db, noteID)
+	return nil
+}
+
+// DeleteNotes removes notes' vectors from the index at dbPath without contacting the embedder, so
+// deletions clean up the index even while Ollama is down. A missing index is not an error.
+func DeleteNotes(dbPath string, noteIDs ...int64) error {
+	if _, err := os.Stat(dbPath); errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	db, err := veclite.Open(dbPath)
 	if err != nil {
-		return nil // Collection doesn't exist, nothing to delete
+		return fmt.Errorf("failed to open veclite database: %w", err)
+	}
+	deleteNotes(db, noteIDs...)
+	return db.Close()
+}
+
+func deleteNotes(db *veclite.DB, noteIDs ...int64) {
+	for _, name := range Collections {
+		coll, err := db.GetCollection(name)
+		if err != nil {
+			continue // Collection doesn't exist, nothing to delete
+		}
+
+		// Find and delete records with these note_ids
+		for _, id := range noteIDs {
+			records, err := coll.Find(veclite.Equal("note_id", strconv.FormatInt(id, 10)))
+			if err != nil {
+				continue // No records found
+			}
+			for _, r := range records {
+				_ = coll.Delete(r.ID)
+			}
+		}
 	}
 
-	// Find and delete records with this note_id
-	records, err := coll.Find(veclite.Equal("note_id", strconv.FormatInt(noteID, 10)))
+	_ = db.Sync()
+}
+
+// NoteIDs returns the distinct ids of the notes with vectors in the index at dbPath, across all
+// collections, in ascending order. Like ReadStatus it opens the index read-only and needs no
+// embedder; a missing index has no notes.
+func NoteIDs(dbPath string) ([]int64, error) {
+	if _, err := os.Stat(dbPath); errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	db, err := veclite.Open(dbPath, veclite.WithReadOnly(true), veclite.WithSharedRead(true))
 	if err != nil {
-		return nil // No records found
+		return nil, fmt.Errorf("failed to open veclite database: %w", err)
 	}
+	defer func() { _ = db.Close() }()
 
-	for _, r := range records {
-		_ = coll.Delete(r.ID)
+	seen := map[int64]bool{}
+	var ids []int64
+	for _, name := range Collections {
+		coll, err := db.GetCollection(name)
+		if err != nil {
+			continue // never synced
+		}
+		for _, r := range coll.All() {
+			raw, _ := r.Payload["note_id"].(string)
+			id, err := strconv.ParseInt(raw, 10, 64)
+			if err != nil || seen[id] {
+				continue
+			}
+			seen[id] = true
+			ids = append(ids, id)
+		}
 	}
+	slices.Sort(ids)
+	return ids, nil
+}
 
-	_ = s.db.Sync()
-	return nil
+// Status describes the vector index on disk.
+type Status struct {
+	Path        string             `json:"path"`
+	Exists      bool               `json:"exists"`
+	SizeBytes   int64              `json:"size_bytes"`
+	Vectors     int                `json:"vectors"` // across all collections
+	Collections []CollectionStatus `json:"collections"`
+}
+
+// CollectionStatus describes one collection of the vector index.
+type CollectionStatus struct {
+	Name         string    `json:"name"`
+	Vectors      int       `json:"vectors"`
+	Dimension    int       `json:"dimension"`
+	Model        string    `json:"model,omitempty"`
+	IndexType    string    `json:"index_type,omitempty"`
+	LastSyncedAt time.Time `json:"last_synced_at,omitzero"`
+}
+
+// ReadStatus reports on the vector index at dbPath without contacting the embedder. It opens the
+// index read-only, so it does not block a running sync.
+func ReadStatus(dbPath string) (Status, error) {
+	st := Status{Path: dbPath}
+	info, err := os.Stat(dbPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return st, nil // nothing synced yet
+	}
+	if err != nil {
+		return st, err
+	}
+	st.Exists = true
+	st.SizeBytes = info.Size()
+
+	db, err := veclite.Open(dbPath, veclite.WithReadOnly(true), veclite.WithSharedRead(true))
+	if err != nil {
+		return st, fmt.Errorf("failed to open veclite database: %w", err)
+	}
+	defer func() { _ = db.Close() }()
+
+	for _, name := range Collections {
+		coll, err := db.GetCollection(name)
+		if err != nil {
+			continue // never synced
+		}
+		stats := coll.Stats()
+		cs := CollectionStatus{
+			Name:      name,
+			Vectors:   stats.VectorCount,
+			Dimension: stats.Dimension,
+			IndexType: stats.IndexType,
+		}
+		meta := coll.Metadata()
+		cs.Model, _ = meta[metaModel].(string)
+		if ts, ok := meta[metaLastSynced].(string); ok {
+			cs.LastSyncedAt, _ = time.Parse(time.RFC3339, ts)
+		}
+		st.Vectors += cs.Vectors
+		st.Collections = append(st.Collections, cs)
+	}
+	return st, nil
 }
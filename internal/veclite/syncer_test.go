@@ -0,0 +1,292 @@
+package veclite
+
+import (
+	"math/rand/v2"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"testing"
+
+	"github.com/abdul-hamid-achik/noted/internal/embeddings"
+	"github.com/abdul-hamid-achik/veclite"
+)
+
+// fakeEmbedder returns a fixed-size vector derived from the text length.
+type fakeEmbedder struct{}
+
+func (fakeEmbedder) Embed(text string) ([]float32, error) {
+	return []float32{float32(len(text)), 1, 0.5}, nil
+}
+
+func (f fakeEmbedder) EmbedBatch(texts []string) ([][]float32, error) {
+	out := make([][]float32, len(texts))
+	for i, t := range texts {
+		out[i], _ = f.Embed(t)
+	}
+	return out, nil
+}
+
+func (fakeEmbedder) Dimension() int    { return 3 }
+func (fakeEmbedder) IsAvailable() bool { return true }
+
+// testSyncer returns a syncer embedding both collections with emb as "fake-model".
+func testSyncer(vdb *veclite.DB, emb embeddings.Embedder) *Syncer {
+	return &Syncer{
+		db:        vdb,
+		models:    map[string]string{CollectionNotes: "fake-model", CollectionMemories: "fake-model"},
+		embedders: map[string]embeddings.Embedder{"fake-model": emb},
+	}
+}
+
+func TestReadStatus(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "vectors.veclite")
+
+	st, err := ReadStatus(path)
+	if err != nil {
+		t.Fatalf("ReadStatus on missing index failed: %v", err)
+	}
+	if st.Exists || st.Vectors != 0 {
+		t.Errorf("expected empty status for missing index, got %+v", st)
+	}
+
+	vdb, err := veclite.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := testSyncer(vdb, fakeEmbedder{})
+	for id, title := range map[int64]string{1: "One", 2: "Two"} {
+		if err := s.SyncNote(id, title, "body"); err != nil {
+			t.Fatalf("SyncNote failed: %v", err)
+		}
+	}
+	// Re-syncing replaces the note's vector rather than adding one
+	_ = s.SyncNote(1, "One", "edited body")
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	st, err = ReadStatus(path)
+	if err != nil {
+		t.Fatalf("ReadStatus failed: %v", err)
+	}
+	if !st.Exists || st.SizeBytes == 0 {
+		t.Errorf("expected index on disk, got %+v", st)
+	}
+	if st.Vectors != 2 || len(st.Collections) != 1 {
+		t.Fatalf("unexpected status: %+v", st)
+	}
+	if c := st.Collections[0]; c.Name != CollectionNotes || c.Dimension != 3 || c.Model != "fake-model" || c.LastSyncedAt.IsZero() {
+		t.Errorf("unexpected collection status: %+v", c)
+	}
+}
+
+func TestDeleteNotes(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "vectors.veclite")
+	if err := DeleteNotes(path, 1); err != nil {
+		t.Fatalf("DeleteNotes on missing index should be a no-op, got %v", err)
+	}
+
+	vdb, err := veclite.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := testSyncer(vdb, fakeEmbedder{})
+	for _, id := range []int64{1, 2, 3} {
+		_ = s.SyncNote(id, "Note", "body")
+	}
+	_ = s.Close()
+
+	if err := DeleteNotes(path, 1, 3); err != nil {
+		t.Fatalf("DeleteNotes failed: %v", err)
+	}
+	st, _ := ReadStatus(path)
+	if st.Vectors != 1 {
+		t.Errorf("expected 1 vector left, got %d", st.Vectors)
+	}
+}
+
+func TestNoteIDs(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "vectors.veclite")
+	if ids, err := NoteIDs(path); err != nil || ids != nil {
+		t.Fatalf("NoteIDs on missing index = %v, %v", ids, err)
+	}
+
+	vdb, err := veclite.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := testSyncer(vdb, fakeEmbedder{})
+	_ = s.SyncNote(3, "Note", "body")
+	_ = s.SyncNote(1, "Note", "body")
+	_ = s.SyncMemory(2, "Memory", "body")
+	_ = s.SyncNote(1, "Note", "edited")
+	_ = s.Close()
+
+	ids, err := NoteIDs(path)
+	if err != nil || !slices.Equal(ids, []int64{1, 2, 3}) {
+		t.Errorf("NoteIDs = %v, %v; want [1 2 3]", ids, err)
+	}
+}
+
+func TestSyncMemory_SeparateCollection(t *testing.T) {
+	vdb, err := veclite.Open(":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := testSyncer(vdb, fakeEmbedder{})
+	defer func() { _ = s.Close() }()
+	_ = s.SyncNote(1, "Note", "body")
+	_ = s.SyncMemory(2, "Memory", "remembered")
+
+	notes, _ := s.Search("body", 10)
+	if len(notes) != 1 || notes[0].NoteID != 1 {
+		t.Errorf("expected only the note in note search, got %+v", notes)
+	}
+	memories, _ := s.SearchMemories("remembered", 10)
+	if len(memories) != 1 || memories[0].NoteID != 2 {
+		t.Errorf("expected only the memory in recall, got %+v", memories)
+	}
+
+	// A note that becomes a memory leaves the notes collection
+	_ = s.SyncMemory(1, "Note", "body")
+	if notes, _ := s.Search("body", 10); len(notes) != 0 {
+		t.Errorf("expected note 1 to move to memories, got %+v", notes)
+	}
+}
+
+func TestMoveToMemories(t *testing.T) {
+	vdb, err := veclite.Open(":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := testSyncer(vdb, fakeEmbedder{})
+	defer func() { _ = s.Close() }()
+	// An index from before the memories collection: everything in notes
+	for _, id := range []int64{1, 2, 3} {
+		_ = s.SyncNote(id, "Note", "body")
+	}
+
+	moved, reembed, err := s.MoveToMemories([]int64{2, 3, 99})
+	if err != nil || moved != 2 || len(reembed) != 0 {
+		t.Fatalf("expected 2 memories moved, got %d (reembed %v, err %v)", moved, reembed, err)
+	}
+	if notes, _ := s.Search("body", 10); len(notes) != 1 || notes[0].NoteID != 1 {
+		t.Errorf("expected only note 1 left in notes, got %+v", notes)
+	}
+	if memories, _ := s.SearchMemories("body", 10); len(memories) != 2 {
+		t.Errorf("expected 2 memories, got %+v", memories)
+	}
+
+	// With a different memory model the old vectors are useless and must be re-embedded
+	_ = s.SyncNote(4, "Note", "body")
+	s.WithCollectionModel(CollectionMemories, "other-model")
+	s.embedders["other-model"] = fakeEmbedder{}
+	moved, reembed, _ = s.MoveToMemories([]int64{4})
+	if moved != 0 || len(reembed) != 1 || reembed[0] != 4 {
+		t.Errorf("expected note 4 queued for re-embedding, got moved=%d reembed=%v", moved, reembed)
+	}
+	if notes, _ := s.Search("body", 10); len(notes) != 1 {
+		t.Errorf("expected note 4's old vector dropped, got %+v", notes)
+	}
+}
+
+func TestQueryCache_EvictsLeastRecentlyUsed(t *testing.T) {
+	c := newQueryCache(2)
+	c.put("a", []float32{1})
+	c.put("b", []float32{2})
+	c.get("a") // b is now least recently used
+	c.put("c", []float32{3})
+
+	if _, ok := c.get("b"); ok {
+		t.Error("expected b to be evicted")
+	}
+	for _, key := range []string{"a", "c"} {
+		if _, ok := c.get(key); !ok {
+			t.Errorf("expected %s to be cached", key)
+		}
+	}
+}
+
+// countingEmbedder counts Embed calls.
+type countingEmbedder struct {
+	fakeEmbedder
+	calls int
+}
+
+func (c *countingEmbedder) Embed(text string) ([]float32, error) {
+	c.calls++
+	return c.fakeEmbedder.Embed(text)
+}
+
+func TestSearch_CachesQueryEmbeddings(t *testing.T) {
+	vdb, err := veclite.Open(":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	emb := &countingEmbedder{}
+	s := testSyncer(vdb, emb)
+	s.queries = newQueryCache(queryCacheSize)
+	defer func() { _ = s.Close() }()
+	_ = s.SyncNote(1, "Note", "body")
+	emb.calls = 0
+
+	for i := 0; i < 3; i++ {
+		if _, err := s.Search("user preferences", 5); err != nil {
+			t.Fatalf("Search failed: %v", err)
+		}
+	}
+	if emb.calls != 1 {
+		t.Errorf("expected 1 embedding for a repeated query, got %d", emb.calls)
+	}
+	_, _ = s.Search("something else", 5)
+	if emb.calls != 2 {
+		t.Errorf("expected a new query to be embedded, got %d calls", emb.calls)
+	}
+}
+
+func TestReindexAndBenchmark(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "vectors.veclite")
+	vdb, err := veclite.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	coll := vdb.Collection(CollectionNotes)
+	rng := rand.New(rand.NewPCG(1, 2))
+	for i := 1; i <= 200; i++ {
+		vec := make([]float32, 8)
+		for j := range vec {
+			vec[j] = rng.Float32()
+		}
+		_, _ = coll.InsertDocument(vec, "text", map[string]any{"note_id": strconv.Itoa(i)})
+	}
+	_ = coll.SetMetadataValue(metaModel, "fake-model")
+	_ = vdb.Close()
+
+	n, err := Reindex(path, IndexConfig{Type: "HNSW"})
+	if err != nil {
+		t.Fatalf("Reindex failed: %v", err)
+	}
+	st, _ := ReadStatus(path)
+	if n != 200 || st.Vectors != 200 || len(st.Collections) != 1 ||
+		st.Collections[0].IndexType != "hnsw" || st.Collections[0].Model != "fake-model" {
+		t.Errorf("unexpected reindex: n=%d status=%+v", n, st)
+	}
+
+	results, err := Benchmark(path, []IndexConfig{{Type: IndexFlat}, {Type: IndexHNSW}}, 20, 5)
+	if err != nil {
+		t.Fatalf("Benchmark failed: %v", err)
+	}
+	if len(results) != 2 || results[0].Queries != 20 || results[0].K != 5 {
+		t.Fatalf("unexpected results: %+v", results)
+	}
+	if results[0].RecallAtK != 1 {
+		t.Errorf("flat index should match exact search, got recall %.2f", results[0].RecallAtK)
+	}
+	if results[1].RecallAtK < 0.5 {
+		t.Errorf("HNSW recall unexpectedly low: %.2f", results[1].RecallAtK)
+	}
+
+	if _, err := (IndexConfig{Type: "annoy"}).Validate(); err == nil {
+		t.Error("expected unknown index type to be rejected")
+	}
+}
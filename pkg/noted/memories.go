@@ -0,0 +1,63 @@
+package noted
+
+import (
+	"context"
+
+	"github.com/abdul-hamid-achik/noted/internal/memory"
+	"github.com/abdul-hamid-achik/noted/internal/notesync"
+)
+
+// Memory types are shared with the noted_remember/noted_recall/noted_forget MCP tools and the
+// remember/recall/forget commands.
+type (
+	Memory        = memory.Memory
+	RememberInput = memory.RememberInput
+	RecallInput   = memory.RecallInput
+	RecallResult  = memory.RecallResult
+	ForgetInput   = memory.ForgetInput
+	ForgetResult  = memory.ForgetResult
+)
+
+// MemoryCategories lists the valid Memory.Category values.
+var MemoryCategories = memory.ValidCategories
+
+// Memories stores and retrieves agent memories: notes tagged "memory" with a category and an
+// importance.
+type Memories struct {
+	s *Store
+}
+
+// Remember stores a memory.
+func (m *Memories) Remember(ctx context.Context, input RememberInput) (*Memory, error) {
+	mem, err := memory.Remember(ctx, m.s.queries, m.s.syncer, input)
+	if err != nil {
+		return nil, err
+	}
+	if note, err := m.s.queries.GetNote(ctx, mem.ID); err == nil {
+		notesync.WriteThrough(ctx, m.s.queries, m.s.vlt, note)
+	}
+	return mem, nil
+}
+
+// Recall finds memories matching a query. UseSemantic only takes effect when Store.Semantic is true.
+func (m *Memories) Recall(ctx context.Context, input RecallInput) (*RecallResult, error) {
+	input.UseSemantic = input.UseSemantic && m.s.syncer != nil
+	return memory.Recall(ctx, m.s.queries, m.s.conn, m.s.syncer, input)
+}
+
+// Forget deletes memories matching the criteria. Without any criteria it only reports what would be
+// deleted.
+func (m *Memories) Forget(ctx context.Context, input ForgetInput) (*ForgetResult, error) {
+	result, err := memory.Forget(ctx, m.s.queries, m.s.syncer, input)
+	if err != nil {
+		return nil, err
+	}
+	if !result.DryRun {
+		for _, mem := range result.Memories {
+			if _, err := m.s.queries.GetNote(ctx, mem.ID); err != nil {
+				notesync.Delete(m.s.vlt, mem.ID)
+			}
+		}
+	}
+	return result, nil
+}
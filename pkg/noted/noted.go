@@ -0,0 +1,148 @@
+// Package noted embeds the noted knowledge base in other Go programs. A Store opens the same
+// SQLite database the CLI uses (running migrations as needed) and exposes notes, search, and agent
+// memories without shelling out to the noted binary.
+//
+//	store, err := noted.Open(noted.Options{DBPath: "notes.db"})
+//	if err != nil {
+//		return err
+//	}
+//	defer store.Close()
+//
+//	note, err := store.Notes().Create(ctx, noted.NoteInput{Title: "Idea", Content: "...", Tags: []string{"inbox"}})
+//	results, err := store.Search(ctx, "idea", 10)
+//	mem, err := store.Memories().Remember(ctx, noted.RememberInput{Content: "User prefers tabs"})
+package noted
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/abdul-hamid-achik/noted/internal/config"
+	"github.com/abdul-hamid-achik/noted/internal/db"
+	"github.com/abdul-hamid-achik/noted/internal/vault"
+	"github.com/abdul-hamid-achik/noted/internal/veclite"
+)
+
+// Options configures Open. Only DBPath is required.
+type Options struct {
+	DBPath string // SQLite database file (created if missing)
+
+	// VaultPath enables markdown write-through: notes changed through the Store are mirrored to
+	// the vault the same way the CLI does. Empty disables it.
+	VaultPath string
+
+	// VeclitePath enables semantic memory recall via Ollama embeddings. Empty disables it, as does
+	// a veclite database or Ollama server that cannot be reached (see Store.Semantic).
+	VeclitePath          string
+	EmbeddingModel       string // Defaults to the configured model
+	MemoryEmbeddingModel string // Model for memories; defaults to the configured one, then EmbeddingModel
+}
+
+// DefaultOptions returns the paths the noted CLI uses, honoring NOTED_VAULT, NOTED_VECLITE_PATH,
+// NOTED_EMBEDDING_MODEL, and NOTED_MEMORY_EMBEDDING_MODEL.
+func DefaultOptions() (Options, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return Options{}, err
+	}
+	return Options{
+		DBPath:               cfg.DBPath,
+		VaultPath:            cfg.VaultPath,
+		VeclitePath:          cfg.VeclitePath,
+		EmbeddingModel:       cfg.EmbeddingModel,
+		MemoryEmbeddingModel: cfg.MemoryEmbeddingModel,
+	}, nil
+}
+
+// Store is an open noted knowledge base. It is safe for concurrent use.
+type Store struct {
+	conn    *sql.DB
+	queries *db.Queries
+	vlt     *vault.Vault
+	syncer  *veclite.Syncer
+}
+
+// Open opens (creating and migrating if needed) the database at opts.DBPath.
+func Open(opts Options) (*Store, error) {
+	if opts.DBPath == "" {
+		return nil, fmt.Errorf("noted: DBPath is required")
+	}
+
+	conn, err := db.Open(opts.DBPath)
+	if err != nil {
+		return nil, fmt.Errorf("noted: failed to open database: %w", err)
+	}
+	s := &Store{conn: conn, queries: db.New(conn)}
+
+	if opts.VaultPath != "" {
+		s.vlt, err = vault.Open(opts.VaultPath)
+		if err != nil {
+			_ = conn.Close()
+			return nil, fmt.Errorf("noted: failed to open vault: %w", err)
+		}
+	}
+
+	if opts.VeclitePath != "" {
+		model, memoryModel := opts.EmbeddingModel, opts.MemoryEmbeddingModel
+		if cfg, err := config.Load(); err == nil {
+			if model == "" {
+				model = cfg.EmbeddingModel
+			}
+			if memoryModel == "" {
+				memoryModel = cfg.MemoryEmbeddingModel
+			}
+		}
+		// Semantic search is optional everywhere in noted: fall back to keyword search.
+		if syncer, err := veclite.NewSyncer(opts.VeclitePath, model); err == nil {
+			s.syncer = syncer.WithCollectionModel(veclite.CollectionMemories, memoryModel)
+		}
+	}
+
+	return s, nil
+}
+
+// Close releases the database and vector store.
+func (s *Store) Close() error {
+	if s.syncer != nil {
+		_ = s.syncer.Close()
+	}
+	return s.conn.Close()
+}
+
+// Semantic reports whether semantic (embedding) search is available.
+func (s *Store) Semantic() bool { return s.syncer != nil }
+
+// Notes returns the note operations for this store.
+func (s *Store) Notes() *Notes { return &Notes{s: s} }
+
+// Memories returns the agent memory operations for this store.
+func (s *Store) Memories() *Memories { return &Memories{s: s} }
+
+// SearchResult is a note matched by Search, best first.
+type SearchResult struct {
+	Note        Note     `json:"note"`
+	Score       float64  `json:"score"`
+	MatchedTags []string `json:"matched_tags,omitempty"`
+}
+
+// Search runs the same keyword search as "noted grep": titles, content, and tag names, with FTS5
+// query syntax when available.
+func (s *Store) Search(ctx context.Context, query string, limit int) ([]SearchResult, error) {
+	if limit <= 0 {
+		limit = 20
+	}
+	results, err := db.SearchNotes(ctx, s.conn, query, int64(limit))
+	if err != nil {
+		return nil, fmt.Errorf("search failed: %w", err)
+	}
+	out := make([]SearchResult, len(results))
+	for i, r := range results {
+		out[i] = SearchResult{
+			Note:        noteFromDB(r.Note, r.Tags, nil),
+			Score:       r.Score,
+			MatchedTags: r.MatchedTags,
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,208 @@
+package noted
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func openTestStore(t *testing.T) (*Store, string) {
+	t.Helper()
+	dir := t.TempDir()
+	vaultDir := filepath.Join(dir, "vault")
+	store, err := Open(Options{DBPath: filepath.Join(dir, "test.db"), VaultPath: vaultDir})
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	t.Cleanup(func() { _ = store.Close() })
+	return store, vaultDir
+}
+
+func TestOpen_RequiresDBPath(t *testing.T) {
+	if _, err := Open(Options{}); err == nil {
+		t.Error("expected error without DBPath")
+	}
+}
+
+func TestNotes_Lifecycle(t *testing.T) {
+	store, vaultDir := openTestStore(t)
+	ctx := context.Background()
+	notes := store.Notes()
+
+	created, err := notes.Create(ctx, NoteInput{Title: "Embedding", Content: "noted as a library", Tags: []string{"go", " "}})
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	if created.ID == 0 || len(created.Tags) != 1 || created.Tags[0] != "go" {
+		t.Errorf("unexpected created note: %+v", created)
+	}
+	if entries, _ := os.ReadDir(vaultDir); len(entries) == 0 {
+		t.Error("expected the note to be written through to the vault")
+	}
+
+	updated, err := notes.Update(ctx, created.ID, "", "noted as a Go library")
+	if err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	if updated.Title != "Embedding" || updated.Content != "noted as a Go library" {
+		t.Errorf("unexpected updated note: %+v", updated)
+	}
+	versions, err := store.queries.GetNoteVersions(ctx, created.ID)
+	if err != nil || len(versions) != 1 {
+		t.Errorf("expected one version snapshot, got %d (err %v)", len(versions), err)
+	}
+
+	tagged, err := notes.ByTag(ctx, "go")
+	if err != nil || len(tagged) != 1 {
+		t.Errorf("ByTag: got %d notes (err %v)", len(tagged), err)
+	}
+
+	if err := notes.Delete(ctx, created.ID); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if _, err := notes.Get(ctx, created.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound after delete, got %v", err)
+	}
+	if err := notes.Delete(ctx, created.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound deleting twice, got %v", err)
+	}
+}
+
+func TestNotes_Aliases(t *testing.T) {
+	store, _ := openTestStore(t)
+	ctx := context.Background()
+	notes := store.Notes()
+
+	created, err := notes.Create(ctx, NoteInput{Title: "Go", Aliases: []string{"Golang", "Go"}})
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	// The note's own title is not stored as an alias
+	if len(created.Aliases) != 1 || created.Aliases[0] != "Golang" {
+		t.Errorf("aliases = %v, want [Golang]", created.Aliases)
+	}
+
+	updated, err := notes.SetAliases(ctx, created.ID, nil)
+	if err != nil {
+		t.Fatalf("SetAliases failed: %v", err)
+	}
+	if len(updated.Aliases) != 0 {
+		t.Errorf("expected aliases to be cleared, got %v", updated.Aliases)
+	}
+	if _, err := notes.SetAliases(ctx, 9999, []string{"x"}); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestNotes_Trash(t *testing.T) {
+	store, _ := openTestStore(t)
+	ctx := context.Background()
+	notes := store.Notes()
+
+	created, err := notes.Create(ctx, NoteInput{Title: "Scratch"})
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	if err := notes.Delete(ctx, created.ID); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	trashed, err := notes.Trash(ctx)
+	if err != nil || len(trashed) != 1 || trashed[0].DeletedAt == nil {
+		t.Fatalf("Trash = %+v, %v", trashed, err)
+	}
+
+	restored, err := notes.Restore(ctx, created.ID)
+	if err != nil || restored.DeletedAt != nil {
+		t.Fatalf("Restore = %+v, %v", restored, err)
+	}
+	if _, err := notes.Restore(ctx, created.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound restoring a live note, got %v", err)
+	}
+
+	_ = notes.Delete(ctx, created.ID)
+	if err := notes.Purge(ctx, created.ID); err != nil {
+		t.Fatalf("Purge failed: %v", err)
+	}
+	if trashed, _ := notes.Trash(ctx); len(trashed) != 0 {
+		t.Errorf("expected an empty trash after Purge, got %d", len(trashed))
+	}
+}
+
+func TestNotes_Archive(t *testing.T) {
+	store, _ := openTestStore(t)
+	ctx := context.Background()
+	notes := store.Notes()
+
+	created, _ := notes.Create(ctx, NoteInput{Title: "Old plan", Tags: []string{"plans"}})
+	archived, err := notes.Archive(ctx, created.ID)
+	if err != nil || archived.ArchivedAt == nil {
+		t.Fatalf("Archive = %+v, %v", archived, err)
+	}
+	if list, _ := notes.List(ctx, 10, 0); len(list) != 0 {
+		t.Errorf("List should hide archived notes, got %d", len(list))
+	}
+	if list, _ := notes.ByTag(ctx, "plans"); len(list) != 0 {
+		t.Errorf("ByTag should hide archived notes, got %d", len(list))
+	}
+	if list, _ := notes.Archived(ctx); len(list) != 1 {
+		t.Errorf("Archived returned %d notes, want 1", len(list))
+	}
+
+	if got, err := notes.Unarchive(ctx, created.ID); err != nil || got.ArchivedAt != nil {
+		t.Fatalf("Unarchive = %+v, %v", got, err)
+	}
+	if _, err := notes.Archive(ctx, 9999); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestStore_Search(t *testing.T) {
+	store, _ := openTestStore(t)
+	ctx := context.Background()
+
+	_, _ = store.Notes().Create(ctx, NoteInput{Title: "SQLite tips", Content: "use WAL"})
+	_, _ = store.Notes().Create(ctx, NoteInput{Title: "Groceries", Content: "milk", Tags: []string{"sqlite"}})
+
+	results, err := store.Search(ctx, "sqlite", 10)
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("expected 2 results, got %d", len(results))
+	}
+	if results[0].Note.Title != "SQLite tips" {
+		t.Errorf("expected title match first, got %q", results[0].Note.Title)
+	}
+	if len(results[1].MatchedTags) != 1 {
+		t.Errorf("expected tag match on second result, got %+v", results[1])
+	}
+}
+
+func TestMemories_RememberRecallForget(t *testing.T) {
+	store, _ := openTestStore(t)
+	ctx := context.Background()
+	mems := store.Memories()
+
+	mem, err := mems.Remember(ctx, RememberInput{Content: "User prefers tabs over spaces", Category: "user-pref"})
+	if err != nil {
+		t.Fatalf("Remember failed: %v", err)
+	}
+
+	recalled, err := mems.Recall(ctx, RecallInput{Query: "tabs", UseSemantic: true})
+	if err != nil {
+		t.Fatalf("Recall failed: %v", err)
+	}
+	if recalled.Method != "keyword" || recalled.Count != 1 || recalled.Memories[0].ID != mem.ID {
+		t.Errorf("unexpected recall result: %+v", recalled)
+	}
+
+	forgotten, err := mems.Forget(ctx, ForgetInput{ID: mem.ID})
+	if err != nil {
+		t.Fatalf("Forget failed: %v", err)
+	}
+	if forgotten.Deleted != 1 {
+		t.Errorf("expected 1 deleted, got %+v", forgotten)
+	}
+}
@@ -0,0 +1,332 @@
+package noted
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/abdul-hamid-achik/noted/internal/db"
+	"github.com/abdul-hamid-achik/noted/internal/memory"
+	"github.com/abdul-hamid-achik/noted/internal/notesync"
+)
+
+// ErrNotFound is returned when a note id does not exist.
+var ErrNotFound = errors.New("noted: note not found")
+
+// Note is a note with its tags.
+type Note struct {
+	ID         int64      `json:"id"`
+	Title      string     `json:"title"`
+	Content    string     `json:"content"`
+	Tags       []string   `json:"tags"`
+	Aliases    []string   `json:"aliases,omitempty"`
+	FolderID   int64      `json:"folder_id,omitempty"` // 0: no folder
+	Pinned     bool       `json:"pinned,omitempty"`
+	Source     string     `json:"source,omitempty"`
+	SourceRef  string     `json:"source_ref,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+	ArchivedAt *time.Time `json:"archived_at,omitempty"` // set for archived notes
+	DeletedAt  *time.Time `json:"deleted_at,omitempty"`  // set for notes in the trash
+}
+
+// NoteInput holds the fields for Notes.Create.
+type NoteInput struct {
+	Title     string
+	Content   string
+	Tags      []string
+	Aliases   []string // Optional, other names [[wikilinks]] resolve to
+	FolderID  int64    // Optional
+	Source    string   // Optional, e.g. "my-app"
+	SourceRef string   // Optional
+}
+
+func noteFromDB(n db.Note, tags, aliases []string) Note {
+	out := Note{
+		ID:        n.ID,
+		Title:     n.Title,
+		Content:   n.Content,
+		Tags:      tags,
+		Aliases:   aliases,
+		FolderID:  n.FolderID.Int64,
+		Pinned:    n.Pinned.Bool,
+		Source:    n.Source.String,
+		SourceRef: n.SourceRef.String,
+		CreatedAt: n.CreatedAt.Time,
+		UpdatedAt: n.UpdatedAt.Time,
+	}
+	if n.ArchivedAt.Valid {
+		out.ArchivedAt = &n.ArchivedAt.Time
+	}
+	if n.DeletedAt.Valid {
+		out.DeletedAt = &n.DeletedAt.Time
+	}
+	if out.Tags == nil {
+		out.Tags = []string{}
+	}
+	return out
+}
+
+// Notes creates, reads, updates, and deletes notes. Writes are mirrored to the vault and the
+// semantic index when the Store has them.
+type Notes struct {
+	s *Store
+}
+
+func (n *Notes) load(ctx context.Context, note db.Note) (Note, error) {
+	tags, err := n.s.queries.GetTagsForNote(ctx, note.ID)
+	if err != nil {
+		return Note{}, err
+	}
+	names := make([]string, len(tags))
+	for i, t := range tags {
+		names[i] = t.Name
+	}
+	aliases, err := n.s.queries.GetNoteAliases(ctx, note.ID)
+	if err != nil {
+		return Note{}, err
+	}
+	return noteFromDB(note, names, aliases), nil
+}
+
+func (n *Notes) loadAll(ctx context.Context, notes []db.Note) ([]Note, error) {
+	out := make([]Note, 0, len(notes))
+	for _, note := range notes {
+		loaded, err := n.load(ctx, note)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, loaded)
+	}
+	return out, nil
+}
+
+// saved mirrors a written note to the vault and the semantic index.
+func (n *Notes) saved(ctx context.Context, note db.Note) {
+	notesync.WriteThrough(ctx, n.s.queries, n.s.vlt, note)
+	if n.s.syncer != nil {
+		if err := memory.SyncNote(ctx, n.s.queries, n.s.syncer, note.ID, note.Title, note.Content); err == nil {
+			_ = n.s.queries.MarkEmbeddingSynced(ctx, note.ID)
+		}
+	}
+}
+
+// Create adds a note.
+func (n *Notes) Create(ctx context.Context, input NoteInput) (Note, error) {
+	title := strings.TrimSpace(input.Title)
+	if title == "" {
+		return Note{}, fmt.Errorf("noted: title is required")
+	}
+
+	var source, sourceRef sql.NullString
+	if input.Source != "" {
+		source = sql.NullString{String: input.Source, Valid: true}
+	}
+	if input.SourceRef != "" {
+		sourceRef = sql.NullString{String: input.SourceRef, Valid: true}
+	}
+
+	q := n.s.queries
+	note, err := q.CreateNoteWithTTL(ctx, db.CreateNoteWithTTLParams{
+		Title:     title,
+		Content:   input.Content,
+		Source:    source,
+		SourceRef: sourceRef,
+	})
+	if err != nil {
+		return Note{}, fmt.Errorf("failed to create note: %w", err)
+	}
+
+	if input.FolderID != 0 {
+		if err := q.MoveNoteToFolder(ctx, db.MoveNoteToFolderParams{
+			FolderID: sql.NullInt64{Int64: input.FolderID, Valid: true},
+			ID:       note.ID,
+		}); err != nil {
+			return Note{}, fmt.Errorf("failed to assign folder: %w", err)
+		}
+	}
+
+	for _, name := range input.Tags {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		tag, err := q.CreateTag(ctx, name)
+		if err != nil {
+			return Note{}, err
+		}
+		if err := q.AddTagToNote(ctx, db.AddTagToNoteParams{NoteID: note.ID, TagID: tag.ID}); err != nil {
+			return Note{}, err
+		}
+	}
+
+	note, err = q.GetNote(ctx, note.ID)
+	if err != nil {
+		return Note{}, err
+	}
+	if len(input.Aliases) > 0 {
+		if err := notesync.SetAliases(ctx, q, note, input.Aliases); err != nil {
+			return Note{}, err
+		}
+	}
+	n.saved(ctx, note)
+	return n.load(ctx, note)
+}
+
+// Get returns a note by id, or ErrNotFound.
+func (n *Notes) Get(ctx context.Context, id int64) (Note, error) {
+	note, err := n.s.queries.GetNote(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return Note{}, ErrNotFound
+		}
+		return Note{}, err
+	}
+	return n.load(ctx, note)
+}
+
+// List returns notes newest first, leaving out archived notes.
+func (n *Notes) List(ctx context.Context, limit, offset int) ([]Note, error) {
+	if limit <= 0 {
+		limit = 20
+	}
+	notes, err := n.s.queries.ListNotes(ctx, db.ListNotesParams{Limit: int64(limit), Offset: int64(offset)})
+	if err != nil {
+		return nil, err
+	}
+	return n.loadAll(ctx, notes)
+}
+
+// ByTag returns the unarchived notes carrying a tag, newest first.
+func (n *Notes) ByTag(ctx context.Context, tag string) ([]Note, error) {
+	notes, err := n.s.queries.GetNotesByTagName(ctx, tag)
+	if err != nil {
+		return nil, err
+	}
+	return n.loadAll(ctx, slices.DeleteFunc(notes, func(note db.Note) bool { return note.ArchivedAt.Valid }))
+}
+
+// Archived returns the archived notes, most recently archived first.
+func (n *Notes) Archived(ctx context.Context) ([]Note, error) {
+	notes, err := n.s.queries.ListArchivedNotes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return n.loadAll(ctx, notes)
+}
+
+// Archive hides a note from List, ByTag, and Search without deleting it.
+func (n *Notes) Archive(ctx context.Context, id int64) (Note, error) {
+	return n.setArchived(ctx, id, true)
+}
+
+// Unarchive brings an archived note back into List, ByTag, and Search.
+func (n *Notes) Unarchive(ctx context.Context, id int64) (Note, error) {
+	return n.setArchived(ctx, id, false)
+}
+
+func (n *Notes) setArchived(ctx context.Context, id int64, archived bool) (Note, error) {
+	note, err := notesync.SetArchived(ctx, n.s.queries, n.s.vlt, id, archived)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return Note{}, ErrNotFound
+		}
+		return Note{}, err
+	}
+	return n.load(ctx, note)
+}
+
+// Update replaces a note's title and content, saving the previous revision to its version
+// history first (as "noted edit" does).
+func (n *Notes) Update(ctx context.Context, id int64, title, content string) (Note, error) {
+	q := n.s.queries
+	existing, err := q.GetNote(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return Note{}, ErrNotFound
+		}
+		return Note{}, err
+	}
+	if title = strings.TrimSpace(title); title == "" {
+		title = existing.Title
+	}
+
+	if err := notesync.SnapshotVersion(ctx, q, n.s.vlt, existing.ID, existing.Title, existing.Content); err != nil {
+		return Note{}, fmt.Errorf("failed to save version: %w", err)
+	}
+	note, err := q.UpdateNote(ctx, db.UpdateNoteParams{Title: title, Content: content, ID: id})
+	if err != nil {
+		return Note{}, fmt.Errorf("failed to update note: %w", err)
+	}
+	n.saved(ctx, note)
+	return n.load(ctx, note)
+}
+
+// SetAliases replaces a note's aliases, the other names its [[wikilinks]] resolve to. An empty
+// list clears them.
+func (n *Notes) SetAliases(ctx context.Context, id int64, aliases []string) (Note, error) {
+	note, err := n.s.queries.GetNote(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return Note{}, ErrNotFound
+		}
+		return Note{}, err
+	}
+	if err := notesync.SetAliases(ctx, n.s.queries, note, aliases); err != nil {
+		return Note{}, err
+	}
+	notesync.WriteThrough(ctx, n.s.queries, n.s.vlt, note)
+	return n.load(ctx, note)
+}
+
+// Delete moves a note to the trash and removes its embedding. Restore brings it back; Purge
+// deletes it for good.
+func (n *Notes) Delete(ctx context.Context, id int64) error {
+	if err := notesync.Trash(ctx, n.s.queries, n.s.vlt, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrNotFound
+		}
+		return err
+	}
+	if n.s.syncer != nil {
+		_ = n.s.syncer.Delete(id)
+	}
+	return nil
+}
+
+// Trash returns the notes in the trash, most recently deleted first.
+func (n *Notes) Trash(ctx context.Context) ([]Note, error) {
+	notes, err := n.s.queries.ListTrashedNotes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return n.loadAll(ctx, notes)
+}
+
+// Restore takes a note out of the trash, or returns ErrNotFound if it is not there.
+func (n *Notes) Restore(ctx context.Context, id int64) (Note, error) {
+	note, err := notesync.Restore(ctx, n.s.queries, n.s.vlt, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return Note{}, ErrNotFound
+		}
+		return Note{}, err
+	}
+	n.saved(ctx, note)
+	return n.load(ctx, note)
+}
+
+// Purge permanently deletes a trashed note, or returns ErrNotFound if it is not in the trash.
+func (n *Notes) Purge(ctx context.Context, id int64) error {
+	if err := notesync.Purge(ctx, n.s.queries, n.s.vlt, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrNotFound
+		}
+		return err
+	}
+	return nil
+}